@@ -0,0 +1,189 @@
+// Package analysis provides static analysis of GraphQL schemas and the operations executed against them.
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// UsageReport lists the parts of a schema which are not referenced by a set of query documents.
+type UsageReport struct {
+	// UnusedTypes are the names of the types never referenced, sorted alphabetically.
+	// Built-in scalars and introspection types are never reported.
+	UnusedTypes []string
+	// UnusedFields are the fields of object and interface types never selected, in the
+	// format "Type.field" and sorted alphabetically.
+	UnusedFields []string
+}
+
+// Unused reports the types and fields of s which are not referenced by any of the given query
+// documents. It is useful to find candidates for deprecation based on a corpus of known queries.
+// Selecting a field on an interface counts as a usage of that field on all implementing types.
+// An error is returned if one of the documents can not be parsed.
+func Unused(s *ast.Schema, documents ...string) (*UsageReport, error) {
+	u := &usage{
+		schema: s,
+		types:  make(map[string]struct{}),
+		fields: make(map[string]struct{}),
+	}
+	for _, d := range documents {
+		doc, err := query.Parse(d)
+		if err != nil {
+			return nil, err
+		}
+		u.doc = doc
+		for _, op := range doc.Operations {
+			root, ok := s.RootOperationTypes[strings.ToLower(string(op.Type))]
+			if !ok {
+				continue
+			}
+			for _, v := range op.Vars {
+				u.useInput(v.Type)
+			}
+			u.useType(root.TypeName())
+			u.selections(root, op.Selections, make(map[string]struct{}))
+		}
+	}
+
+	report := &UsageReport{}
+	for name, t := range s.Types {
+		if isBuiltin(name) {
+			continue
+		}
+		if _, ok := u.types[name]; !ok {
+			report.UnusedTypes = append(report.UnusedTypes, name)
+		}
+		var fields ast.FieldsDefinition
+		switch t := t.(type) {
+		case *ast.ObjectTypeDefinition:
+			fields = t.Fields
+		case *ast.InterfaceTypeDefinition:
+			fields = t.Fields
+		}
+		for _, f := range fields {
+			key := name + "." + f.Name
+			if _, ok := u.fields[key]; !ok {
+				report.UnusedFields = append(report.UnusedFields, key)
+			}
+		}
+	}
+	sort.Strings(report.UnusedTypes)
+	sort.Strings(report.UnusedFields)
+	return report, nil
+}
+
+type usage struct {
+	schema *ast.Schema
+	doc    *ast.ExecutableDefinition
+	types  map[string]struct{}
+	fields map[string]struct{}
+}
+
+func (u *usage) selections(t ast.NamedType, sels ast.SelectionSet, visited map[string]struct{}) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			u.field(t, sel, visited)
+		case *ast.InlineFragment:
+			u.fragment(t, &sel.Fragment, visited)
+		case *ast.FragmentSpread:
+			if _, ok := visited[sel.Name.Name]; ok {
+				continue
+			}
+			visited[sel.Name.Name] = struct{}{}
+			if f := u.doc.Fragments.Get(sel.Name.Name); f != nil {
+				u.fragment(t, &f.Fragment, visited)
+			}
+		}
+	}
+}
+
+func (u *usage) fragment(t ast.NamedType, f *ast.Fragment, visited map[string]struct{}) {
+	if f.On.Name != "" {
+		if on, ok := u.schema.Types[f.On.Name]; ok {
+			t = on
+		}
+	}
+	u.useType(t.TypeName())
+	u.selections(t, f.Selections, visited)
+}
+
+func (u *usage) field(t ast.NamedType, sel *ast.Field, visited map[string]struct{}) {
+	var def *ast.FieldDefinition
+	switch t := t.(type) {
+	case *ast.ObjectTypeDefinition:
+		def = t.Fields.Get(sel.Name.Name)
+	case *ast.InterfaceTypeDefinition:
+		def = t.Fields.Get(sel.Name.Name)
+		for _, impl := range t.PossibleTypes {
+			u.fields[impl.Name+"."+sel.Name.Name] = struct{}{}
+		}
+	}
+	if def == nil {
+		return // meta fields and fields unknown to the schema
+	}
+	u.fields[t.TypeName()+"."+def.Name] = struct{}{}
+
+	for _, arg := range sel.Arguments {
+		if a := def.Arguments.Get(arg.Name.Name); a != nil {
+			u.useInput(a.Type)
+		}
+	}
+
+	named := u.unwrap(def.Type)
+	if named == nil {
+		return
+	}
+	u.useType(named.TypeName())
+	u.selections(named, sel.SelectionSet, visited)
+}
+
+// useInput marks an input type and all input types reachable from it as used.
+func (u *usage) useInput(t ast.Type) {
+	named := u.unwrap(t)
+	if named == nil {
+		return
+	}
+	if _, ok := u.types[named.TypeName()]; ok {
+		return
+	}
+	u.useType(named.TypeName())
+	if obj, ok := named.(*ast.InputObject); ok {
+		for _, v := range obj.Values {
+			u.useInput(v.Type)
+		}
+	}
+}
+
+func (u *usage) useType(name string) {
+	u.types[name] = struct{}{}
+}
+
+// unwrap strips list and non-null wrappers and resolves type references against the schema.
+func (u *usage) unwrap(t ast.Type) ast.NamedType {
+	for {
+		switch tt := t.(type) {
+		case *ast.NonNull:
+			t = tt.OfType
+		case *ast.List:
+			t = tt.OfType
+		case *ast.TypeName:
+			return u.schema.Types[tt.Name]
+		case ast.NamedType:
+			return tt
+		default:
+			return nil
+		}
+	}
+}
+
+func isBuiltin(name string) bool {
+	switch name {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return true
+	}
+	return len(name) > 1 && name[:2] == "__"
+}
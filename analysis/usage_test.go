@@ -0,0 +1,106 @@
+package analysis_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/analysis"
+)
+
+const schema = `
+	type Query {
+		hero(episode: Episode): Character
+		search(filter: SearchFilter): [SearchResult!]!
+		legacy: String
+	}
+
+	enum Episode {
+		NEWHOPE
+		EMPIRE
+	}
+
+	input SearchFilter {
+		text: String!
+		range: Range
+	}
+
+	input Range {
+		from: Int
+		to: Int
+	}
+
+	interface Character {
+		name: String!
+		friends: [Character]
+	}
+
+	type Human implements Character {
+		name: String!
+		friends: [Character]
+		height: Float
+	}
+
+	type Droid implements Character {
+		name: String!
+		friends: [Character]
+		primaryFunction: String
+	}
+
+	type Starship {
+		name: String!
+	}
+
+	union SearchResult = Human | Droid | Starship
+
+	type Unreachable {
+		id: ID!
+	}
+`
+
+func TestUnused(t *testing.T) {
+	s := graphql.MustParseSchema(schema, nil)
+
+	report, err := analysis.Unused(s.AST(), `
+		query Hero($episode: Episode) {
+			hero(episode: $episode) {
+				name
+				... on Droid { primaryFunction }
+			}
+		}
+	`, `
+		query {
+			search(filter: {text: "x"}) {
+				__typename
+				...humanFields
+			}
+		}
+		fragment humanFields on Human { height }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTypes := []string{"Starship", "Unreachable"}
+	if !reflect.DeepEqual(report.UnusedTypes, wantTypes) {
+		t.Errorf("unused types:\nwant: %v\ngot:  %v", wantTypes, report.UnusedTypes)
+	}
+	wantFields := []string{
+		"Character.friends",
+		"Droid.friends",
+		"Human.friends",
+		"Query.legacy",
+		"Starship.name",
+		"Unreachable.id",
+	}
+	if !reflect.DeepEqual(report.UnusedFields, wantFields) {
+		t.Errorf("unused fields:\nwant: %v\ngot:  %v", wantFields, report.UnusedFields)
+	}
+}
+
+func TestUnusedParseError(t *testing.T) {
+	s := graphql.MustParseSchema(schema, nil)
+	if _, err := analysis.Unused(s.AST(), `{ hero `); err == nil {
+		t.Error("expected a parse error")
+	}
+}
@@ -10,6 +10,18 @@ type Directive struct {
 	Arguments ArgumentList
 }
 
+// ArgumentValue returns the Go value of the argument with the given name and reports whether the
+// argument is present. Int values are returned as int32, Float values as float64, String and enum
+// values as string, Boolean values as bool, lists as []interface{} and input objects as
+// map[string]interface{}. Default values of the directive definition are not applied.
+func (d *Directive) ArgumentValue(name string) (interface{}, bool) {
+	v, ok := d.Arguments.Get(name)
+	if !ok || v == nil {
+		return nil, false
+	}
+	return v.Deserialize(nil), true
+}
+
 // DirectiveDefinition is a representation of the GraphQL DirectiveDefinition.
 //
 // http://spec.graphql.org/draft/#sec-Type-System.Directives
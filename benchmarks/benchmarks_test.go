@@ -0,0 +1,112 @@
+package benchmarks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+const starwarsQuery = `
+	query HeroNameAndFriends($episode: Episode, $withFriends: Boolean!) {
+		hero(episode: $episode) {
+			__typename
+			name
+			...on Droid {
+				primaryFunction
+			}
+			friends @include(if: $withFriends) {
+				name
+				...on Human {
+					height(unit: FOOT)
+				}
+			}
+			friendsConnection(first: 2) {
+				totalCount
+				edges {
+					cursor
+					node {
+						name
+					}
+				}
+			}
+		}
+	}
+`
+
+var starwarsVariables = map[string]interface{}{"episode": "JEDI", "withFriends": true}
+
+type benchmark struct {
+	name      string
+	schema    *graphql.Schema
+	query     string
+	variables map[string]interface{}
+}
+
+func benchmarks() []benchmark {
+	wide, wideQuery := wideSchema()
+	deep, deepQuery := deepSchema()
+	return []benchmark{
+		{"Starwars", graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}), starwarsQuery, starwarsVariables},
+		{"Wide", wide, wideQuery, nil},
+		{"Deep", deep, deepQuery, nil},
+	}
+}
+
+// TestQueries makes sure the benchmarks keep working, as go test does not run them by default.
+func TestQueries(t *testing.T) {
+	for _, bb := range benchmarks() {
+		resp := bb.schema.Exec(context.Background(), bb.query, "", bb.variables)
+		if len(resp.Errors) != 0 {
+			t.Errorf("%s: %v", bb.name, resp.Errors)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, bb := range benchmarks() {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := graphql.ParseQuery(bb.query, graphql.ParserOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	for _, bb := range benchmarks() {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if errs := bb.schema.ValidateWithVariables(bb.query, bb.variables); len(errs) != 0 {
+					b.Fatal(errs)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkExec(b *testing.B) {
+	ctx := context.Background()
+	for _, bb := range benchmarks() {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if resp := bb.schema.Exec(ctx, bb.query, "", bb.variables); len(resp.Errors) != 0 {
+					b.Fatal(resp.Errors)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseSchema(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	}
+}
@@ -0,0 +1,15 @@
+// Package benchmarks measures the parsing, validation and execution of queries, so that changes
+// motivated by performance can be evaluated consistently. The benchmarks run against the starwars
+// example schema and two synthetic schemas, a wide one with hundreds of fields per object and a
+// deep one with recursively nested objects.
+//
+// testdata/baseline.txt holds the published baseline. To compare a change against it, run
+//
+//	./scripts/benchcheck.sh
+//
+// from the root of the repository. The script runs the benchmarks, compares them to the baseline
+// with benchstat (golang.org/x/perf/cmd/benchstat) and fails if a benchmark regressed by more than
+// the threshold, 10% by default. Refresh the baseline on the reference machine with
+//
+//	go test -run '^$' -bench . -benchmem -count 10 ./benchmarks > benchmarks/testdata/baseline.txt
+package benchmarks
@@ -0,0 +1,105 @@
+package benchmarks_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	wideFields  = 200
+	wideObjects = 20
+	deepDepth   = 8
+)
+
+type wideObject struct {
+	id int32
+}
+
+// wideSchema returns a schema whose query and object types have wideFields fields each, resolved by
+// field functions, and a query selecting all fields of wideObjects objects.
+func wideSchema() (*graphql.Schema, string) {
+	var sdl, sels strings.Builder
+	var opts []graphql.SchemaOpt
+	sdl.WriteString("type Query {\n\tobjects: [Object!]!\n")
+	for i := 0; i < wideFields; i++ {
+		fmt.Fprintf(&sdl, "\tf%d: Int!\n", i)
+		i := int32(i)
+		opts = append(opts, graphql.FieldFunc("Query", fmt.Sprintf("f%d", i), func() int32 { return i }))
+	}
+	sdl.WriteString("}\n\ntype Object {\n")
+	for i := 0; i < wideFields; i++ {
+		fmt.Fprintf(&sdl, "\tf%d: Int!\n", i)
+		fmt.Fprintf(&sels, " f%d", i)
+		i := int32(i)
+		opts = append(opts, graphql.FieldFunc("Object", fmt.Sprintf("f%d", i), func(o *wideObject) int32 { return o.id + i }))
+	}
+	sdl.WriteString("}\n")
+
+	objects := make([]*wideObject, wideObjects)
+	for i := range objects {
+		objects[i] = &wideObject{id: int32(i)}
+	}
+	opts = append(opts, graphql.FieldFunc("Query", "objects", func() []*wideObject { return objects }))
+
+	s := graphql.MustParseSchema(sdl.String(), &struct{}{}, opts...)
+	return s, fmt.Sprintf("{%s objects {%s } }", sels.String(), sels.String())
+}
+
+const deepSDL = `
+	type Query {
+		node: Node!
+	}
+
+	type Node {
+		id: ID!
+		depth: Int!
+		name(upper: Boolean = false): String!
+		children(first: Int = 2): [Node!]!
+	}
+`
+
+type deepResolver struct{}
+
+func (deepResolver) Node() *node {
+	return &node{}
+}
+
+type node struct {
+	depth int32
+}
+
+func (n *node) ID() graphql.ID {
+	return graphql.ID(fmt.Sprint(n.depth))
+}
+
+func (n *node) Depth() int32 {
+	return n.depth
+}
+
+func (n *node) Name(args struct{ Upper bool }) string {
+	if args.Upper {
+		return "NODE"
+	}
+	return "node"
+}
+
+func (n *node) Children(args struct{ First int32 }) []*node {
+	children := make([]*node, args.First)
+	for i := range children {
+		children[i] = &node{depth: n.depth + 1}
+	}
+	return children
+}
+
+// deepSchema returns a schema of recursively nested nodes and a query selecting deepDepth levels of
+// them with two children each.
+func deepSchema() (*graphql.Schema, string) {
+	s := graphql.MustParseSchema(deepSDL, deepResolver{})
+	q := "id depth name(upper: true)"
+	for i := 0; i < deepDepth; i++ {
+		q = fmt.Sprintf("id depth name children { %s }", q)
+	}
+	return s, fmt.Sprintf("{ node { %s } }", q)
+}
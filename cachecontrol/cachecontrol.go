@@ -0,0 +1,237 @@
+// Package cachecontrol implements Apollo-style cache hints using the @cacheControl directive.
+//
+// The directive and its scope enum must be declared in the schema, e.g. by appending [SDL] to it.
+// Hints may be declared on field definitions and on object, interface and union types:
+//
+//	type Query {
+//		hello: String! @cacheControl(maxAge: 60)
+//		me: User! @cacheControl(scope: PRIVATE)
+//	}
+//
+//	type User @cacheControl(maxAge: 10) {
+//		name: String!
+//	}
+//
+// When the [graphql.CacheControl] schema option is used, the hints of all resolved fields are
+// combined into an overall [Policy]: the lowest max age wins and the scope is private if any of
+// the resolved fields is private. Root fields and fields returning composite types without an
+// explicit max age use the default max age, while scalar fields inherit the max age of their parent.
+package cachecontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// SDL declares the @cacheControl directive and the CacheControlScope enum.
+const SDL = `
+	enum CacheControlScope {
+		PUBLIC
+		PRIVATE
+	}
+
+	directive @cacheControl(
+		maxAge: Int
+		scope: CacheControlScope
+		inheritMaxAge: Boolean
+	) on FIELD_DEFINITION | OBJECT | INTERFACE | UNION
+`
+
+// DirectiveName is the name of the cache control directive.
+const DirectiveName = "cacheControl"
+
+// ExtensionKey is the key of the computed [Policy] in the response extensions.
+const ExtensionKey = "cacheControl"
+
+// Scope defines who is allowed to cache a response.
+type Scope string
+
+// Cache control scopes.
+const (
+	ScopePublic  Scope = "PUBLIC"
+	ScopePrivate Scope = "PRIVATE"
+)
+
+// Hint describes how long and by whom a value may be cached.
+type Hint struct {
+	// MaxAge is the maximum duration the value may be cached for. Nil means unspecified.
+	MaxAge *time.Duration
+	// Scope is the scope of the hint. An empty scope leaves the scope unchanged.
+	Scope Scope
+	// InheritMaxAge makes a field without a max age inherit the max age of its parent
+	// even if it is a root field or returns a composite type.
+	InheritMaxAge bool
+}
+
+// TTL is a helper to set the MaxAge of a [Hint].
+func TTL(d time.Duration) *time.Duration {
+	return &d
+}
+
+// FromDirectives returns the hint declared by the @cacheControl directive in ds, if any.
+func FromDirectives(ds ast.DirectiveList) (Hint, bool) {
+	d := ds.Get(DirectiveName)
+	if d == nil {
+		return Hint{}, false
+	}
+
+	var h Hint
+	if v, ok := d.Arguments.Get("maxAge"); ok && v != nil {
+		if age, ok := v.Deserialize(nil).(int32); ok {
+			h.MaxAge = TTL(time.Duration(age) * time.Second)
+		}
+	}
+	if v, ok := d.Arguments.Get("scope"); ok && v != nil {
+		if s, ok := v.Deserialize(nil).(string); ok {
+			h.Scope = Scope(s)
+		}
+	}
+	if v, ok := d.Arguments.Get("inheritMaxAge"); ok && v != nil {
+		h.InheritMaxAge, _ = v.Deserialize(nil).(bool)
+	}
+	return h, true
+}
+
+// FieldHint returns the hint of a field definition. Hints declared on the field take precedence
+// over hints declared on the type returned by the field.
+func FieldHint(f *ast.FieldDefinition) Hint {
+	var h Hint
+	switch t := unwrap(f.Type).(type) {
+	case *ast.ObjectTypeDefinition:
+		h, _ = FromDirectives(t.Directives)
+	case *ast.InterfaceTypeDefinition:
+		h, _ = FromDirectives(t.Directives)
+	case *ast.Union:
+		h, _ = FromDirectives(t.Directives)
+	}
+	if fh, ok := FromDirectives(f.Directives); ok {
+		if fh.MaxAge != nil || fh.InheritMaxAge {
+			h.MaxAge = fh.MaxAge
+			h.InheritMaxAge = fh.InheritMaxAge
+		}
+		if fh.Scope != "" {
+			h.Scope = fh.Scope
+		}
+	}
+	return h
+}
+
+// Policy is the overall caching policy of a response.
+type Policy struct {
+	MaxAge time.Duration
+	Scope  Scope
+}
+
+// Cacheable reports whether the response may be cached at all.
+func (p Policy) Cacheable() bool {
+	return p.MaxAge > 0
+}
+
+// HeaderValue returns the value of the HTTP Cache-Control header for the policy.
+func (p Policy) HeaderValue() string {
+	if !p.Cacheable() {
+		return "no-store"
+	}
+	scope := "public"
+	if p.Scope == ScopePrivate {
+		scope = "private"
+	}
+	return fmt.Sprintf("max-age=%d, %s", int(p.MaxAge.Seconds()), scope)
+}
+
+// MarshalJSON encodes the policy with the max age in seconds.
+func (p Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		MaxAge int   `json:"maxAge"`
+		Scope  Scope `json:"scope"`
+	}{int(p.MaxAge.Seconds()), p.Scope})
+}
+
+// Collector accumulates the cache hints of a single request. It is safe for concurrent use.
+type Collector struct {
+	mu            sync.Mutex
+	defaultMaxAge time.Duration
+	maxAge        *time.Duration
+	private       bool
+}
+
+// NewCollector returns a collector using defaultMaxAge for fields without an explicit max age.
+func NewCollector(defaultMaxAge time.Duration) *Collector {
+	return &Collector{defaultMaxAge: defaultMaxAge}
+}
+
+// AddFieldHint records the hint of a resolved field.
+func (c *Collector) AddFieldHint(h Hint, root, composite bool) {
+	if h.MaxAge == nil && (root || composite) && !h.InheritMaxAge {
+		h.MaxAge = &c.defaultMaxAge
+	}
+	c.restrict(h)
+}
+
+// Policy returns the overall policy of all hints recorded so far.
+func (c *Collector) Policy() Policy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := Policy{Scope: ScopePublic}
+	if c.maxAge != nil {
+		p.MaxAge = *c.maxAge
+	}
+	if c.private {
+		p.Scope = ScopePrivate
+	}
+	return p
+}
+
+func (c *Collector) restrict(h Hint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if h.MaxAge != nil && (c.maxAge == nil || *h.MaxAge < *c.maxAge) {
+		age := *h.MaxAge
+		c.maxAge = &age
+	}
+	if h.Scope == ScopePrivate {
+		c.private = true
+	}
+}
+
+type ctxKey struct{}
+
+// WithCollector returns a copy of ctx carrying c.
+func WithCollector(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// AddHint restricts the policy of the current request from within a resolver. It does nothing
+// if cache control is not enabled for the schema.
+func AddHint(ctx context.Context, h Hint) {
+	if c, ok := ctx.Value(ctxKey{}).(*Collector); ok {
+		c.restrict(h)
+	}
+}
+
+// IsComposite reports whether t, stripped of any list and non-null wrappers, is an object, interface or union.
+func IsComposite(t ast.Type) bool {
+	switch unwrap(t).(type) {
+	case *ast.ObjectTypeDefinition, *ast.InterfaceTypeDefinition, *ast.Union:
+		return true
+	}
+	return false
+}
+
+func unwrap(t ast.Type) ast.Type {
+	for {
+		switch u := t.(type) {
+		case *ast.NonNull:
+			t = u.OfType
+		case *ast.List:
+			t = u.OfType
+		default:
+			return t
+		}
+	}
+}
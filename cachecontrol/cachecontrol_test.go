@@ -0,0 +1,99 @@
+package cachecontrol_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/cachecontrol"
+)
+
+const schema = cachecontrol.SDL + `
+	type Query {
+		hello: String! @cacheControl(maxAge: 60)
+		greeting: String!
+		me: User @cacheControl(scope: PRIVATE)
+		news: [Article!]!
+	}
+
+	type User {
+		name: String!
+	}
+
+	type Article @cacheControl(maxAge: 30) {
+		title: String!
+		rating: Int! @cacheControl(maxAge: 5)
+	}
+`
+
+type resolver struct{}
+
+func (*resolver) Hello() string    { return "Hello!" }
+func (*resolver) Greeting() string { return "Hi!" }
+func (*resolver) Me() *user        { return &user{} }
+func (*resolver) News() []*article { return []*article{{}} }
+
+type user struct{}
+
+func (*user) Name() string { return "Alice" }
+
+type article struct{}
+
+func (*article) Title() string { return "News" }
+func (*article) Rating(ctx context.Context) int32 {
+	cachecontrol.AddHint(ctx, cachecontrol.Hint{MaxAge: cachecontrol.TTL(2 * time.Second)})
+	return 5
+}
+
+func TestPolicy(t *testing.T) {
+	s := graphql.MustParseSchema(schema, &resolver{}, graphql.CacheControl(0))
+	for _, tc := range []struct {
+		query  string
+		policy cachecontrol.Policy
+		header string
+	}{
+		{`{ hello }`, cachecontrol.Policy{MaxAge: time.Minute, Scope: cachecontrol.ScopePublic}, "max-age=60, public"},
+		{`{ hello greeting }`, cachecontrol.Policy{Scope: cachecontrol.ScopePublic}, "no-store"},
+		{`{ hello me { name } }`, cachecontrol.Policy{Scope: cachecontrol.ScopePrivate}, "no-store"},
+		{`{ hello news { title } }`, cachecontrol.Policy{MaxAge: 30 * time.Second, Scope: cachecontrol.ScopePublic}, "max-age=30, public"},
+		{`{ news { title rating } }`, cachecontrol.Policy{MaxAge: 2 * time.Second, Scope: cachecontrol.ScopePublic}, "max-age=2, public"},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			resp := s.Exec(context.Background(), tc.query, "", nil)
+			if len(resp.Errors) != 0 {
+				t.Fatal(resp.Errors)
+			}
+			p, ok := resp.CachePolicy()
+			if !ok {
+				t.Fatal("missing cache policy")
+			}
+			if p != tc.policy {
+				t.Errorf("got %+v, want %+v", p, tc.policy)
+			}
+			if got := p.HeaderValue(); got != tc.header {
+				t.Errorf("got header %q, want %q", got, tc.header)
+			}
+		})
+	}
+}
+
+func TestDefaultMaxAge(t *testing.T) {
+	s := graphql.MustParseSchema(schema, &resolver{}, graphql.CacheControl(10*time.Second))
+	resp := s.Exec(context.Background(), `{ hello greeting }`, "", nil)
+	p, _ := resp.CachePolicy()
+	if p.MaxAge != 10*time.Second {
+		t.Errorf("got max age %s, want 10s", p.MaxAge)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	s := graphql.MustParseSchema(schema, &resolver{})
+	resp := s.Exec(context.Background(), `{ hello }`, "", nil)
+	if _, ok := resp.CachePolicy(); ok {
+		t.Error("unexpected cache policy")
+	}
+	if resp.Extensions != nil {
+		t.Errorf("unexpected extensions %v", resp.Extensions)
+	}
+}
@@ -0,0 +1,112 @@
+// Package cbor encodes GraphQL responses as CBOR (RFC 8949), e.g. for mobile clients which want
+// smaller payloads than JSON:
+//
+//	schema := graphql.MustParseSchema(sdl, resolver, graphql.ResponseFormat(cbor.ContentType, cbor.Encoder{}))
+//
+// Values are encoded like in JSON and then converted. Numbers become integers if they are whole numbers
+// within the range of 64-bit integers and floats otherwise.
+package cbor
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/graph-gophers/graphql-go/internal/transcode"
+)
+
+// ContentType is the media type of CBOR.
+const ContentType = "application/cbor"
+
+// Encoder is a [graphql.Encoder] which encodes values as CBOR.
+type Encoder struct{}
+
+// Marshal returns the CBOR encoding of v.
+func (Encoder) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var w writer
+	if err := transcode.JSON(data, &w); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// major types
+const (
+	unsignedInt = 0
+	negativeInt = 1
+	textString  = 3
+	array       = 4
+	mapping     = 5
+)
+
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) Null() {
+	w.buf = append(w.buf, 0xf6)
+}
+
+func (w *writer) Bool(b bool) {
+	if b {
+		w.buf = append(w.buf, 0xf5)
+	} else {
+		w.buf = append(w.buf, 0xf4)
+	}
+}
+
+func (w *writer) Int(i int64) {
+	if i >= 0 {
+		w.head(unsignedInt, uint64(i))
+	} else {
+		w.head(negativeInt, uint64(-(i + 1)))
+	}
+}
+
+func (w *writer) Float(f float64) {
+	w.buf = append(w.buf, 0xfb)
+	w.bigEndian(math.Float64bits(f), 8)
+}
+
+func (w *writer) String(s string) {
+	w.head(textString, uint64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+func (w *writer) Array(n int) {
+	w.head(array, uint64(n))
+}
+
+func (w *writer) Map(n int) {
+	w.head(mapping, uint64(n))
+}
+
+// head writes the initial byte of a data item of the major type major with the argument n.
+func (w *writer) head(major byte, n uint64) {
+	switch {
+	case n < 24:
+		w.buf = append(w.buf, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		w.buf = append(w.buf, major<<5|24)
+		w.bigEndian(n, 1)
+	case n <= math.MaxUint16:
+		w.buf = append(w.buf, major<<5|25)
+		w.bigEndian(n, 2)
+	case n <= math.MaxUint32:
+		w.buf = append(w.buf, major<<5|26)
+		w.bigEndian(n, 4)
+	default:
+		w.buf = append(w.buf, major<<5|27)
+		w.bigEndian(n, 8)
+	}
+}
+
+// bigEndian writes the size lowest bytes of n in big-endian order.
+func (w *writer) bigEndian(n uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		w.buf = append(w.buf, byte(n>>(8*i)))
+	}
+}
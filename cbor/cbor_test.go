@@ -0,0 +1,37 @@
+package cbor_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/cbor"
+)
+
+// The expected encodings are taken from appendix A of RFC 8949.
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		value interface{}
+		want  []byte
+	}{
+		{nil, []byte{0xf6}},
+		{true, []byte{0xf5}},
+		{false, []byte{0xf4}},
+		{10, []byte{0x0a}},
+		{100, []byte{0x18, 0x64}},
+		{1000000, []byte{0x1a, 0x00, 0x0f, 0x42, 0x40}},
+		{-1000, []byte{0x39, 0x03, 0xe7}},
+		{1.1, []byte{0xfb, 0x3f, 0xf1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a}},
+		{"IETF", []byte{0x64, 'I', 'E', 'T', 'F'}},
+		{[]int{1, 2, 3}, []byte{0x83, 0x01, 0x02, 0x03}},
+		{json.RawMessage(`{"b":1,"a":[2]}`), []byte{0xa2, 0x61, 'b', 0x01, 0x61, 'a', 0x81, 0x02}},
+	} {
+		got, err := cbor.Encoder{}.Marshal(tc.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("Marshal(%v) = % x, want % x", tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package client sends GraphQL operations to remote servers and decodes the results into Go values,
+// e.g. for integration tests and gateways. Queries and mutations are sent as JSON POST requests such
+// as served by relay.Handler, subscriptions over a websocket:
+//
+//	c := &client.Client{URL: "http://localhost:8080/query"}
+//	var res struct {
+//		Hero struct {
+//			Name string
+//		}
+//	}
+//	err := c.Do(ctx, graphql.Request{Query: `{ hero { name } }`}, &res)
+//
+// Results are decoded with the coercion rules the server applies to inputs: struct fields match
+// GraphQL names case-insensitively ignoring underscores or by a `graphql:"name"` tag, and custom
+// scalars such as graphql.Time are decoded with their UnmarshalGraphQL method.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Websocket subprotocols of subscriptions.
+const (
+	// GraphQLWS is the legacy protocol of subscriptions-transport-ws.
+	GraphQLWS = "graphql-ws"
+	// GraphQLTransportWS is the protocol of graphql-ws.
+	GraphQLTransportWS = "graphql-transport-ws"
+)
+
+// Client sends operations to a GraphQL server.
+type Client struct {
+	// URL is the HTTP endpoint of the server, e.g. "http://localhost:8080/query".
+	URL string
+
+	// WebSocketURL is the endpoint of subscriptions. If it is empty, it is derived from URL by
+	// replacing the http(s) scheme with ws(s).
+	WebSocketURL string
+
+	// Protocol is the websocket subprotocol of subscriptions, GraphQLWS by default.
+	Protocol string
+
+	// HTTPClient sends the requests. If it is nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Header returns additional headers for a request, e.g. to forward authorization. It may be nil.
+	Header func(ctx context.Context) http.Header
+
+	// MaxMessageSize is the maximum size of the websocket messages of subscriptions in bytes. It
+	// defaults to 32 MB if it is not positive.
+	MaxMessageSize int64
+}
+
+// Response is the undecoded response of the server.
+type Response struct {
+	Data       json.RawMessage        `json:"data,omitempty"`
+	Errors     []*errors.QueryError   `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Errors is returned if the response contains errors. The data is decoded nevertheless, so partial
+// results are available.
+type Errors []*errors.QueryError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// StatusError is returned for responses with a status code other than 2xx which carry no GraphQL
+// response.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("graphql: unexpected status %d: %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Decode decodes the data of the response into out. If the response contains errors, they are
+// returned as Errors after decoding.
+func (r *Response) Decode(out interface{}) error {
+	if out != nil && len(r.Data) != 0 {
+		if err := Unmarshal(r.Data, out); err != nil {
+			return err
+		}
+	}
+	if len(r.Errors) != 0 {
+		return Errors(r.Errors)
+	}
+	return nil
+}
+
+// Do sends a query or mutation and decodes the data of the response into out, which may be nil.
+func (c *Client) Do(ctx context.Context, req graphql.Request, out interface{}) error {
+	resp, err := c.Exec(ctx, req)
+	if err != nil {
+		return err
+	}
+	return resp.Decode(out)
+}
+
+// Exec sends a query or mutation and returns the undecoded response.
+func (c *Client) Exec(ctx context.Context, req graphql.Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, vs := range c.header(ctx) {
+		for _, v := range vs {
+			httpReq.Header.Add(k, v)
+		}
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil || resp.Data == nil && resp.Errors == nil {
+		if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+			return nil, &StatusError{StatusCode: httpResp.StatusCode, Body: string(data)}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("graphql: invalid response: %w", err)
+		}
+	}
+	return &resp, nil
+}
+
+func (c *Client) header(ctx context.Context) http.Header {
+	if c.Header == nil {
+		return nil
+	}
+	return c.Header(ctx)
+}
@@ -0,0 +1,314 @@
+package client_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/client"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go/testschemas/starwars"
+)
+
+func TestDo(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	srv := httptest.NewServer(&relay.Handler{Schema: schema})
+	defer srv.Close()
+	c := &client.Client{URL: srv.URL}
+
+	t.Run("query", func(t *testing.T) {
+		var res struct {
+			Hero struct {
+				ID      graphql.ID
+				Name    string
+				Friends []*struct {
+					Name string `graphql:"name"`
+				}
+				AppearsIn []string `graphql:"appearsIn"`
+			}
+			Human *struct {
+				Height float64
+				Mass   int32
+			}
+		}
+		err := c.Do(context.Background(), graphql.Request{
+			Query:     `query($id: ID!) { hero { id name friends { name } appearsIn } human(id: $id) { height mass } }`,
+			Variables: map[string]interface{}{"id": "1000"},
+		}, &res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Hero.ID != "2001" || res.Hero.Name != "R2-D2" || len(res.Hero.Friends) != 3 || res.Hero.Friends[0].Name != "Luke Skywalker" {
+			t.Errorf("unexpected hero: %+v", res.Hero)
+		}
+		if want := []string{"NEWHOPE", "EMPIRE", "JEDI"}; !reflect.DeepEqual(res.Hero.AppearsIn, want) {
+			t.Errorf("got appearsIn %v, want %v", res.Hero.AppearsIn, want)
+		}
+		if res.Human == nil || res.Human.Height != 1.72 || res.Human.Mass != 77 {
+			t.Errorf("unexpected human: %+v", res.Human)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var res struct {
+			Hero *struct{ Name string }
+		}
+		err := c.Do(context.Background(), graphql.Request{Query: `{ hero { name } unknown }`}, &res)
+		var errs client.Errors
+		if !errors.As(err, &errs) || len(errs) != 1 {
+			t.Fatalf("expected one query error, got %v", err)
+		}
+		if want := `Cannot query field "unknown" on type "Query".`; errs[0].Message != want {
+			t.Errorf("got message %q, want %q", errs[0].Message, want)
+		}
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		var res struct {
+			Hero struct{ Name int }
+		}
+		err := c.Do(context.Background(), graphql.Request{Query: `{ hero { name } }`}, &res)
+		if want := `graphql: can not decode hero.name: can not use string "R2-D2" as int`; err == nil || err.Error() != want {
+			t.Errorf("got error %v, want %q", err, want)
+		}
+	})
+}
+
+func TestUnmarshal(t *testing.T) {
+	type Base struct {
+		ID graphql.ID
+	}
+	var res struct {
+		Base
+		CreatedAt  graphql.Time
+		UpdatedAt  *graphql.Time
+		Price      graphql.Decimal
+		Nickname   graphql.NullString
+		Tags       map[string]int
+		Rest       interface{}
+		first_name string
+	}
+	data := `{"id": "1", "created_at": "2021-01-02T03:04:05Z", "updatedAt": null, "price": "12.30", "nickname": null, "tags": {"a": 1}, "rest": [true], "firstName": "x"}`
+	if err := client.Unmarshal([]byte(data), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != "1" || !res.CreatedAt.Equal(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)) || res.UpdatedAt != nil {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if res.Price.String() != "12.30" || !res.Nickname.Set || res.Nickname.Value != nil {
+		t.Errorf("unexpected scalars: %+v", res)
+	}
+	if !reflect.DeepEqual(res.Tags, map[string]int{"a": 1}) || !reflect.DeepEqual(res.Rest, []interface{}{true}) {
+		t.Errorf("unexpected tags or rest: %+v", res)
+	}
+	if res.first_name != "" {
+		t.Errorf("unexported field was set")
+	}
+
+	var n struct{ Count int32 }
+	if err := client.Unmarshal([]byte(`{"count": 1.5}`), &n); err == nil {
+		t.Error("expected error for non-integral number")
+	}
+}
+
+type subscriptionResolver struct{}
+
+func (r *subscriptionResolver) Hello() string { return "Hello world!" }
+
+func (r *subscriptionResolver) Count(ctx context.Context, args struct{ To int32 }) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		for i := int32(1); i <= args.To; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+const subscriptionSchema = `
+	schema {
+		query: Query
+		subscription: Subscription
+	}
+	type Query {
+		hello: String!
+	}
+	type Subscription {
+		count(to: Int!): Int!
+	}
+`
+
+func TestSubscribe(t *testing.T) {
+	schema := graphql.MustParseSchema(subscriptionSchema, &subscriptionResolver{})
+	srv := httptest.NewServer(&wsHandler{t: t, schema: schema})
+	defer srv.Close()
+	c := &client.Client{URL: srv.URL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub, err := c.Subscribe(ctx, graphql.Request{Query: `subscription { count(to: 3) }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	var got []int32
+	for {
+		var res struct{ Count int32 }
+		err := sub.Next(&res)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, res.Count)
+	}
+	if want := []int32{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSubscribeMessageTooLarge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+		// a frame announcing a payload of 1 TB
+		frame := []byte{0x81, 127, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(frame[2:], 1<<40)
+		rw.Write(frame)
+		rw.Flush()
+		readMessage(rw.Reader)
+	}))
+	defer srv.Close()
+
+	c := &client.Client{URL: srv.URL}
+	_, err := c.Subscribe(context.Background(), graphql.Request{Query: `subscription { count }`})
+	if err == nil || err.Error() != "graphql: websocket message too large" {
+		t.Errorf("got error %v, want the message to be rejected", err)
+	}
+}
+
+// wsHandler serves subscriptions with the legacy graphql-ws protocol. It supports just enough of
+// the websocket protocol for the test.
+type wsHandler struct {
+	t      *testing.T
+	schema *graphql.Schema
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Sec-WebSocket-Protocol") != client.GraphQLWS {
+		http.Error(w, "unsupported protocol", http.StatusBadRequest)
+		return
+	}
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		h.t.Error(err)
+		return
+	}
+	defer conn.Close()
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Protocol: graphql-ws\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	rw.Flush()
+
+	write := func(msg *wsMessage) {
+		b, _ := json.Marshal(msg)
+		rw.Write([]byte{0x81, byte(len(b))}) // messages of the test are shorter than 126 bytes
+		rw.Write(b)
+		rw.Flush()
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	for {
+		msg, ok := readMessage(rw.Reader)
+		if !ok {
+			return
+		}
+		switch msg.Type {
+		case "connection_init":
+			write(&wsMessage{Type: "connection_ack"})
+		case "start":
+			var req graphql.Request
+			if err := json.Unmarshal(msg.Payload, &req); err != nil {
+				h.t.Error(err)
+				return
+			}
+			c, err := h.schema.Subscribe(ctx, req.Query, req.OperationName, req.Variables)
+			if err != nil {
+				h.t.Error(err)
+				return
+			}
+			go func(id string) {
+				for resp := range c {
+					b, _ := json.Marshal(resp)
+					write(&wsMessage{ID: id, Type: "data", Payload: b})
+				}
+				write(&wsMessage{ID: id, Type: "complete"})
+			}(msg.ID)
+		case "stop", "connection_terminate":
+			cancel()
+		}
+	}
+}
+
+func readMessage(r *bufio.Reader) (*wsMessage, bool) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil || head[0]&0x0f != 0x1 {
+		return nil, false
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return nil, false
+		}
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	var mask [4]byte
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return nil, false
+	}
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, false
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	var msg wsMessage
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return nil, false
+	}
+	return &msg, true
+}
@@ -0,0 +1,311 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/decode"
+)
+
+// Unmarshal decodes the JSON encoded data of a GraphQL response into out, which must be a non-nil
+// pointer. Struct fields match GraphQL names case-insensitively ignoring underscores, or exactly by a
+// `graphql:"name"` tag. Types implementing decode.Unmarshaler are decoded with UnmarshalGraphQL, other
+// types implementing json.Unmarshaler with UnmarshalJSON. Fields of the response which have no
+// matching struct field are ignored.
+func Unmarshal(data []byte, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("graphql: can not decode into %T, a non-nil pointer is required", out)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	return assign(rv.Elem(), v, nil)
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*decode.Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+type decodeError struct {
+	path []interface{}
+	err  error
+}
+
+func (e *decodeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("graphql: can not decode ")
+	if len(e.path) == 0 {
+		sb.WriteString("data")
+	}
+	for i, p := range e.path {
+		if n, ok := p.(int); ok {
+			fmt.Fprintf(&sb, "[%d]", n)
+			continue
+		}
+		if i != 0 {
+			sb.WriteByte('.')
+		}
+		fmt.Fprint(&sb, p)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.err.Error())
+	return sb.String()
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+func assign(dst reflect.Value, v interface{}, path []interface{}) error {
+	if dst.Kind() == reflect.Ptr {
+		if v == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		p := reflect.New(dst.Type().Elem())
+		if err := assign(p.Elem(), v, path); err != nil {
+			return err
+		}
+		dst.Set(p)
+		return nil
+	}
+
+	if reflect.PtrTo(dst.Type()).Implements(unmarshalerType) {
+		u := dst.Addr().Interface().(decode.Unmarshaler)
+		if v == nil {
+			if _, ok := u.(interface{ Nullable() }); !ok {
+				dst.Set(reflect.Zero(dst.Type()))
+				return nil
+			}
+		}
+		if err := u.UnmarshalGraphQL(v); err != nil {
+			return &decodeError{path: path, err: err}
+		}
+		return nil
+	}
+	if reflect.PtrTo(dst.Type()).Implements(jsonUnmarshalerType) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if err := dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(b); err != nil {
+			return &decodeError{path: path, err: err}
+		}
+		return nil
+	}
+	if v == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	mismatch := func() error {
+		return &decodeError{path: path, err: fmt.Errorf("can not use %s as %s", describe(v), dst.Type())}
+	}
+	switch dst.Kind() {
+	case reflect.Interface:
+		if !reflect.TypeOf(v).AssignableTo(dst.Type()) {
+			return mismatch()
+		}
+		dst.Set(reflect.ValueOf(v))
+
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		fields := fieldsOf(dst.Type())
+		for k, fv := range m {
+			index, ok := fields.lookup(k)
+			if !ok {
+				continue
+			}
+			f, err := fieldByIndex(dst, index)
+			if err != nil {
+				return &decodeError{path: append(path, k), err: err}
+			}
+			if err := assign(f, fv, append(path, k)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok || dst.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		res := reflect.MakeMapWithSize(dst.Type(), len(m))
+		for k, ev := range m {
+			e := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(e, ev, append(path, k)); err != nil {
+				return err
+			}
+			res.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), e)
+		}
+		dst.Set(res)
+
+	case reflect.Slice, reflect.Array:
+		l, ok := v.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		if dst.Kind() == reflect.Slice {
+			dst.Set(reflect.MakeSlice(dst.Type(), len(l), len(l)))
+		} else if len(l) > dst.Len() {
+			return &decodeError{path: path, err: fmt.Errorf("list of length %d does not fit into %s", len(l), dst.Type())}
+		}
+		for i, ev := range l {
+			if err := assign(dst.Index(i), ev, append(path, i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.String:
+		s, ok := v.(string)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetString(s)
+
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || dst.OverflowInt(int64(f)) {
+			return mismatch()
+		}
+		dst.SetInt(int64(f))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := v.(float64)
+		if !ok || f != math.Trunc(f) || f < 0 || dst.OverflowUint(uint64(f)) {
+			return mismatch()
+		}
+		dst.SetUint(uint64(f))
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := v.(float64)
+		if !ok || dst.OverflowFloat(f) {
+			return mismatch()
+		}
+		dst.SetFloat(f)
+
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "list"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	default:
+		return fmt.Sprintf("%T %v", v, v)
+	}
+}
+
+// fieldByIndex returns the nested field, allocating nil pointers to embedded structs.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("can not set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+type structFields struct {
+	tagged map[string][]int
+	named  map[string][]int
+}
+
+func (f *structFields) lookup(name string) ([]int, bool) {
+	if index, ok := f.tagged[name]; ok {
+		return index, true
+	}
+	index, ok := f.named[normalize(name)]
+	return index, ok
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+func fieldsOf(t reflect.Type) *structFields {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(*structFields)
+	}
+	f := &structFields{tagged: make(map[string][]int), named: make(map[string][]int)}
+	collectFields(f, t, nil, make(map[string]int), make(map[string]int))
+	fieldCache.Store(t, f)
+	return f
+}
+
+// collectFields adds the exported fields of t to f. Fields of embedded structs are promoted unless a
+// shallower field has the same name, like Go's selector rules.
+func collectFields(f *structFields, t reflect.Type, index []int, taggedDepth, namedDepth map[string]int) {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	var embeds []embedded
+	depth := len(index)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+		tag := sf.Tag.Get("graphql")
+		if sf.Anonymous && tag == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !reflect.PtrTo(ft).Implements(unmarshalerType) {
+				embeds = append(embeds, embedded{t: ft, index: fieldIndex})
+				continue
+			}
+		}
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+		if tag != "" {
+			if d, ok := taggedDepth[tag]; !ok || d > depth {
+				taggedDepth[tag] = depth
+				f.tagged[tag] = fieldIndex
+			}
+			continue
+		}
+		name := normalize(sf.Name)
+		if d, ok := namedDepth[name]; !ok || d > depth {
+			namedDepth[name] = depth
+			f.named[name] = fieldIndex
+		}
+	}
+	for _, e := range embeds {
+		collectFields(f, e.t, e.index, taggedDepth, namedDepth)
+	}
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/websocket"
+)
+
+// Subscription receives the results of a subscription. It must be closed when it is not needed anymore.
+type Subscription struct {
+	conn     *websocket.Conn
+	protocol string
+	stop     chan struct{}
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscribe opens a websocket connection and starts the subscription. The connection is closed when
+// ctx is done or the subscription is closed.
+func (c *Client) Subscribe(ctx context.Context, req graphql.Request) (*Subscription, error) {
+	protocol := c.Protocol
+	if protocol == "" {
+		protocol = GraphQLWS
+	}
+	if protocol != GraphQLWS && protocol != GraphQLTransportWS {
+		return nil, fmt.Errorf("graphql: unsupported websocket protocol %q", protocol)
+	}
+	wsURL := c.WebSocketURL
+	if wsURL == "" {
+		wsURL = "ws" + strings.TrimPrefix(c.URL, "http")
+	}
+
+	limit := c.MaxMessageSize
+	if limit <= 0 {
+		limit = 32 << 20
+	}
+	conn, err := dialWebSocket(ctx, wsURL, protocol, c.header(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription{conn: conn, protocol: protocol, stop: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.stop:
+		}
+	}()
+
+	if err := conn.WriteJSON(&wsMessage{Type: "connection_init", Payload: json.RawMessage("{}")}); err != nil {
+		s.Close()
+		return nil, err
+	}
+	for {
+		msg, err := s.read()
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		if msg.Type == "connection_ack" {
+			break
+		}
+		if msg.Type == "connection_error" {
+			s.Close()
+			return nil, fmt.Errorf("graphql: connection rejected: %s", msg.Payload)
+		}
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	start := "start"
+	if protocol == GraphQLTransportWS {
+		start = "subscribe"
+	}
+	if err := conn.WriteJSON(&wsMessage{ID: "1", Type: start, Payload: payload}); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// read returns the next message, answering keep-alive pings.
+func (s *Subscription) read() (*wsMessage, error) {
+	for {
+		b, err := s.conn.ReadMessage()
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("graphql: %w", err)
+			}
+			return nil, err
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(b, &msg); err != nil {
+			return nil, fmt.Errorf("graphql: invalid message: %w", err)
+		}
+		switch msg.Type {
+		case "ka", "pong":
+			continue
+		case "ping":
+			if err := s.conn.WriteJSON(&wsMessage{Type: "pong"}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return &msg, nil
+	}
+}
+
+// Next blocks until the next result arrives and decodes its data into out, which may be nil. If the
+// result contains errors, they are returned as Errors after decoding. It returns io.EOF once the
+// subscription is complete.
+func (s *Subscription) Next(out interface{}) error {
+	for {
+		msg, err := s.read()
+		if err != nil {
+			select {
+			case <-s.stop:
+				return io.EOF
+			default:
+			}
+			return err
+		}
+		switch msg.Type {
+		case "data", "next":
+			var resp Response
+			if err := json.Unmarshal(msg.Payload, &resp); err != nil {
+				return fmt.Errorf("graphql: invalid response: %w", err)
+			}
+			return resp.Decode(out)
+		case "error":
+			var errs []*qerrors.QueryError
+			if err := json.Unmarshal(msg.Payload, &errs); err != nil {
+				// the legacy protocol sends a single error object
+				var qErr qerrors.QueryError
+				if err := json.Unmarshal(msg.Payload, &qErr); err != nil {
+					return fmt.Errorf("graphql: invalid error: %w", err)
+				}
+				errs = []*qerrors.QueryError{&qErr}
+			}
+			s.Close()
+			return Errors(errs)
+		case "complete":
+			s.Close()
+			return io.EOF
+		}
+	}
+}
+
+// Close stops the subscription and closes the connection.
+func (s *Subscription) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		stop := "stop"
+		if s.protocol == GraphQLTransportWS {
+			stop = "complete"
+		}
+		s.conn.WriteJSON(&wsMessage{ID: "1", Type: stop})
+		if s.protocol == GraphQLWS {
+			s.conn.WriteJSON(&wsMessage{Type: "connection_terminate"})
+		}
+		if err := s.conn.Close(1000, ""); err != nil && !errors.Is(err, io.EOF) {
+			s.closeErr = err
+		}
+	})
+	return s.closeErr
+}
@@ -0,0 +1,79 @@
+// Command inputgen generates Go structs for the input objects and field arguments of a GraphQL schema,
+// see codegen.Inputs. It is meant to be used with go generate:
+//
+//	//go:generate go run github.com/graph-gophers/graphql-go/codegen/cmd/inputgen -package schema -out inputs_gen.go schema.graphql
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/codegen"
+)
+
+type scalarFlags map[string]string
+
+func (s scalarFlags) String() string { return fmt.Sprint(map[string]string(s)) }
+
+func (s scalarFlags) Set(v string) error {
+	i := strings.IndexByte(v, '=')
+	if i == -1 {
+		return fmt.Errorf("expected Scalar=import/path.Type, got %q", v)
+	}
+	s[v[:i]] = v[i+1:]
+	return nil
+}
+
+func main() {
+	cfg := codegen.Config{Scalars: make(scalarFlags)}
+	flag.StringVar(&cfg.Package, "package", "", "name of the generated package (required)")
+	out := flag.String("out", "", "output file, standard output if empty")
+	descriptions := flag.Bool("string-descriptions", false, "parse string descriptions instead of comments")
+	flag.Var(scalarFlags(cfg.Scalars), "scalar", "Go type of a custom scalar as Scalar=import/path.Type, may be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: inputgen -package name [flags] schema.graphql...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if cfg.Package == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var sdl strings.Builder
+	for _, name := range flag.Args() {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			fatal(err)
+		}
+		sdl.Write(b)
+		sdl.WriteByte('\n')
+	}
+	var opts []graphql.SchemaOpt
+	if *descriptions {
+		opts = append(opts, graphql.UseStringDescriptions())
+	}
+	schema, err := graphql.ParseSchema(sdl.String(), nil, opts...)
+	if err != nil {
+		fatal(err)
+	}
+	src, err := codegen.Inputs(schema.AST(), cfg)
+	if err != nil {
+		fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "inputgen:", err)
+	os.Exit(1)
+}
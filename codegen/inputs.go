@@ -0,0 +1,247 @@
+// Package codegen generates Go code from GraphQL schema definitions.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+const graphqlPkg = "github.com/graph-gophers/graphql-go"
+
+// Config configures the generated code.
+type Config struct {
+	// Package is the name of the package of the generated file.
+	Package string
+
+	// Scalars maps custom scalars to the Go types which unmarshal them, as the import path followed by
+	// the type name, e.g. "github.com/org/geo.Point". The Go type must implement decode.Unmarshaler.
+	// The built-in scalars and the scalars of package graphql such as Time do not need to be mapped.
+	Scalars map[string]string
+}
+
+type scalar struct {
+	pkg, name string
+	// null is the name of the graphql.Null* type of the scalar, if any.
+	null string
+}
+
+var builtinScalars = map[string]scalar{
+	"Int":      {name: "int32", null: "NullInt"},
+	"Float":    {name: "float64", null: "NullFloat"},
+	"String":   {name: "string", null: "NullString"},
+	"Boolean":  {name: "bool", null: "NullBool"},
+	"ID":       {pkg: graphqlPkg, name: "ID", null: "NullID"},
+	"Time":     {pkg: graphqlPkg, name: "Time", null: "NullTime"},
+	"UnixTime": {pkg: graphqlPkg, name: "UnixTime"},
+	"Decimal":  {pkg: graphqlPkg, name: "Decimal"},
+	"Money":    {pkg: graphqlPkg, name: "Money"},
+	"Upload":   {pkg: graphqlPkg, name: "Upload"},
+}
+
+// Inputs generates a gofmt-ed Go file with a struct for each input object of s and an args struct
+// for each field of an object type which takes arguments, named after the type and the field, e.g.
+// QueryHeroArgs for Query.hero. The structs bind to the schema as the arguments of resolvers:
+//
+//   - Int, Float, String, Boolean and ID map to int32, float64, string, bool and graphql.ID.
+//   - Enums map to string.
+//   - Nullable scalars map to the graphql.Null* types, so an omitted value can be told apart from an
+//     explicit null. Other nullable types map to pointers.
+//   - Input values with a default value are never nil and map to non-pointer types.
+func Inputs(s *ast.Schema, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("codegen: package name is missing")
+	}
+	g := &generator{cfg: cfg, imports: make(map[string]string)}
+
+	var names []string
+	for name, t := range s.Types {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		switch t.(type) {
+		case *ast.InputObject, *ast.ObjectTypeDefinition:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch t := s.Types[name].(type) {
+		case *ast.InputObject:
+			if err := g.writeStruct(exportedName(t.Name), t.Desc, t.Values); err != nil {
+				return nil, fmt.Errorf("codegen: input %s: %w", t.Name, err)
+			}
+		case *ast.ObjectTypeDefinition:
+			for _, f := range t.Fields {
+				if len(f.Arguments) == 0 {
+					continue
+				}
+				structName := exportedName(t.Name) + exportedName(f.Name) + "Args"
+				doc := fmt.Sprintf("%s are the arguments of %s.%s.", structName, t.Name, f.Name)
+				if err := g.writeStruct(structName, doc, f.Arguments); err != nil {
+					return nil, fmt.Errorf("codegen: arguments of %s.%s: %w", t.Name, f.Name, err)
+				}
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by graphql-go codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", cfg.Package)
+	if len(g.imports) != 0 {
+		paths := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		out.WriteString("import (\n")
+		for _, path := range paths {
+			fmt.Fprintf(&out, "\t%s %q\n", g.imports[path], path)
+		}
+		out.WriteString(")\n\n")
+	}
+	out.Write(g.buf.Bytes())
+	return format.Source(out.Bytes())
+}
+
+type generator struct {
+	cfg     Config
+	imports map[string]string // import path -> package name
+	buf     bytes.Buffer
+}
+
+func (g *generator) writeStruct(name, desc string, values ast.ArgumentsDefinition) error {
+	writeComment(&g.buf, "", desc)
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	for _, v := range values {
+		typ, err := g.goType(v.Type, v.Default != nil, true)
+		if err != nil {
+			return fmt.Errorf("%s: %w", v.Name.Name, err)
+		}
+		writeComment(&g.buf, "\t", v.Desc)
+		fmt.Fprintf(&g.buf, "\t%s %s\n", exportedName(v.Name.Name), typ)
+	}
+	g.buf.WriteString("}\n\n")
+	return nil
+}
+
+// goType returns the Go type of t. Values with defaults are always set, so they are treated as
+// non-null like the packer does. Only struct fields use the Null* types, list elements are pointers.
+func (g *generator) goType(t ast.Type, hasDefault, field bool) (string, error) {
+	nonNull := hasDefault
+	if nn, ok := t.(*ast.NonNull); ok {
+		t, nonNull = nn.OfType, true
+	}
+	ptr := func(s string) string {
+		if nonNull {
+			return s
+		}
+		return "*" + s
+	}
+
+	switch t := t.(type) {
+	case *ast.List:
+		elem, err := g.goType(t.OfType, false, false)
+		if err != nil {
+			return "", err
+		}
+		return ptr("[]" + elem), nil
+
+	case *ast.EnumTypeDefinition:
+		return ptr("string"), nil
+
+	case *ast.InputObject:
+		return ptr(exportedName(t.Name)), nil
+
+	case *ast.ScalarTypeDefinition:
+		sc, ok := builtinScalars[t.Name]
+		if custom, isCustom := g.cfg.Scalars[t.Name]; isCustom {
+			i := strings.LastIndexByte(custom, '.')
+			if i <= strings.LastIndexByte(custom, '/') {
+				return "", fmt.Errorf("invalid Go type %q of scalar %s, expected an import path followed by a type name", custom, t.Name)
+			}
+			sc, ok = scalar{pkg: custom[:i], name: custom[i+1:]}, true
+		}
+		if !ok {
+			return "", fmt.Errorf("no Go type for scalar %s, map it with Config.Scalars", t.Name)
+		}
+		if !nonNull && field && sc.null != "" {
+			return g.qualify(graphqlPkg, sc.null), nil
+		}
+		return ptr(g.qualify(sc.pkg, sc.name)), nil
+
+	default:
+		return "", fmt.Errorf("type %s of kind %s can not be used as input", t, t.Kind())
+	}
+}
+
+// qualify returns the qualified name of a type and records the import of its package.
+func (g *generator) qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	alias, ok := g.imports[pkg]
+	if !ok {
+		alias = pkg[strings.LastIndexByte(pkg, '/')+1:]
+		if pkg == graphqlPkg {
+			alias = "graphql"
+		}
+		alias = strings.Map(func(r rune) rune {
+			if r == '-' || r == '.' {
+				return '_'
+			}
+			return r
+		}, alias)
+		for taken(g.imports, alias) {
+			alias += "_"
+		}
+		g.imports[pkg] = alias
+	}
+	return alias + "." + name
+}
+
+func taken(imports map[string]string, alias string) bool {
+	for _, a := range imports {
+		if a == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func writeComment(buf *bytes.Buffer, indent, desc string) {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return
+	}
+	for _, line := range strings.Split(desc, "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// initialisms are written in upper case as Go names, e.g. "userId" becomes "UserID".
+var initialisms = []string{"Id", "Url", "Uri", "Json", "Html", "Http", "Api"}
+
+// exportedName converts a GraphQL name into an exported Go name which the packer matches, e.g.
+// "first_name" becomes "FirstName".
+func exportedName(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	s := sb.String()
+	for _, w := range initialisms {
+		if strings.HasSuffix(s, w) {
+			s = strings.TrimSuffix(s, w) + strings.ToUpper(w)
+		}
+	}
+	return s
+}
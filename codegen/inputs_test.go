@@ -0,0 +1,168 @@
+package codegen_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/codegen"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+const schema = `
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+
+	scalar Time
+	scalar Point
+
+	enum Episode {
+		NEWHOPE
+		EMPIRE
+	}
+
+	type Query {
+		hero(episode: Episode): String
+		search(text: String!, first: Int = 10, after: ID, near: Point): [String!]!
+		now: Time
+	}
+
+	type Mutation {
+		createReview(episode: Episode!, review: ReviewInput!): String
+	}
+
+	# ReviewInput is the review of a film.
+	input ReviewInput {
+		stars: Int!
+		# Commentary is optional.
+		commentary: String
+		created_at: Time
+		tags: [String]
+		episodes: [Episode!]
+		author: AuthorInput
+		userId: ID!
+	}
+
+	input AuthorInput {
+		name: String!
+	}
+`
+
+const want = `// Code generated by graphql-go codegen. DO NOT EDIT.
+
+package schema
+
+import (
+	geo "example.com/geo"
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type AuthorInput struct {
+	Name string
+}
+
+// MutationCreateReviewArgs are the arguments of Mutation.createReview.
+type MutationCreateReviewArgs struct {
+	Episode string
+	Review  ReviewInput
+}
+
+// QueryHeroArgs are the arguments of Query.hero.
+type QueryHeroArgs struct {
+	Episode *string
+}
+
+// QuerySearchArgs are the arguments of Query.search.
+type QuerySearchArgs struct {
+	Text  string
+	First int32
+	After graphql.NullID
+	Near  *geo.Point
+}
+
+// ReviewInput is the review of a film.
+type ReviewInput struct {
+	Stars int32
+	// Commentary is optional.
+	Commentary graphql.NullString
+	CreatedAt  graphql.NullTime
+	Tags       *[]*string
+	Episodes   *[]string
+	Author     *AuthorInput
+	UserID     graphql.ID
+}
+`
+
+func TestInputs(t *testing.T) {
+	s := graphql.MustParseSchema(schema, nil)
+	got, err := codegen.Inputs(s.AST(), codegen.Config{
+		Package: "schema",
+		Scalars: map[string]string{"Point": "example.com/geo.Point"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected code:\n%s", got)
+	}
+
+	_, err = codegen.Inputs(s.AST(), codegen.Config{Package: "schema"})
+	if err == nil || !strings.Contains(err.Error(), "no Go type for scalar Point") {
+		t.Errorf("expected error for unmapped scalar, got %v", err)
+	}
+}
+
+// The structs below are copied from the generated code to verify that they bind to the schema.
+type ReviewInput struct {
+	Stars      int32
+	Commentary graphql.NullString
+	CreatedAt  graphql.NullTime
+	Tags       *[]*string
+	Episodes   *[]string
+	Author     *AuthorInput
+	UserID     graphql.ID
+}
+
+type AuthorInput struct {
+	Name string
+}
+
+type MutationCreateReviewArgs struct {
+	Episode string
+	Review  ReviewInput
+}
+
+type QueryHeroArgs struct {
+	Episode *string
+}
+
+type resolver struct{}
+
+func (resolver) Hero(args QueryHeroArgs) *string { return args.Episode }
+
+func (resolver) Now() *graphql.Time { return nil }
+
+func (resolver) CreateReview(args MutationCreateReviewArgs) *string {
+	s := fmt.Sprintf("%s %d %v %s", args.Episode, args.Review.Stars, args.Review.Commentary.Set, args.Review.UserID)
+	return &s
+}
+
+func TestInputsBind(t *testing.T) {
+	s := strings.Replace(schema, "search(text: String!, first: Int = 10, after: ID, near: Point): [String!]!", "", 1)
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(s, resolver{}),
+		Query: `
+			mutation {
+				createReview(episode: EMPIRE, review: {stars: 5, commentary: null, userId: "1"})
+			}
+		`,
+		ExpectedResult: `
+			{
+				"createReview": "EMPIRE 5 true 1"
+			}
+		`,
+	})
+}
@@ -0,0 +1,194 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"unsafe"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+)
+
+// TypeCache holds parsed schemas and the resolvers compiled for them, i.e. the reflection data of
+// the resolver types and the packers of arguments, see [CompilerCache]. The zero value is ready to
+// use and a TypeCache is safe for concurrent use.
+//
+// Entries are never removed, the cache holds one entry per distinct schema string, resolver type and
+// set of options. Schemas only share an entry if their options are the same values, e.g. the same
+// directive visitors and functions rather than equal ones created per schema.
+type TypeCache struct {
+	// MaxEntries limits the number of cached compilations. Once it is reached, further schemas are
+	// compiled without being cached. Zero means no limit.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[typeCacheKey]*compiledSchema
+}
+
+type typeCacheKey struct {
+	schemaString          string
+	useStringDescriptions bool
+	resolverType          reflect.Type
+	options               [sha256.Size]byte
+}
+
+type compiledSchema struct {
+	schema  *ast.Schema
+	res     *resolvable.Schema
+	options []interface{} // the options identified by their address in the key
+}
+
+func (c *TypeCache) get(k typeCacheKey) (*compiledSchema, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	return e, ok
+}
+
+func (c *TypeCache) put(k typeCacheKey, e *compiledSchema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[typeCacheKey]*compiledSchema)
+	}
+	if c.MaxEntries > 0 && len(c.entries) >= c.MaxEntries {
+		return
+	}
+	if _, ok := c.entries[k]; !ok {
+		c.entries[k] = e
+	}
+}
+
+// CompilerCache makes schemas share the parsed schema and the compiled resolvers through c, e.g. when
+// a process hosts many tenants with structurally identical schemas. The first schema parsed with a
+// schema string and a resolver type compiles them, later ones only bind their own root resolver,
+// which saves memory and parse time. As the compilation depends on the options of the schema, only
+// schemas parsed with the same options share it, see [TypeCache]; the concurrency groups of
+// [ConcurrencyGroup] are shared, too.
+func CompilerCache(c *TypeCache) SchemaOpt {
+	return func(s *Schema) {
+		s.typeCache = c
+	}
+}
+
+// compileCached compiles the schema like compile, reusing the result of a schema parsed with the same
+// type cache, schema string, resolver type and options.
+func (s *Schema) compileCached(schemaString string, resolver interface{}) error {
+	c := s.typeCache
+	if c == nil {
+		return s.compile(schemaString, resolver)
+	}
+
+	options, values := s.compileOptions()
+	k := typeCacheKey{
+		schemaString:          schemaString,
+		useStringDescriptions: s.useStringDescriptions,
+		resolverType:          reflect.TypeOf(resolver),
+		options:               options,
+	}
+	if e, ok := c.get(k); ok {
+		if resolver == nil {
+			s.schema, s.res = e.schema, e.res
+			return nil
+		}
+		// The root resolver may return resolvers of other types for the operations than the one the
+		// schema was compiled for, which need a compilation of their own.
+		if res, err := e.res.WithRoot(resolver); err == nil {
+			s.schema, s.res = e.schema, res
+			return nil
+		}
+		return s.compile(schemaString, resolver)
+	}
+
+	if err := s.compile(schemaString, resolver); err != nil {
+		return err
+	}
+	c.put(k, &compiledSchema{schema: s.schema, res: s.res, options: values})
+	return nil
+}
+
+// compileOptions returns a hash of the options compile depends on and the values it identified by
+// their address. Functions, pointers and maps are identified by their address, as two closures of the
+// same function may capture different variables. The values are kept with the cache entry, so that
+// their addresses are not reused by other values while the entry exists.
+func (s *Schema) compileOptions() (sum [sha256.Size]byte, values []interface{}) {
+	h := sha256.New()
+	id := func(v interface{}) string {
+		values = append(values, v)
+		return identity(v)
+	}
+	fmt.Fprintf(h, "%t %t %s %s\n", s.useFieldResolvers, s.strictResolvers, id(s.nameMapper), id(s.traceLabel))
+	fmt.Fprintf(h, "fragment definition skip %t\n", s.fragmentDefinitionSkip)
+	for _, d := range s.directives {
+		fmt.Fprintf(h, "directive %s\n", id(d))
+	}
+	for _, name := range sortedKeys(s.scalarValidators) {
+		fmt.Fprintf(h, "scalar %s\n", name)
+	}
+	for _, u := range s.inputUnions {
+		fmt.Fprintf(h, "union %T %q", u.iface, u.discriminator)
+		for _, value := range sortedKeys(u.members) {
+			fmt.Fprintf(h, " %q:%T", value, u.members[value])
+		}
+		io.WriteString(h, "\n")
+	}
+	for _, typeName := range sortedKeys(s.fieldFuncs) {
+		for _, fieldName := range sortedKeys(s.fieldFuncs[typeName]) {
+			fmt.Fprintf(h, "field %s.%s %s\n", typeName, fieldName, id(s.fieldFuncs[typeName][fieldName]))
+		}
+	}
+	for _, name := range sortedKeys(s.interfaceBases) {
+		fmt.Fprintf(h, "base %s %s\n", name, id(s.interfaceBases[name]))
+	}
+	for _, name := range sortedKeys(s.concurrencyGroups) {
+		fmt.Fprintf(h, "group %q %d %q\n", name, s.concurrencyGroups[name].limit, s.concurrencyGroups[name].fields)
+	}
+	for _, coord := range sortedKeys(s.trivialFields) {
+		fmt.Fprintf(h, "trivial %s %t\n", coord, s.trivialFields[coord])
+	}
+	for _, n := range s.inputNormalizers {
+		fmt.Fprintf(h, "normalizer %s %s\n", n.typeName, id(n.fn))
+	}
+	for _, typeName := range sortedKeys(s.enumMappings) {
+		for _, value := range sortedKeys(s.enumMappings[typeName]) {
+			fmt.Fprintf(h, "enum %s.%s %s\n", typeName, value, id(s.enumMappings[typeName][value]))
+		}
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, values
+}
+
+// identity returns a string identifying v for compileOptions.
+func identity(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return "nil"
+	case reflect.Func:
+		if rv.IsNil() {
+			return rv.Type().String() + "(nil)"
+		}
+		// A func value points to its closure, which differs between closures capturing different
+		// variables, unlike the code pointer returned by reflect.Value.Pointer.
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		return fmt.Sprintf("%s@%x", rv.Type(), *(*uintptr)(unsafe.Pointer(p.Pointer())))
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Slice, reflect.UnsafePointer:
+		return fmt.Sprintf("%T@%x", v, rv.Pointer())
+	}
+	return fmt.Sprintf("%T:%#v", v, v)
+}
+
+// sortedKeys returns the keys of the map m in ascending order.
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0, reflect.ValueOf(m).Len())
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// CostEstimator is consulted after an operation has been validated and before it is executed, e.g. to
+// rate limit the clients of an API key with a token bucket by the estimated cost of their operations.
+// It is set with the [EstimateCost] schema option.
+type CostEstimator interface {
+	// EstimateCost returns an error if the operation must not be executed. The error is returned to the
+	// client instead of a result. Errors of type *errors.QueryError are returned as is, e.g. to add
+	// extensions with the time after which the client may retry.
+	EstimateCost(ctx context.Context, op *CostOperation) error
+}
+
+// CostOperation is the operation passed to a [CostEstimator].
+type CostOperation struct {
+	// Operation is the validated operation about to be executed.
+	Operation *ast.OperationDefinition
+	// Fragments are the fragments of the document, which the selections of the operation may spread.
+	Fragments ast.FragmentList
+	// Variables are the values of the variables of the operation, including their defaults.
+	Variables map[string]interface{}
+}
+
+// EstimateCost makes the schema consult e before each query, mutation and subscription is executed.
+func EstimateCost(e CostEstimator) SchemaOpt {
+	return func(s *Schema) {
+		s.costEstimator = e
+	}
+}
+
+// estimateCost returns the error of the cost estimator of the schema for op, if any.
+func (s *Schema) estimateCost(ctx context.Context, doc *ast.ExecutableDefinition, op *ast.OperationDefinition, variables map[string]interface{}) *errors.QueryError {
+	if s.costEstimator == nil {
+		return nil
+	}
+	err := s.costEstimator.EstimateCost(ctx, &CostOperation{
+		Operation: op,
+		Fragments: doc.Fragments,
+		Variables: variables,
+	})
+	if err == nil {
+		return nil
+	}
+	if qErr, ok := err.(*errors.QueryError); ok {
+		return qErr
+	}
+	return errors.Errorf("%s", err)
+}
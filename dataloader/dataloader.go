@@ -0,0 +1,81 @@
+//go:build go1.18
+
+// Package dataloader batches and caches the loading of values by key, typically to avoid separate
+// database queries for each element of a list. Loaders return a [graphql.Future] which resolvers can
+// return directly:
+//
+//	func (r *postResolver) Author(ctx context.Context) graphql.Future[*userResolver] {
+//		return loaders(ctx).users.Load(ctx, r.post.AuthorID)
+//	}
+//
+// The executor awaits futures once all concurrently running resolvers returned, so the keys of all of
+// them are loaded in one batch. A Loader caches every value for its lifetime, so a new Loader should
+// be created for each request.
+package dataloader
+
+import (
+	"context"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// BatchFunc loads the values of keys. Keys missing from the result resolve to the zero value of V.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys passed to [Loader.Load] and loads them with a single call of its BatchFunc
+// when the first of the returned futures is awaited.
+type Loader[K comparable, V any] struct {
+	fetch BatchFunc[K, V]
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+	loaded  map[K]*batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	once   sync.Once
+	values map[K]V
+	err    error
+}
+
+// New returns a Loader which loads batches of keys with fetch.
+func New[K comparable, V any](fetch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, loaded: make(map[K]*batch[K, V])}
+}
+
+// Load adds key to the next batch, unless it is already loaded or pending, and returns a future of its
+// value. ctx is passed to the BatchFunc if awaiting the future loads the batch.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) graphql.Future[V] {
+	l.mu.Lock()
+	b, ok := l.loaded[key]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &batch[K, V]{}
+		}
+		b = l.pending
+		b.keys = append(b.keys, key)
+		l.loaded[key] = b
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		b.once.Do(func() {
+			l.mu.Lock()
+			if l.pending == b {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+			b.values, b.err = l.fetch(ctx, b.keys)
+		})
+		return b.values[key], b.err
+	}
+}
+
+// Clear removes key from the cache, so the next call of Load loads it again.
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	delete(l.loaded, key)
+	l.mu.Unlock()
+}
@@ -0,0 +1,135 @@
+//go:build go1.18
+
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/dataloader"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+type author struct {
+	name string
+}
+
+func (a *author) Name() string {
+	return a.name
+}
+
+type post struct {
+	title    string
+	authorID int32
+	authors  *dataloader.Loader[int32, *author]
+}
+
+func (p *post) Title() string {
+	return p.title
+}
+
+func (p *post) Author(ctx context.Context) graphql.Future[*author] {
+	return p.authors.Load(ctx, p.authorID)
+}
+
+type query struct {
+	posts   []*post
+	mu      sync.Mutex
+	batches [][]int32
+}
+
+func (q *query) Posts() []*post {
+	return q.posts
+}
+
+func TestLoader(t *testing.T) {
+	q := &query{}
+	authors := dataloader.New(func(ctx context.Context, ids []int32) (map[int32]*author, error) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		sorted := append([]int32(nil), ids...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		q.batches = append(q.batches, sorted)
+		res := make(map[int32]*author, len(ids))
+		for _, id := range ids {
+			if id != 0 {
+				res[id] = &author{name: fmt.Sprintf("author %d", id)}
+			}
+		}
+		return res, nil
+	})
+	for i, id := range []int32{2, 1, 2, 0} {
+		q.posts = append(q.posts, &post{title: fmt.Sprintf("post %d", i), authorID: id, authors: authors})
+	}
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(`
+			type Query {
+				posts: [Post!]!
+			}
+
+			type Post {
+				title: String!
+				author: Author
+			}
+
+			type Author {
+				name: String!
+			}
+		`, q),
+		Query: `
+			{
+				posts {
+					title
+					author {
+						name
+					}
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"posts": [
+					{"title": "post 0", "author": {"name": "author 2"}},
+					{"title": "post 1", "author": {"name": "author 1"}},
+					{"title": "post 2", "author": {"name": "author 2"}},
+					{"title": "post 3", "author": null}
+				]
+			}
+		`,
+	})
+
+	if want := [][]int32{{0, 1, 2}}; !reflect.DeepEqual(q.batches, want) {
+		t.Errorf("want batches %v, got %v", want, q.batches)
+	}
+}
+
+func TestLoaderCache(t *testing.T) {
+	calls := 0
+	l := dataloader.New(func(ctx context.Context, keys []string) (map[string]int, error) {
+		calls++
+		return map[string]int{"a": len(keys)}, nil
+	})
+	ctx := context.Background()
+
+	a, b := l.Load(ctx, "a"), l.Load(ctx, "b")
+	if v, err := a.Await(); err != nil || v != 2 {
+		t.Errorf("want 2, got %d, %v", v, err)
+	}
+	if v, err := b.Await(); err != nil || v != 0 {
+		t.Errorf("want the zero value for a missing key, got %d, %v", v, err)
+	}
+	if v, _ := l.Load(ctx, "a").Await(); v != 2 || calls != 1 {
+		t.Errorf("want cached value 2 after 1 call, got %d after %d calls", v, calls)
+	}
+
+	l.Clear("a")
+	if v, _ := l.Load(ctx, "a").Await(); v != 1 || calls != 2 {
+		t.Errorf("want reloaded value 1 after 2 calls, got %d after %d calls", v, calls)
+	}
+}
@@ -0,0 +1,222 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is a custom GraphQL type to represent an arbitrary-precision decimal number, e.g. an amount
+// of money. It has to be added to a schema via "scalar Decimal". Its value is Coefficient * 10^Exponent,
+// so no precision is lost in contrast to Float. It is serialized as a string, e.g. "12.30", and
+// accepts integers, floats and strings as input.
+type Decimal struct {
+	coef *big.Int
+	exp  int32
+}
+
+// BigDecimal is implemented by third-party decimal types such as github.com/shopspring/decimal.Decimal.
+// Such values can be converted into a Decimal with [DecimalFrom] and are accepted as input.
+// A Decimal is converted back with e.g. decimal.NewFromBigInt(d.Coefficient(), d.Exponent()).
+type BigDecimal interface {
+	Coefficient() *big.Int
+	Exponent() int32
+}
+
+// NewDecimal returns the Decimal coef * 10^exp.
+func NewDecimal(coef int64, exp int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), exp: exp}
+}
+
+// DecimalFrom converts a third-party decimal value into a Decimal.
+func DecimalFrom(d BigDecimal) Decimal {
+	return Decimal{coef: new(big.Int).Set(d.Coefficient()), exp: d.Exponent()}
+}
+
+// Limits of parsed decimals. They bound the work of formatting a decimal, which grows with the
+// number of digits it has without an exponent, so that inputs like "1e50000000" are rejected.
+const (
+	maxDecimalDigits   = 1000
+	maxDecimalExponent = 1000
+)
+
+// ParseDecimal parses a decimal number such as "-12.30" or "1.5e3". Numbers with more than 1000
+// digits or an exponent beyond ±1000 are rejected.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		mantissa = s[:i]
+		exp, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+
+	digits := mantissa
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		exp -= int64(len(mantissa) - i - 1)
+	}
+	if len(digits) > maxDecimalDigits+1 || exp < -maxDecimalExponent || exp > maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("decimal %q out of range", s)
+	}
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	d := Decimal{coef: coef, exp: int32(exp)}
+	if err := d.checkRange(); err != nil {
+		return Decimal{}, err
+	}
+	return d, nil
+}
+
+// checkRange returns an error if d exceeds the limits of parsed decimals.
+func (d Decimal) checkRange() error {
+	if d.exp < -maxDecimalExponent || d.exp > maxDecimalExponent || d.coef != nil && len(d.coef.Text(10)) > maxDecimalDigits+1 {
+		return fmt.Errorf("decimal with exponent %d out of range", d.exp)
+	}
+	return nil
+}
+
+// Coefficient returns the coefficient of d.
+func (d Decimal) Coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.coef)
+}
+
+// Exponent returns the exponent of d.
+func (d Decimal) Exponent() int32 {
+	return d.exp
+}
+
+// Rat returns the value of d as a rational number.
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.Coefficient())
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(d.exp))), nil)
+	if d.exp < 0 {
+		return r.Quo(r, new(big.Rat).SetInt(scale))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(scale))
+}
+
+// String formats d without an exponent, keeping trailing zeros of the fraction, e.g. "12.30".
+func (d Decimal) String() string {
+	coef := d.Coefficient()
+	if d.exp >= 0 {
+		return coef.Mul(coef, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.exp)), nil)).String()
+	}
+
+	sign := ""
+	if coef.Sign() < 0 {
+		sign = "-"
+		coef.Neg(coef)
+	}
+	digits := coef.String()
+	scale := int(-d.exp)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// ImplementsGraphQLType maps this custom Go type
+// to the graphql scalar type in the schema.
+func (Decimal) ImplementsGraphQLType(name string) bool {
+	return name == "Decimal"
+}
+
+// UnmarshalGraphQL is a custom unmarshaler for Decimal
+//
+// This function will be called whenever you use the
+// Decimal scalar as an input
+func (d *Decimal) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		var err error
+		*d, err = ParseDecimal(input)
+		return err
+	case int32:
+		*d = NewDecimal(int64(input), 0)
+		return nil
+	case int64:
+		*d = NewDecimal(input, 0)
+		return nil
+	case float64:
+		var err error
+		*d, err = ParseDecimal(strconv.FormatFloat(input, 'g', -1, 64))
+		return err
+	case BigDecimal:
+		v := DecimalFrom(input)
+		if err := v.checkRange(); err != nil {
+			return err
+		}
+		*d = v
+		return nil
+	default:
+		return fmt.Errorf("wrong type for Decimal: %T", input)
+	}
+}
+
+// MarshalJSON is a custom marshaler for Decimal
+//
+// This function will be called whenever you
+// query for fields that use the Decimal type
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Money is a custom GraphQL type to represent an amount in a currency. It has to be added to a schema
+// via "scalar Money". It is serialized as a string of the amount followed by the ISO 4217 currency
+// code, e.g. "12.30 EUR".
+type Money struct {
+	Amount   Decimal
+	Currency string
+}
+
+// ImplementsGraphQLType maps this custom Go type
+// to the graphql scalar type in the schema.
+func (Money) ImplementsGraphQLType(name string) bool {
+	return name == "Money"
+}
+
+// UnmarshalGraphQL is a custom unmarshaler for Money
+//
+// This function will be called whenever you use the
+// Money scalar as an input
+func (m *Money) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return fmt.Errorf("wrong type for Money: %T", input)
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return fmt.Errorf("invalid money %q, expected an amount and a currency code", s)
+	}
+	amount, err := ParseDecimal(fields[0])
+	if err != nil {
+		return err
+	}
+	m.Amount, m.Currency = amount, fields[1]
+	return nil
+}
+
+// MarshalJSON is a custom marshaler for Money
+//
+// This function will be called whenever you
+// query for fields that use the Money type
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Amount.String() + " " + m.Currency)
+}
+
+func abs32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func TestDecimal_UnmarshalGraphQL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{name: "string", input: "12.30", want: "12.30"},
+		{name: "negative fraction", input: "-0.05", want: "-0.05"},
+		{name: "exponent", input: "1.5e3", want: "1500"},
+		{name: "negative exponent", input: "15E-4", want: "0.0015"},
+		{name: "limits", input: "-" + strings.Repeat("9", 1000) + "e1000", want: "-" + strings.Repeat("9", 1000) + strings.Repeat("0", 1000)},
+		{name: "large", input: "123456789012345678901234567890.123456789", want: "123456789012345678901234567890.123456789"},
+		{name: "int32", input: int32(42), want: "42"},
+		{name: "int64", input: int64(-7), want: "-7"},
+		{name: "float64", input: 0.1, want: "0.1"},
+		{name: "big decimal", input: bigDecimal{coef: big.NewInt(12345), exp: -3}, want: "12.345"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d graphql.Decimal
+			if err := d.UnmarshalGraphQL(tt.input); err != nil {
+				t.Fatalf("UnmarshalGraphQL() error = %v", err)
+			}
+			if got := d.String(); got != tt.want {
+				t.Errorf("UnmarshalGraphQL() got = %s, want = %s", got, tt.want)
+			}
+		})
+	}
+
+	outOfRange := []interface{}{"1e50000000", "1e-1001", "1e9999999999", strings.Repeat("9", 1002), bigDecimal{coef: big.NewInt(1), exp: 50000000}}
+	for _, input := range append([]interface{}{"abc", "1.2.3", "1e", "", true}, outOfRange...) {
+		var d graphql.Decimal
+		if err := d.UnmarshalGraphQL(input); err == nil {
+			t.Errorf("UnmarshalGraphQL(%#v) expected an error", input)
+		}
+	}
+}
+
+type bigDecimal struct {
+	coef *big.Int
+	exp  int32
+}
+
+func (d bigDecimal) Coefficient() *big.Int { return d.coef }
+func (d bigDecimal) Exponent() int32       { return d.exp }
+
+func TestDecimal_Rat(t *testing.T) {
+	if got, want := graphql.NewDecimal(125, -2).Rat(), big.NewRat(5, 4); got.Cmp(want) != 0 {
+		t.Errorf("Rat() got = %s, want = %s", got, want)
+	}
+	if got, want := graphql.NewDecimal(3, 2).Rat(), big.NewRat(300, 1); got.Cmp(want) != 0 {
+		t.Errorf("Rat() got = %s, want = %s", got, want)
+	}
+}
+
+func TestMoney(t *testing.T) {
+	var m graphql.Money
+	if err := m.UnmarshalGraphQL("19.90 EUR"); err != nil {
+		t.Fatalf("UnmarshalGraphQL() error = %v", err)
+	}
+	if m.Currency != "EUR" || m.Amount.String() != "19.90" {
+		t.Errorf("UnmarshalGraphQL() got = %s %s", m.Amount, m.Currency)
+	}
+
+	b, err := json.Marshal(struct {
+		Price graphql.Money    `json:"price"`
+		Tax   *graphql.Decimal `json:"tax"`
+	}{Price: m, Tax: &graphql.Decimal{}})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if got, want := string(b), `{"price":"19.90 EUR","tax":"0"}`; got != want {
+		t.Errorf("MarshalJSON() got = %s, want = %s", got, want)
+	}
+
+	if err := m.UnmarshalGraphQL("19.90"); err == nil {
+		t.Error("expected an error for a missing currency")
+	}
+}
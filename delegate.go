@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/exec"
+)
+
+// DelegatedResult is the result of a field whose selections are resolved by a remote schema, e.g.
+// by another service behind a gateway. Resolvers of fields of any type may return a *DelegatedResult
+// instead of a value resolving the type. Data is written into the response as is, and the errors,
+// whose paths start with the response key of the field in the remote response, are added to the
+// response below the path of the field. See [Delegate] for building the remote query.
+type DelegatedResult struct {
+	Data   json.RawMessage
+	Errors []*errors.QueryError
+}
+
+// DelegatedResponse returns the data and the errors of r.
+func (r *DelegatedResult) DelegatedResponse() (json.RawMessage, []*errors.QueryError) {
+	return r.Data, r.Errors
+}
+
+// Delegation is the request which forwards a field to a remote schema, see [Delegate].
+type Delegation struct {
+	// Request is the standalone query to execute with the remote schema, e.g. with package client.
+	Request     Request
+	responseKey string
+}
+
+// Delegate returns the request which forwards the field whose resolver is called with ctx to the
+// root field remoteField of a remote schema, e.g. in a gateway:
+//
+//	func (r *Resolver) User(ctx context.Context) (*graphql.DelegatedResult, error) {
+//		d, err := graphql.Delegate(ctx, "")
+//		if err != nil {
+//			return nil, err
+//		}
+//		resp, err := r.users.Exec(ctx, d.Request)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.Result(resp.Data, resp.Errors)
+//	}
+//
+// The query of the request selects remoteField with the alias, arguments, directives and selections
+// of the field and defines the variables and fragments they use. If remoteField is empty, the name of
+// the field is used. Fields of the root mutation type are delegated as mutations, all other fields as
+// queries. Delegate is only available to resolvers of fields which return a *DelegatedResult.
+func Delegate(ctx context.Context, remoteField string) (*Delegation, error) {
+	d, ok := exec.Delegate(ctx, remoteField)
+	if !ok {
+		return nil, fmt.Errorf("graphql: delegate: the resolver of the field does not return a delegated result")
+	}
+	return &Delegation{
+		Request:     Request{Query: d.Query, Variables: d.Variables},
+		responseKey: d.ResponseKey,
+	}, nil
+}
+
+// Result extracts the value of the delegated field from the data and the errors of the remote
+// response.
+func (d *Delegation) Result(data json.RawMessage, errs []*errors.QueryError) (*DelegatedResult, error) {
+	r := &DelegatedResult{Errors: errs}
+	if len(data) == 0 || string(data) == "null" {
+		return r, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("graphql: delegate: invalid data of the remote response: %w", err)
+	}
+	r.Data = fields[d.responseKey]
+	return r, nil
+}
@@ -0,0 +1,120 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+type remoteUser struct {
+	id, name string
+}
+
+func (u *remoteUser) ID() graphql.ID { return graphql.ID(u.id) }
+func (u *remoteUser) Name() string   { return u.name }
+func (u *remoteUser) Email() (*string, error) {
+	return nil, errors.New("email is private")
+}
+
+type remoteResolver struct{}
+
+func (remoteResolver) UserByID(args struct{ ID graphql.ID }) *remoteUser {
+	if args.ID != "1" {
+		return nil
+	}
+	return &remoteUser{id: "1", name: "Alice"}
+}
+
+type gatewayResolver struct {
+	remote  *graphql.Schema
+	queries []string
+}
+
+func (r *gatewayResolver) Version() string { return "1.0" }
+
+func (r *gatewayResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*graphql.DelegatedResult, error) {
+	d, err := graphql.Delegate(ctx, "userById")
+	if err != nil {
+		return nil, err
+	}
+	r.queries = append(r.queries, d.Request.Query)
+	resp := r.remote.Exec(ctx, d.Request.Query, d.Request.OperationName, d.Request.Variables)
+	return d.Result(resp.Data, resp.Errors)
+}
+
+func TestDelegate(t *testing.T) {
+	t.Parallel()
+
+	remote := graphql.MustParseSchema(`
+		type Query {
+			userById(id: ID!): User
+		}
+
+		type User {
+			id: ID!
+			name: String!
+			email: String
+		}
+	`, remoteResolver{})
+
+	gateway := &gatewayResolver{remote: remote}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			version: String!
+			user(id: ID!): User
+		}
+
+		type User {
+			id: ID!
+			name: String!
+			email: String
+		}
+	`, gateway)
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query($id: ID!, $withName: Boolean = true) {
+					version
+					alice: user(id: $id) {
+						...userFields
+						name @include(if: $withName)
+					}
+				}
+
+				fragment userFields on User {
+					id
+					... on User { email }
+				}
+			`,
+			Variables: map[string]interface{}{"id": "1"},
+			ExpectedResult: `{
+				"version": "1.0",
+				"alice": {
+					"id": "1",
+					"email": null,
+					"name": "Alice"
+				}
+			}`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:       "email is private",
+				Path:          []interface{}{"alice", "email"},
+				ResolverError: errors.New("email is private"),
+			}},
+		},
+	})
+
+	want := `query($id: ID!, $withName: Boolean = true) { alice: userById(id: $id) { ...userFields name @include(if: $withName) } } fragment userFields on User { id ... on User { email } }`
+	if len(gateway.queries) != 1 || gateway.queries[0] != want {
+		t.Errorf("got delegated queries %q, want %q", gateway.queries, want)
+	}
+
+	if _, err := graphql.Delegate(context.Background(), ""); err == nil {
+		t.Error("expected an error outside of a delegated field")
+	}
+}
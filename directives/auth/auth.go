@@ -0,0 +1,95 @@
+// Package auth provides a ready to use implementation of the @hasRole directive.
+//
+// The directive has to be declared in the schema together with a Role enum, for example:
+//
+//	directive @hasRole(role: Role!) on FIELD_DEFINITION
+//
+//	enum Role {
+//		ADMIN
+//		USER
+//	}
+//
+// and registered with the schema using the [graphql.Directives] option:
+//
+//	graphql.MustParseSchema(sdl, resolver, graphql.Directives(auth.NewHasRole(rolesFromContext)))
+//
+// Fields the caller is not authorized to access resolve to null and an error is added to the
+// response. If such a field is non-null, the null is propagated to the nearest nullable parent
+// as mandated by the GraphQL specification.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/cachecontrol"
+	"github.com/graph-gophers/graphql-go/directives"
+)
+
+// Name is the name of the directive implemented by [HasRole].
+const Name = "hasRole"
+
+// Code is the value of the "code" extension of errors returned for unauthorized fields.
+const Code = "FORBIDDEN"
+
+// RoleExtractor returns the roles of the caller making the request.
+type RoleExtractor func(ctx context.Context) []string
+
+// HasRole implements the @hasRole(role: Role!) directive.
+type HasRole struct {
+	// Role is the role required to access the field. It is set from the directive argument.
+	Role string
+
+	// Extractor returns the roles of the caller. If it is nil, every access is denied.
+	Extractor RoleExtractor
+}
+
+// NewHasRole returns a @hasRole directive implementation which authorizes callers using extract.
+func NewHasRole(extract RoleExtractor) *HasRole {
+	return &HasRole{Extractor: extract}
+}
+
+// ImplementsDirective returns the name of the directive.
+func (h *HasRole) ImplementsDirective() string {
+	return Name
+}
+
+// Resolve calls the next resolver only if the caller has the required role. As the result depends on
+// the caller, it marks the cache policy of the response private, see package cachecontrol.
+func (h *HasRole) Resolve(ctx context.Context, args interface{}, next directives.Resolver) (interface{}, error) {
+	cachecontrol.AddHint(ctx, cachecontrol.Hint{Scope: cachecontrol.ScopePrivate})
+	if !h.authorized(ctx) {
+		return nil, &ForbiddenError{Role: h.Role}
+	}
+	return next.Resolve(ctx, args)
+}
+
+func (h *HasRole) authorized(ctx context.Context) bool {
+	if h.Extractor == nil {
+		return false
+	}
+	for _, r := range h.Extractor(ctx) {
+		if strings.EqualFold(r, h.Role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForbiddenError is returned for fields the caller is not authorized to access.
+type ForbiddenError struct {
+	Role string
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("access denied, %q role required", e.Role)
+}
+
+// Extensions adds a machine-readable code to the GraphQL error.
+func (e *ForbiddenError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": Code,
+		"role": e.Role,
+	}
+}
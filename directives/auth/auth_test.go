@@ -0,0 +1,111 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/cachecontrol"
+	"github.com/graph-gophers/graphql-go/directives/auth"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+const schema = `
+	directive @hasRole(role: Role!) on FIELD_DEFINITION
+
+	enum Role {
+		ADMIN
+		USER
+	}
+
+	type Query {
+		public: String!
+		secret: String @hasRole(role: ADMIN)
+		profile: Profile
+	}
+
+	type Profile {
+		name: String!
+		email: String! @hasRole(role: ADMIN)
+	}
+`
+
+type resolver struct{}
+
+func (*resolver) Public() string    { return "public" }
+func (*resolver) Secret() *string   { s := "secret"; return &s }
+func (*resolver) Profile() *profile { return &profile{} }
+
+type profile struct{}
+
+func (*profile) Name() string  { return "Alice" }
+func (*profile) Email() string { return "alice@example.com" }
+
+type rolesKey struct{}
+
+func roles(ctx context.Context) []string {
+	r, _ := ctx.Value(rolesKey{}).([]string)
+	return r
+}
+
+func TestHasRole(t *testing.T) {
+	s := graphql.MustParseSchema(schema, &resolver{}, graphql.Directives(auth.NewHasRole(roles)))
+	admin := context.WithValue(context.Background(), rolesKey{}, []string{"admin"})
+	user := context.WithValue(context.Background(), rolesKey{}, []string{"user"})
+	ext := map[string]interface{}{"code": auth.Code, "role": "ADMIN"}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context:        admin,
+			Schema:         s,
+			Query:          `{ public secret profile { name email } }`,
+			ExpectedResult: `{"public": "public", "secret": "secret", "profile": {"name": "Alice", "email": "alice@example.com"}}`,
+		},
+		{
+			Context:        user,
+			Schema:         s,
+			Query:          `{ public secret }`,
+			ExpectedResult: `{"public": "public", "secret": null}`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: `access denied, "ADMIN" role required`, Locations: []gqlerrors.Location{{Line: 1, Column: 10}}, Path: []interface{}{"secret"}, ResolverError: &auth.ForbiddenError{Role: "ADMIN"}, Extensions: ext},
+			},
+		},
+		{
+			Context:        user,
+			Schema:         s,
+			Query:          `{ public profile { name email } }`,
+			ExpectedResult: `{"public": "public", "profile": null}`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: `access denied, "ADMIN" role required`, Locations: []gqlerrors.Location{{Line: 1, Column: 25}}, Path: []interface{}{"profile", "email"}, ResolverError: &auth.ForbiddenError{Role: "ADMIN"}, Extensions: ext},
+			},
+		},
+		{
+			Schema:         graphql.MustParseSchema(schema, &resolver{}, graphql.Directives(&auth.HasRole{})),
+			Query:          `{ secret }`,
+			ExpectedResult: `{"secret": null}`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: `access denied, "ADMIN" role required`, Locations: []gqlerrors.Location{{Line: 1, Column: 3}}, Path: []interface{}{"secret"}, ResolverError: &auth.ForbiddenError{Role: "ADMIN"}, Extensions: ext},
+			},
+		},
+	})
+}
+
+func TestHasRolePrivateCachePolicy(t *testing.T) {
+	s := graphql.MustParseSchema(cachecontrol.SDL+schema, &resolver{}, graphql.Directives(auth.NewHasRole(roles)), graphql.CacheControl(time.Minute))
+	admin := context.WithValue(context.Background(), rolesKey{}, []string{"admin"})
+
+	for q, want := range map[string]cachecontrol.Scope{
+		`{ public }`:        cachecontrol.ScopePublic,
+		`{ public secret }`: cachecontrol.ScopePrivate,
+	} {
+		resp := s.Exec(admin, q, "", nil)
+		if len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+		if policy, _ := resp.Extensions[cachecontrol.ExtensionKey].(cachecontrol.Policy); policy.Scope != want {
+			t.Errorf("%s: got scope %q, want %q", q, policy.Scope, want)
+		}
+	}
+}
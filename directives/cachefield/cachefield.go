@@ -0,0 +1,304 @@
+// Package cachefield provides an implementation of the @cacheField directive, which memoizes the
+// results of expensive resolvers for a while.
+//
+// The directive has to be declared in the schema together with its scope enum, for example:
+//
+//	directive @cacheField(ttl: String!, scope: CacheFieldScope = PUBLIC) on FIELD_DEFINITION
+//
+//	enum CacheFieldScope {
+//		PUBLIC
+//		PER_USER
+//	}
+//
+//	type User {
+//		id: ID!
+//		score: Float! @cacheField(ttl: "30s", scope: PER_USER)
+//	}
+//
+// and registered with the schema using the [graphql.Directives] option:
+//
+//	graphql.MustParseSchema(sdl, resolver, graphql.Directives(cachefield.New(cachefield.NewMemoryStore(), userFromContext)))
+//
+// Results are keyed by the field, the identity of the parent object, the arguments and, for the
+// PER_USER scope, the identity of the caller. The ttl is a Go duration such as "30s" or "5m".
+// Concurrent calls for the same key wait for a single call of the resolver, so an expired entry
+// does not cause a stampede of expensive calls. Errors are not cached.
+package cachefield
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/directives"
+)
+
+// Name is the name of the directive implemented by [CacheField].
+const Name = "cacheField"
+
+// Scopes of the cached results.
+const (
+	// ScopePublic shares the cached results between all callers.
+	ScopePublic = "PUBLIC"
+	// ScopePerUser caches the results separately for each caller.
+	ScopePerUser = "PER_USER"
+)
+
+// Store holds the cached results. Implementations must be safe for concurrent use. Values are the
+// results returned by resolvers, so a store shared between processes has to know how to serialize
+// them.
+type Store interface {
+	// Get returns the value stored for key and whether it was found.
+	Get(ctx context.Context, key string) (interface{}, bool)
+	// Set stores value for key for the duration of ttl.
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration)
+}
+
+// Identifiable is implemented by resolvers which know the identity of the object they resolve,
+// e.g. the primary key of a database row.
+type Identifiable interface {
+	CacheKey() string
+}
+
+// CacheField implements the @cacheField(ttl: String!, scope: CacheFieldScope) directive.
+type CacheField struct {
+	// TTL is the duration results are cached for. It is set from the directive argument.
+	TTL string
+	// Scope is either ScopePublic or ScopePerUser. It is set from the directive argument.
+	Scope string
+
+	// Store holds the cached results. If it is nil, nothing is cached.
+	Store Store
+
+	// User returns the identity of the caller. Results with the PER_USER scope are not cached if it
+	// is nil or returns an empty string.
+	User func(ctx context.Context) string
+
+	// ParentKey returns the identity of the object the field belongs to. If it is nil, the identity
+	// is taken from the CacheKey method of [Identifiable] resolvers or from an ID method returning a
+	// string or graphql.ID. Fields of the root operation types need no identity. Results are not
+	// cached if the identity is unknown.
+	ParentKey func(parent interface{}) (string, bool)
+
+	calls *group
+}
+
+// New returns a @cacheField directive implementation which caches the results in store. user
+// returns the identity of the caller for the PER_USER scope and may be nil.
+func New(store Store, user func(ctx context.Context) string) *CacheField {
+	return &CacheField{Store: store, User: user, calls: &group{}}
+}
+
+// ImplementsDirective returns the name of the directive.
+func (c *CacheField) ImplementsDirective() string {
+	return Name
+}
+
+// Resolve returns the cached result of the field or calls the next resolver and caches its result.
+func (c *CacheField) Resolve(ctx context.Context, args interface{}, next directives.Resolver) (interface{}, error) {
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("@%s: invalid ttl %q: %w", Name, c.TTL, err)
+	}
+	key, ok := c.key(ctx, args)
+	if !ok || c.Store == nil || ttl <= 0 {
+		return next.Resolve(ctx, args)
+	}
+	if v, ok := c.Store.Get(ctx, key); ok {
+		return v, nil
+	}
+
+	load := func() (interface{}, error) {
+		if v, ok := c.Store.Get(ctx, key); ok {
+			return v, nil
+		}
+		v, err := next.Resolve(ctx, args)
+		if err == nil {
+			c.Store.Set(ctx, key, v, ttl)
+		}
+		return v, err
+	}
+	if c.calls == nil {
+		return load()
+	}
+	return c.calls.do(key, load)
+}
+
+// key returns the cache key of the field being resolved and whether the result may be cached.
+func (c *CacheField) key(ctx context.Context, args interface{}) (string, bool) {
+	f, ok := graphql.CurrentField(ctx)
+	if !ok {
+		return "", false
+	}
+	parent := ""
+	if !f.Root {
+		if parent, ok = c.parentKey(f.Parent); !ok {
+			return "", false
+		}
+	}
+	user := ""
+	if c.Scope == ScopePerUser {
+		if c.User != nil {
+			user = c.User(ctx)
+		}
+		if user == "" {
+			return "", false
+		}
+	}
+	a, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	b, err := json.Marshal([]string{f.TypeName, f.FieldName, parent, string(a), user})
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+func (c *CacheField) parentKey(parent interface{}) (string, bool) {
+	if c.ParentKey != nil {
+		return c.ParentKey(parent)
+	}
+	if p, ok := parent.(Identifiable); ok {
+		return p.CacheKey(), true
+	}
+	m := reflect.ValueOf(parent).MethodByName("ID")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || m.Type().Out(0).Kind() != reflect.String {
+		return "", false
+	}
+	return m.Call(nil)[0].String(), true
+}
+
+// group deduplicates concurrent calls with the same key.
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+func (g *group) do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		// waiting calls fail with an error if fn panics, the panic itself is left to the caller
+		r := recover()
+		if r != nil {
+			c.val, c.err = nil, fmt.Errorf("@%s: resolver panicked: %v", Name, r)
+		}
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+		if r != nil {
+			panic(r)
+		}
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err
+}
+
+// DefaultMaxEntries is the default capacity of a [MemoryStore].
+const DefaultMaxEntries = 10000
+
+// MemoryStore is an in-memory Store. The zero value is ready to use.
+type MemoryStore struct {
+	// MaxEntries is the maximum number of entries. Once it is reached, the expired entries are removed
+	// and, if the store is still full, arbitrary entries until a quarter of it is free again. It
+	// defaults to DefaultMaxEntries.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Get implements [Store].
+func (s *MemoryStore) Get(_ context.Context, key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set implements [Store].
+func (s *MemoryStore) Set(_ context.Context, key string, value interface{}, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]memoryEntry)
+	}
+	now := time.Now()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries() {
+		s.evict(now)
+	}
+	s.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+}
+
+func (s *MemoryStore) maxEntries() int {
+	if s.MaxEntries <= 0 {
+		return DefaultMaxEntries
+	}
+	return s.MaxEntries
+}
+
+// evict makes room for new entries. Freeing a quarter of the store at once keeps the cost of the
+// sweeps low when the store is full of entries which did not expire yet.
+func (s *MemoryStore) evict(now time.Time) {
+	for k, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+	max := s.maxEntries()
+	for k := range s.entries {
+		if len(s.entries) < max-max/4 {
+			break
+		}
+		delete(s.entries, k)
+	}
+}
+
+// Len returns the number of entries in the store, including expired entries not evicted yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
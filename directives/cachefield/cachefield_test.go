@@ -0,0 +1,220 @@
+package cachefield_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/directives/cachefield"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+const schema = `
+	directive @cacheField(ttl: String!, scope: CacheFieldScope = PUBLIC) on FIELD_DEFINITION
+
+	enum CacheFieldScope {
+		PUBLIC
+		PER_USER
+	}
+
+	type Query {
+		users: [User!]!
+		total(factor: Int!): Int! @cacheField(ttl: "1m")
+	}
+
+	type User {
+		id: ID!
+		score: Int! @cacheField(ttl: "1m")
+		mood: String! @cacheField(ttl: "1m", scope: PER_USER)
+	}
+`
+
+type resolver struct {
+	calls  int32
+	delay  time.Duration
+	panics bool
+}
+
+func (r *resolver) Users() []*user {
+	return []*user{{r: r, id: "1"}, {r: r, id: "2"}, {r: r, id: "1"}}
+}
+
+func (r *resolver) Total(args struct{ Factor int32 }) int32 {
+	atomic.AddInt32(&r.calls, 1)
+	time.Sleep(r.delay)
+	if r.panics {
+		panic("total unavailable")
+	}
+	return 10 * args.Factor
+}
+
+type user struct {
+	r  *resolver
+	id graphql.ID
+}
+
+func (u *user) ID() graphql.ID { return u.id }
+
+func (u *user) Score() int32 {
+	atomic.AddInt32(&u.r.calls, 1)
+	return int32(len(u.id))
+}
+
+func (u *user) Mood(ctx context.Context) string {
+	atomic.AddInt32(&u.r.calls, 1)
+	return "happy " + callerOf(ctx)
+}
+
+type callerKey struct{}
+
+func callerOf(ctx context.Context) string {
+	c, _ := ctx.Value(callerKey{}).(string)
+	return c
+}
+
+func TestCacheField(t *testing.T) {
+	r := &resolver{}
+	store := cachefield.NewMemoryStore()
+	s := graphql.MustParseSchema(schema, r, graphql.Directives(cachefield.New(store, callerOf)))
+	alice := context.WithValue(context.Background(), callerKey{}, "alice")
+	bob := context.WithValue(context.Background(), callerKey{}, "bob")
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: alice,
+			Schema:  s,
+			Query:   `{ users { id score mood } total(factor: 2) }`,
+			ExpectedResult: `
+				{
+					"users": [
+						{"id": "1", "score": 1, "mood": "happy alice"},
+						{"id": "2", "score": 1, "mood": "happy alice"},
+						{"id": "1", "score": 1, "mood": "happy alice"}
+					],
+					"total": 20
+				}
+			`,
+		},
+		{
+			Context: bob,
+			Schema:  s,
+			Query:   `{ users { score mood } total(factor: 3) }`,
+			ExpectedResult: `
+				{
+					"users": [
+						{"score": 1, "mood": "happy bob"},
+						{"score": 1, "mood": "happy bob"},
+						{"score": 1, "mood": "happy bob"}
+					],
+					"total": 30
+				}
+			`,
+		},
+	})
+
+	// alice: 2 scores, 2 moods, 1 total; bob: 2 moods, 1 total with another argument
+	if got := atomic.LoadInt32(&r.calls); got != 8 {
+		t.Errorf("got %d resolver calls, want 8", got)
+	}
+	if got := store.Len(); got != 8 {
+		t.Errorf("got %d cache entries, want 8", got)
+	}
+}
+
+func TestCacheField_PerUserWithoutIdentity(t *testing.T) {
+	r := &resolver{}
+	s := graphql.MustParseSchema(schema, r, graphql.Directives(cachefield.New(cachefield.NewMemoryStore(), callerOf)))
+	for i := 0; i < 2; i++ {
+		res := s.Exec(context.Background(), `{ users { mood } }`, "", nil)
+		if len(res.Errors) != 0 {
+			t.Fatal(res.Errors)
+		}
+	}
+	if got := atomic.LoadInt32(&r.calls); got != 6 {
+		t.Errorf("got %d resolver calls, want 6 since anonymous results must not be cached", got)
+	}
+}
+
+func TestCacheField_Stampede(t *testing.T) {
+	r := &resolver{delay: 20 * time.Millisecond}
+	s := graphql.MustParseSchema(schema, r, graphql.Directives(cachefield.New(cachefield.NewMemoryStore(), nil)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := s.Exec(context.Background(), `{ total(factor: 1) }`, "", nil)
+			if string(res.Data) != `{"total":10}` {
+				t.Errorf("unexpected response: %s %v", res.Data, res.Errors)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&r.calls); got != 1 {
+		t.Errorf("got %d resolver calls, want 1", got)
+	}
+}
+
+func TestCacheField_InvalidTTL(t *testing.T) {
+	sdl := `
+		directive @cacheField(ttl: String!, scope: CacheFieldScope = PUBLIC) on FIELD_DEFINITION
+		enum CacheFieldScope { PUBLIC PER_USER }
+		type Query { total(factor: Int!): Int! @cacheField(ttl: "soon") }
+	`
+	s := graphql.MustParseSchema(sdl, &resolver{}, graphql.Directives(cachefield.New(cachefield.NewMemoryStore(), nil)))
+	res := s.Exec(context.Background(), `{ total(factor: 1) }`, "", nil)
+	if want := fmt.Sprintf(`@%s: invalid ttl "soon": time: invalid duration "soon"`, cachefield.Name); len(res.Errors) != 1 || res.Errors[0].Message != want {
+		t.Errorf("got errors %v, want %q", res.Errors, want)
+	}
+}
+
+func TestCacheField_PanicWhileWaiting(t *testing.T) {
+	r := &resolver{delay: 20 * time.Millisecond, panics: true}
+	s := graphql.MustParseSchema(schema, r, graphql.Directives(cachefield.New(cachefield.NewMemoryStore(), nil)))
+
+	var wg sync.WaitGroup
+	var waiterErrors int32
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := s.Exec(context.Background(), `{ total(factor: 1) }`, "", nil)
+			if len(res.Errors) == 0 {
+				t.Errorf("got response %s without errors", res.Data)
+				return
+			}
+			if strings.Contains(res.Errors[0].Message, "resolver panicked: total unavailable") {
+				atomic.AddInt32(&waiterErrors, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if atomic.LoadInt32(&r.calls) == 1 && atomic.LoadInt32(&waiterErrors) != 1 {
+		t.Errorf("the waiting call did not fail with the panic of the resolver")
+	}
+}
+
+func TestMemoryStore_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	store := &cachefield.MemoryStore{MaxEntries: 8}
+	store.Set(ctx, "expired", 0, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	for i := 0; i < 100; i++ {
+		store.Set(ctx, fmt.Sprint(i), i, time.Minute)
+		if store.Len() > 8 {
+			t.Fatalf("got %d entries, want at most 8", store.Len())
+		}
+	}
+	if _, ok := store.Get(ctx, "99"); !ok {
+		t.Error("the last entry was evicted")
+	}
+	if _, ok := store.Get(ctx, "expired"); ok {
+		t.Error("the expired entry was not evicted")
+	}
+}
@@ -0,0 +1,288 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// Resolvers returns a schema option which resolves every field of schemaString with a @rest
+// directive by sending the request described by the directive with c, so that these fields need no
+// resolver:
+//
+//	opt, err := (&rest.Client{BaseURL: "https://api.example.com"}).Resolvers(sdl)
+//	if err != nil {
+//		return err
+//	}
+//	schema := graphql.MustParseSchema(sdl, &struct{}{}, opt)
+//
+// The fields of the JSON response which are selected by the query are written into the GraphQL
+// response, so the names of the JSON fields have to match the schema. Abstract types are resolved
+// with the __typename field of the JSON objects, if any. Fields of the objects returned by a @rest
+// field are taken from the JSON response, even if they have a @rest directive themselves.
+//
+// Variables of placeholders which are not given are replaced with null. Resolvers returns an error if
+// the arguments of a directive are invalid or if a placeholder names an unknown field argument.
+func (c *Client) Resolvers(schemaString string) (graphql.SchemaOpt, error) {
+	schema, err := graphql.ParseSchemaDocument(schemaString, graphql.ParserOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []graphql.SchemaOpt
+	for _, typeName := range sortedTypeNames(schema) {
+		t, ok := schema.Types[typeName].(*ast.ObjectTypeDefinition)
+		if !ok {
+			continue
+		}
+		for _, f := range t.Fields {
+			d := f.Directives.Get(Name)
+			if d == nil {
+				continue
+			}
+			req, err := parseDirective(d, f.Arguments)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name, f.Name, err)
+			}
+			opts = append(opts, graphql.FieldFunc(t.Name, f.Name, c.resolver(schema, f, req)))
+		}
+	}
+	return func(s *graphql.Schema) {
+		for _, opt := range opts {
+			opt(s)
+		}
+	}, nil
+}
+
+// resolver returns the function which resolves the field f by sending req.
+func (c *Client) resolver(schema *ast.Schema, f *ast.FieldDefinition, req *request) interface{} {
+	resolve := func(ctx context.Context, args map[string]interface{}) (*graphql.DelegatedResult, error) {
+		d, err := graphql.Delegate(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		var data json.RawMessage
+		if err := c.send(ctx, req, argValues(args), &data); err != nil {
+			return nil, err
+		}
+		if len(data) == 0 {
+			return &graphql.DelegatedResult{}, nil
+		}
+		b, err := project(schema, d, f.Type, data)
+		if err != nil {
+			return nil, err
+		}
+		return &graphql.DelegatedResult{Data: b}, nil
+	}
+	if len(f.Arguments) == 0 {
+		return func(ctx context.Context) (*graphql.DelegatedResult, error) {
+			return resolve(ctx, nil)
+		}
+	}
+	return resolve
+}
+
+// project returns the values of the JSON document data selected by the delegated field d of type t.
+func project(schema *ast.Schema, d *graphql.Delegation, t ast.Type, data []byte) ([]byte, error) {
+	doc, qErr := graphql.ParseQuery(d.Request.Query, graphql.ParserOptions{})
+	if qErr != nil {
+		return nil, qErr
+	}
+	field := doc.Operations[0].Selections[0].(*ast.Field)
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("rest: invalid response: %w", err)
+	}
+
+	p := &projector{schema: schema, fragments: doc.Fragments, vars: d.Request.Variables}
+	if err := p.value(t, v, field.SelectionSet); err != nil {
+		return nil, err
+	}
+	return p.buf.Bytes(), nil
+}
+
+// projector writes the values of a JSON document which are selected by a query.
+type projector struct {
+	schema    *ast.Schema
+	fragments ast.FragmentList
+	vars      map[string]interface{}
+	buf       bytes.Buffer
+}
+
+func (p *projector) value(t ast.Type, v interface{}, sels ast.SelectionSet) error {
+	if v == nil {
+		p.buf.WriteString("null")
+		return nil
+	}
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return p.value(t.OfType, v, sels)
+	case *ast.List:
+		list, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("rest: expected a list of %s in the response, got %T", t.OfType, v)
+		}
+		p.buf.WriteByte('[')
+		for i, elem := range list {
+			if i > 0 {
+				p.buf.WriteByte(',')
+			}
+			if err := p.value(t.OfType, elem, sels); err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte(']')
+		return nil
+	}
+
+	if len(sels) == 0 {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		p.buf.Write(b)
+		return nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("rest: expected an object of type %s in the response, got %T", t, v)
+	}
+	typeName := t.(ast.NamedType).TypeName()
+	if name, ok := obj["__typename"].(string); ok {
+		if _, ok := p.schema.Types[name].(*ast.ObjectTypeDefinition); ok {
+			typeName = name
+		}
+	}
+
+	var keys []string
+	fields := make(map[string][]*ast.Field)
+	p.collect(typeName, sels, &keys, fields)
+	p.buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			p.buf.WriteByte(',')
+		}
+		b, _ := json.Marshal(key)
+		p.buf.Write(b)
+		p.buf.WriteByte(':')
+
+		name := fields[key][0].Name.Name
+		if name == "__typename" {
+			b, _ := json.Marshal(typeName)
+			p.buf.Write(b)
+			continue
+		}
+		fd := p.field(typeName, name)
+		if fd == nil {
+			return fmt.Errorf("rest: unknown field %q of type %s", name, typeName)
+		}
+		var fieldSels ast.SelectionSet
+		for _, f := range fields[key] {
+			fieldSels = append(fieldSels, f.SelectionSet...)
+		}
+		if err := p.value(fd.Type, obj[name], fieldSels); err != nil {
+			return err
+		}
+	}
+	p.buf.WriteByte('}')
+	return nil
+}
+
+// collect adds the fields of sels which apply to an object of the type typeName to fields, keyed by
+// their response keys in the order of keys.
+func (p *projector) collect(typeName string, sels ast.SelectionSet, keys *[]string, fields map[string][]*ast.Field) {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if p.skip(sel.Directives) {
+				continue
+			}
+			key := sel.Alias.Name
+			if _, ok := fields[key]; !ok {
+				*keys = append(*keys, key)
+			}
+			fields[key] = append(fields[key], sel)
+		case *ast.InlineFragment:
+			if p.skip(sel.Directives) || !p.applies(typeName, sel.On.Name) {
+				continue
+			}
+			p.collect(typeName, sel.Selections, keys, fields)
+		case *ast.FragmentSpread:
+			frag := p.fragments.Get(sel.Name.Name)
+			if p.skip(sel.Directives) || frag == nil || !p.applies(typeName, frag.On.Name) {
+				continue
+			}
+			p.collect(typeName, frag.Selections, keys, fields)
+		}
+	}
+}
+
+// skip evaluates the @skip and @include directives of a selection.
+func (p *projector) skip(ds ast.DirectiveList) bool {
+	for _, d := range ds {
+		v, ok := d.Arguments.Get("if")
+		if !ok {
+			continue
+		}
+		b, _ := v.Deserialize(p.vars).(bool)
+		if d.Name.Name == "skip" && b || d.Name.Name == "include" && !b {
+			return true
+		}
+	}
+	return false
+}
+
+// applies reports whether a fragment on the type condition applies to an object of the type
+// typeName, which is abstract if the response does not tell the type of the object.
+func (p *projector) applies(typeName, condition string) bool {
+	if condition == "" || condition == typeName {
+		return true
+	}
+	obj, ok := p.schema.Types[typeName].(*ast.ObjectTypeDefinition)
+	if !ok {
+		return true
+	}
+	switch t := p.schema.Types[condition].(type) {
+	case *ast.InterfaceTypeDefinition:
+		for _, iface := range obj.Interfaces {
+			if iface.Name == t.Name {
+				return true
+			}
+		}
+	case *ast.Union:
+		for _, member := range t.UnionMemberTypes {
+			if member.Name == obj.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// field returns the definition of the field name of the type typeName.
+func (p *projector) field(typeName, name string) *ast.FieldDefinition {
+	switch t := p.schema.Types[typeName].(type) {
+	case *ast.ObjectTypeDefinition:
+		return t.Fields.Get(name)
+	case *ast.InterfaceTypeDefinition:
+		return t.Fields.Get(name)
+	}
+	return nil
+}
+
+func sortedTypeNames(schema *ast.Schema) []string {
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
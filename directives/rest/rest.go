@@ -0,0 +1,338 @@
+// Package rest resolves fields by calling REST APIs described by the @rest directive, which allows
+// gateway-style backends to be built with little code.
+//
+// The directive has to be declared in the schema and applied to fields, for example:
+//
+//	directive @rest(method: String = "GET", url: String!, body: String) on FIELD_DEFINITION
+//
+//	type Query {
+//		user(id: ID!): User @rest(url: "/users/{id}")
+//	}
+//
+//	type Mutation {
+//		rename(id: ID!, name: String!): User @rest(method: "PATCH", url: "/users/{id}", body: "{\"name\": {name}}")
+//	}
+//
+// Placeholders such as {id} are replaced with the value of the field argument of the same name and
+// placeholders such as {$id} with the value of the variable of the request. In the path of the URL
+// the value is escaped as a path segment, where "." and ".." are rejected, in the query of the URL it
+// is escaped as a query component and in the body it is encoded as JSON.
+//
+// The fields with the directive are resolved automatically with the schema option returned by
+// [Client.Resolvers], which writes the selected fields of the JSON response into the GraphQL
+// response. Resolvers which need to process the response decode it into their result with
+// [Client.Do] instead:
+//
+//	func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*User, error) {
+//		var u *User
+//		return u, r.rest.Do(ctx, args, &u)
+//	}
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// Name is the name of the directive executed by [Client].
+const Name = "rest"
+
+// Client executes the @rest directive of the field being resolved.
+type Client struct {
+	// BaseURL is prepended to relative URLs of the directive.
+	BaseURL string
+
+	// HTTPClient sends the requests. If it is nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Header returns additional headers for a request, e.g. to forward authorization. It may be nil.
+	Header func(ctx context.Context) http.Header
+
+	// MaxResponseSize is the maximum size of response bodies in bytes. Larger responses fail the
+	// field. It defaults to DefaultMaxResponseSize.
+	MaxResponseSize int64
+}
+
+// DefaultMaxResponseSize is the default of [Client.MaxResponseSize].
+const DefaultMaxResponseSize = 10 << 20
+
+// StatusError is returned for responses with a status code other than 2xx.
+type StatusError struct {
+	StatusCode int
+	Method     string
+	URL        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %d", e.Method, e.URL, e.StatusCode)
+}
+
+// Extensions adds the status code to the GraphQL error.
+func (e *StatusError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   "REST_ERROR",
+		"status": e.StatusCode,
+	}
+}
+
+var placeholder = regexp.MustCompile(`\{(\$?\w+)\}`)
+
+var methodToken = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// request is the request described by a @rest directive.
+type request struct {
+	method, url, body string
+}
+
+// parseDirective checks the arguments of the @rest directive d. If args is not nil, the placeholders
+// which are not variables have to name one of them.
+func parseDirective(d *ast.Directive, args ast.ArgumentsDefinition) (*request, error) {
+	req := &request{method: "GET"}
+	str := func(name string, required bool) (string, error) {
+		v, ok := d.ArgumentValue(name)
+		if !ok {
+			if required {
+				return "", fmt.Errorf("rest: missing argument %q of @%s", name, Name)
+			}
+			return "", nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("rest: argument %q of @%s must be a String, got %T", name, Name, v)
+		}
+		return s, nil
+	}
+	var err error
+	if req.url, err = str("url", true); err != nil {
+		return nil, err
+	}
+	if req.url == "" {
+		return nil, fmt.Errorf("rest: argument \"url\" of @%s must not be empty", Name)
+	}
+	method, err := str("method", false)
+	if err != nil {
+		return nil, err
+	}
+	if method != "" {
+		if !methodToken.MatchString(method) {
+			return nil, fmt.Errorf("rest: invalid method %q of @%s", method, Name)
+		}
+		req.method = strings.ToUpper(method)
+	}
+	if req.body, err = str("body", false); err != nil {
+		return nil, err
+	}
+
+	if args != nil {
+		for _, s := range []string{req.url, req.body} {
+			for _, m := range placeholder.FindAllStringSubmatch(s, -1) {
+				if !strings.HasPrefix(m[1], "$") && args.Get(m[1]) == nil {
+					return nil, fmt.Errorf("rest: unknown argument %q in %q", m[1], s)
+				}
+			}
+		}
+	}
+	return req, nil
+}
+
+// Do sends the request described by the @rest directive of the field being resolved with ctx and
+// decodes the JSON response into out. args are the arguments of the field, usually the args struct
+// of the resolver, or nil.
+func (c *Client) Do(ctx context.Context, args interface{}, out interface{}) error {
+	d := graphql.FieldDirectives(ctx).Get(Name)
+	if d == nil {
+		return fmt.Errorf("rest: field has no @%s directive", Name)
+	}
+	req, err := parseDirective(d, nil)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, req, argValues(args), out)
+}
+
+// send sends req with the placeholders replaced by values and the variables of the request and
+// decodes the JSON response into out.
+func (c *Client) send(ctx context.Context, r *request, values map[string]interface{}, out interface{}) error {
+	if f, ok := graphql.CurrentField(ctx); ok {
+		for name, v := range f.Variables {
+			values["$"+name] = v
+		}
+	}
+	u, err := expandURL(r.url, values)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(u, "://") {
+		u = strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.TrimPrefix(u, "/")
+	}
+
+	var reqBody io.Reader
+	if r.body != "" {
+		b, err := expand(r.body, values, func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		})
+		if err != nil {
+			return err
+		}
+		reqBody = strings.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Header != nil {
+		for k, vs := range c.Header(ctx) {
+			for _, v := range vs {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode, Method: r.method, URL: u}
+	}
+
+	max := c.MaxResponseSize
+	if max <= 0 {
+		max = DefaultMaxResponseSize
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > max {
+		return fmt.Errorf("rest: response of %s %s exceeds the maximum size of %d bytes", r.method, u, max)
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// expandURL replaces the placeholders in the URL template s. Values are escaped as path segments
+// before the query and as query components after it.
+func expandURL(s string, values map[string]interface{}) (string, error) {
+	path, query := s, ""
+	if i := strings.Index(s, "?"); i != -1 {
+		path, query = s[:i], s[i:]
+	}
+	path, err := expand(path, values, func(v interface{}) (string, error) {
+		if v == nil {
+			return "", nil
+		}
+		seg := fmt.Sprint(v)
+		if seg == "." || seg == ".." {
+			return "", fmt.Errorf("rest: invalid path segment %q", seg)
+		}
+		return url.PathEscape(seg), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	query, err = expand(query, values, func(v interface{}) (string, error) {
+		if v == nil {
+			return "", nil
+		}
+		return url.QueryEscape(fmt.Sprint(v)), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return path + query, nil
+}
+
+// expand replaces the placeholders in s with the formatted values of the arguments and, for
+// placeholders starting with $, of the variables.
+func expand(s string, values map[string]interface{}, format func(interface{}) (string, error)) (string, error) {
+	var err error
+	res := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := m[1 : len(m)-1]
+		var v interface{}
+		if strings.HasPrefix(name, "$") {
+			// variables which are not given are templated as null
+			v = values[name]
+		} else {
+			var ok bool
+			if v, ok = values[normalize(name)]; !ok {
+				if err == nil {
+					err = fmt.Errorf("rest: unknown argument %q in %q", name, s)
+				}
+				return m
+			}
+		}
+		f, ferr := format(v)
+		if ferr != nil && err == nil {
+			err = ferr
+		}
+		return f
+	})
+	return res, err
+}
+
+// argValues returns the values of the fields of the args struct keyed by their normalized names.
+func argValues(args interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	if m, ok := args.(map[string]interface{}); ok {
+		for k, v := range m {
+			values[normalize(k)] = v
+		}
+		return values
+	}
+
+	v := reflect.ValueOf(args)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return values
+	}
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := v.Field(i)
+		for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		if (fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface) && fv.IsNil() {
+			values[normalize(sf.Name)] = nil
+			continue
+		}
+		values[normalize(sf.Name)] = fv.Interface()
+	}
+	return values
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
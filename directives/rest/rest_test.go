@@ -0,0 +1,294 @@
+package rest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/directives/rest"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+const schema = `
+	directive @rest(method: String = "GET", url: String!, body: String) on FIELD_DEFINITION
+
+	type Query {
+		user(id: ID!): User @rest(url: "/users/{id}")
+		missing: User @rest(url: "/missing")
+	}
+
+	type Mutation {
+		rename(id: ID!, name: String!): User @rest(method: "PATCH", url: "/users/{id}", body: "{\"name\": {name}}")
+	}
+
+	type User {
+		id: ID!
+		name: String!
+	}
+`
+
+type user struct {
+	ID   graphql.ID `json:"id"`
+	Name string     `json:"name"`
+}
+
+type resolver struct {
+	rest *rest.Client
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*user, error) {
+	var u *user
+	return u, r.rest.Do(ctx, args, &u)
+}
+
+func (r *resolver) Missing(ctx context.Context) (*user, error) {
+	var u *user
+	return u, r.rest.Do(ctx, nil, &u)
+}
+
+func (r *resolver) Rename(ctx context.Context, args struct {
+	ID   graphql.ID
+	Name string
+}) (*user, error) {
+	var u *user
+	return u, r.rest.Do(ctx, args, &u)
+}
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.EscapedPath() == "/users/a%2Fb":
+			w.Write([]byte(`{"id": "a/b", "name": "Alice"}`))
+		case r.Method == "PATCH" && r.URL.Path == "/users/1":
+			if r.Header.Get("Authorization") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			b, _ := io.ReadAll(r.Body)
+			w.Write([]byte(`{"id": "1", "name": ` + string(b)[len(`{"name": `):]))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := &rest.Client{
+		BaseURL: srv.URL,
+		Header: func(ctx context.Context) http.Header {
+			return http.Header{"Authorization": []string{"secret"}}
+		},
+	}
+	s := graphql.MustParseSchema(schema, &resolver{rest: client}, graphql.UseFieldResolvers())
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         s,
+			Query:          `{ user(id: "a/b") { id name } }`,
+			ExpectedResult: `{"user": {"id": "a/b", "name": "Alice"}}`,
+		},
+		{
+			Schema:         s,
+			Query:          `mutation { rename(id: "1", name: "Bob \"B\"") { id name } }`,
+			ExpectedResult: `{"rename": {"id": "1", "name": "Bob \"B\""}}`,
+		},
+		{
+			Schema:         s,
+			Query:          `{ missing { id } }`,
+			ExpectedResult: `{"missing": null}`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "GET " + srv.URL + "/missing: unexpected status 404",
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 3}},
+					Path:          []interface{}{"missing"},
+					ResolverError: &rest.StatusError{StatusCode: 404, Method: "GET", URL: srv.URL + "/missing"},
+					Extensions:    map[string]interface{}{"code": "REST_ERROR", "status": 404},
+				},
+			},
+		},
+	})
+}
+
+func TestResolvers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/users/1" && r.URL.Query().Get("age") == "true":
+			w.Write([]byte(`{"id": "1", "name": "Alice", "age": 42, "password": "secret", "friends": [{"__typename": "User", "id": "2", "name": "Bob"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/users":
+			w.Write([]byte(`[{"id": "1", "name": "Alice"}, {"id": "2", "name": "Bob"}]`))
+		case r.Method == "PATCH" && r.URL.Path == "/users/1":
+			b, _ := io.ReadAll(r.Body)
+			w.Write([]byte(`{"id": "1", "name": ` + string(b)[len(`{"name": `):]))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	const sdl = `
+		directive @rest(method: String = "GET", url: String!, body: String) on FIELD_DEFINITION
+
+		type Query {
+			user(id: ID!): User @rest(url: "/users/{id}?age={$withAge}")
+			users: [User!]! @rest(url: "/users")
+			missing: User @rest(url: "/missing")
+		}
+
+		type Mutation {
+			rename(id: ID!, name: String! = "Bob"): User @rest(method: "patch", url: "/users/{id}", body: "{\"name\": {name}}")
+		}
+
+		interface Node {
+			id: ID!
+		}
+
+		type User implements Node {
+			id: ID!
+			name: String!
+			age: Int
+			friends: [User!]
+		}
+	`
+	opt, err := (&rest.Client{BaseURL: srv.URL}).Resolvers(sdl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := graphql.MustParseSchema(sdl, &struct{}{}, opt)
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: s,
+			Query: `
+				query($id: ID!, $withAge: Boolean!) {
+					user(id: $id) {
+						__typename
+						n: name
+						age @include(if: $withAge)
+						... on Node { id }
+						friends { ...friend }
+					}
+				}
+				fragment friend on User { name }
+			`,
+			Variables:      map[string]interface{}{"id": "1", "withAge": true},
+			ExpectedResult: `{"user": {"__typename": "User", "n": "Alice", "age": 42, "id": "1", "friends": [{"name": "Bob"}]}}`,
+		},
+		{
+			Schema:         s,
+			Query:          `{ users { name } }`,
+			ExpectedResult: `{"users": [{"name": "Alice"}, {"name": "Bob"}]}`,
+		},
+		{
+			Schema:         s,
+			Query:          `mutation { rename(id: "1") { name } }`,
+			ExpectedResult: `{"rename": {"name": "Bob"}}`,
+		},
+		{
+			Schema:         s,
+			Query:          `{ missing { id } }`,
+			ExpectedResult: `{"missing": null}`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "GET " + srv.URL + "/missing: unexpected status 404",
+					Locations:     []gqlerrors.Location{{Line: 1, Column: 3}},
+					Path:          []interface{}{"missing"},
+					ResolverError: &rest.StatusError{StatusCode: 404, Method: "GET", URL: srv.URL + "/missing"},
+					Extensions:    map[string]interface{}{"code": "REST_ERROR", "status": 404},
+				},
+			},
+		},
+	})
+}
+
+func TestResolvers_InvalidDirective(t *testing.T) {
+	for _, tt := range []struct {
+		directive string
+		field     string
+		want      string
+	}{
+		{
+			directive: `directive @rest(method: String = "GET", url: String!, body: String) on FIELD_DEFINITION`,
+			field:     `user(id: ID!): String @rest(url: "/users/{userId}")`,
+			want:      `Query.user: rest: unknown argument "userId" in "/users/{userId}"`,
+		},
+		{
+			directive: `directive @rest(method: Int, url: String!) on FIELD_DEFINITION`,
+			field:     `user: String @rest(method: 1, url: "/user")`,
+			want:      `Query.user: rest: argument "method" of @rest must be a String, got int32`,
+		},
+		{
+			directive: `directive @rest(method: String, url: String!) on FIELD_DEFINITION`,
+			field:     `user: String @rest(method: "GET /", url: "/user")`,
+			want:      `Query.user: rest: invalid method "GET /" of @rest`,
+		},
+		{
+			directive: `directive @rest(url: String) on FIELD_DEFINITION`,
+			field:     `user: String @rest`,
+			want:      `Query.user: rest: missing argument "url" of @rest`,
+		},
+	} {
+		sdl := tt.directive + "\ntype Query { " + tt.field + " }"
+		if _, err := (&rest.Client{}).Resolvers(sdl); err == nil || err.Error() != tt.want {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestResolvers_Escaping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/users" && r.URL.RawQuery == "q=a%26admin%3Dtrue&limit=1":
+			w.Write([]byte(`[{"name": "Alice"}]`))
+		case r.URL.Path == "/large":
+			w.Write([]byte(`{"name": "` + strings.Repeat("a", 100) + `"}`))
+		default:
+			w.Write([]byte(`{"name": "secret"}`))
+		}
+	}))
+	defer srv.Close()
+
+	const sdl = `
+		directive @rest(method: String = "GET", url: String!, body: String) on FIELD_DEFINITION
+
+		type Query {
+			profile(id: ID!): User @rest(url: "/users/{id}/profile")
+			search(q: String!): [User!]! @rest(url: "/users?q={q}&limit=1")
+			large: User @rest(url: "/large")
+		}
+
+		type User {
+			name: String!
+		}
+	`
+	opt, err := (&rest.Client{BaseURL: srv.URL, MaxResponseSize: 64}).Resolvers(sdl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := graphql.MustParseSchema(sdl, &struct{}{}, opt)
+
+	for _, tt := range []struct {
+		query   string
+		data    string
+		wantErr string
+	}{
+		{query: `{ search(q: "a&admin=true") { name } }`, data: `{"search":[{"name":"Alice"}]}`},
+		{query: `{ profile(id: "..") { name } }`, data: `{"profile":null}`, wantErr: `rest: invalid path segment ".."`},
+		{query: `{ profile(id: ".") { name } }`, data: `{"profile":null}`, wantErr: `rest: invalid path segment "."`},
+		{query: `{ large { name } }`, data: `{"large":null}`, wantErr: "exceeds the maximum size of 64 bytes"},
+	} {
+		resp := s.Exec(context.Background(), tt.query, "", nil)
+		if string(resp.Data) != tt.data {
+			t.Errorf("%s: got data %s, want %s", tt.query, resp.Data, tt.data)
+		}
+		switch {
+		case tt.wantErr == "" && len(resp.Errors) != 0:
+			t.Errorf("%s: unexpected errors %v", tt.query, resp.Errors)
+		case tt.wantErr != "" && (len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.wantErr)):
+			t.Errorf("%s: got errors %v, want %q", tt.query, resp.Errors, tt.wantErr)
+		}
+	}
+}
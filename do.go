@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Request is a GraphQL request as sent by a client.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ResultIterator iterates over the responses of a request executed with [Schema.Do]. Queries and
+// mutations produce a single response, subscriptions a response per event.
+type ResultIterator interface {
+	// Next blocks until the next response is available and returns it. It returns false once all
+	// responses have been returned or the iterator has been closed.
+	Next() (*Response, bool)
+
+	// Close stops the iterator. A subscription is cancelled and no further resolvers are called.
+	// It is safe to call Close multiple times and after all responses have been returned.
+	Close()
+}
+
+// Do executes req with the schema's resolver. Queries and mutations are executed like with
+// [Schema.Exec] and subscriptions like with [Schema.Subscribe], which allows transports to handle
+// every kind of operation the same way:
+//
+//	it, err := schema.Do(ctx, req)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for resp, ok := it.Next(); ok; resp, ok = it.Next() {
+//		send(resp)
+//	}
+//
+// It returns an error if the schema was created without a resolver.
+func (s *Schema) Do(ctx context.Context, req Request) (ResultIterator, error) {
+	if !s.CanExec() {
+		return nil, errors.New("schema created without resolver, can not exec")
+	}
+	doc, qErr := s.parseRequest(ctx, req.Query)
+	if qErr != nil {
+		return &singleIterator{resp: &Response{Errors: []*qerrors.QueryError{qErr}}}, nil
+	}
+	if s.isSubscription(doc, req.OperationName) {
+		ctx, cancel := context.WithCancel(ctx)
+		c, err := s.subscribeRequest(ctx, doc, req.Query, req.OperationName, req.Variables)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return &subscriptionIterator{ctx: ctx, c: c, cancel: cancel}, nil
+	}
+	return &singleIterator{resp: s.execWithStats(ctx, doc, req.Query, req.OperationName, req.Variables, s.res)}, nil
+}
+
+// isSubscription reports whether the operation operationName of doc is a subscription or a live
+// query. Invalid operations are not subscriptions, so their errors are reported by exec.
+func (s *Schema) isSubscription(doc *ast.ExecutableDefinition, operationName string) bool {
+	op, opErr := getOperation(doc, operationName)
+	return opErr == nil && (op.Type == query.Subscription || s.isLive(op))
+}
+
+type singleIterator struct {
+	resp *Response
+}
+
+func (it *singleIterator) Next() (*Response, bool) {
+	resp := it.resp
+	it.resp = nil
+	return resp, resp != nil
+}
+
+func (it *singleIterator) Close() {
+	it.resp = nil
+}
+
+type subscriptionIterator struct {
+	ctx    context.Context
+	c      <-chan interface{}
+	cancel context.CancelFunc
+}
+
+func (it *subscriptionIterator) Next() (*Response, bool) {
+	if it.ctx.Err() != nil {
+		return nil, false
+	}
+	select {
+	case v, ok := <-it.c:
+		if !ok {
+			return nil, false
+		}
+		return v.(*Response), true
+	case <-it.ctx.Done():
+		return nil, false
+	}
+}
+
+func (it *subscriptionIterator) Close() {
+	it.cancel()
+}
@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Encoder marshals values into JSON. It allows a faster JSON library, e.g. github.com/goccy/go-json,
+// to be used where encoding dominates the CPU usage of a service. It is set with the [WithEncoder]
+// schema option. Values implementing json.Marshaler, e.g. types generated by easyjson, have to be
+// supported by the encoder.
+type Encoder interface {
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// JSONEncoder is the default [Encoder], which uses package encoding/json.
+type JSONEncoder struct{}
+
+// Marshal returns the encoding/json encoding of v.
+func (JSONEncoder) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// WithEncoder makes the schema encode the values of scalar fields and the responses encoded with
+// [Schema.MarshalResponse] and [Schema.WriteResponse] with enc. It defaults to [JSONEncoder], which
+// is also used if enc is nil.
+func WithEncoder(enc Encoder) SchemaOpt {
+	return func(s *Schema) {
+		if enc == nil {
+			enc = JSONEncoder{}
+		}
+		s.encoder = enc
+	}
+}
+
+// MarshalResponse encodes resp with the encoder of the schema.
+func (s *Schema) MarshalResponse(resp *Response) ([]byte, error) {
+	return s.encoder.Marshal(resp)
+}
+
+// ResponseFormat makes [Schema.MarshalResponseFor] encode responses with enc for requests accepting
+// the media type contentType, e.g. MessagePack or CBOR with the encoders of packages msgpack and cbor
+// for mobile clients which want smaller payloads. Several formats may be added.
+func ResponseFormat(contentType string, enc Encoder) SchemaOpt {
+	return func(s *Schema) {
+		s.responseFormats = append(s.responseFormats, responseFormat{contentType: contentType, enc: enc})
+	}
+}
+
+type responseFormat struct {
+	contentType string
+	enc         Encoder
+}
+
+// jsonContentType is the content type of responses encoded with [Schema.MarshalResponse].
+const jsonContentType = "application/json"
+
+// MarshalResponseFor encodes resp in the format preferred by accept, the value of the Accept header of
+// an HTTP request, and returns the content type of the encoding. Responses are encoded as JSON with
+// [Schema.MarshalResponse] unless accept prefers a format added with [ResponseFormat].
+func (s *Schema) MarshalResponseFor(resp *Response, accept string) (data []byte, contentType string, err error) {
+	if f := s.negotiateFormat(accept); f != nil {
+		data, err = f.enc.Marshal(resp)
+		return data, f.contentType, err
+	}
+	data, err = s.MarshalResponse(resp)
+	return data, jsonContentType, err
+}
+
+// negotiateFormat returns the response format with the highest quality in accept. It returns nil for
+// JSON.
+func (s *Schema) negotiateFormat(accept string) *responseFormat {
+	if len(s.responseFormats) == 0 || accept == "" {
+		return nil
+	}
+	var best *responseFormat
+	bestQuality := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		for _, param := range params[1:] {
+			if q := strings.TrimSpace(param); strings.HasPrefix(q, "q=") {
+				if v, err := strconv.ParseFloat(q[2:], 64); err == nil {
+					quality = v
+				}
+			}
+		}
+		if quality <= bestQuality {
+			continue
+		}
+		if mediaType == jsonContentType || mediaType == "*/*" || mediaType == "application/*" {
+			best, bestQuality = nil, quality
+			continue
+		}
+		for i, f := range s.responseFormats {
+			if strings.EqualFold(f.contentType, mediaType) {
+				best, bestQuality = &s.responseFormats[i], quality
+				break
+			}
+		}
+	}
+	return best
+}
+
+// WriteResponse encodes resp with the encoder of the schema and writes it to w, which may e.g. compress
+// the response. Nothing is written if resp can not be encoded.
+func (s *Schema) WriteResponse(w io.Writer, resp *Response) error {
+	data, err := s.MarshalResponse(resp)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// VariablesDecoder decodes the variables of requests, e.g. carried as msgpack, protobuf or url-encoded
+// forms by a transport, into the values passed to [Schema.Exec]. As the encoding of the variables
+// depends on the transport, it is set on the transports, e.g. relay.Handler, which decode the variables
+// with [DecodeVariables].
+//
+// Besides the values produced by encoding/json, the executor accepts numbers of any Go integer or
+// floating point type, e.g. int64 values which custom scalars of 64-bit integers receive without a lossy
+// conversion to float64, and typed slices, e.g. []int64, for lists.
+type VariablesDecoder interface {
+	DecodeVariables(data []byte) (map[string]interface{}, error)
+}
+
+// JSONVariablesDecoder is the default [VariablesDecoder], which uses package encoding/json. If UseInt64
+// is set, integers are decoded as int64 instead of float64, which keeps the precision of integers
+// beyond 2^53.
+type JSONVariablesDecoder struct {
+	UseInt64 bool
+}
+
+// DecodeVariables decodes the JSON object data.
+func (d JSONVariablesDecoder) DecodeVariables(data []byte) (map[string]interface{}, error) {
+	var vars map[string]interface{}
+	if !d.UseInt64 {
+		err := json.Unmarshal(data, &vars)
+		return vars, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&vars); err != nil {
+		return nil, err
+	}
+	for name, v := range vars {
+		vars[name] = convertNumbers(v)
+	}
+	return vars, nil
+}
+
+// convertNumbers replaces the json.Number values in v by int64 values for integers and float64 values
+// otherwise.
+func convertNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = convertNumbers(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = convertNumbers(elem)
+		}
+	}
+	return v
+}
+
+// DecodeVariables decodes the variables of a request with dec, or with [JSONVariablesDecoder] if dec is
+// nil. Empty data decodes to no variables.
+func DecodeVariables(dec VariablesDecoder, data []byte) (map[string]interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if dec == nil {
+		dec = JSONVariablesDecoder{}
+	}
+	return dec.DecodeVariables(data)
+}
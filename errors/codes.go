@@ -0,0 +1,51 @@
+package errors
+
+// Codes of the "code" extension of the errors raised by the library itself, so that clients and
+// gateways can handle them without matching the messages, which may change between versions.
+// Errors returned by resolvers keep the extensions of the resolver error, see [WithCode].
+const (
+	// CodeParseFailed is the code of errors of documents which can not be parsed, including
+	// documents exceeding the token or nesting limits.
+	CodeParseFailed = "GRAPHQL_PARSE_FAILED"
+	// CodeValidationFailed is the code of errors of documents which are invalid for the schema.
+	CodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	// CodeMaxDepthExceeded is the code of errors of fields or input objects nested deeper than
+	// the maximum depth.
+	CodeMaxDepthExceeded = "MAX_DEPTH_EXCEEDED"
+	// CodeQueryTooLong is the code of errors of documents exceeding the maximum query length.
+	CodeQueryTooLong = "QUERY_TOO_LONG"
+	// CodeOperationResolutionFailure is the code of errors of requests whose operation can not be
+	// determined, e.g. because the document contains several operations and no operation name is given.
+	CodeOperationResolutionFailure = "OPERATION_RESOLUTION_FAILURE"
+	// CodeOperationNotSupported is the code of errors of operations whose type is not offered by the
+	// schema or can not be executed the way they are requested.
+	CodeOperationNotSupported = "OPERATION_NOT_SUPPORTED"
+	// CodeBadUserInput is the code of errors of arguments and variables which can not be coerced.
+	CodeBadUserInput = "BAD_USER_INPUT"
+	// CodeNonNullViolation is the code of errors of fields of non-null types which resolved to null.
+	CodeNonNullViolation = "NON_NULL_VIOLATION"
+	// CodeInvalidEnumValue is the code of errors of resolvers returning values which are not part
+	// of their enum.
+	CodeInvalidEnumValue = "INVALID_ENUM_VALUE"
+	// CodeTimeout is the code of errors of operations which exceeded their timeout or deadline.
+	CodeTimeout = "TIMEOUT"
+	// CodeCancelled is the code of errors of operations which were cancelled.
+	CodeCancelled = "CANCELLED"
+	// CodeResponseTooLarge is the code of errors of responses exceeding the maximum response size.
+	CodeResponseTooLarge = "RESPONSE_TOO_LARGE"
+	// CodeTooManyOperations is the code of errors of operations rejected because the schema executes
+	// the maximum number of concurrent operations.
+	CodeTooManyOperations = "TOO_MANY_OPERATIONS"
+	// CodeNoResolver is the code of errors of requests executed with a schema without resolver, or
+	// with a root resolver which does not match the resolver of the schema.
+	CodeNoResolver = "NO_RESOLVER"
+)
+
+// SetCode sets the "code" extension of err, keeping its other extensions, and returns err.
+func (err *QueryError) SetCode(code string) *QueryError {
+	if err.Extensions == nil {
+		err.Extensions = make(map[string]interface{}, 1)
+	}
+	err.Extensions["code"] = code
+	return err
+}
@@ -2,6 +2,8 @@ package errors
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 type QueryError struct {
@@ -12,6 +14,9 @@ type QueryError struct {
 	Rule          string                 `json:"-"`
 	ResolverError error                  `json:"-"`
 	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+	// Stack is the stack of the goroutine at the time of the panic the error was created for, if any.
+	// It is not sent to clients, but available to tracers and panic handlers for crash reporting.
+	Stack []byte `json:"-"`
 }
 
 type Location struct {
@@ -56,4 +61,29 @@ func (err *QueryError) Unwrap() error {
 	return err.Err
 }
 
+// PathString formats the path of an error, e.g. the Path of a QueryError, the way it is written in
+// JavaScript: field names are separated by dots and list indices are enclosed in brackets, like in
+// "hero.friends[0].name".
+func PathString(path []interface{}) string {
+	var sb strings.Builder
+	for i, p := range path {
+		switch p := p.(type) {
+		case int:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(p))
+			sb.WriteByte(']')
+		case float64: // indices of paths decoded from JSON
+			sb.WriteByte('[')
+			sb.WriteString(strconv.FormatFloat(p, 'f', -1, 64))
+			sb.WriteByte(']')
+		default:
+			if i > 0 {
+				sb.WriteByte('.')
+			}
+			fmt.Fprint(&sb, p)
+		}
+	}
+	return sb.String()
+}
+
 var _ error = &QueryError{}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"io"
+	"reflect"
 	"testing"
 )
 
@@ -53,3 +54,36 @@ func TestErrorf(t *testing.T) {
 		}
 	})
 }
+
+func TestPathString(t *testing.T) {
+	for _, tt := range []struct {
+		path []interface{}
+		want string
+	}{
+		{nil, ""},
+		{[]interface{}{"hero"}, "hero"},
+		{[]interface{}{"hero", "friends", 0, "name"}, "hero.friends[0].name"},
+		{[]interface{}{"grid", 1, 0, "cells", 2}, "grid[1][0].cells[2]"},
+		{[]interface{}{"grid", float64(1), float64(0)}, "grid[1][0]"},
+	} {
+		if got := PathString(tt.path); got != tt.want {
+			t.Errorf("PathString(%v) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSetCode(t *testing.T) {
+	err := &QueryError{Message: "boom", Extensions: map[string]interface{}{"field": "name"}}
+	if got := err.SetCode(CodeNonNullViolation); got != err {
+		t.Fatalf("SetCode returned %p, want %p", got, err)
+	}
+	want := map[string]interface{}{"field": "name", "code": CodeNonNullViolation}
+	if !reflect.DeepEqual(err.Extensions, want) {
+		t.Errorf("got extensions %v, want %v", err.Extensions, want)
+	}
+
+	err = Errorf("boom").SetCode(CodeTimeout)
+	if got := Extensions(err)["code"]; got != CodeTimeout {
+		t.Errorf("got code %v, want %v", got, CodeTimeout)
+	}
+}
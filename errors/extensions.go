@@ -0,0 +1,74 @@
+package errors
+
+import (
+	"errors"
+)
+
+// Extensioner is implemented by errors which carry GraphQL error extensions. The executor adds
+// the extensions of the first Extensioner in the chain of a resolver error to the [QueryError].
+type Extensioner interface {
+	error
+	Extensions() map[string]interface{}
+}
+
+// WithExtensions wraps err so that ext is added to the extensions of the resulting [QueryError].
+// Extensions of errors further down the chain are preserved unless ext overrides their keys.
+// The wrapped error can still be inspected with the standard errors.Is and errors.As functions.
+// WithExtensions returns nil if err is nil.
+func WithExtensions(err error, ext map[string]interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &extendedError{err: err, extensions: ext}
+}
+
+// WithCode wraps err so that the resulting [QueryError] has the given "code" extension.
+// It is a shorthand for WithExtensions(err, map[string]interface{}{"code": code}).
+func WithCode(err error, code string) error {
+	return WithExtensions(err, map[string]interface{}{"code": code})
+}
+
+// Extensions returns the extensions of the first error in the chain of err which implements
+// [Extensioner] or is a [QueryError], or nil.
+func Extensions(err error) map[string]interface{} {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch e := err.(type) {
+		case Extensioner:
+			return e.Extensions()
+		case *QueryError:
+			return e.Extensions
+		}
+	}
+	return nil
+}
+
+type extendedError struct {
+	err        error
+	extensions map[string]interface{}
+}
+
+func (e *extendedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *extendedError) Unwrap() error {
+	return e.err
+}
+
+func (e *extendedError) Extensions() map[string]interface{} {
+	return mergeExtensions(Extensions(e.err), e.extensions)
+}
+
+func mergeExtensions(base, ext map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 && len(ext) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(base)+len(ext))
+	for k, v := range base {
+		m[k] = v
+	}
+	for k, v := range ext {
+		m[k] = v
+	}
+	return m
+}
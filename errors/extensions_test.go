@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type codedError struct{}
+
+func (codedError) Error() string { return "coded" }
+
+func (codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "INNER", "inner": true}
+}
+
+func TestWithExtensions(t *testing.T) {
+	t.Run("handles nil", func(t *testing.T) {
+		if err := WithCode(nil, "X"); err != nil {
+			t.Fatalf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("preserves the chain", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", WithCode(io.EOF, "NOT_FOUND"))
+		if !errors.Is(err, io.EOF) {
+			t.Fatal("expected errors.Is to return true")
+		}
+		if err.Error() != "wrapped: EOF" {
+			t.Fatalf("unexpected message %q", err.Error())
+		}
+		want := map[string]interface{}{"code": "NOT_FOUND"}
+		if got := Extensions(err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("merges extensions", func(t *testing.T) {
+		err := WithExtensions(WithCode(codedError{}, "OUTER"), map[string]interface{}{"retry": false})
+		want := map[string]interface{}{"code": "OUTER", "inner": true, "retry": false}
+		if got := Extensions(err); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		var ce codedError
+		if !errors.As(err, &ce) {
+			t.Fatal("expected errors.As to return true")
+		}
+	})
+
+	t.Run("query error extensions", func(t *testing.T) {
+		qErr := &QueryError{Message: "boom", Extensions: map[string]interface{}{"a": 1}}
+		want := map[string]interface{}{"a": 1, "code": "X"}
+		if got := Extensions(WithCode(qErr, "X")); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package errors
+
+import (
+	"strings"
+)
+
+// MultiError is a list of errors which are reported together, e.g. every mismatch between a
+// schema and its resolvers.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors of the list. It makes errors.Is and errors.As inspect
+// every error of the list since Go 1.20.
+func (e MultiError) Unwrap() []error {
+	return e
+}
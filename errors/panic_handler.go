@@ -9,6 +9,15 @@ type PanicHandler interface {
 	MakePanicError(ctx context.Context, value interface{}) *QueryError
 }
 
+// StackPanicHandler is implemented by panic handlers which want the stack of the goroutine at the time
+// of the panic, e.g. to report crashes or to add the stack to the extensions of the error in development.
+// MakePanicErrorWithStack is called instead of MakePanicError. The stack is stored in the Stack of the
+// returned error unless the handler sets it.
+type StackPanicHandler interface {
+	PanicHandler
+	MakePanicErrorWithStack(ctx context.Context, value interface{}, stack []byte) *QueryError
+}
+
 // DefaultPanicHandler is the default [PanicHandler].
 type DefaultPanicHandler struct{}
 
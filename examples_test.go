@@ -121,7 +121,10 @@ func ExampleMaxDepth() {
 	//           "line": 8,
 	//           "column": 12
 	//         }
-	//       ]
+	//       ],
+	//       "extensions": {
+	//         "code": "MAX_DEPTH_EXCEEDED"
+	//       }
 	//     }
 	//   ]
 	// }
@@ -150,7 +153,10 @@ func ExampleMaxQueryLength() {
 	// {
 	//   "errors": [
 	//     {
-	//       "message": "query length 53 exceeds the maximum allowed query length of 50 bytes"
+	//       "message": "query length 53 exceeds the maximum allowed query length of 50 bytes",
+	//       "extensions": {
+	//         "code": "QUERY_TOO_LONG"
+	//       }
 	//     }
 	//   ]
 	// }
@@ -0,0 +1,21 @@
+//go:build go1.18
+
+package graphql
+
+// Future is the value of a field which is loaded asynchronously, e.g. by a data loader. Resolvers may
+// return a Future instead of the value, which is awaited like a thunk: once no other resolver of the
+// request is running any more, so that the resolvers of sibling fields and of list elements can add
+// their keys to a batch before it is loaded.
+type Future[T any] func() (T, error)
+
+// Await waits for the value of the future.
+func (f Future[T]) Await() (T, error) {
+	return f()
+}
+
+// Resolved returns a future of the value v, e.g. for values which are already cached.
+func Resolved[T any](v T) Future[T] {
+	return func() (T, error) {
+		return v, nil
+	}
+}
@@ -4,13 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/cachecontrol"
 	"github.com/graph-gophers/graphql-go/directives"
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/internal/common"
 	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/internal/exec/packer"
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
 	"github.com/graph-gophers/graphql-go/internal/exec/selected"
 	"github.com/graph-gophers/graphql-go/internal/query"
@@ -18,12 +25,16 @@ import (
 	"github.com/graph-gophers/graphql-go/internal/validation"
 	"github.com/graph-gophers/graphql-go/introspection"
 	"github.com/graph-gophers/graphql-go/log"
+	"github.com/graph-gophers/graphql-go/metrics"
+	"github.com/graph-gophers/graphql-go/responsecache"
+	"github.com/graph-gophers/graphql-go/retry"
 	"github.com/graph-gophers/graphql-go/trace/noop"
 	"github.com/graph-gophers/graphql-go/trace/tracer"
 )
 
 // ParseSchema parses a GraphQL schema and attaches the given root resolver. It returns an error if
-// the Go type signature of the resolvers does not match the schema. If nil is passed as the
+// the Go type signature of the resolvers does not match the schema, which is an [errors.MultiError]
+// listing every mismatch along with the chain of resolvers leading to it. If nil is passed as the
 // resolver, then the schema can not be executed, but it may be inspected (e.g. with [Schema.ToJSON] or [Schema.AST]).
 func ParseSchema(schemaString string, resolver interface{}, opts ...SchemaOpt) (*Schema, error) {
 	s := &Schema{
@@ -32,33 +43,103 @@ func ParseSchema(schemaString string, resolver interface{}, opts ...SchemaOpt) (
 		tracer:         noop.Tracer{},
 		logger:         &log.DefaultLogger{},
 		panicHandler:   &errors.DefaultPanicHandler{},
+		encoder:        JSONEncoder{},
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
 
 	if s.validationTracer == nil {
-		if t, ok := s.tracer.(tracer.ValidationTracer); ok {
-			s.validationTracer = t
-		} else {
-			s.validationTracer = &validationBridgingTracer{tracer: tracer.LegacyNoopValidationTracer{}} //nolint:staticcheck
+		s.validationTracer = s.tracer
+	}
+	if st, ok := tracer.AsSkipTracer(s.tracer); ok {
+		s.skipTracer = st
+	}
+	if s.traceSampler != nil {
+		s.tracer = &sampledTracer{TracerV2: s.tracer, sample: s.traceSampler}
+	}
+
+	if err := s.compileCached(schemaString, resolver); err != nil {
+		return nil, err
+	}
+
+	for _, fn := range s.afterParse {
+		if err := fn(s); err != nil {
+			return nil, err
 		}
 	}
+	return s, nil
+}
 
+// compile parses schemaString and binds resolver to it.
+func (s *Schema) compile(schemaString string, resolver interface{}) error {
 	if err := schema.Parse(s.schema, schemaString, s.useStringDescriptions); err != nil {
-		return nil, err
+		return err
+	}
+	if s.fragmentDefinitionSkip {
+		allowOnFragmentDefinitions(s.schema, "skip", "include")
 	}
 	if err := s.validateSchema(); err != nil {
-		return nil, err
+		return err
+	}
+
+	stringScalars := make(map[string]struct{}, len(s.scalarValidators))
+	for name := range s.scalarValidators {
+		if _, ok := s.schema.Types[name].(*ast.ScalarTypeDefinition); !ok {
+			return fmt.Errorf("scalar validator registered for unknown scalar %q", name)
+		}
+		stringScalars[name] = struct{}{}
+	}
+
+	inputUnions := make(map[reflect.Type]*packer.InputUnion, len(s.inputUnions))
+	for _, u := range s.inputUnions {
+		t := reflect.TypeOf(u.iface)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+			return fmt.Errorf("input union must be registered with a pointer to an interface, got %T", u.iface)
+		}
+		members := make(map[string]reflect.Type, len(u.members))
+		for value, m := range u.members {
+			if m == nil {
+				return fmt.Errorf("input union member %q of %s is nil", value, t.Elem())
+			}
+			members[value] = reflect.TypeOf(m)
+		}
+		inputUnions[t.Elem()] = &packer.InputUnion{Discriminator: u.discriminator, Members: members}
 	}
 
-	r, err := resolvable.ApplyResolver(s.schema, resolver, s.directives, s.useFieldResolvers)
+	sems, err := s.concurrencySemaphores()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	trivial, err := s.trivialOverrides()
+	if err != nil {
+		return err
+	}
+	normalizers, err := s.normalizerFuncs()
+	if err != nil {
+		return err
 	}
-	s.res = r
 
-	return s, nil
+	r, err := resolvable.ApplyResolver(s.schema, resolver, resolvable.Options{
+		Directives:        s.directives,
+		UseFieldResolvers: s.useFieldResolvers,
+		StringScalars:     stringScalars,
+		NameMapper:        s.nameMapper,
+		Strict:            s.strictResolvers,
+		InputUnions:       inputUnions,
+		TraceLabel:        s.traceLabel,
+		FieldFuncs:        s.fieldFuncs,
+		InterfaceBases:    s.interfaceBases,
+		ConcurrencyGroups: sems,
+		TrivialFields:     trivial,
+		InputNormalizers:  normalizers,
+		EnumMappings:      s.enumMappings,
+	})
+	if err != nil {
+		return err
+	}
+	s.res = r
+	return nil
 }
 
 // MustParseSchema calls ParseSchema and panics on error.
@@ -78,15 +159,69 @@ type Schema struct {
 	allowIntrospection       func(ctx context.Context) bool
 	directives               []directives.Directive
 	maxQueryLength           int
+	parseLimits              query.Limits
 	maxDepth                 int
+	maxInputDepth            int
 	maxParallelism           int
-	tracer                   tracer.Tracer
+	tracer                   tracer.TracerV2
 	validationTracer         tracer.ValidationTracer
 	logger                   log.Logger
 	panicHandler             errors.PanicHandler
 	useStringDescriptions    bool
+	fragmentDefinitionSkip   bool
 	subscribeResolverTimeout time.Duration
 	useFieldResolvers        bool
+	cacheControl             bool
+	defaultMaxAge            time.Duration
+	responseCache            *responsecache.Cache
+	profiling                bool
+	scalarValidators         map[string]func(interface{}) error
+	disableNullBubbling      bool
+	nameMapper               func(goName string) string
+	strictResolvers          bool
+	collectStats             bool
+	inputUnions              []inputUnion
+	retryPolicy              func(retry.FieldInfo) retry.Policy
+	sortResponseKeys         bool
+	traceLabel               func(typeName, fieldName string) string
+	traceSampler             func(info tracer.ResolverInfo) bool
+	fieldFuncs               map[string]map[string]interface{}
+	interfaceBases           map[string]interface{}
+	introspectionFilter      func(ctx context.Context, typeName, fieldName string) bool
+	visibilityFilter         func(ctx context.Context, info VisibilityInfo) bool
+	counters                 metrics.Counters
+	costEstimator            CostEstimator
+	encoder                  Encoder
+	responseFormats          []responseFormat
+	subscriptions            subscriptionTracker
+	operations               operationRegistry
+	operationSlots           chan struct{}
+	overloadPolicy           OverloadPolicy
+	mutationPlanner          MutationPlanner
+	invalidationBus          InvalidationBus
+	typeCache                *TypeCache
+	queryTimeout             time.Duration
+	mutationTimeout          time.Duration
+	subscriptionInitTimeout  time.Duration
+	requestLogger            log.RequestLogger
+	redactedVariables        []string
+	maxResponseBytes         int
+	concurrencyGroups        map[string]*concurrencyGroup
+	trivialFields            map[string]bool
+	inputNormalizers         []inputNormalizer
+	enumMappings             map[string]map[string]interface{}
+	lenientEnumOutput        bool
+	skipTracer               tracer.SkipTracer
+	variableWarnings         bool
+	afterParse               []func(s *Schema) error
+	deduplicateFields        bool
+	hashOnce                 sync.Once
+	hash                     string
+}
+
+type concurrencyGroup struct {
+	limit  int
+	fields []string
 }
 
 // AST returns the abstract syntax tree of the GraphQL schema definition.
@@ -116,6 +251,35 @@ func UseStringDescriptions() SchemaOpt {
 	}
 }
 
+// SkipIncludeOnFragmentDefinitions allows the @skip and @include directives on fragment
+// definitions, which skip every spread of the fragment. This is not part of the GraphQL
+// specification, so it is disabled by default and such queries fail validation.
+func SkipIncludeOnFragmentDefinitions() SchemaOpt {
+	return func(s *Schema) {
+		s.fragmentDefinitionSkip = true
+	}
+}
+
+// allowOnFragmentDefinitions adds the FRAGMENT_DEFINITION location to the named directives. The
+// definitions are copied, as the built-in ones are shared by all schemas.
+func allowOnFragmentDefinitions(s *ast.Schema, names ...string) {
+	for _, name := range names {
+		d, ok := s.Directives[name]
+		if !ok {
+			continue
+		}
+		c := *d
+		c.Locations = make([]string, 0, len(d.Locations)+1)
+		for _, loc := range d.Locations {
+			c.Locations = append(c.Locations, loc)
+			if loc == "FIELD" {
+				c.Locations = append(c.Locations, "FRAGMENT_DEFINITION")
+			}
+		}
+		s.Directives[name] = &c
+	}
+}
+
 // UseFieldResolvers specifies whether to use struct fields as resolvers.
 func UseFieldResolvers() SchemaOpt {
 	return func(s *Schema) {
@@ -130,6 +294,15 @@ func MaxDepth(n int) SchemaOpt {
 	}
 }
 
+// MaxInputDepth specifies the maximum nesting depth of input objects in the arguments and variables of a
+// query, e.g. of recursive input types. Deeper inputs are rejected during validation, before they are
+// coerced into the arguments of resolvers. The default is 0 which disables max input depth checking.
+func MaxInputDepth(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxInputDepth = n
+	}
+}
+
 // MaxParallelism specifies the maximum number of resolvers per request allowed to run in parallel. The default is 10.
 func MaxParallelism(n int) SchemaOpt {
 	return func(s *Schema) {
@@ -137,6 +310,24 @@ func MaxParallelism(n int) SchemaOpt {
 	}
 }
 
+// MaxTokens specifies the maximum number of tokens in a query, not counting commas and comments. The parser
+// aborts as soon as the limit is exceeded, before memory is allocated for the rest of a pathological
+// document, e.g. one with a huge argument list. The default is 0 which disables max tokens checking.
+func MaxTokens(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.parseLimits.MaxTokens = n
+	}
+}
+
+// MaxNestingDepth specifies the maximum nesting depth of selection sets, list and object values and list
+// types in a query. In contrast to [MaxDepth] it is checked by the parser, so deeply nested documents are
+// rejected before they are validated. The default is 1000, which keeps the parser from overflowing the stack.
+func MaxNestingDepth(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.parseLimits.MaxDepth = n
+	}
+}
+
 // MaxQueryLength specifies the maximum allowed query length in bytes. The default is 0 which disables max length checking.
 func MaxQueryLength(n int) SchemaOpt {
 	return func(s *Schema) {
@@ -145,12 +336,296 @@ func MaxQueryLength(n int) SchemaOpt {
 }
 
 // Tracer is used to trace queries and fields. It defaults to [noop.Tracer].
+// Tracers which do not implement [tracer.TracerV2] are converted using [tracer.Upgrade].
 func Tracer(t tracer.Tracer) SchemaOpt {
+	return func(s *Schema) {
+		s.tracer = tracer.Upgrade(t)
+	}
+}
+
+// TracerV2 is used to trace every phase of a request, including parsing and subscriptions.
+// It is an alternative to [Tracer] for tracers which only implement [tracer.TracerV2].
+func TracerV2(t tracer.TracerV2) SchemaOpt {
 	return func(s *Schema) {
 		s.tracer = t
 	}
 }
 
+// TraceLabel overrides the format of the trace labels of fields, which defaults to
+// "GraphQL field: <type>.<field>". The label is passed to tracers as [tracer.ResolverInfo.Label].
+func TraceLabel(fn func(typeName, fieldName string) string) SchemaOpt {
+	return func(s *Schema) {
+		s.traceLabel = fn
+	}
+}
+
+// TraceSampler decides for each field which is about to be resolved whether it is traced. Tracing every
+// field of a large response, e.g. __typename and scalar fields, adds significant overhead, a sampler
+// can skip such fields:
+//
+//	graphql.TraceSampler(func(info tracer.ResolverInfo) bool {
+//		return !info.Trivial
+//	})
+func TraceSampler(fn func(info tracer.ResolverInfo) bool) SchemaOpt {
+	return func(s *Schema) {
+		s.traceSampler = fn
+	}
+}
+
+// FieldFunc resolves the field fieldName of the type typeName with the function fn instead of a method
+// or struct field of the resolver. fn has the same signature as a resolver method, optionally preceded
+// by the resolver of the parent object:
+//
+//	graphql.FieldFunc("Query", "hello", func(ctx context.Context, args struct{ Name string }) (string, error) {
+//		return "Hello, " + args.Name + "!", nil
+//	})
+//	graphql.FieldFunc("User", "fullName", func(u *User) string {
+//		return u.FirstName + " " + u.LastName
+//	})
+func FieldFunc(typeName, fieldName string, fn interface{}) SchemaOpt {
+	return func(s *Schema) {
+		if s.fieldFuncs == nil {
+			s.fieldFuncs = make(map[string]map[string]interface{})
+		}
+		if s.fieldFuncs[typeName] == nil {
+			s.fieldFuncs[typeName] = make(map[string]interface{})
+		}
+		s.fieldFuncs[typeName][fieldName] = fn
+	}
+}
+
+// InterfaceBase resolves the fields of the interface interfaceName with the methods of base for the
+// implementing types whose resolvers lack a method or struct field for them, e.g. to share the resolvers of
+// common fields between all implementations. Like a function of [FieldFunc], a method of base may take the
+// resolver of the object as its first argument:
+//
+//	type entityBase struct{ db *DB }
+//
+//	func (b entityBase) CreatedAt(e Entity) graphql.Time {
+//		return graphql.Time{Time: b.db.CreatedAt(e.ID())}
+//	}
+//
+//	graphql.InterfaceBase("Entity", entityBase{db})
+//
+// Methods and struct fields of the resolvers and functions of [FieldFunc] take precedence over base. Fields
+// selected on the interface itself are resolved with the resolver of the interface, so base resolves them if
+// that resolver lacks them, even if the resolver of the object implements them.
+func InterfaceBase(interfaceName string, base interface{}) SchemaOpt {
+	return func(s *Schema) {
+		if s.interfaceBases == nil {
+			s.interfaceBases = make(map[string]interface{})
+		}
+		s.interfaceBases[interfaceName] = base
+	}
+}
+
+// ConcurrencyGroup limits the number of concurrent calls of the resolvers of fields sharing a backend,
+// e.g. all fields hitting the same database, to limit. The limit is shared by all requests executed
+// with the schema. fields are schema coordinates of the form "Type.field":
+//
+//	graphql.ConcurrencyGroup("db", 10, "Query.users", "User.orders")
+//
+// Calling ConcurrencyGroup again with the same name adds fields to the group and replaces its limit.
+// Fields of interfaces are grouped by the coordinates of the object types implementing them. A field can
+// only belong to one group.
+func ConcurrencyGroup(name string, limit int, fields ...string) SchemaOpt {
+	return func(s *Schema) {
+		if s.concurrencyGroups == nil {
+			s.concurrencyGroups = make(map[string]*concurrencyGroup)
+		}
+		g, ok := s.concurrencyGroups[name]
+		if !ok {
+			g = &concurrencyGroup{}
+			s.concurrencyGroups[name] = g
+		}
+		g.limit = limit
+		g.fields = append(g.fields, fields...)
+	}
+}
+
+// TrivialFields marks the fields with the schema coordinates "Type.field" as trivial. Trivial fields
+// are resolved without starting a goroutine and are reported as trivial to tracers, see
+// [tracer.ResolverInfo.Trivial]. By default fields are trivial unless their resolver takes a context or
+// arguments, returns an error or has directive visitors, which misjudges e.g. methods which take a
+// context only to log. Struct fields may be marked with the tag `trivial:"true"` instead.
+func TrivialFields(fields ...string) SchemaOpt {
+	return markTrivial(true, fields)
+}
+
+// ExpensiveFields marks the fields with the schema coordinates "Type.field" as not trivial, e.g. struct
+// fields of function types which load their value lazily, so that they are resolved concurrently and
+// traced like other expensive fields. Struct fields may be marked with the tag `trivial:"false"`
+// instead. See [TrivialFields].
+func ExpensiveFields(fields ...string) SchemaOpt {
+	return markTrivial(false, fields)
+}
+
+func markTrivial(trivial bool, fields []string) SchemaOpt {
+	return func(s *Schema) {
+		if s.trivialFields == nil {
+			s.trivialFields = make(map[string]bool)
+		}
+		for _, f := range fields {
+			s.trivialFields[f] = trivial
+		}
+	}
+}
+
+type inputNormalizer struct {
+	typeName string
+	fn       interface{}
+}
+
+// InputNormalizer registers fn to normalize the values of the input type typeName, a scalar, enum or
+// input object, after they were coerced into the Go types of resolver arguments and before the
+// resolvers receive them, e.g. to trim or case-fold values in one place:
+//
+//	graphql.InputNormalizer("EmailAddress", strings.ToLower)
+//
+// fn has the form func(T) T or func(T) (T, error), where T is the Go type the values are coerced into,
+// the element type of pointers to it or a type of the same kind, e.g. string for type Email string. An
+// error rejects the argument like a malformed value. Several normalizers of a type are applied in the
+// order they were registered. Values used only in directives are not normalized.
+func InputNormalizer(typeName string, fn interface{}) SchemaOpt {
+	return func(s *Schema) {
+		s.inputNormalizers = append(s.inputNormalizers, inputNormalizer{typeName: typeName, fn: fn})
+	}
+}
+
+// EnumMapping maps the values of enums to Go values, keyed by the names of the enum and the value,
+// e.g. to bind an enum to constants of a protobuf enum whose names differ from the schema:
+//
+//	graphql.EnumMapping(map[string]map[string]interface{}{
+//		"Episode": {"NEWHOPE": pb.Episode_EPISODE_NEW_HOPE, "EMPIRE": pb.Episode_EPISODE_EMPIRE},
+//	})
+//
+// Arguments and input fields of a mapped enum are bound to the mapped values and resolvers of fields
+// of the enum return them, in both cases converted to the Go type of the argument or the result. Every
+// value of a mapped enum must be mapped. EnumMapping may be used several times, mappings of the same
+// enum replace each other.
+func EnumMapping(mappings map[string]map[string]interface{}) SchemaOpt {
+	return func(s *Schema) {
+		if s.enumMappings == nil {
+			s.enumMappings = make(map[string]map[string]interface{})
+		}
+		for enum, m := range mappings {
+			s.enumMappings[enum] = m
+		}
+	}
+}
+
+// concurrencySemaphores returns the semaphores of the concurrency groups keyed by type and field name.
+func (s *Schema) concurrencySemaphores() (map[string]map[string]chan struct{}, error) {
+	if len(s.concurrencyGroups) == 0 {
+		return nil, nil
+	}
+	names := make([]string, 0, len(s.concurrencyGroups))
+	for name := range s.concurrencyGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sems := make(map[string]map[string]chan struct{})
+	groupOf := make(map[string]string)
+	for _, name := range names {
+		g := s.concurrencyGroups[name]
+		if g.limit <= 0 {
+			return nil, fmt.Errorf("concurrency group %q: limit must be positive, got %d", name, g.limit)
+		}
+		sem := make(chan struct{}, g.limit)
+		for _, coord := range g.fields {
+			typeName, fieldName, ok := splitCoordinate(coord)
+			if !ok {
+				return nil, fmt.Errorf("concurrency group %q: invalid field %q, expected \"Type.field\"", name, coord)
+			}
+			t, _ := s.schema.Types[typeName].(*ast.ObjectTypeDefinition)
+			if t == nil || t.Fields.Get(fieldName) == nil {
+				return nil, fmt.Errorf("concurrency group %q: unknown field %q", name, coord)
+			}
+			if other, ok := groupOf[coord]; ok && other != name {
+				return nil, fmt.Errorf("concurrency group %q: field %q already belongs to group %q", name, coord, other)
+			}
+			groupOf[coord] = name
+			if sems[typeName] == nil {
+				sems[typeName] = make(map[string]chan struct{})
+			}
+			sems[typeName][fieldName] = sem
+		}
+	}
+	return sems, nil
+}
+
+// trivialOverrides returns the fields marked with TrivialFields and ExpensiveFields keyed by type and
+// field name.
+func (s *Schema) trivialOverrides() (map[string]map[string]bool, error) {
+	if len(s.trivialFields) == 0 {
+		return nil, nil
+	}
+	overrides := make(map[string]map[string]bool)
+	for coord, trivial := range s.trivialFields {
+		typeName, fieldName, ok := splitCoordinate(coord)
+		if !ok {
+			return nil, fmt.Errorf("trivial fields: invalid field %q, expected \"Type.field\"", coord)
+		}
+		t, _ := s.schema.Types[typeName].(*ast.ObjectTypeDefinition)
+		if t == nil || t.Fields.Get(fieldName) == nil {
+			return nil, fmt.Errorf("trivial fields: unknown field %q", coord)
+		}
+		if overrides[typeName] == nil {
+			overrides[typeName] = make(map[string]bool)
+		}
+		overrides[typeName][fieldName] = trivial
+	}
+	return overrides, nil
+}
+
+// normalizerFuncs returns the functions registered with InputNormalizer keyed by type name.
+func (s *Schema) normalizerFuncs() (map[string][]reflect.Value, error) {
+	if len(s.inputNormalizers) == 0 {
+		return nil, nil
+	}
+	funcs := make(map[string][]reflect.Value)
+	for _, n := range s.inputNormalizers {
+		switch s.schema.Types[n.typeName].(type) {
+		case *ast.ScalarTypeDefinition, *ast.EnumTypeDefinition, *ast.InputObject:
+		default:
+			return nil, fmt.Errorf("input normalizer: unknown input type %q", n.typeName)
+		}
+		fn := reflect.ValueOf(n.fn)
+		if fn.Kind() != reflect.Func || fn.IsNil() {
+			return nil, fmt.Errorf("input normalizer of %s: expected a function, got %T", n.typeName, n.fn)
+		}
+		t := fn.Type()
+		ok := t.NumIn() == 1 && (t.NumOut() == 1 || t.NumOut() == 2 && t.Out(1) == reflect.TypeOf((*error)(nil)).Elem())
+		if !ok || t.Out(0) != t.In(0) {
+			return nil, fmt.Errorf("input normalizer of %s: expected func(T) T or func(T) (T, error), got %s", n.typeName, t)
+		}
+		funcs[n.typeName] = append(funcs[n.typeName], fn)
+	}
+	return funcs, nil
+}
+
+func splitCoordinate(coord string) (typeName, fieldName string, ok bool) {
+	i := strings.IndexByte(coord, '.')
+	if i <= 0 || i == len(coord)-1 {
+		return "", "", false
+	}
+	return coord[:i], coord[i+1:], true
+}
+
+// sampledTracer only traces the resolvers for which sample returns true.
+type sampledTracer struct {
+	tracer.TracerV2
+	sample func(info tracer.ResolverInfo) bool
+}
+
+func (t *sampledTracer) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, tracer.ResolverFinishFunc) {
+	if !t.sample(info) {
+		return ctx, func(*errors.QueryError, int) {}
+	}
+	return t.TracerV2.TraceResolver(ctx, info)
+}
+
 // ValidationTracer is used to trace validation errors. It defaults to [tracer.LegacyNoopValidationTracer].
 // Deprecated: context is needed to support tracing correctly. Use a tracer which implements [tracer.ValidationTracer].
 func ValidationTracer(tracer tracer.LegacyValidationTracer) SchemaOpt { //nolint:staticcheck
@@ -166,6 +641,25 @@ func Logger(logger log.Logger) SchemaOpt {
 	}
 }
 
+// LogRequests logs the name, duration, error count and variables of each operation executed with
+// [Schema.Exec] with l. The values of variables and input fields named like one of the redacted keys,
+// e.g. "password" or "token", are replaced by [log.Redacted]. The default logger, [log.DefaultLogger],
+// implements [log.RequestLogger].
+func LogRequests(l log.RequestLogger, redacted ...string) SchemaOpt {
+	return func(s *Schema) {
+		s.requestLogger = l
+		s.redactedVariables = redacted
+	}
+}
+
+// Metrics is used to count events during query execution, such as argument values which can not be
+// coerced into the Go types of the resolvers. See package [metrics] for the counters reported.
+func Metrics(c metrics.Counters) SchemaOpt {
+	return func(s *Schema) {
+		s.counters = c
+	}
+}
+
 // PanicHandler is used to customize the panic errors during query execution.
 // It defaults to [errors.DefaultPanicHandler].
 func PanicHandler(panicHandler errors.PanicHandler) SchemaOpt {
@@ -203,6 +697,108 @@ func DisableIntrospection() SchemaOpt {
 	}
 }
 
+// IntrospectionFilter hides types and fields from the introspection queries of a request for which fn
+// returns false, e.g. to hide admin-only types from unauthenticated clients. fn is called with an empty
+// fieldName for types, including the built-in scalars and introspection types, and with the name of a
+// field or input field otherwise. Fields, arguments and input fields of hidden types are hidden as well,
+// and `__type` returns null for hidden types. Execution is not affected. Responses of the [ResponseCache]
+// are only cached per caller identity with this option.
+func IntrospectionFilter(fn func(ctx context.Context, typeName, fieldName string) bool) SchemaOpt {
+	return func(s *Schema) {
+		s.introspectionFilter = fn
+	}
+}
+
+// VisibilityInfo identifies a type, or a field of it, whose visibility is decided by a [VisibilityFilter].
+type VisibilityInfo struct {
+	TypeName string
+	// FieldName is the name of a field or input field of the type, or empty for the type itself.
+	FieldName string
+}
+
+// VisibilityFilter removes the types and fields for which fn returns false from the schema of a request,
+// which allows one process to serve different schemas to different tenants. They are hidden from
+// introspection like with [IntrospectionFilter], and queries selecting hidden fields, fields of hidden
+// types, using hidden types in fragments and variables, or passing hidden input fields or arguments of
+// hidden types fail validation as if they were not defined. Responses of the [ResponseCache] are only
+// cached per caller identity with this option.
+func VisibilityFilter(fn func(ctx context.Context, info VisibilityInfo) bool) SchemaOpt {
+	return func(s *Schema) {
+		s.visibilityFilter = fn
+	}
+}
+
+func (s *Schema) introspectionFilterFor(ctx context.Context) introspection.Filter {
+	if s.introspectionFilter == nil && s.visibilityFilter == nil {
+		return nil
+	}
+	return func(typeName, fieldName string) bool {
+		if s.introspectionFilter != nil && !s.introspectionFilter(ctx, typeName, fieldName) {
+			return false
+		}
+		return s.visibilityFilter == nil || s.visibilityFilter(ctx, VisibilityInfo{TypeName: typeName, FieldName: fieldName})
+	}
+}
+
+// coercionFailedFor returns the callback counting the arguments of the request with ctx which can not
+// be coerced, or nil if no metrics are collected.
+func (s *Schema) coercionFailedFor(ctx context.Context) func(typeName, fieldName string, err *packer.InputError) {
+	if s.counters == nil {
+		return nil
+	}
+	return func(typeName, fieldName string, err *packer.InputError) {
+		var arg string
+		if len(err.Path) != 0 {
+			arg = fmt.Sprint(err.Path[0])
+		}
+		s.counters.IncCounter(ctx, metrics.InputCoercionFailures, map[string]string{
+			metrics.LabelType:         typeName,
+			metrics.LabelField:        fieldName,
+			metrics.LabelArgument:     arg,
+			metrics.LabelExpectedType: err.ExpectedType,
+		})
+	}
+}
+
+// QueryTimeout limits the duration of the execution of queries. Once it is exceeded, the context of the
+// resolvers is cancelled and the response holds a timeout error instead of data. The default is 0 which
+// disables the timeout.
+func QueryTimeout(d time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		s.queryTimeout = d
+	}
+}
+
+// MaxResponseBytes limits the size of the data of query and mutation responses. Once the serialized
+// data exceeds n bytes, the context of the remaining resolvers is cancelled and the response holds an
+// error wrapping [ErrResponseTooLarge] instead of data, e.g. to protect the server from runaway list
+// results. The default is 0 which disables the limit.
+func MaxResponseBytes(n int) SchemaOpt {
+	return func(s *Schema) {
+		s.maxResponseBytes = n
+	}
+}
+
+// ErrResponseTooLarge is wrapped by the error of responses exceeding the [MaxResponseBytes] limit.
+var ErrResponseTooLarge = exec.ErrResponseTooLarge
+
+// MutationTimeout limits the duration of the execution of mutations like [QueryTimeout] does for queries.
+func MutationTimeout(d time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		s.mutationTimeout = d
+	}
+}
+
+// SubscriptionInitTimeout limits the time the resolver of a subscription field may take to return its
+// channel. Once it is exceeded, the subscription is cancelled and a timeout error is sent to the
+// subscriber. The events of the subscription are limited by [SubscribeResolverTimeout] instead. The
+// default is 0 which disables the timeout.
+func SubscriptionInitTimeout(d time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		s.subscriptionInitTimeout = d
+	}
+}
+
 // SubscribeResolverTimeout is an option to control the amount of time
 // we allow for a single subscribe message resolver to complete it's job
 // before it times out and returns an error to the subscriber.
@@ -220,6 +816,253 @@ func Directives(ds ...directives.Directive) SchemaOpt {
 	}
 }
 
+// CacheControl enables the collection of @cacheControl hints during execution. The overall
+// [cachecontrol.Policy] is added to the response extensions and can be retrieved with
+// [Response.CachePolicy]; relay.Handler sends it as the Cache-Control header. Fields without an
+// explicit max age which are root fields or return composite types use defaultMaxAge. See package
+// [cachecontrol] for details.
+func CacheControl(defaultMaxAge time.Duration) SchemaOpt {
+	return func(s *Schema) {
+		s.cacheControl = true
+		s.defaultMaxAge = defaultMaxAge
+	}
+}
+
+// ResponseCache caches whole responses of query operations in c. Mutations invalidate the cache.
+// The max age of each response is computed from the @cacheControl hints of the resolved fields. Fields
+// without a hint use the TTL of the cache, unless the [CacheControl] option sets a default max age.
+func ResponseCache(c *responsecache.Cache) SchemaOpt {
+	return func(s *Schema) {
+		s.responseCache = c
+	}
+}
+
+// EnableProfiling measures the duration of every resolver call and adds the 10 slowest calls of each
+// request to the "profile" key of the response extensions. Durations are reported in nanoseconds.
+// It helps to debug latency without a full tracing infrastructure.
+func EnableProfiling() SchemaOpt {
+	return func(s *Schema) {
+		s.profiling = true
+	}
+}
+
+// ScalarValidator registers a function which validates every literal and variable value of the custom
+// scalar with the given name. An error returned by fn fails the validation of the request. Resolvers
+// may use Go types with an underlying string type for such scalars, e.g.:
+//
+//	graphql.ScalarValidator("EmailAddress", func(v interface{}) error {
+//		s, ok := v.(string)
+//		if !ok || !strings.Contains(s, "@") {
+//			return fmt.Errorf("not an email address")
+//		}
+//		return nil
+//	})
+func ScalarValidator(name string, fn func(interface{}) error) SchemaOpt {
+	return func(s *Schema) {
+		if s.scalarValidators == nil {
+			s.scalarValidators = make(map[string]func(interface{}) error)
+		}
+		s.scalarValidators[name] = fn
+	}
+}
+
+// NullBubblingDisabled returns null for non-null fields which could not be resolved instead of
+// propagating the null to the nearest nullable ancestor as the GraphQL specification requires.
+// The error of such a field is still reported. It is useful for clients which prefer as much data
+// as possible over strict adherence to the schema.
+func NullBubblingDisabled() SchemaOpt {
+	return func(s *Schema) {
+		s.disableNullBubbling = true
+	}
+}
+
+// NameMapper customizes how the names of Go methods, struct fields and argument struct fields are
+// matched against the names in the schema. A Go identifier matches a schema name if fn returns
+// exactly that name. By default names are matched case-insensitively after removing underscores.
+// Explicit `graphql` struct tags and [FieldMethods] mappings take precedence. For example, to map
+// Go names to snake_case schema names:
+//
+//	graphql.NameMapper(func(goName string) string {
+//		return toSnakeCase(goName) // e.g. "UserID" -> "user_id"
+//	})
+func NameMapper(fn func(goName string) string) SchemaOpt {
+	return func(s *Schema) {
+		s.nameMapper = fn
+	}
+}
+
+// StrictResolvers checks that the resolvers cover the schema exactly. In addition to the schema fields
+// without a resolver, the [errors.MultiError] returned by [ParseSchema] lists all exported resolver
+// methods which don't resolve any field. It helps to catch drift between the schema and the Go code.
+// Methods of the library interfaces, e.g. the SubscriptionCompletion method of an embedded
+// [Completion], are not reported.
+func StrictResolvers() SchemaOpt {
+	return func(s *Schema) {
+		s.strictResolvers = true
+	}
+}
+
+// CollectStats populates [Response.Stats] with a summary of the execution of each request, so servers
+// can log per-request statistics without a tracer.
+func CollectStats() SchemaOpt {
+	return func(s *Schema) {
+		s.collectStats = true
+	}
+}
+
+// RetryPolicy retries resolvers which failed with a transient error before the error is recorded at
+// the path of the field. fn returns the policy for the field which failed, the zero [retry.Policy]
+// disables retries. Only fields whose resolvers are idempotent should be retried.
+func RetryPolicy(fn func(info retry.FieldInfo) retry.Policy) SchemaOpt {
+	return func(s *Schema) {
+		s.retryPolicy = fn
+	}
+}
+
+// DeduplicateFields resolves identical sibling fields of queries, which select the same field with the
+// same arguments under different aliases, with a single resolver call. The result is shared by all
+// aliases, each of which resolves its own selection of subfields, e.g.
+//
+//	{
+//		a: user(id: 1) { name }
+//		b: user(id: 1) { email }
+//	}
+//
+// calls the resolver of user once. Mutation fields are always resolved separately.
+func DeduplicateFields() SchemaOpt {
+	return func(s *Schema) {
+		s.deduplicateFields = true
+	}
+}
+
+// AfterParse calls fn with the schema once it has been parsed and checked against the resolver, e.g.
+// to publish it to a schema registry. If fn returns an error, ParseSchema fails with it. Functions
+// registered with multiple AfterParse options are called in order.
+func AfterParse(fn func(s *Schema) error) SchemaOpt {
+	return func(s *Schema) {
+		s.afterParse = append(s.afterParse, fn)
+	}
+}
+
+// VariableWarnings reports variables which are never used by the operation and variables which are
+// used but not defined by it as warnings instead of errors, e.g. for gateways forwarding a superset of
+// the variables of the operations they split. The request is executed and the warnings are returned in
+// the "warnings" extension of the response. The values of undefined variables are taken from the
+// request as they are.
+func VariableWarnings() SchemaOpt {
+	return func(s *Schema) {
+		s.variableWarnings = true
+	}
+}
+
+// LenientEnumOutput resolves values of nullable enum fields which are not defined by the enum to
+// null, e.g. values added by a backend before they are added to the schema. Each of them is reported
+// in the "warnings" extension of the response instead of the errors. Fields of non-null enum types
+// still fail with an error, since null is no valid value for them.
+func LenientEnumOutput() SchemaOpt {
+	return func(s *Schema) {
+		s.lenientEnumOutput = true
+	}
+}
+
+// warningsExtension is the key of the extension holding the warnings of a response.
+const warningsExtension = "warnings"
+
+// splitWarnings separates the validation errors which are reported as warnings from errs.
+func (s *Schema) splitWarnings(errs []*errors.QueryError) (_ []*errors.QueryError, warnings []*errors.QueryError) {
+	if !s.variableWarnings {
+		return errs, nil
+	}
+	var rest []*errors.QueryError
+	for _, err := range errs {
+		if err.Rule == "NoUnusedVariablesRule" || err.Rule == "NoUndefinedVariablesRule" {
+			warnings = append(warnings, err)
+			continue
+		}
+		rest = append(rest, err)
+	}
+	return rest, warnings
+}
+
+// SortResponseKeys orders the fields of every object in the response alphabetically by their response
+// keys. By default fields are ordered as they are selected in the query, as the GraphQL specification
+// requires, which is deterministic as well, independent of the order in which resolvers finish. Sorting
+// makes responses of differently ordered but otherwise equal queries identical, e.g. for hashing.
+func SortResponseKeys() SchemaOpt {
+	return func(s *Schema) {
+		s.sortResponseKeys = true
+	}
+}
+
+// RegisterInputUnion makes input objects unpack into a Go interface implemented by several structs.
+// iface is a pointer to the interface, e.g. (*Shape)(nil). The value of the discriminator input field
+// selects the implementing type from members, which maps each value to a zero value of that type.
+// Input fields which the selected type doesn't define are ignored. For example:
+//
+//	graphql.RegisterInputUnion((*Shape)(nil), "kind", map[string]interface{}{
+//		"CIRCLE": &Circle{},
+//		"SQUARE": &Square{},
+//	})
+func RegisterInputUnion(iface interface{}, discriminator string, members map[string]interface{}) SchemaOpt {
+	return func(s *Schema) {
+		s.inputUnions = append(s.inputUnions, inputUnion{iface: iface, discriminator: discriminator, members: members})
+	}
+}
+
+type inputUnion struct {
+	iface         interface{}
+	discriminator string
+	members       map[string]interface{}
+}
+
+// FieldMethods can be implemented by resolvers whose Go method names don't match the names of
+// the GraphQL fields they resolve. GraphQLFieldMethods maps GraphQL field names to Go method
+// names and takes precedence over the default name matching. It is called once when the schema
+// is parsed, on the zero value of the resolver type, e.g.:
+//
+//	func (*userResolver) GraphQLFieldMethods() map[string]string {
+//		return map[string]string{"type": "Kind"}
+//	}
+type FieldMethods interface {
+	GraphQLFieldMethods() map[string]string
+}
+
+// FieldDirectives returns the directives applied in the schema to the field whose resolver is called
+// with ctx. It allows generic resolvers to be configured by annotations, e.g. a resolver proxying
+// a REST API could read the URL from a `@rest(url: "...")` directive:
+//
+//	func (r *Resolver) User(ctx context.Context) (*User, error) {
+//		if d := graphql.FieldDirectives(ctx).Get("rest"); d != nil {
+//			url, _ := d.ArgumentValue("url")
+//			...
+//		}
+//	}
+//
+// Directive visitors observe the same directives in the context passed to them.
+func FieldDirectives(ctx context.Context) ast.DirectiveList {
+	return exec.FieldDirectives(ctx)
+}
+
+// FieldInfo describes the field whose resolver is called, see [CurrentField].
+type FieldInfo struct {
+	TypeName  string
+	FieldName string
+	// Parent is the resolver of the object the field belongs to.
+	Parent interface{}
+	// Root is true for the fields of the root operation types, e.g. Query.
+	Root bool
+	// Variables are the values of the variables of the request.
+	Variables map[string]interface{}
+}
+
+// CurrentField returns the field whose resolver is called with ctx. Like [FieldDirectives] it is
+// only available for fields with directives applied in the schema, which allows directive visitors
+// to tell the fields they are applied to apart.
+func CurrentField(ctx context.Context) (FieldInfo, bool) {
+	f, ok := exec.Field(ctx)
+	return FieldInfo(f), ok
+}
+
 // Response represents a typical response of a GraphQL server. It may be encoded to JSON directly or
 // it may be further processed to a custom response type, for example to include custom error data.
 // Errors are intentionally serialized first based on the advice in the [spec].
@@ -229,6 +1072,40 @@ type Response struct {
 	Errors     []*errors.QueryError   `json:"errors,omitempty"`
 	Data       json.RawMessage        `json:"data,omitempty"`
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
+
+	// Stats summarizes the execution of the request. It is only set if the [CollectStats]
+	// option is used and is not part of the JSON encoding.
+	Stats *Stats `json:"-"`
+
+	// Completion is set on the final response of a subscription ended by its resolver with a
+	// [Completion]. It is not part of the JSON encoding.
+	Completion CompletionReason `json:"-"`
+}
+
+// Stats summarizes the execution of a request.
+type Stats struct {
+	// ResolverCount is the number of resolver calls.
+	ResolverCount int
+	// MaxDepth is the deepest field nesting level which was executed.
+	MaxDepth int
+	// Duration is the time it took to process the request, including parsing and validation.
+	Duration time.Duration
+	// CacheHits is the number of results served from a cache instead of the resolvers.
+	CacheHits int
+}
+
+// CachePolicy returns the cache policy computed for the response. It returns false if
+// the [CacheControl] schema option is not used.
+func (r *Response) CachePolicy() (cachecontrol.Policy, bool) {
+	p, ok := r.Extensions[cachecontrol.ExtensionKey].(cachecontrol.Policy)
+	return p, ok
+}
+
+func (r *Response) setExtension(key string, value interface{}) {
+	if r.Extensions == nil {
+		r.Extensions = make(map[string]interface{})
+	}
+	r.Extensions[key] = value
 }
 
 // Validate validates the given query with the schema.
@@ -238,43 +1115,160 @@ func (s *Schema) Validate(queryString string) []*errors.QueryError {
 
 // ValidateWithVariables validates the given query with the schema and the input variables.
 func (s *Schema) ValidateWithVariables(queryString string, variables map[string]interface{}) []*errors.QueryError {
-	doc, qErr := query.Parse(queryString)
+	doc, qErr := parseQuery(queryString, s.parseLimits)
 	if qErr != nil {
 		return []*errors.QueryError{qErr}
 	}
 
-	return validation.Validate(s.schema, doc, variables, s.maxDepth)
+	return s.validate(context.Background(), doc, variables)
+}
+
+func (s *Schema) validate(ctx context.Context, doc *ast.ExecutableDefinition, variables map[string]interface{}) []*errors.QueryError {
+	opts := validation.Options{
+		MaxDepth:         s.maxDepth,
+		MaxInputDepth:    s.maxInputDepth,
+		ScalarValidators: s.scalarValidators,
+	}
+	if s.visibilityFilter != nil {
+		opts.Visible = func(typeName, fieldName string) bool {
+			return s.visibilityFilter(ctx, VisibilityInfo{TypeName: typeName, FieldName: fieldName})
+		}
+	}
+	errs := validation.ValidateWithOptions(s.schema, doc, variables, opts)
+	for _, err := range errs {
+		if err.Rule == "MaxDepthExceeded" || err.Rule == "MaxInputDepthExceeded" {
+			err.SetCode(errors.CodeMaxDepthExceeded)
+		} else {
+			err.SetCode(errors.CodeValidationFailed)
+		}
+	}
+	return errs
+}
+
+// Operations parses the query document and returns its operations in the order of their definition,
+// e.g. to let tools choose the operation name to execute. Anonymous operations have an empty name.
+// Errors are returned if the document can not be parsed, if it contains an anonymous operation next
+// to other operations or if operation names are not unique.
+func (s *Schema) Operations(queryString string) ([]*ast.OperationDefinition, []*errors.QueryError) {
+	doc, qErr := s.parse(context.Background(), queryString)
+	if qErr != nil {
+		return nil, []*errors.QueryError{qErr}
+	}
+	var errs []*errors.QueryError
+	for _, err := range s.validate(context.Background(), doc, nil) {
+		if err.Rule == "LoneAnonymousOperationRule" || err.Rule == "UniqueOperationNamesRule" {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	return doc.Operations, nil
+}
+
+// CanExec reports whether the schema was created with a resolver. Schemas without a resolver can
+// only be used to validate queries, e.g. when a schema is shared by a validation service.
+func (s *Schema) CanExec() bool {
+	return s.res.QueryResolver.IsValid()
+}
+
+// noResolverResponse is the response of requests executed with a schema created without a resolver.
+func noResolverResponse() *Response {
+	err := errors.Errorf("schema created without resolver, can not exec").SetCode(errors.CodeNoResolver)
+	return &Response{Errors: []*errors.QueryError{err}}
 }
 
-// Exec executes the given query with the schema's resolver. It panics if the schema was created
-// without a resolver. If the context get cancelled, no further resolvers will be called and a
-// the context error will be returned as soon as possible (not immediately).
+// Exec executes the given query with the schema's resolver. If the schema was created without a
+// resolver, the response holds an error, see [Schema.CanExec]. If the context get cancelled, no
+// further resolvers will be called and a the context error will be returned as soon as possible (not
+// immediately).
 func (s *Schema) Exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) *Response {
-	if !s.res.QueryResolver.IsValid() {
-		panic("schema created without resolver, can not exec")
+	if !s.CanExec() {
+		return noResolverResponse()
 	}
-	return s.exec(ctx, queryString, operationName, variables, s.res)
+	return s.execWithStats(ctx, nil, queryString, operationName, variables, s.res)
 }
 
-func (s *Schema) exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) *Response {
-	if s.maxQueryLength > 0 && len(queryString) > s.maxQueryLength {
-		return &Response{Errors: []*errors.QueryError{errors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(queryString), s.maxQueryLength)}}
+// ExecWithRoot executes the given query like [Schema.Exec], but with root as the root resolver instead
+// of the resolver passed to [ParseSchema]. It allows the root resolver to be created per request, e.g. to
+// carry the authenticated user or a database transaction. root must have the same type as the resolver
+// of the schema, which is only used to check the resolvers against the schema.
+func (s *Schema) ExecWithRoot(ctx context.Context, root interface{}, queryString string, operationName string, variables map[string]interface{}) *Response {
+	if !s.CanExec() {
+		return noResolverResponse()
 	}
-	doc, qErr := query.Parse(queryString)
-	if qErr != nil {
-		return &Response{Errors: []*errors.QueryError{qErr}}
+	res, err := s.res.WithRoot(root)
+	if err != nil {
+		return &Response{Errors: []*errors.QueryError{errors.Errorf("%s", err).SetCode(errors.CodeNoResolver)}}
+	}
+	return s.execWithStats(ctx, nil, queryString, operationName, variables, res)
+}
+
+// execWithStats executes queryString like exec and collects the statistics of the request.
+func (s *Schema) execWithStats(ctx context.Context, doc *ast.ExecutableDefinition, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) *Response {
+	if !s.collectStats && s.requestLogger == nil {
+		return s.exec(ctx, doc, queryString, operationName, variables, res)
+	}
+
+	start := time.Now()
+	var resp *Response
+	if doc == nil {
+		var qErr *errors.QueryError
+		if doc, qErr = s.parseRequest(ctx, queryString); qErr != nil {
+			resp = &Response{Errors: []*errors.QueryError{qErr}}
+		}
+	}
+	// anonymous operations are logged with their name in the document, if any
+	name := operationName
+	if resp == nil {
+		if op, err := getOperation(doc, operationName); err == nil {
+			name = op.Name.Name
+		}
+		resp = s.exec(ctx, doc, queryString, operationName, variables, res)
+	}
+	duration := time.Since(start)
+	if s.collectStats {
+		if resp.Stats == nil {
+			resp.Stats = &Stats{}
+		}
+		resp.Stats.Duration = duration
+	}
+	if s.requestLogger != nil {
+		s.requestLogger.LogRequest(ctx, log.Request{
+			OperationName: name,
+			Duration:      duration,
+			ErrorCount:    len(resp.Errors),
+			Variables:     log.Redact(variables, s.redactedVariables),
+		})
+	}
+	return resp
+}
+
+// exec executes queryString. doc is the parsed queryString, or nil if it was not parsed yet.
+func (s *Schema) exec(ctx context.Context, doc *ast.ExecutableDefinition, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) (resp *Response) {
+	if doc == nil {
+		var qErr *errors.QueryError
+		if doc, qErr = s.parseRequest(ctx, queryString); qErr != nil {
+			return &Response{Errors: []*errors.QueryError{qErr}}
+		}
 	}
 
 	validationFinish := s.validationTracer.TraceValidation(ctx)
-	errs := validation.Validate(s.schema, doc, variables, s.maxDepth)
+	errs := s.validate(ctx, doc, variables)
 	validationFinish(errs)
+	errs, warnings := s.splitWarnings(errs)
 	if len(errs) != 0 {
 		return &Response{Errors: errs}
 	}
+	defer func() {
+		if len(warnings) != 0 {
+			resp.setExtension(warningsExtension, warnings)
+		}
+	}()
 
 	op, err := getOperation(doc, operationName)
 	if err != nil {
-		return &Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}}
+		return &Response{Errors: []*errors.QueryError{operationError(err)}}
 	}
 
 	// If the optional "operationName" POST parameter is not provided then
@@ -285,11 +1279,13 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 
 	// Subscriptions are not valid in Exec. Use schema.Subscribe() instead.
 	if op.Type == query.Subscription {
-		return &Response{Errors: []*errors.QueryError{{Message: "graphql-ws protocol header is missing"}}}
+		err := &errors.QueryError{Message: "graphql-ws protocol header is missing"}
+		return &Response{Errors: []*errors.QueryError{err.SetCode(errors.CodeOperationNotSupported)}}
 	}
 	if op.Type == query.Mutation {
 		if _, ok := s.schema.RootOperationTypes["mutation"]; !ok {
-			return &Response{Errors: []*errors.QueryError{{Message: "no mutations are offered by the schema"}}}
+			err := &errors.QueryError{Message: "no mutations are offered by the schema"}
+			return &Response{Errors: []*errors.QueryError{err.SetCode(errors.CodeOperationNotSupported)}}
 		}
 	}
 
@@ -303,17 +1299,69 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 		}
 	}
 
+	if err := s.estimateCost(ctx, doc, op, variables); err != nil {
+		return &Response{Errors: []*errors.QueryError{err}}
+	}
+
+	allowIntrospection := s.allowIntrospection == nil || s.allowIntrospection(ctx) // allow introspection by default, i.e. when allowIntrospection is nil
+	var cacheReq *responsecache.Request
+	if s.responseCache != nil && op.Type == query.Query {
+		cacheReq = &responsecache.Request{
+			Query:         queryString,
+			OperationName: operationName,
+			Variables:     variables,
+			Introspection: allowIntrospection,
+			// the response depends on the caller if the schema or the root resolver is chosen per request
+			Private: s.visibilityFilter != nil || s.introspectionFilter != nil || res != s.res,
+		}
+		if data, policy, ok := s.responseCache.Lookup(ctx, *cacheReq); ok {
+			resp := &Response{Data: data}
+			if s.collectStats {
+				resp.Stats = &Stats{CacheHits: 1}
+			}
+			if s.cacheControl {
+				resp.setExtension(cachecontrol.ExtensionKey, policy)
+			}
+			return resp
+		}
+	}
+
 	r := &exec.Request{
 		Request: selected.Request{
-			Doc:                doc,
-			Vars:               variables,
-			Schema:             s.schema,
-			AllowIntrospection: s.allowIntrospection == nil || s.allowIntrospection(ctx), // allow introspection by default, i.e. when allowIntrospection is nil
+			Doc:                 doc,
+			Vars:                variables,
+			Schema:              s.schema,
+			AllowIntrospection:  allowIntrospection,
+			IntrospectionFilter: s.introspectionFilterFor(ctx),
+			CoercionFailed:      s.coercionFailedFor(ctx),
 		},
-		Limiter:      make(chan struct{}, s.maxParallelism),
-		Tracer:       s.tracer,
-		Logger:       s.logger,
-		PanicHandler: s.panicHandler,
+		Limiter:             make(chan struct{}, s.maxParallelism),
+		Tracer:              s.tracer,
+		SkipTracer:          s.skipTracer,
+		Logger:              s.logger,
+		PanicHandler:        s.panicHandler,
+		DisableNullBubbling: s.disableNullBubbling,
+		RetryPolicy:         s.retryPolicy,
+		SortResponseKeys:    s.sortResponseKeys,
+		Marshal:             s.encoder.Marshal,
+		MaxResponseBytes:    s.maxResponseBytes,
+		DeduplicateFields:   s.deduplicateFields,
+		LenientEnums:        s.lenientEnumOutput,
+		PlanMutation:        s.planMutation,
+	}
+	if s.cacheControl || s.responseCache != nil {
+		defaultMaxAge := s.defaultMaxAge
+		if !s.cacheControl {
+			defaultMaxAge = s.responseCache.TTL
+		}
+		r.CacheControl = cachecontrol.NewCollector(defaultMaxAge)
+		ctx = cachecontrol.WithCollector(ctx, r.CacheControl)
+	}
+	if s.profiling {
+		r.Profiler = exec.NewProfiler(profileSize)
+	}
+	if s.collectStats {
+		r.Stats = &exec.Stats{}
 	}
 	varTypes := make(map[string]*introspection.Type)
 	for _, v := range op.Vars {
@@ -324,13 +1372,115 @@ func (s *Schema) exec(ctx context.Context, queryString string, operationName str
 		varTypes[v.Name.Name] = introspection.WrapType(t)
 	}
 	traceCtx, finish := s.tracer.TraceQuery(ctx, queryString, operationName, variables, varTypes)
-	data, errs := r.Execute(traceCtx, res, op)
+	data, errs, executed := s.execute(traceCtx, r, res, op, queryString)
 	finish(errs)
+	warnings = append(warnings, r.Warnings()...)
 
-	return &Response{
+	resp = &Response{
 		Data:   data,
 		Errors: errs,
 	}
+	if r.Stats != nil {
+		resp.Stats = &Stats{
+			ResolverCount: r.Stats.ResolverCount(),
+			MaxDepth:      r.Stats.MaxDepth(),
+		}
+	}
+	if r.CacheControl != nil {
+		policy := r.CacheControl.Policy()
+		if len(errs) != 0 || op.Type == query.Mutation {
+			// responses with errors and mutation results must never be cached
+			policy.MaxAge = 0
+		}
+		if s.cacheControl {
+			resp.setExtension(cachecontrol.ExtensionKey, policy)
+		}
+		if cacheReq != nil {
+			s.responseCache.Save(ctx, *cacheReq, data, policy)
+		}
+	}
+	if s.responseCache != nil && op.Type == query.Mutation && executed {
+		// fields of the mutation may have written data even if others failed
+		s.responseCache.MutationExecuted(ctx, operationName)
+	}
+	if r.Profiler != nil {
+		resp.setExtension("profile", r.Profiler.Slowest())
+	}
+	return resp
+}
+
+// execute executes op of the document queryString with the timeout of its operation type, if any.
+// executed reports whether the resolvers ran, i.e. op was not rejected by [MaxConcurrentOperations].
+func (s *Schema) execute(ctx context.Context, r *exec.Request, res *resolvable.Schema, op *ast.OperationDefinition, queryString string) (data []byte, errs []*errors.QueryError, executed bool) {
+	release, err := s.acquireOperation(ctx)
+	if err != nil {
+		return nil, []*errors.QueryError{err}, false
+	}
+	defer release()
+
+	ctx, a := s.operations.start(ctx, op, queryString)
+	data, errs = s.executeWithTimeout(ctx, r, res, op)
+	if s.operations.finish(a) {
+		return nil, []*errors.QueryError{cancelledError(op.Type)}, true
+	}
+	return data, errs, true
+}
+
+// executeWithTimeout executes op with the timeout of its operation type, if any.
+func (s *Schema) executeWithTimeout(ctx context.Context, r *exec.Request, res *resolvable.Schema, op *ast.OperationDefinition) ([]byte, []*errors.QueryError) {
+	var timeout time.Duration
+	switch op.Type {
+	case query.Query:
+		timeout = s.queryTimeout
+	case query.Mutation:
+		timeout = s.mutationTimeout
+	}
+	if timeout <= 0 {
+		return r.Execute(ctx, res, op)
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	data, errs := r.Execute(execCtx, res, op)
+	if ctx.Err() == nil && execCtx.Err() == context.DeadlineExceeded {
+		return nil, []*errors.QueryError{timeoutError(op.Type, timeout)}
+	}
+	return data, errs
+}
+
+// timeoutError is returned for operations of type t which exceeded their timeout d.
+func timeoutError(t ast.OperationType, d time.Duration) *errors.QueryError {
+	err := errors.Errorf("%s timed out after %s", strings.ToLower(string(t)), d)
+	err.Err = context.DeadlineExceeded
+	return err.SetCode(errors.CodeTimeout)
+}
+
+// profileSize is the number of resolver calls reported when profiling is enabled.
+const profileSize = 10
+
+// parseRequest parses the query of a request after checking its length against MaxQueryLength.
+func (s *Schema) parseRequest(ctx context.Context, queryString string) (*ast.ExecutableDefinition, *errors.QueryError) {
+	if s.maxQueryLength > 0 && len(queryString) > s.maxQueryLength {
+		err := errors.Errorf("query length %d exceeds the maximum allowed query length of %d bytes", len(queryString), s.maxQueryLength)
+		return nil, err.SetCode(errors.CodeQueryTooLong)
+	}
+	return s.parse(ctx, queryString)
+}
+
+func (s *Schema) parse(ctx context.Context, queryString string) (*ast.ExecutableDefinition, *errors.QueryError) {
+	finish := s.tracer.TraceParse(ctx, queryString)
+	doc, err := parseQuery(queryString, s.parseLimits)
+	finish(err)
+	return doc, err
+}
+
+// parseQuery parses queryString like [query.ParseWithLimits] and sets the code of the error, if any.
+func parseQuery(queryString string, limits query.Limits) (*ast.ExecutableDefinition, *errors.QueryError) {
+	doc, err := query.ParseWithLimits(queryString, limits)
+	if err != nil {
+		err.SetCode(errors.CodeParseFailed)
+	}
+	return doc, err
 }
 
 func (s *Schema) validateSchema() error {
@@ -349,7 +1499,7 @@ func (s *Schema) validateSchema() error {
 	if err := validateRootOp(s.schema, "subscription", false); err != nil {
 		return err
 	}
-	return nil
+	return validation.ValidateArgumentConstraints(s.schema)
 }
 
 type validationBridgingTracer struct {
@@ -374,6 +1524,11 @@ func validateRootOp(s *ast.Schema, name string, mandatory bool) error {
 	return nil
 }
 
+// operationError converts an error of getOperation into a query error.
+func operationError(err error) *errors.QueryError {
+	return errors.Errorf("%s", err).SetCode(errors.CodeOperationResolutionFailure)
+}
+
 func getOperation(document *ast.ExecutableDefinition, operationName string) (*ast.OperationDefinition, error) {
 	if len(document.Operations) == 0 {
 		return nil, fmt.Errorf("no operations in query document")
@@ -381,7 +1536,7 @@ func getOperation(document *ast.ExecutableDefinition, operationName string) (*as
 
 	if operationName == "" {
 		if len(document.Operations) > 1 {
-			return nil, fmt.Errorf("more than one operation in query document and no operation name given")
+			return nil, fmt.Errorf("more than one operation in query document and no operation name given, the document defines %s", operationNames(document))
 		}
 		for _, op := range document.Operations {
 			return op, nil // return the one and only operation
@@ -390,7 +1545,19 @@ func getOperation(document *ast.ExecutableDefinition, operationName string) (*as
 
 	op := document.Operations.Get(operationName)
 	if op == nil {
-		return nil, fmt.Errorf("no operation with name %q", operationName)
+		return nil, fmt.Errorf("no operation with name %q, the document defines %s", operationName, operationNames(document))
 	}
 	return op, nil
 }
+
+func operationNames(document *ast.ExecutableDefinition) string {
+	names := make([]string, len(document.Operations))
+	for i, op := range document.Operations {
+		if op.Name.Name == "" {
+			names[i] = "an anonymous " + strings.ToLower(string(op.Type))
+			continue
+		}
+		names[i] = strconv.Quote(op.Name.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -1,22 +1,33 @@
 package graphql_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
 	"github.com/graph-gophers/graphql-go/directives"
 	gqlerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/example/social"
 	"github.com/graph-gophers/graphql-go/example/starwars"
 	"github.com/graph-gophers/graphql-go/gqltesting"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/log"
+	"github.com/graph-gophers/graphql-go/metrics"
+	"github.com/graph-gophers/graphql-go/retry"
+	"github.com/graph-gophers/graphql-go/trace/noop"
 	"github.com/graph-gophers/graphql-go/trace/tracer"
 )
 
@@ -1190,7 +1201,7 @@ func TestRootOperations_validSchema(t *testing.T) {
 					}
 				}
 			`,
-			ExpectedErrors: []*gqlerrors.QueryError{{Message: "no mutations are offered by the schema"}},
+			ExpectedErrors: []*gqlerrors.QueryError{{Message: "no mutations are offered by the schema", Extensions: map[string]interface{}{"code": gqlerrors.CodeOperationNotSupported}}},
 		},
 		{
 			// Explicit schema without mutation field
@@ -1215,7 +1226,7 @@ func TestRootOperations_validSchema(t *testing.T) {
 					}
 				}
 			`,
-			ExpectedErrors: []*gqlerrors.QueryError{{Message: "no mutations are offered by the schema"}},
+			ExpectedErrors: []*gqlerrors.QueryError{{Message: "no mutations are offered by the schema", Extensions: map[string]interface{}{"code": gqlerrors.CodeOperationNotSupported}}},
 		},
 	})
 }
@@ -1391,6 +1402,7 @@ func TestNilInterface(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       "x",
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
 					Path:          []interface{}{"b"},
 					ResolverError: errors.New("x"),
 				},
@@ -1429,6 +1441,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       droidNotFoundError.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					Path:          []interface{}{"findDroids", 1, "name"},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
@@ -1471,6 +1484,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       droidNotFoundError.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					Path:          []interface{}{"findDroids", 1, "name"},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
@@ -1504,8 +1518,9 @@ func TestErrorPropagationInLists(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: `graphql: got nil for non-null "Droid"`,
-					Path:    []interface{}{"findNilDroids", 1},
+					Message:    `graphql: got nil for non-null "Droid"`,
+					Path:       []interface{}{"findNilDroids", 1},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 			},
 		},
@@ -1582,6 +1597,7 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errQuote.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 7}},
 					ResolverError: errQuote,
 					Path:          []interface{}{"findDroids", 0, "quotes"},
 				},
@@ -1617,12 +1633,14 @@ func TestErrorPropagationInLists(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errQuote.Error(),
+					Locations:     []gqlerrors.Location{{Line: 5, Column: 7}},
 					ResolverError: errQuote,
 					Path:          []interface{}{"findNilDroids", 0, "quotes"},
 				},
 				{
-					Message: `graphql: got nil for non-null "Droid"`,
-					Path:    []interface{}{"findNilDroids", 1},
+					Message:    `graphql: got nil for non-null "Droid"`,
+					Path:       []interface{}{"findNilDroids", 1},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 			},
 		},
@@ -1661,6 +1679,7 @@ func TestErrorWithExtensions(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       droidNotFoundError.Error(),
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
 					Path:          []interface{}{"FindDroid"},
 					ResolverError: droidNotFoundError,
 					Extensions:    map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message},
@@ -1697,6 +1716,7 @@ func TestErrorWithNoExtensions(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       err.Error(),
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
 					Path:          []interface{}{"DismissVader"},
 					ResolverError: err,
 					Extensions:    nil,
@@ -1706,6 +1726,114 @@ func TestErrorWithNoExtensions(t *testing.T) {
 	})
 }
 
+type nullBubblingResolver struct{}
+
+func (*nullBubblingResolver) Human() *nullBubblingResolver { return &nullBubblingResolver{} }
+
+func (*nullBubblingResolver) Name() string { return "Luke" }
+
+func (*nullBubblingResolver) Friends() []*nullBubblingResolver {
+	return []*nullBubblingResolver{{}, nil}
+}
+
+func TestNullBubblingDisabled(t *testing.T) {
+	t.Parallel()
+
+	schema := `
+		type Query {
+			human: Human
+		}
+
+		type Human {
+			name: String!
+			friends: [Human!]!
+		}
+	`
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(schema, &nullBubblingResolver{}),
+			Query:  `{ human { name friends { name } } }`,
+			ExpectedResult: `
+				{
+					"human": null
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `graphql: got nil for non-null "Human"`,
+				Path:       []interface{}{"human", "friends", 1},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
+			}},
+		},
+		{
+			Schema: graphql.MustParseSchema(schema, &nullBubblingResolver{}, graphql.NullBubblingDisabled()),
+			Query:  `{ human { name friends { name } } }`,
+			ExpectedResult: `
+				{
+					"human": {
+						"name": "Luke",
+						"friends": [{"name": "Luke"}, null]
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `graphql: got nil for non-null "Human"`,
+				Path:       []interface{}{"human", "friends", 1},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
+			}},
+		},
+	})
+}
+
+type wrappedErrorResolver struct{}
+
+func (*wrappedErrorResolver) Wrapped() (string, error) {
+	return "", fmt.Errorf("lookup failed: %w", droidNotFoundError)
+}
+
+func (*wrappedErrorResolver) Coded() (string, error) {
+	return "", gqlerrors.WithCode(errQuote, "QUOTE")
+}
+
+func TestErrorWithWrappedExtensions(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			wrapped: String!
+			coded: String!
+		}
+	`, &wrappedErrorResolver{})
+
+	resp := schema.Exec(context.Background(), `{ wrapped }`, "", nil)
+	if len(resp.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", resp.Errors)
+	}
+	err := resp.Errors[0]
+	if want := map[string]interface{}{"code": droidNotFoundError.Code, "message": droidNotFoundError.Message}; !reflect.DeepEqual(err.Extensions, want) {
+		t.Errorf("want extensions %v, got %v", want, err.Extensions)
+	}
+	var target resolverNotFoundError
+	if !errors.As(err, &target) || target != droidNotFoundError {
+		t.Errorf("expected the resolver error to be preserved in the chain")
+	}
+
+	resp = schema.Exec(context.Background(), `{ coded }`, "", nil)
+	if len(resp.Errors) != 1 {
+		t.Fatalf("expected one error, got %v", resp.Errors)
+	}
+	err = resp.Errors[0]
+	if want := map[string]interface{}{"code": "QUOTE"}; !reflect.DeepEqual(err.Extensions, want) {
+		t.Errorf("want extensions %v, got %v", want, err.Extensions)
+	}
+	if !errors.Is(err, errQuote) || !errors.Is(err.ResolverError, errQuote) {
+		t.Errorf("expected errors.Is to find the original error")
+	}
+	if err.Message != errQuote.Error() {
+		t.Errorf("unexpected message %q", err.Message)
+	}
+}
+
 func TestArguments(t *testing.T) {
 	gqltesting.RunTests(t, []*gqltesting.Test{
 		{
@@ -2093,6 +2221,151 @@ func TestIncludeDirective(t *testing.T) {
 	})
 }
 
+func TestFragmentDefinitionDirectives(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.SkipIncludeOnFragmentDefinitions())
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				query Hero($withFriends: Boolean!) {
+					hero {
+						name
+						...friendsFragment
+					}
+				}
+
+				fragment friendsFragment on Character @include(if: $withFriends) {
+					friends {
+						name
+					}
+				}
+			`,
+			Variables: map[string]interface{}{
+				"withFriends": false,
+			},
+			ExpectedResult: `
+				{
+					"hero": {
+						"name": "R2-D2"
+					}
+				}
+			`,
+		},
+
+		{
+			Schema: schema,
+			Query: `
+				query Hero($skipFriends: Boolean!) {
+					hero {
+						name
+						...friendsFragment
+					}
+				}
+
+				fragment friendsFragment on Character @skip(if: $skipFriends) {
+					friends {
+						name
+					}
+				}
+			`,
+			Variables: map[string]interface{}{
+				"skipFriends": false,
+			},
+			ExpectedResult: `
+				{
+					"hero": {
+						"name": "R2-D2",
+						"friends": [
+							{
+								"name": "Luke Skywalker"
+							},
+							{
+								"name": "Han Solo"
+							},
+							{
+								"name": "Leia Organa"
+							}
+						]
+					}
+				}
+			`,
+		},
+
+		{
+			Schema: starwarsSchema,
+			Query: `
+				query Hero($skipFriends: Boolean!) {
+					hero {
+						...friendsFragment
+					}
+				}
+
+				fragment friendsFragment on Character @skip(if: $skipFriends) {
+					name
+				}
+			`,
+			Variables: map[string]interface{}{
+				"skipFriends": true,
+			},
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:    `Directive "@skip" may not be used on FRAGMENT_DEFINITION.`,
+					Locations:  []gqlerrors.Location{{Line: 8, Column: 43}},
+					Rule:       "KnownDirectivesRule",
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+				},
+			},
+		},
+
+		{
+			Schema: starwarsSchema,
+			Query: `
+				query Hero($skip: String!) {
+					hero @unknown {
+						name @skip(if: $skip)
+					}
+				}
+			`,
+			Variables: map[string]interface{}{
+				"skip": "yes",
+			},
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:    `Unknown directive "@unknown".`,
+					Locations:  []gqlerrors.Location{{Line: 3, Column: 11}},
+					Rule:       "KnownDirectivesRule",
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+				},
+				{
+					Message:    `Variable "$skip" of type "String!" used in position expecting type "Boolean!".`,
+					Locations:  []gqlerrors.Location{{Line: 2, Column: 16}, {Line: 4, Column: 22}},
+					Rule:       "VariablesInAllowedPositionRule",
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+				},
+			},
+		},
+
+		{
+			Schema: starwarsSchema,
+			Query: `
+				query Hero @skip(if: true) {
+					hero {
+						name
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:    `Directive "@skip" may not be used on QUERY.`,
+					Locations:  []gqlerrors.Location{{Line: 2, Column: 16}},
+					Rule:       "KnownDirectivesRule",
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+				},
+			},
+		},
+	})
+}
+
 type testDeprecatedDirectiveResolver struct{}
 
 func (r *testDeprecatedDirectiveResolver) A() int32 {
@@ -2299,9 +2572,10 @@ func TestEnums(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message:   "Argument \"episode\" has invalid value WRATH_OF_KHAN.\nExpected type \"Episode\", found WRATH_OF_KHAN.",
-					Locations: []gqlerrors.Location{{Column: 20, Line: 3}},
-					Rule:      "ArgumentsOfCorrectType",
+					Message:    "Argument \"episode\" has invalid value WRATH_OF_KHAN.\nExpected type \"Episode\", found WRATH_OF_KHAN.",
+					Locations:  []gqlerrors.Location{{Column: 20, Line: 3}},
+					Rule:       "ArgumentsOfCorrectType",
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
 				},
 			},
 		},
@@ -2337,9 +2611,10 @@ func TestEnums(t *testing.T) {
 			Variables: map[string]interface{}{"episode": "FINAL_FRONTIER"},
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message:   "Variable \"episode\" has invalid value FINAL_FRONTIER.\nExpected type \"Episode\", found FINAL_FRONTIER.",
-					Locations: []gqlerrors.Location{{Column: 26, Line: 2}},
-					Rule:      "VariablesOfCorrectType",
+					Message:    "Variable \"episode\" has invalid value FINAL_FRONTIER.\nExpected type \"Episode\", found FINAL_FRONTIER.",
+					Locations:  []gqlerrors.Location{{Column: 26, Line: 2}},
+					Rule:       "VariablesOfCorrectType",
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
 				},
 			},
 		},
@@ -2398,8 +2673,9 @@ func TestEnums(t *testing.T) {
 			}`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: "Invalid value STAR_TREK.\nExpected type Episode, found STAR_TREK.",
-					Path:    []interface{}{"hero", "appearsIn", 0},
+					Message:    "Invalid value STAR_TREK.\nExpected type Episode, found STAR_TREK.",
+					Path:       []interface{}{"hero", "appearsIn", 0},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeInvalidEnumValue},
 				},
 			},
 		},
@@ -3195,8 +3471,9 @@ func TestIntrospection(t *testing.T) {
 									"description": "Marks an element of a GraphQL schema as no longer supported.",
 									"locations": [
 										"FIELD_DEFINITION",
-										"ENUM_VALUE",
-										"ARGUMENT_DEFINITION"
+										"ARGUMENT_DEFINITION",
+										"INPUT_FIELD_DEFINITION",
+										"ENUM_VALUE"
 									],
 									"args": [
 										{
@@ -3283,14 +3560,40 @@ func TestIntrospection(t *testing.T) {
 
 var starwarsSchemaNoIntrospection = graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, []graphql.SchemaOpt{graphql.DisableIntrospection()}...)
 
-func TestIntrospectionDisableIntrospection(t *testing.T) {
+type tenantKey struct{}
+
+func TestVisibilityFilter(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			me: User!
+			audit: [AuditEntry!]!
+		}
+
+		type User {
+			name: String!
+			salary: String!
+		}
+
+		type AuditEntry {
+			action: String!
+		}
+	`, &introspectionFilterResolver{}, graphql.VisibilityFilter(func(ctx context.Context, info graphql.VisibilityInfo) bool {
+		if ctx.Value(tenantKey{}) == "enterprise" {
+			return true
+		}
+		return info.TypeName != "AuditEntry" && info != graphql.VisibilityInfo{TypeName: "User", FieldName: "salary"}
+	}))
+	enterprise := context.WithValue(context.Background(), tenantKey{}, "enterprise")
+
 	gqltesting.RunTests(t, []*gqltesting.Test{
 		{
-			Schema: starwarsSchemaNoIntrospection,
+			Schema: schema,
 			Query: `
 				{
-					__schema {
-						types {
+					__type(name: "User") {
+						fields {
 							name
 						}
 					}
@@ -3298,84 +3601,220 @@ func TestIntrospectionDisableIntrospection(t *testing.T) {
 			`,
 			ExpectedResult: `
 				{
+					"__type": {
+						"fields": [
+							{"name": "name"}
+						]
+					}
 				}
 			`,
 		},
-
 		{
-			Schema: starwarsSchemaNoIntrospection,
+			Schema: schema,
 			Query: `
 				{
-					__schema {
-						queryType {
-							name
-						}
+					me {
+						salary
 					}
 				}
 			`,
-			ExpectedResult: `
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Cannot query field "salary" on type "User".`,
+				Locations:  []gqlerrors.Location{{Line: 4, Column: 7}},
+				Rule:       "FieldsOnCorrectTypeRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+		{
+			Schema: schema,
+			Query: `
 				{
+					audit {
+						action
+					}
 				}
 			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Cannot query field "audit" on type "Query".`,
+				Locations:  []gqlerrors.Location{{Line: 3, Column: 6}},
+				Rule:       "FieldsOnCorrectTypeRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
 		},
-
 		{
-			Schema: starwarsSchemaNoIntrospection,
+			Context: enterprise,
+			Schema:  schema,
 			Query: `
 				{
-					a: __type(name: "Droid") {
-						name
-						kind
-						interfaces {
-							name
-						}
-						possibleTypes {
-							name
-						}
-					},
-					b: __type(name: "Character") {
-						name
-						kind
-						interfaces {
-							name
-						}
-						possibleTypes {
-							name
-						}
+					me {
+						salary
 					}
-					c: __type(name: "SearchResult") {
-						name
-						kind
-						interfaces {
-							name
-						}
-						possibleTypes {
-							name
-						}
+					audit {
+						action
 					}
 				}
 			`,
 			ExpectedResult: `
 				{
+					"me": {
+						"salary": "secret"
+					},
+					"audit": []
 				}
 			`,
 		},
+	})
+}
 
-		{
-			Schema: starwarsSchemaNoIntrospection,
-			Query: `
-				{
-					__type(name: "Droid") {
-						name
-						fields {
-							name
-							args {
-								name
-								type {
-									name
-								}
-								defaultValue
-							}
+type visibilityInputResolver struct{}
+
+func (r *visibilityInputResolver) F(args struct {
+	In *struct {
+		Name   *string
+		Secret *string
+	}
+	Token *struct{ V *string }
+}) string {
+	if args.In != nil && args.In.Secret != nil || args.Token != nil {
+		return "leaked"
+	}
+	return "ok"
+}
+
+func TestVisibilityFilterInputs(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			f(in: In, token: Token): String!
+		}
+
+		input In {
+			name: String
+			secret: String
+		}
+
+		input Token {
+			v: String
+		}
+	`, &visibilityInputResolver{}, graphql.VisibilityFilter(func(ctx context.Context, info graphql.VisibilityInfo) bool {
+		return info.TypeName != "Token" && info != graphql.VisibilityInfo{TypeName: "In", FieldName: "secret"}
+	}))
+
+	for _, tt := range []struct {
+		query string
+		vars  map[string]interface{}
+		want  string
+	}{
+		{`{ f(in: {secret: "x"}) }`, nil, `Argument "in" has invalid value {secret: "x"}.` + "\nIn field \"secret\": Unknown field."},
+		{`query($in: In) { f(in: $in) }`, map[string]interface{}{"in": map[string]interface{}{"secret": "x"}}, `Variable "in" has invalid value.` + "\nField \"secret\" is not defined by type \"In\"."},
+		{`{ f(token: {v: "x"}) }`, nil, `Unknown argument "token" on field "Query.f".`},
+	} {
+		resp := schema.Exec(context.Background(), tt.query, "", tt.vars)
+		if len(resp.Errors) != 1 || resp.Errors[0].Message != tt.want {
+			t.Errorf("%s: got data %s and errors %v, want error %q", tt.query, resp.Data, resp.Errors, tt.want)
+		}
+	}
+
+	resp := schema.Exec(context.Background(), `{ f(in: {name: "x"}) }`, "", nil)
+	if got := string(resp.Data); got != `{"f":"ok"}` {
+		t.Errorf("got %s and errors %v for visible input fields", got, resp.Errors)
+	}
+}
+
+func TestIntrospectionDisableIntrospection(t *testing.T) {
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: starwarsSchemaNoIntrospection,
+			Query: `
+				{
+					__schema {
+						types {
+							name
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+				}
+			`,
+		},
+
+		{
+			Schema: starwarsSchemaNoIntrospection,
+			Query: `
+				{
+					__schema {
+						queryType {
+							name
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+				}
+			`,
+		},
+
+		{
+			Schema: starwarsSchemaNoIntrospection,
+			Query: `
+				{
+					a: __type(name: "Droid") {
+						name
+						kind
+						interfaces {
+							name
+						}
+						possibleTypes {
+							name
+						}
+					},
+					b: __type(name: "Character") {
+						name
+						kind
+						interfaces {
+							name
+						}
+						possibleTypes {
+							name
+						}
+					}
+					c: __type(name: "SearchResult") {
+						name
+						kind
+						interfaces {
+							name
+						}
+						possibleTypes {
+							name
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+				}
+			`,
+		},
+
+		{
+			Schema: starwarsSchemaNoIntrospection,
+			Query: `
+				{
+					__type(name: "Droid") {
+						name
+						fields {
+							name
+							args {
+								name
+								type {
+									name
+								}
+								defaultValue
+							}
 							type {
 								name
 								kind
@@ -3477,132 +3916,419 @@ func TestIntrospectionDisableIntrospection(t *testing.T) {
 	})
 }
 
-func TestMutationOrder(t *testing.T) {
+type deprecatedInputResolver struct{}
+
+func (*deprecatedInputResolver) Hello(args struct {
+	Name     *string
+	FullName string
+	Filter   *struct {
+		Old *string
+		New *string
+	}
+}) string {
+	return "Hello " + args.FullName
+}
+
+func TestIntrospectionDeprecatedInputValues(t *testing.T) {
 	t.Parallel()
 
-	gqltesting.RunTests(t, []*gqltesting.Test{
-		{
-			Schema: graphql.MustParseSchema(`
-				schema {
-					query: Query
-					mutation: Mutation
-				}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			hello(name: String @deprecated(reason: "Use fullName."), fullName: String! = "world", filter: Filter): String!
+		}
 
-				type Query {
-					theNumber: Int!
-				}
+		input Filter {
+			old: String @deprecated
+			new: String
+		}
+	`, &deprecatedInputResolver{})
 
-				type Mutation {
-					changeTheNumber(newNumber: Int!): Query
-				}
-			`, &theNumberResolver{}),
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
 			Query: `
-				mutation {
-					first: changeTheNumber(newNumber: 1) {
-						theNumber
-					}
-					second: changeTheNumber(newNumber: 3) {
-						theNumber
+				{
+					query: __type(name: "Query") {
+						fields {
+							args {
+								name
+							}
+							allArgs: args(includeDeprecated: true) {
+								name
+								isDeprecated
+								deprecationReason
+							}
+						}
 					}
-					third: changeTheNumber(newNumber: 2) {
-						theNumber
+					filter: __type(name: "Filter") {
+						inputFields {
+							name
+						}
+						allInputFields: inputFields(includeDeprecated: true) {
+							name
+							isDeprecated
+							deprecationReason
+						}
 					}
 				}
 			`,
 			ExpectedResult: `
 				{
-					"first": {
-						"theNumber": 1
-					},
-					"second": {
-						"theNumber": 3
+					"query": {
+						"fields": [
+							{
+								"args": [
+									{"name": "fullName"},
+									{"name": "filter"}
+								],
+								"allArgs": [
+									{"name": "name", "isDeprecated": true, "deprecationReason": "Use fullName."},
+									{"name": "fullName", "isDeprecated": false, "deprecationReason": null},
+									{"name": "filter", "isDeprecated": false, "deprecationReason": null}
+								]
+							}
+						]
 					},
-					"third": {
-						"theNumber": 2
+					"filter": {
+						"inputFields": [
+							{"name": "new"}
+						],
+						"allInputFields": [
+							{"name": "old", "isDeprecated": true, "deprecationReason": "No longer supported"},
+							{"name": "new", "isDeprecated": false, "deprecationReason": null}
+						]
 					}
 				}
 			`,
 		},
-	})
-}
-
-func TestTime(t *testing.T) {
-	t.Parallel()
-
-	gqltesting.RunTests(t, []*gqltesting.Test{
 		{
-			Schema: graphql.MustParseSchema(`
-				schema {
-					query: Query
-				}
-
-				type Query {
-					addHour(time: Time = "2001-02-03T04:05:06Z"): Time!
-				}
-
-				scalar Time
-			`, &timeResolver{}),
-			Query: `
-				query($t: Time!) {
-					a: addHour(time: $t)
-					b: addHour
-				}
-			`,
-			Variables: map[string]interface{}{
-				"t": time.Date(2000, 2, 3, 4, 5, 6, 0, time.UTC),
-			},
+			Schema: schema,
+			Query:  `{ hello(name: "x", filter: {old: "y"}) }`,
 			ExpectedResult: `
 				{
-					"a": "2000-02-03T05:05:06Z",
-					"b": "2001-02-03T05:05:06Z"
+					"hello": "Hello world"
 				}
 			`,
 		},
 	})
 }
 
-type resolverWithUnexportedMethod struct{}
+type testCounters struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
 
-func (r *resolverWithUnexportedMethod) changeTheNumber(args struct{ NewNumber int32 }) int32 { //lint:ignore U1000 ingore this for now
-	return args.NewNumber
+func (c *testCounters) IncCounter(ctx context.Context, name string, labels map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := fmt.Sprintf("%s{%s=%s %s=%s %s=%s %s=%s}", name,
+		metrics.LabelType, labels[metrics.LabelType],
+		metrics.LabelField, labels[metrics.LabelField],
+		metrics.LabelArgument, labels[metrics.LabelArgument],
+		metrics.LabelExpectedType, labels[metrics.LabelExpectedType])
+	c.counts[key]++
 }
 
-func TestUnexportedMethod(t *testing.T) {
+type metricsResolver struct{}
+
+func (*metricsResolver) Events(args struct{ Since []graphql.Time }) int32 {
+	return int32(len(args.Since))
+}
+
+func TestMetrics(t *testing.T) {
 	t.Parallel()
 
-	_, err := graphql.ParseSchema(`
-		schema {
-			mutation: Mutation
-		}
+	counters := &testCounters{counts: make(map[string]int)}
+	schema := graphql.MustParseSchema(`
+		scalar Time
 
-		type Mutation {
-			changeTheNumber(newNumber: Int!): Int!
+		type Query {
+			events(since: [Time!]!): Int!
 		}
-	`, &resolverWithUnexportedMethod{})
-	if err == nil {
-		t.Error("error expected")
+	`, &metricsResolver{}, graphql.Metrics(counters))
+
+	for i := 0; i < 2; i++ {
+		gqltesting.RunTest(t, &gqltesting.Test{
+			Schema: schema,
+			Query: `
+				query($since: [Time!]!) {
+					events(since: $since)
+				}
+			`,
+			Variables:      map[string]interface{}{"since": []interface{}{"2021-01-01T00:00:00Z", "yesterday"}},
+			ExpectedResult: "{}",
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   `since[1] (expected Time!): parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`,
+				Locations: []gqlerrors.Location{{Line: 3, Column: 6}},
+				Extensions: map[string]interface{}{
+					"code":         gqlerrors.CodeBadUserInput,
+					"inputPath":    "since[1]",
+					"expectedType": "Time!",
+				},
+			}},
+		})
+	}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         schema,
+		Query:          `{ events(since: ["2021-01-01T00:00:00Z"]) }`,
+		ExpectedResult: `{"events": 1}`,
+	})
+
+	want := map[string]int{
+		"graphql_input_coercion_failures{type=Query field=events argument=since expected_type=Time!}": 2,
+	}
+	if !reflect.DeepEqual(counters.counts, want) {
+		t.Errorf("got counters %v, want %v", counters.counts, want)
 	}
 }
 
-type resolverWithUnexportedField struct{}
+type introspectionFilterResolver struct{}
 
-func (r *resolverWithUnexportedField) ChangeTheNumber(args struct{ newNumber int32 }) int32 {
-	return args.newNumber
-}
+func (introspectionFilterResolver) Me() *introspectionFilterUser       { return &introspectionFilterUser{} }
+func (introspectionFilterResolver) Audit() []*introspectionFilterAudit { return nil }
 
-func TestUnexportedField(t *testing.T) {
+type introspectionFilterUser struct{}
+
+func (introspectionFilterUser) Name() string   { return "alice" }
+func (introspectionFilterUser) Salary() string { return "secret" }
+
+type introspectionFilterAudit struct{}
+
+func (introspectionFilterAudit) Action() string { return "login" }
+
+type adminKey struct{}
+
+func TestIntrospectionFilter(t *testing.T) {
 	t.Parallel()
 
-	_, err := graphql.ParseSchema(`
-		schema {
-			mutation: Mutation
+	schema := graphql.MustParseSchema(`
+		type Query {
+			me: User!
+			audit: [AuditEntry!]!
 		}
 
-		type Mutation {
-			changeTheNumber(newNumber: Int!): Int!
+		type User {
+			name: String!
+			salary: String!
 		}
-	`, &resolverWithUnexportedField{})
-	if err == nil {
+
+		type AuditEntry {
+			action: String!
+		}
+	`, &introspectionFilterResolver{}, graphql.IntrospectionFilter(func(ctx context.Context, typeName, fieldName string) bool {
+		if ctx.Value(adminKey{}) != nil {
+			return true
+		}
+		return typeName != "AuditEntry" && !(typeName == "User" && fieldName == "salary")
+	}))
+
+	query := `
+		{
+			query: __type(name: "Query") {
+				fields {
+					name
+				}
+			}
+			user: __type(name: "User") {
+				fields {
+					name
+				}
+			}
+			audit: __type(name: "AuditEntry") {
+				name
+			}
+		}
+	`
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  query,
+			ExpectedResult: `
+				{
+					"query": {
+						"fields": [
+							{"name": "me"}
+						]
+					},
+					"user": {
+						"fields": [
+							{"name": "name"}
+						]
+					},
+					"audit": null
+				}
+			`,
+		},
+		{
+			Context: context.WithValue(context.Background(), adminKey{}, true),
+			Schema:  schema,
+			Query:   query,
+			ExpectedResult: `
+				{
+					"query": {
+						"fields": [
+							{"name": "me"},
+							{"name": "audit"}
+						]
+					},
+					"user": {
+						"fields": [
+							{"name": "name"},
+							{"name": "salary"}
+						]
+					},
+					"audit": {
+						"name": "AuditEntry"
+					}
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					me {
+						salary
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"me": {
+						"salary": "secret"
+					}
+				}
+			`,
+		},
+	})
+}
+
+func TestMutationOrder(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(`
+				schema {
+					query: Query
+					mutation: Mutation
+				}
+
+				type Query {
+					theNumber: Int!
+				}
+
+				type Mutation {
+					changeTheNumber(newNumber: Int!): Query
+				}
+			`, &theNumberResolver{}),
+			Query: `
+				mutation {
+					first: changeTheNumber(newNumber: 1) {
+						theNumber
+					}
+					second: changeTheNumber(newNumber: 3) {
+						theNumber
+					}
+					third: changeTheNumber(newNumber: 2) {
+						theNumber
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"first": {
+						"theNumber": 1
+					},
+					"second": {
+						"theNumber": 3
+					},
+					"third": {
+						"theNumber": 2
+					}
+				}
+			`,
+		},
+	})
+}
+
+func TestTime(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(`
+				schema {
+					query: Query
+				}
+
+				type Query {
+					addHour(time: Time = "2001-02-03T04:05:06Z"): Time!
+				}
+
+				scalar Time
+			`, &timeResolver{}),
+			Query: `
+				query($t: Time!) {
+					a: addHour(time: $t)
+					b: addHour
+				}
+			`,
+			Variables: map[string]interface{}{
+				"t": time.Date(2000, 2, 3, 4, 5, 6, 0, time.UTC),
+			},
+			ExpectedResult: `
+				{
+					"a": "2000-02-03T05:05:06Z",
+					"b": "2001-02-03T05:05:06Z"
+				}
+			`,
+		},
+	})
+}
+
+type resolverWithUnexportedMethod struct{}
+
+func (r *resolverWithUnexportedMethod) changeTheNumber(args struct{ NewNumber int32 }) int32 { //lint:ignore U1000 ingore this for now
+	return args.NewNumber
+}
+
+func TestUnexportedMethod(t *testing.T) {
+	t.Parallel()
+
+	_, err := graphql.ParseSchema(`
+		schema {
+			mutation: Mutation
+		}
+
+		type Mutation {
+			changeTheNumber(newNumber: Int!): Int!
+		}
+	`, &resolverWithUnexportedMethod{})
+	if err == nil {
+		t.Error("error expected")
+	}
+}
+
+type resolverWithUnexportedField struct{}
+
+func (r *resolverWithUnexportedField) ChangeTheNumber(args struct{ newNumber int32 }) int32 {
+	return args.newNumber
+}
+
+func TestUnexportedField(t *testing.T) {
+	t.Parallel()
+
+	_, err := graphql.ParseSchema(`
+		schema {
+			mutation: Mutation
+		}
+
+		type Mutation {
+			changeTheNumber(newNumber: Int!): Int!
+		}
+	`, &resolverWithUnexportedField{})
+	if err == nil {
 		t.Error("error expected")
 	}
 }
@@ -4160,6 +4886,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
 					ResolverError: errExample,
 					Path:          []interface{}{"triggerError"},
 				},
@@ -4199,6 +4926,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 6, Column: 7}},
 					ResolverError: errExample,
 					Path:          []interface{}{"child", "triggerError"},
 				},
@@ -4242,6 +4970,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 8, Column: 8}},
 					ResolverError: errExample,
 					Path:          []interface{}{"child", "child", "triggerError"},
 				},
@@ -4288,6 +5017,7 @@ func TestErrorPropagation(t *testing.T) {
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 8, Column: 8}},
 					ResolverError: errExample,
 					Path:          []interface{}{"child", "child", "triggerError"},
 				},
@@ -4324,8 +5054,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "nilChild"},
+					Message:    nilChildErrorString,
+					Path:       []interface{}{"child", "nilChild"},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 			},
 		},
@@ -4364,8 +5095,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "nilChild"},
+					Message:    nilChildErrorString,
+					Path:       []interface{}{"child", "nilChild"},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 			},
 		},
@@ -4408,11 +5140,13 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "child", "child", "nilChild"},
+					Message:    nilChildErrorString,
+					Path:       []interface{}{"child", "child", "child", "nilChild"},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 				{
 					Message:       errExample.Error(),
+					Locations:     []gqlerrors.Location{{Line: 5, Column: 8}},
 					ResolverError: errExample,
 					Path:          []interface{}{"child", "child", "triggerError"},
 				},
@@ -4452,8 +5186,9 @@ func TestErrorPropagation(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: nilChildErrorString,
-					Path:    []interface{}{"child", "child", "nilChild"},
+					Message:    nilChildErrorString,
+					Path:       []interface{}{"child", "child", "nilChild"},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 			},
 		},
@@ -4605,55 +5340,40 @@ func TestPanicAmbiguity(t *testing.T) {
 func TestSchema_Exec_without_resolver(t *testing.T) {
 	t.Parallel()
 
-	type args struct {
-		Query  string
-		Schema string
-	}
-	type want struct {
-		Panic interface{}
+	s := graphql.MustParseSchema(starwars.Schema, nil)
+	if s.CanExec() {
+		t.Error("schema without resolver can exec")
 	}
-	testTable := []struct {
-		Name string
-		Args args
-		Want want
-	}{
-		{
-			Name: "schema_without_resolver_errors",
-			Args: args{
-				Query: `
-					query {
-						hero {
-							id
-							name
-							friends {
-								name
-							}
-						}
-					}
-				`,
-				Schema: starwars.Schema,
-			},
-			Want: want{Panic: "schema created without resolver, can not exec"},
-		},
+	if !graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}).CanExec() {
+		t.Error("schema with resolver can not exec")
 	}
 
-	for _, tt := range testTable {
-		t.Run(tt.Name, func(t *testing.T) {
-			s := graphql.MustParseSchema(tt.Args.Schema, nil)
-
-			defer func() {
-				r := recover()
-				if r == nil {
-					t.Fatal("expected query to panic")
-				}
-				if r != tt.Want.Panic {
-					t.Logf("got:  %s", r)
-					t.Logf("want: %s", tt.Want.Panic)
-					t.Fail()
+	query := `
+		query {
+			hero {
+				id
+				name
+				friends {
+					name
 				}
-			}()
-			_ = s.Exec(context.Background(), tt.Args.Query, "", map[string]interface{}{})
-		})
+			}
+		}
+	`
+	if errs := s.Validate(query); len(errs) != 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: s,
+		Query:  query,
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:    "schema created without resolver, can not exec",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeNoResolver},
+		}},
+	})
+
+	resp := s.ExecWithRoot(context.Background(), &starwars.Resolver{}, query, "", nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "schema created without resolver, can not exec" {
+		t.Errorf("unexpected errors: %v", resp.Errors)
 	}
 }
 
@@ -4687,7 +5407,8 @@ func TestSubscriptions_In_Exec(t *testing.T) {
 		`,
 		ExpectedErrors: []*gqlerrors.QueryError{
 			{
-				Message: "graphql-ws protocol header is missing",
+				Message:    "graphql-ws protocol header is missing",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeOperationNotSupported},
 			},
 		},
 	})
@@ -4734,8 +5455,9 @@ func TestPointerReturnForNonNull(t *testing.T) {
 			`,
 			ExpectedErrors: []*gqlerrors.QueryError{
 				{
-					Message: `graphql: got nil for non-null "Hello"`,
-					Path:    []interface{}{"pointerReturn", "value"},
+					Message:    `graphql: got nil for non-null "Hello"`,
+					Path:       []interface{}{"pointerReturn", "value"},
+					Extensions: map[string]interface{}{"code": gqlerrors.CodeNonNullViolation},
 				},
 			},
 		},
@@ -5034,694 +5756,4312 @@ func TestTracer(t *testing.T) {
 	checkFieldTraces(t, expectedFieldTraces, tt.fields)
 }
 
-func checkFieldTraces(t *testing.T, want, have []fieldTrace) {
-	if len(want) != len(have) {
-		t.Errorf("mismatched field traces: expected %d but got %d: %#v", len(want), len(have), have)
-	}
+type testTracerV2 struct {
+	mu      sync.Mutex
+	parsed  []string
+	queries int
+	fields  map[string]int
+}
 
-	type comparison struct {
-		want fieldTrace
-		have fieldTrace
+func (t *testTracerV2) TraceParse(ctx context.Context, queryString string) tracer.ParseFinishFunc {
+	return func(*gqlerrors.QueryError) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.parsed = append(t.parsed, queryString)
 	}
+}
 
-	m := map[string]comparison{}
+func (t *testTracerV2) TraceValidation(ctx context.Context) tracer.ValidationFinishFunc {
+	return func([]*gqlerrors.QueryError) {}
+}
 
-	for _, ft := range want {
-		m[ft.fieldName] = comparison{want: ft}
+func (t *testTracerV2) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, tracer.QueryFinishFunc) {
+	return ctx, func([]*gqlerrors.QueryError) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.queries++
 	}
+}
 
-	for _, ft := range have {
-		c := m[ft.fieldName]
-		c.have = ft
-		m[ft.fieldName] = c
-	}
+func (t *testTracerV2) TraceSubscription(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, tracer.SubscriptionEventFunc, tracer.SubscriptionFinishFunc) {
+	return ctx, func([]*gqlerrors.QueryError, int) {}, func() {}
+}
 
-	for _, c := range m {
-		if err := stringsEqual(c.want.fieldName, c.have.fieldName); err != "" {
-			t.Error("mismatched field name:", err)
-		}
-		if err := stringsEqual(c.want.typeName, c.have.typeName); err != "" {
-			t.Error("mismatched field parent type:", err)
-		}
+func (t *testTracerV2) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, tracer.ResolverFinishFunc) {
+	return ctx, func(err *gqlerrors.QueryError, size int) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.fields[info.TypeName+"."+info.FieldName] = size
 	}
 }
 
-func stringsEqual(want, have string) string {
-	if want != have {
-		return fmt.Sprintf("mismatched values:\nwant: %q\nhave: %q", want, have)
-	}
+var _ tracer.TracerV2 = (*testTracerV2)(nil)
 
-	return ""
+func TestTracerV2(t *testing.T) {
+	t.Parallel()
+
+	tt := &testTracerV2{fields: map[string]int{}}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.TracerV2(tt))
+
+	doc := `{ human(id: "1002") { name } }`
+	_ = schema.Exec(context.Background(), doc, "", nil)
+
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if len(tt.parsed) != 1 || tt.parsed[0] != doc {
+		t.Errorf("unexpected parse traces: %q", tt.parsed)
+	}
+	if tt.queries != 1 {
+		t.Errorf("expected one query trace, got %d", tt.queries)
+	}
+	want := map[string]int{
+		"Human.name":  len(`"Han Solo"`),
+		"Query.human": len(`{"name":"Han Solo"}`),
+	}
+	if !reflect.DeepEqual(tt.fields, want) {
+		t.Errorf("unexpected resolver traces:\nwant: %v\ngot:  %v", want, tt.fields)
+	}
 }
 
-type queryVarResolver struct{}
-type filterArgs struct {
-	Required string
-	Optional *string
+type argsTracer struct {
+	noop.Tracer
+	mu   sync.Mutex
+	args map[string]map[string]interface{}
+	defs map[string][]string
 }
-type filterSearchResults struct {
-	Match *string
+
+func (t *argsTracer) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, tracer.ResolverFinishFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	coord := info.TypeName + "." + info.FieldName
+	t.args[coord] = info.CoercedArgs
+	for _, def := range info.ArgumentDefinitions {
+		t.defs[coord] = append(t.defs[coord], def.Name.Name+": "+def.Type.String())
+	}
+	return ctx, func(*gqlerrors.QueryError, int) {}
 }
 
-func (r *queryVarResolver) Search(ctx context.Context, args *struct{ Filter filterArgs }) []filterSearchResults {
-	return []filterSearchResults{}
+func TestTracerCoercedArgs(t *testing.T) {
+	t.Parallel()
+
+	at := &argsTracer{args: map[string]map[string]interface{}{}, defs: map[string][]string{}}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.TracerV2(at))
+	resp := schema.Exec(context.Background(), `{ hero { name } human(id: "1000") { height(unit: FOOT) } }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+
+	at.mu.Lock()
+	defer at.mu.Unlock()
+	wantArgs := map[string]map[string]interface{}{
+		"Query.hero":     {"episode": "NEWHOPE"},
+		"Query.human":    {"id": graphql.ID("1000")},
+		"Human.height":   {"unit": "FOOT"},
+		"Character.name": nil,
+	}
+	if !reflect.DeepEqual(at.args, wantArgs) {
+		t.Errorf("unexpected coerced arguments:\nwant: %v\ngot:  %v", wantArgs, at.args)
+	}
+	wantDefs := map[string][]string{
+		"Query.hero":   {"episode: Episode"},
+		"Query.human":  {"id: ID!"},
+		"Human.height": {"unit: LengthUnit"},
+	}
+	if !reflect.DeepEqual(at.defs, wantDefs) {
+		t.Errorf("unexpected argument definitions:\nwant: %v\ngot:  %v", wantDefs, at.defs)
+	}
 }
 
-func TestQueryVariablesValidation(t *testing.T) {
-	gqltesting.RunTests(t, []*gqltesting.Test{{
-		Schema: graphql.MustParseSchema(`
-			input SearchFilter {
-			  	required: String!
-			  	optional: String
-			}
+type labelTracer struct {
+	noop.Tracer
+	mu     sync.Mutex
+	labels []string
+}
 
-			type SearchResults {
-				match: String
-			}
+func (t *labelTracer) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, tracer.ResolverFinishFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.labels = append(t.labels, info.Label)
+	return ctx, func(*gqlerrors.QueryError, int) {}
+}
 
-			type Query {
-				search(filter: SearchFilter!): [SearchResults!]!
-			}`, &queryVarResolver{}, graphql.UseFieldResolvers()),
-		Query: `
-        		query {
-        			search(filter: {}) {
-        				match
-        			}
-        		}`,
-		ExpectedErrors: []*gqlerrors.QueryError{{
-			Message:   "Argument \"filter\" has invalid value {}.\nIn field \"required\": Expected \"String!\", found null.",
-			Locations: []gqlerrors.Location{{Line: 3, Column: 27}},
-			Rule:      "ArgumentsOfCorrectType",
-		}},
-	}, {
-		Schema: graphql.MustParseSchema(`
-			input SearchFilter {
-				required: String!
-				optional: String
-			}
+func TestTraceLabelAndSampler(t *testing.T) {
+	t.Parallel()
 
-			type SearchResults {
-				match: String
-			}
+	lt := &labelTracer{}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.TracerV2(lt),
+		graphql.TraceLabel(func(typeName, fieldName string) string { return typeName + "/" + fieldName }),
+		graphql.TraceSampler(func(info tracer.ResolverInfo) bool { return !info.Trivial }),
+	)
+	_ = schema.Exec(context.Background(), `{ human(id: "1002") { __typename name friends { name } } }`, "", nil)
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if want := []string{"Query/human"}; !reflect.DeepEqual(lt.labels, want) {
+		t.Errorf("want traced labels %q, got %q", want, lt.labels)
+	}
+}
 
-			type Query {
-				search(filter: SearchFilter!): [SearchResults!]!
-			}`, &queryVarResolver{}, graphql.UseFieldResolvers()),
-		Query: `
-			query q($filter: SearchFilter!) {
-				search(filter: $filter) {
-					match
-				}
-			}`,
-		Variables: map[string]interface{}{"filter": map[string]interface{}{}},
-		ExpectedErrors: []*gqlerrors.QueryError{{
-			Message:   "Variable \"required\" has invalid value null.\nExpected type \"String!\", found null.",
-			Locations: []gqlerrors.Location{{Line: 3, Column: 5}},
-			Rule:      "VariablesOfCorrectType",
-		}},
-	}})
+type trivialResolver struct {
+	Lazy    func() string `trivial:"false"`
+	Profile *trivialProfile
 }
 
-type interfaceImplementingInterfaceResolver struct{}
-type interfaceImplementingInterfaceExample struct {
-	A string
-	B string
-	C bool
+func (r *trivialResolver) Name(ctx context.Context) string { return "Luke" }
+
+type trivialProfile struct {
+	Bio string
 }
 
-func (r *interfaceImplementingInterfaceResolver) Hey() *interfaceImplementingInterfaceExample {
-	return &interfaceImplementingInterfaceExample{
-		A: "testing",
-		B: "test",
-		C: true,
+func (p *trivialProfile) Avatar() string { return "luke.png" }
+
+func TestTrivialFields(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		type Query {
+			name: String!
+			lazy: String!
+			profile: Profile!
+		}
+		type Profile {
+			bio: String!
+			avatar: String!
+		}
+	`
+	lt := &labelTracer{}
+	schema := graphql.MustParseSchema(sdl, &trivialResolver{Lazy: func() string { return "loaded" }, Profile: &trivialProfile{Bio: "Jedi"}},
+		graphql.UseFieldResolvers(),
+		graphql.TracerV2(lt),
+		graphql.TraceLabel(func(typeName, fieldName string) string { return typeName + "." + fieldName }),
+		graphql.TraceSampler(func(info tracer.ResolverInfo) bool { return !info.Trivial }),
+		graphql.TrivialFields("Query.name"),
+		graphql.ExpensiveFields("Profile.avatar"),
+	)
+	resp := schema.Exec(context.Background(), `{ name lazy profile { bio avatar } }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
 	}
-}
 
-func TestInterfaceImplementingInterface(t *testing.T) {
-	gqltesting.RunTests(t, []*gqltesting.Test{{
-		Schema: graphql.MustParseSchema(`
-        interface A {
-          a: String!
-        }
-        interface B implements A {
-          a: String!
-          b: String!
-        }
-        interface C implements B & A {
-          a: String!
-          b: String!
-          c: Boolean!
-        }
-        type ABC implements C {
-          a: String!
-          b: String!
-          c: Boolean!
-        }
-        type Query {
-          hey: ABC
-        }`, &interfaceImplementingInterfaceResolver{}, graphql.UseFieldResolvers(), graphql.UseFieldResolvers()),
-		Query: `query {hey { a b c }}`,
-		ExpectedResult: `
-				{
-					"hey": {
-						"a": "testing",
-						"b": "test",
-						"c": true
-					}
-				}
-			`,
-	}})
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	sort.Strings(lt.labels)
+	if want := []string{"Profile.avatar", "Query.lazy", "Query.profile"}; !reflect.DeepEqual(lt.labels, want) {
+		t.Errorf("want traced labels %q, got %q", want, lt.labels)
+	}
+
+	for _, tt := range []struct {
+		opts     []graphql.SchemaOpt
+		resolver interface{}
+		want     string
+	}{
+		{[]graphql.SchemaOpt{graphql.TrivialFields("Query.unknown")}, &trivialResolver{}, `trivial fields: unknown field "Query.unknown"`},
+		{[]graphql.SchemaOpt{graphql.ExpensiveFields("name")}, &trivialResolver{}, `trivial fields: invalid field "name", expected "Type.field"`},
+		{nil, &struct {
+			trivialResolver
+			Bio string `trivial:"maybe"`
+		}{}, `invalid trivial tag "maybe", expected true or false`},
+	} {
+		_, err := graphql.ParseSchema(`type Query { name: String! bio: String! }`, tt.resolver, append(tt.opts, graphql.UseFieldResolvers())...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
 }
 
-func TestCircularFragmentMaxDepth(t *testing.T) {
-	withMaxDepth := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxDepth(2))
-	gqltesting.RunTests(t, []*gqltesting.Test{
-		{
-			Schema: withMaxDepth,
-			Query: `
-	              query {
-	                  ...X
-	              }
+type Email string
 
-	              fragment X on Query {
-	                  ...Y
-	              }
-	              fragment Y on Query {
-	                  ...X
-	              }
-	          `,
-			ExpectedErrors: []*gqlerrors.QueryError{{
-				Message: `Cannot spread fragment "X" within itself via "Y".`,
-				Rule:    "NoFragmentCyclesRule",
-				Locations: []gqlerrors.Location{
-					{Line: 7, Column: 20},
-					{Line: 10, Column: 20},
-				},
-			}},
-		},
-	})
+type normalizerResolver struct{}
+
+type signupInput struct {
+	Email Email
+	Name  string
 }
 
-func TestMaxQueryLength(t *testing.T) {
-	withMaxQueryLen := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxQueryLength(75))
+func (r *normalizerResolver) Signup(args struct {
+	Input *signupInput
+	Cc    []string
+	Bcc   *string
+}) string {
+	bcc := "<nil>"
+	if args.Bcc != nil {
+		bcc = *args.Bcc
+	}
+	return fmt.Sprintf("%s <%s> cc %v bcc %s", args.Input.Name, args.Input.Email, args.Cc, bcc)
+}
+
+func TestInputNormalizer(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		scalar EmailAddress
+		input SignupInput {
+			email: EmailAddress!
+			name: String!
+		}
+		type Query {
+			signup(input: SignupInput, cc: [EmailAddress!]!, bcc: EmailAddress): String!
+		}
+	`
+	schema := graphql.MustParseSchema(sdl, &normalizerResolver{},
+		graphql.InputNormalizer("EmailAddress", strings.TrimSpace),
+		graphql.InputNormalizer("EmailAddress", strings.ToLower),
+		graphql.InputNormalizer("SignupInput", func(in signupInput) (signupInput, error) {
+			if in.Name == "" {
+				return in, errors.New("name must not be empty")
+			}
+			in.Name = strings.ToUpper(in.Name[:1]) + in.Name[1:]
+			return in, nil
+		}),
+	)
+
 	gqltesting.RunTests(t, []*gqltesting.Test{
 		{
-			Schema: withMaxQueryLen,
-			// Query length is 69 bytes
-			Query: `
-				query {
-					hero(episode: EMPIRE) {
-						name
-					}
-				}
-			`,
-			ExpectedResult: `{"hero":{"name":"Luke Skywalker"}}`,
+			Schema: schema,
+			Query: `query($bcc: EmailAddress) {
+				signup(input: {email: " Luke@Example.COM", name: "luke"}, cc: ["Leia@Example.com "], bcc: $bcc)
+			}`,
+			Variables:      map[string]interface{}{"bcc": "HAN@example.com"},
+			ExpectedResult: `{"signup": "Luke <luke@example.com> cc [leia@example.com] bcc han@example.com"}`,
 		},
 		{
-			Schema: withMaxQueryLen,
-			Query: `
-				query HeroForEpisode {
-					hero(episode: WRATH_OF_KHAN) {
-						name
-					}
-				}
-			`,
+			Schema:         schema,
+			Query:          `{ signup(input: {email: "a@b.c", name: "a"}, cc: []) }`,
+			ExpectedResult: `{"signup": "A <a@b.c> cc [] bcc <nil>"}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ signup(input: {email: "a@b.c", name: ""}, cc: []) }`,
+			ExpectedResult: `{}`,
 			ExpectedErrors: []*gqlerrors.QueryError{{
-				Message: `query length 91 exceeds the maximum allowed query length of 75 bytes`,
+				Message:    "input (expected SignupInput): name must not be empty",
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 3}},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeBadUserInput, "inputPath": "input", "expectedType": "SignupInput"},
 			}},
 		},
 	})
-}
 
-type RootResolver struct{}
-type QueryResolver struct{}
-type MutationResolver struct{}
-type SubscriptionResolver struct {
-	err      error
-	upstream <-chan *helloEventResolver
+	for _, tt := range []struct {
+		opt  graphql.SchemaOpt
+		want string
+	}{
+		{graphql.InputNormalizer("Query", strings.ToLower), `input normalizer: unknown input type "Query"`},
+		{graphql.InputNormalizer("EmailAddress", strings.Repeat), "input normalizer of EmailAddress: expected func(T) T or func(T) (T, error), got func(string, int) string"},
+		{graphql.InputNormalizer("EmailAddress", func(i int) int { return i }), "normalizer of EmailAddress: can not normalize graphql_test.Email with func(int) int"},
+	} {
+		_, err := graphql.ParseSchema(sdl, &normalizerResolver{}, tt.opt)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
 }
 
-func (r *RootResolver) Query() *QueryResolver {
-	return &QueryResolver{}
+type skipTracer struct {
+	noop.Tracer
+	mu      sync.Mutex
+	skipped []tracer.SkippedSelection
 }
 
-func (r *RootResolver) Mutation() *MutationResolver {
-	return &MutationResolver{}
+func (t *skipTracer) TraceSkipped(ctx context.Context, skipped []tracer.SkippedSelection) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.skipped = append(t.skipped, skipped...)
 }
 
-type helloEventResolver struct {
-	msg string
-	err error
-}
+func TestSkipTracer(t *testing.T) {
+	t.Parallel()
 
-func (r *helloEventResolver) Msg() (string, error) {
-	return r.msg, r.err
-}
+	st := &skipTracer{}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.TracerV2(st),
+		graphql.TraceSampler(func(info tracer.ResolverInfo) bool { return false }),
+	)
+	resp := schema.Exec(context.Background(), `
+		query($withFriends: Boolean!) {
+			human(id: "1002") {
+				name
+				height @skip(if: true)
+				friends @include(if: $withFriends) { name }
+				...mass @include(if: false)
+			}
+		}
 
-func closedHelloEventUpstream(rr ...*helloEventResolver) <-chan *helloEventResolver {
-	c := make(chan *helloEventResolver, len(rr))
-	for _, r := range rr {
-		c <- r
+		fragment mass on Human { mass }
+	`, "", map[string]interface{}{"withFriends": false})
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
 	}
-	close(c)
-	return c
-}
 
-func (r *RootResolver) Subscription() *SubscriptionResolver {
-	return &SubscriptionResolver{
-		upstream: closedHelloEventUpstream(
-			&helloEventResolver{msg: "Hello subscription!"},
-			&helloEventResolver{err: errors.New("resolver error")},
-			&helloEventResolver{msg: "Hello again!"},
-		),
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	want := []tracer.SkippedSelection{
+		{TypeName: "Human", FieldName: "height", Alias: "height", Directive: "skip"},
+		{TypeName: "Human", FieldName: "friends", Alias: "friends", Directive: "include"},
+		{TypeName: "Human", Fragment: "mass", Directive: "include"},
+	}
+	if !reflect.DeepEqual(st.skipped, want) {
+		t.Errorf("unexpected skipped selections:\nwant: %+v\ngot:  %+v", want, st.skipped)
 	}
 }
 
-func (qr *QueryResolver) Hello() string {
-	return "Hello query!"
+type fieldFuncUser struct {
+	FirstName string
+	LastName  string
 }
 
-func (mr *MutationResolver) Hello() string {
-	return "Hello mutation!"
+type fieldFuncQuery struct{}
+
+func (fieldFuncQuery) User() *fieldFuncUser {
+	return &fieldFuncUser{FirstName: "Ada", LastName: "Lovelace"}
 }
 
-func (sr *SubscriptionResolver) Hello(ctx context.Context) (chan *helloEventResolver, error) {
-	if sr.err != nil {
-		return nil, sr.err
-	}
+func TestFieldFunc(t *testing.T) {
+	t.Parallel()
 
-	c := make(chan *helloEventResolver)
-	go func() {
-		for r := range sr.upstream {
-			select {
-			case <-ctx.Done():
-				close(c)
-				return
-			case c <- r:
-			}
+	schemaString := `
+		type Query {
+			hello(name: String!): String!
+			user: User!
 		}
-		close(c)
-	}()
 
-	return c, nil
-}
+		type User {
+			firstName: String!
+			fullName: String!
+		}
+	`
+	schema := graphql.MustParseSchema(schemaString, &fieldFuncQuery{},
+		graphql.UseFieldResolvers(),
+		graphql.FieldFunc("Query", "hello", func(ctx context.Context, args struct{ Name string }) (string, error) {
+			if args.Name == "" {
+				return "", fmt.Errorf("name must not be empty")
+			}
+			return "Hello, " + args.Name + "!", nil
+		}),
+		graphql.FieldFunc("User", "fullName", func(u *fieldFuncUser) string {
+			return u.FirstName + " " + u.LastName
+		}),
+	)
 
-type errRootResolver1 struct {
-	RootResolver
-}
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					hello(name: "world")
+					user {
+						firstName
+						fullName
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"hello": "Hello, world!",
+					"user": {
+						"firstName": "Ada",
+						"fullName": "Ada Lovelace"
+					}
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					hello(name: "")
+				}
+			`,
+			ExpectedResult: `
+				null
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{
+					Message:       "name must not be empty",
+					Locations:     []gqlerrors.Location{{Line: 3, Column: 6}},
+					ResolverError: fmt.Errorf("name must not be empty"),
+					Path:          []interface{}{"hello"},
+				},
+			},
+		},
+	})
 
-// Query is invalid because it doesn't have a return value.
-func (*errRootResolver1) Query() {}
+	_, err := graphql.ParseSchema(schemaString, &fieldFuncQuery{},
+		graphql.UseFieldResolvers(),
+		graphql.FieldFunc("Query", "hello", func(args struct{ Name string }) int { return 0 }),
+	)
+	if err == nil || !strings.Contains(err.Error(), `used by graphql.FieldFunc("Query", "hello"`) {
+		t.Errorf("want error about the FieldFunc of Query.hello, got %v", err)
+	}
 
-type errRootResolver2 struct {
-	RootResolver
+	for _, fn := range []interface{}{nil, "hello", (func() string)(nil)} {
+		_, err := graphql.ParseSchema(schemaString, &fieldFuncQuery{},
+			graphql.UseFieldResolvers(),
+			graphql.FieldFunc("Query", "hello", fn),
+		)
+		if err == nil || !strings.Contains(err.Error(), "expected a function") {
+			t.Errorf("FieldFunc(%#v): want error about the function, got %v", fn, err)
+		}
+	}
 }
 
-// Query is invalid because it has more than 1 return value
-func (*errRootResolver2) Query() (*QueryResolver, error) {
-	return nil, nil
+// thunkLoader loads names in batches. Keys are registered when a resolver returns a thunk and the
+// first thunk which is called loads all registered keys.
+type thunkLoader struct {
+	mu      sync.Mutex
+	keys    []string
+	batches [][]string
+}
+
+func (l *thunkLoader) load(key string) func() (*string, error) {
+	l.mu.Lock()
+	l.keys = append(l.keys, key)
+	l.mu.Unlock()
+	return func() (*string, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if len(l.keys) > 0 {
+			sort.Strings(l.keys)
+			l.batches = append(l.batches, l.keys)
+			l.keys = nil
+		}
+		if key == "x" {
+			return nil, fmt.Errorf("no name for %q", key)
+		}
+		name := strings.ToUpper(key)
+		return &name, nil
+	}
 }
 
-type errRootResolver3 struct {
-	RootResolver
+type thunkQuery struct {
+	ids    []string
+	loader *thunkLoader
 }
 
-// Mutation is invalid because it returns nil
-func (*errRootResolver3) Mutation() *MutationResolver {
-	return nil
+func (q *thunkQuery) Title() func() string {
+	return func() string { return "items" }
 }
 
-type errRootResolver4 struct {
-	RootResolver
+func (q *thunkQuery) Items() []*thunkItem {
+	items := make([]*thunkItem, len(q.ids))
+	for i, id := range q.ids {
+		items[i] = &thunkItem{id: id, loader: q.loader}
+	}
+	return items
 }
 
-// Query is invalid because it doesn't return a pointer.
-func (*errRootResolver4) Query() MutationResolver {
-	return MutationResolver{}
+type thunkItem struct {
+	id     string
+	loader *thunkLoader
 }
 
-type errRootResolver5 struct {
-	RootResolver
+func (i *thunkItem) ID() graphql.ID {
+	return graphql.ID(i.id)
 }
 
-// Query is invalid because it returns *[]int instead of a resolver.
-func (*errRootResolver5) Query() *[]int {
-	return &[]int{1, 2}
+func (i *thunkItem) Name() func() (*string, error) {
+	return i.loader.load(i.id)
 }
 
-type errRootResolver6 struct {
-	RootResolver
-}
+func TestThunks(t *testing.T) {
+	t.Parallel()
 
-// Mutation is invalid because it returns a map[string]int instead of a resolver.
-func (*errRootResolver6) Mutation() map[string]int {
-	return map[string]int{"key": 3}
-}
+	schemaString := `
+		type Query {
+			title: String!
+			items: [Item!]!
+		}
 
-type errRootResolver7 struct {
-	RootResolver
-}
+		type Item {
+			id: ID!
+			name: String
+		}
+	`
+	loader := &thunkLoader{}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(schemaString, &thunkQuery{ids: []string{"c", "a", "b"}, loader: loader}),
+		Query: `
+			{
+				title
+				items {
+					id
+					name
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"title": "items",
+				"items": [
+					{"id": "c", "name": "C"},
+					{"id": "a", "name": "A"},
+					{"id": "b", "name": "B"}
+				]
+			}
+		`,
+	})
+	if want := [][]string{{"a", "b", "c"}}; !reflect.DeepEqual(loader.batches, want) {
+		t.Errorf("want batches %q, got %q", want, loader.batches)
+	}
 
-// Subscription is invalid because it returns an invalid resolver.
-func (*errRootResolver7) Subscription() interface{} {
-	a := struct {
-		Name string
-	}{Name: "invalid"}
-	return &a
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(schemaString, &thunkQuery{ids: []string{"a", "x"}, loader: &thunkLoader{}}),
+		Query: `
+			{
+				items {
+					name
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"items": [
+					{"name": "A"},
+					{"name": null}
+				]
+			}
+		`,
+		ExpectedErrors: []*gqlerrors.QueryError{
+			{
+				Message:       `no name for "x"`,
+				Locations:     []gqlerrors.Location{{Line: 4, Column: 6}},
+				ResolverError: fmt.Errorf(`no name for "x"`),
+				Path:          []interface{}{"items", 1, "name"},
+			},
+		},
+	})
+
+	// The elements of a list are still resolved by at most MaxParallelism goroutines, so the thunks
+	// are called in several batches.
+	loader = &thunkLoader{}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(schemaString, &thunkQuery{ids: []string{"a", "b", "c", "d", "e"}, loader: loader}, graphql.MaxParallelism(2)),
+		Query: `
+			{
+				items {
+					name
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"items": [
+					{"name": "A"},
+					{"name": "B"},
+					{"name": "C"},
+					{"name": "D"},
+					{"name": "E"}
+				]
+			}
+		`,
+	})
+	var loaded int
+	for _, batch := range loader.batches {
+		if len(batch) > 2 {
+			t.Errorf("want batches of at most 2 keys, got %q", loader.batches)
+			break
+		}
+		loaded += len(batch)
+	}
+	if loaded != 5 {
+		t.Errorf("want 5 keys loaded, got %q", loader.batches)
+	}
 }
 
-type errRootResolver8 struct {
-	RootResolver
+type rootValueResolver struct {
+	user string
 }
 
-// Query is invalid because it accepts arguments.
-func (*errRootResolver8) Query(ctx context.Context) *QueryResolver {
-	return &QueryResolver{}
+func (r *rootValueResolver) Viewer() string {
+	return r.user
 }
 
-// TestSeparateResolvers ensures that a field with the same name is allowed in different operations
-func TestSeparateResolvers(t *testing.T) {
-	helloEverywhere := `
-		schema {
-			query: Query
-			mutation: Mutation
-			subscription: Subscription
-		}
+func TestExecWithRoot(t *testing.T) {
+	t.Parallel()
 
+	schema := graphql.MustParseSchema(`
 		type Query {
-			hello: String!
-		}
-
-		type Mutation {
-			hello: String!
-		}
-
-		type Subscription {
-			hello: HelloEvent!
-		}
-
-		type HelloEvent {
-			msg: String!
+			viewer: String!
 		}
-	`
-
-	separateSchema := graphql.MustParseSchema(helloEverywhere, &RootResolver{})
+	`, &rootValueResolver{})
 
 	gqltesting.RunTests(t, []*gqltesting.Test{
 		{
-			Schema: separateSchema,
-			Query: `
-				query {
-					hello
-				}
-			`,
-			ExpectedResult: `
-				{
-					"hello": "Hello query!"
-				}
-			`,
-		},
-		{
-			Schema: separateSchema,
-			Query: `
-				mutation {
-					hello
-				}
-			`,
+			Schema: schema,
+			Query:  `{ viewer }`,
 			ExpectedResult: `
 				{
-					"hello": "Hello mutation!"
+					"viewer": ""
 				}
 			`,
 		},
 	})
 
-	gqltesting.RunSubscribes(t, []*gqltesting.TestSubscription{
-		{
-			Name:   "ok",
-			Schema: separateSchema,
-			Query: `
-				subscription {
-					hello {
-						msg
-					}
-				}
-			`,
-			ExpectedResults: []gqltesting.TestResponse{
-				{
-					Data: json.RawMessage(`
-						{
-							"hello": {
-								"msg": "Hello subscription!"
-							}
-						}
-					`),
-				},
-				{
-					// null propagates all the way up because msg is non-null
-					Data:   json.RawMessage(`null`),
-					Errors: []*gqlerrors.QueryError{gqlerrors.Errorf("%s", errResolver)},
-				},
-				{
-					Data: json.RawMessage(`
-						{
-							"hello": {
-								"msg": "Hello again!"
-							}
-						}
-					`),
-				},
-			},
-		},
-	})
+	for _, user := range []string{"alice", "bob"} {
+		resp := schema.ExecWithRoot(context.Background(), &rootValueResolver{user: user}, `{ viewer }`, "", nil)
+		if len(resp.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", resp.Errors)
+		}
+		if want := `{"viewer":"` + user + `"}`; string(resp.Data) != want {
+			t.Errorf("want %s, got %s", want, resp.Data)
+		}
+	}
 
-	// test errors with invalid resolvers
-	tests := []struct {
-		name     string
-		resolver interface{}
-		opts     []graphql.SchemaOpt
-		wantErr  string
-	}{
-		{
-			name:     "query_method_has_no_return_val",
-			resolver: &errRootResolver1{},
-			wantErr:  "method \"Query\" of *graphql_test.errRootResolver1 must have 1 return value, got 0",
-		},
-		{
-			name:     "query_method_returns_too_many_vals",
-			resolver: &errRootResolver2{},
-			wantErr:  "method \"Query\" of *graphql_test.errRootResolver2 must have 1 return value, got 2",
-		},
-		{
-			name:     "mutation_method_returns_nil",
+	resp := schema.ExecWithRoot(context.Background(), struct{}{}, `{ viewer }`, "", nil)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "does not match the resolver of the schema") ||
+		resp.Errors[0].Extensions["code"] != gqlerrors.CodeNoResolver {
+		t.Errorf("want an error about the root resolver type, got %v", resp.Errors)
+	}
+}
+
+func TestUpgradeTracer(t *testing.T) {
+	t.Parallel()
+
+	if _, ok := tracer.Upgrade(noop.Tracer{}).(noop.Tracer); !ok {
+		t.Error("tracer implementing TracerV2 must be returned as is")
+	}
+
+	legacy := &testTracer{mu: &sync.Mutex{}}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.Tracer(legacy))
+	_ = schema.Exec(context.Background(), `{ hero { name } }`, "", nil)
+
+	legacy.mu.Lock()
+	defer legacy.mu.Unlock()
+	checkFieldTraces(t, []fieldTrace{
+		{fieldName: "hero", typeName: "Query"},
+		{fieldName: "name", typeName: "Character"},
+	}, legacy.fields)
+}
+
+func checkFieldTraces(t *testing.T, want, have []fieldTrace) {
+	if len(want) != len(have) {
+		t.Errorf("mismatched field traces: expected %d but got %d: %#v", len(want), len(have), have)
+	}
+
+	type comparison struct {
+		want fieldTrace
+		have fieldTrace
+	}
+
+	m := map[string]comparison{}
+
+	for _, ft := range want {
+		m[ft.fieldName] = comparison{want: ft}
+	}
+
+	for _, ft := range have {
+		c := m[ft.fieldName]
+		c.have = ft
+		m[ft.fieldName] = c
+	}
+
+	for _, c := range m {
+		if err := stringsEqual(c.want.fieldName, c.have.fieldName); err != "" {
+			t.Error("mismatched field name:", err)
+		}
+		if err := stringsEqual(c.want.typeName, c.have.typeName); err != "" {
+			t.Error("mismatched field parent type:", err)
+		}
+	}
+}
+
+func stringsEqual(want, have string) string {
+	if want != have {
+		return fmt.Sprintf("mismatched values:\nwant: %q\nhave: %q", want, have)
+	}
+
+	return ""
+}
+
+type queryVarResolver struct{}
+type filterArgs struct {
+	Required string
+	Optional *string
+}
+type filterSearchResults struct {
+	Match *string
+}
+
+func (r *queryVarResolver) Search(ctx context.Context, args *struct{ Filter filterArgs }) []filterSearchResults {
+	return []filterSearchResults{}
+}
+
+func TestQueryVariablesValidation(t *testing.T) {
+	gqltesting.RunTests(t, []*gqltesting.Test{{
+		Schema: graphql.MustParseSchema(`
+			input SearchFilter {
+			  	required: String!
+			  	optional: String
+			}
+
+			type SearchResults {
+				match: String
+			}
+
+			type Query {
+				search(filter: SearchFilter!): [SearchResults!]!
+			}`, &queryVarResolver{}, graphql.UseFieldResolvers()),
+		Query: `
+        		query {
+        			search(filter: {}) {
+        				match
+        			}
+        		}`,
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:    "Argument \"filter\" has invalid value {}.\nIn field \"required\": Expected \"String!\", found null.",
+			Locations:  []gqlerrors.Location{{Line: 3, Column: 27}},
+			Rule:       "ArgumentsOfCorrectType",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+		}},
+	}, {
+		Schema: graphql.MustParseSchema(`
+			input SearchFilter {
+				required: String!
+				optional: String
+			}
+
+			type SearchResults {
+				match: String
+			}
+
+			type Query {
+				search(filter: SearchFilter!): [SearchResults!]!
+			}`, &queryVarResolver{}, graphql.UseFieldResolvers()),
+		Query: `
+			query q($filter: SearchFilter!) {
+				search(filter: $filter) {
+					match
+				}
+			}`,
+		Variables: map[string]interface{}{"filter": map[string]interface{}{}},
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:    "Variable \"filter.required\" has invalid value null.\nExpected type \"String!\", found null.",
+			Locations:  []gqlerrors.Location{{Line: 2, Column: 12}},
+			Rule:       "VariablesOfCorrectType",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+		}},
+	}, {
+		Schema: graphql.MustParseSchema(`
+			input SearchFilter {
+				required: String! = "default"
+				optional: String
+			}
+
+			type SearchResults {
+				match: String
+			}
+
+			type Query {
+				search(filter: SearchFilter!): [SearchResults!]!
+			}`, &queryVarResolver{}, graphql.UseFieldResolvers()),
+		Query: `
+			query q($filter: SearchFilter!) {
+				search(filter: $filter) {
+					match
+				}
+			}`,
+		Variables: map[string]interface{}{"filter": map[string]interface{}{"optional": 10, "unknown": true}},
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:    "Variable \"filter.optional\" has invalid value 10.\nExpected type \"String\", found 10.",
+			Locations:  []gqlerrors.Location{{Line: 2, Column: 12}},
+			Rule:       "VariablesOfCorrectType",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+		}, {
+			Message:    "Variable \"filter\" has invalid value.\nField \"unknown\" is not defined by type \"SearchFilter\".",
+			Locations:  []gqlerrors.Location{{Line: 2, Column: 12}},
+			Rule:       "VariablesOfCorrectType",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+		}},
+	}})
+}
+
+type interfaceImplementingInterfaceResolver struct{}
+type interfaceImplementingInterfaceExample struct {
+	A string
+	B string
+	C bool
+}
+
+func (r *interfaceImplementingInterfaceResolver) Hey() *interfaceImplementingInterfaceExample {
+	return &interfaceImplementingInterfaceExample{
+		A: "testing",
+		B: "test",
+		C: true,
+	}
+}
+
+func TestInterfaceImplementingInterface(t *testing.T) {
+	gqltesting.RunTests(t, []*gqltesting.Test{{
+		Schema: graphql.MustParseSchema(`
+        interface A {
+          a: String!
+        }
+        interface B implements A {
+          a: String!
+          b: String!
+        }
+        interface C implements B & A {
+          a: String!
+          b: String!
+          c: Boolean!
+        }
+        type ABC implements C {
+          a: String!
+          b: String!
+          c: Boolean!
+        }
+        type Query {
+          hey: ABC
+        }`, &interfaceImplementingInterfaceResolver{}, graphql.UseFieldResolvers(), graphql.UseFieldResolvers()),
+		Query: `query {hey { a b c }}`,
+		ExpectedResult: `
+				{
+					"hey": {
+						"a": "testing",
+						"b": "test",
+						"c": true
+					}
+				}
+			`,
+	}})
+}
+
+func TestCircularFragmentMaxDepth(t *testing.T) {
+	withMaxDepth := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxDepth(2))
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: withMaxDepth,
+			Query: `
+	              query {
+	                  ...X
+	              }
+
+	              fragment X on Query {
+	                  ...Y
+	              }
+	              fragment Y on Query {
+	                  ...X
+	              }
+	          `,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message: `Cannot spread fragment "X" within itself via "Y".`,
+				Rule:    "NoFragmentCyclesRule",
+				Locations: []gqlerrors.Location{
+					{Line: 7, Column: 20},
+					{Line: 10, Column: 20},
+				},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+	})
+}
+
+func TestMaxQueryLength(t *testing.T) {
+	withMaxQueryLen := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxQueryLength(75))
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: withMaxQueryLen,
+			// Query length is 69 bytes
+			Query: `
+				query {
+					hero(episode: EMPIRE) {
+						name
+					}
+				}
+			`,
+			ExpectedResult: `{"hero":{"name":"Luke Skywalker"}}`,
+		},
+		{
+			Schema: withMaxQueryLen,
+			Query: `
+				query HeroForEpisode {
+					hero(episode: WRATH_OF_KHAN) {
+						name
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `query length 91 exceeds the maximum allowed query length of 75 bytes`,
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeQueryTooLong},
+			}},
+		},
+	})
+}
+
+func TestParseLimits(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.MaxTokens(12), graphql.MaxNestingDepth(3))
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			// 12 tokens, 2 selection sets
+			Query: `
+				query {
+					hero(episode: EMPIRE) {
+						name
+					}
+				}
+			`,
+			ExpectedResult: `{"hero":{"name":"Luke Skywalker"}}`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				query {
+					hero(episode: EMPIRE) {
+						name, id
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `document exceeds the maximum of 12 tokens`,
+				Locations:  []gqlerrors.Location{{Line: 6, Column: 5}},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeParseFailed},
+			}},
+		},
+		{
+			Schema: schema,
+			Query: `
+				{
+					hero {
+						friends {
+							friends {
+								name
+							}
+						}
+					}
+				}
+			`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `document exceeds the maximum nesting depth of 3`,
+				Locations:  []gqlerrors.Location{{Line: 5, Column: 16}},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeParseFailed},
+			}},
+		},
+		{
+			Schema: schema,
+			Query:  `{ search(text: [[["a"]]]) { __typename } }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `document exceeds the maximum nesting depth of 3`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 18}},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeParseFailed},
+			}},
+		},
+	})
+}
+
+func TestMaxInputDepth(t *testing.T) {
+	schema := graphql.MustParseSchema(`
+		type Query {
+			recursive(value: RecursiveInput): Int!
+		}
+
+		input RecursiveInput {
+			next: RecursiveInput
+		}
+	`, &inputResolver{}, graphql.MaxInputDepth(2))
+	tooDeep := func(msg string, line, column int) []*gqlerrors.QueryError {
+		return []*gqlerrors.QueryError{{
+			Message:    msg,
+			Locations:  []gqlerrors.Location{{Line: line, Column: column}},
+			Rule:       "MaxInputDepthExceeded",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeMaxDepthExceeded},
+		}}
+	}
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `{ recursive(value: {next: {}}) }`,
+			ExpectedResult: `{"recursive":2}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ recursive(value: {next: {next: {}}}) }`,
+			ExpectedErrors: tooDeep(`Argument "value" of field "recursive" exceeds max input depth 2`, 1, 13),
+		},
+		{
+			Schema: schema,
+			Query:  `query($v: RecursiveInput) { recursive(value: $v) }`,
+			Variables: map[string]interface{}{
+				"v": map[string]interface{}{"next": map[string]interface{}{"next": map[string]interface{}{}}},
+			},
+			ExpectedErrors: tooDeep(`Variable "$v" exceeds max input depth 2`, 1, 7),
+		},
+		{
+			Schema: schema,
+			Query:  `query($v: RecursiveInput) { recursive(value: {next: $v}) }`,
+			Variables: map[string]interface{}{
+				"v": map[string]interface{}{"next": map[string]interface{}{}},
+			},
+			ExpectedErrors: tooDeep(`Argument "value" of field "recursive" exceeds max input depth 2`, 1, 39),
+		},
+	})
+}
+
+type apiKey struct{}
+
+// tokenBucketEstimator charges one token per selected field to the API key of the request.
+type tokenBucketEstimator struct {
+	mu      sync.Mutex
+	buckets map[string]int
+}
+
+func (e *tokenBucketEstimator) EstimateCost(ctx context.Context, op *graphql.CostOperation) error {
+	cost := countFields(op.Operation.Selections, op.Fragments)
+	key, _ := ctx.Value(apiKey{}).(string)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.buckets[key] < cost {
+		return &gqlerrors.QueryError{
+			Message:    fmt.Sprintf("operation cost %d exceeds the remaining %d tokens", cost, e.buckets[key]),
+			Extensions: map[string]interface{}{"code": "RATE_LIMITED"},
+		}
+	}
+	e.buckets[key] -= cost
+	return nil
+}
+
+func countFields(sels []ast.Selection, fragments ast.FragmentList) int {
+	n := 0
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			n += 1 + countFields(sel.SelectionSet, fragments)
+		case *ast.InlineFragment:
+			n += countFields(sel.Selections, fragments)
+		case *ast.FragmentSpread:
+			n += countFields(fragments.Get(sel.Name.Name).Selections, fragments)
+		}
+	}
+	return n
+}
+
+func TestEstimateCost(t *testing.T) {
+	estimator := &tokenBucketEstimator{buckets: map[string]int{"a": 5, "b": 1}}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.EstimateCost(estimator))
+	ctxA := context.WithValue(context.Background(), apiKey{}, "a")
+	ctxB := context.WithValue(context.Background(), apiKey{}, "b")
+	query := `
+		query {
+			hero {
+				...HeroName
+			}
+		}
+
+		fragment HeroName on Character {
+			name
+		}
+	`
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context:        ctxA,
+			Schema:         schema,
+			Query:          query,
+			ExpectedResult: `{"hero": {"name": "R2-D2"}}`,
+		},
+		{
+			Context:        ctxA,
+			Schema:         schema,
+			Query:          query,
+			ExpectedResult: `{"hero": {"name": "R2-D2"}}`,
+		},
+		{
+			Context: ctxA,
+			Schema:  schema,
+			Query:   query,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "operation cost 2 exceeds the remaining 1 tokens",
+				Extensions: map[string]interface{}{"code": "RATE_LIMITED"},
+			}},
+		},
+		{
+			Context: ctxB,
+			Schema:  schema,
+			Query:   `{ hero { __typename } }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "operation cost 2 exceeds the remaining 1 tokens",
+				Extensions: map[string]interface{}{"code": "RATE_LIMITED"},
+			}},
+		},
+	})
+}
+
+type countingEncoder struct {
+	mu    sync.Mutex
+	types []string
+}
+
+func (e *countingEncoder) Marshal(v interface{}) ([]byte, error) {
+	e.mu.Lock()
+	e.types = append(e.types, fmt.Sprintf("%T", v))
+	e.mu.Unlock()
+	return json.Marshal(v)
+}
+
+func TestWithEncoder(t *testing.T) {
+	enc := &countingEncoder{}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.WithEncoder(enc))
+
+	resp := schema.Exec(context.Background(), `{ hero { name appearsIn } }`, "", nil)
+	var buf bytes.Buffer
+	if err := schema.WriteResponse(&buf, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"data":{"hero":{"name":"R2-D2","appearsIn":["NEWHOPE","EMPIRE","JEDI"]}}}`; buf.String() != want {
+		t.Errorf("got %s, want %s", buf.String(), want)
+	}
+	// Enum values are written by the executor, scalars and the response by the encoder.
+	if want := []string{"string", "*graphql.Response"}; !reflect.DeepEqual(enc.types, want) {
+		t.Errorf("got encoded types %v, want %v", enc.types, want)
+	}
+
+	// A nil encoder falls back to JSONEncoder.
+	schema = graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.WithEncoder(nil))
+	b, err := schema.MarshalResponse(schema.Exec(context.Background(), `{ hero { name } }`, "", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"data":{"hero":{"name":"R2-D2"}}}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+type slowResolver struct{}
+
+func (*slowResolver) Slow(ctx context.Context) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-time.After(time.Second):
+		return "done", nil
+	}
+}
+
+func (*slowResolver) Fast() string {
+	return "done"
+}
+
+func TestOperationTimeouts(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			slow: String!
+			fast: String!
+		}
+
+		type Mutation {
+			slow: String!
+			fast: String!
+		}
+	`, &slowResolver{}, graphql.QueryTimeout(20*time.Millisecond), graphql.MutationTimeout(time.Minute))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ slow }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "query timed out after 20ms",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeTimeout},
+			}},
+		},
+		{
+			Schema:         schema,
+			Query:          `{ fast }`,
+			ExpectedResult: `{"fast": "done"}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `mutation { fast }`,
+			ExpectedResult: `{"fast": "done"}`,
+		},
+	})
+
+	resp := schema.Exec(context.Background(), `{ slow }`, "", nil)
+	if len(resp.Errors) != 1 || !errors.Is(resp.Errors[0], context.DeadlineExceeded) {
+		t.Errorf("got errors %v, want a deadline error", resp.Errors)
+	}
+}
+
+type bigListResolver struct {
+	calls int32
+}
+
+func (r *bigListResolver) Items() []*bigListItemResolver {
+	items := make([]*bigListItemResolver, 1000)
+	for i := range items {
+		items[i] = &bigListItemResolver{r: r}
+	}
+	return items
+}
+
+func (r *bigListResolver) Small() []*bigListItemResolver {
+	return []*bigListItemResolver{{r: r}}
+}
+
+type bigListItemResolver struct {
+	r *bigListResolver
+}
+
+func (i *bigListItemResolver) Name(ctx context.Context) (string, error) {
+	atomic.AddInt32(&i.r.calls, 1)
+	return "item", ctx.Err()
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	t.Parallel()
+
+	r := &bigListResolver{}
+	schema := graphql.MustParseSchema(`
+		type Query {
+			items: [Item!]!
+			small: [Item!]!
+		}
+
+		type Item {
+			name: String!
+		}
+	`, r, graphql.MaxResponseBytes(100))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `{ small { name } }`,
+			ExpectedResult: `{"small": [{"name": "item"}]}`,
+		},
+		{
+			Schema: schema,
+			Query:  `{ items { name } }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "response exceeds the maximum size of 100 bytes",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeResponseTooLarge},
+			}},
+		},
+	})
+
+	resp := schema.Exec(context.Background(), `{ items { name } }`, "", nil)
+	if len(resp.Errors) != 1 || !errors.Is(resp.Errors[0], graphql.ErrResponseTooLarge) {
+		t.Errorf("got errors %v, want %v", resp.Errors, graphql.ErrResponseTooLarge)
+	}
+	if calls := atomic.LoadInt32(&r.calls); calls >= 1000 {
+		t.Errorf("got %d resolver calls, want the remaining resolvers to be skipped", calls)
+	}
+}
+
+type paginatedResolver struct{}
+
+func (paginatedResolver) Users(args struct {
+	First *int32
+	Last  *int32
+}) []string {
+	n := int32(3)
+	if args.First != nil {
+		n = *args.First
+	} else if args.Last != nil {
+		n = *args.Last
+	}
+	users := []string{"alice", "bob", "carol"}
+	if int(n) < len(users) {
+		users = users[:n]
+	}
+	return users
+}
+
+func TestPaginationRequired(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		directive @paginationRequired(max: Int = 100) on FIELD_DEFINITION
+
+		type Query {
+			users(first: Int, last: Int): [String!]! @paginationRequired(max: 2)
+		}
+	`, paginatedResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `{ users(first: 2) }`,
+			ExpectedResult: `{"users": ["alice", "bob"]}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `query($n: Int = 1) { users(last: $n) }`,
+			ExpectedResult: `{"users": ["alice"]}`,
+		},
+		{
+			Schema: schema,
+			Query:  `{ users }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Field "users" must be paginated with the "first" or "last" argument.`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 3}},
+				Rule:       "PaginationRequiredRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+		{
+			Schema:    schema,
+			Query:     `query($n: Int) { users(first: $n) }`,
+			Variables: map[string]interface{}{"n": float64(500)},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Argument "first" of field "users" must be between 0 and 2, got 500.`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 31}},
+				Rule:       "PaginationRequiredRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+	})
+}
+
+type constrainedResolver struct{}
+
+func (constrainedResolver) User(args struct {
+	ID   *graphql.ID
+	Slug *string
+}) string {
+	if args.ID != nil {
+		return "user " + string(*args.ID)
+	}
+	return "user " + *args.Slug
+}
+
+func (constrainedResolver) Posts(args struct {
+	First *int32
+	After *string
+}) []string {
+	return []string{"post"}
+}
+
+func TestArgumentConstraints(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		directive @requiresOneOf(fields: [String!]!) repeatable on FIELD_DEFINITION
+		directive @mutuallyExclusive(fields: [String!]!) repeatable on FIELD_DEFINITION
+		directive @requiredWith(field: String!, with: [String!]!) repeatable on FIELD_DEFINITION
+
+		type Query {
+			user(id: ID, slug: String): String! @requiresOneOf(fields: ["id", "slug"]) @mutuallyExclusive(fields: ["id", "slug"])
+			posts(first: Int, after: String): [String!]! @requiredWith(field: "first", with: ["after"])
+		}
+	`, constrainedResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         schema,
+			Query:          `query($slug: String = "luke") { user(slug: $slug) posts(first: 1, after: "x") }`,
+			ExpectedResult: `{"user": "user luke", "posts": ["post"]}`,
+		},
+		{
+			Schema: schema,
+			Query: `query($id: ID) {
+				user(id: $id, slug: null)
+			}`,
+			Variables: map[string]interface{}{"id": nil},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Field "user" requires one of the arguments "id", "slug".`,
+				Locations:  []gqlerrors.Location{{Line: 2, Column: 5}},
+				Rule:       "RequiresOneOfRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+		{
+			Schema:    schema,
+			Query:     `query($slug: String) { user(id: "1", slug: $slug) }`,
+			Variables: map[string]interface{}{"slug": "luke"},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Arguments "id" and "slug" of field "user" are mutually exclusive.`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 38}},
+				Rule:       "MutuallyExclusiveRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+		{
+			Schema: schema,
+			Query:  `{ posts(after: "x") }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Argument "first" of field "posts" is required with argument "after".`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 9}},
+				Rule:       "RequiredWithRule",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+	})
+
+	// variables without a known value may be provided at execution
+	if errs := schema.Validate(`query($id: ID) { user(id: $id) }`); len(errs) != 0 {
+		t.Errorf("got errors %v, want none", errs)
+	}
+}
+
+func TestArgumentConstraints_UnknownArgument(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		field string
+		want  string
+	}{
+		{
+			field: `user(id: ID, slug: String): String! @requiresOneOf(fields: ["id", "name"])`,
+			want:  `@requiresOneOf of field Query.user names unknown argument "name"`,
+		},
+		{
+			field: `user(id: ID, slug: String): String! @mutuallyExclusive(fields: ["ID", "slug"])`,
+			want:  `@mutuallyExclusive of field Query.user names unknown argument "ID"`,
+		},
+		{
+			field: `posts(first: Int, after: String): [String!]! @requiredWith(field: "last", with: ["after"])`,
+			want:  `@requiredWith of field Query.posts names unknown argument "last"`,
+		},
+	} {
+		_, err := graphql.ParseSchema(`
+			directive @requiresOneOf(fields: [String!]!) repeatable on FIELD_DEFINITION
+			directive @mutuallyExclusive(fields: [String!]!) repeatable on FIELD_DEFINITION
+			directive @requiredWith(field: String!, with: [String!]!) repeatable on FIELD_DEFINITION
+
+			type Query {
+				`+tt.field+`
+			}
+		`, constrainedResolver{})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
+}
+
+type groupedResolver struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (r *groupedResolver) call(ctx context.Context) int32 {
+	r.mu.Lock()
+	r.inFlight++
+	if r.inFlight > r.max {
+		r.max = r.inFlight
+	}
+	r.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	r.mu.Lock()
+	r.inFlight--
+	r.mu.Unlock()
+	return 1
+}
+
+func (r *groupedResolver) Users(ctx context.Context) int32  { return r.call(ctx) }
+func (r *groupedResolver) Orders(ctx context.Context) int32 { return r.call(ctx) }
+
+func TestConcurrencyGroup(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		type Query {
+			users: Int!
+			orders: Int!
+		}
+	`
+	r := &groupedResolver{}
+	schema := graphql.MustParseSchema(sdl, r, graphql.ConcurrencyGroup("db", 2, "Query.users", "Query.orders"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := schema.Exec(context.Background(), `{ a: users b: users orders }`, "", nil)
+			if len(resp.Errors) != 0 {
+				t.Error(resp.Errors)
+			}
+		}()
+	}
+	wg.Wait()
+	if r.max != 2 {
+		t.Errorf("got at most %d concurrent resolver calls, want 2", r.max)
+	}
+
+	for _, tt := range []struct {
+		opts []graphql.SchemaOpt
+		want string
+	}{
+		{
+			opts: []graphql.SchemaOpt{graphql.ConcurrencyGroup("db", 0, "Query.users")},
+			want: `concurrency group "db": limit must be positive, got 0`,
+		},
+		{
+			opts: []graphql.SchemaOpt{graphql.ConcurrencyGroup("db", 1, "Query.user")},
+			want: `concurrency group "db": unknown field "Query.user"`,
+		},
+		{
+			opts: []graphql.SchemaOpt{graphql.ConcurrencyGroup("db", 1, "users")},
+			want: `concurrency group "db": invalid field "users", expected "Type.field"`,
+		},
+		{
+			opts: []graphql.SchemaOpt{
+				graphql.ConcurrencyGroup("cache", 1, "Query.users"),
+				graphql.ConcurrencyGroup("db", 1, "Query.users"),
+			},
+			want: `concurrency group "db": field "Query.users" already belongs to group "cache"`,
+		},
+	} {
+		if _, err := graphql.ParseSchema(sdl, r, tt.opts...); err == nil || err.Error() != tt.want {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestVariableWarnings(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		type Query {
+			greet(name: String): String!
+		}
+	`
+	query := `query($unused: Int) { greet(name: $name) }`
+	vars := map[string]interface{}{"name": "Alice", "unused": 1, "extra": true}
+
+	strict := graphql.MustParseSchema(sdl, &greetResolver{})
+	if resp := strict.Exec(context.Background(), query, "", vars); len(resp.Errors) != 2 || resp.Data != nil {
+		t.Errorf("want two validation errors without the option, got %v", resp.Errors)
+	}
+
+	schema := graphql.MustParseSchema(sdl, &greetResolver{}, graphql.VariableWarnings())
+	resp := schema.Exec(context.Background(), query, "", vars)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	if got, want := string(resp.Data), `{"greet":"Hello, Alice!"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	warnings, _ := resp.Extensions["warnings"].([]*gqlerrors.QueryError)
+	var messages []string
+	for _, w := range warnings {
+		messages = append(messages, w.Message)
+	}
+	if want := []string{`Variable "$name" is not defined.`, `Variable "$unused" is never used.`}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("got warnings %q, want %q", messages, want)
+	}
+}
+
+// longScalar is a custom scalar of 64-bit integers.
+type longScalar int64
+
+func (longScalar) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+func (l *longScalar) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case int64:
+		*l = longScalar(input)
+	case float64:
+		*l = longScalar(input)
+	default:
+		return fmt.Errorf("wrong type for Long: %T", input)
+	}
+	return nil
+}
+
+func (l longScalar) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(fmt.Sprint(int64(l)))), nil
+}
+
+type variablesDecoderResolver struct{}
+
+func (variablesDecoderResolver) Echo(args struct{ ID longScalar }) longScalar {
+	return args.ID
+}
+
+func (variablesDecoderResolver) Sum(args struct {
+	Values []int32
+	Scale  float64
+}) float64 {
+	var sum float64
+	for _, v := range args.Values {
+		sum += float64(v)
+	}
+	return sum * args.Scale
+}
+
+// formDecoder decodes variables of the form "id=1&values=1,2" into typed values.
+type formDecoder struct{}
+
+func (formDecoder) DecodeVariables(data []byte) (map[string]interface{}, error) {
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]interface{})
+	if id := form.Get("id"); id != "" {
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		vars["id"] = n
+	}
+	if values := form.Get("values"); values != "" {
+		var list []int64
+		for _, v := range strings.Split(values, ",") {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, n)
+		}
+		vars["values"] = list
+	}
+	return vars, nil
+}
+
+func TestVariablesDecoder(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		scalar Long
+
+		type Query {
+			echo(id: Long!): Long!
+			sum(values: [Int!]!, scale: Float! = 1): Float!
+		}
+	`
+	query := `query($id: Long!, $values: [Int!]!) { echo(id: $id) sum(values: $values, scale: 2) }`
+
+	for _, tc := range []struct {
+		name string
+		dec  graphql.VariablesDecoder
+		vars string
+		want string
+	}{
+		{
+			name: "default",
+			vars: `{"id": 9007199254740993, "values": [1, 2]}`,
+			want: `{"echo":"9007199254740992","sum":6}`,
+		},
+		{
+			name: "int64",
+			dec:  graphql.JSONVariablesDecoder{UseInt64: true},
+			vars: `{"id": 9007199254740993, "values": [1, 2]}`,
+			want: `{"echo":"9007199254740993","sum":6}`,
+		},
+		{
+			name: "typed values",
+			dec:  formDecoder{},
+			vars: `id=9007199254740993&values=1,2,3`,
+			want: `{"echo":"9007199254740993","sum":12}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			schema := graphql.MustParseSchema(sdl, variablesDecoderResolver{})
+			vars, err := graphql.DecodeVariables(tc.dec, []byte(tc.vars))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := schema.Exec(context.Background(), query, "", vars)
+			if len(resp.Errors) != 0 {
+				t.Fatal(resp.Errors)
+			}
+			if got := string(resp.Data); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	schema := graphql.MustParseSchema(sdl, variablesDecoderResolver{})
+	vars, err := graphql.DecodeVariables(formDecoder{}, []byte(`id=1&values=1,2147483648`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := schema.Exec(context.Background(), query, "", vars); len(resp.Errors) != 1 {
+		t.Errorf("want an error for integers exceeding 32 bits, got %v", resp.Errors)
+	}
+}
+
+type greetResolver struct{}
+
+func (*greetResolver) Greet(args struct{ Name *string }) string {
+	if args.Name == nil {
+		return "Hello!"
+	}
+	return "Hello, " + *args.Name + "!"
+}
+
+type dedupResolver struct {
+	calls int32
+}
+
+func (r *dedupResolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*dedupUser, error) {
+	atomic.AddInt32(&r.calls, 1)
+	if args.ID == "0" {
+		return nil, errors.New("no such user")
+	}
+	return &dedupUser{id: args.ID}, nil
+}
+
+type dedupUser struct {
+	id graphql.ID
+}
+
+func (u *dedupUser) Name() string  { return "user " + string(u.id) }
+func (u *dedupUser) Email() string { return string(u.id) + "@example.com" }
+
+func TestDeduplicateFields(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `
+		type Query {
+			user(id: ID!): User
+		}
+
+		type User {
+			name: String!
+			email: String!
+		}
+	`
+	query := `
+		{
+			a: user(id: 1) { name }
+			b: user(id: 1) { email }
+			c: user(id: 2) { name }
+			d: user(id: 0) { name }
+			e: user(id: 0) { email }
+		}
+	`
+	r := &dedupResolver{}
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(sdl, r, graphql.DeduplicateFields()),
+		Query:  query,
+		ExpectedResult: `
+			{
+				"a": {"name": "user 1"},
+				"b": {"email": "1@example.com"},
+				"c": {"name": "user 2"},
+				"d": null,
+				"e": null
+			}
+		`,
+		ExpectedErrors: []*gqlerrors.QueryError{
+			{Message: "no such user", Locations: []gqlerrors.Location{{Line: 6, Column: 4}}, Path: []interface{}{"d"}, ResolverError: errors.New("no such user")},
+			{Message: "no such user", Locations: []gqlerrors.Location{{Line: 7, Column: 4}}, Path: []interface{}{"e"}, ResolverError: errors.New("no such user")},
+		},
+	})
+	if got := atomic.LoadInt32(&r.calls); got != 3 {
+		t.Errorf("got %d resolver calls, want 3", got)
+	}
+
+	r = &dedupResolver{}
+	graphql.MustParseSchema(sdl, r).Exec(context.Background(), query, "", nil)
+	if got := atomic.LoadInt32(&r.calls); got != 5 {
+		t.Errorf("got %d resolver calls without deduplication, want 5", got)
+	}
+
+	// sorted keys execute trivial fields sequentially in sorted order
+	r = &dedupResolver{}
+	sorted := graphql.MustParseSchema(sdl, r, graphql.DeduplicateFields(), graphql.SortResponseKeys(), graphql.TrivialFields("Query.user"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp := sorted.Exec(ctx, `{ z: user(id: 1) { name } a: user(id: 1) { name } }`, "", nil)
+	if got, want := string(resp.Data), `{"a":{"name":"user 1"},"z":{"name":"user 1"}}`; got != want || len(resp.Errors) != 0 {
+		t.Errorf("got %s and errors %v with sorted keys, want %s", got, resp.Errors, want)
+	}
+	if got := atomic.LoadInt32(&r.calls); got != 1 {
+		t.Errorf("got %d resolver calls with sorted keys, want 1", got)
+	}
+
+	// fields with different directives are not identical
+	atomic.StoreInt32(&r.calls, 0)
+	resp = sorted.Exec(context.Background(), `query($no: Boolean!) { a: user(id: 1) { name } b: user(id: 1) @include(if: $no) { name } }`, "", map[string]interface{}{"no": true})
+	if got := atomic.LoadInt32(&r.calls); got != 2 || len(resp.Errors) != 0 {
+		t.Errorf("got %d resolver calls and errors %v for fields with different directives, want 2", got, resp.Errors)
+	}
+}
+
+type recordingRequestLogger struct {
+	mu   sync.Mutex
+	reqs []log.Request
+}
+
+func (l *recordingRequestLogger) LogRequest(ctx context.Context, req log.Request) {
+	l.mu.Lock()
+	req.Duration = 0
+	l.reqs = append(l.reqs, req)
+	l.mu.Unlock()
+}
+
+func TestLogRequests(t *testing.T) {
+	logger := &recordingRequestLogger{}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.LogRequests(logger, "commentary", "Token"))
+
+	schema.Exec(context.Background(), `
+		mutation CreateReview($episode: Episode!, $review: ReviewInput!) {
+			createReview(episode: $episode, review: $review) {
+				stars
+			}
+		}
+	`, "CreateReview", map[string]interface{}{
+		"episode": "JEDI",
+		"review":  map[string]interface{}{"stars": 5, "commentary": "secret"},
+		"token":   "abc",
+	})
+	schema.Exec(context.Background(), `{ hero { unknown } }`, "", nil)
+	schema.Exec(context.Background(), `query Hero { hero { name } }`, "", nil)
+
+	want := []log.Request{
+		{
+			OperationName: "CreateReview",
+			Variables: map[string]interface{}{
+				"episode": "JEDI",
+				"review":  map[string]interface{}{"stars": 5, "commentary": log.Redacted},
+				"token":   log.Redacted,
+			},
+		},
+		{
+			ErrorCount: 1,
+		},
+		{
+			OperationName: "Hero",
+		},
+	}
+	if !reflect.DeepEqual(logger.reqs, want) {
+		t.Errorf("got requests %#v, want %#v", logger.reqs, want)
+	}
+}
+
+type RootResolver struct{}
+type QueryResolver struct{}
+type MutationResolver struct{}
+type SubscriptionResolver struct {
+	err      error
+	upstream <-chan *helloEventResolver
+}
+
+func (r *RootResolver) Query() *QueryResolver {
+	return &QueryResolver{}
+}
+
+func (r *RootResolver) Mutation() *MutationResolver {
+	return &MutationResolver{}
+}
+
+type helloEventResolver struct {
+	msg string
+	err error
+}
+
+func (r *helloEventResolver) Msg() (string, error) {
+	return r.msg, r.err
+}
+
+func closedHelloEventUpstream(rr ...*helloEventResolver) <-chan *helloEventResolver {
+	c := make(chan *helloEventResolver, len(rr))
+	for _, r := range rr {
+		c <- r
+	}
+	close(c)
+	return c
+}
+
+func (r *RootResolver) Subscription() *SubscriptionResolver {
+	return &SubscriptionResolver{
+		upstream: closedHelloEventUpstream(
+			&helloEventResolver{msg: "Hello subscription!"},
+			&helloEventResolver{err: errors.New("resolver error")},
+			&helloEventResolver{msg: "Hello again!"},
+		),
+	}
+}
+
+func (qr *QueryResolver) Hello() string {
+	return "Hello query!"
+}
+
+func (mr *MutationResolver) Hello() string {
+	return "Hello mutation!"
+}
+
+func (sr *SubscriptionResolver) Hello(ctx context.Context) (chan *helloEventResolver, error) {
+	if sr.err != nil {
+		return nil, sr.err
+	}
+
+	c := make(chan *helloEventResolver)
+	go func() {
+		for r := range sr.upstream {
+			select {
+			case <-ctx.Done():
+				close(c)
+				return
+			case c <- r:
+			}
+		}
+		close(c)
+	}()
+
+	return c, nil
+}
+
+type errRootResolver1 struct {
+	RootResolver
+}
+
+// Query is invalid because it doesn't have a return value.
+func (*errRootResolver1) Query() {}
+
+type errRootResolver2 struct {
+	RootResolver
+}
+
+// Query is invalid because it has more than 1 return value
+func (*errRootResolver2) Query() (*QueryResolver, error) {
+	return nil, nil
+}
+
+type errRootResolver3 struct {
+	RootResolver
+}
+
+// Mutation is invalid because it returns nil
+func (*errRootResolver3) Mutation() *MutationResolver {
+	return nil
+}
+
+type errRootResolver4 struct {
+	RootResolver
+}
+
+// Query is invalid because it doesn't return a pointer.
+func (*errRootResolver4) Query() MutationResolver {
+	return MutationResolver{}
+}
+
+type errRootResolver5 struct {
+	RootResolver
+}
+
+// Query is invalid because it returns *[]int instead of a resolver.
+func (*errRootResolver5) Query() *[]int {
+	return &[]int{1, 2}
+}
+
+type errRootResolver6 struct {
+	RootResolver
+}
+
+// Mutation is invalid because it returns a map[string]int instead of a resolver.
+func (*errRootResolver6) Mutation() map[string]int {
+	return map[string]int{"key": 3}
+}
+
+type errRootResolver7 struct {
+	RootResolver
+}
+
+// Subscription is invalid because it returns an invalid resolver.
+func (*errRootResolver7) Subscription() interface{} {
+	a := struct {
+		Name string
+	}{Name: "invalid"}
+	return &a
+}
+
+type errRootResolver8 struct {
+	RootResolver
+}
+
+// Query is invalid because it accepts arguments.
+func (*errRootResolver8) Query(ctx context.Context) *QueryResolver {
+	return &QueryResolver{}
+}
+
+// TestSeparateResolvers ensures that a field with the same name is allowed in different operations
+func TestSeparateResolvers(t *testing.T) {
+	helloEverywhere := `
+		schema {
+			query: Query
+			mutation: Mutation
+			subscription: Subscription
+		}
+
+		type Query {
+			hello: String!
+		}
+
+		type Mutation {
+			hello: String!
+		}
+
+		type Subscription {
+			hello: HelloEvent!
+		}
+
+		type HelloEvent {
+			msg: String!
+		}
+	`
+
+	separateSchema := graphql.MustParseSchema(helloEverywhere, &RootResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: separateSchema,
+			Query: `
+				query {
+					hello
+				}
+			`,
+			ExpectedResult: `
+				{
+					"hello": "Hello query!"
+				}
+			`,
+		},
+		{
+			Schema: separateSchema,
+			Query: `
+				mutation {
+					hello
+				}
+			`,
+			ExpectedResult: `
+				{
+					"hello": "Hello mutation!"
+				}
+			`,
+		},
+	})
+
+	gqltesting.RunSubscribes(t, []*gqltesting.TestSubscription{
+		{
+			Name:   "ok",
+			Schema: separateSchema,
+			Query: `
+				subscription {
+					hello {
+						msg
+					}
+				}
+			`,
+			ExpectedResults: []gqltesting.TestResponse{
+				{
+					Data: json.RawMessage(`
+						{
+							"hello": {
+								"msg": "Hello subscription!"
+							}
+						}
+					`),
+				},
+				{
+					// null propagates all the way up because msg is non-null
+					Data:   json.RawMessage(`null`),
+					Errors: []*gqlerrors.QueryError{resolverErrorAt(4, 7)},
+				},
+				{
+					Data: json.RawMessage(`
+						{
+							"hello": {
+								"msg": "Hello again!"
+							}
+						}
+					`),
+				},
+			},
+		},
+	})
+
+	// test errors with invalid resolvers
+	tests := []struct {
+		name     string
+		resolver interface{}
+		opts     []graphql.SchemaOpt
+		wantErr  string
+	}{
+		{
+			name:     "query_method_has_no_return_val",
+			resolver: &errRootResolver1{},
+			wantErr:  "method \"Query\" of *graphql_test.errRootResolver1 must have 1 return value, got 0",
+		},
+		{
+			name:     "query_method_returns_too_many_vals",
+			resolver: &errRootResolver2{},
+			wantErr:  "method \"Query\" of *graphql_test.errRootResolver2 must have 1 return value, got 2",
+		},
+		{
+			name:     "mutation_method_returns_nil",
 			resolver: &errRootResolver3{},
 			wantErr:  "method \"Mutation\" of *graphql_test.errRootResolver3 must return a non-nil result, got <nil>",
 		},
 		{
-			name:     "query_method_does_not_return_a_pointer",
-			resolver: &errRootResolver4{},
-			wantErr:  "method \"Query\" of *graphql_test.errRootResolver4 must return an interface or a pointer, got graphql_test.MutationResolver",
+			name:     "query_method_returns_a_value_without_methods",
+			resolver: &errRootResolver4{},
+			wantErr:  "graphql_test.MutationResolver does not resolve \"Query\": missing method for field \"hello\", searched the empty method set of graphql_test.MutationResolver; expected Hello() resolving \"String!\" (hint: the method exists on the pointer type)",
+		},
+		{
+			name:     "query_method_returns_invalid_resolver_type",
+			resolver: &errRootResolver5{},
+			wantErr:  "*[]int does not resolve \"Query\": missing method for field \"hello\", searched the empty method set of *[]int; expected Hello() resolving \"String!\"",
+		},
+		{
+			name:     "mutation_method_returns_invalid_resolver_type",
+			resolver: &errRootResolver6{},
+			wantErr:  "map[string]int does not resolve \"Mutation\": missing method for field \"hello\", searched the empty method set of map[string]int; expected Hello() resolving \"String!\"",
+		},
+		{
+			name:     "query_subscription_returns_invalid_resolver_type",
+			resolver: &errRootResolver7{},
+			wantErr:  "*struct { Name string } does not resolve \"Subscription\": missing method for field \"hello\", searched the empty method set of *struct { Name string }; expected Hello() resolving \"HelloEvent!\"",
+		},
+		{
+			name:     "mutation_method_returns_invalid_resolver_type",
+			resolver: &errRootResolver8{},
+			wantErr:  "method \"Query\" of *graphql_test.errRootResolver8 must not accept any arguments, got 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := graphql.ParseSchema(helloEverywhere, tt.resolver, tt.opts...)
+			if err == nil {
+				t.Fatalf("want err: %q, got: <nil>", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Fatalf("want err: %q, got: %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+type valueQuery struct{}
+
+func (valueQuery) Point() valuePoint          { return valuePoint{x: 1, y: 2} }
+func (valueQuery) Settings() valueSettings    { return valueSettings{"theme": "dark"} }
+func (valueQuery) NoSettings() valueSettings  { return nil }
+func (valueQuery) Tags() valueTags            { return valueTags{"a", "b"} }
+func (valueQuery) AliasedPoint() aliasedPoint { return aliasedPoint{x: 3, y: 4} }
+
+type valuePoint struct{ x, y int32 }
+
+func (p valuePoint) X() int32 { return p.x }
+func (p valuePoint) Y() int32 { return p.y }
+
+type aliasedPoint = valuePoint
+
+type valueSettings map[string]string
+
+func (s valueSettings) Theme() string { return s["theme"] }
+
+type valueTags []string
+
+func (t valueTags) Count() int32  { return int32(len(t)) }
+func (t valueTags) First() string { return t[0] }
+
+func TestNonPointerResolvers(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(`
+			type Query {
+				point: Point
+				settings: Settings!
+				noSettings: Settings
+				tags: Tags
+				aliasedPoint: Point!
+			}
+
+			type Point {
+				x: Int!
+				y: Int!
+			}
+
+			type Settings {
+				theme: String!
+			}
+
+			type Tags {
+				count: Int!
+				first: String!
+			}
+		`, valueQuery{}),
+		Query: `{ point { x y } settings { theme } noSettings { theme } tags { count first } aliasedPoint { x } }`,
+		ExpectedResult: `
+			{
+				"point": {"x": 1, "y": 2},
+				"settings": {"theme": "dark"},
+				"noSettings": null,
+				"tags": {"count": 2, "first": "a"},
+				"aliasedPoint": {"x": 3}
+			}
+		`,
+	})
+}
+
+type mappedMethodsResolver struct{}
+
+func (*mappedMethodsResolver) GraphQLFieldMethods() map[string]string {
+	return map[string]string{"type": "Kind", "id": "Identifier"}
+}
+
+func (*mappedMethodsResolver) Kind() string           { return "admin" }
+func (*mappedMethodsResolver) Identifier() graphql.ID { return "1" }
+func (*mappedMethodsResolver) Name() string           { return "Alice" }
+
+type badMappedMethodsResolver struct{}
+
+func (badMappedMethodsResolver) GraphQLFieldMethods() map[string]string {
+	return map[string]string{"name": "FullName"}
+}
+
+func (badMappedMethodsResolver) Name() string { return "Alice" }
+
+func TestFieldMethods(t *testing.T) {
+	t.Parallel()
+
+	var _ graphql.FieldMethods = &mappedMethodsResolver{}
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(`
+			type Query {
+				type: String!
+				id: ID!
+				name: String!
+			}
+		`, &mappedMethodsResolver{}),
+		Query: `{ type id name }`,
+		ExpectedResult: `
+			{
+				"type": "admin",
+				"id": "1",
+				"name": "Alice"
+			}
+		`,
+	})
+
+	_, err := graphql.ParseSchema(`type Query { name: String! }`, &badMappedMethodsResolver{})
+	want := `*graphql_test.badMappedMethodsResolver does not resolve "Query": method "FullName" mapped to field "name" does not exist`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
+
+type snakeCaseResolver struct{}
+
+func (*snakeCaseResolver) UserID() graphql.ID { return "42" }
+
+func (*snakeCaseResolver) FullName(args struct{ FirstOnly bool }) string {
+	if args.FirstOnly {
+		return "Ada"
+	}
+	return "Ada Lovelace"
+}
+
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 && (runes[i-1] < 'A' || runes[i-1] > 'Z' || (i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z')) {
+			b.WriteByte('_')
+		}
+		b.WriteString(strings.ToLower(string(r)))
+	}
+	return b.String()
+}
+
+func TestNameMapper(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		type Query {
+			user_id: ID!
+			full_name(first_only: Boolean!): String!
+		}
+	`
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(sdl, &snakeCaseResolver{}, graphql.NameMapper(toSnakeCase)),
+		Query:  `{ user_id full_name(first_only: true) }`,
+		ExpectedResult: `
+			{
+				"user_id": "42",
+				"full_name": "Ada"
+			}
+		`,
+	})
+
+	_, err := graphql.ParseSchema(`type Query { userid: ID! }`, &snakeCaseResolver{}, graphql.NameMapper(toSnakeCase))
+	if err == nil || !strings.Contains(err.Error(), `missing method for field "userid"`) {
+		t.Errorf("expected a missing method error, got %v", err)
+	}
+}
+
+type strictQuery struct{}
+
+func (*strictQuery) Hello() string           { return "hello" }
+func (*strictQuery) Goodbye() string         { return "goodbye" }
+func (*strictQuery) User() *strictUser       { return &strictUser{} }
+func (*strictQuery) SetName(name string) int { return 0 }
+
+type strictUser struct{}
+
+func (*strictUser) Name() string { return "Alice" }
+
+func TestStrictResolvers(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		type Query {
+			hello: String!
+			user: User!
+			missing: String
+		}
+
+		type User {
+			name: String!
+			email: String!
+		}
+	`
+	_, err := graphql.ParseSchema(sdl, &strictQuery{}, graphql.StrictResolvers())
+	var multi gqlerrors.MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected a multi error, got %v", err)
+	}
+	want := []string{
+		"*graphql_test.strictUser does not resolve \"User\": missing method for field \"email\", searched the method set of *graphql_test.strictUser [Name]; expected Email() resolving \"String!\"\n\tused by (*graphql_test.strictQuery).User",
+		`*graphql_test.strictQuery does not resolve "Query": missing method for field "missing", searched the method set of *graphql_test.strictQuery [Goodbye, Hello, SetName, User]; expected Missing() resolving "String"`,
+		`*graphql_test.strictQuery: method "Goodbye" does not resolve any schema field`,
+		`*graphql_test.strictQuery: method "SetName" does not resolve any schema field`,
+	}
+	if len(multi) != len(want) {
+		t.Fatalf("want %d errors, got %d: %v", len(want), len(multi), multi)
+	}
+	for i, err := range multi {
+		if err.Error() != want[i] {
+			t.Errorf("error %d:\nwant %s\ngot  %s", i, want[i], err)
+		}
+	}
+
+	if _, err := graphql.ParseSchema(`type Query { hello: String! }`, &helloResolver{}, graphql.StrictResolvers()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type bindingQuery struct{}
+
+func (*bindingQuery) User() *bindingUser { return &bindingUser{} }
+func (*bindingQuery) Count() string      { return "" }
+
+type bindingUser struct{}
+
+func (*bindingUser) Name(ctx context.Context, extra int) string { return "" }
+
+func TestResolverBindingErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := graphql.ParseSchema(`
+		type Query {
+			user: User!
+			count: Int!
+			missing: String
+		}
+
+		type User {
+			name: String!
+			email: String!
+		}
+	`, &bindingQuery{})
+	var multi gqlerrors.MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected a multi error, got %v", err)
+	}
+	want := []string{
+		"too many arguments\n\tused by (*graphql_test.bindingUser).Name\n\tused by (*graphql_test.bindingQuery).User",
+		"*graphql_test.bindingUser does not resolve \"User\": missing method for field \"email\", searched the method set of *graphql_test.bindingUser [Name]; expected Email() resolving \"String!\"\n\tused by (*graphql_test.bindingQuery).User",
+		"can not use string as Int\n\tused by (*graphql_test.bindingQuery).Count",
+		`*graphql_test.bindingQuery does not resolve "Query": missing method for field "missing", searched the method set of *graphql_test.bindingQuery [Count, User]; expected Missing() resolving "String"`,
+	}
+	if len(multi) != len(want) {
+		t.Fatalf("want %d errors, got %d: %v", len(want), len(multi), multi)
+	}
+	for i, err := range multi {
+		if err.Error() != want[i] {
+			t.Errorf("error %d:\nwant %s\ngot  %s", i, want[i], err)
+		}
+	}
+}
+
+type suggestionQuery struct{}
+
+func (*suggestionQuery) Heroes() []string { return nil }
+
+func TestResolverBindingSuggestions(t *testing.T) {
+	t.Parallel()
+
+	_, err := graphql.ParseSchema(`
+		input ReviewInput {
+			stars: Int!
+		}
+
+		enum Episode {
+			NEWHOPE
+		}
+
+		type Query {
+			heros(episode: Episode, first: Int = 10, after_id: ID, review: ReviewInput!, tags: [String!]): [String!]!
+		}
+	`, &suggestionQuery{})
+	want := `*graphql_test.suggestionQuery does not resolve "Query": missing method for field "heros", searched the method set of *graphql_test.suggestionQuery [Heroes]; ` +
+		`expected Heros(args struct{ Episode *string; First int32; AfterId *graphql.ID; Review ReviewInput; Tags *[]string }) resolving "[String!]!". Did you mean "Heroes"?`
+	if err == nil || err.Error() != want {
+		t.Errorf("unexpected error:\nwant %s\ngot  %v", want, err)
+	}
+}
+
+func TestSchemaExtension(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+	directive @awesome on SCHEMA
+
+	schema {
+		query: Query
+	}
+
+	type Query {
+		hello: String!
+	}
+	
+	extend schema @awesome
+	`
+	schema := graphql.MustParseSchema(sdl, &helloResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					hello
+				}
+			`,
+			ExpectedResult: `
+				{
+					"hello": "Hello world!"
+				}
+			`,
+		},
+	})
+
+	ast := schema.AST()
+	dirs := ast.SchemaDefinition.Directives
+	if len(dirs) != 1 {
+		t.Fatalf("expected 1 schema directive, got %d", len(dirs))
+	}
+	name := dirs[0].Name.Name
+	if name != "awesome" {
+		t.Fatalf(`expected an "awesome" schema directive, got %q`, dirs[0].Name.Name)
+	}
+}
+
+func TestDirectiveArgumentValue(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		enum Unit { SECONDS MINUTES }
+
+		input Window {
+			size: Int!
+			unit: Unit!
+		}
+
+		directive @limit(max: Int!, ratio: Float, name: String, enabled: Boolean, unit: Unit, tags: [String!], window: Window) on FIELD_DEFINITION
+
+		type Query {
+			hello: String! @limit(max: 10, ratio: 0.5, name: "hello", enabled: true, unit: MINUTES, tags: ["a", "b"], window: {size: 2, unit: SECONDS})
+		}
+	`
+	schema := graphql.MustParseSchema(sdl, &helloResolver{})
+
+	d := schema.AST().Types["Query"].(*ast.ObjectTypeDefinition).Fields.Get("hello").Directives.Get("limit")
+	for name, want := range map[string]interface{}{
+		"max":     int32(10),
+		"ratio":   0.5,
+		"name":    "hello",
+		"enabled": true,
+		"unit":    "MINUTES",
+		"tags":    []interface{}{"a", "b"},
+		"window":  map[string]interface{}{"size": int32(2), "unit": "SECONDS"},
+	} {
+		got, ok := d.ArgumentValue(name)
+		if !ok {
+			t.Errorf("argument %q not found", name)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("argument %q: want %#v, got %#v", name, want, got)
+		}
+	}
+	if _, ok := d.ArgumentValue("missing"); ok {
+		t.Error("expected a missing argument not to be found")
+	}
+}
+
+func TestGraphqlNames(t *testing.T) {
+	t.Parallel()
+
+	sdl1 := `
+	type Query {
+		hello: String!
+	}
+	`
+	type invalidResolver1 struct {
+		Field1 string `graphql:"hello"`
+		Field2 string `graphql:"hello"`
+	}
+
+	wantErr := fmt.Errorf(`*graphql_test.invalidResolver1 does not resolve "Query": multiple fields have a graphql reflect tag "hello"`)
+	_, err := graphql.ParseSchema(sdl1, &invalidResolver1{}, graphql.UseFieldResolvers())
+	if err == nil || err.Error() != wantErr.Error() {
+		t.Fatalf("want err %q, got %q", wantErr, err)
+	}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(`
+				type Query {
+					_hello: String!
+					hello: String!
+					Hello: String!
+					HELLO: String!
+				}`,
+				func() interface{} {
+					type helloTagResolver struct {
+						Hello           string
+						HelloUnderscore string `graphql:"_hello"`
+						HelloLower      string `graphql:"hello"`
+						HelloTitle      string `graphql:"Hello"`
+						HelloUpper      string `graphql:"HELLO"`
+					}
+					return &helloTagResolver{
+						Hello:           "This field will not be used during query execution!",
+						HelloLower:      "Hello, graphql!",
+						HelloTitle:      "Hello, GraphQL!",
+						HelloUnderscore: "Hello, _!",
+						HelloUpper:      "Hello, GRAPHQL!",
+					}
+				}(),
+				graphql.UseFieldResolvers()),
+			Query: `
+				{
+					_hello
+					hello
+					Hello
+					HELLO
+				}
+			`,
+			ExpectedResult: `
+				{
+					"_hello": "Hello, _!",
+				    "hello": "Hello, graphql!",
+				    "Hello": "Hello, GraphQL!",
+				    "HELLO": "Hello, GRAPHQL!"
+				}
+			`,
+		},
+	})
+}
+
+func Test_fieldFunc(t *testing.T) {
+	sdl := `
+		type Query {
+			hello(name: String!): String!
+		}
+	`
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(sdl,
+				func() interface{} {
+					type helloTagResolver struct {
+						Hello func(args struct{ Name string }) string
+					}
+					fn := func(args struct{ Name string }) string {
+						return "Hello, " + args.Name + "!"
+					}
+					return &helloTagResolver{
+						Hello: fn,
+					}
+				}(),
+				graphql.UseFieldResolvers()),
+			Query: `
+				{
+					hello(name: "GraphQL")
+				}
+			`,
+			ExpectedResult: `
+				{
+				    "hello": "Hello, GraphQL!"
+				}
+			`,
+		},
+		{
+			Schema: graphql.MustParseSchema(sdl,
+				func() interface{} {
+					type helloTagResolver struct {
+						Greet func(ctx context.Context, args struct{ Name string }) (string, error) `graphql:"hello"`
+					}
+					fn := func(_ context.Context, args struct{ Name string }) (string, error) {
+						return "Hello, " + args.Name + "!", nil
+					}
+					return &helloTagResolver{
+						Greet: fn,
+					}
+				}(),
+				graphql.UseFieldResolvers()),
+			Query: `
+				{
+					hello(name: "GraphQL")
+				}
+			`,
+			ExpectedResult: `
+				{
+				    "hello": "Hello, GraphQL!"
+				}
+			`,
+		},
+	})
+}
+
+type profiledResolver struct{}
+
+func (*profiledResolver) Slow(ctx context.Context) string {
+	time.Sleep(20 * time.Millisecond)
+	return "slow"
+}
+
+func (*profiledResolver) Fast(ctx context.Context) string {
+	return "fast"
+}
+
+func TestEnableProfiling(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			slow: String!
+			fast: String!
+		}
+	`, &profiledResolver{}, graphql.EnableProfiling())
+
+	resp := schema.Exec(context.Background(), `{ fast slow second: fast }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+
+	b, err := json.Marshal(resp.Extensions["profile"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var profile []struct {
+		Path       []interface{} `json:"path"`
+		ParentType string        `json:"parentType"`
+		FieldName  string        `json:"fieldName"`
+		Duration   int64         `json:"duration"`
+	}
+	if err := json.Unmarshal(b, &profile); err != nil {
+		t.Fatal(err)
+	}
+	if len(profile) != 3 {
+		t.Fatalf("expected 3 profile entries, got %d: %s", len(profile), b)
+	}
+	if profile[0].FieldName != "slow" || profile[0].ParentType != "Query" || profile[0].Path[0] != "slow" {
+		t.Errorf("expected the slowest field first, got %s", b)
+	}
+	if profile[0].Duration < int64(20*time.Millisecond) {
+		t.Errorf("unexpected duration %d", profile[0].Duration)
+	}
+	for i := 1; i < len(profile); i++ {
+		if profile[i].Duration > profile[i-1].Duration {
+			t.Errorf("profile entries are not sorted: %s", b)
+		}
+	}
+
+	resp = graphql.MustParseSchema(`type Query { fast: String! }`, &profiledResolver{}).Exec(context.Background(), `{ fast }`, "", nil)
+	if resp.Extensions != nil {
+		t.Errorf("unexpected extensions without profiling: %v", resp.Extensions)
+	}
+}
+
+type emailResolver struct{}
+
+func (*emailResolver) Echo(args struct{ Email string }) string {
+	return args.Email
+}
+
+func TestScalarValidator(t *testing.T) {
+	t.Parallel()
+
+	validateEmail := func(v interface{}) error {
+		s, ok := v.(string)
+		if !ok || !strings.Contains(s, "@") {
+			return fmt.Errorf("not an email address")
+		}
+		return nil
+	}
+	schema := graphql.MustParseSchema(`
+		scalar EmailAddress
+
+		type Query {
+			echo(email: EmailAddress!): EmailAddress!
+		}
+	`, &emailResolver{}, graphql.ScalarValidator("EmailAddress", validateEmail))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ echo(email: "a@example.com") }`,
+			ExpectedResult: `
+				{
+					"echo": "a@example.com"
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query:  `{ echo(email: "nope") }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Argument "email" has invalid value "nope".` + "\n" + `Expected type "EmailAddress", found "nope": not an email address.`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 15}},
+				Rule:       "ArgumentsOfCorrectType",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+		{
+			Schema:    schema,
+			Query:     `query($email: EmailAddress!) { echo(email: $email) }`,
+			Variables: map[string]interface{}{"email": "nope"},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    `Variable "email" has invalid value nope.` + "\n" + `Expected type "EmailAddress", found nope: not an email address.`,
+				Locations:  []gqlerrors.Location{{Line: 1, Column: 7}},
+				Rule:       "VariablesOfCorrectType",
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeValidationFailed},
+			}},
+		},
+	})
+
+	if _, err := graphql.ParseSchema(`type Query { a: String }`, nil, graphql.ScalarValidator("EmailAddress", validateEmail)); err == nil {
+		t.Error("expected an error for an unknown scalar")
+	}
+}
+
+type evenInt int32
+
+func (evenInt) ImplementsGraphQLType(name string) bool {
+	return name == "EvenInt"
+}
+
+func (e *evenInt) UnmarshalGraphQL(input interface{}) error {
+	n, ok := input.(int32)
+	if !ok || n%2 != 0 {
+		return fmt.Errorf("%v is not an even number", input)
+	}
+	*e = evenInt(n)
+	return nil
+}
+
+type inputPathResolver struct{}
+
+func (*inputPathResolver) Count(args struct {
+	Filter struct {
+		Users []struct {
+			Age evenInt
+		}
+	}
+}) int32 {
+	return int32(len(args.Filter.Users))
+}
+
+func TestInputErrorPath(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		scalar EvenInt
+
+		input UserFilter {
+			age: EvenInt!
+		}
+
+		input Filter {
+			users: [UserFilter!]!
+		}
+
+		type Query {
+			count(filter: Filter!): Int!
+		}
+	`, &inputPathResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ count(filter: {users: [{age: 2}, {age: 4}, {age: 5}]}) }`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   "filter.users[2].age (expected EvenInt!): 5 is not an even number",
+				Locations: []gqlerrors.Location{{Line: 1, Column: 3}},
+				Extensions: map[string]interface{}{
+					"code":         gqlerrors.CodeBadUserInput,
+					"inputPath":    "filter.users[2].age",
+					"expectedType": "EvenInt!",
+				},
+			}},
+			ExpectedResult: "{}",
+		},
+	})
+}
+
+type listIDs []graphql.ID
+
+type listCount int32
+
+type listArgsResolver struct{}
+
+func (r *listArgsResolver) IDs(args struct{ IDs listIDs }) string {
+	return fmt.Sprint([]graphql.ID(args.IDs))
+}
+
+func (r *listArgsResolver) Point(args struct{ Coords [2]float64 }) string {
+	return fmt.Sprint(args.Coords)
+}
+
+func (r *listArgsResolver) Counts(args struct{ Counts *[]listCount }) string {
+	return fmt.Sprint(*args.Counts)
+}
+
+func (r *listArgsResolver) Days(args struct{ Times []*graphql.Time }) string {
+	var days []int
+	for _, t := range args.Times {
+		days = append(days, t.Day())
+	}
+	return fmt.Sprint(days)
+}
+
+func TestListArguments(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		scalar Time
+
+		type Query {
+			iDs(iDs: [ID!]!): String!
+			point(coords: [Float!]!): String!
+			counts(counts: [Int!]): String!
+			days(times: [Time!]!): String!
+		}
+	`, &listArgsResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `{
+				iDs(iDs: ["a", "b"])
+				point(coords: [1.5, 2])
+				counts(counts: [1, 2, 3])
+				days(times: ["2020-01-02T00:00:00Z", "2020-01-03T00:00:00Z"])
+			}`,
+			ExpectedResult: `{
+				"iDs": "[a b]",
+				"point": "[1.5 2]",
+				"counts": "[1 2 3]",
+				"days": "[2 3]"
+			}`,
+		},
+		{
+			Schema: schema,
+			Query:  `query($coords: [Float!]!) { point(coords: $coords) }`,
+			Variables: map[string]interface{}{
+				"coords": []interface{}{1.0, 2.0, 3.0},
+			},
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:   "coords (expected [Float!]!): expected a list of 2 elements, got 3",
+				Locations: []gqlerrors.Location{{Line: 1, Column: 29}},
+				Extensions: map[string]interface{}{
+					"code":         gqlerrors.CodeBadUserInput,
+					"inputPath":    "coords",
+					"expectedType": "[Float!]!",
+				},
+			}},
+			ExpectedResult: "{}",
+		},
+	})
+}
+
+type userQueries interface {
+	User() string
+}
+
+type postQueries interface {
+	Post(args struct{ ID graphql.ID }) (*string, error)
+}
+
+type realUsers struct{}
+
+func (realUsers) User() string { return "real user" }
+
+type mockUsers struct{}
+
+func (*mockUsers) User() string { return "mock user" }
+
+type realPosts struct{}
+
+func (realPosts) Post(args struct{ ID graphql.ID }) (*string, error) {
+	post := "post " + string(args.ID)
+	return &post, nil
+}
+
+type embeddedQueries struct {
+	postQueries
+}
+
+type embeddedInterfacesResolver struct {
+	userQueries
+	*embeddedQueries
+}
+
+func TestEmbeddedInterfaces(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		type Query {
+			user: String!
+			post(id: ID!): String
+		}
+	`
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: graphql.MustParseSchema(sdl, &embeddedInterfacesResolver{realUsers{}, &embeddedQueries{realPosts{}}}),
+			Query:  `{ user post(id: "1") }`,
+			ExpectedResult: `{
+				"user": "real user",
+				"post": "post 1"
+			}`,
+		},
+		{
+			Schema: graphql.MustParseSchema(sdl, &embeddedInterfacesResolver{&mockUsers{}, &embeddedQueries{}}),
+			Query:  `{ user post(id: "1") }`,
+			ExpectedResult: `{
+				"user": "mock user",
+				"post": null
+			}`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:       "(*graphql_test.embeddedInterfacesResolver).Post can not be called: embedded graphql_test.postQueries of *graphql_test.embeddedInterfacesResolver is nil",
+				Locations:     []gqlerrors.Location{{Line: 1, Column: 8}},
+				Path:          []interface{}{"post"},
+				ResolverError: errors.New("(*graphql_test.embeddedInterfacesResolver).Post can not be called: embedded graphql_test.postQueries of *graphql_test.embeddedInterfacesResolver is nil"),
+			}},
+		},
+	})
+}
+
+type typedEpisode int
+
+const (
+	newHope typedEpisode = iota + 1
+	empire
+	jedi
+)
+
+func (e typedEpisode) String() string {
+	switch e {
+	case newHope:
+		return "NEWHOPE"
+	case empire:
+		return "EMPIRE"
+	case jedi:
+		return "JEDI"
+	}
+	return fmt.Sprintf("typedEpisode(%d)", int(e))
+}
+
+func (typedEpisode) Values() []typedEpisode {
+	return []typedEpisode{newHope, empire, jedi}
+}
+
+// typedColor has a String method like those generated by stringer and no Values method.
+type typedColor uint8
+
+func (c typedColor) String() string {
+	names := [...]string{"RED", "GREEN"}
+	if int(c) < len(names) {
+		return names[c]
+	}
+	return fmt.Sprintf("typedColor(%d)", c)
+}
+
+type typedEnumResolver struct{}
+
+func (typedEnumResolver) Next(args struct{ Episode typedEpisode }) typedEpisode {
+	return args.Episode + 1
+}
+
+func (typedEnumResolver) Colors(args struct{ Colors []typedColor }) []typedColor {
+	return append(args.Colors, 0)
+}
+
+func TestTypedEnums(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		enum Episode { NEWHOPE EMPIRE JEDI }
+		enum Color { RED GREEN }
+
+		type Query {
+			next(episode: Episode!): Episode!
+			colors(colors: [Color!]!): [Color!]!
+		}
+	`
+	schema := graphql.MustParseSchema(sdl, typedEnumResolver{})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `{ next(episode: NEWHOPE) colors(colors: [GREEN]) }`,
+			ExpectedResult: `{
+				"next": "EMPIRE",
+				"colors": ["GREEN", "RED"]
+			}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ next(episode: JEDI) }`,
+			ExpectedResult: `null`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Invalid value typedEpisode(4).\nExpected type Episode, found typedEpisode(4).",
+				Path:       []interface{}{"next"},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeInvalidEnumValue},
+			}},
+		},
+	})
+
+	for _, tc := range []struct {
+		sdl  string
+		want string
+	}{
+		{
+			sdl:  `enum Episode { NEWHOPE EMPIRE } enum Color { RED GREEN } type Query { next(episode: Episode!): Episode! colors(colors: [Color!]!): [Color!]! }`,
+			want: `values JEDI of graphql_test.typedEpisode are not defined by enum "Episode"`,
+		},
+		{
+			sdl:  `enum Episode { NEWHOPE EMPIRE JEDI } enum Color { RED GREEN BLUE } type Query { next(episode: Episode!): Episode! colors(colors: [Color!]!): [Color!]! }`,
+			want: `graphql_test.typedColor has no values for BLUE of enum "Color"`,
+		},
+	} {
+		_, err := graphql.ParseSchema(tc.sdl, typedEnumResolver{})
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("got error %v, want %q", err, tc.want)
+		}
+	}
+}
+
+// pbEpisode is an enum like those generated by protoc, whose names differ from the schema.
+type pbEpisode int32
+
+const (
+	pbEpisodeUnspecified pbEpisode = iota
+	pbEpisodeNewHope
+	pbEpisodeEmpire
+	pbEpisodeJedi
+)
+
+func (e pbEpisode) String() string {
+	return [...]string{"EPISODE_UNSPECIFIED", "EPISODE_NEW_HOPE", "EPISODE_EMPIRE", "EPISODE_JEDI"}[e]
+}
+
+type enumMappingResolver struct{}
+
+func (enumMappingResolver) Next(args struct{ Episode pbEpisode }) pbEpisode {
+	if args.Episode == pbEpisodeJedi {
+		return pbEpisodeUnspecified
+	}
+	return args.Episode + 1
+}
+
+func (enumMappingResolver) Codes(args struct{ Episodes []int32 }) []int32 {
+	return args.Episodes
+}
+
+func TestEnumMapping(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		enum Episode { NEWHOPE EMPIRE JEDI }
+
+		type Query {
+			next(episode: Episode!): Episode!
+			codes(episodes: [Episode!]!): [Episode!]!
+		}
+	`
+	mapping := graphql.EnumMapping(map[string]map[string]interface{}{
+		"Episode": {"NEWHOPE": pbEpisodeNewHope, "EMPIRE": pbEpisodeEmpire, "JEDI": pbEpisodeJedi},
+	})
+	schema := graphql.MustParseSchema(sdl, enumMappingResolver{}, mapping)
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query:  `query($episodes: [Episode!]!) { next(episode: NEWHOPE) codes(episodes: $episodes) }`,
+			Variables: map[string]interface{}{
+				"episodes": []interface{}{"JEDI", "EMPIRE"},
+			},
+			ExpectedResult: `{
+				"next": "EMPIRE",
+				"codes": ["JEDI", "EMPIRE"]
+			}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ next(episode: JEDI) }`,
+			ExpectedResult: `null`,
+			ExpectedErrors: []*gqlerrors.QueryError{{
+				Message:    "Invalid value EPISODE_UNSPECIFIED.\nExpected type Episode, found EPISODE_UNSPECIFIED.",
+				Path:       []interface{}{"next"},
+				Extensions: map[string]interface{}{"code": gqlerrors.CodeInvalidEnumValue},
+			}},
+		},
+	})
+
+	for _, tc := range []struct {
+		mapping map[string]map[string]interface{}
+		want    string
+	}{
+		{
+			mapping: map[string]map[string]interface{}{"Episode": {"NEWHOPE": pbEpisodeNewHope, "EMPIRE": pbEpisodeEmpire}},
+			want:    `enum mapping of "Episode": value "JEDI" is not mapped`,
+		},
+		{
+			mapping: map[string]map[string]interface{}{"Episode": {"NEWHOPE": 1, "EMPIRE": 2, "JEDI": 3, "CLONES": 4}},
+			want:    `enum mapping of "Episode": "CLONES" is not a value of the enum`,
+		},
+		{
+			mapping: map[string]map[string]interface{}{"Season": {"WINTER": 1}},
+			want:    `enum mapping of "Season": no such enum`,
+		},
+		{
+			mapping: map[string]map[string]interface{}{"Episode": {"NEWHOPE": "4", "EMPIRE": "5", "JEDI": "6"}},
+			want:    `(string) as graphql_test.pbEpisode`,
+		},
+		{
+			mapping: map[string]map[string]interface{}{"Episode": {"NEWHOPE": pbEpisodeNewHope, "EMPIRE": pbEpisodeNewHope, "JEDI": pbEpisodeJedi}},
+			want:    `enum mapping of "Episode": "EMPIRE" and "NEWHOPE" are mapped to the same value`,
+		},
+		{
+			mapping: map[string]map[string]interface{}{"Episode": {"NEWHOPE": pbEpisodeNewHope, "EMPIRE": int32(1), "JEDI": pbEpisodeJedi}},
+			want:    `enum mapping of "Episode": "EMPIRE" and "NEWHOPE" are both mapped to`,
+		},
+	} {
+		_, err := graphql.ParseSchema(sdl, enumMappingResolver{}, graphql.EnumMapping(tc.mapping))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("got error %v, want %q", err, tc.want)
+		}
+	}
+}
+
+type lenientEnumResolver struct{}
+
+func (lenientEnumResolver) Status() *string {
+	s := "ARCHIVED"
+	return &s
+}
+
+func (lenientEnumResolver) Statuses() []*string {
+	active, archived := "ACTIVE", "ARCHIVED"
+	return []*string{&active, &archived}
+}
+
+func (lenientEnumResolver) Required() string { return "ARCHIVED" }
+
+func TestLenientEnumOutput(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		enum Status { ACTIVE INACTIVE }
+
+		type Query {
+			status: Status
+			statuses: [Status]!
+			required: Status!
+		}
+	`
+	schema := graphql.MustParseSchema(sdl, lenientEnumResolver{}, graphql.LenientEnumOutput())
+
+	resp := schema.Exec(context.Background(), `{ status statuses }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	if got, want := string(resp.Data), `{"status":null,"statuses":["ACTIVE",null]}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	warnings, _ := resp.Extensions["warnings"].([]*gqlerrors.QueryError)
+	var paths []string
+	for _, w := range warnings {
+		paths = append(paths, fmt.Sprint(w.Path))
+	}
+	sort.Strings(paths)
+	if want := []string{"[status]", "[statuses 1]"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got warnings at %q, want %q", paths, want)
+	}
+
+	resp = schema.Exec(context.Background(), `{ required }`, "", nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "Invalid value ARCHIVED.\nExpected type Status, found ARCHIVED." {
+		t.Errorf("want an error for non-null fields, got %v", resp.Errors)
+	}
+
+	strict := graphql.MustParseSchema(sdl, lenientEnumResolver{})
+	if resp := strict.Exec(context.Background(), `{ status }`, "", nil); len(resp.Errors) != 1 || resp.Extensions["warnings"] != nil {
+		t.Errorf("want an error without the option, got %v", resp.Errors)
+	}
+}
+
+func TestCollectStats(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.CollectStats())
+	resp := schema.Exec(context.Background(), `{ hero { name friends { name } } }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	if resp.Stats == nil {
+		t.Fatal("expected stats")
+	}
+	// hero, hero.name, hero.friends and the names of three friends
+	if resp.Stats.ResolverCount != 6 {
+		t.Errorf("expected 6 resolver calls, got %d", resp.Stats.ResolverCount)
+	}
+	if resp.Stats.MaxDepth != 3 {
+		t.Errorf("expected max depth 3, got %d", resp.Stats.MaxDepth)
+	}
+	if resp.Stats.Duration <= 0 || resp.Stats.CacheHits != 0 {
+		t.Errorf("unexpected stats %+v", resp.Stats)
+	}
+
+	resp = schema.Exec(context.Background(), `{ unknown }`, "", nil)
+	if resp.Stats == nil || resp.Stats.ResolverCount != 0 {
+		t.Errorf("expected empty stats for invalid queries, got %+v", resp.Stats)
+	}
+
+	if resp := starwarsSchema.Exec(context.Background(), `{ hero { name } }`, "", nil); resp.Stats != nil {
+		t.Errorf("unexpected stats without the option: %+v", resp.Stats)
+	}
+}
+
+type shape interface {
+	Area() float64
+}
+
+type circle struct {
+	Radius *float64
+}
+
+func (c *circle) Area() float64 { return 3 * *c.Radius * *c.Radius }
+
+type rectangle struct {
+	Width  *float64
+	Height *float64
+}
+
+func (r rectangle) Area() float64 { return *r.Width * *r.Height }
+
+type shapeResolver struct{}
+
+func (r *shapeResolver) Area(args struct{ Shape shape }) float64 {
+	return args.Shape.Area()
+}
+
+func (r *shapeResolver) TotalArea(args struct{ Shapes []shape }) float64 {
+	var total float64
+	for _, s := range args.Shapes {
+		if s != nil {
+			total += s.Area()
+		}
+	}
+	return total
+}
+
+func TestInputUnion(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		enum ShapeKind { CIRCLE RECTANGLE }
+
+		input ShapeInput {
+			kind: ShapeKind!
+			radius: Float
+			width: Float
+			height: Float
+		}
+
+		type Query {
+			area(shape: ShapeInput!): Float!
+			totalArea(shapes: [ShapeInput]!): Float!
+		}
+	`, &shapeResolver{}, graphql.RegisterInputUnion((*shape)(nil), "kind", map[string]interface{}{
+		"CIRCLE":    &circle{},
+		"RECTANGLE": rectangle{},
+	}))
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					circle: area(shape: {kind: CIRCLE, radius: 2})
+					rectangle: area(shape: {kind: RECTANGLE, width: 2, height: 3})
+				}
+			`,
+			ExpectedResult: `{"circle": 12, "rectangle": 6}`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				query($shapes: [ShapeInput]!) {
+					totalArea(shapes: $shapes)
+				}
+			`,
+			Variables: map[string]interface{}{
+				"shapes": []interface{}{
+					map[string]interface{}{"kind": "CIRCLE", "radius": 1},
+					nil,
+					map[string]interface{}{"kind": "RECTANGLE", "width": 1, "height": 2},
+				},
+			},
+			ExpectedResult: `{"totalArea": 5}`,
+		},
+	})
+}
+
+func TestInputUnionErrors(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		input ShapeInput {
+			radius: Float
+		}
+
+		type Query {
+			area(shape: ShapeInput!): Float!
+		}
+	`
+	for _, tc := range []struct {
+		name    string
+		iface   interface{}
+		members map[string]interface{}
+		wantErr string
+	}{
+		{
+			name:    "not an interface",
+			iface:   circle{},
+			wantErr: "input union must be registered with a pointer to an interface, got graphql_test.circle",
 		},
 		{
-			name:     "query_method_returns_invalid_resolver_type",
-			resolver: &errRootResolver5{},
-			wantErr:  "*[]int does not resolve \"Query\": missing method for field \"hello\"",
+			name:    "missing discriminator",
+			iface:   (*shape)(nil),
+			members: map[string]interface{}{"CIRCLE": &circle{}},
+			wantErr: `input object "ShapeInput" does not define discriminator field "kind"`,
 		},
 		{
-			name:     "mutation_method_returns_invalid_resolver_type",
-			resolver: &errRootResolver6{},
-			wantErr:  "method \"Mutation\" of *graphql_test.errRootResolver6 must return an interface or a pointer, got map[string]int",
+			name:    "nil member",
+			iface:   (*shape)(nil),
+			members: map[string]interface{}{"CIRCLE": nil},
+			wantErr: `input union member "CIRCLE" of graphql_test.shape is nil`,
+		},
+	} {
+		_, err := graphql.ParseSchema(sdl, &shapeResolver{}, graphql.RegisterInputUnion(tc.iface, "kind", tc.members))
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: want error containing %q, got %v", tc.name, tc.wantErr, err)
+		}
+	}
+}
+
+type fieldDirectivesResolver struct{}
+
+func (r *fieldDirectivesResolver) User(ctx context.Context) string {
+	d := graphql.FieldDirectives(ctx).Get("rest")
+	if d == nil {
+		return "no directive"
+	}
+	url, _ := d.ArgumentValue("url")
+	return url.(string)
+}
+
+func (r *fieldDirectivesResolver) Plain(ctx context.Context) string {
+	return fmt.Sprintf("%d directives", len(graphql.FieldDirectives(ctx)))
+}
+
+func TestFieldDirectives(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(`
+			directive @rest(url: String!) on FIELD_DEFINITION
+
+			type Query {
+				user: String! @rest(url: "https://example.com/users/1")
+				plain: String!
+			}
+		`, &fieldDirectivesResolver{}),
+		Query:          `{ user plain }`,
+		ExpectedResult: `{"user": "https://example.com/users/1", "plain": "0 directives"}`,
+	})
+}
+
+type currentFieldResolver struct{}
+
+func (r *currentFieldResolver) Root(ctx context.Context) string {
+	f, ok := graphql.CurrentField(ctx)
+	return fmt.Sprintf("%s.%s %v %v", f.TypeName, f.FieldName, f.Root, ok)
+}
+
+func (r *currentFieldResolver) Child() *currentFieldChild {
+	return &currentFieldChild{id: "7"}
+}
+
+type currentFieldChild struct {
+	id string
+}
+
+func (c *currentFieldChild) Name(ctx context.Context) string {
+	f, ok := graphql.CurrentField(ctx)
+	return fmt.Sprintf("%s.%s %v %v %s", f.TypeName, f.FieldName, f.Root, ok, f.Parent.(*currentFieldChild).id)
+}
+
+func (c *currentFieldChild) Plain(ctx context.Context) string {
+	_, ok := graphql.CurrentField(ctx)
+	return fmt.Sprint(ok)
+}
+
+func TestCurrentField(t *testing.T) {
+	t.Parallel()
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: graphql.MustParseSchema(`
+			directive @tag on FIELD_DEFINITION
+
+			type Query {
+				root: String! @tag
+				child: Child!
+			}
+
+			type Child {
+				name: String! @tag
+				plain: String!
+			}
+		`, &currentFieldResolver{}),
+		Query: `{ root child { name plain } }`,
+		ExpectedResult: `
+			{
+				"root": "Query.root true true",
+				"child": {"name": "Child.name false true 7", "plain": "false"}
+			}
+		`,
+	})
+}
+
+type flakyResolver struct {
+	calls int32
+}
+
+func (r *flakyResolver) Flaky() (string, error) {
+	if atomic.AddInt32(&r.calls, 1) < 3 {
+		return "", retry.Transient(fmt.Errorf("unavailable"))
+	}
+	return "ok", nil
+}
+
+func (r *flakyResolver) Broken() (*string, error) {
+	return nil, retry.Transient(fmt.Errorf("still unavailable"))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	var retried []string
+	var mu sync.Mutex
+	schema := graphql.MustParseSchema(`
+		type Query {
+			flaky: String!
+			broken: String
+		}
+	`, &flakyResolver{}, graphql.RetryPolicy(func(info retry.FieldInfo) retry.Policy {
+		mu.Lock()
+		retried = append(retried, info.TypeName+"."+info.FieldName)
+		mu.Unlock()
+		return retry.Policy{MaxAttempts: 3}
+	}))
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema:         schema,
+		Query:          `{ flaky broken }`,
+		ExpectedResult: `{"flaky": "ok", "broken": null}`,
+		ExpectedErrors: []*gqlerrors.QueryError{
+			{
+				Message:       "still unavailable",
+				Locations:     []gqlerrors.Location{{Line: 1, Column: 9}},
+				Path:          []interface{}{"broken"},
+				ResolverError: retry.Transient(fmt.Errorf("still unavailable")),
+			},
+		},
+	})
+
+	sort.Strings(retried)
+	if got, want := strings.Join(retried, ","), "Query.broken,Query.flaky"; got != want {
+		t.Errorf("want policies requested for %s, got %s", want, got)
+	}
+}
+
+func TestSchemaPlan(t *testing.T) {
+	t.Parallel()
+
+	p, errs := starwarsSchema.Plan(`
+		query($episode: Episode, $withFriends: Boolean!) {
+			hero(episode: $episode) {
+				__typename
+				name
+				... on Droid {
+					primaryFunction
+				}
+				friends @include(if: $withFriends) {
+					name
+				}
+			}
+			r2: character(id: "2001") {
+				id
+			}
+		}
+	`, "", map[string]interface{}{"episode": "JEDI", "withFriends": false})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	want := `query
+  Query.hero <- (*starwars.QueryResolver).Hero [concurrent]
+    Character.__typename [trivial, serial]
+    Character.name <- (*starwars.characterResolver).Name [trivial, serial]
+    ... on Droid Droid.primaryFunction <- (*starwars.droidResolver).PrimaryFunction [trivial, serial]
+  r2: Query.character <- (*starwars.QueryResolver).Character [concurrent]
+    Character.id <- (*starwars.characterResolver).ID [trivial, serial]
+`
+	if got := p.String(); got != want {
+		t.Errorf("want plan:\n%s\ngot:\n%s", want, got)
+	}
+	if f := p.Fields[0].Fields[2]; f.TypeCondition != "Droid" || !f.Trivial {
+		t.Errorf("unexpected field %+v", f)
+	}
+
+	mutation := graphql.MustParseSchema(`
+		schema {
+			query: Query
+			mutation: Mutation
+		}
+
+		type Query {
+			hello: String!
+		}
+
+		type Mutation {
+			first(n: Int!): Int!
+			second(n: Int!): Int!
+		}
+	`, &mutationPlanResolver{})
+	p, errs = mutation.Plan(`mutation { first(n: 1) second(n: 2) }`, "", nil)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	for _, f := range p.Fields {
+		if f.Concurrent {
+			t.Errorf("expected mutation field %s to be executed serially", f.Name)
+		}
+	}
+
+	if _, errs := starwarsSchema.Plan(`{ unknown }`, "", nil); len(errs) != 1 {
+		t.Errorf("expected a validation error, got %v", errs)
+	}
+}
+
+type mutationPlanResolver struct{}
+
+func (*mutationPlanResolver) Hello() string                       { return "hello" }
+func (*mutationPlanResolver) First(args struct{ N int32 }) int32  { return args.N }
+func (*mutationPlanResolver) Second(args struct{ N int32 }) int32 { return args.N }
+
+type orderResolver struct{}
+
+func (*orderResolver) Slow(ctx context.Context, args struct{ Ms int32 }) *orderResolver {
+	time.Sleep(time.Duration(args.Ms) * time.Millisecond)
+	return &orderResolver{}
+}
+
+func (*orderResolver) A() string { return "a" }
+func (*orderResolver) B() string { return "b" }
+func (*orderResolver) C() string { return "c" }
+
+func TestResponseKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	sdl := `
+		type Query {
+			slow(ms: Int!): Item!
+		}
+
+		type Item {
+			slow(ms: Int!): Item!
+			a: String!
+			b: String!
+			c: String!
+		}
+	`
+	query := `
+		{
+			z: slow(ms: 20) { c ...ab }
+			y: slow(ms: 10) { b a }
+			x: slow(ms: 0) { ... on Item { c } a }
+			z: slow(ms: 20) { a inner: slow(ms: 0) { b } }
+		}
+
+		fragment ab on Item {
+			a
+			b
+		}
+	`
+	for _, tc := range []struct {
+		opts []graphql.SchemaOpt
+		want string
+	}{
+		{
+			want: `{"data":{"z":{"c":"c","a":"a","b":"b","inner":{"b":"b"}},"y":{"b":"b","a":"a"},"x":{"c":"c","a":"a"}}}`,
 		},
 		{
-			name:     "query_subscription_returns_invalid_resolver_type",
-			resolver: &errRootResolver7{},
-			wantErr:  "*struct { Name string } does not resolve \"Subscription\": missing method for field \"hello\"",
+			opts: []graphql.SchemaOpt{graphql.SortResponseKeys()},
+			want: `{"data":{"x":{"a":"a","c":"c"},"y":{"a":"a","b":"b"},"z":{"a":"a","b":"b","c":"c","inner":{"b":"b"}}}}`,
+		},
+	} {
+		schema := graphql.MustParseSchema(sdl, &orderResolver{}, tc.opts...)
+		for i := 0; i < 3; i++ {
+			b, err := json.Marshal(schema.Exec(context.Background(), query, "", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		}
+	}
+}
+
+type counterResolver struct {
+	x int32
+}
+
+func (r *counterResolver) X() int32 { return r.x }
+
+func (r *counterResolver) SetX(args struct{ V int32 }) int32 {
+	r.x = args.V
+	return r.x
+}
+
+func TestSortResponseKeysMutationOrder(t *testing.T) {
+	t.Parallel()
+
+	r := &counterResolver{}
+	schema := graphql.MustParseSchema(`
+		type Query { x: Int! }
+		type Mutation { setX(v: Int!): Int! }
+	`, r, graphql.SortResponseKeys())
+	resp := schema.Exec(context.Background(), `mutation { b: setX(v: 1) a: setX(v: 2) }`, "", nil)
+	if got, want := string(resp.Data), `{"a":2,"b":1}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if r.x != 2 {
+		t.Errorf("got x = %d, want the value of the last mutation field 2", r.x)
+	}
+}
+
+func TestSchemaOperations(t *testing.T) {
+	t.Parallel()
+
+	ops, errs := starwarsSchema.Operations(`
+		query Hero($episode: Episode!) { hero(episode: $episode) { name } }
+		mutation Review { createReview(episode: JEDI, review: {stars: 5}) { stars } }
+		query Droid { character(id: "2001") { name } }
+	`)
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	var got []string
+	for _, op := range ops {
+		got = append(got, fmt.Sprintf("%s %s(%d)", op.Type, op.Name.Name, len(op.Vars)))
+	}
+	if want := "QUERY Hero(1), MUTATION Review(0), QUERY Droid(0)"; strings.Join(got, ", ") != want {
+		t.Errorf("want %s, got %s", want, strings.Join(got, ", "))
+	}
+
+	_, errs = starwarsSchema.Operations(`{ hero { name } } query Named { hero { name } }`)
+	if len(errs) != 1 || errs[0].Rule != "LoneAnonymousOperationRule" {
+		t.Errorf("expected a LoneAnonymousOperationRule error, got %v", errs)
+	}
+}
+
+func TestOperationNameErrors(t *testing.T) {
+	t.Parallel()
+
+	query := `
+		query Hero { hero { name } }
+		query Droid { character(id: "2001") { name } }
+	`
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema:         starwarsSchema,
+			Query:          query,
+			OperationName:  "Droid",
+			ExpectedResult: `{"character": {"name": "R2-D2"}}`,
 		},
 		{
-			name:     "mutation_method_returns_invalid_resolver_type",
-			resolver: &errRootResolver8{},
-			wantErr:  "method \"Query\" of *graphql_test.errRootResolver8 must not accept any arguments, got 1",
+			Schema: starwarsSchema,
+			Query:  query,
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: `more than one operation in query document and no operation name given, the document defines "Hero", "Droid"`, Extensions: map[string]interface{}{"code": gqlerrors.CodeOperationResolutionFailure}},
+			},
+		},
+		{
+			Schema:        starwarsSchema,
+			Query:         query,
+			OperationName: "Human",
+			ExpectedErrors: []*gqlerrors.QueryError{
+				{Message: `no operation with name "Human", the document defines "Hero", "Droid"`, Extensions: map[string]interface{}{"code": gqlerrors.CodeOperationResolutionFailure}},
+			},
+		},
+	})
+}
+
+func TestErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	s := graphql.MustParseSchema(`type Query { hello: String! }`, nil, graphql.MaxDepth(1))
+	for _, tt := range []struct {
+		query string
+		want  string
+	}{
+		{`{ hello `, gqlerrors.CodeParseFailed},
+		{`{ goodbye }`, gqlerrors.CodeValidationFailed},
+		{`{ __schema { types { name } } }`, gqlerrors.CodeMaxDepthExceeded},
+	} {
+		errs := s.Validate(tt.query)
+		if len(errs) == 0 {
+			t.Fatalf("%s: expected errors", tt.query)
+		}
+		if got := errs[0].Extensions["code"]; got != tt.want {
+			t.Errorf("%s: got code %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	resp := s.Exec(context.Background(), `{ hello }`, "", nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != gqlerrors.CodeNoResolver {
+		t.Errorf("got errors %v, want code %v", resp.Errors, gqlerrors.CodeNoResolver)
+	}
+}
+
+type blockingResolver struct {
+	started chan struct{}
+}
+
+func (r *blockingResolver) Slow(ctx context.Context) string {
+	close(r.started)
+	<-ctx.Done()
+	return "done"
+}
+
+func TestCancelOperation(t *testing.T) {
+	t.Parallel()
+
+	r := &blockingResolver{started: make(chan struct{})}
+	s := graphql.MustParseSchema(`type Query { slow: String! }`, r)
+
+	query := `query Runaway { slow }`
+	c := make(chan *graphql.Response)
+	go func() {
+		c <- s.Exec(context.Background(), query, "", nil)
+	}()
+	<-r.started
+
+	ops := s.ActiveOperations()
+	if len(ops) != 1 {
+		t.Fatalf("got %d active operations, want 1", len(ops))
+	}
+	op := ops[0]
+	if op.Type != "query" || op.Name != "Runaway" || op.Query != query || op.Start.IsZero() {
+		t.Errorf("unexpected operation info %+v", op)
+	}
+	if !s.Cancel(op.ID) {
+		t.Fatal("Cancel of an active operation returned false")
+	}
+
+	resp := <-c
+	if resp.Data != nil {
+		t.Errorf("got data %s for a cancelled operation", resp.Data)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "query was cancelled" || !errors.Is(resp.Errors[0], context.Canceled) {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+	if ops := s.ActiveOperations(); len(ops) != 0 {
+		t.Errorf("got active operations %+v after the operation ended", ops)
+	}
+	if s.Cancel(op.ID) {
+		t.Error("Cancel of an ended operation returned true")
+	}
+}
+
+type tenantResolver struct {
+	name string
+}
+
+func (r *tenantResolver) Tenant() string {
+	return r.name
+}
+
+func TestCompilerCache(t *testing.T) {
+	t.Parallel()
+
+	var compiled int32
+	nameMapper := func(goName string) string {
+		atomic.AddInt32(&compiled, 1)
+		return strings.ToLower(goName)
+	}
+	cache := &graphql.TypeCache{}
+	sdl := `type Query { tenant: String! }`
+	parse := func(name string) *graphql.Schema {
+		return graphql.MustParseSchema(sdl, &tenantResolver{name: name}, graphql.CompilerCache(cache), graphql.NameMapper(nameMapper))
+	}
+
+	acme := parse("acme")
+	calls := atomic.LoadInt32(&compiled)
+	if calls == 0 {
+		t.Fatal("the name mapper was not called for the first schema")
+	}
+	globex := parse("globex")
+	if got := atomic.LoadInt32(&compiled); got != calls {
+		t.Errorf("the resolvers were compiled again for the second schema")
+	}
+	if globex.AST() != acme.AST() {
+		t.Error("the schemas do not share the parsed schema")
+	}
+
+	for s, want := range map[*graphql.Schema]string{acme: `{"tenant":"acme"}`, globex: `{"tenant":"globex"}`} {
+		resp := s.Exec(context.Background(), `{ tenant }`, "", nil)
+		if len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+		if string(resp.Data) != want {
+			t.Errorf("got %s, want %s", resp.Data, want)
+		}
+	}
+
+	graphql.MustParseSchema(`type Query { tenant: String! } type Mutation { tenant: String! }`, &tenantResolver{}, graphql.CompilerCache(cache), graphql.NameMapper(nameMapper))
+	if got := atomic.LoadInt32(&compiled); got == calls {
+		t.Error("a different schema string was not compiled")
+	}
+
+	// schemas with different options do not share the compiled resolvers
+	for _, greeting := range []string{"hello", "goodbye"} {
+		greeting := greeting
+		s := graphql.MustParseSchema(sdl, &tenantResolver{name: "acme"}, graphql.CompilerCache(cache), graphql.NameMapper(nameMapper),
+			graphql.FieldFunc("Query", "tenant", func(r *tenantResolver) string { return greeting + " " + r.name }))
+		resp := s.Exec(context.Background(), `{ tenant }`, "", nil)
+		if want := `{"tenant":"` + greeting + ` acme"}`; string(resp.Data) != want {
+			t.Errorf("got %s, want %s", resp.Data, want)
+		}
+	}
+
+	limited := &graphql.TypeCache{MaxEntries: 1}
+	graphql.MustParseSchema(sdl, &tenantResolver{}, graphql.CompilerCache(limited))
+	var perCompile []int32
+	for i := 0; i < 2; i++ {
+		calls = atomic.LoadInt32(&compiled)
+		graphql.MustParseSchema(sdl, &tenantResolver{}, graphql.CompilerCache(limited), graphql.NameMapper(nameMapper))
+		perCompile = append(perCompile, atomic.LoadInt32(&compiled)-calls)
+	}
+	if perCompile[0] == 0 || perCompile[1] != perCompile[0] {
+		t.Errorf("a schema was cached beyond MaxEntries: %d name mapper calls per schema", perCompile)
+	}
+}
+
+type pathGridResolver struct{}
+
+func (pathGridResolver) Things() [][]*pathThingResolver {
+	return [][]*pathThingResolver{{{id: 1}, {id: 2}}, {{id: 3}, {id: 4}}}
+}
+
+type pathThingResolver struct {
+	id int
+}
+
+func (t *pathThingResolver) Name() (string, error) {
+	if t.id == 2 {
+		return "", errors.New("no name")
+	}
+	return strconv.Itoa(t.id), nil
+}
+
+func (t *pathThingResolver) ToItem() (*pathThingResolver, bool) {
+	if t.id == 3 {
+		panic("no type")
+	}
+	return t, true
+}
+
+func TestErrorPathNestedLists(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			things: [[Thing]!]!
+		}
+
+		interface Thing {
+			name: String!
+		}
+
+		type Item implements Thing {
+			name: String!
+		}
+	`, pathGridResolver{})
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query:  `{ things { ... on Item { name } } }`,
+		ExpectedResult: `{
+			"things": [[{"name": "1"}, null], [null, {"name": "4"}]]
+		}`,
+		ExpectedErrors: []*gqlerrors.QueryError{
+			{Message: "no name", Locations: []gqlerrors.Location{{Line: 1, Column: 26}}, Path: []interface{}{"things", 0, 1, "name"}, ResolverError: errors.New("no name")},
+			{Message: "panic occurred: no type", Path: []interface{}{"things", 1, 0}},
 		},
+	})
+
+	if got := gqlerrors.PathString([]interface{}{"things", 0, 1, "name"}); got != "things[0][1].name" {
+		t.Errorf("got path string %q", got)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			_, err := graphql.ParseSchema(helloEverywhere, tt.resolver, tt.opts...)
-			if err == nil {
-				t.Fatalf("want err: %q, got: <nil>", tt.wantErr)
+}
+
+type gatedResolver struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *gatedResolver) Gated() string {
+	r.started <- struct{}{}
+	<-r.release
+	return "done"
+}
+
+func TestMaxConcurrentOperations(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []graphql.OverloadPolicy{graphql.QueueOperations, graphql.RejectOperations} {
+		r := &gatedResolver{started: make(chan struct{}), release: make(chan struct{})}
+		s := graphql.MustParseSchema(`type Query { gated: String! }`, r, graphql.MaxConcurrentOperations(1, policy))
+
+		c := make(chan *graphql.Response)
+		go func() {
+			c <- s.Exec(context.Background(), `{ gated }`, "", nil)
+		}()
+		<-r.started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		resp := s.Exec(ctx, `{ gated }`, "", nil)
+		cancel()
+		if len(resp.Errors) != 1 {
+			t.Fatalf("policy %d: got errors %v, want one error", policy, resp.Errors)
+		}
+		err := resp.Errors[0]
+		switch policy {
+		case graphql.QueueOperations:
+			if !errors.Is(err, context.DeadlineExceeded) || err.Extensions["code"] != gqlerrors.CodeTimeout {
+				t.Errorf("got error %v with extensions %v, want the deadline of the context", err, err.Extensions)
 			}
-			if err.Error() != tt.wantErr {
-				t.Fatalf("want err: %q, got: %q", tt.wantErr, err.Error())
+		case graphql.RejectOperations:
+			if !errors.Is(err, graphql.ErrTooManyOperations) || err.Extensions["code"] != gqlerrors.CodeTooManyOperations {
+				t.Errorf("got error %v with extensions %v, want a rejection", err, err.Extensions)
 			}
-		})
+		}
+
+		r.release <- struct{}{}
+		if resp := <-c; len(resp.Errors) != 0 {
+			t.Fatalf("policy %d: got errors %v", policy, resp.Errors)
+		}
+
+		go func() {
+			<-r.started
+			r.release <- struct{}{}
+		}()
+		if resp := s.Exec(context.Background(), `{ gated }`, "", nil); len(resp.Errors) != 0 {
+			t.Errorf("policy %d: got errors %v after the first operation finished", policy, resp.Errors)
+		}
 	}
 }
 
-func TestSchemaExtension(t *testing.T) {
-	t.Parallel()
+type parallelMutationResolver struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	order   []string
+}
 
-	sdl := `
-	directive @awesome on SCHEMA
+func (r *parallelMutationResolver) Hello() string {
+	return "world"
+}
 
-	schema {
-		query: Query
+func (r *parallelMutationResolver) run(name string) string {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.max {
+		r.max = r.running
 	}
+	r.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	r.mu.Lock()
+	r.running--
+	r.order = append(r.order, name)
+	r.mu.Unlock()
+	return name
+}
 
-	type Query {
-		hello: String!
-	}
-	
-	extend schema @awesome
+func (r *parallelMutationResolver) AddTag(args struct{ Name string }) string {
+	return r.run(args.Name)
+}
+
+func (r *parallelMutationResolver) Publish() string {
+	return r.run("publish")
+}
+
+func TestParallelMutations(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
+		directive @parallel on FIELD_DEFINITION
+
+		schema {
+			query: Query
+			mutation: Query
+		}
+
+		type Query {
+			hello: String!
+			addTag(name: String!): String! @parallel
+			publish: String!
+		}
 	`
-	schema := graphql.MustParseSchema(sdl, &helloResolver{})
+	mutation := `mutation { a: addTag(name: "a") b: addTag(name: "b") c: addTag(name: "c") publish }`
 
-	gqltesting.RunTests(t, []*gqltesting.Test{
-		{
-			Schema: schema,
-			Query: `
-				{
-					hello
-				}
-			`,
-			ExpectedResult: `
-				{
-					"hello": "Hello world!"
-				}
-			`,
-		},
-	})
+	r := &parallelMutationResolver{}
+	s := graphql.MustParseSchema(schemaString, r)
+	resp := s.Exec(context.Background(), mutation, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	if got, want := string(resp.Data), `{"a":"a","b":"b","c":"c","publish":"publish"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if r.max != 3 {
+		t.Errorf("got %d concurrent mutations, want 3", r.max)
+	}
+	if r.order[3] != "publish" {
+		t.Errorf("publish ran before the parallel mutations finished: %v", r.order)
+	}
 
-	ast := schema.AST()
-	dirs := ast.SchemaDefinition.Directives
-	if len(dirs) != 1 {
-		t.Fatalf("expected 1 schema directive, got %d", len(dirs))
+	r = &parallelMutationResolver{}
+	s = graphql.MustParseSchema(schemaString, r, graphql.PlanMutations(func(fields []graphql.MutationField) []int {
+		return []int{1, 1, 1, 1}
+	}))
+	if resp := s.Exec(context.Background(), mutation, "", nil); len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
 	}
-	name := dirs[0].Name.Name
-	if name != "awesome" {
-		t.Fatalf(`expected an "awesome" schema directive, got %q`, dirs[0].Name.Name)
+	if want := []string{"a", "b", "c", "publish"}; r.max != 1 || !reflect.DeepEqual(r.order, want) {
+		t.Errorf("got order %v with %d concurrent mutations, want %v serially", r.order, r.max, want)
 	}
 }
 
-func TestGraphqlNames(t *testing.T) {
+func TestParallelMutationsPlanner(t *testing.T) {
+	parallel := ast.DirectiveList{{Name: ast.Ident{Name: "parallel"}}}
+	fields := []graphql.MutationField{
+		{Name: "a", Directives: parallel},
+		{Name: "b"},
+		{Name: "c", Directives: parallel},
+		{Name: "d", Directives: parallel},
+		{Name: "e", Directives: parallel},
+		{Name: "f"},
+	}
+	if got, want := graphql.ParallelMutations(fields), []int{1, 1, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got stages %v, want %v", got, want)
+	}
+}
+
+type panicStackResolver struct{}
+
+func (panicStackResolver) Crash() *string {
+	panic("crash")
+}
+
+type stackPanicHandler struct {
+	stack []byte
+}
+
+func (h *stackPanicHandler) MakePanicError(ctx context.Context, value interface{}) *gqlerrors.QueryError {
+	panic("MakePanicErrorWithStack must be called instead")
+}
+
+func (h *stackPanicHandler) MakePanicErrorWithStack(ctx context.Context, value interface{}, stack []byte) *gqlerrors.QueryError {
+	h.stack = stack
+	return gqlerrors.Errorf("crashed: %v", value)
+}
+
+func TestPanicStack(t *testing.T) {
 	t.Parallel()
 
-	sdl1 := `
-	type Query {
-		hello: String!
+	tt := &testTracer{mu: &sync.Mutex{}}
+	h := &stackPanicHandler{}
+	s := graphql.MustParseSchema(`type Query { crash: String }`, panicStackResolver{}, graphql.Tracer(tt), graphql.PanicHandler(h))
+
+	resp := s.Exec(context.Background(), `{ crash }`, "", nil)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "crashed: crash" {
+		t.Fatalf("got errors %v, want the error of the panic handler", resp.Errors)
 	}
-	`
-	type invalidResolver1 struct {
-		Field1 string `graphql:"hello"`
-		Field2 string `graphql:"hello"`
+	const frame = "panicStackResolver.Crash"
+	if !strings.Contains(string(h.stack), frame) {
+		t.Errorf("the stack passed to the panic handler does not contain %q:\n%s", frame, h.stack)
+	}
+	if !bytes.Equal(resp.Errors[0].Stack, h.stack) {
+		t.Errorf("the error does not carry the stack of the panic")
+	}
+	if len(tt.fields) != 1 || tt.fields[0].err == nil || !bytes.Equal(tt.fields[0].err.Stack, h.stack) {
+		t.Errorf("the tracer did not receive the stack of the panic: %+v", tt.fields)
 	}
 
-	wantErr := fmt.Errorf(`*graphql_test.invalidResolver1 does not resolve "Query": multiple fields have a graphql reflect tag "hello"`)
-	_, err := graphql.ParseSchema(sdl1, &invalidResolver1{}, graphql.UseFieldResolvers())
-	if err == nil || err.Error() != wantErr.Error() {
-		t.Fatalf("want err %q, got %q", wantErr, err)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), frame) {
+		t.Errorf("the stack is sent to clients: %s", data)
 	}
+}
 
-	gqltesting.RunTests(t, []*gqltesting.Test{
-		{
-			Schema: graphql.MustParseSchema(`
-				type Query {
-					_hello: String!
-					hello: String!
-					Hello: String!
-					HELLO: String!
-				}`,
-				func() interface{} {
-					type helloTagResolver struct {
-						Hello           string
-						HelloUnderscore string `graphql:"_hello"`
-						HelloLower      string `graphql:"hello"`
-						HelloTitle      string `graphql:"Hello"`
-						HelloUpper      string `graphql:"HELLO"`
-					}
-					return &helloTagResolver{
-						Hello:           "This field will not be used during query execution!",
-						HelloLower:      "Hello, graphql!",
-						HelloTitle:      "Hello, GraphQL!",
-						HelloUnderscore: "Hello, _!",
-						HelloUpper:      "Hello, GRAPHQL!",
-					}
-				}(),
-				graphql.UseFieldResolvers()),
-			Query: `
-				{
-					_hello
-					hello
-					Hello
-					HELLO
-				}
-			`,
-			ExpectedResult: `
-				{
-					"_hello": "Hello, _!",
-				    "hello": "Hello, graphql!",
-				    "Hello": "Hello, GraphQL!",
-				    "HELLO": "Hello, GRAPHQL!"
-				}
-			`,
-		},
-	})
+type baseEntity interface {
+	ID() graphql.ID
 }
 
-func Test_fieldFunc(t *testing.T) {
-	sdl := `
+type baseUser struct{ id, name string }
+
+func (u *baseUser) ID() graphql.ID { return graphql.ID(u.id) }
+func (u *baseUser) Name() string   { return u.name }
+
+type basePost struct{ id string }
+
+func (p *basePost) ID() graphql.ID { return graphql.ID(p.id) }
+
+// Label takes precedence over the label of the base resolver.
+func (p *basePost) Label() string { return "post " + p.id }
+
+type baseEntityResolver struct{}
+
+func (baseEntityResolver) Entities() []baseEntityUnion {
+	return []baseEntityUnion{{&baseUser{id: "1", name: "Alice"}}, {&basePost{id: "2"}}}
+}
+
+func (baseEntityResolver) User() *baseUser {
+	return &baseUser{id: "3", name: "Bob"}
+}
+
+type baseEntityUnion struct{ baseEntity }
+
+func (u baseEntityUnion) ToUser() (*baseUser, bool) {
+	v, ok := u.baseEntity.(*baseUser)
+	return v, ok
+}
+
+func (u baseEntityUnion) ToPost() (*basePost, bool) {
+	v, ok := u.baseEntity.(*basePost)
+	return v, ok
+}
+
+type entityBase struct{ prefix string }
+
+func (b entityBase) Label(e baseEntity) string {
+	return b.prefix + string(e.ID())
+}
+
+func (entityBase) Kind() string {
+	return "entity"
+}
+
+type badEntityBase struct{ entityBase }
+
+func (badEntityBase) Label(e baseEntity, extra int) string {
+	return ""
+}
+
+func TestInterfaceBase(t *testing.T) {
+	t.Parallel()
+
+	schemaString := `
 		type Query {
-			hello(name: String!): String!
+			entities: [Entity!]!
+			user: User!
+		}
+
+		interface Entity {
+			id: ID!
+			label: String!
+			kind: String!
+		}
+
+		type User implements Entity {
+			id: ID!
+			label: String!
+			kind: String!
+			name: String!
+		}
+
+		type Post implements Entity {
+			id: ID!
+			label: String!
+			kind: String!
 		}
 	`
 	gqltesting.RunTests(t, []*gqltesting.Test{
 		{
-			Schema: graphql.MustParseSchema(sdl,
-				func() interface{} {
-					type helloTagResolver struct {
-						Hello func(args struct{ Name string }) string
-					}
-					fn := func(args struct{ Name string }) string {
-						return "Hello, " + args.Name + "!"
-					}
-					return &helloTagResolver{
-						Hello: fn,
-					}
-				}(),
-				graphql.UseFieldResolvers()),
+			Schema: graphql.MustParseSchema(schemaString, &baseEntityResolver{}, graphql.InterfaceBase("Entity", entityBase{prefix: "entity "})),
 			Query: `
 				{
-					hello(name: "GraphQL")
-				}
-			`,
-			ExpectedResult: `
-				{
-				    "hello": "Hello, GraphQL!"
-				}
-			`,
-		},
-		{
-			Schema: graphql.MustParseSchema(sdl,
-				func() interface{} {
-					type helloTagResolver struct {
-						Greet func(ctx context.Context, args struct{ Name string }) (string, error) `graphql:"hello"`
-					}
-					fn := func(_ context.Context, args struct{ Name string }) (string, error) {
-						return "Hello, " + args.Name + "!", nil
-					}
-					return &helloTagResolver{
-						Greet: fn,
+					entities {
+						id
+						label
+						kind
+						... on User { name }
+						... on Post { postLabel: label }
 					}
-				}(),
-				graphql.UseFieldResolvers()),
-			Query: `
-				{
-					hello(name: "GraphQL")
+					user { label kind }
 				}
 			`,
 			ExpectedResult: `
 				{
-				    "hello": "Hello, GraphQL!"
+					"entities": [
+						{"id": "1", "label": "entity 1", "kind": "entity", "name": "Alice"},
+						{"id": "2", "label": "entity 2", "kind": "entity", "postLabel": "post 2"}
+					],
+					"user": {"label": "entity 3", "kind": "entity"}
 				}
 			`,
 		},
 	})
+
+	_, err := graphql.ParseSchema(schemaString, &baseEntityResolver{})
+	if err == nil || !strings.Contains(err.Error(), `missing method for field "label"`) {
+		t.Errorf("got error %v without interface base, want a missing method", err)
+	}
+
+	_, err = graphql.ParseSchema(schemaString, &baseEntityResolver{}, graphql.InterfaceBase("Entity", badEntityBase{}))
+	if err == nil || !strings.Contains(err.Error(), `used by graphql.InterfaceBase("Entity", graphql_test.badEntityBase)`) {
+		t.Errorf("got error %v, want an error of the interface base", err)
+	}
 }
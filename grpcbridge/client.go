@@ -0,0 +1,150 @@
+package grpcbridge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// StatusError is returned by [Client] for calls which ended with a gRPC status other than OK.
+type StatusError struct {
+	// Code is the gRPC status code.
+	Code int
+	// Message is the status message.
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("grpcbridge: status %d: %s", e.Code, e.Message)
+}
+
+// Client calls the GraphQL service of graphql.proto, e.g. served by a [Handler], without generated code.
+type Client struct {
+	// URL is the base URL of the server, e.g. "https://graphql.internal:8443".
+	URL string
+	// HTTPClient sends the requests. It must speak HTTP/2, e.g. with a TLS transport. It defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// MaxMessageBytes is the maximum size of response messages. It defaults to 4 MB.
+	MaxMessageBytes int
+}
+
+// Execute executes the query or mutation of req.
+func (c *Client) Execute(ctx context.Context, req graphql.Request) (*graphql.Response, error) {
+	s, err := c.call(ctx, ExecutePath, req)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	resp, err := s.Recv()
+	if err == io.EOF {
+		return nil, &StatusError{Code: codeInternal, Message: "missing response message"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Recv(); err != io.EOF {
+		if err == nil {
+			return nil, &StatusError{Code: codeInternal, Message: "more than one response message"}
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Subscribe executes the operation of req and returns the stream of its responses, one per event of
+// subscriptions.
+func (c *Client) Subscribe(ctx context.Context, req graphql.Request) (*Stream, error) {
+	return c.call(ctx, SubscribePath, req)
+}
+
+func (c *Client) call(ctx context.Context, path string, req graphql.Request) (*Stream, error) {
+	msg, err := marshalRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := writeMessage(&body, msg); err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.URL, "/")+path, &body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/grpc")
+	httpReq.Header.Set("Te", "trailers")
+	if deadline, ok := ctx.Deadline(); ok {
+		ms := time.Until(deadline).Milliseconds()
+		if ms < 1 {
+			ms = 1
+		}
+		httpReq.Header.Set("Grpc-Timeout", strconv.FormatInt(ms, 10)+"m")
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("grpcbridge: unexpected HTTP status %s", resp.Status)
+	}
+	max := c.MaxMessageBytes
+	if max <= 0 {
+		max = defaultMaxMessageBytes
+	}
+	return &Stream{resp: resp, max: max}, nil
+}
+
+// Stream is the stream of responses of a call of [Client.Subscribe].
+type Stream struct {
+	resp *http.Response
+	max  int
+}
+
+// Recv returns the next response. It returns io.EOF once the stream ended successfully and a
+// *StatusError if the server ended it with an error.
+func (s *Stream) Recv() (*graphql.Response, error) {
+	msg, err := readMessage(s.resp.Body, s.max)
+	if err == io.EOF {
+		return nil, s.status()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalResponse(msg)
+}
+
+// Close ends the stream.
+func (s *Stream) Close() error {
+	return s.resp.Body.Close()
+}
+
+// status returns io.EOF if the call ended with the status OK and a *StatusError otherwise. The status
+// is read from the trailers, or from the headers of responses without messages.
+func (s *Stream) status() error {
+	code, message := s.resp.Trailer.Get("Grpc-Status"), s.resp.Trailer.Get("Grpc-Message")
+	if code == "" {
+		code, message = s.resp.Header.Get("Grpc-Status"), s.resp.Header.Get("Grpc-Message")
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return &StatusError{Code: codeInternal, Message: fmt.Sprintf("missing or invalid status %q", code)}
+	}
+	if n != codeOK {
+		return &StatusError{Code: n, Message: decodeMessage(message)}
+	}
+	return io.EOF
+}
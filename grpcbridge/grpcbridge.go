@@ -0,0 +1,245 @@
+// Package grpcbridge serves a schema as the gRPC service defined by graphql.proto, so that internal
+// services can execute operations without the overhead of JSON requests over HTTP/1.1. The requests and
+// responses carry the query and the variables and the members of the GraphQL response as JSON:
+//
+//	srv := &http.Server{Addr: ":8443", Handler: &grpcbridge.Handler{Schema: schema}}
+//	log.Fatal(srv.ListenAndServeTLS("cert.pem", "key.pem"))
+//
+// gRPC requires HTTP/2, which net/http servers speak over TLS. Unencrypted HTTP/2 (h2c) needs a handler
+// such as the one of golang.org/x/net/http2/h2c. The handler implements the gRPC wire protocol itself,
+// so the package does not depend on a gRPC library; messages must not be compressed.
+package grpcbridge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Paths of the methods of the GraphQL service.
+const (
+	ExecutePath   = "/graphql.v1.GraphQL/Execute"
+	SubscribePath = "/graphql.v1.GraphQL/Subscribe"
+)
+
+// gRPC status codes, see https://grpc.github.io/grpc/core/md_doc_statuscodes.html.
+const (
+	codeOK                = 0
+	codeCanceled          = 1
+	codeUnknown           = 2
+	codeInvalidArgument   = 3
+	codeDeadlineExceeded  = 4
+	codeResourceExhausted = 8
+	codeUnimplemented     = 12
+	codeInternal          = 13
+)
+
+// defaultMaxMessageBytes is the default maximum size of request messages, like in gRPC libraries.
+const defaultMaxMessageBytes = 4 << 20
+
+// Handler serves the GraphQL service of graphql.proto. Execute runs queries and mutations with
+// [graphql.Schema.Exec], Subscribe runs all operations with [graphql.Schema.Do] and streams a response
+// per event of subscriptions. GraphQL errors are part of the responses, the gRPC status is only set for
+// malformed requests.
+type Handler struct {
+	Schema *graphql.Schema
+	// MaxMessageBytes is the maximum size of request messages. It defaults to 4 MB.
+	MaxMessageBytes int
+	// VariablesDecoder decodes the JSON variables of requests. It defaults to
+	// [graphql.JSONVariablesDecoder].
+	VariablesDecoder graphql.VariablesDecoder
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "gRPC requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/grpc" && !strings.HasPrefix(ct, "application/grpc+proto") && !strings.HasPrefix(ct, "application/grpc;") {
+		http.Error(w, "unsupported content type "+ct, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Add("Trailer", "Grpc-Status")
+	w.Header().Add("Trailer", "Grpc-Message")
+
+	ctx := r.Context()
+	if t := r.Header.Get("Grpc-Timeout"); t != "" {
+		d, err := parseTimeout(t)
+		if err != nil {
+			writeStatus(w, codeInvalidArgument, err.Error())
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	switch r.URL.Path {
+	case ExecutePath, SubscribePath:
+	default:
+		writeStatus(w, codeUnimplemented, "unknown method "+r.URL.Path)
+		return
+	}
+
+	max := h.MaxMessageBytes
+	if max <= 0 {
+		max = defaultMaxMessageBytes
+	}
+	msg, err := readMessage(r.Body, max)
+	switch err {
+	case nil:
+	case errMessageTooLarge:
+		writeStatus(w, codeResourceExhausted, err.Error())
+		return
+	case errCompressed:
+		writeStatus(w, codeUnimplemented, err.Error())
+		return
+	default:
+		writeStatus(w, codeInvalidArgument, "invalid request: "+err.Error())
+		return
+	}
+	req, err := unmarshalRequest(msg)
+	if err != nil {
+		writeStatus(w, codeInvalidArgument, "invalid request: "+err.Error())
+		return
+	}
+	vars, err := graphql.DecodeVariables(h.VariablesDecoder, req.variables)
+	if err != nil {
+		writeStatus(w, codeInvalidArgument, "invalid variables: "+err.Error())
+		return
+	}
+
+	if r.URL.Path == ExecutePath {
+		resp := h.Schema.Exec(ctx, req.query, req.operationName, vars)
+		if !writeResponse(w, resp) {
+			return
+		}
+		writeStatus(w, contextCode(ctx), "")
+		return
+	}
+
+	it, err := h.Schema.Do(ctx, graphql.Request{Query: req.query, OperationName: req.operationName, Variables: vars})
+	if err != nil {
+		writeStatus(w, codeUnknown, err.Error())
+		return
+	}
+	defer it.Close()
+	flusher, _ := w.(http.Flusher)
+	for {
+		resp, ok := it.Next()
+		if !ok {
+			break
+		}
+		if !writeResponse(w, resp) {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	writeStatus(w, contextCode(ctx), "")
+}
+
+// writeResponse writes resp as a Response message. It reports false after writing the status of a failure.
+func writeResponse(w http.ResponseWriter, resp *graphql.Response) bool {
+	msg, err := marshalResponse(resp)
+	if err != nil {
+		writeStatus(w, codeInternal, err.Error())
+		return false
+	}
+	if err := writeMessage(w, msg); err != nil {
+		writeStatus(w, codeUnknown, err.Error())
+		return false
+	}
+	return true
+}
+
+// writeStatus sets the status trailers of the call.
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set("Grpc-Message", encodeMessage(message))
+	}
+}
+
+// contextCode returns the status of a call whose context is ctx once its responses have been sent.
+func contextCode(ctx context.Context) int {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return codeDeadlineExceeded
+	case context.Canceled:
+		return codeCanceled
+	}
+	return codeOK
+}
+
+// parseTimeout parses the value of the grpc-timeout header, an integer of at most 8 digits followed by
+// a unit.
+func parseTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// encodeMessage percent-encodes the status message like gRPC requires.
+func encodeMessage(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c <= 0x7e && c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+// decodeMessage reverses encodeMessage. Invalid escapes are kept.
+func decodeMessage(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
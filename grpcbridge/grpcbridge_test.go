@@ -0,0 +1,126 @@
+package grpcbridge_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/grpcbridge"
+)
+
+type resolver struct{}
+
+func (r *resolver) Hello(args struct{ Name string }) string { return "Hello, " + args.Name + "!" }
+
+func (r *resolver) Count(ctx context.Context, args struct{ To int32 }) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		for i := int32(1); i <= args.To; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+const schemaString = `
+	schema {
+		query: Query
+		subscription: Subscription
+	}
+	type Query {
+		hello(name: String!): String!
+	}
+	type Subscription {
+		count(to: Int!): Int!
+	}
+`
+
+func newClient(t *testing.T) *grpcbridge.Client {
+	schema := graphql.MustParseSchema(schemaString, &resolver{})
+	srv := httptest.NewUnstartedServer(&grpcbridge.Handler{Schema: schema})
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return &grpcbridge.Client{URL: srv.URL, HTTPClient: srv.Client()}
+}
+
+func TestExecute(t *testing.T) {
+	c := newClient(t)
+	ctx := context.Background()
+
+	resp, err := c.Execute(ctx, graphql.Request{
+		Query:     `query Hello($name: String!) { hello(name: $name) }`,
+		Variables: map[string]interface{}{"name": "gRPC"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(resp.Data), `{"hello":"Hello, gRPC!"}`; got != want || len(resp.Errors) != 0 {
+		t.Errorf("got data %s and errors %v, want %s", got, resp.Errors, want)
+	}
+
+	resp, err = c.Execute(ctx, graphql.Request{Query: `{ goodbye }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != `Cannot query field "goodbye" on type "Query".` {
+		t.Errorf("got errors %v, want the validation error", resp.Errors)
+	}
+	if code := resp.Errors[0].Extensions["code"]; code != "GRAPHQL_VALIDATION_FAILED" {
+		t.Errorf("got code %v, want the code of the validation error", code)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	c := newClient(t)
+
+	s, err := c.Subscribe(context.Background(), graphql.Request{Query: `subscription { count(to: 3) }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var got []string
+	for {
+		resp, err := s.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(resp.Data))
+	}
+	want := []string{`{"count":1}`, `{"count":2}`, `{"count":3}`}
+	if len(got) != len(want) {
+		t.Fatalf("got responses %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got response %d %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	c := newClient(t)
+
+	_, err := c.Execute(context.Background(), graphql.Request{Query: `{ hello(name: "x") }`, Variables: map[string]interface{}{"f": func() {}}})
+	if err == nil {
+		t.Fatal("expected an error for unencodable variables")
+	}
+
+	c.URL += "/unknown"
+	_, err = c.Execute(context.Background(), graphql.Request{Query: `{ hello(name: "x") }`})
+	var statusErr *grpcbridge.StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != 12 || statusErr.Message != "unknown method /unknown"+grpcbridge.ExecutePath {
+		t.Errorf("got error %v, want the status UNIMPLEMENTED", err)
+	}
+}
@@ -0,0 +1,216 @@
+package grpcbridge
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Field numbers of the messages of graphql.proto.
+const (
+	requestQuery         = 1
+	requestOperationName = 2
+	requestVariables     = 3
+
+	responseData       = 1
+	responseErrors     = 2
+	responseExtensions = 3
+)
+
+// Protocol Buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errMalformed = errors.New("malformed protobuf message")
+
+// appendField appends the length-delimited field num with the value b. Empty values are omitted
+// like in proto3.
+func appendField(buf []byte, num int, b []byte) []byte {
+	if len(b) == 0 {
+		return buf
+	}
+	buf = appendVarint(buf, uint64(num)<<3|wireBytes)
+	buf = appendVarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendVarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// decodeFields calls fn with the number and the value of each length-delimited field of msg. Fields of
+// other wire types are skipped, as unknown fields have to be.
+func decodeFields(msg []byte, fn func(num int, b []byte)) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return errMalformed
+		}
+		msg = msg[n:]
+		num := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			if _, n = binary.Uvarint(msg); n <= 0 {
+				return errMalformed
+			}
+			msg = msg[n:]
+		case wireFixed64:
+			if len(msg) < 8 {
+				return errMalformed
+			}
+			msg = msg[8:]
+		case wireFixed32:
+			if len(msg) < 4 {
+				return errMalformed
+			}
+			msg = msg[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(msg)
+			if n <= 0 || l > uint64(len(msg)-n) {
+				return errMalformed
+			}
+			fn(num, msg[n:n+int(l)])
+			msg = msg[n+int(l):]
+		default:
+			return errMalformed
+		}
+	}
+	return nil
+}
+
+func marshalRequest(req graphql.Request) ([]byte, error) {
+	var buf []byte
+	buf = appendField(buf, requestQuery, []byte(req.Query))
+	buf = appendField(buf, requestOperationName, []byte(req.OperationName))
+	if req.Variables != nil {
+		vars, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendField(buf, requestVariables, vars)
+	}
+	return buf, nil
+}
+
+// request is a decoded Request message. The variables are decoded by the handler with the variables
+// decoder of the schema.
+type request struct {
+	query         string
+	operationName string
+	variables     []byte
+}
+
+func unmarshalRequest(msg []byte) (request, error) {
+	var req request
+	err := decodeFields(msg, func(num int, b []byte) {
+		switch num {
+		case requestQuery:
+			req.query = string(b)
+		case requestOperationName:
+			req.operationName = string(b)
+		case requestVariables:
+			req.variables = b
+		}
+	})
+	return req, err
+}
+
+func marshalResponse(resp *graphql.Response) ([]byte, error) {
+	var buf []byte
+	buf = appendField(buf, responseData, resp.Data)
+	if len(resp.Errors) != 0 {
+		errs, err := json.Marshal(resp.Errors)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendField(buf, responseErrors, errs)
+	}
+	if len(resp.Extensions) != 0 {
+		ext, err := json.Marshal(resp.Extensions)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendField(buf, responseExtensions, ext)
+	}
+	return buf, nil
+}
+
+func unmarshalResponse(msg []byte) (*graphql.Response, error) {
+	resp := &graphql.Response{}
+	var errs, ext []byte
+	err := decodeFields(msg, func(num int, b []byte) {
+		switch num {
+		case responseData:
+			resp.Data = append([]byte(nil), b...)
+		case responseErrors:
+			errs = b
+		case responseExtensions:
+			ext = b
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) != 0 {
+		if err := json.Unmarshal(errs, &resp.Errors); err != nil {
+			return nil, fmt.Errorf("invalid errors of response: %s", err)
+		}
+	}
+	if len(ext) != 0 {
+		if err := json.Unmarshal(ext, &resp.Extensions); err != nil {
+			return nil, fmt.Errorf("invalid extensions of response: %s", err)
+		}
+	}
+	return resp, nil
+}
+
+// writeMessage writes msg with the length prefix of gRPC messages.
+func writeMessage(w io.Writer, msg []byte) error {
+	var prefix [5]byte // uncompressed flag and big-endian length
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(msg)))
+	if _, err := w.Write(prefix[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(msg)
+	return err
+}
+
+// errMessageTooLarge is returned by readMessage for messages exceeding the maximum size.
+var errMessageTooLarge = errors.New("message exceeds the maximum size")
+
+// errCompressed is returned by readMessage for compressed messages, which are not supported.
+var errCompressed = errors.New("compressed messages are not supported")
+
+// readMessage reads a length-prefixed gRPC message of at most max bytes. It returns io.EOF if r ended
+// before the message.
+func readMessage(r io.Reader, max int) ([]byte, error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errMalformed
+		}
+		return nil, err
+	}
+	if prefix[0] != 0 {
+		return nil, errCompressed
+	}
+	n := binary.BigEndian.Uint32(prefix[1:])
+	if uint64(n) > uint64(max) {
+		return nil, errMessageTooLarge
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, errMalformed
+	}
+	return msg, nil
+}
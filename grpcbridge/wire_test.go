@@ -0,0 +1,72 @@
+package grpcbridge
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestUnmarshalRequest(t *testing.T) {
+	var msg []byte
+	msg = appendVarint(msg, 7<<3|wireVarint) // unknown fields are skipped
+	msg = appendVarint(msg, 300)
+	msg = appendField(msg, requestQuery, []byte("{ hello }"))
+	msg = append(msg, 8<<3|wireFixed32, 1, 2, 3, 4)
+	msg = appendField(msg, requestVariables, []byte(`{"a":1}`))
+
+	req, err := unmarshalRequest(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.query != "{ hello }" || req.operationName != "" || string(req.variables) != `{"a":1}` {
+		t.Errorf("got %+v", req)
+	}
+
+	for _, msg := range [][]byte{
+		{requestQuery<<3 | wireBytes},    // missing length
+		{requestQuery<<3 | wireBytes, 5}, // truncated value
+		{requestQuery<<3 | wireFixed64},  // truncated fixed64
+		{requestQuery<<3 | 3, 0},         // unsupported wire type
+	} {
+		if _, err := unmarshalRequest(msg); err != errMalformed {
+			t.Errorf("%v: got error %v, want %v", msg, err, errMalformed)
+		}
+	}
+}
+
+func TestReadMessage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	msg, err := readMessage(bytes.NewReader(data), 5)
+	if err != nil || string(msg) != "hello" {
+		t.Errorf("got %q, %v", msg, err)
+	}
+	if _, err := readMessage(bytes.NewReader(data), 4); err != errMessageTooLarge {
+		t.Errorf("got error %v, want %v", err, errMessageTooLarge)
+	}
+	if _, err := readMessage(bytes.NewReader(data[:7]), 5); err != errMalformed {
+		t.Errorf("got error %v, want %v", err, errMalformed)
+	}
+	if _, err := readMessage(bytes.NewReader(nil), 5); err != io.EOF {
+		t.Errorf("got error %v, want %v", err, io.EOF)
+	}
+	compressed := append([]byte{1}, data[1:]...)
+	if _, err := readMessage(bytes.NewReader(compressed), 5); err != errCompressed {
+		t.Errorf("got error %v, want %v", err, errCompressed)
+	}
+}
+
+func TestEncodeMessage(t *testing.T) {
+	const s = "invalid variables: 100% wrong\nünicode"
+	encoded := encodeMessage(s)
+	if encoded != "invalid variables: 100%25 wrong%0A%C3%BCnicode" {
+		t.Errorf("got %q", encoded)
+	}
+	if got := decodeMessage(encoded); got != s {
+		t.Errorf("got %q, want %q", got, s)
+	}
+}
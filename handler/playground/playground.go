@@ -0,0 +1,57 @@
+// Package playground serves the GraphiQL IDE, so development servers don't need to ship their own HTML
+// page:
+//
+//	http.Handle("/", &playground.Handler{Endpoint: "/query"})
+//	http.Handle("/query", &relay.Handler{Schema: schema})
+//
+// The page is embedded into the binary. The scripts and styles of GraphiQL are loaded from unpkg.com
+// with subresource integrity checks.
+package playground
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+// Handler serves the GraphiQL IDE for a GraphQL endpoint.
+type Handler struct {
+	// Title is the title of the page. It defaults to "GraphiQL".
+	Title string
+
+	// Endpoint is the URL which queries and mutations are sent to. It defaults to "/query".
+	Endpoint string
+
+	// SubscriptionEndpoint is the websocket URL which subscriptions are sent to with the
+	// graphql-ws protocol, e.g. "ws://localhost:8080/subscriptions". A path like "/query" is resolved
+	// against the URL of the page with the ws or wss scheme. If it is empty, subscriptions are sent to
+	// Endpoint.
+	SubscriptionEndpoint string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Title                string
+		Endpoint             string
+		SubscriptionEndpoint string
+	}{h.Title, h.Endpoint, h.SubscriptionEndpoint}
+	if data.Title == "" {
+		data.Title = "GraphiQL"
+	}
+	if data.Endpoint == "" {
+		data.Endpoint = "/query"
+	}
+	if data.SubscriptionEndpoint == "" {
+		data.SubscriptionEndpoint = data.Endpoint
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := page.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+//go:embed graphiql.html
+var pageHTML string
+
+var page = template.Must(template.New("graphiql").Parse(pageHTML))
@@ -0,0 +1,62 @@
+package playground_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/handler/playground"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler *playground.Handler
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "defaults",
+			handler: &playground.Handler{},
+			want: []string{
+				"<title>GraphiQL</title>",
+				`url: "/query",`,
+				`subscriptionUrl: new URL("/query", location.href.replace(/^http/, 'ws')).href,`,
+			},
+		},
+		{
+			name: "subscriptions",
+			handler: &playground.Handler{
+				Title:                "Star Wars </title>",
+				Endpoint:             "/graphql",
+				SubscriptionEndpoint: "ws://localhost:8080/subscriptions",
+			},
+			want: []string{
+				"<title>Star Wars &lt;/title&gt;</title>",
+				`url: "/graphql",`,
+				`subscriptionUrl: new URL("ws://localhost:8080/subscriptions", location.href.replace(/^http/, 'ws')).href,`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("got content type %q", got)
+			}
+			body := w.Body.String()
+			for _, s := range tt.want {
+				if !strings.Contains(body, s) {
+					t.Errorf("page does not contain %q:\n%s", s, body)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(body, s) {
+					t.Errorf("page contains %q", s)
+				}
+			}
+		})
+	}
+}
@@ -19,6 +19,10 @@ func (id *ID) UnmarshalGraphQL(input interface{}) error {
 		*id = ID(input)
 	case int32:
 		*id = ID(strconv.Itoa(int(input)))
+	case int64:
+		*id = ID(strconv.FormatInt(input, 10))
+	case uint64:
+		*id = ID(strconv.FormatUint(input, 10))
 	default:
 		err = fmt.Errorf("wrong type for ID: %T", input)
 	}
@@ -13,13 +13,24 @@ import (
 
 type syntaxError string
 
+// limitError is raised when a document exceeds the limits of the lexer.
+type limitError string
+
 type Lexer struct {
 	sc                    *scanner.Scanner
 	next                  rune
 	comment               bytes.Buffer
 	useStringDescriptions bool
+	maxTokens             int
+	tokens                int
+	maxDepth              int
+	depth                 int
 }
 
+// DefaultMaxDepth is the nesting depth limit of documents whose limits do not set one. It keeps the
+// recursive descent parser from overflowing the stack, which can not be recovered from.
+const DefaultMaxDepth = 1000
+
 type Ident struct {
 	Name string
 	Loc  errors.Location
@@ -31,19 +42,35 @@ func NewLexer(s string, useStringDescriptions bool) *Lexer {
 	}
 	sc.Init(strings.NewReader(s))
 
-	l := Lexer{sc: sc, useStringDescriptions: useStringDescriptions}
+	l := Lexer{sc: sc, useStringDescriptions: useStringDescriptions, maxDepth: DefaultMaxDepth}
 	l.sc.Error = l.CatchScannerError
 
 	return &l
 }
 
+// Limit makes the lexer abort with an error once more than maxTokens tokens are consumed or the
+// nesting depth exceeds maxDepth. Zero disables the token limit and sets the depth limit to
+// DefaultMaxDepth.
+func (l *Lexer) Limit(maxTokens, maxDepth int) {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	l.maxTokens = maxTokens
+	l.maxDepth = maxDepth
+}
+
 func (l *Lexer) CatchSyntaxError(f func()) (errRes *errors.QueryError) {
 	defer func() {
 		if err := recover(); err != nil {
-			if err, ok := err.(syntaxError); ok {
+			switch err := err.(type) {
+			case syntaxError:
 				errRes = errors.Errorf("syntax error: %s", err)
 				errRes.Locations = []errors.Location{l.Location()}
 				return
+			case limitError:
+				errRes = errors.Errorf("%s", err)
+				errRes.Locations = []errors.Location{l.Location()}
+				return
 			}
 			panic(err)
 		}
@@ -88,6 +115,26 @@ func (l *Lexer) ConsumeWhitespace() {
 
 		break
 	}
+
+	if l.next != scanner.EOF {
+		l.tokens++
+		if l.maxTokens > 0 && l.tokens > l.maxTokens {
+			panic(limitError(fmt.Sprintf("document exceeds the maximum of %d tokens", l.maxTokens)))
+		}
+	}
+}
+
+// Enter increases the nesting depth, e.g. when a selection set or a list value is opened.
+func (l *Lexer) Enter() {
+	l.depth++
+	if l.maxDepth > 0 && l.depth > l.maxDepth {
+		panic(limitError(fmt.Sprintf("document exceeds the maximum nesting depth of %d", l.maxDepth)))
+	}
+}
+
+// Leave decreases the nesting depth increased by Enter.
+func (l *Lexer) Leave() {
+	l.depth--
 }
 
 // consumeDescription optionally consumes a description based on the June 2018 graphql spec if any are present.
@@ -146,10 +193,13 @@ func (l *Lexer) ConsumeToken(expected rune) {
 	l.ConsumeWhitespace()
 }
 
+// DescComment returns the description of the next definition. With string descriptions, comments
+// are ignored. Otherwise the preceding comments are the description, or a string description if
+// there are no comments, so docstrings are not lost when the legacy mode is used.
 func (l *Lexer) DescComment() string {
 	comment := l.comment.String()
 	desc := l.consumeDescription()
-	if l.useStringDescriptions {
+	if l.useStringDescriptions || comment == "" {
 		return desc
 	}
 	return comment
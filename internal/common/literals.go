@@ -31,15 +31,18 @@ func ParseLiteral(l *Lexer, constOnly bool) ast.Value {
 		lit.Loc = loc
 		return lit
 	case '[':
+		l.Enter()
 		l.ConsumeToken('[')
 		var list []ast.Value
 		for l.Peek() != ']' {
 			list = append(list, ParseLiteral(l, constOnly))
 		}
 		l.ConsumeToken(']')
+		l.Leave()
 		return &ast.ListValue{Values: list, Loc: loc}
 
 	case '{':
+		l.Enter()
 		l.ConsumeToken('{')
 		var fields []*ast.ObjectField
 		for l.Peek() != '}' {
@@ -49,6 +52,7 @@ func ParseLiteral(l *Lexer, constOnly bool) ast.Value {
 			fields = append(fields, &ast.ObjectField{Name: name, Value: value})
 		}
 		l.ConsumeToken('}')
+		l.Leave()
 		return &ast.ObjectValue{Fields: fields, Loc: loc}
 
 	default:
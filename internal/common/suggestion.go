@@ -1,4 +1,4 @@
-package validation
+package common
 
 import (
 	"fmt"
@@ -7,7 +7,9 @@ import (
 	"strings"
 )
 
-func makeSuggestion(prefix string, options []string, input string) string {
+// MakeSuggestion returns a sentence suggesting the options which are close to input, e.g.
+// ` Did you mean "name" or "names"?`, or an empty string if there are none.
+func MakeSuggestion(prefix string, options []string, input string) string {
 	var selected []string
 	distances := make(map[string]int)
 	for _, opt := range options {
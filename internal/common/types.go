@@ -16,9 +16,11 @@ func ParseType(l *Lexer) ast.Type {
 
 func parseNullType(l *Lexer) ast.Type {
 	if l.Peek() == '[' {
+		l.Enter()
 		l.ConsumeToken('[')
 		ofType := ParseType(l)
 		l.ConsumeToken(']')
+		l.Leave()
 		return &ast.List{OfType: ofType}
 	}
 
@@ -0,0 +1,268 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Delegation is a standalone query which forwards a field to a remote schema.
+type Delegation struct {
+	Query     string
+	Variables map[string]interface{}
+	// ResponseKey is the key of the field in the data of the remote response.
+	ResponseKey string
+}
+
+type delegationContextKey struct{}
+
+type delegationContext struct {
+	r    *Request
+	f    *fieldToExec
+	root bool
+}
+
+func isDelegated(f *fieldToExec) bool {
+	_, ok := f.field.ValueExec.(*resolvable.Delegated)
+	return ok
+}
+
+// withDelegation stores the field f in ctx, so that its resolver can delegate it with Delegate.
+func withDelegation(ctx context.Context, r *Request, f *fieldToExec, root bool) context.Context {
+	return context.WithValue(ctx, delegationContextKey{}, &delegationContext{r: r, f: f, root: root})
+}
+
+// Delegate returns the query which selects the field remoteField of the root operation type of a
+// remote schema with the arguments, directives and selections of the field whose resolver is called
+// with ctx. The query defines the variables and fragments used by the field. Root mutation fields
+// are delegated as mutations, all other fields as queries. It returns false if the field does not
+// resolve to a delegated result.
+func Delegate(ctx context.Context, remoteField string) (*Delegation, bool) {
+	dc, ok := ctx.Value(delegationContextKey{}).(*delegationContext)
+	if !ok {
+		return nil, false
+	}
+	field := dc.f.field.Query
+	if remoteField == "" {
+		remoteField = field.Name.Name
+	}
+
+	p := &queryPrinter{doc: dc.r.Doc, vars: make(map[string]bool), fragments: make(map[string]bool)}
+	var body bytes.Buffer
+	p.buf = &body
+	p.buf.WriteString("{ ")
+	if field.Alias.Name != remoteField {
+		p.buf.WriteString(field.Alias.Name)
+		p.buf.WriteString(": ")
+	}
+	p.buf.WriteString(remoteField)
+	p.arguments(field.Arguments)
+	p.directives(field.Directives)
+	p.selectionSet(field.SelectionSet)
+	p.buf.WriteString(" }")
+	fragments := p.fragmentDefinitions()
+
+	var doc bytes.Buffer
+	if dc.root && dc.r.op != nil && dc.r.op.Type == query.Mutation {
+		doc.WriteString("mutation")
+	} else {
+		doc.WriteString("query")
+	}
+	d := &Delegation{ResponseKey: field.Alias.Name}
+	if len(p.vars) > 0 && dc.r.op != nil {
+		d.Variables = make(map[string]interface{})
+		var defs []string
+		for _, v := range dc.r.op.Vars {
+			if !p.vars[v.Name.Name] {
+				continue
+			}
+			def := "$" + v.Name.Name + ": " + typeString(v.Type)
+			if v.Default != nil {
+				def += " = " + v.Default.String()
+			}
+			defs = append(defs, def)
+			if value, ok := dc.r.Vars[v.Name.Name]; ok {
+				d.Variables[v.Name.Name] = value
+			}
+		}
+		doc.WriteString("(" + strings.Join(defs, ", ") + ")")
+	}
+	doc.WriteByte(' ')
+	doc.Write(body.Bytes())
+	doc.WriteString(fragments)
+	d.Query = doc.String()
+	return d, true
+}
+
+// queryPrinter prints selections in the query language and records the variables and fragments
+// which they use.
+type queryPrinter struct {
+	buf       *bytes.Buffer
+	doc       *ast.ExecutableDefinition
+	vars      map[string]bool
+	fragments map[string]bool
+}
+
+func (p *queryPrinter) selectionSet(sels ast.SelectionSet) {
+	if len(sels) == 0 {
+		return
+	}
+	p.buf.WriteString(" {")
+	for _, sel := range sels {
+		p.buf.WriteByte(' ')
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if sel.Alias.Name != sel.Name.Name {
+				p.buf.WriteString(sel.Alias.Name)
+				p.buf.WriteString(": ")
+			}
+			p.buf.WriteString(sel.Name.Name)
+			p.arguments(sel.Arguments)
+			p.directives(sel.Directives)
+			p.selectionSet(sel.SelectionSet)
+		case *ast.InlineFragment:
+			p.buf.WriteString("...")
+			if sel.On.Name != "" {
+				p.buf.WriteString(" on ")
+				p.buf.WriteString(sel.On.Name)
+			}
+			p.directives(sel.Directives)
+			p.selectionSet(sel.Selections)
+		case *ast.FragmentSpread:
+			p.buf.WriteString("...")
+			p.buf.WriteString(sel.Name.Name)
+			p.directives(sel.Directives)
+			p.fragments[sel.Name.Name] = true
+		}
+	}
+	p.buf.WriteString(" }")
+}
+
+// fragmentDefinitions prints the definitions of the used fragments in the order of the document,
+// including the fragments used by them.
+func (p *queryPrinter) fragmentDefinitions() string {
+	body := p.buf
+	defer func() { p.buf = body }()
+
+	var out bytes.Buffer
+	printed := make(map[string]bool)
+	for progress := true; progress; {
+		progress = false
+		for _, frag := range p.doc.Fragments {
+			name := frag.Name.Name
+			if !p.fragments[name] || printed[name] {
+				continue
+			}
+			printed[name] = true
+			progress = true
+			var def bytes.Buffer
+			p.buf = &def
+			def.WriteString(" fragment " + name + " on " + frag.On.Name)
+			p.directives(frag.Directives)
+			p.selectionSet(frag.Selections)
+			out.Write(def.Bytes())
+		}
+	}
+	return out.String()
+}
+
+func (p *queryPrinter) arguments(args ast.ArgumentList) {
+	if len(args) == 0 {
+		return
+	}
+	p.buf.WriteByte('(')
+	for i, arg := range args {
+		if i > 0 {
+			p.buf.WriteString(", ")
+		}
+		p.buf.WriteString(arg.Name.Name)
+		p.buf.WriteString(": ")
+		p.value(arg.Value)
+	}
+	p.buf.WriteByte(')')
+}
+
+func (p *queryPrinter) directives(ds ast.DirectiveList) {
+	for _, d := range ds {
+		p.buf.WriteString(" @")
+		p.buf.WriteString(d.Name.Name)
+		p.arguments(d.Arguments)
+	}
+}
+
+func (p *queryPrinter) value(v ast.Value) {
+	p.collectVariables(v)
+	p.buf.WriteString(v.String())
+}
+
+func (p *queryPrinter) collectVariables(v ast.Value) {
+	switch v := v.(type) {
+	case *ast.Variable:
+		p.vars[v.Name] = true
+	case *ast.ListValue:
+		for _, e := range v.Values {
+			p.collectVariables(e)
+		}
+	case *ast.ObjectValue:
+		for _, f := range v.Fields {
+			p.collectVariables(f.Value)
+		}
+	}
+}
+
+func typeString(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.TypeName:
+		return t.Name
+	case *ast.List:
+		return "[" + typeString(t.OfType) + "]"
+	case *ast.NonNull:
+		return typeString(t.OfType) + "!"
+	default:
+		return t.String()
+	}
+}
+
+// writeDelegated writes the data of the result of a delegated field into out and adds its errors.
+// The paths of the errors start with the response key of the field in the remote response, so they
+// are made relative to path.
+func (r *Request) writeDelegated(typ ast.Type, path *pathSegment, result reflect.Value, out *bytes.Buffer) {
+	var data []byte
+	var errs []*errors.QueryError
+	if result.IsValid() && !(result.Kind() == reflect.Ptr && result.IsNil()) {
+		data, errs = result.Interface().(resolvable.DelegatedResult).DelegatedResponse()
+	}
+	for _, err := range errs {
+		e := *err
+		e.Locations = nil
+		e.Path = path.toSlice()
+		if len(err.Path) > 1 {
+			for _, segment := range err.Path[1:] {
+				if i, ok := segment.(float64); ok {
+					segment = int(i)
+				}
+				e.Path = append(e.Path, segment)
+			}
+		}
+		r.AddError(&e)
+	}
+
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		if t, nonNull := unwrapNonNull(typ); nonNull && len(errs) == 0 {
+			err := errors.Errorf("graphql: got nil for non-null %q", t).SetCode(errors.CodeNonNullViolation)
+			err.Path = path.toSlice()
+			r.AddError(err)
+		}
+		out.WriteString("null")
+		r.grow(4)
+		return
+	}
+	out.Write(data)
+	r.grow(len(data))
+}
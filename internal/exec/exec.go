@@ -4,45 +4,178 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/cachecontrol"
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
 	"github.com/graph-gophers/graphql-go/internal/exec/selected"
 	"github.com/graph-gophers/graphql-go/internal/query"
 	"github.com/graph-gophers/graphql-go/log"
+	"github.com/graph-gophers/graphql-go/retry"
 	"github.com/graph-gophers/graphql-go/trace/tracer"
 )
 
 type Request struct {
 	selected.Request
-	Limiter                  chan struct{}
-	Tracer                   tracer.Tracer
+	Limiter chan struct{}
+	Tracer  tracer.TracerV2
+	// SkipTracer receives the selections excluded by @skip and @include, if it is not nil.
+	SkipTracer               tracer.SkipTracer
 	Logger                   log.Logger
 	PanicHandler             errors.PanicHandler
 	SubscribeResolverTimeout time.Duration
+	CacheControl             *cachecontrol.Collector
+	Profiler                 *Profiler
+	// DisableNullBubbling keeps the siblings of non-null fields which resolved to null
+	// instead of propagating the null to the nearest nullable ancestor.
+	DisableNullBubbling bool
+	Stats               *Stats
+	// RetryPolicy returns the policy for retrying the resolver of a field which failed. It may be nil.
+	RetryPolicy func(retry.FieldInfo) retry.Policy
+	// SortResponseKeys orders the fields of response objects by their keys instead of the order of
+	// their selection.
+	SortResponseKeys bool
+	// Marshal encodes the values of scalar fields. If it is nil, json.Marshal is used.
+	Marshal func(v interface{}) ([]byte, error)
+	// MaxResponseBytes aborts the execution once the serialized response exceeds the given number
+	// of bytes. It is disabled if it is 0.
+	MaxResponseBytes int
+	// DeduplicateFields resolves identical sibling fields of queries, which only differ in their
+	// aliases, with a single resolver call.
+	DeduplicateFields bool
+	// LenientEnums resolves values of nullable enum fields which are not defined by the enum to null
+	// and reports them as warnings instead of errors.
+	LenientEnums bool
+	// PlanMutation splits the root fields of a mutation into stages which are executed one after
+	// another, the fields of each stage concurrently. It returns the number of fields of each stage.
+	// If it is nil, the root fields of mutations are executed serially.
+	PlanMutation func(fields []*selected.SchemaField) []int
+
+	thunks   *thunkDispatcher
+	size     *responseSize
+	op       *ast.OperationDefinition
+	warnings []*errors.QueryError
 }
 
-func (r *Request) handlePanic(ctx context.Context) {
+// ErrResponseTooLarge is wrapped by the error returned for responses exceeding MaxResponseBytes.
+var ErrResponseTooLarge = goerrors.New("response too large")
+
+// responseSize counts the bytes of the response written so far.
+type responseSize struct {
+	n        int64
+	max      int64
+	exceeded int32
+	cancel   context.CancelFunc
+}
+
+// AddWarning reports err as a warning, which does not fail the field.
+func (r *Request) AddWarning(err *errors.QueryError) {
+	r.Mu.Lock()
+	r.warnings = append(r.warnings, err)
+	r.Mu.Unlock()
+}
+
+// Warnings returns the warnings reported during the execution.
+func (r *Request) Warnings() []*errors.QueryError {
+	r.Mu.Lock()
+	defer r.Mu.Unlock()
+	return r.warnings
+}
+
+// limitSize returns the context of an execution which is cancelled once the response exceeds
+// MaxResponseBytes, if it is set.
+func (r *Request) limitSize(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.MaxResponseBytes <= 0 {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.size = &responseSize{max: int64(r.MaxResponseBytes), cancel: cancel}
+	return ctx, cancel
+}
+
+// sizeError returns the error of a response which exceeded MaxResponseBytes, or nil.
+func (r *Request) sizeError() *errors.QueryError {
+	if r.size == nil || atomic.LoadInt32(&r.size.exceeded) == 0 {
+		return nil
+	}
+	err := errors.Errorf("response exceeds the maximum size of %d bytes", r.MaxResponseBytes)
+	err.Err = ErrResponseTooLarge
+	return err.SetCode(errors.CodeResponseTooLarge)
+}
+
+// grow adds n bytes to the size of the response and cancels the execution once it exceeds the
+// maximum size.
+func (r *Request) grow(n int) {
+	if r.size == nil {
+		return
+	}
+	if atomic.AddInt64(&r.size.n, int64(n)) > r.size.max && atomic.CompareAndSwapInt32(&r.size.exceeded, 0, 1) {
+		r.size.cancel()
+	}
+}
+
+// handlePanic recovers from a panic while executing the selections at path, which is nil for the
+// whole operation, and adds an error for it. The partial output written to out, if any, is replaced
+// by null.
+func (r *Request) handlePanic(ctx context.Context, path *pathSegment, out *bytes.Buffer) {
 	if value := recover(); value != nil {
-		r.Logger.LogPanic(ctx, value)
-		r.AddError(r.PanicHandler.MakePanicError(ctx, value))
+		r.AddError(r.panicError(ctx, value, path))
+		if out != nil {
+			out.Reset()
+			out.WriteString("null")
+		}
 	}
 }
 
-type extensionser interface {
-	Extensions() map[string]interface{}
+// panicError converts the value of a recovered panic of the selections at path into a query error
+// carrying the stack of the panic. It must be called by the deferred function recovering the panic.
+func (r *Request) panicError(ctx context.Context, value interface{}, path *pathSegment) *errors.QueryError {
+	stack := debug.Stack()
+	r.Logger.LogPanic(ctx, value)
+	var err *errors.QueryError
+	if h, ok := r.PanicHandler.(errors.StackPanicHandler); ok {
+		err = h.MakePanicErrorWithStack(ctx, value, stack)
+	} else {
+		err = r.PanicHandler.MakePanicError(ctx, value)
+	}
+	if err.Stack == nil {
+		err.Stack = stack
+	}
+	if path != nil {
+		err.Path = path.toSlice()
+	}
+	return err
+}
+
+// applyOperation selects the fields of op and reports the selections excluded by @skip and @include
+// to the SkipTracer, if any.
+func (r *Request) applyOperation(ctx context.Context, s *resolvable.Schema, op *ast.OperationDefinition) []selected.Selection {
+	r.RecordSkipped = r.SkipTracer != nil
+	sels := selected.ApplyOperation(&r.Request, s, op)
+	if r.SkipTracer != nil && len(r.Skipped) != 0 {
+		r.SkipTracer.TraceSkipped(ctx, r.Skipped)
+	}
+	return sels
 }
 
 func (r *Request) Execute(ctx context.Context, s *resolvable.Schema, op *ast.OperationDefinition) ([]byte, []*errors.QueryError) {
+	ctx, cancel := r.limitSize(ctx)
+	defer cancel()
+
+	r.op = op
 	var out bytes.Buffer
 	func() {
-		defer r.handlePanic(ctx)
-		sels := selected.ApplyOperation(&r.Request, s, op)
+		defer r.handlePanic(ctx, nil, nil)
+		sels := r.applyOperation(ctx, s, op)
 		var resolver reflect.Value
 		switch op.Type {
 		case query.Query:
@@ -55,6 +188,10 @@ func (r *Request) Execute(ctx context.Context, s *resolvable.Schema, op *ast.Ope
 			panic("unknown query operation")
 		}
 
+		if s.HasThunks {
+			r.thunks = newThunkDispatcher()
+		}
+
 		if errs := validateSelections(ctx, sels, nil, s); errs != nil {
 			r.Errs = errs
 			out.Write([]byte("null"))
@@ -64,8 +201,11 @@ func (r *Request) Execute(ctx context.Context, s *resolvable.Schema, op *ast.Ope
 		r.execSelections(ctx, sels, nil, s, resolver, &out, op.Type == query.Mutation)
 	}()
 
+	if err := r.sizeError(); err != nil {
+		return nil, []*errors.QueryError{err}
+	}
 	if err := ctx.Err(); err != nil {
-		return nil, []*errors.QueryError{errors.Errorf("%s", err)}
+		return nil, []*errors.QueryError{contextError(err)}
 	}
 
 	return out.Bytes(), r.Errs
@@ -81,12 +221,136 @@ type fieldToExec struct {
 	sels     []selected.Selection
 	resolver reflect.Value
 	out      *bytes.Buffer
+	// shared is set for fields whose resolver result is shared with identical sibling fields. The
+	// first of them resolves the field, the followers wait for its result.
+	shared   *sharedResult
+	follower bool
+}
+
+// sharedResult is the result of a resolver call shared by identical sibling fields.
+type sharedResult struct {
+	once   sync.Once
+	done   chan struct{}
+	result reflect.Value
+	err    *errors.QueryError
+}
+
+func (sr *sharedResult) set(result reflect.Value, err *errors.QueryError) {
+	sr.once.Do(func() {
+		sr.result, sr.err = result, err
+		close(sr.done)
+	})
 }
 
-func (f *fieldToExec) resolve(ctx context.Context) (output interface{}, err error) {
+// deduplicate marks the fields which only differ in their alias from a preceding sibling field, so
+// that their resolver is called once. Fields are identical if their arguments and query directives
+// are. Fields resolved by thunks are not deduplicated, as the thunks are only called once no other
+// resolver is waiting. The fields have to be executed in their order, so that leaders are resolved
+// before their followers wait for them.
+func deduplicate(fields []*fieldToExec, vars map[string]interface{}) {
+	first := make(map[string]*fieldToExec)
+	for _, f := range fields {
+		if f.field.FixedResult.IsValid() || f.field.Thunk {
+			continue
+		}
+		args, err := json.Marshal(f.field.Args)
+		if err != nil {
+			continue
+		}
+		directives, err := directivesKey(f.field.Query, vars)
+		if err != nil {
+			continue
+		}
+		key := f.field.TypeName + "." + f.field.Name + string(args) + directives
+		leader, ok := first[key]
+		if !ok {
+			first[key] = f
+			continue
+		}
+		if leader.shared == nil {
+			leader.shared = &sharedResult{done: make(chan struct{})}
+		}
+		f.shared, f.follower = leader.shared, true
+	}
+}
+
+// directivesKey returns the directives of the query field q with their argument values.
+func directivesKey(q *ast.Field, vars map[string]interface{}) (string, error) {
+	if q == nil || len(q.Directives) == 0 {
+		return "", nil
+	}
+	type directive struct {
+		Name string
+		Args map[string]interface{}
+	}
+	ds := make([]directive, len(q.Directives))
+	for i, d := range q.Directives {
+		ds[i].Name = d.Name.Name
+		ds[i].Args = make(map[string]interface{}, len(d.Arguments))
+		for _, arg := range d.Arguments {
+			ds[i].Args[arg.Name.Name] = arg.Value.Deserialize(vars)
+		}
+	}
+	b, err := json.Marshal(ds)
+	return string(b), err
+}
+
+func (f *fieldToExec) resolve(ctx context.Context, r *Request, root bool) (output interface{}, err error) {
+	if len(f.field.Directives) > 0 {
+		ctx = withField(ctx, r, f, root)
+	}
 	return f.field.Resolve(ctx, f.resolver)
 }
 
+// FieldInfo describes the field whose resolver is called.
+type FieldInfo struct {
+	TypeName  string
+	FieldName string
+	// Parent is the resolver of the object the field belongs to.
+	Parent interface{}
+	// Root is true for the fields of the root operation types.
+	Root bool
+	// Variables are the values of the variables of the request.
+	Variables map[string]interface{}
+}
+
+type fieldContextKey struct{}
+
+type fieldContext struct {
+	directives ast.DirectiveList
+	info       FieldInfo
+}
+
+// withField stores the directives and the description of the field f of the request r in ctx. It is
+// only done for fields with directives to avoid an allocation for every other field.
+func withField(ctx context.Context, r *Request, f *fieldToExec, root bool) context.Context {
+	fc := &fieldContext{
+		directives: f.field.Directives,
+		info:       FieldInfo{TypeName: f.field.TypeName, FieldName: f.field.Name, Root: root, Variables: r.Vars},
+	}
+	if f.resolver.IsValid() && f.resolver.CanInterface() {
+		fc.info.Parent = f.resolver.Interface()
+	}
+	return context.WithValue(ctx, fieldContextKey{}, fc)
+}
+
+// FieldDirectives returns the directives applied in the schema to the field whose resolver is called
+// with ctx.
+func FieldDirectives(ctx context.Context) ast.DirectiveList {
+	if fc, ok := ctx.Value(fieldContextKey{}).(*fieldContext); ok {
+		return fc.directives
+	}
+	return nil
+}
+
+// Field returns the field whose resolver is called with ctx, if the field has directives.
+func Field(ctx context.Context) (FieldInfo, bool) {
+	if fc, ok := ctx.Value(fieldContextKey{}).(*fieldContext); ok {
+		return fc.info, true
+	}
+	return FieldInfo{}, false
+}
+
 func resolvedToNull(b *bytes.Buffer) bool {
 	return bytes.Equal(b.Bytes(), []byte("null"))
 }
@@ -96,32 +360,46 @@ func (r *Request) execSelections(ctx context.Context, sels []selected.Selection,
 
 	var fields []*fieldToExec
 	collectFieldsToResolve(sels, s, resolver, &fields, make(map[string]*fieldToExec))
+	if r.DeduplicateFields && !serially {
+		deduplicate(fields, r.Vars)
+	}
 
-	if async {
-		var wg sync.WaitGroup
-		wg.Add(len(fields))
-		for _, f := range fields {
-			go func(f *fieldToExec) {
-				defer wg.Done()
-				defer r.handlePanic(ctx)
-				f.out = new(bytes.Buffer)
-				execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
-			}(f)
-		}
-		wg.Wait()
-	} else {
+	switch {
+	case async:
+		r.execFieldsConcurrently(ctx, s, fields, path)
+	case serially && r.PlanMutation != nil && len(fields) > 1:
+		for _, stage := range r.planMutation(fields) {
+			if len(stage) == 1 {
+				stage[0].out = new(bytes.Buffer)
+				execFieldSelection(ctx, r, s, stage[0], &pathSegment{path, stage[0].field.Alias}, true)
+				continue
+			}
+			r.execFieldsConcurrently(ctx, s, stage, path)
+		}
+	default:
 		for _, f := range fields {
 			f.out = new(bytes.Buffer)
 			execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
 		}
 	}
 
+	size := 2
+	for _, f := range fields {
+		size += len(f.field.Alias) + 4 // quotes, colon and comma
+	}
+	r.grow(size)
+
+	// the keys are sorted for the output only, as the fields of mutations are executed in their order
+	if r.SortResponseKeys {
+		fields = append([]*fieldToExec(nil), fields...)
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].field.Alias < fields[j].field.Alias })
+	}
 	out.WriteByte('{')
 	for i, f := range fields {
 		// If a non-nullable child resolved to null, an error was added to the
 		// "errors" list in the response, so this field resolves to null.
 		// If this field is non-nullable, the error is propagated to its parent.
-		if _, ok := f.field.Type.(*ast.NonNull); ok && resolvedToNull(f.out) {
+		if _, ok := f.field.Type.(*ast.NonNull); ok && resolvedToNull(f.out) && !r.DisableNullBubbling {
 			out.Reset()
 			out.Write([]byte("null"))
 			return
@@ -139,6 +417,45 @@ func (r *Request) execSelections(ctx context.Context, sels []selected.Selection,
 	out.WriteByte('}')
 }
 
+func (r *Request) execFieldsConcurrently(ctx context.Context, s *resolvable.Schema, fields []*fieldToExec, path *pathSegment) {
+	var wg sync.WaitGroup
+	wg.Add(len(fields))
+	for _, f := range fields {
+		r.thunks.start()
+		go func(f *fieldToExec) {
+			defer wg.Done()
+			defer r.thunks.stop()
+			f.out = new(bytes.Buffer)
+			defer r.handlePanic(ctx, &pathSegment{path, f.field.Alias}, f.out)
+			execFieldSelection(ctx, r, s, f, &pathSegment{path, f.field.Alias}, true)
+		}(f)
+	}
+	r.thunks.stop()
+	wg.Wait()
+	r.thunks.start()
+}
+
+// planMutation splits the root fields of a mutation into the stages returned by PlanMutation. Once the
+// sizes of the stages do not match the fields, the remaining fields are executed serially.
+func (r *Request) planMutation(fields []*fieldToExec) [][]*fieldToExec {
+	sels := make([]*selected.SchemaField, len(fields))
+	for i, f := range fields {
+		sels[i] = f.field
+	}
+	var stages [][]*fieldToExec
+	for _, n := range r.PlanMutation(sels) {
+		if n <= 0 || n > len(fields) {
+			break
+		}
+		stages = append(stages, fields[:n])
+		fields = fields[n:]
+	}
+	for _, f := range fields {
+		stages = append(stages, []*fieldToExec{f})
+	}
+	return stages
+}
+
 func collectFieldsToResolve(sels []selected.Selection, s *resolvable.Schema, resolver reflect.Value, fields *[]*fieldToExec, fieldByAlias map[string]*fieldToExec) {
 	for _, sel := range sels {
 		switch sel := sel.(type) {
@@ -196,6 +513,15 @@ func typeOf(tf *selected.TypenameField, resolver reflect.Value) string {
 }
 
 func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f *fieldToExec, path *pathSegment, applyLimiter bool) {
+	if f.follower {
+		execSharedFieldSelection(ctx, r, s, f, path)
+		return
+	}
+	if f.shared != nil {
+		// unblocks the followers if resolving the field panics
+		defer f.shared.set(reflect.Value{}, nil)
+	}
+
 	if applyLimiter {
 		r.Limiter <- struct{}{}
 	}
@@ -203,17 +529,37 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 	var result reflect.Value
 	var err *errors.QueryError
 
-	traceCtx, finish := r.Tracer.TraceField(ctx, f.field.TraceLabel, f.field.TypeName, f.field.Name, !f.field.Async, f.field.Args)
+	if r.Stats != nil {
+		r.Stats.field(path)
+	}
+	if r.CacheControl != nil {
+		r.CacheControl.AddFieldHint(f.field.CacheHint, path.parent == nil, cachecontrol.IsComposite(f.field.Type))
+	}
+
+	info := tracer.ResolverInfo{
+		Label:               f.field.TraceLabel,
+		TypeName:            f.field.TypeName,
+		FieldName:           f.field.Name,
+		Trivial:             !f.field.Async,
+		Args:                f.field.Args,
+		ArgumentDefinitions: f.field.Arguments,
+	}
+	if f.field.ArgsPacker != nil && f.field.PackedArgs.IsValid() {
+		info.CoercedArgs = f.field.ArgsPacker.Values(f.field.PackedArgs)
+	}
+	traceCtx, finish := r.Tracer.TraceResolver(ctx, info)
 	defer func() {
-		finish(err)
+		finish(err, f.out.Len())
 	}()
 
+	if isDelegated(f) {
+		ctx = withDelegation(ctx, r, f, path.parent == nil)
+	}
+
 	err = func() (err *errors.QueryError) {
 		defer func() {
 			if panicValue := recover(); panicValue != nil {
-				r.Logger.LogPanic(ctx, panicValue)
-				err = r.PanicHandler.MakePanicError(ctx, panicValue)
-				err.Path = path.toSlice()
+				err = r.panicError(ctx, panicValue, path)
 			}
 		}()
 
@@ -223,17 +569,49 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 		}
 
 		if err := traceCtx.Err(); err != nil {
-			return errors.Errorf("%s", err) // don't execute any more resolvers if context got cancelled
+			// don't execute any more resolvers if context got cancelled
+			err := contextError(err)
+			err.Path = path.toSlice()
+			return err
 		}
 
-		res, resolverErr := f.resolve(ctx)
+		if sem := f.field.Semaphore; sem != nil {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-traceCtx.Done():
+				err := contextError(traceCtx.Err())
+				err.Path = path.toSlice()
+				return err
+			}
+		}
+
+		if r.Stats != nil {
+			r.Stats.resolver()
+		}
+		start := time.Now()
+		res, resolverErr := f.resolve(ctx, r, path.parent == nil)
+		if resolverErr != nil && r.RetryPolicy != nil {
+			p := r.RetryPolicy(retry.FieldInfo{TypeName: f.field.TypeName, FieldName: f.field.Name})
+			if p.MaxAttempts > 1 {
+				res, resolverErr = p.Retry(ctx, resolverErr, func() (interface{}, error) {
+					return f.resolve(ctx, r, path.parent == nil)
+				})
+			}
+		}
+		if r.Profiler != nil {
+			r.Profiler.add(ProfileEntry{
+				Path:       path.toSlice(),
+				ParentType: f.field.TypeName,
+				FieldName:  f.field.Name,
+				Duration:   time.Since(start),
+			})
+		}
 		if resolverErr != nil {
 			err := errors.Errorf("%s", resolverErr)
 			err.Path = path.toSlice()
 			err.ResolverError = resolverErr
-			if ex, ok := resolverErr.(extensionser); ok {
-				err.Extensions = ex.Extensions()
-			}
+			err.Extensions = errors.Extensions(resolverErr)
 			return err
 		}
 
@@ -246,31 +624,78 @@ func execFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f
 		<-r.Limiter
 	}
 
+	if err == nil && f.field.Thunk {
+		result, err = r.callThunk(ctx, result, path)
+	}
+	if err != nil && err.Locations == nil {
+		err.Locations = fieldLocations(f)
+	}
+	if f.shared != nil {
+		f.shared.set(result, err)
+	}
+
 	if err != nil {
 		// If an error occurred while resolving a field, it should be treated as though the field
 		// returned null, and an error must be added to the "errors" list in the response.
 		r.AddError(err)
 		f.out.WriteString("null")
+		r.grow(4)
 		return
 	}
 
+	if isDelegated(f) {
+		r.writeDelegated(f.field.Type, path, result, f.out)
+		return
+	}
 	r.execSelectionSet(traceCtx, f.sels, f.field.Type, path, s, result, f.out)
 }
 
+// execSharedFieldSelection completes a field with the result of the resolver called for an identical
+// sibling field.
+func execSharedFieldSelection(ctx context.Context, r *Request, s *resolvable.Schema, f *fieldToExec, path *pathSegment) {
+	if r.Stats != nil {
+		r.Stats.field(path)
+	}
+	if r.CacheControl != nil {
+		r.CacheControl.AddFieldHint(f.field.CacheHint, path.parent == nil, cachecontrol.IsComposite(f.field.Type))
+	}
+
+	var err *errors.QueryError
+	select {
+	case <-f.shared.done:
+		if f.shared.err != nil {
+			shared := *f.shared.err
+			err = &shared
+		}
+	case <-ctx.Done():
+		err = contextError(ctx.Err())
+	}
+	if err != nil {
+		err.Path = path.toSlice()
+		err.Locations = fieldLocations(f)
+		r.AddError(err)
+		f.out.WriteString("null")
+		r.grow(4)
+		return
+	}
+	r.execSelectionSet(ctx, f.sels, f.field.Type, path, s, f.shared.result, f.out)
+}
+
 func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selection, typ ast.Type, path *pathSegment, s *resolvable.Schema, resolver reflect.Value, out *bytes.Buffer) {
 	t, nonNull := unwrapNonNull(typ)
 
 	// a reflect.Value of a nil interface will show up as an Invalid value
-	if resolver.Kind() == reflect.Invalid || ((resolver.Kind() == reflect.Ptr || resolver.Kind() == reflect.Interface) && resolver.IsNil()) {
+	if resolver.Kind() == reflect.Invalid || isNilResolver(t, resolver) {
 		// If a field of a non-null type resolves to null (either because the
 		// function to resolve the field returned null or because an error occurred),
 		// add an error to the "errors" list in the response.
 		if nonNull {
-			err := errors.Errorf("graphql: got nil for non-null %q", t)
+			err := errors.Errorf("graphql: got nil for non-null %q", t).SetCode(errors.CodeNonNullViolation)
 			err.Path = path.toSlice()
 			r.AddError(err)
 		}
 		out.WriteString("null")
+		r.grow(4)
 		return
 	}
 
@@ -292,35 +717,54 @@ func (r *Request) execSelectionSet(ctx context.Context, sels []selected.Selectio
 
 	case *ast.ScalarTypeDefinition:
 		v := resolver.Interface()
-		data, err := json.Marshal(v)
+		marshal := r.Marshal
+		if marshal == nil {
+			marshal = json.Marshal
+		}
+		data, err := marshal(v)
 		if err != nil {
 			panic(errors.Errorf("could not marshal %v: %s", v, err))
 		}
 		out.Write(data)
+		r.grow(len(data))
 
 	case *ast.EnumTypeDefinition:
-		var stringer fmt.Stringer = resolver
-		if s, ok := resolver.Interface().(fmt.Stringer); ok {
-			stringer = s
-		}
-		name := stringer.String()
+		var name string
 		var valid bool
-		for _, v := range t.EnumValuesDefinition {
-			if v.EnumValue == name {
-				valid = true
-				break
+		if names, ok := s.EnumNames[resolvable.EnumType{Name: t.Name, Type: resolver.Type()}]; ok {
+			name, valid = names[resolver.Interface()]
+			if !valid {
+				name = fmt.Sprint(resolver.Interface())
+			}
+		} else {
+			var stringer fmt.Stringer = resolver
+			if s, ok := resolver.Interface().(fmt.Stringer); ok {
+				stringer = s
+			}
+			name = stringer.String()
+			for _, v := range t.EnumValuesDefinition {
+				if v.EnumValue == name {
+					valid = true
+					break
+				}
 			}
 		}
 		if !valid {
-			err := errors.Errorf("Invalid value %s.\nExpected type %s, found %s.", name, t.Name, name)
+			err := errors.Errorf("Invalid value %s.\nExpected type %s, found %s.", name, t.Name, name).SetCode(errors.CodeInvalidEnumValue)
 			err.Path = path.toSlice()
-			r.AddError(err)
+			if r.LenientEnums && !nonNull {
+				r.AddWarning(err)
+			} else {
+				r.AddError(err)
+			}
 			out.WriteString("null")
+			r.grow(4)
 			return
 		}
 		out.WriteByte('"')
 		out.WriteString(name)
 		out.WriteByte('"')
+		r.grow(len(name) + 2)
 
 	default:
 		panic("unreachable")
@@ -334,18 +778,22 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 	if selected.HasAsyncSel(sels) {
 		// Limit the number of concurrent goroutines spawned as it can lead to large
 		// memory spikes for large lists.
+		// With thunks, the elements which wait for a slot are resolved in later batches.
 		concurrency := cap(r.Limiter)
 		sem := make(chan struct{}, concurrency)
 		for i := 0; i < l; i++ {
-			sem <- struct{}{}
+			r.thunks.acquire(sem)
+			r.thunks.start()
 			go func(i int) {
 				defer func() { <-sem }()
-				defer r.handlePanic(ctx)
-				r.execSelectionSet(ctx, sels, typ.OfType, &pathSegment{path, i}, s, resolver.Index(i), &entryouts[i])
+				defer r.thunks.stop()
+				elemPath := &pathSegment{path, i}
+				defer r.handlePanic(ctx, elemPath, &entryouts[i])
+				r.execSelectionSet(ctx, sels, typ.OfType, elemPath, s, resolver.Index(i), &entryouts[i])
 			}(i)
 		}
 		for i := 0; i < concurrency; i++ {
-			sem <- struct{}{}
+			r.thunks.acquire(sem)
 		}
 	} else {
 		for i := 0; i < l; i++ {
@@ -355,11 +803,12 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 
 	_, listOfNonNull := typ.OfType.(*ast.NonNull)
 
+	r.grow(l + 2) // brackets and commas
 	out.WriteByte('[')
 	for i, entryout := range entryouts {
 		// If the list wraps a non-null type and one of the list elements
 		// resolves to null, then the entire list resolves to null.
-		if listOfNonNull && resolvedToNull(&entryout) {
+		if listOfNonNull && resolvedToNull(&entryout) && !r.DisableNullBubbling {
 			out.Reset()
 			out.WriteString("null")
 			return
@@ -373,6 +822,21 @@ func (r *Request) execList(ctx context.Context, sels []selected.Selection, typ *
 	out.WriteByte(']')
 }
 
+// isNilResolver reports whether resolver represents null. Nil maps and slices only do so for
+// object types, lists resolved by nil slices are empty.
+func isNilResolver(t ast.Type, resolver reflect.Value) bool {
+	switch resolver.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return resolver.IsNil()
+	case reflect.Map, reflect.Slice:
+		switch t.(type) {
+		case *ast.ObjectTypeDefinition, *ast.InterfaceTypeDefinition, *ast.Union:
+			return resolver.IsNil()
+		}
+	}
+	return false
+}
+
 func unwrapNonNull(t ast.Type) (ast.Type, bool) {
 	if nn, ok := t.(*ast.NonNull); ok {
 		return nn.OfType, true
@@ -380,6 +844,16 @@ func unwrapNonNull(t ast.Type) (ast.Type, bool) {
 	return t, false
 }
 
+// fieldLocations returns the location of the field f in the query document for the errors of its
+// resolver. Fields which are not selected by the query document, e.g. __typename of type assertions,
+// have no location.
+func fieldLocations(f *fieldToExec) []errors.Location {
+	if f.field.Query == nil {
+		return nil
+	}
+	return []errors.Location{f.field.Query.Alias.Loc}
+}
+
 type pathSegment struct {
 	parent *pathSegment
 	value  interface{}
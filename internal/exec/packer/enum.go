@@ -0,0 +1,159 @@
+package packer
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/decode"
+)
+
+// maxProbedEnumValue bounds the integers which are probed for the names of enum types without a
+// Values method, e.g. types whose String method is generated by stringer.
+const maxProbedEnumValue = 1024
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// IsTypedEnum reports whether t is a Go type bound to GraphQL enums by the names returned by its
+// String method, i.e. an integer type like type Episode int or a type with a Values method. Types
+// implementing decode.Unmarshaler bind themselves.
+func IsTypedEnum(t reflect.Type) bool {
+	if _, ok := reflect.New(t).Interface().(decode.Unmarshaler); ok || !t.Implements(stringerType) {
+		return false
+	}
+	if _, ok := valuesMethod(t); ok {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// valuesMethod returns the method Values() []T of t.
+func valuesMethod(t reflect.Type) (reflect.Method, bool) {
+	m, ok := t.MethodByName("Values")
+	return m, ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0) == reflect.SliceOf(t)
+}
+
+// EnumValues returns the Go values of the typed enum t keyed by their names. The values are returned
+// by a method Values() []T of t or, for integer types, found by probing the integers from 0, skipping
+// those whose names are no GraphQL names, e.g. "Episode(7)" as returned by stringer, and names shared
+// by several integers, which are fallbacks for unknown values.
+func EnumValues(t reflect.Type) map[string]reflect.Value {
+	values := make(map[string]reflect.Value)
+	if m, ok := valuesMethod(t); ok {
+		list := m.Func.Call([]reflect.Value{reflect.Zero(t)})[0]
+		for i := 0; i < list.Len(); i++ {
+			v := list.Index(i)
+			values[v.Interface().(fmt.Stringer).String()] = v
+		}
+		return values
+	}
+
+	shared := make(map[string]bool)
+	probe := func(v reflect.Value) {
+		name := v.Interface().(fmt.Stringer).String()
+		if !isName(name) {
+			return
+		}
+		if _, ok := values[name]; ok {
+			shared[name] = true
+			return
+		}
+		values[name] = v
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for i := 0; i < maxProbedEnumValue && !reflect.Zero(t).OverflowInt(int64(i)); i++ {
+			v := reflect.New(t).Elem()
+			v.SetInt(int64(i))
+			probe(v)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		for i := 0; i < maxProbedEnumValue && !reflect.Zero(t).OverflowUint(uint64(i)); i++ {
+			v := reflect.New(t).Elem()
+			v.SetUint(uint64(i))
+			probe(v)
+		}
+	}
+	for name := range shared {
+		delete(values, name)
+	}
+	return values
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && (i == 0 || !('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckEnum returns an error unless the names of the values of the typed enum t are exactly the
+// values of the enum e.
+func CheckEnum(e *ast.EnumTypeDefinition, t reflect.Type, values map[string]reflect.Value) error {
+	var missing, extra []string
+	defined := make(map[string]bool, len(e.EnumValuesDefinition))
+	for _, v := range e.EnumValuesDefinition {
+		defined[v.EnumValue] = true
+		if _, ok := values[v.EnumValue]; !ok {
+			missing = append(missing, v.EnumValue)
+		}
+	}
+	for name := range values {
+		if !defined[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	switch {
+	case len(missing) > 0:
+		return fmt.Errorf("%s has no values for %s of enum %q", t, strings.Join(missing, ", "), e.Name)
+	case len(extra) > 0:
+		return fmt.Errorf("values %s of %s are not defined by enum %q", strings.Join(extra, ", "), t, e.Name)
+	}
+	return nil
+}
+
+type enumPacker struct {
+	enumType string
+	values   map[string]reflect.Value
+}
+
+func (p *enumPacker) Pack(value interface{}) (reflect.Value, error) {
+	name, ok := value.(string)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("could not unmarshal %#v (%T) into enum %s", value, value, p.enumType)
+	}
+	v, ok := p.values[name]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%q is not a value of enum %s", name, p.enumType)
+	}
+	return v, nil
+}
+
+// ConvertEnumValue converts the Go value of an enum value, as mapped by the EnumMapping option, into
+// the type t of an argument or a resolver result.
+func ConvertEnumValue(goValue interface{}, t reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(goValue)
+	if !v.IsValid() {
+		return reflect.Value{}, fmt.Errorf("enum value mapped to nil")
+	}
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	if v.Kind() != t.Kind() || !v.Type().ConvertibleTo(t) {
+		return reflect.Value{}, fmt.Errorf("can not use enum value %v (%s) as %s", goValue, v.Type(), t)
+	}
+	return v.Convert(t), nil
+}
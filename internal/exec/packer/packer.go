@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/graph-gophers/graphql-go/ast"
@@ -17,10 +18,28 @@ type packer interface {
 }
 
 type Builder struct {
+	// NameMapper maps the names of Go struct fields to the names of input values. If nil,
+	// names are matched case-insensitively ignoring underscores.
+	NameMapper func(goName string) string
+	// InputUnions are Go interfaces which input objects unpack into, keyed by the interface type.
+	InputUnions map[reflect.Type]*InputUnion
+	// EnumMappings are the Go values of enum values, keyed by the names of the enum and the value.
+	EnumMappings map[string]map[string]interface{}
+	// Normalizers are functions of the form func(T) T or func(T) (T, error) applied in order to the
+	// coerced values of input types, keyed by type name.
+	Normalizers map[string][]reflect.Value
+
 	packerMap     map[typePair]*packerMapEntry
 	structPackers []*StructPacker
 }
 
+// InputUnion describes a Go interface which input objects unpack into. The value of the
+// Discriminator input field selects the implementing type from Members.
+type InputUnion struct {
+	Discriminator string
+	Members       map[string]reflect.Type
+}
+
 type typePair struct {
 	graphQLType  ast.Type
 	resolverType reflect.Type
@@ -46,6 +65,10 @@ func (b *Builder) Finish() error {
 
 	for _, p := range b.structPackers {
 		p.defaultStruct = reflect.New(p.structType).Elem()
+		if p.template.IsValid() {
+			p.defaultStruct.Set(p.template)
+		}
+		p.allocEmbedded(p.defaultStruct)
 		for _, f := range p.fields {
 			if defaultVal := f.def; defaultVal != nil {
 				v, err := f.packer.Pack(defaultVal.Deserialize(nil))
@@ -55,6 +78,7 @@ func (b *Builder) Finish() error {
 				p.defaultStruct.FieldByIndex(f.index).Set(v)
 			}
 		}
+		p.defaultValue = reflect.ValueOf(p.defaultStruct.Interface())
 	}
 
 	return nil
@@ -95,6 +119,15 @@ func (b *Builder) makePacker(schemaType ast.Type, reflectType reflect.Type) (pac
 				valueType:  reflectType,
 				addPtr:     addPtr,
 			}, nil
+		} else if _, ok := b.InputUnions[reflectType]; ok {
+			elem, err := b.makeNonNullPacker(t, reflectType)
+			if err != nil {
+				return nil, err
+			}
+			return &nullPacker{
+				elemPacker: elem,
+				valueType:  reflectType,
+			}, nil
 		} else if isNullable(reflectType) {
 			elemType := reflectType
 			addPtr := false
@@ -116,6 +149,24 @@ func (b *Builder) makePacker(schemaType ast.Type, reflectType reflect.Type) (pac
 }
 
 func (b *Builder) makeNonNullPacker(schemaType ast.Type, reflectType reflect.Type) (packer, error) {
+	p, err := b.makeValuePacker(schemaType, reflectType)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := schemaType.(ast.NamedType)
+	if !ok {
+		return p, nil
+	}
+	for _, fn := range b.Normalizers[t.TypeName()] {
+		p, err = makeNormalizerPacker(p, fn, reflectType)
+		if err != nil {
+			return nil, fmt.Errorf("normalizer of %s: %s", t.TypeName(), err)
+		}
+	}
+	return p, nil
+}
+
+func (b *Builder) makeValuePacker(schemaType ast.Type, reflectType reflect.Type) (packer, error) {
 	if u, ok := reflect.New(reflectType).Interface().(decode.Unmarshaler); ok {
 		if !u.ImplementsGraphQLType(schemaType.String()) {
 			return nil, fmt.Errorf("can not unmarshal %s into %s", schemaType, reflectType)
@@ -124,6 +175,18 @@ func (b *Builder) makeNonNullPacker(schemaType ast.Type, reflectType reflect.Typ
 			ValueType: reflectType,
 		}, nil
 	}
+	if reflectType.Kind() == reflect.Ptr {
+		// allow non-null values to be bound to pointers of custom scalars, e.g. []*graphql.Time
+		if u, ok := reflect.New(reflectType.Elem()).Interface().(decode.Unmarshaler); ok {
+			if !u.ImplementsGraphQLType(schemaType.String()) {
+				return nil, fmt.Errorf("can not unmarshal %s into %s", schemaType, reflectType)
+			}
+			return &unmarshalerPacker{
+				ValueType: reflectType.Elem(),
+				addPtr:    true,
+			}, nil
+		}
+	}
 
 	switch t := schemaType.(type) {
 	case *ast.ScalarTypeDefinition:
@@ -132,14 +195,35 @@ func (b *Builder) makeNonNullPacker(schemaType ast.Type, reflectType reflect.Typ
 		}, nil
 
 	case *ast.EnumTypeDefinition:
+		if m, ok := b.EnumMappings[t.Name]; ok {
+			values := make(map[string]reflect.Value, len(m))
+			for name, goValue := range m {
+				v, err := ConvertEnumValue(goValue, reflectType)
+				if err != nil {
+					return nil, err
+				}
+				values[name] = v
+			}
+			return &enumPacker{enumType: t.Name, values: values}, nil
+		}
+		if IsTypedEnum(reflectType) {
+			values := EnumValues(reflectType)
+			if err := CheckEnum(t, reflectType, values); err != nil {
+				return nil, err
+			}
+			return &enumPacker{enumType: t.Name, values: values}, nil
+		}
 		if reflectType.Kind() != reflect.String {
-			return nil, fmt.Errorf("wrong type, expected %s", reflect.String)
+			return nil, fmt.Errorf("wrong type, expected %s or a type with a String method", reflect.String)
 		}
 		return &ValuePacker{
 			ValueType: reflectType,
 		}, nil
 
 	case *ast.InputObject:
+		if u, ok := b.InputUnions[reflectType]; ok {
+			return b.makeUnionPacker(t, u, reflectType)
+		}
 		e, err := b.MakeStructPacker(t.Values, reflectType)
 		if err != nil {
 			return nil, err
@@ -147,11 +231,12 @@ func (b *Builder) makeNonNullPacker(schemaType ast.Type, reflectType reflect.Typ
 		return e, nil
 
 	case *ast.List:
-		if reflectType.Kind() != reflect.Slice {
-			return nil, fmt.Errorf("expected slice, got %s", reflectType)
+		if k := reflectType.Kind(); k != reflect.Slice && k != reflect.Array {
+			return nil, fmt.Errorf("expected slice or array, got %s", reflectType)
 		}
 		p := &listPacker{
 			sliceType: reflectType,
+			elemType:  t.OfType,
 		}
 		if err := b.assignPacker(&p.elem, t.OfType, reflectType.Elem()); err != nil {
 			return nil, err
@@ -167,6 +252,16 @@ func (b *Builder) makeNonNullPacker(schemaType ast.Type, reflectType reflect.Typ
 }
 
 func (b *Builder) MakeStructPacker(values []*ast.InputValueDefinition, typ reflect.Type) (*StructPacker, error) {
+	return b.makeStructPacker(values, typ, false)
+}
+
+// makeStructPacker creates a packer for the struct typ. If partial is set, input values without a
+// matching struct field are ignored instead of reported.
+func (b *Builder) makeStructPacker(values []*ast.InputValueDefinition, typ reflect.Type, partial bool) (*StructPacker, error) {
+	if typ == rawValuesType {
+		return &StructPacker{raw: true, values: values}, nil
+	}
+
 	structType := typ
 	usePtr := false
 	if typ.Kind() == reflect.Ptr {
@@ -180,12 +275,21 @@ func (b *Builder) MakeStructPacker(values []*ast.InputValueDefinition, typ refle
 	var fields []*structPackerField
 	for _, v := range values {
 		name := v.Name.Name
-		fe := &structPackerField{name: name, def: v.Default}
+		fe := &structPackerField{name: name, def: v.Default, typ: v.Type}
 		fx := func(n string) bool {
+			if b.NameMapper != nil {
+				return b.NameMapper(n) == name
+			}
 			return strings.EqualFold(stripUnderscore(n), stripUnderscore(name))
 		}
 
-		sf, ok := structType.FieldByNameFunc(fx)
+		sf, ok, err := findInputField(structType, fx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: input field %q: %s", typ, name, err)
+		}
+		if !ok && partial {
+			continue
+		}
 		if !ok {
 			dv := reflect.TypeOf((*directives.ResolverInterceptor)(nil)).Elem()
 
@@ -220,26 +324,164 @@ func (b *Builder) MakeStructPacker(values []*ast.InputValueDefinition, typ refle
 		fields = append(fields, fe)
 	}
 
+	embedded, err := embeddedPointers(structType, fields)
+	if err != nil {
+		return nil, err
+	}
 	p := &StructPacker{
 		structType: structType,
 		usePtr:     usePtr,
 		fields:     fields,
+		embedded:   embedded,
 	}
 	b.structPackers = append(b.structPackers, p)
 	return p, nil
 }
 
+// rawValuesType receives the input values as they are decoded from the query and the variables
+// instead of a struct.
+var rawValuesType = reflect.TypeOf(map[string]interface{}(nil))
+
 type StructPacker struct {
+	// raw packs the input values defined by values into a map[string]interface{}.
+	raw           bool
+	values        []*ast.InputValueDefinition
 	structType    reflect.Type
 	usePtr        bool
+	template      reflect.Value
 	defaultStruct reflect.Value
-	fields        []*structPackerField
+	// defaultValue is the read-only copy of defaultStruct which is returned for empty values, so that
+	// packing the arguments of fields queried without arguments does not allocate.
+	defaultValue reflect.Value
+	fields       []*structPackerField
+	// embedded are the indices of the embedded struct pointers leading to fields, shorter ones first.
+	// They are allocated for every packed value.
+	embedded [][]int
+}
+
+// SetTemplate makes every packed value start out as a copy of v instead of the zero value.
+// It must be called before [Builder.Finish].
+func (p *StructPacker) SetTemplate(v reflect.Value) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	p.template = v
+}
+
+// allocEmbedded replaces the embedded struct pointers of v leading to fields with pointers to copies
+// of their values, so that packed values do not share them with each other or the defaults.
+func (p *StructPacker) allocEmbedded(v reflect.Value) {
+	for _, index := range p.embedded {
+		f := v.FieldByIndex(index)
+		ptr := reflect.New(f.Type().Elem())
+		if !f.IsNil() {
+			ptr.Elem().Set(f.Elem())
+		}
+		f.Set(ptr)
+	}
+}
+
+// findInputField returns the field of the struct t whose name matches an input value. Fields of
+// embedded structs are promoted like in Go: the match at the shallowest depth wins and several matches
+// at the same depth are reported as ambiguous instead of being ignored. Embedded structs themselves
+// are not matched.
+func findInputField(t reflect.Type, match func(name string) bool) (reflect.StructField, bool, error) {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	level := []embedded{{typ: t}}
+	visited := map[reflect.Type]bool{t: true}
+	for len(level) != 0 {
+		var matches []reflect.StructField
+		var next []embedded
+		for _, e := range level {
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				sf.Index = append(append([]int(nil), e.index...), i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if ft.Kind() == reflect.Struct {
+						if !visited[ft] {
+							visited[ft] = true
+							next = append(next, embedded{typ: ft, index: sf.Index})
+						}
+						continue
+					}
+				}
+				if match(sf.Name) {
+					matches = append(matches, sf)
+				}
+			}
+		}
+		switch len(matches) {
+		case 0:
+			level = next
+		case 1:
+			return matches[0], true, nil
+		default:
+			names := make([]string, len(matches))
+			for i, m := range matches {
+				names[i] = fieldPath(t, m.Index)
+			}
+			return reflect.StructField{}, false, fmt.Errorf("ambiguous fields %s", strings.Join(names, " and "))
+		}
+	}
+	return reflect.StructField{}, false, nil
+}
+
+// fieldPath returns the names of the fields leading to the field of t with the given index, e.g.
+// "PaginationArgs.First".
+func fieldPath(t reflect.Type, index []int) string {
+	var names []string
+	for _, i := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		f := t.Field(i)
+		names = append(names, f.Name)
+		t = f.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// embeddedPointers returns the indices of the embedded struct pointers of t leading to fields, shorter
+// ones first.
+func embeddedPointers(t reflect.Type, fields []*structPackerField) ([][]int, error) {
+	var indices [][]int
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		st := t
+		for i, fi := range f.index[:len(f.index)-1] {
+			sf := st.Field(fi)
+			st = sf.Type
+			if st.Kind() != reflect.Ptr {
+				continue
+			}
+			st = st.Elem()
+			key := fmt.Sprint(f.index[:i+1])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if sf.PkgPath != "" {
+				return nil, fmt.Errorf("%s: embedded field %s must be exported to be allocated", t, fieldPath(t, f.index[:i+1]))
+			}
+			indices = append(indices, f.index[:i+1:i+1])
+		}
+	}
+	sort.SliceStable(indices, func(i, j int) bool { return len(indices[i]) < len(indices[j]) })
+	return indices, nil
 }
 
 type structPackerField struct {
 	name   string
 	index  []int
 	def    ast.Value
+	typ    ast.Type
 	packer packer
 }
 
@@ -249,15 +491,23 @@ func (p *StructPacker) Pack(value interface{}) (reflect.Value, error) {
 	}
 
 	values := value.(map[string]interface{})
+	if p.raw {
+		return reflect.ValueOf(p.packRaw(values)), nil
+	}
+	if len(values) == 0 && !p.usePtr && len(p.embedded) == 0 {
+		return p.defaultValue, nil
+	}
 	v := reflect.New(p.structType)
-	v.Elem().Set(p.defaultStruct)
+	elem := v.Elem()
+	elem.Set(p.defaultStruct)
+	p.allocEmbedded(elem)
 	for _, f := range p.fields {
 		if value, ok := values[f.name]; ok {
 			packed, err := f.packer.Pack(value)
 			if err != nil {
-				return reflect.Value{}, err
+				return reflect.Value{}, wrapInputError(err, f.name, f.typ)
 			}
-			v.Elem().FieldByIndex(f.index).Set(packed)
+			elem.FieldByIndex(f.index).Set(packed)
 		}
 	}
 	if !p.usePtr {
@@ -266,28 +516,129 @@ func (p *StructPacker) Pack(value interface{}) (reflect.Value, error) {
 	return v, nil
 }
 
+// packRaw returns a copy of values with the defaults of the missing input values.
+func (p *StructPacker) packRaw(values map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(p.values))
+	for _, v := range p.values {
+		if value, ok := values[v.Name.Name]; ok {
+			m[v.Name.Name] = value
+		} else if v.Default != nil {
+			m[v.Name.Name] = v.Default.Deserialize(nil)
+		}
+	}
+	return m
+}
+
+// Values returns the values of the input fields of v, a value packed by p, keyed by their names.
+func (p *StructPacker) Values(v reflect.Value) map[string]interface{} {
+	if p.raw {
+		return v.Interface().(map[string]interface{})
+	}
+	if p.usePtr {
+		v = v.Elem()
+	}
+	values := make(map[string]interface{}, len(p.fields))
+	for _, f := range p.fields {
+		values[f.name] = v.FieldByIndex(f.index).Interface()
+	}
+	return values
+}
+
+func (b *Builder) makeUnionPacker(t *ast.InputObject, u *InputUnion, ifaceType reflect.Type) (packer, error) {
+	if t.Values.Get(u.Discriminator) == nil {
+		return nil, fmt.Errorf("input object %q does not define discriminator field %q", t.Name, u.Discriminator)
+	}
+
+	p := &unionPacker{
+		ifaceType:     ifaceType,
+		discriminator: u.Discriminator,
+		members:       make(map[string]*StructPacker, len(u.Members)),
+	}
+	for value, memberType := range u.Members {
+		if !memberType.Implements(ifaceType) {
+			return nil, fmt.Errorf("%s does not implement %s", memberType, ifaceType)
+		}
+		member, err := b.makeStructPacker(t.Values, memberType, true)
+		if err != nil {
+			return nil, err
+		}
+		p.members[value] = member
+	}
+	return p, nil
+}
+
+type unionPacker struct {
+	ifaceType     reflect.Type
+	discriminator string
+	members       map[string]*StructPacker
+}
+
+func (p *unionPacker) Pack(value interface{}) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, errors.Errorf("got null for non-null")
+	}
+
+	values := value.(map[string]interface{})
+	d, _ := values[p.discriminator].(string)
+	member, ok := p.members[d]
+	if !ok {
+		return reflect.Value{}, errors.Errorf("unknown %s %q", p.discriminator, d)
+	}
+	packed, err := member.Pack(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	v := reflect.New(p.ifaceType).Elem()
+	v.Set(packed)
+	return v, nil
+}
+
 type listPacker struct {
-	sliceType reflect.Type
+	sliceType reflect.Type // slice or array type
+	elemType  ast.Type
 	elem      packer
 }
 
 func (e *listPacker) Pack(value interface{}) (reflect.Value, error) {
 	list, ok := value.([]interface{})
 	if !ok {
-		list = []interface{}{value}
+		list = typedList(value)
 	}
 
-	v := reflect.MakeSlice(e.sliceType, len(list), len(list))
+	var v reflect.Value
+	if e.sliceType.Kind() == reflect.Array {
+		if len(list) != e.sliceType.Len() {
+			return reflect.Value{}, errors.Errorf("expected a list of %d elements, got %d", e.sliceType.Len(), len(list))
+		}
+		v = reflect.New(e.sliceType).Elem()
+	} else {
+		v = reflect.MakeSlice(e.sliceType, len(list), len(list))
+	}
 	for i := range list {
 		packed, err := e.elem.Pack(list[i])
 		if err != nil {
-			return reflect.Value{}, err
+			return reflect.Value{}, wrapInputError(err, i, e.elemType)
 		}
 		v.Index(i).Set(packed)
 	}
 	return v, nil
 }
 
+// typedList returns the elements of value if it is a slice or an array of another type than
+// []interface{}, e.g. []int64 as decoded by a graphql.VariablesDecoder, and value as a single
+// element otherwise, as the input coercion rules allow.
+func typedList(value interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list
+}
+
 type nullPacker struct {
 	elemPacker packer
 	valueType  reflect.Type
@@ -331,6 +682,7 @@ func (p *ValuePacker) Pack(value interface{}) (reflect.Value, error) {
 
 type unmarshalerPacker struct {
 	ValueType reflect.Type
+	addPtr    bool
 }
 
 func (p *unmarshalerPacker) Pack(value interface{}) (reflect.Value, error) {
@@ -342,36 +694,175 @@ func (p *unmarshalerPacker) Pack(value interface{}) (reflect.Value, error) {
 	if err := v.Interface().(decode.Unmarshaler).UnmarshalGraphQL(value); err != nil {
 		return reflect.Value{}, err
 	}
+	if p.addPtr {
+		return v, nil
+	}
 	return v.Elem(), nil
 }
 
+// normalizerPacker applies a normalizer to the values of another packer.
+type normalizerPacker struct {
+	packer   packer
+	fn       reflect.Value
+	hasError bool
+	// elem is set if fn normalizes the elements of pointers, e.g. of input objects bound to pointers.
+	elem bool
+	// convert is set if fn takes a different but convertible type, e.g. string for type Email string.
+	convert bool
+}
+
+// makeNormalizerPacker wraps p to apply fn to values of the Go type t. fn has to be a function of the
+// form func(T) T or func(T) (T, error), where T is t, its element type if t is a pointer, or a type of
+// the same kind convertible to t.
+func makeNormalizerPacker(p packer, fn reflect.Value, t reflect.Type) (packer, error) {
+	ft := fn.Type()
+	in := ft.In(0)
+	np := &normalizerPacker{packer: p, fn: fn, hasError: ft.NumOut() == 2}
+	switch {
+	case in == t:
+	case t.Kind() == reflect.Ptr && in == t.Elem():
+		np.elem = true
+	case in.Kind() == t.Kind() && in.Kind() != reflect.Struct && in.ConvertibleTo(t) && t.ConvertibleTo(in):
+		np.convert = true
+	default:
+		return nil, fmt.Errorf("can not normalize %s with %s", t, ft)
+	}
+	return np, nil
+}
+
+func (p *normalizerPacker) Pack(value interface{}) (reflect.Value, error) {
+	v, err := p.packer.Pack(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	in := v
+	switch {
+	case p.elem:
+		if v.IsNil() {
+			return v, nil
+		}
+		in = v.Elem()
+	case p.convert:
+		in = v.Convert(p.fn.Type().In(0))
+	}
+	out := p.fn.Call([]reflect.Value{in})
+	if p.hasError && !out[1].IsNil() {
+		return reflect.Value{}, out[1].Interface().(error)
+	}
+	switch {
+	case p.elem:
+		ptr := reflect.New(in.Type())
+		ptr.Elem().Set(out[0])
+		return ptr, nil
+	case p.convert:
+		return out[0].Convert(v.Type()), nil
+	}
+	return out[0], nil
+}
+
+// InputError is returned when an input value can not be coerced into its Go type.
+// It records where the value is located within the input and which GraphQL type was expected.
+type InputError struct {
+	// Path holds the names of the input fields and the indices of the list elements
+	// leading to the value, starting with the argument name.
+	Path         []interface{}
+	ExpectedType string
+	Err          error
+}
+
+// wrapInputError prepends segment to the path of err. Errors which are not yet an InputError are
+// converted into one with the expected type t.
+func wrapInputError(err error, segment interface{}, t ast.Type) *InputError {
+	ie, ok := err.(*InputError)
+	if !ok {
+		ie = &InputError{ExpectedType: t.String(), Err: err}
+	}
+	ie.Path = append([]interface{}{segment}, ie.Path...)
+	return ie
+}
+
+// PathString formats the path in JSON style, e.g. "filter.users[2].email".
+func (e *InputError) PathString() string {
+	var sb strings.Builder
+	for _, seg := range e.Path {
+		switch seg := seg.(type) {
+		case int:
+			fmt.Fprintf(&sb, "[%d]", seg)
+		default:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			fmt.Fprint(&sb, seg)
+		}
+	}
+	return sb.String()
+}
+
+func (e *InputError) Error() string {
+	msg := e.Err.Error()
+	if qe, ok := e.Err.(*errors.QueryError); ok {
+		msg = qe.Message
+	}
+	return fmt.Sprintf("%s (expected %s): %s", e.PathString(), e.ExpectedType, msg)
+}
+
+func (e *InputError) Unwrap() error {
+	return e.Err
+}
+
 func unmarshalInput(typ reflect.Type, input interface{}) (interface{}, error) {
 	if reflect.TypeOf(input) == typ {
 		return input, nil
 	}
 
+	coerced, err := coerceInput(typ, input)
+	if err != nil {
+		return nil, err
+	}
+	// convert into named types, e.g. type Count int32
+	if v := reflect.ValueOf(coerced); v.Type() != typ && v.Kind() == typ.Kind() {
+		return v.Convert(typ).Interface(), nil
+	}
+	return coerced, nil
+}
+
+func coerceInput(typ reflect.Type, input interface{}) (interface{}, error) {
+	if t := reflect.TypeOf(input); t.Kind() == typ.Kind() && t.ConvertibleTo(typ) {
+		return input, nil
+	}
+
+	// numbers may be of any Go type, e.g. int64 as decoded by a graphql.VariablesDecoder
+	in := reflect.ValueOf(input)
 	switch typ.Kind() {
 	case reflect.Int32:
-		switch input := input.(type) {
-		case int:
-			if input < math.MinInt32 || input > math.MaxInt32 {
+		switch in.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64:
+			if in.Int() < math.MinInt32 || in.Int() > math.MaxInt32 {
 				return nil, fmt.Errorf("not a 32-bit integer")
 			}
-			return int32(input), nil
-		case float64:
-			coerced := int32(input)
-			if input < math.MinInt32 || input > math.MaxInt32 || float64(coerced) != input {
+			return int32(in.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if in.Uint() > math.MaxInt32 {
+				return nil, fmt.Errorf("not a 32-bit integer")
+			}
+			return int32(in.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			f := in.Float()
+			coerced := int32(f)
+			if f < math.MinInt32 || f > math.MaxInt32 || float64(coerced) != f {
 				return nil, fmt.Errorf("not a 32-bit integer")
 			}
 			return coerced, nil
 		}
 
 	case reflect.Float64:
-		switch input := input.(type) {
-		case int32:
-			return float64(input), nil
-		case int:
-			return float64(input), nil
+		switch in.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(in.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(in.Uint()), nil
+		case reflect.Float32:
+			return in.Float(), nil
 		}
 
 	case reflect.String:
@@ -0,0 +1,172 @@
+package packer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec/packer"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+const benchmarkSchema = `
+	type Query {
+		hero: String!
+		characters(first: Int = 10, after: String, filter: Filter): [String!]!
+	}
+
+	input Filter {
+		name: String
+		episodes: [Episode!]
+	}
+
+	enum Episode { NEWHOPE EMPIRE JEDI }
+`
+
+type filter struct {
+	Name     *string
+	Episodes *[]string
+}
+
+type charactersArgs struct {
+	First  int32
+	After  *string
+	Filter *filter
+}
+
+func structPacker(b testing.TB, field string, typ reflect.Type) *packer.StructPacker {
+	s, err := schema.ParseSchema(benchmarkSchema, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	f := s.Types["Query"].(*ast.ObjectTypeDefinition).Fields.Get(field)
+	builder := packer.NewBuilder()
+	p, err := builder.MakeStructPacker(f.Arguments, typ)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := builder.Finish(); err != nil {
+		b.Fatal(err)
+	}
+	return p
+}
+
+func TestStructPackerDefaults(t *testing.T) {
+	p := structPacker(t, "characters", reflect.TypeOf(charactersArgs{}))
+	for _, args := range []map[string]interface{}{nil, {}, {"after": "Y3Vyc29y"}} {
+		v, err := p.Pack(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := v.Interface().(charactersArgs); got.First != 10 || got.Filter != nil {
+			t.Errorf("Pack(%v): got %+v, want the default of first", args, got)
+		}
+	}
+
+	p = structPacker(t, "characters", reflect.TypeOf(&charactersArgs{}))
+	v1, _ := p.Pack(map[string]interface{}(nil))
+	v2, _ := p.Pack(map[string]interface{}(nil))
+	if v1.Pointer() == v2.Pointer() {
+		t.Error("pointers to packed arguments are shared")
+	}
+}
+
+type PaginationArgs struct {
+	First int32
+	After *string
+}
+
+type Cursor struct {
+	After *string
+}
+
+type embeddedArgs struct {
+	*PaginationArgs
+	Filter *filter
+}
+
+type shadowingArgs struct {
+	PaginationArgs
+	Cursor
+	After  *string // shadows the promoted After fields
+	Filter *filter
+}
+
+type ambiguousArgs struct {
+	PaginationArgs
+	Cursor
+	Filter *filter
+}
+
+func TestStructPackerEmbedded(t *testing.T) {
+	p := structPacker(t, "characters", reflect.TypeOf(embeddedArgs{}))
+	v1, err := p.Pack(map[string]interface{}{"after": "Y3Vyc29y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := v1.Interface().(embeddedArgs)
+	if got.PaginationArgs == nil || got.First != 10 || got.After == nil || *got.After != "Y3Vyc29y" {
+		t.Errorf("got %+v, want the promoted fields set", got.PaginationArgs)
+	}
+	v2, _ := p.Pack(map[string]interface{}{})
+	if v2.Interface().(embeddedArgs).PaginationArgs == got.PaginationArgs {
+		t.Error("embedded pointers of packed arguments are shared")
+	}
+
+	p = structPacker(t, "characters", reflect.TypeOf(shadowingArgs{}))
+	v, err := p.Pack(map[string]interface{}{"after": "Y3Vyc29y", "first": int32(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.Interface().(shadowingArgs); got.After == nil || *got.After != "Y3Vyc29y" || got.First != 3 || got.PaginationArgs.After != nil || got.Cursor.After != nil {
+		t.Errorf("got %+v, want the shallowest field set", got)
+	}
+
+	s, err := schema.ParseSchema(benchmarkSchema, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := s.Types["Query"].(*ast.ObjectTypeDefinition).Fields.Get("characters")
+	_, err = packer.NewBuilder().MakeStructPacker(f.Arguments, reflect.TypeOf(ambiguousArgs{}))
+	want := `packer_test.ambiguousArgs: input field "after": ambiguous fields PaginationArgs.After and Cursor.After`
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
+func BenchmarkStructPacker(b *testing.B) {
+	for _, bb := range []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"NoArguments", nil},
+		{"Scalars", map[string]interface{}{"first": int32(5), "after": "Y3Vyc29y"}},
+		{"InputObject", map[string]interface{}{
+			"first":  int32(5),
+			"filter": map[string]interface{}{"name": "Luke", "episodes": []interface{}{"NEWHOPE", "JEDI"}},
+		}},
+	} {
+		b.Run(bb.name, func(b *testing.B) {
+			p := structPacker(b, "characters", reflect.TypeOf(charactersArgs{}))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Pack(bb.args); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStructPackerPointer(b *testing.B) {
+	p := structPacker(b, "characters", reflect.TypeOf(&charactersArgs{}))
+	args := map[string]interface{}{"first": int32(5)}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Pack(args); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package exec
+
+import (
+	"sync"
+	"time"
+)
+
+// ProfileEntry is the duration of a single resolver call.
+type ProfileEntry struct {
+	Path       []interface{} `json:"path"`
+	ParentType string        `json:"parentType"`
+	FieldName  string        `json:"fieldName"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// Profiler keeps track of the slowest resolver calls of a request. It is safe for concurrent use.
+type Profiler struct {
+	mu      sync.Mutex
+	size    int
+	entries []ProfileEntry // sorted by descending duration
+}
+
+// NewProfiler returns a profiler keeping track of the n slowest resolver calls.
+func NewProfiler(n int) *Profiler {
+	return &Profiler{size: n, entries: make([]ProfileEntry, 0, n)}
+}
+
+func (p *Profiler) add(e ProfileEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i := len(p.entries)
+	for i > 0 && p.entries[i-1].Duration < e.Duration {
+		i--
+	}
+	if i >= p.size {
+		return
+	}
+	if len(p.entries) < p.size {
+		p.entries = append(p.entries, ProfileEntry{})
+	}
+	copy(p.entries[i+1:], p.entries[i:])
+	p.entries[i] = e
+}
+
+// Slowest returns the slowest resolver calls, slowest first.
+func (p *Profiler) Slowest() []ProfileEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ProfileEntry(nil), p.entries...)
+}
@@ -0,0 +1,104 @@
+package resolvable
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/common"
+)
+
+// missingResolverHint describes the method expected to resolve f, followed by the close matches
+// among the exported methods and, if field resolvers are used, the exported struct fields of
+// resolverType.
+func (b *execBuilder) missingResolverHint(resolverType reflect.Type, f *ast.FieldDefinition) string {
+	goName := expectedGoName(f.Name)
+
+	var candidates []string
+	for i := 0; i < resolverType.NumMethod(); i++ {
+		candidates = append(candidates, resolverType.Method(i).Name)
+	}
+	if rt := unwrapPtr(resolverType); b.useFieldResolvers && rt.Kind() == reflect.Struct {
+		for i := 0; i < rt.NumField(); i++ {
+			if sf := rt.Field(i); sf.PkgPath == "" && !sf.Anonymous {
+				candidates = append(candidates, sf.Name)
+			}
+		}
+	}
+	hint := "; expected " + expectedSignature(goName, f)
+	if suggestion := common.MakeSuggestion("Did you mean", candidates, goName); suggestion != "" {
+		hint += "." + suggestion
+	}
+	return hint
+}
+
+// expectedGoName returns the Go identifier matching the schema name, e.g. "CreatedAt" for
+// "created_at".
+func expectedGoName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// expectedSignature describes a method resolving f, e.g.
+// `Hero(args struct{ Episode *string }) resolving "Character"`.
+func expectedSignature(goName string, f *ast.FieldDefinition) string {
+	var sb strings.Builder
+	sb.WriteString(goName + "(")
+	if len(f.Arguments) != 0 {
+		sb.WriteString("args struct{ ")
+		for i, arg := range f.Arguments {
+			if i > 0 {
+				sb.WriteString("; ")
+			}
+			sb.WriteString(expectedGoName(arg.Name.Name) + " " + goInputType(arg.Type, arg.Default != nil))
+		}
+		sb.WriteString(" }")
+	}
+	sb.WriteString(") resolving \"" + f.Type.String() + "\"")
+	return sb.String()
+}
+
+// goInputType returns the Go type an argument of type t is packed into. Arguments with a default
+// value are never null.
+func goInputType(t ast.Type, hasDefault bool) string {
+	prefix := "*"
+	if nn, ok := t.(*ast.NonNull); ok {
+		t, prefix = nn.OfType, ""
+	} else if hasDefault {
+		prefix = ""
+	}
+	switch t := t.(type) {
+	case *ast.List:
+		return prefix + "[]" + goInputType(t.OfType, false)
+	case *ast.ScalarTypeDefinition:
+		switch t.Name {
+		case "Int":
+			return prefix + "int32"
+		case "Float":
+			return prefix + "float64"
+		case "String":
+			return prefix + "string"
+		case "Boolean":
+			return prefix + "bool"
+		case "ID":
+			return prefix + "graphql.ID"
+		}
+		return prefix + t.Name
+	case *ast.EnumTypeDefinition:
+		return prefix + "string"
+	}
+	return prefix + t.String()
+}
@@ -2,13 +2,18 @@ package resolvable
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/cachecontrol"
 	"github.com/graph-gophers/graphql-go/decode"
 	"github.com/graph-gophers/graphql-go/directives"
+	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/internal/exec/packer"
 )
 
@@ -27,6 +32,17 @@ type Schema struct {
 	QueryResolver        reflect.Value
 	MutationResolver     reflect.Value
 	SubscriptionResolver reflect.Value
+	// HasThunks is true if a resolver of the schema returns a thunk.
+	HasThunks bool
+	// EnumNames map the Go values returned by resolvers to the names of the enum values mapped to
+	// them by the EnumMapping option.
+	EnumNames map[EnumType]map[interface{}]string
+}
+
+// EnumType identifies the Go type of the resolvers of a mapped enum.
+type EnumType struct {
+	Name string
+	Type reflect.Type
 }
 
 type Resolvable interface {
@@ -52,6 +68,27 @@ type Field struct {
 	Visitors    *FieldVisitors
 	ValueExec   Resolvable
 	TraceLabel  string
+	CacheHint   cachecontrol.Hint
+	// Resolver names the Go method or struct field resolving the field, e.g. "(*main.Resolver).Hero".
+	Resolver string
+	// Func is the function registered for the field with graphql.FieldFunc, if any. If FuncHasParent
+	// is set, it receives the resolver of the parent object as its first argument.
+	Func          reflect.Value
+	FuncHasParent bool
+	// Thunk is true if the resolver returns a func() T or func() (T, error) which is called to get
+	// the value of the field once no other resolver of the request is running any more.
+	Thunk bool
+	// EmbeddedIndex is the index sequence of the anonymous interface field which the resolver method
+	// is promoted from, if any, e.g. of Users in struct{ Users; Posts }.
+	EmbeddedIndex []int
+	// Semaphore limits the concurrent calls of the resolver together with the resolvers of the other
+	// fields of its concurrency group. It is nil if the field belongs to no group.
+	Semaphore chan struct{}
+	// Trivial overrides whether the field is trivial, i.e. resolved without starting a goroutine, as set
+	// by the graphql.TrivialFields and graphql.ExpensiveFields options or the trivial tag of a struct
+	// field. If it is nil, fields are trivial unless their resolver takes a context or arguments, returns
+	// an error or has directive visitors.
+	Trivial *bool
 }
 
 type FieldVisitors struct {
@@ -60,7 +97,48 @@ type FieldVisitors struct {
 }
 
 func (f *Field) UseMethodResolver() bool {
-	return f.MethodIndex != -1 || f.IsFieldFunc
+	return f.MethodIndex != -1 || f.IsFieldFunc || f.Func.IsValid()
+}
+
+// CheckEmbedded returns an error if the method resolving the field is promoted from an embedded
+// interface which is not set on resolver, since calling it would panic.
+func (f *Field) CheckEmbedded(resolver reflect.Value) error {
+	if f.EmbeddedIndex == nil {
+		return nil
+	}
+	v := resolver
+	for _, i := range f.EmbeddedIndex {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if v.IsNil() {
+		return fmt.Errorf("%s can not be called: embedded %s of %s is nil", f.Resolver, v.Type(), resolver.Type())
+	}
+	return nil
+}
+
+// Call calls the method or function resolving the field on resolver with the arguments in.
+func (f *Field) Call(resolver reflect.Value, in []reflect.Value) []reflect.Value {
+	switch {
+	case f.Func.IsValid():
+		if f.FuncHasParent {
+			in = append([]reflect.Value{resolver}, in...)
+		}
+		return f.Func.Call(in)
+	case f.IsFieldFunc: // resolver is a struct field of type func
+		res := resolver
+		if res.Kind() == reflect.Pointer {
+			res = resolver.Elem()
+		}
+		return res.FieldByIndex(f.FieldIndex).Call(in)
+	default:
+		return resolver.Method(f.MethodIndex).Call(in)
+	}
 }
 
 func (f *Field) Resolve(ctx context.Context, resolver reflect.Value, args interface{}) (output interface{}, err error) {
@@ -116,8 +194,11 @@ func (f *Field) resolve(ctx context.Context, resolver reflect.Value, args interf
 		return res.FieldByIndex(f.FieldIndex).Interface(), nil
 	}
 
+	if err := f.CheckEmbedded(resolver); err != nil {
+		return nil, err
+	}
+
 	var in []reflect.Value
-	var callOut []reflect.Value
 
 	if f.HasContext {
 		in = append(in, reflect.ValueOf(ctx))
@@ -127,15 +208,7 @@ func (f *Field) resolve(ctx context.Context, resolver reflect.Value, args interf
 		in = append(in, reflect.ValueOf(args))
 	}
 
-	if f.IsFieldFunc { // resolver is a struct field of type func
-		res := resolver
-		if res.Kind() == reflect.Pointer {
-			res = resolver.Elem()
-		}
-		callOut = res.FieldByIndex(f.FieldIndex).Call(in)
-	} else {
-		callOut = resolver.Method(f.MethodIndex).Call(in)
-	}
+	callOut := f.Call(resolver, in)
 	result := callOut[0]
 
 	if f.HasError && !callOut[1].IsNil() {
@@ -163,29 +236,150 @@ type List struct {
 
 type Scalar struct{}
 
-func (*Object) isResolvable() {}
-func (*List) isResolvable()   {}
-func (*Scalar) isResolvable() {}
+// Delegated is the exec of fields whose resolvers return a DelegatedResult. Their selections are
+// resolved by a remote schema, so the result is written into the response as is.
+type Delegated struct{}
+
+func (*Object) isResolvable()    {}
+func (*List) isResolvable()      {}
+func (*Scalar) isResolvable()    {}
+func (*Delegated) isResolvable() {}
+
+// DelegatedResult is implemented by the results of fields delegated to a remote schema, see
+// graphql.DelegatedResult.
+type DelegatedResult interface {
+	// DelegatedResponse returns the JSON value of the field and the errors of the remote response.
+	DelegatedResponse() (json.RawMessage, []*errors.QueryError)
+}
 
-func ApplyResolver(s *ast.Schema, resolver interface{}, dirs []directives.Directive, useFieldResolvers bool) (*Schema, error) {
+var delegatedResultType = reflect.TypeOf((*DelegatedResult)(nil)).Elem()
+
+// Options configure how resolvers are bound to the schema.
+type Options struct {
+	// Directives are the directive visitor implementations.
+	Directives []directives.Directive
+	// UseFieldResolvers enables struct fields as resolvers.
+	UseFieldResolvers bool
+	// StringScalars are custom scalars which may be resolved with Go strings.
+	StringScalars map[string]struct{}
+	// NameMapper maps Go identifiers to schema names. If nil, names are matched
+	// case-insensitively ignoring underscores.
+	NameMapper func(goName string) string
+	// Strict reports every schema field without a resolver and every resolver method which
+	// doesn't resolve a field at once.
+	Strict bool
+	// InputUnions are Go interfaces which input objects unpack into.
+	InputUnions map[reflect.Type]*packer.InputUnion
+	// TraceLabel formats the trace labels of fields. If nil, labels look like "GraphQL field: Query.hero".
+	TraceLabel func(typeName, fieldName string) string
+	// FieldFuncs are functions resolving fields, keyed by type and field name. They take precedence
+	// over methods and struct fields of the resolvers.
+	FieldFuncs map[string]map[string]interface{}
+	// InterfaceBases are resolvers keyed by interface name whose methods resolve the fields of the
+	// interface for implementing types whose resolvers lack them.
+	InterfaceBases map[string]interface{}
+	// ConcurrencyGroups are semaphores limiting the concurrent calls of the resolvers of fields,
+	// keyed by type and field name.
+	ConcurrencyGroups map[string]map[string]chan struct{}
+	// InputNormalizers are functions of the form func(T) T or func(T) (T, error) applied in order to
+	// the coerced values of input types, keyed by type name.
+	InputNormalizers map[string][]reflect.Value
+	// TrivialFields override whether fields are trivial, keyed by type and field name.
+	TrivialFields map[string]map[string]bool
+	// EnumMappings are the Go values of enum values, keyed by the names of the enum and the value.
+	// Every value of a mapped enum must be mapped.
+	EnumMappings map[string]map[string]interface{}
+}
+
+func ApplyResolver(s *ast.Schema, resolver interface{}, opts Options) (*Schema, error) {
 	if resolver == nil {
 		return &Schema{Meta: newMeta(s), Schema: *s}, nil
 	}
 
-	ds, err := applyDirectives(s, dirs)
+	if err := checkEnumMappings(s, opts.EnumMappings); err != nil {
+		return nil, err
+	}
+
+	ds, err := applyDirectives(s, opts.Directives)
 	if err != nil {
 		return nil, err
 	}
 
-	directivePackers, err := buildDirectivePackers(s, ds)
+	directivePackers, err := buildDirectivePackers(s, ds, opts.NameMapper)
 	if err != nil {
 		return nil, err
 	}
 
-	b := newBuilder(s, directivePackers, useFieldResolvers)
+	b := newBuilder(s, directivePackers, opts)
+	if opts.Strict {
+		b.strict = &strictReport{
+			usedMethods: make(map[reflect.Type]map[int]struct{}),
+			ignored:     map[string]struct{}{"GraphQLFieldMethods": {}, "ResolverContext": {}, "SubscriptionCompletion": {}},
+		}
+		rt := reflect.TypeOf(resolver)
+		for _, op := range [...]string{Query, Mutation, Subscription} {
+			if m, ok := rt.MethodByName(op); ok {
+				b.strict.use(rt, m.Index)
+			}
+		}
+	}
 
 	var query, mutation, subscription Resolvable
 
+	resolvers, err := rootResolvers(resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report every mismatch between the schema and the resolvers at once.
+	var errs errors.MultiError
+	if t, ok := s.RootOperationTypes["query"]; ok {
+		if err := b.assignExec(&query, t, reflect.TypeOf(resolvers[Query])); err != nil {
+			errs = appendErrs(errs, err, "")
+		}
+	}
+
+	if t, ok := s.RootOperationTypes["mutation"]; ok {
+		if err := b.assignExec(&mutation, t, reflect.TypeOf(resolvers[Mutation])); err != nil {
+			errs = appendErrs(errs, err, "")
+		}
+	}
+
+	if t, ok := s.RootOperationTypes["subscription"]; ok {
+		if err := b.assignExec(&subscription, t, reflect.TypeOf(resolvers[Subscription])); err != nil {
+			errs = appendErrs(errs, err, "")
+		}
+	}
+
+	if b.strict != nil {
+		b.strict.unusedMethods()
+		errs = append(errs, b.strict.errs...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := b.finish(); err != nil {
+		return nil, err
+	}
+
+	return &Schema{
+		Meta:                 newMeta(s),
+		Schema:               *s,
+		QueryResolver:        reflect.ValueOf(resolvers[Query]),
+		MutationResolver:     reflect.ValueOf(resolvers[Mutation]),
+		SubscriptionResolver: reflect.ValueOf(resolvers[Subscription]),
+		HasThunks:            b.hasThunks,
+		EnumNames:            b.enumNames,
+		Query:                query,
+		Mutation:             mutation,
+		Subscription:         subscription,
+	}, nil
+}
+
+// rootResolvers returns the resolvers of the query, mutation and subscription operations. They are
+// returned by the Query, Mutation and Subscription methods of the root resolver, if it has them.
+func rootResolvers(resolver interface{}) (map[string]interface{}, error) {
 	resolvers := map[string]interface{}{}
 
 	rv := reflect.ValueOf(resolver)
@@ -201,12 +395,14 @@ func ApplyResolver(s *ast.Schema, resolver interface{}, dirs []directives.Direct
 				return nil, fmt.Errorf("method %q of %v must have 1 return value, got %d", op, rv.Type(), mt.NumOut())
 			}
 			ot := mt.Out(0)
-			if ot.Kind() != reflect.Pointer && ot.Kind() != reflect.Interface {
-				return nil, fmt.Errorf("method %q of %v must return an interface or a pointer, got %+v", op, rv.Type(), ot)
+			switch ot.Kind() {
+			case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Struct:
+			default:
+				return nil, fmt.Errorf("method %q of %v must return an interface, a pointer, a struct, a map or a slice, got %+v", op, rv.Type(), ot)
 			}
 			out := m.Call(nil)
 			res := out[0]
-			if res.IsNil() {
+			if ot.Kind() != reflect.Struct && res.IsNil() {
 				return nil, fmt.Errorf("method %q of %v must return a non-nil result, got %v", op, rv.Type(), res)
 			}
 			switch res.Kind() {
@@ -215,7 +411,7 @@ func ApplyResolver(s *ast.Schema, resolver interface{}, dirs []directives.Direct
 			case reflect.Interface:
 				resolvers[op] = res.Elem().Interface()
 			default:
-				panic("ureachable")
+				resolvers[op] = res.Interface()
 			}
 		}
 		// If a method for the current operation is not defined in the root resolver,
@@ -224,45 +420,40 @@ func ApplyResolver(s *ast.Schema, resolver interface{}, dirs []directives.Direct
 			resolvers[op] = resolver
 		}
 	}
+	return resolvers, nil
+}
 
-	if t, ok := s.RootOperationTypes["query"]; ok {
-		if err := b.assignExec(&query, t, reflect.TypeOf(resolvers[Query])); err != nil {
-			return nil, err
-		}
+// WithRoot returns a copy of s which resolves operations with the root resolver instead of the one
+// s was built for. root must have the same type as that resolver, and so must the resolvers returned by
+// its Query, Mutation and Subscription methods.
+func (s *Schema) WithRoot(root interface{}) (*Schema, error) {
+	if root == nil {
+		return nil, fmt.Errorf("root resolver must not be nil")
 	}
-
-	if t, ok := s.RootOperationTypes["mutation"]; ok {
-		if err := b.assignExec(&mutation, t, reflect.TypeOf(resolvers[Mutation])); err != nil {
-			return nil, err
-		}
+	resolvers, err := rootResolvers(root)
+	if err != nil {
+		return nil, err
 	}
-
-	if t, ok := s.RootOperationTypes["subscription"]; ok {
-		if err := b.assignExec(&subscription, t, reflect.TypeOf(resolvers[Subscription])); err != nil {
-			return nil, err
+	res := *s
+	for op, rv := range map[string]*reflect.Value{
+		Query:        &res.QueryResolver,
+		Mutation:     &res.MutationResolver,
+		Subscription: &res.SubscriptionResolver,
+	} {
+		v := reflect.ValueOf(resolvers[op])
+		if !rv.IsValid() || v.Type() != rv.Type() {
+			return nil, fmt.Errorf("%s resolver of type %v does not match the resolver of the schema", strings.ToLower(op), v.Type())
 		}
+		*rv = v
 	}
-
-	if err := b.finish(); err != nil {
-		return nil, err
-	}
-
-	return &Schema{
-		Meta:                 newMeta(s),
-		Schema:               *s,
-		QueryResolver:        reflect.ValueOf(resolvers[Query]),
-		MutationResolver:     reflect.ValueOf(resolvers[Mutation]),
-		SubscriptionResolver: reflect.ValueOf(resolvers[Subscription]),
-		Query:                query,
-		Mutation:             mutation,
-		Subscription:         subscription,
-	}, nil
+	return &res, nil
 }
 
-func buildDirectivePackers(s *ast.Schema, visitors map[string]directives.Directive) (map[string]*packer.StructPacker, error) {
+func buildDirectivePackers(s *ast.Schema, visitors map[string]directives.Directive, nameMapper func(string) string) (map[string]*packer.StructPacker, error) {
 	// Directive packers need to use a dedicated builder which is ready ('finish()' called) while
 	// schema fields (and their argument packers) are still being built
 	builder := packer.NewBuilder()
+	builder.NameMapper = nameMapper
 
 	packers := map[string]*packer.StructPacker{}
 	for _, d := range s.Directives {
@@ -289,6 +480,8 @@ func buildDirectivePackers(s *ast.Schema, visitors map[string]directives.Directi
 		if err != nil {
 			return nil, err
 		}
+		// Preserve any configuration set on the registered implementation, e.g. callbacks.
+		p.SetTemplate(reflect.ValueOf(v))
 
 		packers[n] = p
 	}
@@ -328,6 +521,53 @@ type execBuilder struct {
 	directivePackers  map[string]*packer.StructPacker
 	packerBuilder     *packer.Builder
 	useFieldResolvers bool
+	stringScalars     map[string]struct{}
+	nameMapper        func(string) string
+	traceLabel        func(typeName, fieldName string) string
+	fieldFuncs        map[string]map[string]interface{}
+	interfaceBases    map[string]interface{}
+	concurrencyGroups map[string]map[string]chan struct{}
+	trivialFields     map[string]map[string]bool
+	enumMappings      map[string]map[string]interface{}
+	enumNames         map[EnumType]map[interface{}]string
+	hasThunks         bool
+	strict            *strictReport
+}
+
+// strictReport collects the resolver methods which don't resolve any field in strict mode.
+type strictReport struct {
+	errs        errors.MultiError
+	types       []reflect.Type
+	usedMethods map[reflect.Type]map[int]struct{}
+	ignored     map[string]struct{}
+}
+
+func (r *strictReport) use(t reflect.Type, methodIndex int) {
+	used, ok := r.usedMethods[t]
+	if !ok {
+		used = make(map[int]struct{})
+		r.usedMethods[t] = used
+		r.types = append(r.types, t)
+	}
+	if methodIndex != -1 {
+		used[methodIndex] = struct{}{}
+	}
+}
+
+// unusedMethods reports the exported methods of every resolver type which don't resolve any field.
+func (r *strictReport) unusedMethods() {
+	for _, t := range r.types {
+		for i := 0; i < t.NumMethod(); i++ {
+			if _, ok := r.usedMethods[t][i]; ok {
+				continue
+			}
+			name := t.Method(i).Name
+			if _, ok := r.ignored[name]; ok {
+				continue
+			}
+			r.errs = append(r.errs, fmt.Errorf("%s: method %q does not resolve any schema field", t, name))
+		}
+	}
 }
 
 type typePair struct {
@@ -340,16 +580,71 @@ type resMapEntry struct {
 	targets []*Resolvable
 }
 
-func newBuilder(s *ast.Schema, directives map[string]*packer.StructPacker, useFieldResolvers bool) *execBuilder {
+func newBuilder(s *ast.Schema, directives map[string]*packer.StructPacker, opts Options) *execBuilder {
+	pb := packer.NewBuilder()
+	pb.NameMapper = opts.NameMapper
+	pb.InputUnions = opts.InputUnions
+	pb.EnumMappings = opts.EnumMappings
+	pb.Normalizers = opts.InputNormalizers
 	return &execBuilder{
 		schema:            s,
 		resMap:            make(map[typePair]*resMapEntry),
 		directivePackers:  directives,
-		packerBuilder:     packer.NewBuilder(),
-		useFieldResolvers: useFieldResolvers,
+		packerBuilder:     pb,
+		useFieldResolvers: opts.UseFieldResolvers,
+		stringScalars:     opts.StringScalars,
+		nameMapper:        opts.NameMapper,
+		traceLabel:        opts.TraceLabel,
+		fieldFuncs:        opts.FieldFuncs,
+		interfaceBases:    opts.InterfaceBases,
+		concurrencyGroups: opts.ConcurrencyGroups,
+		trivialFields:     opts.TrivialFields,
+		enumMappings:      opts.EnumMappings,
+		enumNames:         make(map[EnumType]map[interface{}]string),
 	}
 }
 
+// checkEnumMappings returns an error unless the mappings map exactly the values of enums of s.
+func checkEnumMappings(s *ast.Schema, mappings map[string]map[string]interface{}) error {
+	for name, m := range mappings {
+		e, ok := s.Types[name].(*ast.EnumTypeDefinition)
+		if !ok {
+			return fmt.Errorf("enum mapping of %q: no such enum", name)
+		}
+		defined := make(map[string]bool, len(e.EnumValuesDefinition))
+		for _, v := range e.EnumValuesDefinition {
+			defined[v.EnumValue] = true
+			if _, ok := m[v.EnumValue]; !ok {
+				return fmt.Errorf("enum mapping of %q: value %q is not mapped", name, v.EnumValue)
+			}
+		}
+		mapped := make(map[interface{}]string, len(m))
+		for _, value := range sortedKeys(m) {
+			if !defined[value] {
+				return fmt.Errorf("enum mapping of %q: %q is not a value of the enum", name, value)
+			}
+			goValue := m[value]
+			if goValue == nil || !reflect.TypeOf(goValue).Comparable() {
+				continue
+			}
+			if other, ok := mapped[goValue]; ok {
+				return fmt.Errorf("enum mapping of %q: %q and %q are mapped to the same value %v", name, other, value, goValue)
+			}
+			mapped[goValue] = value
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func (b *execBuilder) finish() error {
 	for _, entry := range b.resMap {
 		for _, target := range entry.targets {
@@ -377,6 +672,10 @@ func (b *execBuilder) assignExec(target *Resolvable, t ast.Type, resolverType re
 }
 
 func (b *execBuilder) makeExec(t ast.Type, resolverType reflect.Type) (Resolvable, error) {
+	if resolverType.Implements(delegatedResultType) {
+		return &Delegated{}, nil
+	}
+
 	var nonNull bool
 	t, nonNull = unwrapNonNull(t)
 
@@ -400,9 +699,32 @@ func (b *execBuilder) makeExec(t ast.Type, resolverType reflect.Type) (Resolvabl
 
 	switch t := t.(type) {
 	case *ast.ScalarTypeDefinition:
-		return makeScalarExec(t, resolverType)
+		return b.makeScalarExec(t, resolverType)
 
 	case *ast.EnumTypeDefinition:
+		if m, ok := b.enumMappings[t.Name]; ok {
+			if !resolverType.Comparable() {
+				return nil, fmt.Errorf("%s can not be compared with the mapped values of enum %q", resolverType, t.Name)
+			}
+			names := make(map[interface{}]string, len(m))
+			for _, value := range sortedKeys(m) {
+				v, err := packer.ConvertEnumValue(m[value], resolverType)
+				if err != nil {
+					return nil, err
+				}
+				if other, ok := names[v.Interface()]; ok {
+					return nil, fmt.Errorf("enum mapping of %q: %q and %q are both mapped to %v as %s", t.Name, other, value, v, resolverType)
+				}
+				names[v.Interface()] = value
+			}
+			b.enumNames[EnumType{t.Name, resolverType}] = names
+			return &Scalar{}, nil
+		}
+		if packer.IsTypedEnum(resolverType) {
+			if err := packer.CheckEnum(t, resolverType, packer.EnumValues(resolverType)); err != nil {
+				return nil, err
+			}
+		}
 		return &Scalar{}, nil
 
 	case *ast.List:
@@ -420,7 +742,7 @@ func (b *execBuilder) makeExec(t ast.Type, resolverType reflect.Type) (Resolvabl
 	}
 }
 
-func makeScalarExec(t *ast.ScalarTypeDefinition, resolverType reflect.Type) (Resolvable, error) {
+func (b *execBuilder) makeScalarExec(t *ast.ScalarTypeDefinition, resolverType reflect.Type) (Resolvable, error) {
 	implementsType := false
 	switch r := reflect.New(resolverType).Interface().(type) {
 	case *int32:
@@ -434,6 +756,9 @@ func makeScalarExec(t *ast.ScalarTypeDefinition, resolverType reflect.Type) (Res
 	case decode.Unmarshaler:
 		implementsType = r.ImplementsGraphQLType(t.Name)
 	}
+	if _, ok := b.stringScalars[t.Name]; ok && resolverType.Kind() == reflect.String {
+		implementsType = true
+	}
 
 	if !implementsType {
 		return nil, fmt.Errorf("can not use %s as %s", resolverType, t.Name)
@@ -443,36 +768,83 @@ func makeScalarExec(t *ast.ScalarTypeDefinition, resolverType reflect.Type) (Res
 
 func (b *execBuilder) makeObjectExec(typeName string, fields ast.FieldsDefinition, possibleTypes []*ast.ObjectTypeDefinition,
 	interfaces []*ast.InterfaceTypeDefinition, nonNull bool, resolverType reflect.Type) (*Object, error) {
-	if !nonNull {
-		if resolverType.Kind() != reflect.Ptr && resolverType.Kind() != reflect.Interface {
-			return nil, fmt.Errorf("%s is not a pointer or interface", resolverType)
-		}
-	}
-
+	// Nullable types may be resolved by any type. Values of types which can not be nil, e.g. structs
+	// with value receivers, are never null.
 	methodHasReceiver := resolverType.Kind() != reflect.Interface
 
 	Fields := make(map[string]*Field)
 	rt := unwrapPtr(resolverType)
-	fieldsCount, fieldTagsCount := fieldCount(rt, map[string]int{}, map[string]int{})
+	fieldsCount, fieldTagsCount := fieldCount(rt, b.nameMapper, map[string]int{}, map[string]int{})
+	methodNames := fieldMethods(resolverType)
+	if b.strict != nil {
+		b.strict.use(resolverType, -1)
+	}
+	// Mismatches are collected, so that all of them are reported at once.
+	var errs errors.MultiError
 	for _, f := range fields {
+		if fn, ok := b.fieldFuncs[typeName][f.Name]; ok {
+			fe, err := b.makeFieldFuncExec(typeName, f, reflect.ValueOf(fn), resolverType)
+			if err != nil {
+				errs = appendErrs(errs, err, fmt.Sprintf("graphql.FieldFunc(%q, %q, %T)", typeName, f.Name, fn))
+				continue
+			}
+			Fields[f.Name] = fe
+			continue
+		}
+
 		var fieldIndex []int
-		methodIndex := findMethod(resolverType, f.Name)
+		methodIndex := findFieldMethod(resolverType, f.Name, b.nameMapper)
+		if name, ok := methodNames[f.Name]; ok {
+			m, ok := resolverType.MethodByName(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s does not resolve %q: method %q mapped to field %q does not exist", resolverType, typeName, name, f.Name))
+				continue
+			}
+			methodIndex = m.Index
+		}
 		if b.useFieldResolvers && methodIndex == -1 {
 			// If a resolver field is ambiguous thrown an error unless there is exactly one field with the given graphql
 			// reflect tag. In that case use the field with the reflect tag.
 			if fieldTagsCount[f.Name] > 1 {
-				return nil, fmt.Errorf("%s does not resolve %q: multiple fields have a graphql reflect tag %q", resolverType, typeName, f.Name)
-			} else if fieldsCount[strings.ToLower(stripUnderscore(f.Name))] > 1 && fieldTagsCount[f.Name] != 1 {
-				return nil, fmt.Errorf("%s does not resolve %q: ambiguous field %q", resolverType, typeName, f.Name)
+				errs = append(errs, fmt.Errorf("%s does not resolve %q: multiple fields have a graphql reflect tag %q", resolverType, typeName, f.Name))
+				continue
+			} else if fieldsCount[schemaNameKey(f.Name, b.nameMapper)] > 1 && fieldTagsCount[f.Name] != 1 {
+				errs = append(errs, fmt.Errorf("%s does not resolve %q: ambiguous field %q", resolverType, typeName, f.Name))
+				continue
+			}
+			if rt.Kind() == reflect.Struct {
+				fieldIndex = findField(rt, f.Name, b.nameMapper, []int{}, fieldTagsCount)
 			}
-			fieldIndex = findField(rt, f.Name, []int{}, fieldTagsCount)
 		}
 		if methodIndex == -1 && len(fieldIndex) == 0 {
+			// Typed resolvers resolve the fields without a method with the fields of their value.
+			if vi := typedValueIndex(rt); vi != nil {
+				if vt := rt.FieldByIndex(vi).Type; vt.Kind() == reflect.Struct {
+					if idx := findField(vt, f.Name, b.nameMapper, vi, map[string]int{}); len(idx) > len(vi) {
+						fieldIndex = idx
+					}
+				}
+			}
+		}
+		if methodIndex == -1 && len(fieldIndex) == 0 {
+			if fn, iface, ok := b.interfaceBaseMethod(typeName, f.Name, interfaces); ok {
+				fe, err := b.makeFieldFuncExec(typeName, f, fn, resolverType)
+				if err != nil {
+					errs = appendErrs(errs, err, fmt.Sprintf("graphql.InterfaceBase(%q, %T)", iface, b.interfaceBases[iface]))
+					continue
+				}
+				Fields[f.Name] = fe
+				continue
+			}
 			var hint string
-			if findMethod(reflect.PtrTo(resolverType), f.Name) != -1 {
+			if findFieldMethod(reflect.PtrTo(resolverType), f.Name, b.nameMapper) != -1 {
 				hint = " (hint: the method exists on the pointer type)"
 			}
-			return nil, fmt.Errorf("%s does not resolve %q: missing method for field %q%s", resolverType, typeName, f.Name, hint)
+			errs = append(errs, fmt.Errorf("%s does not resolve %q: missing method for field %q, searched %s%s%s", resolverType, typeName, f.Name, describeMethodSet(resolverType), b.missingResolverHint(resolverType, f), hint))
+			continue
+		}
+		if b.strict != nil {
+			b.strict.use(resolverType, methodIndex)
 		}
 
 		var m reflect.Method
@@ -483,14 +855,19 @@ func (b *execBuilder) makeObjectExec(typeName string, fields ast.FieldsDefinitio
 			sf = rt.FieldByIndex(fieldIndex)
 		}
 		fe, err := b.makeFieldExec(typeName, f, m, sf, methodIndex, fieldIndex, methodHasReceiver)
+		var resolverName string
+		if methodIndex != -1 {
+			resolverName = m.Name
+		} else {
+			resolverName = sf.Name
+		}
 		if err != nil {
-			var resolverName string
-			if methodIndex != -1 {
-				resolverName = m.Name
-			} else {
-				resolverName = sf.Name
-			}
-			return nil, fmt.Errorf("%s\n\tused by (%s).%s", err, resolverType, resolverName)
+			errs = appendErrs(errs, err, fmt.Sprintf("(%s).%s", resolverType, resolverName))
+			continue
+		}
+		fe.Resolver = fmt.Sprintf("(%s).%s", resolverType, resolverName)
+		if methodIndex != -1 {
+			fe.EmbeddedIndex = embeddedInterfaceIndex(resolverType, m.Name)
 		}
 		Fields[f.Name] = fe
 	}
@@ -503,7 +880,8 @@ func (b *execBuilder) makeObjectExec(typeName string, fields ast.FieldsDefinitio
 		for _, impl := range possibleTypes {
 			methodIndex := findMethod(resolverType, "To"+impl.Name)
 			if methodIndex == -1 {
-				return nil, fmt.Errorf("%s does not resolve %q: missing method %q to convert to %q", resolverType, typeName, "To"+impl.Name, impl.Name)
+				errs = append(errs, fmt.Errorf("%s does not resolve %q: missing method %q to convert to %q", resolverType, typeName, "To"+impl.Name, impl.Name))
+				continue
 			}
 			m := resolverType.Method(methodIndex)
 			expectedIn := 0
@@ -511,20 +889,29 @@ func (b *execBuilder) makeObjectExec(typeName string, fields ast.FieldsDefinitio
 				expectedIn = 1
 			}
 			if m.Type.NumIn() != expectedIn {
-				return nil, fmt.Errorf("%s does not resolve %q: method %q should't have any arguments", resolverType, typeName, "To"+impl.Name)
+				errs = append(errs, fmt.Errorf("%s does not resolve %q: method %q should't have any arguments", resolverType, typeName, "To"+impl.Name))
+				continue
 			}
 			if m.Type.NumOut() != 2 {
-				return nil, fmt.Errorf("%s does not resolve %q: method %q should return a value and a bool indicating success", resolverType, typeName, "To"+impl.Name)
+				errs = append(errs, fmt.Errorf("%s does not resolve %q: method %q should return a value and a bool indicating success", resolverType, typeName, "To"+impl.Name))
+				continue
+			}
+			if b.strict != nil {
+				b.strict.use(resolverType, methodIndex)
 			}
 			a := &TypeAssertion{
 				MethodIndex: methodIndex,
 			}
 			if err := b.assignExec(&a.TypeExec, impl, resolverType.Method(methodIndex).Type.Out(0)); err != nil {
-				return nil, err
+				errs = appendErrs(errs, err, fmt.Sprintf("(%s).%s", resolverType, m.Name))
+				continue
 			}
 			typeAssertions[impl.Name] = a
 		}
 	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 
 	ifaces := make(map[string]struct{})
 	for _, iface := range interfaces {
@@ -539,14 +926,83 @@ func (b *execBuilder) makeObjectExec(typeName string, fields ast.FieldsDefinitio
 	}, nil
 }
 
+// isThunk reports whether t is the type of a thunk, i.e. func() T or func() (T, error).
+func isThunk(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 0 {
+		return false
+	}
+	return t.NumOut() == 1 || t.NumOut() == 2 && t.Out(1) == errorType
+}
+
+// appendErrs appends err, or the errors of it if it is a MultiError, to errs. If usedBy is not empty,
+// it is added to every error to describe the chain of resolvers leading to the mismatch.
+func appendErrs(errs errors.MultiError, err error, usedBy string) errors.MultiError {
+	list, ok := err.(errors.MultiError)
+	if !ok {
+		list = errors.MultiError{err}
+	}
+	for _, e := range list {
+		if usedBy != "" {
+			e = fmt.Errorf("%s\n\tused by %s", e, usedBy)
+		}
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// interfaceBaseMethod returns the method of the base resolver of the interface typeName or of one of the
+// interfaces implemented by it which declares the field fieldName, if any, and the name of the interface.
+func (b *execBuilder) interfaceBaseMethod(typeName, fieldName string, interfaces []*ast.InterfaceTypeDefinition) (reflect.Value, string, bool) {
+	if len(b.interfaceBases) == 0 {
+		return reflect.Value{}, "", false
+	}
+	candidates := interfaces
+	if t, ok := b.schema.Types[typeName].(*ast.InterfaceTypeDefinition); ok {
+		candidates = append([]*ast.InterfaceTypeDefinition{t}, interfaces...)
+	}
+	for _, iface := range candidates {
+		base, ok := b.interfaceBases[iface.Name]
+		if !ok || iface.Fields.Get(fieldName) == nil {
+			continue
+		}
+		if i := findFieldMethod(reflect.TypeOf(base), fieldName, b.nameMapper); i != -1 {
+			return reflect.ValueOf(base).Method(i), iface.Name, true
+		}
+	}
+	return reflect.Value{}, "", false
+}
+
 var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
 
+// makeFieldFuncExec creates the exec of a field resolved by the function fn. Like a method expression,
+// fn may take the resolver of the parent object as its first argument.
+func (b *execBuilder) makeFieldFuncExec(typeName string, f *ast.FieldDefinition, fn reflect.Value, resolverType reflect.Type) (*Field, error) {
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		var got interface{}
+		if fn.IsValid() {
+			got = fn.Interface()
+		}
+		return nil, fmt.Errorf("expected a function, got %T", got)
+	}
+	t := fn.Type()
+	hasParent := t.NumIn() > 0 && t.In(0) != contextType && resolverType.AssignableTo(t.In(0))
+	fe, err := b.makeFieldExec(typeName, f, reflect.Method{Type: t, Func: fn}, reflect.StructField{}, -1, nil, hasParent)
+	if err != nil {
+		return nil, err
+	}
+	fe.Func = fn
+	fe.FuncHasParent = hasParent
+	fe.Resolver = fn.Type().String()
+	return fe, nil
+}
+
 func (b *execBuilder) makeFieldExec(typeName string, f *ast.FieldDefinition, m reflect.Method, sf reflect.StructField, methodIndex int, fieldIndex []int, methodHasReceiver bool) (*Field, error) {
 	var argsPacker *packer.StructPacker
 	var hasError bool
 	var hasContext bool
 	var isFieldFunc bool
+	isFunc := m.Func.IsValid() // function registered with graphql.FieldFunc
 
 	if methodIndex == -1 && len(fieldIndex) > 0 {
 		if sf.Type.Kind() == reflect.Func {
@@ -556,7 +1012,7 @@ func (b *execBuilder) makeFieldExec(typeName string, f *ast.FieldDefinition, m r
 		}
 	}
 	// Validate resolver method only when there is one
-	if methodIndex != -1 || isFieldFunc {
+	if methodIndex != -1 || isFieldFunc || isFunc {
 		in := make([]reflect.Type, m.Type.NumIn())
 		for i := range in {
 			in[i] = m.Type.In(i)
@@ -619,10 +1075,24 @@ func (b *execBuilder) makeFieldExec(typeName string, f *ast.FieldDefinition, m r
 		Visitors:        visitors,
 		HasError:        hasError,
 		TraceLabel:      fmt.Sprintf("GraphQL field: %s.%s", typeName, f.Name),
+		CacheHint:       cachecontrol.FieldHint(f),
+		Semaphore:       b.concurrencyGroups[typeName][f.Name],
+	}
+	if b.traceLabel != nil {
+		fe.TraceLabel = b.traceLabel(typeName, f.Name)
+	}
+	if trivial, ok := b.trivialFields[typeName][f.Name]; ok {
+		fe.Trivial = &trivial
+	} else if tag, ok := sf.Tag.Lookup("trivial"); ok && len(fieldIndex) > 0 {
+		trivial, err := strconv.ParseBool(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trivial tag %q, expected true or false", tag)
+		}
+		fe.Trivial = &trivial
 	}
 
 	var out reflect.Type
-	if methodIndex != -1 || isFieldFunc {
+	if methodIndex != -1 || isFieldFunc || isFunc {
 		out = m.Type.Out(0)
 		sub, ok := b.schema.RootOperationTypes["subscription"]
 		if ok && typeName == sub.TypeName() && out.Kind() == reflect.Chan {
@@ -631,6 +1101,11 @@ func (b *execBuilder) makeFieldExec(typeName string, f *ast.FieldDefinition, m r
 	} else {
 		out = sf.Type
 	}
+	if isThunk(out) {
+		fe.Thunk = true
+		b.hasThunks = true
+		out = out.Out(0)
+	}
 	if err := b.assignExec(&fe.ValueExec, f.Type, out); err != nil {
 		return nil, err
 	}
@@ -676,6 +1151,53 @@ func packDirectives(ds ast.DirectiveList, packers map[string]*packer.StructPacke
 	return &FieldVisitors{Interceptors: resolvers, Validators: validators}, nil
 }
 
+type fieldMethodser interface {
+	GraphQLFieldMethods() map[string]string
+}
+
+var fieldMethodserType = reflect.TypeOf((*fieldMethodser)(nil)).Elem()
+
+// fieldMethods returns the GraphQL field to Go method name mapping of resolvers which implement
+// GraphQLFieldMethods. The method is called on the zero value of t.
+func fieldMethods(t reflect.Type) map[string]string {
+	if t.Kind() == reflect.Interface || !t.Implements(fieldMethodserType) {
+		return nil
+	}
+	v := reflect.Zero(t)
+	if t.Kind() == reflect.Ptr {
+		v = reflect.New(t.Elem())
+	}
+	return v.Interface().(fieldMethodser).GraphQLFieldMethods()
+}
+
+// embeddedInterfaceIndex returns the index sequence of the anonymous interface field of t, or of
+// the structs embedded in it, which the method name is promoted from, or nil if there is none.
+func embeddedInterfaceIndex(t reflect.Type, name string) []int {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Interface {
+			if _, ok := f.Type.MethodByName(name); ok {
+				return []int{i}
+			}
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() != reflect.Interface {
+			if idx := embeddedInterfaceIndex(f.Type, name); idx != nil {
+				return append([]int{i}, idx...)
+			}
+		}
+	}
+	return nil
+}
+
 func findMethod(t reflect.Type, name string) int {
 	for i := 0; i < t.NumMethod(); i++ {
 		if strings.EqualFold(stripUnderscore(name), stripUnderscore(t.Method(i).Name)) {
@@ -685,12 +1207,71 @@ func findMethod(t reflect.Type, name string) int {
 	return -1
 }
 
-func findField(t reflect.Type, name string, index []int, matchingTagsCount map[string]int) []int {
+// findFieldMethod returns the index of the method of t which resolves the schema field name.
+func findFieldMethod(t reflect.Type, name string, nameMapper func(string) string) int {
+	if nameMapper == nil {
+		return findMethod(t, name)
+	}
+	for i := 0; i < t.NumMethod(); i++ {
+		if nameMapper(t.Method(i).Name) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// goNameKey and schemaNameKey return the keys under which Go identifiers and schema names match.
+func goNameKey(goName string, nameMapper func(string) string) string {
+	if nameMapper != nil {
+		return nameMapper(goName)
+	}
+	return strings.ToLower(stripUnderscore(goName))
+}
+
+func schemaNameKey(name string, nameMapper func(string) string) string {
+	if nameMapper != nil {
+		return name
+	}
+	return strings.ToLower(stripUnderscore(name))
+}
+
+// TypedValue marks struct types such as graphql.TypedResolver. The fields of their Value field resolve
+// the schema fields which have no resolver method.
+type TypedValue struct{}
+
+var typedValueType = reflect.TypeOf(TypedValue{})
+
+// typedValueIndex returns the index of the Value field of the typed resolver which t is or embeds, or
+// nil if there is none.
+func typedValueIndex(t reflect.Type) []int {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Type == typedValueType {
+			if f, ok := t.FieldByName("Value"); ok && len(f.Index) == 1 {
+				return f.Index
+			}
+			return nil
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if idx := typedValueIndex(f.Type); idx != nil {
+				return append([]int{i}, idx...)
+			}
+		}
+	}
+	return nil
+}
+
+func findField(t reflect.Type, name string, nameMapper func(string) string, index []int, matchingTagsCount map[string]int) []int {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 
 		if field.Type.Kind() == reflect.Struct && field.Anonymous {
-			newIndex := findField(field.Type, name, []int{i}, matchingTagsCount)
+			newIndex := findField(field.Type, name, nameMapper, []int{i}, matchingTagsCount)
 			if len(newIndex) > 1 {
 				return append(index, newIndex...)
 			}
@@ -708,7 +1289,11 @@ func findField(t reflect.Type, name string, index []int, matchingTagsCount map[s
 			continue
 		}
 
-		if strings.EqualFold(stripUnderscore(name), stripUnderscore(field.Name)) {
+		if nameMapper != nil {
+			if nameMapper(field.Name) == name {
+				return append(index, i)
+			}
+		} else if strings.EqualFold(stripUnderscore(name), stripUnderscore(field.Name)) {
 			return append(index, i)
 		}
 	}
@@ -718,7 +1303,7 @@ func findField(t reflect.Type, name string, index []int, matchingTagsCount map[s
 
 // fieldCount helps resolve ambiguity when more than one embedded struct contains fields with the same name.
 // or when a field has a `graphql` reflect tag with the same name as some other field causing name collision.
-func fieldCount(t reflect.Type, count, tagsCount map[string]int) (map[string]int, map[string]int) {
+func fieldCount(t reflect.Type, nameMapper func(string) string, count, tagsCount map[string]int) (map[string]int, map[string]int) {
 	if t.Kind() != reflect.Struct {
 		return nil, nil
 	}
@@ -730,11 +1315,11 @@ func fieldCount(t reflect.Type, count, tagsCount map[string]int) (map[string]int
 		if gt, hasTag = field.Tag.Lookup("graphql"); hasTag && gt != "" {
 			fieldName = gt
 		} else {
-			fieldName = strings.ToLower(stripUnderscore(field.Name))
+			fieldName = goNameKey(field.Name, nameMapper)
 		}
 
 		if field.Type.Kind() == reflect.Struct && field.Anonymous {
-			count, tagsCount = fieldCount(field.Type, count, tagsCount)
+			count, tagsCount = fieldCount(field.Type, nameMapper, count, tagsCount)
 		} else {
 			if _, ok := count[fieldName]; !ok {
 				count[fieldName] = 0
@@ -764,6 +1349,18 @@ func stripUnderscore(s string) string {
 	return strings.Replace(s, "_", "", -1)
 }
 
+// describeMethodSet lists the exported methods of t for error messages.
+func describeMethodSet(t reflect.Type) string {
+	if t.NumMethod() == 0 {
+		return fmt.Sprintf("the empty method set of %s", t)
+	}
+	names := make([]string, t.NumMethod())
+	for i := range names {
+		names[i] = t.Method(i).Name
+	}
+	return fmt.Sprintf("the method set of %s [%s]", t, strings.Join(names, ", "))
+}
+
 func unwrapPtr(t reflect.Type) reflect.Type {
 	if t.Kind() == reflect.Ptr {
 		return t.Elem()
@@ -12,6 +12,7 @@ import (
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
 	"github.com/graph-gophers/graphql-go/internal/query"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
 )
 
 type Request struct {
@@ -21,6 +22,14 @@ type Request struct {
 	Mu                 sync.Mutex
 	Errs               []*errors.QueryError
 	AllowIntrospection bool
+	// IntrospectionFilter hides types and fields from introspection queries, if it is not nil.
+	IntrospectionFilter introspection.Filter
+	// CoercionFailed is called with the object type, the field and the error for each field whose
+	// arguments can not be coerced into their Go types, if it is not nil.
+	CoercionFailed func(typeName, fieldName string, err *packer.InputError)
+	// RecordSkipped collects the selections excluded by @skip and @include directives in Skipped.
+	RecordSkipped bool
+	Skipped       []tracer.SkippedSelection
 }
 
 func (r *Request) AddError(err *errors.QueryError) {
@@ -29,6 +38,20 @@ func (r *Request) AddError(err *errors.QueryError) {
 	r.Mu.Unlock()
 }
 
+// argumentsError converts an error of the arguments packer of field into a query error. Errors
+// of nested input values report the input path and the expected type in the extensions.
+func argumentsError(field *ast.Field, err error) *errors.QueryError {
+	qErr := errors.Errorf("%s", err)
+	qErr.Locations = []errors.Location{field.Name.Loc}
+	if ie, ok := err.(*packer.InputError); ok {
+		qErr.Extensions = map[string]interface{}{
+			"inputPath":    ie.PathString(),
+			"expectedType": ie.ExpectedType,
+		}
+	}
+	return qErr.SetCode(errors.CodeBadUserInput)
+}
+
 func ApplyOperation(r *Request, s *resolvable.Schema, op *ast.OperationDefinition) []Selection {
 	var obj *resolvable.Object
 	switch op.Type {
@@ -48,6 +71,8 @@ type Selection interface {
 
 type SchemaField struct {
 	resolvable.Field
+	// Query is the field as selected in the query document.
+	Query       *ast.Field
 	Alias       string
 	Args        map[string]interface{}
 	PackedArgs  reflect.Value
@@ -85,7 +110,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 		switch sel := sel.(type) {
 		case *ast.Field:
 			field := sel
-			if skipByDirective(r, field.Directives) {
+			if skip(r, tracer.SkippedSelection{TypeName: e.Name, FieldName: field.Name.Name, Alias: field.Alias.Name}, field.Directives) {
 				continue
 			}
 
@@ -105,7 +130,7 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 						Alias:       field.Alias.Name,
 						Sels:        applySelectionSet(r, s, s.Meta.Schema, field.SelectionSet),
 						Async:       true,
-						FixedResult: reflect.ValueOf(introspection.WrapSchema(r.Schema)),
+						FixedResult: reflect.ValueOf(introspection.WrapSchemaFiltered(r.Schema, r.IntrospectionFilter)),
 					})
 				}
 
@@ -114,14 +139,14 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 					p := packer.ValuePacker{ValueType: reflect.TypeOf("")}
 					v, err := p.Pack(field.Arguments.MustGet("name").Deserialize(r.Vars))
 					if err != nil {
-						r.AddError(errors.Errorf("%s", err))
+						r.AddError(errors.Errorf("%s", err).SetCode(errors.CodeBadUserInput))
 						return nil
 					}
 
 					var resolvedType *introspection.Type
 					t, ok := r.Schema.Types[v.String()]
 					if ok {
-						resolvedType = introspection.WrapType(t)
+						resolvedType = introspection.WrapTypeFiltered(t, r.IntrospectionFilter)
 					}
 
 					flattenedSels = append(flattenedSels, &SchemaField{
@@ -139,42 +164,57 @@ func applySelectionSet(r *Request, s *resolvable.Schema, e *resolvable.Object, s
 				var args map[string]interface{}
 				var packedArgs reflect.Value
 				if fe.ArgsPacker != nil {
-					args = make(map[string]interface{})
+					if len(field.Arguments) != 0 {
+						args = make(map[string]interface{}, len(field.Arguments))
+					}
 					for _, arg := range field.Arguments {
 						args[arg.Name.Name] = arg.Value.Deserialize(r.Vars)
 					}
 					var err error
 					packedArgs, err = fe.ArgsPacker.Pack(args)
 					if err != nil {
-						r.AddError(errors.Errorf("%s", err))
+						if ie, ok := err.(*packer.InputError); ok && r.CoercionFailed != nil {
+							r.CoercionFailed(fe.TypeName, fe.Name, ie)
+						}
+						r.AddError(argumentsError(field, err))
 						return
 					}
 				}
 
 				fieldSels := applyField(r, s, fe.ValueExec, field.SelectionSet)
+				async := fe.HasContext || fe.ArgsPacker != nil || len(fe.Visitors.Interceptors) > 0 || fe.HasError
+				if fe.Trivial != nil {
+					async = !*fe.Trivial
+				}
 				flattenedSels = append(flattenedSels, &SchemaField{
 					Field:      *fe,
+					Query:      field,
 					Alias:      field.Alias.Name,
 					Args:       args,
 					PackedArgs: packedArgs,
 					Sels:       fieldSels,
-					Async:      fe.HasContext || fe.ArgsPacker != nil || len(fe.Visitors.Interceptors) > 0 || fe.HasError || HasAsyncSel(fieldSels),
+					Async:      async || fe.Thunk || HasAsyncSel(fieldSels),
 				})
 			}
 
 		case *ast.InlineFragment:
 			frag := sel
-			if skipByDirective(r, frag.Directives) {
+			if skip(r, tracer.SkippedSelection{TypeName: e.Name}, frag.Directives) {
 				continue
 			}
 			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &frag.Fragment)...)
 
 		case *ast.FragmentSpread:
 			spread := sel
-			if skipByDirective(r, spread.Directives) {
+			skipped := tracer.SkippedSelection{TypeName: e.Name, Fragment: spread.Name.Name}
+			if skip(r, skipped, spread.Directives) {
+				continue
+			}
+			frag := r.Doc.Fragments.Get(spread.Name.Name)
+			if skip(r, skipped, frag.Directives) {
 				continue
 			}
-			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &r.Doc.Fragments.Get(spread.Name.Name).Fragment)...)
+			flattenedSels = append(flattenedSels, applyFragment(r, s, e, &frag.Fragment)...)
 
 		default:
 			panic("invalid type")
@@ -260,22 +300,38 @@ func applyField(r *Request, s *resolvable.Schema, e resolvable.Resolvable, sels
 		return applySelectionSet(r, s, e, sels)
 	case *resolvable.List:
 		return applyField(r, s, e.Elem, sels)
-	case *resolvable.Scalar:
+	case *resolvable.Scalar, *resolvable.Delegated:
 		return nil
 	default:
 		panic("unreachable")
 	}
 }
 
-func skipByDirective(r *Request, directives ast.DirectiveList) bool {
+// skip reports whether the selection is excluded by its directives and records it as skipped.
+func skip(r *Request, skipped tracer.SkippedSelection, directives ast.DirectiveList) bool {
+	directive := skippedBy(r, directives)
+	if directive == "" {
+		return false
+	}
+	if r.RecordSkipped {
+		skipped.Directive = directive
+		r.Mu.Lock()
+		r.Skipped = append(r.Skipped, skipped)
+		r.Mu.Unlock()
+	}
+	return true
+}
+
+// skippedBy returns the name of the directive which excludes a selection, if any.
+func skippedBy(r *Request, directives ast.DirectiveList) string {
 	if d := directives.Get("skip"); d != nil {
 		p := packer.ValuePacker{ValueType: reflect.TypeOf(false)}
 		v, err := p.Pack(d.Arguments.MustGet("if").Deserialize(r.Vars))
 		if err != nil {
-			r.AddError(errors.Errorf("%s", err))
+			r.AddError(errors.Errorf("%s", err).SetCode(errors.CodeBadUserInput))
 		}
 		if err == nil && v.Bool() {
-			return true
+			return "skip"
 		}
 	}
 
@@ -283,14 +339,14 @@ func skipByDirective(r *Request, directives ast.DirectiveList) bool {
 		p := packer.ValuePacker{ValueType: reflect.TypeOf(false)}
 		v, err := p.Pack(d.Arguments.MustGet("if").Deserialize(r.Vars))
 		if err != nil {
-			r.AddError(errors.Errorf("%s", err))
+			r.AddError(errors.Errorf("%s", err).SetCode(errors.CodeBadUserInput))
 		}
 		if err == nil && !v.Bool() {
-			return true
+			return "include"
 		}
 	}
 
-	return false
+	return ""
 }
 
 func HasAsyncSel(sels []Selection) bool {
@@ -0,0 +1,40 @@
+package exec
+
+import (
+	"sync/atomic"
+)
+
+// Stats counts the resolver calls of a request. It is safe for concurrent use.
+type Stats struct {
+	resolvers int64
+	maxDepth  int64
+}
+
+func (s *Stats) field(path *pathSegment) {
+	var depth int64
+	for p := path; p != nil; p = p.parent {
+		if _, ok := p.value.(string); ok {
+			depth++
+		}
+	}
+	for {
+		max := atomic.LoadInt64(&s.maxDepth)
+		if depth <= max || atomic.CompareAndSwapInt64(&s.maxDepth, max, depth) {
+			return
+		}
+	}
+}
+
+func (s *Stats) resolver() {
+	atomic.AddInt64(&s.resolvers, 1)
+}
+
+// ResolverCount returns the number of resolver calls.
+func (s *Stats) ResolverCount() int {
+	return int(atomic.LoadInt64(&s.resolvers))
+}
+
+// MaxDepth returns the deepest field nesting level which was executed.
+func (s *Stats) MaxDepth() int {
+	return int(atomic.LoadInt64(&s.maxDepth))
+}
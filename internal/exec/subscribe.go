@@ -15,36 +15,49 @@ import (
 )
 
 type Response struct {
-	Data   json.RawMessage
-	Errors []*errors.QueryError
+	Data       json.RawMessage
+	Errors     []*errors.QueryError
+	Extensions map[string]interface{}
+	// Final is set on the response sent for an event which completed the subscription.
+	Final bool
 }
 
 func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.OperationDefinition) <-chan *Response {
 	var result reflect.Value
 	var f *fieldToExec
 	var err *errors.QueryError
+	r.op = op
 	func() {
-		defer r.handlePanic(ctx)
+		defer r.handlePanic(ctx, nil, nil)
 
-		sels := selected.ApplyOperation(&r.Request, s, op)
+		sels := r.applyOperation(ctx, s, op)
 		var fields []*fieldToExec
 		collectFieldsToResolve(sels, s, s.SubscriptionResolver, &fields, make(map[string]*fieldToExec))
 
 		// TODO: move this check into validation.Validate
 		if len(fields) != 1 {
-			err = errors.Errorf("%s", "can subscribe to at most one subscription at a time")
+			err = errors.Errorf("%s", "can subscribe to at most one subscription at a time").SetCode(errors.CodeValidationFailed)
 			return
 		}
 		f = fields[0]
+		if embeddedErr := f.field.CheckEmbedded(f.resolver); embeddedErr != nil {
+			err = errors.Errorf("%s", embeddedErr)
+			err.ResolverError = embeddedErr
+			return
+		}
 
 		var in []reflect.Value
 		if f.field.HasContext {
-			in = append(in, reflect.ValueOf(ctx))
+			resolverCtx := ctx
+			if len(f.field.Directives) > 0 {
+				resolverCtx = withField(ctx, r, f, true)
+			}
+			in = append(in, reflect.ValueOf(resolverCtx))
 		}
 		if f.field.ArgsPacker != nil {
 			in = append(in, f.field.PackedArgs)
 		}
-		callOut := f.resolver.Method(f.field.MethodIndex).Call(in)
+		callOut := f.field.Call(f.resolver, in)
 		result = callOut[0]
 
 		if f.field.HasError && !callOut[1].IsNil() {
@@ -53,7 +66,9 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 				err = resolverErr
 			case error:
 				err = errors.Errorf("%s", resolverErr)
+				err.Locations = fieldLocations(f)
 				err.ResolverError = resolverErr
+				err.Extensions = errors.Extensions(resolverErr)
 			default:
 				panic(fmt.Errorf("can only deal with *QueryError and error types, got %T", resolverErr))
 			}
@@ -77,7 +92,7 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 	}
 
 	if ctxErr := ctx.Err(); ctxErr != nil {
-		return sendAndReturnClosed(&Response{Errors: []*errors.QueryError{errors.Errorf("%s", ctxErr)}})
+		return sendAndReturnClosed(&Response{Errors: []*errors.QueryError{contextError(ctxErr)}})
 	}
 
 	c := make(chan *Response)
@@ -113,16 +128,20 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 					return
 				}
 
-				subR := &Request{
-					Request: selected.Request{
-						Doc:    r.Request.Doc,
-						Vars:   r.Request.Vars,
-						Schema: r.Request.Schema,
-					},
-					Limiter: r.Limiter,
-					Tracer:  r.Tracer,
-					Logger:  r.Logger,
+				if done, ext, err := completion(resp); done {
+					final := &Response{Extensions: ext, Final: true}
+					if err != nil {
+						final.Errors = []*errors.QueryError{resolverError(err)}
+					}
+					select {
+					case <-ctx.Done():
+					case c <- final:
+					}
+					close(c)
+					return
 				}
+
+				subR := r.eventRequest(s)
 				var out bytes.Buffer
 				func() {
 					timeout := r.SubscribeResolverTimeout
@@ -130,18 +149,21 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 						timeout = time.Second
 					}
 
-					subCtx, cancel := context.WithTimeout(ctx, timeout)
+					subCtx, cancel := context.WithTimeout(eventContext(ctx, resp), timeout)
 					defer cancel()
+					subCtx, cancelSize := subR.limitSize(subCtx)
+					defer cancelSize()
 
 					// resolve response
 					func() {
-						defer subR.handlePanic(subCtx)
+						path := &pathSegment{nil, f.field.Alias}
+						defer subR.handlePanic(subCtx, path, nil)
 
 						var buf bytes.Buffer
-						subR.execSelectionSet(subCtx, f.sels, f.field.Type, &pathSegment{nil, f.field.Alias}, s, resp, &buf)
+						subR.execSelectionSet(subCtx, f.sels, f.field.Type, path, s, resp, &buf)
 
 						propagateChildError := false
-						if _, nonNullChild := f.field.Type.(*ast.NonNull); nonNullChild && resolvedToNull(&buf) {
+						if _, nonNullChild := f.field.Type.(*ast.NonNull); nonNullChild && resolvedToNull(&buf) && !r.DisableNullBubbling {
 							propagateChildError = true
 						}
 
@@ -152,8 +174,18 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 						}
 					}()
 
+					if err := subR.sizeError(); err != nil {
+						select {
+						case <-ctx.Done():
+						case c <- &Response{Errors: []*errors.QueryError{err}}:
+						}
+						return
+					}
 					if err := subCtx.Err(); err != nil {
-						c <- &Response{Errors: []*errors.QueryError{errors.Errorf("%s", err)}}
+						select {
+						case <-ctx.Done():
+						case c <- &Response{Errors: []*errors.QueryError{contextError(err)}}:
+						}
 						return
 					}
 
@@ -171,6 +203,95 @@ func (r *Request) Subscribe(ctx context.Context, s *resolvable.Schema, op *ast.O
 	return c
 }
 
+// eventRequest returns the request resolving an event of the subscription r. It has all the options
+// of r, but collects the errors of the event and calls the thunks of the event in batches of its own.
+func (r *Request) eventRequest(s *resolvable.Schema) *Request {
+	subR := &Request{
+		Request: selected.Request{
+			Schema:              r.Request.Schema,
+			Doc:                 r.Request.Doc,
+			Vars:                r.Request.Vars,
+			AllowIntrospection:  r.AllowIntrospection,
+			IntrospectionFilter: r.IntrospectionFilter,
+			CoercionFailed:      r.CoercionFailed,
+		},
+		Limiter:                  r.Limiter,
+		Tracer:                   r.Tracer,
+		SkipTracer:               r.SkipTracer,
+		Logger:                   r.Logger,
+		PanicHandler:             r.PanicHandler,
+		SubscribeResolverTimeout: r.SubscribeResolverTimeout,
+		CacheControl:             r.CacheControl,
+		Profiler:                 r.Profiler,
+		DisableNullBubbling:      r.DisableNullBubbling,
+		Stats:                    r.Stats,
+		RetryPolicy:              r.RetryPolicy,
+		SortResponseKeys:         r.SortResponseKeys,
+		Marshal:                  r.Marshal,
+		MaxResponseBytes:         r.MaxResponseBytes,
+		DeduplicateFields:        r.DeduplicateFields,
+		LenientEnums:             r.LenientEnums,
+		PlanMutation:             r.PlanMutation,
+		op:                       r.op,
+	}
+	if s.HasThunks {
+		subR.thunks = newThunkDispatcher()
+	}
+	return subR
+}
+
+// eventContexter is implemented by subscription events which carry values for the
+// resolvers of the event's fields.
+type eventContexter interface {
+	ResolverContext(ctx context.Context) context.Context
+}
+
+// eventContext returns the context used to resolve the subscription event ev.
+func eventContext(ctx context.Context, ev reflect.Value) context.Context {
+	if (ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Interface) && ev.IsNil() {
+		return ctx
+	}
+	if ec, ok := ev.Interface().(eventContexter); ok {
+		return ec.ResolverContext(ctx)
+	}
+	return ctx
+}
+
+// completer is implemented by subscription events which end the subscription.
+type completer interface {
+	SubscriptionCompletion() (done bool, extensions map[string]interface{}, err error)
+}
+
+// completion reports whether the subscription event ev ends the subscription.
+func completion(ev reflect.Value) (done bool, extensions map[string]interface{}, err error) {
+	if (ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Interface) && ev.IsNil() {
+		return false, nil, nil
+	}
+	if c, ok := ev.Interface().(completer); ok {
+		return c.SubscriptionCompletion()
+	}
+	return false, nil, nil
+}
+
+// contextError converts the error of a done context into a query error.
+func contextError(err error) *errors.QueryError {
+	qErr := errors.Errorf("%s", err)
+	if err == context.DeadlineExceeded {
+		return qErr.SetCode(errors.CodeTimeout)
+	}
+	return qErr.SetCode(errors.CodeCancelled)
+}
+
+func resolverError(err error) *errors.QueryError {
+	if qErr, ok := err.(*errors.QueryError); ok {
+		return qErr
+	}
+	qErr := errors.Errorf("%s", err)
+	qErr.ResolverError = err
+	qErr.Extensions = errors.Extensions(err)
+	return qErr
+}
+
 func sendAndReturnClosed(resp *Response) chan *Response {
 	c := make(chan *Response, 1)
 	c <- resp
@@ -0,0 +1,104 @@
+package exec
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// thunkDispatcher calls the thunks returned by resolvers once no goroutine of a request is running any
+// more, i.e. when every goroutine either waits for a thunk or for other goroutines. This gives the
+// resolvers of all fields which are resolved concurrently, e.g. of sibling fields and of the elements of
+// a list, the chance to register the keys of a batch before the first thunk loads it.
+type thunkDispatcher struct {
+	mu      sync.Mutex
+	running int
+	waiting int
+	flush   chan struct{}
+}
+
+func newThunkDispatcher() *thunkDispatcher {
+	return &thunkDispatcher{running: 1, flush: make(chan struct{})}
+}
+
+// start records that a goroutine starts or continues running. It does nothing if d is nil, as do the
+// other methods.
+func (d *thunkDispatcher) start() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.running++
+	d.mu.Unlock()
+}
+
+// stop records that a goroutine exits or is about to wait for other goroutines.
+func (d *thunkDispatcher) stop() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.running--
+	d.flushIfIdle()
+	d.mu.Unlock()
+}
+
+// wait blocks until the thunks may be called.
+func (d *thunkDispatcher) wait() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	flush := d.flush
+	d.running--
+	d.waiting++
+	d.flushIfIdle()
+	d.mu.Unlock()
+	<-flush
+}
+
+func (d *thunkDispatcher) flushIfIdle() {
+	if d.running == 0 && d.waiting > 0 {
+		d.running, d.waiting = d.waiting, 0
+		close(d.flush)
+		d.flush = make(chan struct{})
+	}
+}
+
+// acquire sends to the semaphore sem, recording that the goroutine waits if sem is full.
+func (d *thunkDispatcher) acquire(sem chan struct{}) {
+	select {
+	case sem <- struct{}{}:
+	default:
+		d.stop()
+		sem <- struct{}{}
+		d.start()
+	}
+}
+
+// callThunk waits until the thunk returned by the resolver of a field may be called, calls it and
+// returns the value of the field.
+func (r *Request) callThunk(ctx context.Context, thunk reflect.Value, path *pathSegment) (result reflect.Value, err *errors.QueryError) {
+	if thunk.IsNil() {
+		return reflect.Value{}, nil
+	}
+	r.thunks.wait()
+
+	defer func() {
+		if panicValue := recover(); panicValue != nil {
+			err = r.panicError(ctx, panicValue, path)
+		}
+	}()
+	out := thunk.Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		resolverErr := out[1].Interface().(error)
+		err := errors.Errorf("%s", resolverErr)
+		err.Path = path.toSlice()
+		err.ResolverError = resolverErr
+		err.Extensions = errors.Extensions(resolverErr)
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(out[0].Interface()), nil
+}
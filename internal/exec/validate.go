@@ -49,16 +49,12 @@ func validateFieldSelection(ctx context.Context, s *resolvable.Schema, f *fieldT
 		errs := make([]*errors.QueryError, l)
 
 		for i, err := range vErrs {
-			var ext map[string]interface{}
-			if e, ok := err.(extensionser); ok {
-				ext = e.Extensions()
-			}
 			errs[i] = &errors.QueryError{
 				Err:        err,
 				Message:    err.Error(),
 				Locations:  []errors.Location{f.field.Loc},
 				Path:       path.toSlice(),
-				Extensions: ext,
+				Extensions: errors.Extensions(err),
 			}
 		}
 
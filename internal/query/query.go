@@ -15,8 +15,23 @@ const (
 	Subscription ast.OperationType = "SUBSCRIPTION"
 )
 
+// Limits restrict the resources spent on parsing a document. A zero MaxTokens disables the token limit,
+// a zero MaxDepth uses common.DefaultMaxDepth.
+type Limits struct {
+	// MaxTokens is the maximum number of tokens of the document, not counting commas and comments.
+	MaxTokens int
+	// MaxDepth is the maximum nesting depth of selection sets, list and object values and list types.
+	MaxDepth int
+}
+
 func Parse(queryString string) (*ast.ExecutableDefinition, *errors.QueryError) {
+	return ParseWithLimits(queryString, Limits{})
+}
+
+// ParseWithLimits parses the document like Parse but aborts with an error as soon as it exceeds limits.
+func ParseWithLimits(queryString string, limits Limits) (*ast.ExecutableDefinition, *errors.QueryError) {
 	l := common.NewLexer(queryString, false)
+	l.Limit(limits.MaxTokens, limits.MaxDepth)
 
 	var execDef *ast.ExecutableDefinition
 	err := l.CatchSyntaxError(func() { execDef = parseExecutableDefinition(l) })
@@ -97,11 +112,13 @@ func parseFragment(l *common.Lexer) *ast.FragmentDefinition {
 
 func parseSelectionSet(l *common.Lexer) []ast.Selection {
 	var sels []ast.Selection
+	l.Enter()
 	l.ConsumeToken('{')
 	for l.Peek() != '}' {
 		sels = append(sels, parseSelection(l))
 	}
 	l.ConsumeToken('}')
+	l.Leave()
 	return sels
 }
 
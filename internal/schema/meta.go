@@ -56,7 +56,7 @@ var metaSrc = `
 		# for how to access supported similar data. Formatted in
 		# [Markdown](https://daringfireball.net/projects/markdown/).
 		reason: String = "No longer supported"
-	) on FIELD_DEFINITION | ENUM_VALUE | ARGUMENT_DEFINITION
+	) on FIELD_DEFINITION | ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION | ENUM_VALUE
 
 	# Provides a scalar specification URL for specifying the behavior of custom scalar types.
 	directive @specifiedBy(
@@ -74,7 +74,7 @@ var metaSrc = `
 		name: String!
 		description: String
 		locations: [__DirectiveLocation!]!
-		args: [__InputValue!]!
+		args(includeDeprecated: Boolean = false): [__InputValue!]!
 	}
 
 	# A Directive can be adjacent to many parts of the GraphQL language, a
@@ -133,7 +133,7 @@ var metaSrc = `
 	type __Field {
 		name: String!
 		description: String
-		args: [__InputValue!]!
+		args(includeDeprecated: Boolean = false): [__InputValue!]!
 		type: __Type!
 		isDeprecated: Boolean!
 		deprecationReason: String
@@ -156,6 +156,7 @@ var metaSrc = `
 	# available types and directives on the server, as well as the entry points for
 	# query, mutation, and subscription operations.
 	type __Schema {
+		description: String
 		# A list of all types supported by this server.
 		types: [__Type!]!
 		# The type that query operations will be rooted at.
@@ -184,7 +185,7 @@ var metaSrc = `
 		interfaces: [__Type!]
 		possibleTypes: [__Type!]
 		enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
-		inputFields: [__InputValue!]
+		inputFields(includeDeprecated: Boolean = false): [__InputValue!]
 		ofType: __Type
 		specifiedByURL: String
 	}
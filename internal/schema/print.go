@@ -0,0 +1,295 @@
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+var (
+	builtinsOnce sync.Once
+	builtinTypes map[string]bool
+	builtinDirs  map[string]bool
+)
+
+func builtins() (types, directives map[string]bool) {
+	builtinsOnce.Do(func() {
+		m := newMeta()
+		builtinTypes = make(map[string]bool, len(m.Types))
+		for name := range m.Types {
+			builtinTypes[name] = true
+		}
+		builtinDirs = make(map[string]bool, len(m.Directives))
+		for name := range m.Directives {
+			builtinDirs[name] = true
+		}
+	})
+	return builtinTypes, builtinDirs
+}
+
+// Print formats s in the schema definition language. Definitions are printed in the order of the
+// source, omitting the built-in scalars, directives and introspection types. Extensions are printed
+// merged into the types they extend. Descriptions are printed as strings, or as comments if
+// commentDescriptions is set, so that parsing the result in the same mode yields the same
+// descriptions.
+func Print(s *ast.Schema, commentDescriptions bool) string {
+	p := &printer{schema: s, commentDescriptions: commentDescriptions}
+	types, directives := builtins()
+
+	type definition struct {
+		loc   errors.Location
+		print func()
+	}
+	var defs []definition
+	for name, t := range s.Types {
+		if types[name] {
+			continue
+		}
+		t := t
+		defs = append(defs, definition{loc: typeLoc(t), print: func() { p.namedType(t) }})
+	}
+	for name, d := range s.Directives {
+		if directives[name] {
+			continue
+		}
+		d := d
+		defs = append(defs, definition{loc: d.Loc, print: func() { p.directiveDefinition(d) }})
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].loc.Line != defs[j].loc.Line {
+			return defs[i].loc.Line < defs[j].loc.Line
+		}
+		return defs[i].loc.Column < defs[j].loc.Column
+	})
+
+	if s.SchemaDefinition.Present {
+		p.schemaDefinition(&s.SchemaDefinition)
+	}
+	for _, d := range defs {
+		if p.sb.Len() != 0 {
+			p.sb.WriteByte('\n')
+		}
+		d.print()
+	}
+	return p.sb.String()
+}
+
+func typeLoc(t ast.NamedType) errors.Location {
+	switch t := t.(type) {
+	case *ast.ScalarTypeDefinition:
+		return t.Loc
+	case *ast.ObjectTypeDefinition:
+		return t.Loc
+	case *ast.InterfaceTypeDefinition:
+		return t.Loc
+	case *ast.Union:
+		return t.Loc
+	case *ast.EnumTypeDefinition:
+		return t.Loc
+	case *ast.InputObject:
+		return t.Loc
+	}
+	return errors.Location{}
+}
+
+type printer struct {
+	schema              *ast.Schema
+	sb                  strings.Builder
+	commentDescriptions bool
+}
+
+func (p *printer) schemaDefinition(d *ast.SchemaDefinition) {
+	p.description("", d.Desc)
+	p.sb.WriteString("schema")
+	p.directives(d.Directives)
+	p.sb.WriteString(" {\n")
+	for _, op := range []string{"query", "mutation", "subscription"} {
+		if name, ok := d.EntryPointNames[op]; ok {
+			p.sb.WriteString("  " + op + ": " + name + "\n")
+		}
+	}
+	p.sb.WriteString("}\n")
+}
+
+func (p *printer) namedType(t ast.NamedType) {
+	p.description("", t.Description())
+	switch t := t.(type) {
+	case *ast.ScalarTypeDefinition:
+		p.sb.WriteString("scalar " + t.Name)
+		p.directives(t.Directives)
+		p.sb.WriteByte('\n')
+
+	case *ast.ObjectTypeDefinition:
+		p.sb.WriteString("type " + t.Name)
+		if len(t.InterfaceNames) != 0 {
+			p.sb.WriteString(" implements " + strings.Join(t.InterfaceNames, " & "))
+		}
+		p.directives(t.Directives)
+		p.fields(t.Fields)
+
+	case *ast.InterfaceTypeDefinition:
+		p.sb.WriteString("interface " + t.Name)
+		if len(t.Interfaces) != 0 {
+			names := make([]string, len(t.Interfaces))
+			for i, iface := range t.Interfaces {
+				names[i] = iface.Name
+			}
+			p.sb.WriteString(" implements " + strings.Join(names, " & "))
+		}
+		p.directives(t.Directives)
+		p.fields(t.Fields)
+
+	case *ast.Union:
+		p.sb.WriteString("union " + t.Name)
+		p.directives(t.Directives)
+		p.sb.WriteString(" = " + strings.Join(t.TypeNames, " | ") + "\n")
+
+	case *ast.EnumTypeDefinition:
+		p.sb.WriteString("enum " + t.Name)
+		p.directives(t.Directives)
+		p.sb.WriteString(" {\n")
+		for _, v := range t.EnumValuesDefinition {
+			p.description("  ", v.Desc)
+			p.sb.WriteString("  " + v.EnumValue)
+			p.directives(v.Directives)
+			p.sb.WriteByte('\n')
+		}
+		p.sb.WriteString("}\n")
+
+	case *ast.InputObject:
+		p.sb.WriteString("input " + t.Name)
+		p.directives(t.Directives)
+		p.sb.WriteString(" {\n")
+		for _, v := range t.Values {
+			p.description("  ", v.Desc)
+			p.sb.WriteString("  ")
+			p.inputValue(v)
+			p.sb.WriteByte('\n')
+		}
+		p.sb.WriteString("}\n")
+	}
+}
+
+func (p *printer) fields(fields ast.FieldsDefinition) {
+	p.sb.WriteString(" {\n")
+	for _, f := range fields {
+		p.description("  ", f.Desc)
+		p.sb.WriteString("  " + f.Name)
+		p.arguments("  ", f.Arguments)
+		p.sb.WriteString(": " + f.Type.String())
+		p.directives(f.Directives)
+		p.sb.WriteByte('\n')
+	}
+	p.sb.WriteString("}\n")
+}
+
+func (p *printer) directiveDefinition(d *ast.DirectiveDefinition) {
+	p.description("", d.Desc)
+	p.sb.WriteString("directive @" + d.Name)
+	p.arguments("", d.Arguments)
+	if d.Repeatable {
+		p.sb.WriteString(" repeatable")
+	}
+	p.sb.WriteString(" on " + strings.Join(d.Locations, " | ") + "\n")
+}
+
+// arguments prints the arguments inline, or one per line if any of them has a description.
+func (p *printer) arguments(indent string, args ast.ArgumentsDefinition) {
+	if len(args) == 0 {
+		return
+	}
+	multiline := false
+	for _, a := range args {
+		multiline = multiline || a.Desc != ""
+	}
+	p.sb.WriteByte('(')
+	for i, a := range args {
+		if multiline {
+			p.sb.WriteByte('\n')
+			p.description(indent+"  ", a.Desc)
+			p.sb.WriteString(indent + "  ")
+		} else if i > 0 {
+			p.sb.WriteString(", ")
+		}
+		p.inputValue(a)
+	}
+	if multiline {
+		p.sb.WriteString("\n" + indent)
+	}
+	p.sb.WriteByte(')')
+}
+
+func (p *printer) inputValue(v *ast.InputValueDefinition) {
+	p.sb.WriteString(v.Name.Name + ": " + v.Type.String())
+	if v.Default != nil {
+		p.sb.WriteString(" = " + v.Default.String())
+	}
+	p.directives(v.Directives)
+}
+
+func (p *printer) directives(ds ast.DirectiveList) {
+	for _, d := range ds {
+		p.sb.WriteString(" @" + d.Name.Name)
+		var args []string
+		for _, a := range d.Arguments {
+			if a.Value == nil || p.isDefault(d.Name.Name, a) {
+				continue
+			}
+			args = append(args, a.Name.Name+": "+a.Value.String())
+		}
+		if len(args) != 0 {
+			p.sb.WriteString("(" + strings.Join(args, ", ") + ")")
+		}
+	}
+}
+
+// isDefault reports whether the argument was added by the parser with the default value of the
+// directive definition, so it is omitted like in the source.
+func (p *printer) isDefault(directive string, a *ast.Argument) bool {
+	dd, ok := p.schema.Directives[directive]
+	if !ok {
+		return false
+	}
+	def := dd.Arguments.Get(a.Name.Name)
+	return def != nil && def.Default == a.Value
+}
+
+func (p *printer) description(indent, desc string) {
+	if desc == "" {
+		return
+	}
+	if p.commentDescriptions {
+		for _, line := range strings.Split(desc, "\n") {
+			if line == "" {
+				p.sb.WriteString(indent + "#\n")
+				continue
+			}
+			p.sb.WriteString(indent + "# " + line + "\n")
+		}
+		return
+	}
+	// Block strings can not represent every string, e.g. ones with leading blank lines or
+	// quotes, so those are printed as regular strings.
+	if strings.Contains(desc, `"`) || strings.Contains(desc, `\`) || strings.TrimSpace(desc) != desc ||
+		strings.ContainsAny(desc, "\r\t") {
+		p.sb.WriteString(indent + strconv.Quote(desc) + "\n")
+		return
+	}
+	if !strings.Contains(desc, "\n") {
+		p.sb.WriteString(indent + `"""` + desc + `"""` + "\n")
+		return
+	}
+	p.sb.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(desc, "\n") {
+		if line == "" {
+			p.sb.WriteByte('\n')
+			continue
+		}
+		p.sb.WriteString(indent + line + "\n")
+	}
+	p.sb.WriteString(indent + `"""` + "\n")
+}
@@ -29,7 +29,15 @@ func New() *ast.Schema {
 }
 
 func Parse(s *ast.Schema, schemaString string, useStringDescriptions bool) error {
+	return ParseWithLimits(s, schemaString, useStringDescriptions, 0, 0)
+}
+
+// ParseWithLimits parses the schema like Parse but aborts with an error as soon as the document has
+// more than maxTokens tokens or is nested deeper than maxDepth. Zero disables the token limit and uses
+// common.DefaultMaxDepth as depth limit.
+func ParseWithLimits(s *ast.Schema, schemaString string, useStringDescriptions bool, maxTokens, maxDepth int) error {
 	l := common.NewLexer(schemaString, useStringDescriptions)
+	l.Limit(maxTokens, maxDepth)
 	err := l.CatchSyntaxError(func() { parseSchema(s, l) })
 	if err != nil {
 		return err
@@ -274,7 +282,7 @@ func resolveNamedType(s *ast.Schema, t ast.NamedType) error {
 			return err
 		}
 	case *ast.InputObject:
-		if err := resolveInputObject(s, t.Values); err != nil {
+		if err := resolveInputObject(s, t.Values, "INPUT_FIELD_DEFINITION"); err != nil {
 			return err
 		}
 		if err := resolveDirectives(s, t.Directives, "INPUT_OBJECT"); err != nil {
@@ -297,7 +305,7 @@ func resolveField(s *ast.Schema, f *ast.FieldDefinition) error {
 	if err := resolveDirectives(s, f.Directives, "FIELD_DEFINITION"); err != nil {
 		return err
 	}
-	return resolveInputObject(s, f.Arguments)
+	return resolveInputObject(s, f.Arguments, "ARGUMENT_DEFINITION")
 }
 
 func resolveDirectives(s *ast.Schema, directives ast.DirectiveList, loc string) error {
@@ -340,7 +348,7 @@ func resolveDirectives(s *ast.Schema, directives ast.DirectiveList, loc string)
 	return nil
 }
 
-func resolveInputObject(s *ast.Schema, values ast.ArgumentsDefinition) error {
+func resolveInputObject(s *ast.Schema, values ast.ArgumentsDefinition, loc string) error {
 	for _, v := range values {
 		t, err := common.ResolveType(v.Type, s.Resolve)
 		if err != nil {
@@ -348,10 +356,14 @@ func resolveInputObject(s *ast.Schema, values ast.ArgumentsDefinition) error {
 		}
 		v.Type = t
 
-		if err := resolveDirectives(s, v.Directives, "ARGUMENT_DEFINITION"); err != nil {
+		if err := resolveDirectives(s, v.Directives, loc); err != nil {
 			return err
 		}
 
+		// Clients can't stop using required arguments and input fields, so they can't be deprecated.
+		if _, nonNull := v.Type.(*ast.NonNull); nonNull && v.Default == nil && v.Directives.Get("deprecated") != nil {
+			return errors.Errorf("required input value %q can not be deprecated", v.Name.Name)
+		}
 	}
 	return nil
 }
@@ -977,6 +977,60 @@ Second line of the description.
 				return nil
 			},
 		},
+		{
+			name: "Directive on input field is validated against INPUT_FIELD_DEFINITION",
+			sdl: `
+			directive @argonly on ARGUMENT_DEFINITION
+			input InputObject {
+				name: String @argonly
+			}
+			`,
+			validateError: func(err error) error {
+				prefix := `graphql: invalid location "INPUT_FIELD_DEFINITION" for directive "argonly"`
+				if err == nil || !strings.HasPrefix(err.Error(), prefix) {
+					return fmt.Errorf("expected error starting with %q, but got %q", prefix, err)
+				}
+				return nil
+			},
+		},
+		{
+			name: "Required arguments can not be deprecated",
+			sdl: `
+			type Query {
+				hello(name: String! @deprecated): String!
+			}
+			`,
+			validateError: func(err error) error {
+				msg := `graphql: required input value "name" can not be deprecated`
+				if err == nil || err.Error() != msg {
+					return fmt.Errorf("expected error %q, but got %q", msg, err)
+				}
+				return nil
+			},
+		},
+		{
+			name: "Deprecated input fields and arguments",
+			sdl: `
+			type Query {
+				hello(name: String @deprecated(reason: "use fullName"), fullName: String! = "world"): String!
+			}
+			input InputObject {
+				old: String @deprecated
+				required: Int! = 1 @deprecated
+			}
+			`,
+			validateSchema: func(s *ast.Schema) error {
+				arg := s.Types["Query"].(*ast.ObjectTypeDefinition).Fields.Get("hello").Arguments.Get("name")
+				if arg.Directives.Get("deprecated") == nil {
+					return fmt.Errorf("expected argument %q to be deprecated", arg.Name.Name)
+				}
+				field := s.Types["InputObject"].(*ast.InputObject).Values.Get("required")
+				if field.Directives.Get("deprecated") == nil {
+					return fmt.Errorf("expected input field %q to be deprecated", field.Name.Name)
+				}
+				return nil
+			},
+		},
 		{
 			name: "Decorating interface with an undeclared directive should return an error",
 			sdl: `
@@ -0,0 +1,105 @@
+// Package transcode converts JSON documents into binary formats such as MessagePack and CBOR. The
+// members of objects keep their order, e.g. the order of the fields of a GraphQL response.
+package transcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Writer writes the values of a binary format. Arrays and maps announce the number of their elements,
+// which are written next. Each member of a map is written as its key followed by its value.
+type Writer interface {
+	Null()
+	Bool(b bool)
+	Int(i int64)
+	Float(f float64)
+	String(s string)
+	Array(n int)
+	Map(n int)
+}
+
+// JSON writes the JSON document data to w.
+func JSON(data []byte, w Writer) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := parse(dec)
+	if err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("transcode: unexpected data after the JSON value")
+	}
+	v.write(w)
+	return nil
+}
+
+// value is a JSON value. The members of objects are stored in order, keys at even and values at odd
+// indices of elems.
+type value struct {
+	token  json.Token
+	object bool
+	array  bool
+	elems  []*value
+}
+
+func parse(dec *json.Decoder) (*value, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := tok.(json.Delim)
+	if !ok {
+		return &value{token: tok}, nil
+	}
+	v := &value{object: d == '{', array: d == '['}
+	for dec.More() {
+		if v.object {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v.elems = append(v.elems, &value{token: key})
+		}
+		elem, err := parse(dec)
+		if err != nil {
+			return nil, err
+		}
+		v.elems = append(v.elems, elem)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *value) write(w Writer) {
+	switch {
+	case v.object:
+		w.Map(len(v.elems) / 2)
+	case v.array:
+		w.Array(len(v.elems))
+	default:
+		switch tok := v.token.(type) {
+		case nil:
+			w.Null()
+		case bool:
+			w.Bool(tok)
+		case string:
+			w.String(tok)
+		case json.Number:
+			if i, err := tok.Int64(); err == nil {
+				w.Int(i)
+			} else {
+				f, _ := tok.Float64()
+				w.Float(f)
+			}
+		}
+		return
+	}
+	for _, elem := range v.elems {
+		elem.write(w)
+	}
+}
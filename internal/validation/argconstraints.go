@@ -0,0 +1,180 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Names of the directives constraining which arguments of a field are provided together.
+const (
+	requiresOneOfDirective     = "requiresOneOf"
+	mutuallyExclusiveDirective = "mutuallyExclusive"
+	requiredWithDirective      = "requiredWith"
+)
+
+// validateArgumentConstraints checks the arguments of a field selection against the argument
+// constraint directives of its definition:
+//
+//   - @requiresOneOf(fields: [String!]!) requires at least one of the arguments
+//   - @mutuallyExclusive(fields: [String!]!) allows at most one of the arguments
+//   - @requiredWith(field: String!, with: [String!]!) requires field once any of the arguments of
+//     with is provided
+//
+// An argument is provided if its value is not null. Arguments given as variables are provided if the
+// variable or its default value is not null, or if neither is known.
+func validateArgumentConstraints(c *opContext, sel *ast.Field, f *ast.FieldDefinition) {
+	for _, d := range f.Directives {
+		switch d.Name.Name {
+		case requiresOneOfDirective:
+			names := stringList(d, "fields")
+			for _, name := range names {
+				if argumentProvided(c, sel, name) {
+					names = nil
+					break
+				}
+			}
+			if len(names) != 0 {
+				c.addErr(sel.Alias.Loc, "RequiresOneOfRule", "Field %q requires one of the arguments %s.", f.Name, quoteList(names))
+			}
+
+		case mutuallyExclusiveDirective:
+			names := stringList(d, "fields")
+			var first string
+			for _, name := range names {
+				if !argumentProvided(c, sel, name) {
+					continue
+				}
+				if first == "" {
+					first = name
+					continue
+				}
+				c.addErr(argumentLocation(sel, name), "MutuallyExclusiveRule", "Arguments %q and %q of field %q are mutually exclusive.", first, name, f.Name)
+			}
+
+		case requiredWithDirective:
+			required := stringList(d, "field")
+			if len(required) != 1 {
+				continue
+			}
+			if argumentProvided(c, sel, required[0]) {
+				continue
+			}
+			for _, name := range stringList(d, "with") {
+				if argumentProvided(c, sel, name) {
+					c.addErr(argumentLocation(sel, name), "RequiredWithRule", "Argument %q of field %q is required with argument %q.", required[0], f.Name, name)
+					break
+				}
+			}
+		}
+	}
+}
+
+// ValidateArgumentConstraints checks that the argument constraint directives of the fields of s
+// name arguments of the fields they are applied to.
+func ValidateArgumentConstraints(s *ast.Schema) error {
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var fields ast.FieldsDefinition
+		switch t := s.Types[name].(type) {
+		case *ast.ObjectTypeDefinition:
+			fields = t.Fields
+		case *ast.InterfaceTypeDefinition:
+			fields = t.Fields
+		}
+		for _, f := range fields {
+			for _, d := range f.Directives {
+				var args []string
+				switch d.Name.Name {
+				case requiresOneOfDirective, mutuallyExclusiveDirective:
+					args = stringList(d, "fields")
+				case requiredWithDirective:
+					required := stringList(d, "field")
+					if len(required) != 1 {
+						return fmt.Errorf("@%s of field %s.%s must name exactly one required argument", d.Name.Name, name, f.Name)
+					}
+					args = append(required, stringList(d, "with")...)
+				default:
+					continue
+				}
+				for _, arg := range args {
+					if f.Arguments.Get(arg) == nil {
+						return fmt.Errorf("@%s of field %s.%s names unknown argument %q", d.Name.Name, name, f.Name, arg)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// argumentProvided reports whether the argument name of sel is provided with a value other than null.
+func argumentProvided(c *opContext, sel *ast.Field, name string) bool {
+	v, ok := sel.Arguments.Get(name)
+	if !ok {
+		return false
+	}
+	switch v := v.(type) {
+	case *ast.NullValue:
+		return false
+	case *ast.Variable:
+		if value, ok := c.variables[v.Name]; ok {
+			return value != nil
+		}
+		for _, op := range c.ops {
+			if vd := op.Vars.Get(v.Name); vd != nil && vd.Default != nil {
+				_, isNull := vd.Default.(*ast.NullValue)
+				return !isNull
+			}
+		}
+		// the value is not known, e.g. when the document is validated without variables
+		return true
+	}
+	return true
+}
+
+// argumentLocation returns the location of the name of the argument name of sel.
+func argumentLocation(sel *ast.Field, name string) errors.Location {
+	for _, arg := range sel.Arguments {
+		if arg.Name.Name == name {
+			return arg.Name.Loc
+		}
+	}
+	return sel.Alias.Loc
+}
+
+// stringList returns the strings of the argument name of the directive d, which may be a list of
+// strings or a single string.
+func stringList(d *ast.Directive, name string) []string {
+	v, ok := d.Arguments.Get(name)
+	if !ok || v == nil {
+		return nil
+	}
+	var names []string
+	switch val := v.Deserialize(nil).(type) {
+	case string:
+		names = append(names, val)
+	case []interface{}:
+		for _, e := range val {
+			if s, ok := e.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+	return names
+}
+
+func quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = `"` + n + `"`
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// validateArgumentDepth checks that the input objects of the arguments of a field selection are not
+// nested deeper than maxInputDepth. Variables nested into object literals add the depth of their
+// values. Arguments which are variables are checked with the variables.
+func validateArgumentDepth(c *opContext, sel *ast.Field) {
+	if c.maxInputDepth == 0 {
+		return
+	}
+	for _, arg := range sel.Arguments {
+		if _, ok := arg.Value.(*ast.Variable); ok {
+			continue
+		}
+		if literalDepth(c, arg.Value, c.maxInputDepth) > c.maxInputDepth {
+			c.addErr(arg.Name.Loc, "MaxInputDepthExceeded", "Argument %q of field %q exceeds max input depth %d", arg.Name.Name, sel.Name.Name, c.maxInputDepth)
+		}
+	}
+}
+
+// validateVariableDepth checks that the input objects of the value and the default value of the
+// variable v are not nested deeper than maxInputDepth. It reports whether the value exceeds it.
+func validateVariableDepth(c *opContext, v *ast.InputValueDefinition, val interface{}) bool {
+	if c.maxInputDepth == 0 {
+		return false
+	}
+	if v.Default != nil {
+		if literalDepth(c, v.Default, c.maxInputDepth) > c.maxInputDepth {
+			c.addErr(v.Default.Location(), "MaxInputDepthExceeded", "Default value of variable %q exceeds max input depth %d", "$"+v.Name.Name, c.maxInputDepth)
+		}
+	}
+	if valueDepth(val, c.maxInputDepth) > c.maxInputDepth {
+		c.addErr(v.Loc, "MaxInputDepthExceeded", "Variable %q exceeds max input depth %d", "$"+v.Name.Name, c.maxInputDepth)
+		return true
+	}
+	return false
+}
+
+// literalDepth returns the nesting depth of the object values of v. Lists do not add to the depth.
+// It stops descending once the depth exceeds limit, so the result is at most limit+1.
+func literalDepth(c *opContext, v ast.Value, limit int) int {
+	depth := 0
+	switch v := v.(type) {
+	case *ast.ObjectValue:
+		if limit < 0 {
+			return 1
+		}
+		for _, f := range v.Fields {
+			if d := literalDepth(c, f.Value, limit-1); d > depth {
+				depth = d
+			}
+		}
+		depth++
+	case *ast.ListValue:
+		for _, e := range v.Values {
+			if d := literalDepth(c, e, limit); d > depth {
+				depth = d
+			}
+		}
+	case *ast.Variable:
+		if val, ok := c.variables[v.Name]; ok {
+			return valueDepth(val, limit)
+		}
+		for _, op := range c.ops {
+			if vd := op.Vars.Get(v.Name); vd != nil && vd.Default != nil {
+				return literalDepth(c, vd.Default, limit)
+			}
+		}
+	}
+	return depth
+}
+
+// valueDepth returns the nesting depth of the objects of the variable value val like literalDepth.
+func valueDepth(val interface{}, limit int) int {
+	depth := 0
+	switch val := val.(type) {
+	case map[string]interface{}:
+		if limit < 0 {
+			return 1
+		}
+		for _, f := range val {
+			if d := valueDepth(f, limit-1); d > depth {
+				depth = d
+			}
+		}
+		depth++
+	case []interface{}:
+		for _, e := range val {
+			if d := valueDepth(e, limit); d > depth {
+				depth = d
+			}
+		}
+	}
+	return depth
+}
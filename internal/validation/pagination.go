@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// paginationDirective is the name of the directive marking list fields which have to be paginated.
+const paginationDirective = "paginationRequired"
+
+// paginationArgs are the arguments limiting the number of items of a paginated field.
+var paginationArgs = []string{"first", "last"}
+
+// validatePagination checks that a field whose definition has the @paginationRequired(max: Int)
+// directive is selected with a "first" or "last" argument not exceeding max.
+func validatePagination(c *opContext, sel *ast.Field, f *ast.FieldDefinition) {
+	d := f.Directives.Get(paginationDirective)
+	if d == nil {
+		return
+	}
+	max, hasMax := int64(0), false
+	if v, ok := d.Arguments.Get("max"); ok && v != nil {
+		max, hasMax = intValue(c, v)
+	}
+
+	limited := false
+	for _, name := range paginationArgs {
+		v, ok := sel.Arguments.Get(name)
+		if !ok {
+			continue
+		}
+		n, ok := intValue(c, v)
+		if !ok {
+			continue
+		}
+		limited = true
+		if n < 0 || hasMax && n > max {
+			c.addErr(v.Location(), "PaginationRequiredRule", "Argument %q of field %q must be between 0 and %d, got %d.", name, f.Name, max, n)
+		}
+	}
+	if !limited {
+		c.addErr(sel.Alias.Loc, "PaginationRequiredRule", "Field %q must be paginated with the %q or %q argument.", f.Name, paginationArgs[0], paginationArgs[1])
+	}
+}
+
+// intValue returns the value of an Int literal or variable. Variables which are not provided fall
+// back to their default value.
+func intValue(c *opContext, v ast.Value) (int64, bool) {
+	switch v := v.(type) {
+	case *ast.PrimitiveValue:
+		n, err := strconv.ParseInt(v.Text, 10, 64)
+		return n, err == nil
+	case *ast.Variable:
+		val, ok := c.variables[v.Name]
+		if !ok {
+			for _, op := range c.ops {
+				if vd := op.Vars.Get(v.Name); vd != nil && vd.Default != nil {
+					return intValue(c, vd.Default)
+				}
+			}
+			return 0, false
+		}
+		switch val := val.(type) {
+		case int32:
+			return int64(val), true
+		case int:
+			return int64(val), true
+		case int64:
+			return val, true
+		case float64:
+			return int64(val), float64(int64(val)) == val
+		}
+	}
+	return 0, false
+}
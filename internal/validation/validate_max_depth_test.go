@@ -489,7 +489,7 @@ func TestMaxDepthValidation(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			context := newContext(s, doc, tc.maxDepth)
+			context := newContext(s, doc, Options{MaxDepth: tc.maxDepth})
 			op := doc.Operations[0]
 
 			opc := &opContext{context: context, ops: doc.Operations}
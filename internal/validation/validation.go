@@ -34,6 +34,10 @@ type context struct {
 	fieldMap         map[*ast.Field]fieldInfo
 	overlapValidated map[selectionPair]struct{}
 	maxDepth         int
+	maxInputDepth    int
+	scalarValidators map[string]func(interface{}) error
+	visible          func(typeName, fieldName string) bool
+	variables        map[string]interface{}
 }
 
 func (c *context) addErr(loc errors.Location, rule string, format string, a ...interface{}) {
@@ -53,7 +57,7 @@ type opContext struct {
 	ops []*ast.OperationDefinition
 }
 
-func newContext(s *ast.Schema, doc *ast.ExecutableDefinition, maxDepth int) *context {
+func newContext(s *ast.Schema, doc *ast.ExecutableDefinition, opts Options) *context {
 	return &context{
 		schema:           s,
 		doc:              doc,
@@ -61,12 +65,34 @@ func newContext(s *ast.Schema, doc *ast.ExecutableDefinition, maxDepth int) *con
 		usedVars:         make(map[*ast.OperationDefinition]varSet),
 		fieldMap:         make(map[*ast.Field]fieldInfo),
 		overlapValidated: make(map[selectionPair]struct{}),
-		maxDepth:         maxDepth,
+		maxDepth:         opts.MaxDepth,
+		maxInputDepth:    opts.MaxInputDepth,
+		scalarValidators: opts.ScalarValidators,
+		visible:          opts.Visible,
 	}
 }
 
+// Options configure the optional validation rules.
+type Options struct {
+	// MaxDepth is the maximum field nesting depth. 0 disables the check.
+	MaxDepth int
+	// MaxInputDepth is the maximum nesting depth of input objects in arguments and variables. 0
+	// disables the check.
+	MaxInputDepth int
+	// ScalarValidators validate the values of custom scalars by scalar name.
+	ScalarValidators map[string]func(interface{}) error
+	// Visible reports whether a type, or a field of it if fieldName is not empty, is visible to the
+	// request. Hidden types and fields are treated as if they were not defined. If nil, all are visible.
+	Visible func(typeName, fieldName string) bool
+}
+
 func Validate(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string]interface{}, maxDepth int) []*errors.QueryError {
-	c := newContext(s, doc, maxDepth)
+	return ValidateWithOptions(s, doc, variables, Options{MaxDepth: maxDepth})
+}
+
+func ValidateWithOptions(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string]interface{}, opts Options) []*errors.QueryError {
+	c := newContext(s, doc, opts)
+	c.variables = variables
 
 	opNames := make(nameSet, len(doc.Operations))
 	fragUsedBy := make(map[*ast.FragmentDefinition][]*ast.OperationDefinition)
@@ -98,7 +124,9 @@ func Validate(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string
 			if !canBeInput(t) {
 				c.addErr(v.TypeLoc, "VariablesAreInputTypesRule", "Variable %q cannot be non-input type %q.", "$"+v.Name.Name, t)
 			}
-			validateValue(opc, v, variables[v.Name.Name], t)
+			if !validateVariableDepth(opc, v, variables[v.Name.Name]) {
+				validateValue(opc, v, v.Name.Name, variables[v.Name.Name], t)
+			}
 
 			if v.Default != nil {
 				validateLiteral(opc, v.Default)
@@ -197,34 +225,42 @@ func Validate(s *ast.Schema, doc *ast.ExecutableDefinition, variables map[string
 	return c.errs
 }
 
-func validateValue(c *opContext, v *ast.InputValueDefinition, val interface{}, t ast.Type) {
+// validateValue coerces the variable value val of type t like the executor would. path is the
+// location of val within the variable and all errors are reported at the variable definition v.
+func validateValue(c *opContext, v *ast.InputValueDefinition, path string, val interface{}, t ast.Type) {
 	switch t := t.(type) {
 	case *ast.NonNull:
 		if val == nil {
-			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value null.\nExpected type \"%s\", found null.", v.Name.Name, t)
+			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value null.\nExpected type \"%s\", found null.", path, t)
 			return
 		}
-		validateValue(c, v, val, t.OfType)
+		validateValue(c, v, path, val, t.OfType)
 	case *ast.List:
 		if val == nil {
 			return
 		}
-		vv, ok := val.([]interface{})
-		if !ok {
-			// Input coercion rules allow single items without wrapping array
-			validateValue(c, v, val, t.OfType)
+		if vv, ok := val.([]interface{}); ok {
+			for i, elem := range vv {
+				validateValue(c, v, fmt.Sprintf("%s[%d]", path, i), elem, t.OfType)
+			}
 			return
 		}
-		for _, elem := range vv {
-			validateValue(c, v, elem, t.OfType)
+		// lists may be typed, e.g. []int64 as decoded by a graphql.VariablesDecoder
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				validateValue(c, v, fmt.Sprintf("%s[%d]", path, i), rv.Index(i).Interface(), t.OfType)
+			}
+			return
 		}
+		// Input coercion rules allow single items without wrapping array
+		validateValue(c, v, path, val, t.OfType)
 	case *ast.EnumTypeDefinition:
 		if val == nil {
 			return
 		}
 		e, ok := val.(string)
 		if !ok {
-			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %v.", v.Name.Name, val, t, val)
+			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %v.", path, val, t, val)
 			return
 		}
 		for _, option := range t.EnumValuesDefinition {
@@ -232,20 +268,80 @@ func validateValue(c *opContext, v *ast.InputValueDefinition, val interface{}, t
 				return
 			}
 		}
-		c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value %s.\nExpected type \"%s\", found %s.", v.Name.Name, e, t, e)
+		c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value %s.\nExpected type \"%s\", found %s.", path, e, t, e)
+	case *ast.ScalarTypeDefinition:
+		if val == nil {
+			return
+		}
+		if !validateBuiltInVariable(val, t.Name) {
+			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value %v.\nExpected type \"%s\", found %v.", path, val, t, val)
+			return
+		}
+		if fn, ok := c.scalarValidators[t.Name]; ok {
+			if err := fn(val); err != nil {
+				c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value %v.\nExpected type \"%s\", found %v: %s.", path, val, t, val, err)
+			}
+		}
 	case *ast.InputObject:
 		if val == nil {
 			return
 		}
 		in, ok := val.(map[string]interface{})
 		if !ok {
-			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %s.", v.Name.Name, val, t, val)
+			c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid type %T.\nExpected type \"%s\", found %s.", path, val, t, val)
 			return
 		}
 		for _, f := range t.Values {
-			fieldVal := in[f.Name.Name]
-			validateValue(c, f, fieldVal, f.Type)
+			fieldVal, ok := in[f.Name.Name]
+			if !ok && f.Default != nil || !c.inputValueVisible(t.Name, f) {
+				continue // the default value of the field is used
+			}
+			validateValue(c, v, path+"."+f.Name.Name, fieldVal, f.Type)
+		}
+		for name := range in {
+			if f := t.Values.Get(name); f == nil || !c.inputValueVisible(t.Name, f) {
+				c.addErr(v.Loc, "VariablesOfCorrectType", "Variable \"%s\" has invalid value.\nField %q is not defined by type \"%s\".", path, name, t)
+			}
+		}
+	}
+}
+
+// validateBuiltInVariable reports whether the variable value val can be coerced into the
+// built-in scalar n. Values of custom scalars are always accepted.
+func validateBuiltInVariable(val interface{}, n string) bool {
+	rv := reflect.ValueOf(val)
+	switch n {
+	case "Int":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return rv.Int() >= math.MinInt32 && rv.Int() <= math.MaxInt32
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return rv.Uint() <= math.MaxInt32
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			return f == math.Trunc(f) && f >= math.MinInt32 && f <= math.MaxInt32
 		}
+		return false
+	case "Float":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		}
+		return false
+	case "String":
+		return rv.Kind() == reflect.String
+	case "Boolean":
+		return rv.Kind() == reflect.Bool
+	case "ID":
+		switch rv.Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return true
+		}
+		return false
+	default:
+		return true
 	}
 }
 
@@ -344,20 +440,23 @@ func validateSelection(c *opContext, sel ast.Selection, t ast.NamedType) {
 				Type: c.schema.Types["__Type"],
 			}
 		default:
-			f = fields(t).Get(fieldName)
+			f = c.fields(t).Get(fieldName)
 			if f == nil && t != nil {
-				suggestion := makeSuggestion("Did you mean", fields(t).Names(), fieldName)
+				suggestion := common.MakeSuggestion("Did you mean", c.fields(t).Names(), fieldName)
 				c.addErr(sel.Alias.Loc, "FieldsOnCorrectTypeRule", "Cannot query field %q on type %q.%s", fieldName, t, suggestion)
 			}
 		}
 		c.fieldMap[sel] = fieldInfo{sf: f, parent: t}
 
 		validateArgumentLiterals(c, sel.Arguments)
+		validateArgumentDepth(c, sel)
 		if f != nil {
 			validateArgumentTypes(c, sel.Arguments, f.Arguments, sel.Alias.Loc,
 				func() string { return fmt.Sprintf(`field "%s.%s"`, t, fieldName) },
 				func() string { return fmt.Sprintf("Field %q", fieldName) },
 			)
+			validatePagination(c, sel, f)
+			validateArgumentConstraints(c, sel, f)
 		}
 
 		var ft ast.Type
@@ -629,6 +728,43 @@ func argumentsConflict(a, b ast.ArgumentList) bool {
 	return false
 }
 
+// resolve looks up the named type like ast.Schema.Resolve, but does not find hidden types.
+func (c *context) resolve(name string) ast.Type {
+	t := c.schema.Resolve(name)
+	if t == nil || c.visible == nil || c.visible(name, "") {
+		return t
+	}
+	return nil
+}
+
+// inputValueVisible reports whether the input field v of the input object typeName, or the argument v
+// if typeName is empty, is visible. Like in introspection, arguments are hidden if their type is.
+func (c *context) inputValueVisible(typeName string, v *ast.InputValueDefinition) bool {
+	if c.visible == nil {
+		return true
+	}
+	if typeName != "" && !c.visible(typeName, v.Name.Name) {
+		return false
+	}
+	return c.visible(unwrapType(v.Type).TypeName(), "")
+}
+
+// fields returns the fields of t which are visible, i.e. which are not hidden themselves and whose
+// type is not hidden.
+func (c *context) fields(t ast.Type) ast.FieldsDefinition {
+	all := fields(t)
+	if c.visible == nil {
+		return all
+	}
+	var l ast.FieldsDefinition
+	for _, f := range all {
+		if c.visible(t.(ast.NamedType).TypeName(), f.Name) && c.visible(unwrapType(f.Type).TypeName(), "") {
+			l = append(l, f)
+		}
+	}
+	return l
+}
+
 func fields(t ast.Type) ast.FieldsDefinition {
 	switch t := t.(type) {
 	case *ast.ObjectTypeDefinition:
@@ -659,7 +795,7 @@ func unwrapType(t ast.Type) ast.NamedType {
 }
 
 func resolveType(c *context, t ast.Type) ast.Type {
-	t2, err := common.ResolveType(t, c.schema.Resolve)
+	t2, err := common.ResolveType(t, c.resolve)
 	if err != nil {
 		c.errs = append(c.errs, err)
 	}
@@ -751,8 +887,14 @@ func validateNameCustomMsg(c *context, locs []errors.Location, rule string, msg
 func validateArgumentTypes(c *opContext, args ast.ArgumentList, argDecls ast.ArgumentsDefinition, loc errors.Location, owner1, owner2 func() string) {
 	for _, selArg := range args {
 		arg := argDecls.Get(selArg.Name.Name)
-		if arg == nil {
-			suggestion := makeSuggestion("Did you mean", argDecls.Names(), selArg.Name.Name)
+		if arg == nil || !c.inputValueVisible("", arg) {
+			var names []string
+			for _, decl := range argDecls {
+				if c.inputValueVisible("", decl) {
+					names = append(names, decl.Name.Name)
+				}
+			}
+			suggestion := common.MakeSuggestion("Did you mean", names, selArg.Name.Name)
 			c.addErr(selArg.Name.Loc, "KnownArgumentNamesRule", "Unknown argument %q on %s.%s", selArg.Name.Name, owner1(), suggestion)
 			continue
 		}
@@ -762,7 +904,7 @@ func validateArgumentTypes(c *opContext, args ast.ArgumentList, argDecls ast.Arg
 		}
 	}
 	for _, decl := range argDecls {
-		if _, ok := decl.Type.(*ast.NonNull); ok {
+		if _, ok := decl.Type.(*ast.NonNull); ok && c.inputValueVisible("", decl) {
 			if _, ok := args.Get(decl.Name.Name); !ok {
 				if decl.Default != nil {
 					continue
@@ -859,6 +1001,14 @@ func validateValueType(c *opContext, v ast.Value, t ast.Type) (bool, string) {
 		return true, ""
 	}
 
+	if st, ok := t.(*ast.ScalarTypeDefinition); ok && !containsVariable(v) {
+		if fn, ok := c.scalarValidators[st.Name]; ok {
+			if err := fn(v.Deserialize(nil)); err != nil {
+				return false, fmt.Sprintf("Expected type %q, found %s: %s.", t, v, err)
+			}
+		}
+	}
+
 	switch t := t.(type) {
 	case *ast.ScalarTypeDefinition, *ast.EnumTypeDefinition:
 		if lit, ok := v.(*ast.PrimitiveValue); ok {
@@ -889,7 +1039,7 @@ func validateValueType(c *opContext, v ast.Value, t ast.Type) (bool, string) {
 		for _, f := range v.Fields {
 			name := f.Name.Name
 			iv := t.Values.Get(name)
-			if iv == nil {
+			if iv == nil || !c.inputValueVisible(t.Name, iv) {
 				return false, fmt.Sprintf("In field %q: Unknown field.", name)
 			}
 			if ok, reason := validateValueType(c, f.Value, iv.Type); !ok {
@@ -897,6 +1047,9 @@ func validateValueType(c *opContext, v ast.Value, t ast.Type) (bool, string) {
 			}
 		}
 		for _, iv := range t.Values {
+			if !c.inputValueVisible(t.Name, iv) {
+				continue
+			}
 			found := false
 			for _, f := range v.Fields {
 				if f.Name.Name == iv.Name.Name {
@@ -1025,6 +1178,26 @@ func isNull(lit interface{}) bool {
 	return ok
 }
 
+func containsVariable(v ast.Value) bool {
+	switch v := v.(type) {
+	case *ast.Variable:
+		return true
+	case *ast.ListValue:
+		for _, entry := range v.Values {
+			if containsVariable(entry) {
+				return true
+			}
+		}
+	case *ast.ObjectValue:
+		for _, f := range v.Fields {
+			if containsVariable(f.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func typesCompatible(a, b ast.Type) bool {
 	al, aIsList := a.(*ast.List)
 	bl, bIsList := b.(*ast.List)
@@ -0,0 +1,195 @@
+// Package websocket implements the framing of the websocket protocol, see RFC 6455, shared by the
+// GraphQL client and server. Only text messages are exchanged, control frames are handled while
+// reading.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// websocket opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrMessageTooLarge is returned by ReadMessage for messages exceeding the read limit.
+var ErrMessageTooLarge = errors.New("websocket message too large")
+
+// AcceptKey returns the value of the Sec-WebSocket-Accept header answering the Sec-WebSocket-Key
+// header key of the handshake.
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Conn is a websocket connection which exchanges text messages once the handshake is done.
+type Conn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	limit  int64
+	client bool       // clients mask the frames they write, servers require masked frames
+	mu     sync.Mutex // serializes writes
+}
+
+// NewServerConn returns the server end of the websocket connection conn, which reads through br.
+// Messages are limited to limit bytes, which has to be positive.
+func NewServerConn(conn net.Conn, br *bufio.Reader, limit int64) *Conn {
+	return &Conn{conn: conn, br: br, limit: limit}
+}
+
+// NewClientConn returns the client end of the websocket connection conn, which reads through br.
+// Messages are limited to limit bytes, which has to be positive.
+func NewClientConn(conn net.Conn, br *bufio.Reader, limit int64) *Conn {
+	return &Conn{conn: conn, br: br, limit: limit, client: true}
+}
+
+// NetConn returns the underlying connection, e.g. to set deadlines.
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
+}
+
+// WriteJSON sends the JSON encoding of v as a text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(opText, b)
+}
+
+// writeFrame writes a single frame, which is masked for clients as required.
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	frame := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		frame[1] = byte(n)
+	case n <= 0xffff:
+		frame[1] = 126
+		frame = append(frame, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(n))
+	default:
+		frame[1] = 127
+		frame = append(frame, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(frame[2:], uint64(n))
+	}
+	if c.client {
+		frame[1] |= 0x80
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// ReadMessage returns the payload of the next text message. Control frames are handled on the way.
+// It returns io.EOF if the peer closed the connection.
+func (c *Conn) ReadMessage() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := c.readFrame(c.limit - int64(len(msg)))
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, nil)
+			return nil, io.EOF
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads a frame with a payload of at most limit bytes.
+func (c *Conn) readFrame(limit int64) (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode = head[0]&0x80 != 0, head[0]&0x0f
+	masked := head[1]&0x80 != 0
+	if !masked && !c.client {
+		return false, 0, nil, errors.New("unmasked websocket frame")
+	}
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if limit < 0 || n > uint64(limit) {
+		return false, 0, nil, ErrMessageTooLarge
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	if opcode != opContinuation && opcode != opText && opcode < opClose {
+		return false, 0, nil, fmt.Errorf("unsupported websocket opcode %d", opcode)
+	}
+	return fin, opcode, payload, nil
+}
+
+// Close sends a close frame with code and reason and closes the connection.
+func (c *Conn) Close(code uint16, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, code)
+	c.writeFrame(opClose, append(payload, reason...))
+	return c.conn.Close()
+}
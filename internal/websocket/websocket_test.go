@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func pipe(limit int64) (client, server *Conn) {
+	c, s := net.Pipe()
+	return NewClientConn(c, bufio.NewReader(c), limit), NewServerConn(s, bufio.NewReader(s), limit)
+}
+
+func TestConn(t *testing.T) {
+	client, server := pipe(1 << 20)
+	defer client.NetConn().Close()
+	defer server.NetConn().Close()
+
+	for _, msg := range []string{"", "hello", strings.Repeat("x", 200), strings.Repeat("y", 70000)} {
+		go client.WriteJSON(msg)
+		b, err := server.ReadMessage()
+		if err != nil || string(b) != `"`+msg+`"` {
+			t.Fatalf("server got %d bytes and error %v", len(b), err)
+		}
+		go server.WriteJSON(msg)
+		b, err = client.ReadMessage()
+		if err != nil || string(b) != `"`+msg+`"` {
+			t.Fatalf("client got %d bytes and error %v", len(b), err)
+		}
+	}
+
+	go server.Close(1000, "")
+	if _, err := client.ReadMessage(); err != io.EOF {
+		t.Errorf("got error %v after close, want io.EOF", err)
+	}
+}
+
+func TestConn_Limit(t *testing.T) {
+	client, server := pipe(10)
+	defer client.NetConn().Close()
+	defer server.NetConn().Close()
+
+	go client.WriteJSON(strings.Repeat("x", 10))
+	if _, err := server.ReadMessage(); err != ErrMessageTooLarge {
+		t.Errorf("got error %v, want %v", err, ErrMessageTooLarge)
+	}
+}
+
+func TestConn_Unmasked(t *testing.T) {
+	c, s := net.Pipe()
+	defer c.Close()
+	server := NewServerConn(s, bufio.NewReader(s), 1<<20)
+	defer s.Close()
+
+	// servers write unmasked frames, which clients must not send
+	go NewServerConn(c, bufio.NewReader(c), 1<<20).WriteJSON("hello")
+	if _, err := server.ReadMessage(); err == nil || err.Error() != "unmasked websocket frame" {
+		t.Errorf("got error %v, want an unmasked frame error", err)
+	}
+}
@@ -2,10 +2,17 @@ package graphql
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 
+	"github.com/graph-gophers/graphql-go/internal/exec"
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/schema"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/noop"
 )
 
 // Inspect allows inspection of the given schema.
@@ -13,17 +20,64 @@ func (s *Schema) Inspect() *introspection.Schema {
 	return introspection.WrapSchema(s.schema)
 }
 
-// ToJSON encodes the schema in a JSON format used by tools like Relay.
+// SDL prints the schema in the schema definition language, e.g. to generate documentation. The
+// definitions keep the order of the source and their descriptions. Descriptions are printed as
+// strings if the schema was parsed with [UseStringDescriptions], otherwise as comments, so that
+// parsing the result with the same options yields the same schema. Built-in scalars, directives and
+// introspection types are omitted.
+func (s *Schema) SDL() string {
+	return schema.Print(s.schema, !s.useStringDescriptions)
+}
+
+// Hash returns a stable content hash of the schema, the hex encoded SHA-256 hash of [Schema.SDL]. It
+// only changes if definitions or descriptions change, so clients can use it to tell whether their copy
+// of the schema is outdated, e.g. by the ETag of introspection responses served by relay.Handler.
+func (s *Schema) Hash() string {
+	s.hashOnce.Do(func() {
+		sum := sha256.Sum256([]byte(s.SDL()))
+		s.hash = hex.EncodeToString(sum[:])
+	})
+	return s.hash
+}
+
+// IntrospectionDependsOnRequest reports whether the results of introspection queries depend on the
+// request, because introspection is disabled or restricted with [DisableIntrospection] or
+// [RestrictIntrospection], or types and fields are hidden with [IntrospectionFilter] or
+// [VisibilityFilter]. Such results must not be shared between requests, e.g. by HTTP caches.
+func (s *Schema) IntrospectionDependsOnRequest() bool {
+	return s.allowIntrospection != nil || s.introspectionFilter != nil || s.visibilityFilter != nil
+}
+
+// ToJSON encodes the schema in a JSON format used by tools like Relay, GraphiQL and code generators.
+// It is the data of the response to the canonical introspection query, i.e. an object with the
+// "__schema" field. The whole schema is encoded, even if introspection is disabled for clients with
+// [DisableIntrospection] or restricted with [IntrospectionFilter] or [VisibilityFilter].
 func (s *Schema) ToJSON() ([]byte, error) {
-	result := s.exec(context.Background(), introspectionQuery, "", nil, &resolvable.Schema{
+	doc, qErr := query.Parse(introspectionQuery)
+	if qErr != nil {
+		return nil, qErr
+	}
+	r := &exec.Request{
+		Request: selected.Request{
+			Doc:                doc,
+			Schema:             s.schema,
+			AllowIntrospection: true,
+		},
+		Limiter:      make(chan struct{}, s.maxParallelism),
+		Tracer:       noop.Tracer{},
+		Logger:       s.logger,
+		PanicHandler: s.panicHandler,
+	}
+	res := &resolvable.Schema{
 		Meta:   s.res.Meta,
 		Query:  &resolvable.Object{},
 		Schema: *s.schema,
-	})
-	if len(result.Errors) != 0 {
-		panic(result.Errors[0])
 	}
-	return json.MarshalIndent(result.Data, "", "\t")
+	data, errs := r.Execute(context.Background(), res, doc.Operations[0])
+	if len(errs) != 0 {
+		return nil, errs[0]
+	}
+	return json.MarshalIndent(json.RawMessage(data), "", "\t")
 }
 
 var introspectionQuery = `
@@ -39,7 +93,7 @@ var introspectionQuery = `
         name
         description
         locations
-        args {
+        args(includeDeprecated: true) {
           ...InputValue
         }
       }
@@ -52,7 +106,7 @@ var introspectionQuery = `
     fields(includeDeprecated: true) {
       name
       description
-      args {
+      args(includeDeprecated: true) {
         ...InputValue
       }
       type {
@@ -61,7 +115,7 @@ var introspectionQuery = `
       isDeprecated
       deprecationReason
     }
-    inputFields {
+    inputFields(includeDeprecated: true) {
       ...InputValue
     }
     interfaces {
@@ -82,6 +136,8 @@ var introspectionQuery = `
     description
     type { ...TypeRef }
     defaultValue
+    isDeprecated
+    deprecationReason
   }
   fragment TypeRef on __Type {
     kind
@@ -6,25 +6,84 @@ import (
 	"github.com/graph-gophers/graphql-go/ast"
 )
 
+// Filter reports whether a type, or a field or input field of it, is visible in introspection queries.
+// fieldName is empty for the type itself. A nil Filter shows everything.
+type Filter func(typeName, fieldName string) bool
+
+func (f Filter) typeVisible(t ast.Type) bool {
+	if f == nil {
+		return true
+	}
+	for {
+		switch u := t.(type) {
+		case *ast.List:
+			t = u.OfType
+		case *ast.NonNull:
+			t = u.OfType
+		case ast.NamedType:
+			return f(u.TypeName(), "")
+		default:
+			return true
+		}
+	}
+}
+
+func (f Filter) fieldVisible(typeName, fieldName string, t ast.Type) bool {
+	return f == nil || f(typeName, fieldName) && f.typeVisible(t)
+}
+
+func (f Filter) wrapType(t ast.Type) *Type {
+	return &Type{typ: t, filter: f}
+}
+
+// inputValues returns the arguments, or the input fields of the input object typeName, which are visible.
+func (f Filter) inputValues(typeName string, values ast.ArgumentsDefinition, includeDeprecated bool) []*InputValue {
+	l := make([]*InputValue, 0, len(values))
+	for _, v := range values {
+		if typeName == "" && !f.typeVisible(v.Type) || typeName != "" && !f.fieldVisible(typeName, v.Name.Name, v.Type) {
+			continue
+		}
+		if d := v.Directives.Get("deprecated"); d == nil || includeDeprecated {
+			l = append(l, &InputValue{value: v, filter: f})
+		}
+	}
+	return l
+}
+
 type Schema struct {
 	schema *ast.Schema
+	filter Filter
 }
 
 // WrapSchema is only used internally.
 func WrapSchema(schema *ast.Schema) *Schema {
-	return &Schema{schema}
+	return &Schema{schema: schema}
+}
+
+// WrapSchemaFiltered is only used internally.
+func WrapSchemaFiltered(schema *ast.Schema, filter Filter) *Schema {
+	return &Schema{schema: schema, filter: filter}
+}
+
+func (r *Schema) Description() *string {
+	if r.schema.Desc == "" {
+		return nil
+	}
+	return &r.schema.Desc
 }
 
 func (r *Schema) Types() []*Type {
 	var names []string
-	for name := range r.schema.Types {
-		names = append(names, name)
+	for name, t := range r.schema.Types {
+		if r.filter.typeVisible(t) {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
 
 	l := make([]*Type, len(names))
 	for i, name := range names {
-		l[i] = &Type{r.schema.Types[name]}
+		l[i] = r.filter.wrapType(r.schema.Types[name])
 	}
 	return l
 }
@@ -38,7 +97,7 @@ func (r *Schema) Directives() []*Directive {
 
 	l := make([]*Directive, len(names))
 	for i, name := range names {
-		l[i] = &Directive{r.schema.Directives[name]}
+		l[i] = &Directive{directive: r.schema.Directives[name], filter: r.filter}
 	}
 	return l
 }
@@ -48,7 +107,7 @@ func (r *Schema) QueryType() *Type {
 	if !ok {
 		return nil
 	}
-	return &Type{t}
+	return r.filter.wrapType(t)
 }
 
 func (r *Schema) MutationType() *Type {
@@ -56,7 +115,7 @@ func (r *Schema) MutationType() *Type {
 	if !ok {
 		return nil
 	}
-	return &Type{t}
+	return r.filter.wrapType(t)
 }
 
 func (r *Schema) SubscriptionType() *Type {
@@ -64,16 +123,25 @@ func (r *Schema) SubscriptionType() *Type {
 	if !ok {
 		return nil
 	}
-	return &Type{t}
+	return r.filter.wrapType(t)
 }
 
 type Type struct {
-	typ ast.Type
+	typ    ast.Type
+	filter Filter
 }
 
 // WrapType is only used internally.
 func WrapType(typ ast.Type) *Type {
-	return &Type{typ}
+	return &Type{typ: typ}
+}
+
+// WrapTypeFiltered is only used internally.
+func WrapTypeFiltered(typ ast.Type, filter Filter) *Type {
+	if !filter.typeVisible(typ) {
+		return nil
+	}
+	return &Type{typ: typ, filter: filter}
 }
 
 func (r *Type) Kind() string {
@@ -100,20 +168,24 @@ func (r *Type) Description() *string {
 }
 
 func (r *Type) Fields(args *struct{ IncludeDeprecated bool }) *[]*Field {
+	var typeName string
 	var fields ast.FieldsDefinition
 	switch t := r.typ.(type) {
 	case *ast.ObjectTypeDefinition:
-		fields = t.Fields
+		typeName, fields = t.Name, t.Fields
 	case *ast.InterfaceTypeDefinition:
-		fields = t.Fields
+		typeName, fields = t.Name, t.Fields
 	default:
 		return nil
 	}
 
 	var l []*Field
 	for _, f := range fields {
+		if !r.filter.fieldVisible(typeName, f.Name, f.Type) {
+			continue
+		}
 		if d := f.Directives.Get("deprecated"); d == nil || args.IncludeDeprecated {
-			l = append(l, &Field{field: f})
+			l = append(l, &Field{field: f, filter: r.filter})
 		}
 	}
 	return &l
@@ -125,9 +197,11 @@ func (r *Type) Interfaces() *[]*Type {
 		return nil
 	}
 
-	l := make([]*Type, len(t.Interfaces))
-	for i, intf := range t.Interfaces {
-		l[i] = &Type{intf}
+	l := make([]*Type, 0, len(t.Interfaces))
+	for _, intf := range t.Interfaces {
+		if r.filter.typeVisible(intf) {
+			l = append(l, r.filter.wrapType(intf))
+		}
 	}
 	return &l
 }
@@ -143,9 +217,11 @@ func (r *Type) PossibleTypes() *[]*Type {
 		return nil
 	}
 
-	l := make([]*Type, len(possibleTypes))
-	for i, intf := range possibleTypes {
-		l[i] = &Type{intf}
+	l := make([]*Type, 0, len(possibleTypes))
+	for _, t := range possibleTypes {
+		if r.filter.typeVisible(t) {
+			l = append(l, r.filter.wrapType(t))
+		}
 	}
 	return &l
 }
@@ -159,31 +235,40 @@ func (r *Type) EnumValues(args *struct{ IncludeDeprecated bool }) *[]*EnumValue
 	var l []*EnumValue
 	for _, v := range t.EnumValuesDefinition {
 		if d := v.Directives.Get("deprecated"); d == nil || args.IncludeDeprecated {
-			l = append(l, &EnumValue{v})
+			l = append(l, &EnumValue{value: v})
 		}
 	}
 	return &l
 }
 
+// InputFields returns the input fields of an input object which are not deprecated.
 func (r *Type) InputFields() *[]*InputValue {
+	return r.InputFieldsWithDeprecated(&struct{ IncludeDeprecated bool }{})
+}
+
+// InputFieldsWithDeprecated resolves the inputFields field, which includes the deprecated input
+// fields if IncludeDeprecated is set.
+func (r *Type) InputFieldsWithDeprecated(args *struct{ IncludeDeprecated bool }) *[]*InputValue {
 	t, ok := r.typ.(*ast.InputObject)
 	if !ok {
 		return nil
 	}
 
-	l := make([]*InputValue, len(t.Values))
-	for i, v := range t.Values {
-		l[i] = &InputValue{v}
-	}
+	l := r.filter.inputValues(t.Name, t.Values, args.IncludeDeprecated)
 	return &l
 }
 
+// GraphQLFieldMethods maps the fields of __Type to the methods resolving them.
+func (*Type) GraphQLFieldMethods() map[string]string {
+	return map[string]string{"inputFields": "InputFieldsWithDeprecated"}
+}
+
 func (r *Type) OfType() *Type {
 	switch t := r.typ.(type) {
 	case *ast.List:
-		return &Type{t.OfType}
+		return r.filter.wrapType(t.OfType)
 	case *ast.NonNull:
-		return &Type{t.OfType}
+		return r.filter.wrapType(t.OfType)
 	default:
 		return nil
 	}
@@ -204,7 +289,8 @@ func (r *Type) SpecifiedByURL() *string {
 }
 
 type Field struct {
-	field *ast.FieldDefinition
+	field  *ast.FieldDefinition
+	filter Filter
 }
 
 func (r *Field) Name() string {
@@ -218,16 +304,24 @@ func (r *Field) Description() *string {
 	return &r.field.Desc
 }
 
+// Args returns the arguments of the field which are not deprecated.
 func (r *Field) Args() []*InputValue {
-	l := make([]*InputValue, len(r.field.Arguments))
-	for i, v := range r.field.Arguments {
-		l[i] = &InputValue{v}
-	}
-	return l
+	return r.ArgsWithDeprecated(&struct{ IncludeDeprecated bool }{})
+}
+
+// ArgsWithDeprecated resolves the args field, which includes the deprecated arguments if
+// IncludeDeprecated is set.
+func (r *Field) ArgsWithDeprecated(args *struct{ IncludeDeprecated bool }) []*InputValue {
+	return r.filter.inputValues("", r.field.Arguments, args.IncludeDeprecated)
+}
+
+// GraphQLFieldMethods maps the fields of __Field to the methods resolving them.
+func (*Field) GraphQLFieldMethods() map[string]string {
+	return map[string]string{"args": "ArgsWithDeprecated"}
 }
 
 func (r *Field) Type() *Type {
-	return &Type{r.field.Type}
+	return r.filter.wrapType(r.field.Type)
 }
 
 func (r *Field) IsDeprecated() bool {
@@ -244,7 +338,8 @@ func (r *Field) DeprecationReason() *string {
 }
 
 type InputValue struct {
-	value *ast.InputValueDefinition
+	value  *ast.InputValueDefinition
+	filter Filter
 }
 
 func (r *InputValue) Name() string {
@@ -259,7 +354,7 @@ func (r *InputValue) Description() *string {
 }
 
 func (r *InputValue) Type() *Type {
-	return &Type{r.value.Type}
+	return r.filter.wrapType(r.value.Type)
 }
 
 func (r *InputValue) DefaultValue() *string {
@@ -313,6 +408,7 @@ func (r *EnumValue) DeprecationReason() *string {
 
 type Directive struct {
 	directive *ast.DirectiveDefinition
+	filter    Filter
 }
 
 func (r *Directive) Name() string {
@@ -330,10 +426,18 @@ func (r *Directive) Locations() []string {
 	return r.directive.Locations
 }
 
+// Args returns the arguments of the directive which are not deprecated.
 func (r *Directive) Args() []*InputValue {
-	l := make([]*InputValue, len(r.directive.Arguments))
-	for i, v := range r.directive.Arguments {
-		l[i] = &InputValue{v}
-	}
-	return l
+	return r.ArgsWithDeprecated(&struct{ IncludeDeprecated bool }{})
+}
+
+// ArgsWithDeprecated resolves the args field, which includes the deprecated arguments if
+// IncludeDeprecated is set.
+func (r *Directive) ArgsWithDeprecated(args *struct{ IncludeDeprecated bool }) []*InputValue {
+	return r.filter.inputValues("", r.directive.Arguments, args.IncludeDeprecated)
+}
+
+// GraphQLFieldMethods maps the fields of __Directive to the methods resolving them.
+func (*Directive) GraphQLFieldMethods() map[string]string {
+	return map[string]string{"args": "ArgsWithDeprecated"}
 }
@@ -40,6 +40,11 @@ func TestSchema_ToJSON(t *testing.T) {
 			Args: args{Schema: graphql.MustParseSchema(starwars.Schema, nil)},
 			Want: want{JSON: mustReadFile("example/starwars/introspect.json")},
 		},
+		{
+			Name: "Star Wars Schema with disabled introspection",
+			Args: args{Schema: graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.DisableIntrospection())},
+			Want: want{JSON: mustReadFile("example/starwars/introspect.json")},
+		},
 	}
 
 	for _, tt := range testTable {
@@ -87,3 +92,118 @@ func mustReadFile(filename string) []byte {
 	}
 	return b
 }
+
+func TestSchema_SDL(t *testing.T) {
+	t.Parallel()
+
+	const sdl = `"""The schema of a blog."""
+schema @tag(name: "blog") {
+  query: Query
+}
+
+"""Marks the owner of a field."""
+directive @owner(
+  """The team owning the field."""
+  team: String!
+) repeatable on FIELD_DEFINITION | OBJECT
+
+"""
+The root query type.
+
+It has a multi-line description,
+  which keeps its indentation.
+"""
+type Query {
+  """Fetches a post by its id."""
+  post(
+    """The id of the post."""
+    id: ID!
+    draft: Boolean = false
+  ): Post @owner(team: "content")
+  "Title with \"quotes\"."
+  titles(first: Int = 10, tags: [String!] = ["a", "b"]): [String!]! @deprecated(reason: "Use post")
+}
+
+directive @tag(name: String!) on SCHEMA
+
+type Post implements Node {
+  id: ID!
+  kind: Kind!
+}
+
+interface Node {
+  id: ID!
+}
+
+enum Kind {
+  """A regular post."""
+  ARTICLE
+  LINK @deprecated
+}
+
+scalar Time @specifiedBy(url: "https://example.com/time")
+
+union Result = Post
+
+input PostFilter {
+  """Posts created after the time."""
+  after: Time
+  kinds: [Kind!] = [ARTICLE]
+}
+`
+	s := graphql.MustParseSchema(sdl, nil, graphql.UseStringDescriptions())
+	if got := s.SDL(); got != sdl {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, sdl)
+	}
+
+	legacy := graphql.MustParseSchema(sdl, nil)
+	again := graphql.MustParseSchema(legacy.SDL(), nil)
+	if got, want := again.SDL(), legacy.SDL(); got != want {
+		t.Errorf("comment descriptions do not round-trip, got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSchema_SDL_CommentDescriptions(t *testing.T) {
+	t.Parallel()
+
+	s := graphql.MustParseSchema(`
+		# The root query type.
+		#
+		#  Indented line.
+		type Query {
+			"""Docstrings are kept without string descriptions."""
+			hello: String!
+			# Comments win over docstrings.
+			"ignored"
+			bye: String!
+		}
+	`, nil)
+	want := `# The root query type.
+#
+#  Indented line.
+type Query {
+  # Docstrings are kept without string descriptions.
+  hello: String!
+  # Comments win over docstrings.
+  bye: String!
+}
+`
+	if got := s.SDL(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSchema_Hash(t *testing.T) {
+	t.Parallel()
+
+	a := graphql.MustParseSchema(starwars.Schema, nil)
+	b := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	if a.Hash() != b.Hash() || len(a.Hash()) != 64 {
+		t.Errorf("got hashes %q and %q for the same schema, want equal SHA-256 hashes", a.Hash(), b.Hash())
+	}
+
+	c := graphql.MustParseSchema(starwars.Schema+"\nextend type Query { version: String }", nil)
+	if c.Hash() == a.Hash() {
+		t.Errorf("got hash %q for a changed schema, want another hash", c.Hash())
+	}
+}
@@ -0,0 +1,207 @@
+// Package jsonschema converts the input types of a GraphQL schema into JSON Schema (draft 2020-12),
+// e.g. to validate variables outside of GraphQL or to document them in an OpenAPI 3.1 document:
+//
+//	doc, err := jsonschema.Export(schema.AST(), jsonschema.Config{RefPrefix: "#/components/schemas/"})
+//
+// Input objects and enums become definitions, scalars are inlined where they are used.
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/ast"
+)
+
+// Draft is the JSON Schema dialect of the exported documents.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Only the keywords needed to describe GraphQL input types are supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"` // a string or a list of strings
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Config configures the export.
+type Config struct {
+	// RefPrefix is prepended to the names of input objects and enums in references. It defaults to
+	// "#/$defs/", the definitions of the exported document. For OpenAPI, use "#/components/schemas/"
+	// and copy the definitions to the components.
+	RefPrefix string
+
+	// Scalars maps custom scalars to their JSON Schema. Custom scalars which are not mapped and not
+	// provided by package graphql accept any value.
+	Scalars map[string]*Schema
+}
+
+var builtinScalars = map[string]func() *Schema{
+	"Int": func() *Schema {
+		min, max := float64(math.MinInt32), float64(math.MaxInt32)
+		return &Schema{Type: "integer", Minimum: &min, Maximum: &max}
+	},
+	"Float":    func() *Schema { return &Schema{Type: "number"} },
+	"String":   func() *Schema { return &Schema{Type: "string"} },
+	"Boolean":  func() *Schema { return &Schema{Type: "boolean"} },
+	"ID":       func() *Schema { return &Schema{Type: []string{"string", "integer"}} },
+	"Time":     func() *Schema { return &Schema{Type: "string", Format: "date-time"} },
+	"UnixTime": func() *Schema { return &Schema{Type: []string{"integer", "string"}} },
+	"Decimal":  func() *Schema { return &Schema{Type: []string{"string", "number"}} },
+	"Money":    func() *Schema { return &Schema{Type: "string"} },
+	"Upload":   func() *Schema { return &Schema{Type: "string", Format: "binary"} },
+}
+
+// Export returns a document whose definitions describe the input objects and enums of s.
+func Export(s *ast.Schema, cfg Config) (*Schema, error) {
+	if cfg.RefPrefix == "" {
+		cfg.RefPrefix = "#/$defs/"
+	}
+	e := &exporter{cfg: cfg}
+	doc := &Schema{Schema: Draft, Defs: make(map[string]*Schema)}
+
+	names := make([]string, 0, len(s.Types))
+	for name := range s.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch t := s.Types[name].(type) {
+		case *ast.InputObject:
+			def, err := e.inputObject(t)
+			if err != nil {
+				return nil, err
+			}
+			doc.Defs[name] = def
+		case *ast.EnumTypeDefinition:
+			if !strings.HasPrefix(name, "__") {
+				doc.Defs[name] = enum(t)
+			}
+		}
+	}
+	return doc, nil
+}
+
+// Type returns the JSON Schema of a single input type, e.g. the type of a variable or argument.
+// Input objects and enums are references to the definitions of the document returned by Export.
+func Type(t ast.Type, cfg Config) (*Schema, error) {
+	if cfg.RefPrefix == "" {
+		cfg.RefPrefix = "#/$defs/"
+	}
+	e := &exporter{cfg: cfg}
+	return e.typ(t)
+}
+
+type exporter struct {
+	cfg Config
+}
+
+func (e *exporter) inputObject(t *ast.InputObject) (*Schema, error) {
+	noAdditional := false
+	def := &Schema{
+		Title:                t.Name,
+		Description:          t.Desc,
+		Type:                 "object",
+		Properties:           make(map[string]*Schema, len(t.Values)),
+		AdditionalProperties: &noAdditional,
+	}
+	for _, v := range t.Values {
+		prop, err := e.typ(v.Type)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: %s.%s: %w", t.Name, v.Name.Name, err)
+		}
+		if v.Desc != "" {
+			prop.Description = v.Desc
+		}
+		if v.Default != nil {
+			prop.Default = v.Default.Deserialize(nil)
+		}
+		if v.Directives.Get("deprecated") != nil {
+			prop.Deprecated = true
+		}
+		def.Properties[v.Name.Name] = prop
+		if _, ok := v.Type.(*ast.NonNull); ok && v.Default == nil {
+			def.Required = append(def.Required, v.Name.Name)
+		}
+	}
+	return def, nil
+}
+
+func enum(t *ast.EnumTypeDefinition) *Schema {
+	def := &Schema{Title: t.Name, Description: t.Desc, Type: "string"}
+	for _, v := range t.EnumValuesDefinition {
+		def.Enum = append(def.Enum, v.EnumValue)
+	}
+	return def
+}
+
+func (e *exporter) typ(t ast.Type) (*Schema, error) {
+	nonNull := false
+	if nn, ok := t.(*ast.NonNull); ok {
+		t, nonNull = nn.OfType, true
+	}
+
+	var s *Schema
+	switch t := t.(type) {
+	case *ast.List:
+		items, err := e.typ(t.OfType)
+		if err != nil {
+			return nil, err
+		}
+		s = &Schema{Type: "array", Items: items}
+	case *ast.InputObject, *ast.EnumTypeDefinition:
+		s = &Schema{Ref: e.cfg.RefPrefix + t.String()}
+	case *ast.ScalarTypeDefinition:
+		if custom, ok := e.cfg.Scalars[t.Name]; ok {
+			c := *custom
+			s = &c
+		} else if builtin, ok := builtinScalars[t.Name]; ok {
+			s = builtin()
+		} else {
+			s = &Schema{Description: t.Desc}
+		}
+	default:
+		return nil, fmt.Errorf("type %s of kind %s can not be used as input", t, t.Kind())
+	}
+
+	if nonNull {
+		return s, nil
+	}
+	return nullable(s), nil
+}
+
+// nullable allows null in addition to the values of s.
+func nullable(s *Schema) *Schema {
+	switch typ := s.Type.(type) {
+	case string:
+		if s.Ref == "" && s.Enum == nil {
+			s.Type = []string{typ, "null"}
+			return s
+		}
+	case []string:
+		if s.Ref == "" && s.Enum == nil {
+			s.Type = append(typ[:len(typ):len(typ)], "null")
+			return s
+		}
+	case nil:
+		if s.Ref == "" && s.AnyOf == nil && s.Enum == nil {
+			return s // any value, including null
+		}
+	}
+	return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+}
@@ -0,0 +1,102 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/jsonschema"
+)
+
+const schema = `
+	scalar Time
+	scalar Point
+
+	enum Episode {
+		NEWHOPE
+		EMPIRE
+	}
+
+	type Query {
+		reviews(filter: ReviewFilter): [String!]!
+	}
+
+	# ReviewFilter selects reviews.
+	input ReviewFilter {
+		episode: Episode!
+		stars: Int = 5
+		# Only reviews created after this instant.
+		after: Time
+		tags: [String!]
+		near: Point
+		old: Boolean @deprecated
+	}
+`
+
+func TestExport(t *testing.T) {
+	s := graphql.MustParseSchema(schema, nil)
+	doc, err := jsonschema.Export(s.AST(), jsonschema.Config{
+		Scalars: map[string]*jsonschema.Schema{
+			"Point": {Type: "array", Items: &jsonschema.Schema{Type: "number"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$defs": {
+			"Episode": {"title": "Episode", "type": "string", "enum": ["NEWHOPE", "EMPIRE"]},
+			"ReviewFilter": {
+				"title": "ReviewFilter",
+				"description": "ReviewFilter selects reviews.",
+				"type": "object",
+				"properties": {
+					"episode": {"$ref": "#/$defs/Episode"},
+					"stars": {"type": ["integer", "null"], "minimum": -2147483648, "maximum": 2147483647, "default": 5},
+					"after": {"description": "Only reviews created after this instant.", "type": ["string", "null"], "format": "date-time"},
+					"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+					"near": {"type": ["array", "null"], "items": {"type": "number"}},
+					"old": {"type": ["boolean", "null"], "deprecated": true}
+				},
+				"required": ["episode"],
+				"additionalProperties": false
+			}
+		}
+	}`
+	assertEqualJSON(t, want, got)
+}
+
+func TestType(t *testing.T) {
+	s := graphql.MustParseSchema(schema, nil)
+	arg := s.AST().Types["Query"].(*ast.ObjectTypeDefinition).Fields.Get("reviews").Arguments.Get("filter")
+	typ, err := jsonschema.Type(arg.Type, jsonschema.Config{RefPrefix: "#/components/schemas/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualJSON(t, `{"anyOf": [{"$ref": "#/components/schemas/ReviewFilter"}, {"type": "null"}]}`, got)
+}
+
+func assertEqualJSON(t *testing.T, want string, got []byte) {
+	t.Helper()
+	var w, g interface{}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(w, g) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
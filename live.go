@@ -0,0 +1,190 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/exec"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// liveDirective is the name of the directive marking queries which are re-executed once their data
+// changed.
+const liveDirective = "live"
+
+// InvalidationBus signals live queries that data changed, see [LiveQueries]. The live package
+// provides an in-process implementation; buses spanning several servers can be built on message
+// brokers.
+type InvalidationBus interface {
+	// Subscribe returns a channel receiving the keys of changed data until ctx is done. A live query
+	// ends once the channel is closed.
+	Subscribe(ctx context.Context) (<-chan []string, error)
+}
+
+// LiveQueries enables live queries. Queries marked with the @live directive, which has to be declared
+// by the schema, are executed like subscriptions by [Schema.Subscribe] and [Schema.Do]:
+//
+//	directive @live on QUERY
+//
+//	query @live {
+//		todos { id text done }
+//	}
+//
+// The query is executed once and again whenever bus signals a change of the data it depends on, and
+// each changed result is sent as a new response. A query depends on the coordinates of its root fields,
+// e.g. "Query.todos", and on the keys its resolvers report with [LiveDependency]. Without this option
+// the directive is ignored and live queries are executed once.
+func LiveQueries(bus InvalidationBus) SchemaOpt {
+	return func(s *Schema) {
+		s.invalidationBus = bus
+	}
+}
+
+type liveDependenciesKey struct{}
+
+// liveDependencies collects the keys an execution of a live query depends on.
+type liveDependencies struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func (d *liveDependencies) add(keys ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, k := range keys {
+		d.keys[k] = struct{}{}
+	}
+}
+
+// changed reports whether any of keys is a dependency.
+func (d *liveDependencies) changed(keys []string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, k := range keys {
+		if _, ok := d.keys[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LiveDependency reports that the result of the resolver called with ctx depends on the data
+// identified by keys, e.g. "Todo:42", so that live queries are re-executed once the keys are
+// invalidated. It does nothing outside of live queries.
+func LiveDependency(ctx context.Context, keys ...string) {
+	if d, ok := ctx.Value(liveDependenciesKey{}).(*liveDependencies); ok {
+		d.add(keys...)
+	}
+}
+
+// isLive reports whether op is a live query of a schema with live queries enabled.
+func (s *Schema) isLive(op *ast.OperationDefinition) bool {
+	return s.invalidationBus != nil && op.Type == query.Query && op.Directives.Get(liveDirective) != nil
+}
+
+// liveQuery executes the live query op of doc whenever its dependencies changed. Each execution uses a new
+// request of newRequest. Results equal to the previous one are not sent.
+func (s *Schema) liveQuery(ctx context.Context, doc *ast.ExecutableDefinition, newRequest func() *exec.Request, res *resolvable.Schema, op *ast.OperationDefinition, queryString string, warnings []*qerrors.QueryError) <-chan interface{} {
+	ctx, done, err := s.subscriptions.add(ctx)
+	if err != nil {
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
+	}
+	invalidations, err := s.invalidationBus.Subscribe(ctx)
+	if err != nil {
+		done()
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
+	}
+	roots := s.rootCoordinates(doc, op)
+
+	c := make(chan interface{})
+	go func() {
+		defer close(c)
+		defer done()
+		var last []byte
+		for {
+			deps := &liveDependencies{keys: make(map[string]struct{})}
+			deps.add(roots...)
+			r := newRequest()
+			data, errs, _ := s.execute(context.WithValue(ctx, liveDependenciesKey{}, deps), r, res, op, queryString)
+			if ctx.Err() != nil {
+				return
+			}
+			if len(errs) != 0 || last == nil || !bytes.Equal(data, last) {
+				resp := &Response{Data: data, Errors: errs}
+				warnings = append(warnings, r.Warnings()...)
+				if len(warnings) != 0 {
+					resp.setExtension(warningsExtension, warnings)
+					warnings = nil
+				}
+				select {
+				case c <- resp:
+				case <-ctx.Done():
+					return
+				}
+				last = data
+				if len(errs) != 0 {
+					last = nil
+				}
+			}
+
+			for changed := false; !changed; {
+				select {
+				case keys, ok := <-invalidations:
+					if !ok {
+						return
+					}
+					changed = deps.changed(keys)
+				case <-ctx.Done():
+					return
+				}
+			}
+			// the execution covers the changes signalled in the meantime
+			for drained := false; !drained; {
+				select {
+				case _, ok := <-invalidations:
+					if !ok {
+						return
+					}
+				default:
+					drained = true
+				}
+			}
+		}
+	}()
+	return c
+}
+
+// rootCoordinates returns the coordinates of the root fields selected by the query op of doc, e.g.
+// "Query.todos".
+func (s *Schema) rootCoordinates(doc *ast.ExecutableDefinition, op *ast.OperationDefinition) []string {
+	typeName := s.schema.RootOperationTypes["query"].TypeName()
+	var coords []string
+	seen := make(map[string]bool)
+	var collect func(sels ast.SelectionSet)
+	collect = func(sels ast.SelectionSet) {
+		for _, sel := range sels {
+			switch sel := sel.(type) {
+			case *ast.Field:
+				if coord := typeName + "." + sel.Name.Name; !seen[coord] {
+					seen[coord] = true
+					coords = append(coords, coord)
+				}
+			case *ast.InlineFragment:
+				collect(sel.Selections)
+			case *ast.FragmentSpread:
+				if !seen["..."+sel.Name.Name] {
+					seen["..."+sel.Name.Name] = true
+					if f := doc.Fragments.Get(sel.Name.Name); f != nil {
+						collect(f.Selections)
+					}
+				}
+			}
+		}
+	}
+	collect(op.Selections)
+	return coords
+}
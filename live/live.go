@@ -0,0 +1,75 @@
+// Package live provides an in-process invalidation bus for the live queries of the
+// graphql.LiveQueries schema option:
+//
+//	invalidations := live.New()
+//	schema := graphql.MustParseSchema(sdl, resolver, graphql.LiveQueries(invalidations))
+//
+// Mutation resolvers invalidate the data they changed, by the coordinates of root fields or by the keys
+// reported with graphql.LiveDependency:
+//
+//	invalidations.Invalidate("Query.todos", "Todo:"+string(args.ID))
+package live
+
+import (
+	"context"
+	"sync"
+)
+
+// Bus delivers invalidated keys to all live queries. It is safe for concurrent use. The zero value is
+// not usable, use [New] instead.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan []string]struct{}
+}
+
+// New returns a Bus without subscribers.
+func New() *Bus {
+	return &Bus{subscribers: make(map[chan []string]struct{})}
+}
+
+// Subscribe returns a channel receiving the invalidated keys until ctx is done. Then the channel is
+// closed.
+func (b *Bus) Subscribe(ctx context.Context) (<-chan []string, error) {
+	c := make(chan []string, 1)
+	b.mu.Lock()
+	b.subscribers[c] = struct{}{}
+	b.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.subscribers, c)
+		close(c)
+		b.mu.Unlock()
+	}()
+	return c, nil
+}
+
+// Invalidate signals that the data identified by keys changed. It does not block: the keys of
+// subscribers which did not receive the previous invalidation yet are merged with it.
+func (b *Bus) Invalidate(keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for c := range b.subscribers {
+		select {
+		case c <- keys:
+		default:
+			// only Invalidate sends, so the buffer is free once the pending keys were taken
+			select {
+			case pending := <-c:
+				c <- append(append([]string(nil), pending...), keys...)
+			default:
+				c <- keys
+			}
+		}
+	}
+}
+
+// Subscribers returns the number of active subscribers, e.g. live queries.
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
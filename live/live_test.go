@@ -0,0 +1,41 @@
+package live_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/live"
+)
+
+func TestBus(t *testing.T) {
+	b := live.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := b.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := b.Subscribers(); n != 1 {
+		t.Errorf("got %d subscribers, want 1", n)
+	}
+
+	// the keys of invalidations not received yet are merged instead of blocking
+	b.Invalidate("Query.todos")
+	b.Invalidate("Todo:1", "Todo:2")
+	b.Invalidate()
+	if got, want := <-c, []string{"Query.todos", "Todo:1", "Todo:2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %q, want %q", got, want)
+	}
+	b.Invalidate("Todo:3")
+	if got, want := <-c, []string{"Todo:3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %q, want %q", got, want)
+	}
+
+	cancel()
+	if _, ok := <-c; ok {
+		t.Error("expected the channel to be closed")
+	}
+	if n := b.Subscribers(); n != 0 {
+		t.Errorf("got %d subscribers, want 0", n)
+	}
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+)
+
+// RequestLogger is the interface used to log executed operations. It is settable via graphql.LogRequests.
+type RequestLogger interface {
+	LogRequest(ctx context.Context, req Request)
+}
+
+// Request describes an executed operation.
+type Request struct {
+	// OperationName is the name of the executed operation, which is also known if the client did
+	// not request it by name. It is empty for anonymous operations.
+	OperationName string
+	Duration      time.Duration
+	ErrorCount    int
+	// Variables are the variables of the operation with the values of the redacted keys replaced
+	// by [Redacted].
+	Variables map[string]interface{}
+}
+
+// Redacted replaces the values of redacted variables and input fields.
+const Redacted = "[REDACTED]"
+
+// LogRequest is used to log executed operations.
+func (l *DefaultLogger) LogRequest(ctx context.Context, req Request) {
+	vars, err := json.Marshal(req.Variables)
+	if err != nil {
+		vars = []byte(err.Error())
+	}
+	log.Printf("graphql: operation %q took %s with %d errors, variables: %s", req.OperationName, req.Duration, req.ErrorCount, vars)
+}
+
+// Redact returns a copy of variables in which the values of the given keys are replaced by [Redacted].
+// Keys are matched case-insensitively at any depth, e.g. "password" also redacts the password field of
+// an input object.
+func Redact(variables map[string]interface{}, keys []string) map[string]interface{} {
+	if variables == nil || len(keys) == 0 {
+		return variables
+	}
+	redacted := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		redacted[strings.ToLower(k)] = struct{}{}
+	}
+	return redactValue(variables, redacted).(map[string]interface{})
+}
+
+func redactValue(v interface{}, redacted map[string]struct{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, fv := range v {
+			if _, ok := redacted[strings.ToLower(k)]; ok {
+				m[k] = Redacted
+				continue
+			}
+			m[k] = redactValue(fv, redacted)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, ev := range v {
+			l[i] = redactValue(ev, redacted)
+		}
+		return l
+	default:
+		return v
+	}
+}
@@ -0,0 +1,39 @@
+// Package metrics defines the interface used to count events during query execution, which adapters
+// of metrics libraries such as Prometheus or OpenTelemetry implement. It is settable via
+// graphql.Metrics.
+package metrics
+
+import "context"
+
+// Counter names reported by the library.
+const (
+	// InputCoercionFailures counts argument values which can not be coerced into the Go type of the
+	// resolver. The counter has the labels [LabelType], [LabelField], [LabelArgument] and
+	// [LabelExpectedType].
+	InputCoercionFailures = "graphql_input_coercion_failures"
+)
+
+// Label names of the counters.
+const (
+	// LabelType is the name of the object type of the field.
+	LabelType = "type"
+	// LabelField is the name of the field.
+	LabelField = "field"
+	// LabelArgument is the name of the argument whose value could not be coerced.
+	LabelArgument = "argument"
+	// LabelExpectedType is the GraphQL type which the malformed value was expected to have, e.g.
+	// "Int!" for an element of a [Int!]! argument.
+	LabelExpectedType = "expected_type"
+)
+
+// Counters increments counters of events during query execution. The context is the one of the
+// request, so implementations may derive additional labels from it, e.g. to identify the client.
+type Counters interface {
+	IncCounter(ctx context.Context, name string, labels map[string]string)
+}
+
+// NoopCounters is a [Counters] which discards all events.
+type NoopCounters struct{}
+
+// IncCounter does nothing.
+func (NoopCounters) IncCounter(ctx context.Context, name string, labels map[string]string) {}
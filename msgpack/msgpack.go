@@ -0,0 +1,127 @@
+// Package msgpack encodes GraphQL responses as MessagePack (https://msgpack.org), e.g. for mobile
+// clients which want smaller payloads than JSON:
+//
+//	schema := graphql.MustParseSchema(sdl, resolver, graphql.ResponseFormat(msgpack.ContentType, msgpack.Encoder{}))
+//
+// Values are encoded like in JSON and then converted. Numbers become integers if they are whole numbers
+// within the range of 64-bit integers and floats otherwise.
+package msgpack
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/graph-gophers/graphql-go/internal/transcode"
+)
+
+// ContentType is the media type of MessagePack.
+const ContentType = "application/msgpack"
+
+// Encoder is a [graphql.Encoder] which encodes values as MessagePack.
+type Encoder struct{}
+
+// Marshal returns the MessagePack encoding of v.
+func (Encoder) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var w writer
+	if err := transcode.JSON(data, &w); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+type writer struct {
+	buf []byte
+}
+
+func (w *writer) Null() {
+	w.buf = append(w.buf, 0xc0)
+}
+
+func (w *writer) Bool(b bool) {
+	if b {
+		w.buf = append(w.buf, 0xc3)
+	} else {
+		w.buf = append(w.buf, 0xc2)
+	}
+}
+
+func (w *writer) Int(i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f: // positive fixint
+		w.buf = append(w.buf, byte(i))
+	case i >= -32 && i < 0: // negative fixint
+		w.buf = append(w.buf, byte(i))
+	case i > 0:
+		switch {
+		case i <= math.MaxUint8:
+			w.code(0xcc, uint64(i), 1)
+		case i <= math.MaxUint16:
+			w.code(0xcd, uint64(i), 2)
+		case i <= math.MaxUint32:
+			w.code(0xce, uint64(i), 4)
+		default:
+			w.code(0xcf, uint64(i), 8)
+		}
+	case i >= math.MinInt8:
+		w.code(0xd0, uint64(i), 1)
+	case i >= math.MinInt16:
+		w.code(0xd1, uint64(i), 2)
+	case i >= math.MinInt32:
+		w.code(0xd2, uint64(i), 4)
+	default:
+		w.code(0xd3, uint64(i), 8)
+	}
+}
+
+func (w *writer) Float(f float64) {
+	w.code(0xcb, math.Float64bits(f), 8)
+}
+
+func (w *writer) String(s string) {
+	n := len(s)
+	switch {
+	case n <= 31:
+		w.buf = append(w.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		w.code(0xd9, uint64(n), 1)
+	case n <= math.MaxUint16:
+		w.code(0xda, uint64(n), 2)
+	default:
+		w.code(0xdb, uint64(n), 4)
+	}
+	w.buf = append(w.buf, s...)
+}
+
+func (w *writer) Array(n int) {
+	switch {
+	case n <= 15:
+		w.buf = append(w.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		w.code(0xdc, uint64(n), 2)
+	default:
+		w.code(0xdd, uint64(n), 4)
+	}
+}
+
+func (w *writer) Map(n int) {
+	switch {
+	case n <= 15:
+		w.buf = append(w.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		w.code(0xde, uint64(n), 2)
+	default:
+		w.code(0xdf, uint64(n), 4)
+	}
+}
+
+// code writes the format code c followed by the size lowest bytes of n in big-endian order.
+func (w *writer) code(c byte, n uint64, size int) {
+	w.buf = append(w.buf, c)
+	for i := size - 1; i >= 0; i-- {
+		w.buf = append(w.buf, byte(n>>(8*i)))
+	}
+}
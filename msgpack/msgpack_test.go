@@ -0,0 +1,39 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go/msgpack"
+)
+
+func TestMarshal(t *testing.T) {
+	for _, tc := range []struct {
+		value interface{}
+		want  []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{false, []byte{0xc2}},
+		{7, []byte{0x07}},
+		{-3, []byte{0xfd}},
+		{200, []byte{0xcc, 0xc8}},
+		{70000, []byte{0xce, 0x00, 0x01, 0x11, 0x70}},
+		{-200, []byte{0xd1, 0xff, 0x38}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"hi", []byte{0xa2, 'h', 'i'}},
+		{strings.Repeat("a", 40), append([]byte{0xd9, 40}, strings.Repeat("a", 40)...)},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{json.RawMessage(`{"b":1,"a":[null]}`), []byte{0x82, 0xa1, 'b', 0x01, 0xa1, 'a', 0x91, 0xc0}},
+	} {
+		got, err := msgpack.Encoder{}.Marshal(tc.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, tc.want) {
+			t.Errorf("Marshal(%v) = % x, want % x", tc.value, got, tc.want)
+		}
+	}
+}
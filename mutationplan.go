@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+)
+
+// parallelDirective is the name of the directive marking mutation fields which may be executed
+// concurrently with their neighbours.
+const parallelDirective = "parallel"
+
+// MutationField is a root field selected by a mutation, see [MutationPlanner].
+type MutationField struct {
+	// Name is the name of the field in the schema.
+	Name string
+	// Alias is the key of the field in the response.
+	Alias string
+	// Directives are the directives of the definition of the field in the schema, e.g. to read
+	// dependency hints of a custom planner.
+	Directives ast.DirectiveList
+}
+
+// Parallel reports whether the definition of the field has the @parallel directive.
+func (f MutationField) Parallel() bool {
+	return f.Directives.Get(parallelDirective) != nil
+}
+
+// MutationPlanner splits the root fields of a mutation, given in the order of the query, into stages.
+// The stages are executed one after another, while the fields of a stage are executed concurrently. It
+// returns the number of fields of each stage, e.g. []int{1, 3, 1}. Once the sizes do not match the
+// fields, the remaining fields are executed serially as the GraphQL specification requires.
+type MutationPlanner func(fields []MutationField) []int
+
+// PlanMutations makes the schema execute the root fields of mutations in the stages returned by p instead
+// of [ParallelMutations], e.g. for bulk-write APIs whose planner knows which mutations are independent.
+func PlanMutations(p MutationPlanner) SchemaOpt {
+	return func(s *Schema) {
+		s.mutationPlanner = p
+	}
+}
+
+// ParallelMutations is the default [MutationPlanner]. It executes consecutive fields whose definitions have
+// the @parallel directive concurrently and all other fields serially. The directive asserts that a
+// mutation is independent of the mutations next to it and has to be declared by the schema:
+//
+//	directive @parallel on FIELD_DEFINITION
+//
+//	type Mutation {
+//		addTag(name: String!): Tag! @parallel
+//	}
+func ParallelMutations(fields []MutationField) []int {
+	var stages []int
+	for i, f := range fields {
+		if f.Parallel() && i > 0 && fields[i-1].Parallel() {
+			stages[len(stages)-1]++
+			continue
+		}
+		stages = append(stages, 1)
+	}
+	return stages
+}
+
+// planMutation calls the mutation planner of the schema with the root fields of a mutation.
+func (s *Schema) planMutation(sels []*selected.SchemaField) []int {
+	fields := make([]MutationField, len(sels))
+	for i, f := range sels {
+		fields[i] = MutationField{Name: f.Name, Alias: f.Alias, Directives: f.Directives}
+	}
+	if s.mutationPlanner != nil {
+		return s.mutationPlanner(fields)
+	}
+	return ParallelMutations(fields)
+}
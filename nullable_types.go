@@ -120,6 +120,15 @@ func (s *NullInt) UnmarshalGraphQL(input interface{}) error {
 		}
 		s.Value = &coerced
 		return nil
+	case int:
+		return s.UnmarshalGraphQL(int64(v))
+	case int64:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return fmt.Errorf("not a 32-bit integer")
+		}
+		coerced := int32(v)
+		s.Value = &coerced
+		return nil
 	default:
 		return fmt.Errorf("wrong type for Int: %T", v)
 	}
@@ -158,6 +167,10 @@ func (s *NullFloat) UnmarshalGraphQL(input interface{}) error {
 		coerced := float64(v)
 		s.Value = &coerced
 		return nil
+	case int64:
+		coerced := float64(v)
+		s.Value = &coerced
+		return nil
 	default:
 		return fmt.Errorf("wrong type for Float: %T", v)
 	}
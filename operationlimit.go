@@ -0,0 +1,66 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// ErrTooManyOperations is wrapped by the error of operations rejected by [MaxConcurrentOperations].
+var ErrTooManyOperations = errors.New("graphql: too many concurrent operations")
+
+// OverloadPolicy decides what happens to the operations arriving while the maximum number of
+// operations of [MaxConcurrentOperations] are executing.
+type OverloadPolicy int
+
+const (
+	// QueueOperations makes operations wait until another operation finished. Operations whose context
+	// is done while they wait return the error of the context.
+	QueueOperations OverloadPolicy = iota
+	// RejectOperations rejects operations immediately with an error wrapping [ErrTooManyOperations].
+	RejectOperations
+)
+
+// MaxConcurrentOperations limits the number of queries and mutations executed by the schema at the same
+// time to n, e.g. to protect backends when a flood of expensive queries arrives simultaneously. Once the
+// limit is reached, further operations are queued or rejected according to policy. Subscriptions are not
+// limited. The default is 0 which disables the limit.
+func MaxConcurrentOperations(n int, policy OverloadPolicy) SchemaOpt {
+	return func(s *Schema) {
+		s.operationSlots = nil
+		if n > 0 {
+			s.operationSlots = make(chan struct{}, n)
+		}
+		s.overloadPolicy = policy
+	}
+}
+
+// acquireOperation takes one of the slots of [MaxConcurrentOperations], if the limit is set. It returns
+// the function which releases the slot or the error of the operation if it did not get a slot.
+func (s *Schema) acquireOperation(ctx context.Context) (func(), *qerrors.QueryError) {
+	if s.operationSlots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-s.operationSlots }
+	select {
+	case s.operationSlots <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if s.overloadPolicy == RejectOperations {
+		err := qerrors.Errorf("too many concurrent operations, try again later")
+		err.Err = ErrTooManyOperations
+		return nil, err.SetCode(qerrors.CodeTooManyOperations)
+	}
+	select {
+	case s.operationSlots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		err := qerrors.Errorf("%s", ctx.Err())
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, err.SetCode(qerrors.CodeTimeout)
+		}
+		return nil, err.SetCode(qerrors.CodeCancelled)
+	}
+}
@@ -0,0 +1,109 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// OperationInfo describes an operation in flight, see [Schema.ActiveOperations].
+type OperationInfo struct {
+	// ID identifies the operation, e.g. to cancel it with [Schema.Cancel].
+	ID uint64
+	// Type is "query", "mutation" or "subscription".
+	Type string
+	// Name is the name of the operation. It is empty for anonymous operations.
+	Name string
+	// Query is the document containing the operation.
+	Query string
+	// Start is the time the execution of the operation started.
+	Start time.Time
+}
+
+// operationRegistry keeps track of the operations in flight. The zero value is ready to use.
+type operationRegistry struct {
+	mu   sync.Mutex
+	next uint64
+	ops  map[uint64]*activeOperation
+}
+
+type activeOperation struct {
+	info      OperationInfo
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// start registers the operation op of the document queryString. It returns the context to execute
+// the operation with, which is cancelled by [Schema.Cancel].
+func (r *operationRegistry) start(ctx context.Context, op *ast.OperationDefinition, queryString string) (context.Context, *activeOperation) {
+	ctx, cancel := context.WithCancel(ctx)
+	a := &activeOperation{
+		info: OperationInfo{
+			Type:  strings.ToLower(string(op.Type)),
+			Name:  op.Name.Name,
+			Query: queryString,
+			Start: time.Now(),
+		},
+		cancel: cancel,
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ops == nil {
+		r.ops = make(map[uint64]*activeOperation)
+	}
+	r.next++
+	a.info.ID = r.next
+	r.ops[a.info.ID] = a
+	return ctx, a
+}
+
+// finish removes the operation a once it is no longer in flight and reports whether it was cancelled.
+func (r *operationRegistry) finish(a *activeOperation) (cancelled bool) {
+	r.mu.Lock()
+	delete(r.ops, a.info.ID)
+	cancelled = a.cancelled
+	r.mu.Unlock()
+	a.cancel()
+	return cancelled
+}
+
+// ActiveOperations returns the queries, mutations and subscriptions which are executed by the schema,
+// ordered by the start of their execution, e.g. for an admin endpoint listing runaway queries.
+func (s *Schema) ActiveOperations() []OperationInfo {
+	s.operations.mu.Lock()
+	ops := make([]OperationInfo, 0, len(s.operations.ops))
+	for _, a := range s.operations.ops {
+		ops = append(ops, a.info)
+	}
+	s.operations.mu.Unlock()
+	sort.Slice(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+	return ops
+}
+
+// Cancel cancels the context of the operation in flight with the given ID. Queries and mutations
+// return a cancellation error instead of their data once their resolvers returned, subscriptions are
+// closed. Cancel reports false if no such operation is in flight.
+func (s *Schema) Cancel(id uint64) bool {
+	s.operations.mu.Lock()
+	a, ok := s.operations.ops[id]
+	if ok {
+		a.cancelled = true
+	}
+	s.operations.mu.Unlock()
+	if ok {
+		a.cancel()
+	}
+	return ok
+}
+
+// cancelledError is returned for operations of type t which were cancelled with [Schema.Cancel].
+func cancelledError(t ast.OperationType) *errors.QueryError {
+	err := errors.Errorf("%s was cancelled", strings.ToLower(string(t)))
+	err.Err = context.Canceled
+	return err.SetCode(errors.CodeCancelled)
+}
@@ -0,0 +1,259 @@
+// Package pagination encodes positions in lists, such as offsets or the keys of the last items, into
+// the opaque cursors of Relay connections. Cursors may be signed so that clients can't forge positions
+// and may expire:
+//
+//	var cursors = pagination.Cursor{Key: secret, TTL: time.Hour}
+//
+//	func (r *Resolver) Friends(args pagination.ConnectionArgs) (*friendsConnection, error) {
+//		from, to, pageInfo, err := cursors.Window(args, len(r.friends))
+//		if err != nil {
+//			return nil, err
+//		}
+//		edges := make([]*friendsEdge, 0, to-from)
+//		for i := from; i < to; i++ {
+//			edges = append(edges, &friendsEdge{cursor: cursors.EncodeOffset(i), node: r.friends[i]})
+//		}
+//		return &friendsConnection{edges: edges, pageInfo: pageInfo}, nil
+//	}
+//
+// The returned [PageInfo] resolves the PageInfo type of the Relay cursor connections specification.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+var (
+	// ErrInvalidCursor is returned for cursors which were not encoded by the [Cursor], were altered or
+	// don't match the position they are decoded into.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrExpiredCursor is returned for cursors older than the TTL of the [Cursor].
+	ErrExpiredCursor = errors.New("expired cursor")
+)
+
+// Cursor encodes positions into cursors and decodes them. The zero value encodes unsigned cursors
+// without expiry.
+type Cursor struct {
+	// Key signs the cursors with HMAC-SHA256. Cursors without a valid signature are rejected. If it is
+	// empty, cursors are not signed.
+	Key []byte
+	// PreviousKeys are still accepted when decoding cursors, so that the key can be rotated without
+	// invalidating the cursors held by clients.
+	PreviousKeys [][]byte
+	// TTL is the time after which cursors expire. The default is 0, cursors don't expire.
+	TTL time.Duration
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
+// payload is the encoded content of a cursor.
+type payload struct {
+	Position json.RawMessage `json:"p"`
+	Expires  int64           `json:"e,omitempty"`
+}
+
+// Encode encodes position, any value which can be marshaled to JSON such as an offset or a struct
+// holding the keys of an item, into a cursor.
+func (c Cursor) Encode(position interface{}) (graphql.ID, error) {
+	p, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("pagination: %s", err)
+	}
+	pl := payload{Position: p}
+	if c.TTL > 0 {
+		pl.Expires = c.now().Add(c.TTL).Unix()
+	}
+	data, err := json.Marshal(pl)
+	if err != nil {
+		return "", fmt.Errorf("pagination: %s", err)
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(data)
+	if len(c.Key) != 0 {
+		cursor += "." + base64.RawURLEncoding.EncodeToString(sign(c.Key, data))
+	}
+	return graphql.ID(cursor), nil
+}
+
+// Decode verifies cursor and decodes its position into the value pointed to by position. It returns
+// ErrInvalidCursor or ErrExpiredCursor if the cursor is rejected.
+func (c Cursor) Decode(cursor graphql.ID, position interface{}) error {
+	encoded, signature := string(cursor), ""
+	if i := strings.IndexByte(encoded, '.'); i != -1 {
+		encoded, signature = encoded[:i], encoded[i+1:]
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if len(c.Key) != 0 {
+		mac, err := base64.RawURLEncoding.DecodeString(signature)
+		if err != nil || !c.verify(data, mac) {
+			return ErrInvalidCursor
+		}
+	} else if signature != "" {
+		return ErrInvalidCursor
+	}
+
+	var pl payload
+	if err := json.Unmarshal(data, &pl); err != nil || pl.Position == nil {
+		return ErrInvalidCursor
+	}
+	if pl.Expires != 0 && !c.now().Before(time.Unix(pl.Expires, 0)) {
+		return ErrExpiredCursor
+	}
+	if err := json.Unmarshal(pl.Position, position); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+// EncodeOffset encodes the offset of an item in a list into a cursor.
+func (c Cursor) EncodeOffset(offset int) graphql.ID {
+	cursor, err := c.Encode(offset)
+	if err != nil {
+		panic(err) // integers are always marshaled
+	}
+	return cursor
+}
+
+// DecodeOffset decodes a cursor encoded with EncodeOffset. Negative offsets are rejected with
+// ErrInvalidCursor.
+func (c Cursor) DecodeOffset(cursor graphql.ID) (int, error) {
+	var offset int
+	if err := c.Decode(cursor, &offset); err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
+
+func (c Cursor) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func (c Cursor) verify(data, mac []byte) bool {
+	if hmac.Equal(mac, sign(c.Key, data)) {
+		return true
+	}
+	for _, key := range c.PreviousKeys {
+		if hmac.Equal(mac, sign(key, data)) {
+			return true
+		}
+	}
+	return false
+}
+
+func sign(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// ConnectionArgs are the arguments of Relay connection fields. It is the arguments struct of resolvers of
+// fields declared with exactly these arguments, or is embedded into the arguments struct of fields with
+// further arguments.
+type ConnectionArgs struct {
+	First  *int32
+	After  *graphql.ID
+	Last   *int32
+	Before *graphql.ID
+}
+
+// Window returns the range [from, to) of the items of an offset based list of count items selected by
+// args, following the pagination algorithm of the Relay cursor connections specification, together
+// with the PageInfo of the page. The after and before cursors must have been encoded with
+// EncodeOffset.
+func (c Cursor) Window(args ConnectionArgs, count int) (from, to int, pageInfo *PageInfo, err error) {
+	from, to = 0, count
+	if args.After != nil {
+		after, err := c.DecodeOffset(*args.After)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if after+1 > from {
+			from = after + 1
+		}
+	}
+	if args.Before != nil {
+		before, err := c.DecodeOffset(*args.Before)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if before < to {
+			to = before
+		}
+	}
+	if from > to {
+		from = to
+	}
+	if args.First != nil {
+		if *args.First < 0 {
+			return 0, 0, nil, errors.New(`argument "first" must not be negative`)
+		}
+		if n := int(*args.First); to-from > n {
+			to = from + n
+		}
+	}
+	if args.Last != nil {
+		if *args.Last < 0 {
+			return 0, 0, nil, errors.New(`argument "last" must not be negative`)
+		}
+		if n := int(*args.Last); to-from > n {
+			from = to - n
+		}
+	}
+
+	pageInfo = &PageInfo{hasPreviousPage: from > 0, hasNextPage: to < count}
+	if from < to {
+		start, end := c.EncodeOffset(from), c.EncodeOffset(to-1)
+		pageInfo.startCursor, pageInfo.endCursor = &start, &end
+	}
+	return from, to, pageInfo, nil
+}
+
+// PageInfo resolves the PageInfo type of Relay connections:
+//
+//	type PageInfo {
+//		hasPreviousPage: Boolean!
+//		hasNextPage: Boolean!
+//		startCursor: ID
+//		endCursor: ID
+//	}
+type PageInfo struct {
+	hasPreviousPage bool
+	hasNextPage     bool
+	startCursor     *graphql.ID
+	endCursor       *graphql.ID
+}
+
+// NewPageInfo returns the PageInfo of a page of a list which is not paginated with [Cursor.Window],
+// e.g. based on keysets.
+func NewPageInfo(hasPreviousPage, hasNextPage bool, startCursor, endCursor *graphql.ID) *PageInfo {
+	return &PageInfo{hasPreviousPage: hasPreviousPage, hasNextPage: hasNextPage, startCursor: startCursor, endCursor: endCursor}
+}
+
+// HasPreviousPage reports whether items precede the page.
+func (p *PageInfo) HasPreviousPage() bool { return p.hasPreviousPage }
+
+// HasNextPage reports whether items follow the page.
+func (p *PageInfo) HasNextPage() bool { return p.hasNextPage }
+
+// StartCursor returns the cursor of the first item of the page, or nil if the page is empty.
+func (p *PageInfo) StartCursor() *graphql.ID { return p.startCursor }
+
+// EndCursor returns the cursor of the last item of the page, or nil if the page is empty.
+func (p *PageInfo) EndCursor() *graphql.ID { return p.endCursor }
@@ -0,0 +1,220 @@
+package pagination_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/pagination"
+)
+
+type keyset struct {
+	CreatedAt int64  `json:"c"`
+	ID        string `json:"i"`
+}
+
+func TestCursor(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := pagination.Cursor{Key: []byte("secret"), TTL: time.Minute, Now: func() time.Time { return now }}
+
+	cursor, err := c.Encode(keyset{CreatedAt: 42, ID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k keyset
+	if err := c.Decode(cursor, &k); err != nil {
+		t.Fatal(err)
+	}
+	if k != (keyset{CreatedAt: 42, ID: "a"}) {
+		t.Errorf("got position %+v", k)
+	}
+
+	forged, err := pagination.Cursor{Key: []byte("guess")}.Encode(keyset{CreatedAt: 42, ID: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := pagination.Cursor{}.EncodeOffset(3)
+	for _, cursor := range []graphql.ID{forged, unsigned, cursor + "x", "!", ""} {
+		if err := c.Decode(cursor, &k); err != pagination.ErrInvalidCursor {
+			t.Errorf("%q: got error %v, want %v", cursor, err, pagination.ErrInvalidCursor)
+		}
+	}
+	if _, err := c.DecodeOffset(cursor); err != pagination.ErrInvalidCursor {
+		t.Errorf("got error %v for a cursor of another position, want %v", err, pagination.ErrInvalidCursor)
+	}
+
+	rotated := pagination.Cursor{Key: []byte("new"), PreviousKeys: [][]byte{[]byte("secret")}, Now: c.Now}
+	if err := rotated.Decode(cursor, &k); err != nil {
+		t.Errorf("got error %v for a cursor signed with a previous key", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := c.Decode(cursor, &k); err != pagination.ErrExpiredCursor {
+		t.Errorf("got error %v, want %v", err, pagination.ErrExpiredCursor)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	var c pagination.Cursor
+	first := func(n int32) *int32 { return &n }
+	offset := func(i int) *graphql.ID { cursor := c.EncodeOffset(i); return &cursor }
+	for _, tt := range []struct {
+		args       pagination.ConnectionArgs
+		from, to   int
+		prev, next bool
+	}{
+		{pagination.ConnectionArgs{}, 0, 10, false, false},
+		{pagination.ConnectionArgs{First: first(3)}, 0, 3, false, true},
+		{pagination.ConnectionArgs{First: first(3), After: offset(2)}, 3, 6, true, true},
+		{pagination.ConnectionArgs{Last: first(3)}, 7, 10, true, false},
+		{pagination.ConnectionArgs{Last: first(2), Before: offset(5)}, 3, 5, true, true},
+		{pagination.ConnectionArgs{After: offset(8), Before: offset(2)}, 2, 2, true, true},
+		{pagination.ConnectionArgs{First: first(0)}, 0, 0, false, true},
+	} {
+		from, to, pageInfo, err := c.Window(tt.args, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if from != tt.from || to != tt.to || pageInfo.HasPreviousPage() != tt.prev || pageInfo.HasNextPage() != tt.next {
+			t.Errorf("%+v: got [%d, %d) with previous %t and next %t, want [%d, %d) with previous %t and next %t",
+				tt.args, from, to, pageInfo.HasPreviousPage(), pageInfo.HasNextPage(), tt.from, tt.to, tt.prev, tt.next)
+		}
+		if (pageInfo.StartCursor() == nil) != (from == to) {
+			t.Errorf("%+v: got start cursor %v for [%d, %d)", tt.args, pageInfo.StartCursor(), from, to)
+		}
+	}
+
+	if _, _, _, err := c.Window(pagination.ConnectionArgs{First: first(-1)}, 10); err == nil {
+		t.Error("expected an error for a negative first argument")
+	}
+	invalid := graphql.ID("cursor1")
+	if _, _, _, err := c.Window(pagination.ConnectionArgs{After: &invalid}, 10); err != pagination.ErrInvalidCursor {
+		t.Errorf("got error %v, want %v", err, pagination.ErrInvalidCursor)
+	}
+}
+
+var cursors = pagination.Cursor{Key: []byte("secret")}
+
+type resolver struct{}
+
+func (r *resolver) Numbers(args pagination.ConnectionArgs) (*connection, error) {
+	from, to, pageInfo, err := cursors.Window(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	conn := &connection{pageInfo: pageInfo}
+	for i := from; i < to; i++ {
+		conn.edges = append(conn.edges, &edge{cursor: cursors.EncodeOffset(i), node: int32(i + 1)})
+	}
+	return conn, nil
+}
+
+type connection struct {
+	edges    []*edge
+	pageInfo *pagination.PageInfo
+}
+
+func (c *connection) Edges() []*edge                 { return c.edges }
+func (c *connection) PageInfo() *pagination.PageInfo { return c.pageInfo }
+
+type edge struct {
+	cursor graphql.ID
+	node   int32
+}
+
+func (e *edge) Cursor() graphql.ID { return e.cursor }
+func (e *edge) Node() int32        { return e.node }
+
+func TestConnection(t *testing.T) {
+	schema := graphql.MustParseSchema(`
+		type Query {
+			numbers(first: Int, after: ID, last: Int, before: ID): NumberConnection!
+		}
+		type NumberConnection {
+			edges: [NumberEdge!]!
+			pageInfo: PageInfo!
+		}
+		type NumberEdge {
+			cursor: ID!
+			node: Int!
+		}
+		type PageInfo {
+			hasPreviousPage: Boolean!
+			hasNextPage: Boolean!
+			startCursor: ID
+			endCursor: ID
+		}
+	`, &resolver{})
+
+	const query = `query($after: ID) { numbers(first: 2, after: $after) { edges { node } pageInfo { hasNextPage endCursor } } }`
+	var after interface{}
+	var got []int32
+	for page := 0; page < 5; page++ {
+		resp := schema.Exec(context.Background(), query, "", map[string]interface{}{"after": after})
+		if len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+		var data struct {
+			Numbers struct {
+				Edges    []struct{ Node int32 }
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range data.Numbers.Edges {
+			got = append(got, e.Node)
+		}
+		if !data.Numbers.PageInfo.HasNextPage {
+			break
+		}
+		after = data.Numbers.PageInfo.EndCursor
+	}
+	if fmt.Sprint(got) != "[1 2 3 4 5]" {
+		t.Errorf("got nodes %v, want [1 2 3 4 5]", got)
+	}
+
+	resp := schema.Exec(context.Background(), query, "", map[string]interface{}{"after": "eyJwIjo0fQ"})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != pagination.ErrInvalidCursor.Error() {
+		t.Errorf("got errors %v for a forged cursor, want %q", resp.Errors, pagination.ErrInvalidCursor)
+	}
+}
+
+type filteredArgs struct {
+	pagination.ConnectionArgs
+	Query *string
+}
+
+func TestEmbeddedConnectionArgs(t *testing.T) {
+	var r filteredResolver
+	schema := graphql.MustParseSchema(`
+		type Query {
+			numbers(query: String, first: Int, after: ID, last: Int, before: ID): [Int!]!
+		}
+	`, &r)
+	resp := schema.Exec(context.Background(), `{ numbers(query: "odd", last: 2) }`, "", nil)
+	if got, want := string(resp.Data), `{"numbers":[3,4]}`; got != want || len(resp.Errors) != 0 {
+		t.Errorf("got data %s and errors %v, want %s", got, resp.Errors, want)
+	}
+}
+
+type filteredResolver struct{}
+
+func (r *filteredResolver) Numbers(args filteredArgs) ([]int32, error) {
+	from, to, _, err := cursors.Window(args.ConnectionArgs, 5)
+	if err != nil {
+		return nil, err
+	}
+	var numbers []int32
+	for i := from; i < to; i++ {
+		numbers = append(numbers, int32(i))
+	}
+	return numbers, nil
+}
@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/query"
+	"github.com/graph-gophers/graphql-go/internal/schema"
+)
+
+// ParserOptions restrict the resources spent on parsing a document, e.g. one received by an
+// internet-facing endpoint. A zero MaxTokens disables the token limit.
+type ParserOptions struct {
+	// MaxTokens is the maximum number of tokens of the document, not counting commas and comments.
+	MaxTokens int
+	// MaxDepth is the maximum nesting depth of selection sets, list and object values and list types.
+	// It defaults to 1000, which keeps the parser from overflowing the stack.
+	MaxDepth int
+	// UseStringDescriptions parses descriptions of schema documents like [UseStringDescriptions].
+	UseStringDescriptions bool
+}
+
+// ParseQuery parses the executable document queryString without validating it, e.g. for tools
+// which inspect queries. The parser aborts with an error as soon as the document exceeds the limits
+// of opts and never panics on malformed input.
+func ParseQuery(queryString string, opts ParserOptions) (*ast.ExecutableDefinition, *errors.QueryError) {
+	return parseQuery(queryString, query.Limits{MaxTokens: opts.MaxTokens, MaxDepth: opts.MaxDepth})
+}
+
+// ParseSchemaDocument parses the schema definition language document schemaString without
+// resolving its types against a resolver. The parser aborts with an error as soon as the document
+// exceeds the limits of opts and never panics on malformed input.
+func ParseSchemaDocument(schemaString string, opts ParserOptions) (*ast.Schema, error) {
+	s := schema.New()
+	if err := schema.ParseWithLimits(s, schemaString, opts.UseStringDescriptions, opts.MaxTokens, opts.MaxDepth); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
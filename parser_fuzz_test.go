@@ -0,0 +1,55 @@
+//go:build go1.18
+
+package graphql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/example/starwars"
+)
+
+func FuzzParseQuery(f *testing.F) {
+	for _, q := range []string{
+		`{ a(b: [1, {c: "d"}]) { ...F } } fragment F on T { e @skip(if: $x) }`,
+		`query Q($a: [Int!]! = [1]) { a }`,
+		`subscription { a(b: """block""") }`,
+		`{ a(b: 1.5e3, c: -1, d: null, e: ENUM) }`,
+	} {
+		f.Add(q)
+	}
+	f.Fuzz(func(t *testing.T, q string) {
+		graphql.ParseQuery(q, graphql.ParserOptions{})
+		graphql.ParseQuery(q, graphql.ParserOptions{MaxTokens: 10, MaxDepth: 2})
+	})
+}
+
+func FuzzParseSchemaDocument(f *testing.F) {
+	for _, s := range []string{
+		`schema { query: Query } type Query { a(b: Int = 1): [String!]! @deprecated } interface I { a: Int }`,
+		`union U = A | B enum E { A B } input In { a: Int = 1 } scalar S directive @d(a: Int) on FIELD`,
+		`"""desc""" type A implements B & C { a: Int } extend type Query { b: Int }`,
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		graphql.ParseSchemaDocument(s, graphql.ParserOptions{})
+		graphql.ParseSchemaDocument(s, graphql.ParserOptions{UseStringDescriptions: true, MaxTokens: 20, MaxDepth: 2})
+	})
+}
+
+func FuzzExec(f *testing.F) {
+	for _, q := range []string{
+		`{ hero { name friends { name ... on Human { height(unit: FOOT) } } } }`,
+		`query Q($id: ID!) { character(id: $id) { ...F } } fragment F on Character { name friendsConnection(first: 1) { edges { cursor } } }`,
+		`mutation { createReview(episode: JEDI, review: {stars: 5, commentary: "x"}) { stars } }`,
+		`{ __schema { types { name } } __type(name: "Human") { name } }`,
+	} {
+		f.Add(q)
+	}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{})
+	f.Fuzz(func(t *testing.T, q string) {
+		schema.Exec(context.Background(), q, "", map[string]interface{}{"id": "1000"})
+	})
+}
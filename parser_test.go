@@ -0,0 +1,64 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	doc, err := graphql.ParseQuery(`query Hero { hero { name } } fragment F on Droid { id }`, graphql.ParserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Operations) != 1 || doc.Operations[0].Name.Name != "Hero" || len(doc.Fragments) != 1 {
+		t.Errorf("unexpected document %+v", doc)
+	}
+
+	for _, tt := range []struct {
+		query string
+		opts  graphql.ParserOptions
+		want  string
+	}{
+		{`{ hero { name }`, graphql.ParserOptions{}, `syntax error: unexpected "", expecting Ident`},
+		{`{ a b c d }`, graphql.ParserOptions{MaxTokens: 3}, "document exceeds the maximum of 3 tokens"},
+		{`{ a { b { c } } }`, graphql.ParserOptions{MaxDepth: 2}, "document exceeds the maximum nesting depth of 2"},
+		{"{ a(b: " + strings.Repeat("[", 1<<20), graphql.ParserOptions{}, "document exceeds the maximum nesting depth of 1000"},
+	} {
+		if _, err := graphql.ParseQuery(tt.query, tt.opts); err == nil || err.Message != tt.want {
+			t.Errorf("ParseQuery(%q): got error %v, want %q", tt.query, err, tt.want)
+		}
+	}
+}
+
+func TestParseSchemaDocument(t *testing.T) {
+	t.Parallel()
+
+	s, err := graphql.ParseSchemaDocument(`
+		"""The root."""
+		type Query { hero: Character }
+		interface Character { name: String! }
+	`, graphql.ParserOptions{UseStringDescriptions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := s.Types["Query"]; q == nil || q.Description() != "The root." {
+		t.Errorf("unexpected type Query %+v", q)
+	}
+	if s.Types["Character"] == nil {
+		t.Error("missing type Character")
+	}
+
+	if _, err := graphql.ParseSchemaDocument(`type Query { a: [[[Int]]] }`, graphql.ParserOptions{MaxDepth: 2}); err == nil {
+		t.Error("expected an error for a document exceeding the maximum nesting depth")
+	}
+	if _, err := graphql.ParseSchemaDocument(`type Query { a: Int b: Int }`, graphql.ParserOptions{MaxTokens: 5}); err == nil {
+		t.Error("expected an error for a document exceeding the maximum of tokens")
+	}
+	if _, err := graphql.ParseSchemaDocument(`type Query { a: Unknown }`, graphql.ParserOptions{}); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
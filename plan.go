@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+	"github.com/graph-gophers/graphql-go/internal/exec/selected"
+	"github.com/graph-gophers/graphql-go/internal/query"
+)
+
+// Plan is the execution tree of an operation as returned by [Schema.Plan].
+type Plan struct {
+	// Operation is the type of the operation, i.e. "QUERY", "MUTATION" or "SUBSCRIPTION".
+	Operation string
+	Fields    []*PlanField
+}
+
+// PlanField describes how a selected field is going to be executed.
+type PlanField struct {
+	// Alias is the name of the field in the response.
+	Alias string
+	Name  string
+	// ParentType is the name of the type the field belongs to.
+	ParentType string
+	// TypeCondition is the name of the concrete type the field is selected for via a fragment on an
+	// interface or union. It is empty if the field is selected for every type.
+	TypeCondition string
+	// Resolver names the Go method or struct field which resolves the field. It is empty for
+	// __typename.
+	Resolver string
+	// Trivial is true if the field and its selections are resolved without calling a method that takes
+	// a context, arguments or returns an error, so they are resolved synchronously.
+	Trivial bool
+	// Concurrent is true if the field is resolved concurrently with its siblings, otherwise the
+	// siblings are resolved one after the other.
+	Concurrent bool
+	Fields     []*PlanField
+}
+
+// Plan returns the tree of fields which executing the query with the given variables resolves, including
+// the resolvers to be called and whether they are called concurrently. No resolvers are called. It is
+// intended for debugging and for static analysis tools.
+func (s *Schema) Plan(queryString string, operationName string, variables map[string]interface{}) (*Plan, []*errors.QueryError) {
+	if s.res.Query == nil {
+		return nil, []*errors.QueryError{errors.Errorf("schema created without resolver, can not plan").SetCode(errors.CodeNoResolver)}
+	}
+	doc, qErr := s.parse(context.Background(), queryString)
+	if qErr != nil {
+		return nil, []*errors.QueryError{qErr}
+	}
+	if errs := s.validate(context.Background(), doc, variables); len(errs) != 0 {
+		return nil, errs
+	}
+	op, err := getOperation(doc, operationName)
+	if err != nil {
+		return nil, []*errors.QueryError{operationError(err)}
+	}
+	if op.Type == query.Mutation && s.res.Mutation == nil || op.Type == query.Subscription && s.res.Subscription == nil {
+		return nil, []*errors.QueryError{errors.Errorf("no %s operations are offered by the schema", strings.ToLower(string(op.Type))).SetCode(errors.CodeOperationNotSupported)}
+	}
+
+	vars := make(map[string]interface{}, len(op.Vars))
+	for k, v := range variables {
+		vars[k] = v
+	}
+	for _, v := range op.Vars {
+		if _, ok := vars[v.Name.Name]; !ok && v.Default != nil {
+			vars[v.Name.Name] = v.Default.Deserialize(nil)
+		}
+	}
+
+	r := &selected.Request{
+		Doc:                doc,
+		Vars:               vars,
+		Schema:             s.schema,
+		AllowIntrospection: s.allowIntrospection == nil || s.allowIntrospection(context.Background()),
+	}
+	sels := selected.ApplyOperation(r, s.res, op)
+	if len(r.Errs) != 0 {
+		return nil, r.Errs
+	}
+	return &Plan{
+		Operation: string(op.Type),
+		Fields:    planSelections(sels, op.Type != query.Mutation, ""),
+	}, nil
+}
+
+func planSelections(sels []selected.Selection, concurrent bool, typeCondition string) []*PlanField {
+	concurrent = concurrent && selected.HasAsyncSel(sels)
+	var fields []*PlanField
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *selected.SchemaField:
+			fields = append(fields, &PlanField{
+				Alias:         sel.Alias,
+				Name:          sel.Name,
+				ParentType:    sel.TypeName,
+				TypeCondition: typeCondition,
+				Resolver:      sel.Resolver,
+				Trivial:       !sel.Async,
+				Concurrent:    concurrent,
+				Fields:        planSelections(sel.Sels, true, ""),
+			})
+		case *selected.TypeAssertion:
+			var name string
+			if obj, ok := sel.TypeExec.(*resolvable.Object); ok {
+				name = obj.Name
+			}
+			fields = append(fields, planSelections(sel.Sels, concurrent, name)...)
+		case *selected.TypenameField:
+			fields = append(fields, &PlanField{
+				Alias:         sel.Alias,
+				Name:          "__typename",
+				ParentType:    sel.Name,
+				TypeCondition: typeCondition,
+				Trivial:       true,
+				Concurrent:    concurrent,
+			})
+		}
+	}
+	return fields
+}
+
+// String formats the plan as an indented tree, one field per line.
+func (p *Plan) String() string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(p.Operation))
+	sb.WriteByte('\n')
+	writePlanFields(&sb, p.Fields, 1)
+	return sb.String()
+}
+
+func writePlanFields(sb *strings.Builder, fields []*PlanField, depth int) {
+	for _, f := range fields {
+		sb.WriteString(strings.Repeat("  ", depth))
+		if f.TypeCondition != "" {
+			fmt.Fprintf(sb, "... on %s ", f.TypeCondition)
+		}
+		if f.Alias != f.Name {
+			fmt.Fprintf(sb, "%s: ", f.Alias)
+		}
+		fmt.Fprintf(sb, "%s.%s", f.ParentType, f.Name)
+		if f.Resolver != "" {
+			fmt.Fprintf(sb, " <- %s", f.Resolver)
+		}
+		var modes []string
+		if f.Trivial {
+			modes = append(modes, "trivial")
+		}
+		if f.Concurrent {
+			modes = append(modes, "concurrent")
+		} else {
+			modes = append(modes, "serial")
+		}
+		fmt.Fprintf(sb, " [%s]\n", strings.Join(modes, ", "))
+		writePlanFields(sb, f.Fields, depth+1)
+	}
+}
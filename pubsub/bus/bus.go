@@ -0,0 +1,268 @@
+// Package bus turns subscriptions of message buses such as NATS or Kafka into the channels returned by
+// subscription resolvers. It handles unmarshaling the messages, the cancellation of the subscription and
+// consumers slower than the bus.
+//
+// The package does not depend on the clients of the buses. A [Source] adapts a client subscription with
+// a few lines, e.g. for a NATS subscription of github.com/nats-io/nats.go:
+//
+//	sub, err := nc.SubscribeSync("messages")
+//	src := bus.SourceFunc(func(ctx context.Context) (*bus.Message, error) {
+//		m, err := sub.NextMsgWithContext(ctx)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &bus.Message{Subject: m.Subject, Data: m.Data, Header: m.Header}, nil
+//	}, sub.Unsubscribe)
+//
+// or for a Kafka reader of github.com/segmentio/kafka-go, committing the offsets of delivered messages:
+//
+//	src := bus.SourceFunc(func(ctx context.Context) (*bus.Message, error) {
+//		m, err := reader.FetchMessage(ctx)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &bus.Message{Subject: m.Topic, Key: m.Key, Data: m.Value, Ack: func() error {
+//			return reader.CommitMessages(context.Background(), m)
+//		}}, nil
+//	}, reader.Close)
+//
+// The subscription resolver forwards the source to the channel it returns:
+//
+//	func (r *Resolver) MessageSent(ctx context.Context) (<-chan *messageResolver, error) {
+//		c := make(chan *messageResolver)
+//		if err := bus.Subscribe(ctx, r.source(ctx), c, bus.Options{}); err != nil {
+//			return nil, err
+//		}
+//		return c, nil
+//	}
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Message is a message received from a bus.
+type Message struct {
+	// Subject is the subject or topic the message was published to.
+	Subject string
+	// Key is the key of the message, if the bus has keys.
+	Key []byte
+	// Data is the payload of the message.
+	Data []byte
+	// Header holds the headers of the message, if the bus has headers.
+	Header map[string][]string
+	// Ack acknowledges the message once it was delivered to the subscriber or dropped, e.g. to commit
+	// its offset. It may be nil.
+	Ack func() error
+}
+
+// Source is a subscription of a message bus.
+type Source interface {
+	// Next blocks until the next message is received or ctx is done. It returns io.EOF once the
+	// subscription ended without an error.
+	Next(ctx context.Context) (*Message, error)
+	// Close ends the subscription.
+	Close() error
+}
+
+// SourceFunc returns a [Source] which receives messages with next and is ended with close, which may
+// be nil.
+func SourceFunc(next func(ctx context.Context) (*Message, error), close func() error) Source {
+	return &funcSource{next: next, close: close}
+}
+
+type funcSource struct {
+	next  func(ctx context.Context) (*Message, error)
+	close func() error
+}
+
+func (s *funcSource) Next(ctx context.Context) (*Message, error) {
+	return s.next(ctx)
+}
+
+func (s *funcSource) Close() error {
+	if s.close == nil {
+		return nil
+	}
+	return s.close()
+}
+
+// Backpressure decides what happens to messages while the subscriber is slower than the bus and the
+// buffer of [Options] is full.
+type Backpressure int
+
+const (
+	// Block stops receiving messages from the source until the subscriber caught up, so that the bus
+	// buffers them or slows down the publishers.
+	Block Backpressure = iota
+	// DropNewest drops the messages received while the buffer is full.
+	DropNewest
+	// DropOldest drops the oldest message of the buffer to make room for the received one, so that the
+	// subscriber gets the most recent messages.
+	DropOldest
+)
+
+// Options configure [Subscribe].
+type Options struct {
+	// Buffer is the number of unmarshaled messages held while the subscriber is busy. It defaults to 0,
+	// the dropping policies keep at least one message.
+	Buffer int
+	// Backpressure is the policy for messages received while the buffer is full. It defaults to Block.
+	Backpressure Backpressure
+	// Unmarshal decodes the message into v, a pointer to a new value of the element type of the
+	// channel. It defaults to decoding the data of the message as JSON. Channels of *Message receive
+	// the messages as they are.
+	Unmarshal func(msg *Message, v interface{}) error
+	// OnError is called with the errors of unmarshaling and acknowledging messages, whose messages are
+	// skipped, and with the error other than io.EOF of the source which ended the subscription.
+	OnError func(msg *Message, err error)
+	// OnDrop is called with the messages dropped by the backpressure policy.
+	OnDrop func(msg *Message)
+}
+
+var messageType = reflect.TypeOf(&Message{})
+
+// Subscribe sends the messages of src, unmarshaled into the element type of ch, on ch until ctx is done
+// or src fails. Then src and ch are closed. ch must be a channel which can be sent to, for example a
+// chan *MessageResolver.
+func Subscribe(ctx context.Context, src Source, ch interface{}, opts Options) error {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.SendDir == 0 {
+		return fmt.Errorf("bus: expected a channel which can be sent to, got %T", ch)
+	}
+	if v.IsNil() {
+		return fmt.Errorf("bus: nil channel")
+	}
+	s := &subscription{src: src, ch: v, opts: opts}
+	if s.opts.Buffer <= 0 && s.opts.Backpressure != Block {
+		s.opts.Buffer = 1
+	}
+	queue := make(chan delivery, s.opts.Buffer)
+	go s.receive(ctx, queue)
+	go s.send(ctx, queue)
+	return nil
+}
+
+type subscription struct {
+	src  Source
+	ch   reflect.Value
+	opts Options
+}
+
+// delivery is an unmarshaled message.
+type delivery struct {
+	msg   *Message
+	value reflect.Value
+}
+
+// receive unmarshals the messages of the source and queues them until ctx is done or the source fails.
+func (s *subscription) receive(ctx context.Context, queue chan delivery) {
+	defer close(queue)
+	defer s.src.Close()
+	for {
+		msg, err := s.src.Next(ctx)
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				s.error(msg, err)
+			}
+			return
+		}
+		v, err := s.unmarshal(msg)
+		if err != nil {
+			s.error(msg, err)
+			s.ack(msg)
+			continue
+		}
+		d := delivery{msg: msg, value: v}
+		switch s.opts.Backpressure {
+		case DropNewest:
+			select {
+			case queue <- d:
+			default:
+				s.drop(msg)
+			}
+		case DropOldest:
+			for queued := false; !queued; {
+				select {
+				case queue <- d:
+					queued = true
+				default:
+					select {
+					case old := <-queue:
+						s.drop(old.msg)
+					default:
+					}
+				}
+			}
+		default:
+			select {
+			case queue <- d:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// send sends the queued messages to the channel of the subscriber and closes it once the subscription
+// ended. The messages queued when the source failed are still sent.
+func (s *subscription) send(ctx context.Context, queue chan delivery) {
+	defer s.ch.Close()
+	for d := range queue {
+		chosen, _, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: s.ch, Send: d.value},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		})
+		if chosen == 1 {
+			// wait until the receiver stopped to not block it on a full queue
+			for range queue {
+			}
+			return
+		}
+		s.ack(d.msg)
+	}
+}
+
+func (s *subscription) unmarshal(msg *Message) (reflect.Value, error) {
+	elemType := s.ch.Type().Elem()
+	if elemType == messageType {
+		return reflect.ValueOf(msg), nil
+	}
+	p := reflect.New(elemType)
+	var err error
+	if s.opts.Unmarshal != nil {
+		err = s.opts.Unmarshal(msg, p.Interface())
+	} else {
+		err = json.Unmarshal(msg.Data, p.Interface())
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return p.Elem(), nil
+}
+
+func (s *subscription) ack(msg *Message) {
+	if msg.Ack == nil {
+		return
+	}
+	if err := msg.Ack(); err != nil {
+		s.error(msg, err)
+	}
+}
+
+func (s *subscription) drop(msg *Message) {
+	if s.opts.OnDrop != nil {
+		s.opts.OnDrop(msg)
+	}
+	s.ack(msg)
+}
+
+func (s *subscription) error(msg *Message, err error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(msg, err)
+	}
+}
@@ -0,0 +1,206 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/pubsub/bus"
+)
+
+// chanSource is a source receiving the messages sent on c, like a bus subscription.
+type chanSource struct {
+	c      chan *bus.Message
+	err    error
+	mu     sync.Mutex
+	closed bool
+	acked  []string
+}
+
+func newChanSource() *chanSource {
+	return &chanSource{c: make(chan *bus.Message, 10), err: io.EOF}
+}
+
+func (s *chanSource) publish(data string) {
+	m := &bus.Message{Subject: "messages", Data: []byte(data)}
+	m.Ack = func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.acked = append(s.acked, data)
+		return nil
+	}
+	s.c <- m
+}
+
+func (s *chanSource) Next(ctx context.Context) (*bus.Message, error) {
+	select {
+	case m, ok := <-s.c:
+		if !ok {
+			return nil, s.err
+		}
+		return m, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *chanSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *chanSource) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+type event struct {
+	Text string `json:"text"`
+}
+
+func TestSubscribe(t *testing.T) {
+	src := newChanSource()
+	ctx, cancel := context.WithCancel(context.Background())
+	c := make(chan *event)
+	var errs []error
+	if err := bus.Subscribe(ctx, src, c, bus.Options{OnError: func(msg *bus.Message, err error) { errs = append(errs, err) }}); err != nil {
+		t.Fatal(err)
+	}
+
+	src.publish(`{"text": "a"}`)
+	src.publish(`not json`)
+	src.publish(`{"text": "b"}`)
+	for _, want := range []string{"a", "b"} {
+		if e := <-c; e.Text != want {
+			t.Errorf("got event %q, want %q", e.Text, want)
+		}
+	}
+	cancel()
+	if _, ok := <-c; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if len(errs) != 1 {
+		t.Errorf("got errors %v, want the unmarshal error", errs)
+	}
+	for !src.isClosed() {
+		time.Sleep(time.Millisecond)
+	}
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if len(src.acked) != 3 {
+		t.Errorf("got acknowledged messages %q, want all of them", src.acked)
+	}
+}
+
+func TestSubscribeSourceError(t *testing.T) {
+	src := newChanSource()
+	c := make(chan *bus.Message)
+	var sourceErr error
+	if err := bus.Subscribe(context.Background(), src, c, bus.Options{Buffer: 2, OnError: func(msg *bus.Message, err error) { sourceErr = err }}); err != nil {
+		t.Fatal(err)
+	}
+	src.publish("a")
+	src.publish("b")
+	src.err = errors.New("connection lost")
+	close(src.c)
+
+	var got []string
+	for m := range c {
+		got = append(got, string(m.Data))
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("got messages %q, want the messages received before the error", got)
+	}
+	if sourceErr != src.err {
+		t.Errorf("got error %v, want %v", sourceErr, src.err)
+	}
+}
+
+func TestSubscribeBackpressure(t *testing.T) {
+	for _, tt := range []struct {
+		policy bus.Backpressure
+		kept   string
+	}{
+		{bus.DropNewest, "1"},
+		{bus.DropOldest, "5"},
+	} {
+		src := newChanSource()
+		c := make(chan *bus.Message)
+		var dropped []string
+		opts := bus.Options{Buffer: 1, Backpressure: tt.policy, OnDrop: func(m *bus.Message) { dropped = append(dropped, string(m.Data)) }}
+		if err := bus.Subscribe(context.Background(), src, c, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		// nothing is received until the source ended, so at most two messages are kept: one waiting
+		// to be sent on c and one in the buffer
+		for _, data := range []string{"1", "2", "3", "4", "5"} {
+			src.publish(data)
+		}
+		close(src.c)
+		for !src.isClosed() {
+			time.Sleep(time.Millisecond)
+		}
+		var got []string
+		for m := range c {
+			got = append(got, string(m.Data))
+		}
+		if len(got) == 0 || len(got) > 2 || len(got)+len(dropped) != 5 {
+			t.Errorf("policy %d: got messages %q and dropped %q, want at most two messages and the others dropped", tt.policy, got, dropped)
+			continue
+		}
+		kept := got[0]
+		if tt.policy == bus.DropOldest {
+			kept = got[len(got)-1]
+		}
+		if kept != tt.kept {
+			t.Errorf("policy %d: got messages %q, want %q kept", tt.policy, got, tt.kept)
+		}
+	}
+}
+
+type resolver struct {
+	src bus.Source
+}
+
+func (r *resolver) Hello() string { return "Hello world!" }
+
+func (r *resolver) MessageSent(ctx context.Context) (<-chan *message, error) {
+	c := make(chan *message)
+	if err := bus.Subscribe(ctx, r.src, c, bus.Options{}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+func TestSchemaSubscription(t *testing.T) {
+	src := newChanSource()
+	schema := graphql.MustParseSchema(`
+		type Query { hello: String! }
+		type Subscription { messageSent: Message! }
+		type Message { text: String! }
+	`, &resolver{src: src}, graphql.UseFieldResolvers())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := schema.Subscribe(ctx, `subscription { messageSent { text } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.publish(`{"text": "Hello bus!"}`)
+	resp := (<-c).(*graphql.Response)
+	if got, want := string(resp.Data), `{"messageSent":{"text":"Hello bus!"}}`; got != want || len(resp.Errors) != 0 {
+		t.Errorf("got data %s and errors %v, want %s", got, resp.Errors, want)
+	}
+}
@@ -0,0 +1,134 @@
+// Package pubsub provides a minimal in-process, topic-based publish/subscribe hub which connects
+// mutation resolvers to subscription resolvers.
+//
+// A subscription resolver subscribes a typed channel to a topic and returns it:
+//
+//	func (r *Resolver) MessageSent(ctx context.Context) <-chan *messageResolver {
+//		c := make(chan *messageResolver)
+//		if err := r.hub.Subscribe(ctx, "messages", c); err != nil {
+//			panic(err)
+//		}
+//		return c
+//	}
+//
+// A mutation resolver publishes events to the same topic:
+//
+//	r.hub.Publish(ctx, "messages", &messageResolver{text: args.Text})
+//
+// The channel is closed once the context of the subscription is done.
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// PubSub fans out published events to all subscribers of a topic. It is safe for concurrent use.
+// The zero value is not usable, use [New] instead.
+type PubSub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	ch   reflect.Value
+	done chan struct{}
+}
+
+// New returns an empty PubSub.
+func New() *PubSub {
+	return &PubSub{topics: make(map[string]map[*subscriber]struct{})}
+}
+
+// Subscribe sends the events published to topic on ch until ctx is done. Then ch is closed.
+// ch must be a channel which can be sent to, for example a chan *MessageResolver. Events which
+// are not assignable to the element type of ch are not sent to it.
+func (ps *PubSub) Subscribe(ctx context.Context, topic string, ch interface{}) error {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.SendDir == 0 {
+		return fmt.Errorf("pubsub: expected a channel which can be sent to, got %T", ch)
+	}
+	if v.IsNil() {
+		return fmt.Errorf("pubsub: nil channel")
+	}
+
+	sub := &subscriber{ch: v, done: make(chan struct{})}
+	ps.mu.Lock()
+	subs, ok := ps.topics[topic]
+	if !ok {
+		subs = make(map[*subscriber]struct{})
+		ps.topics[topic] = subs
+	}
+	subs[sub] = struct{}{}
+	ps.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		// unblock pending publishers before removing the subscriber
+		close(sub.done)
+		ps.mu.Lock()
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(ps.topics, topic)
+		}
+		ps.mu.Unlock()
+		sub.ch.Close()
+	}()
+	return nil
+}
+
+// Publish sends event to every subscriber of topic. It blocks until each subscriber received the
+// event, its subscription ended or ctx is done. It returns the number of subscribers which received
+// the event.
+func (ps *PubSub) Publish(ctx context.Context, topic string, event interface{}) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	ev := reflect.ValueOf(event)
+	n := 0
+	for sub := range ps.topics[topic] {
+		elemType := sub.ch.Type().Elem()
+		var v reflect.Value
+		switch {
+		case !ev.IsValid():
+			if !isNillable(elemType) {
+				continue
+			}
+			v = reflect.Zero(elemType)
+		case ev.Type().AssignableTo(elemType):
+			v = ev
+		default:
+			continue
+		}
+
+		chosen, _, _ := reflect.Select([]reflect.SelectCase{
+			{Dir: reflect.SelectSend, Chan: sub.ch, Send: v},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sub.done)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		})
+		switch chosen {
+		case 0:
+			n++
+		case 2:
+			return n
+		}
+	}
+	return n
+}
+
+// Subscribers returns the number of active subscribers of topic.
+func (ps *PubSub) Subscribers(topic string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(ps.topics[topic])
+}
+
+func isNillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package pubsub_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/pubsub"
+)
+
+const schema = `
+	type Query {
+		hello: String!
+	}
+
+	type Mutation {
+		send(text: String!): Message!
+	}
+
+	type Subscription {
+		messageSent: Message!
+	}
+
+	type Message {
+		text: String!
+	}
+`
+
+type message struct {
+	text string
+}
+
+func (m *message) Text() string { return m.text }
+
+type resolver struct {
+	hub *pubsub.PubSub
+}
+
+func (r *resolver) Hello() string { return "hello" }
+
+func (r *resolver) Send(ctx context.Context, args struct{ Text string }) *message {
+	m := &message{text: args.Text}
+	r.hub.Publish(ctx, "messages", m)
+	return m
+}
+
+func (r *resolver) MessageSent(ctx context.Context) (<-chan *message, error) {
+	c := make(chan *message)
+	if err := r.hub.Subscribe(ctx, "messages", c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func TestMutationTriggersSubscription(t *testing.T) {
+	hub := pubsub.New()
+	s := graphql.MustParseSchema(schema, &resolver{hub: hub})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := s.Subscribe(ctx, `subscription { messageSent { text } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return hub.Subscribers("messages") == 1 })
+
+	done := make(chan *graphql.Response)
+	go func() {
+		done <- s.Exec(context.Background(), `mutation { send(text: "hi") { text } }`, "", nil)
+	}()
+
+	ev := (<-events).(*graphql.Response)
+	if len(ev.Errors) != 0 {
+		t.Fatal(ev.Errors)
+	}
+	if got, want := string(ev.Data), `{"messageSent":{"text":"hi"}}`; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+	if resp := <-done; len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+
+	cancel()
+	for range events {
+	}
+	waitFor(t, func() bool { return hub.Subscribers("messages") == 0 })
+}
+
+func TestPublish(t *testing.T) {
+	hub := pubsub.New()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ints := make(chan int, 1)
+	strs := make(chan string, 1)
+	if err := hub.Subscribe(ctx, "t", ints); err != nil {
+		t.Fatal(err)
+	}
+	if err := hub.Subscribe(ctx, "t", strs); err != nil {
+		t.Fatal(err)
+	}
+	if err := hub.Subscribe(ctx, "t", 42); err == nil {
+		t.Error("expected an error for a non-channel")
+	}
+
+	if n := hub.Publish(context.Background(), "t", 1); n != 1 {
+		t.Errorf("expected 1 receiver, got %d", n)
+	}
+	if v := <-ints; v != 1 {
+		t.Errorf("unexpected event %v", v)
+	}
+	if n := hub.Publish(context.Background(), "other", 1); n != 0 {
+		t.Errorf("expected no receivers, got %d", n)
+	}
+
+	// a blocked publisher is released when the subscription ends
+	strs <- "full"
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	hub.Publish(context.Background(), "t", "blocked")
+
+	waitFor(t, func() bool { return hub.Subscribers("t") == 0 })
+	if _, ok := <-ints; ok {
+		t.Error("expected the channel to be closed")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,65 @@
+// Package registry integrates schemas with external schema registries such as Apollo Studio or
+// Hive. It publishes the schema definition when the schema is parsed:
+//
+//	schema := graphql.MustParseSchema(sdl, resolver,
+//		registry.Publish(publisher, map[string]string{"service": "users", "version": version}),
+//	)
+//
+// and reports the usage of operations and fields collected by a [UsageTracer]. This package only
+// defines the hooks, the clients of the registries implement [Publisher] and [Reporter].
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+// Schema is a schema as published to a registry.
+type Schema struct {
+	// SDL is the schema definition language of the schema, see [graphql.Schema.SDL].
+	SDL string
+	// Hash is the hex encoded SHA-256 hash of SDL.
+	Hash string
+	// Metadata describes the schema, e.g. the name of the service and its version.
+	Metadata map[string]string
+}
+
+// Describe returns the published form of s.
+func Describe(s *graphql.Schema, metadata map[string]string) Schema {
+	sdl := s.SDL()
+	return Schema{SDL: sdl, Hash: hash(sdl), Metadata: metadata}
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Publisher publishes schemas to a registry.
+type Publisher interface {
+	Publish(ctx context.Context, s Schema) error
+}
+
+// PublisherFunc is an adapter to use ordinary functions as [Publisher].
+type PublisherFunc func(ctx context.Context, s Schema) error
+
+// Publish calls f(ctx, s).
+func (f PublisherFunc) Publish(ctx context.Context, s Schema) error {
+	return f(ctx, s)
+}
+
+// Publish returns a schema option which publishes the schema with p when it is parsed. If p fails,
+// ParseSchema returns its error, so publishers which should not prevent the server from starting,
+// e.g. because the registry is unavailable, have to log and drop their errors.
+func Publish(p Publisher, metadata map[string]string) graphql.SchemaOpt {
+	return graphql.AfterParse(func(s *graphql.Schema) error {
+		if err := p.Publish(context.Background(), Describe(s, metadata)); err != nil {
+			return fmt.Errorf("registry: publish schema: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/registry"
+)
+
+const sdl = `
+	type Query {
+		hello: String!
+		fail: String
+	}
+`
+
+type resolver struct{}
+
+func (resolver) Hello() string { return "Hello!" }
+
+func (resolver) Fail() (*string, error) { return nil, errors.New("failed") }
+
+func TestPublish(t *testing.T) {
+	var published []registry.Schema
+	p := registry.PublisherFunc(func(ctx context.Context, s registry.Schema) error {
+		published = append(published, s)
+		return nil
+	})
+	s := graphql.MustParseSchema(sdl, resolver{}, registry.Publish(p, map[string]string{"service": "hello"}))
+
+	want := registry.Describe(s, map[string]string{"service": "hello"})
+	if len(want.Hash) != 64 || !strings.Contains(want.SDL, "hello: String!") {
+		t.Fatalf("unexpected description %+v", want)
+	}
+	if !reflect.DeepEqual(published, []registry.Schema{want}) {
+		t.Errorf("got published %+v, want %+v", published, want)
+	}
+
+	fail := registry.PublisherFunc(func(ctx context.Context, s registry.Schema) error {
+		return errors.New("unavailable")
+	})
+	if _, err := graphql.ParseSchema(sdl, resolver{}, registry.Publish(fail, nil)); err == nil || err.Error() != "registry: publish schema: unavailable" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestUsageTracer(t *testing.T) {
+	usage := registry.NewUsageTracer(nil, "abc")
+	s := graphql.MustParseSchema(sdl, resolver{}, graphql.TracerV2(usage))
+	ctx := context.Background()
+	s.Exec(ctx, `query Hello { hello }`, "", nil)
+	s.Exec(ctx, `query Hello { hello }`, "", nil)
+	s.Exec(ctx, `{ hello fail }`, "", nil)
+
+	var reports []*registry.Report
+	reporter := registry.ReporterFunc(func(ctx context.Context, r *registry.Report) error {
+		reports = append(reports, r)
+		return nil
+	})
+	failing := registry.ReporterFunc(func(ctx context.Context, r *registry.Report) error {
+		return errors.New("unavailable")
+	})
+	if err := usage.Flush(ctx, failing); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := usage.Flush(ctx, reporter); err != nil {
+		t.Fatal(err)
+	}
+	if err := usage.Flush(ctx, reporter); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1 since nothing was executed after the first one", len(reports))
+	}
+
+	r := reports[0]
+	if r.SchemaHash != "abc" || r.End.Before(r.Start) {
+		t.Errorf("unexpected report %+v", r)
+	}
+	var ops []registry.OperationUsage
+	for _, op := range r.Operations {
+		ops = append(ops, registry.OperationUsage{Name: op.Name, Count: op.Count, ErrorCount: op.ErrorCount})
+	}
+	wantOps := []registry.OperationUsage{{Name: "", Count: 1, ErrorCount: 1}, {Name: "Hello", Count: 2}}
+	if !reflect.DeepEqual(ops, wantOps) {
+		t.Errorf("got operations %+v, want %+v", ops, wantOps)
+	}
+	if want := map[string]int64{"Query.hello": 3, "Query.fail": 1}; !reflect.DeepEqual(r.Fields, want) {
+		t.Errorf("got fields %v, want %v", r.Fields, want)
+	}
+}
@@ -0,0 +1,178 @@
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/noop"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
+)
+
+// Report is the usage of a schema collected between Start and End.
+type Report struct {
+	// SchemaHash is the hash of the schema the usage was collected for, see [Schema.Hash].
+	SchemaHash string
+	Start      time.Time
+	End        time.Time
+	// Operations are the executed operations, ordered by name and query hash.
+	Operations []*OperationUsage
+	// Fields are the numbers of times the fields were resolved, keyed by "Type.field".
+	Fields map[string]int64
+}
+
+// OperationUsage is the usage of an operation.
+type OperationUsage struct {
+	// Name is the name of the operation, which is empty for anonymous operations.
+	Name string
+	// QueryHash is the hex encoded SHA-256 hash of the query document.
+	QueryHash string
+	// Count is the number of executions of the operation, or of subscriptions to it.
+	Count int64
+	// ErrorCount is the number of executions which resulted in errors.
+	ErrorCount int64
+}
+
+// Reporter sends usage reports to a registry.
+type Reporter interface {
+	Report(ctx context.Context, r *Report) error
+}
+
+// ReporterFunc is an adapter to use ordinary functions as [Reporter].
+type ReporterFunc func(ctx context.Context, r *Report) error
+
+// Report calls f(ctx, r).
+func (f ReporterFunc) Report(ctx context.Context, r *Report) error {
+	return f(ctx, r)
+}
+
+// UsageTracer collects the usage of operations and fields for [Reporter]s while passing every call
+// on to another tracer. Register it with the [graphql.TracerV2] option and call Flush periodically:
+//
+//	usage := registry.NewUsageTracer(otelTracer, registry.Describe(schema, nil).Hash)
+//
+// Fields skipped by a [graphql.TraceSampler] are not counted.
+type UsageTracer struct {
+	tracer.TracerV2
+
+	mu         sync.Mutex
+	schemaHash string
+	start      time.Time
+	operations map[operationKey]*OperationUsage
+	fields     map[string]int64
+}
+
+type operationKey struct {
+	name, queryHash string
+}
+
+// NewUsageTracer returns a tracer which collects usage for the schema with the given hash and
+// passes the calls on to next. If next is nil, nothing else is traced.
+func NewUsageTracer(next tracer.TracerV2, schemaHash string) *UsageTracer {
+	if next == nil {
+		next = noop.Tracer{}
+	}
+	t := &UsageTracer{TracerV2: next, schemaHash: schemaHash}
+	t.reset()
+	return t
+}
+
+func (t *UsageTracer) reset() {
+	t.start = time.Now()
+	t.operations = make(map[operationKey]*OperationUsage)
+	t.fields = make(map[string]int64)
+}
+
+// TraceQuery counts the execution of the query or mutation.
+func (t *UsageTracer) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, tracer.QueryFinishFunc) {
+	ctx, finish := t.TracerV2.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	return ctx, func(errs []*errors.QueryError) {
+		t.addOperation(operationName, queryString, len(errs) != 0)
+		finish(errs)
+	}
+}
+
+// TraceSubscription counts the subscription.
+func (t *UsageTracer) TraceSubscription(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, tracer.SubscriptionEventFunc, tracer.SubscriptionFinishFunc) {
+	t.addOperation(operationName, queryString, false)
+	return t.TracerV2.TraceSubscription(ctx, queryString, operationName, variables, varTypes)
+}
+
+// TraceResolver counts the resolution of the field.
+func (t *UsageTracer) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, tracer.ResolverFinishFunc) {
+	t.mu.Lock()
+	t.fields[info.TypeName+"."+info.FieldName]++
+	t.mu.Unlock()
+	return t.TracerV2.TraceResolver(ctx, info)
+}
+
+func (t *UsageTracer) addOperation(name, query string, failed bool) {
+	key := operationKey{name: name, queryHash: hash(query)}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op, ok := t.operations[key]
+	if !ok {
+		op = &OperationUsage{Name: key.name, QueryHash: key.queryHash}
+		t.operations[key] = op
+	}
+	op.Count++
+	if failed {
+		op.ErrorCount++
+	}
+}
+
+// Flush sends the usage collected since the last flush to r. If r fails, the usage is kept and sent
+// with the next flush.
+func (t *UsageTracer) Flush(ctx context.Context, r Reporter) error {
+	t.mu.Lock()
+	report := &Report{
+		SchemaHash: t.schemaHash,
+		Start:      t.start,
+		End:        time.Now(),
+		Fields:     t.fields,
+	}
+	for _, op := range t.operations {
+		report.Operations = append(report.Operations, op)
+	}
+	t.reset()
+	t.mu.Unlock()
+
+	sort.Slice(report.Operations, func(i, j int) bool {
+		a, b := report.Operations[i], report.Operations[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.QueryHash < b.QueryHash
+	})
+	if len(report.Operations) == 0 && len(report.Fields) == 0 {
+		return nil
+	}
+
+	if err := r.Report(ctx, report); err != nil {
+		t.restore(report)
+		return err
+	}
+	return nil
+}
+
+// restore merges the usage of a report which could not be sent back into the collected usage.
+func (t *UsageTracer) restore(report *Report) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = report.Start
+	for _, op := range report.Operations {
+		key := operationKey{name: op.Name, queryHash: op.QueryHash}
+		if cur, ok := t.operations[key]; ok {
+			cur.Count += op.Count
+			cur.ErrorCount += op.ErrorCount
+			continue
+		}
+		t.operations[key] = op
+	}
+	for field, n := range report.Fields {
+		t.fields[field] += n
+	}
+}
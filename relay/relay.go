@@ -1,14 +1,20 @@
 package relay
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 
 	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/internal/query"
 )
 
 func MarshalID(kind string, spec interface{}) graphql.ID {
@@ -43,28 +49,263 @@ func UnmarshalSpec(id graphql.ID, v interface{}) error {
 	return json.Unmarshal(s[i+1:], v)
 }
 
+// Handler serves GraphQL requests over HTTP. Besides JSON requests it accepts file uploads sent as
+// GraphQL multipart requests (https://github.com/jaydenseric/graphql-multipart-request-spec). The
+// uploaded files are placed into the variables as *graphql.Upload values. Responses are encoded in the
+// format preferred by the Accept header of the request, see [graphql.Schema.MarshalResponseFor].
+// Responses carrying a cache policy, see [graphql.CacheControl], are sent with a Cache-Control header.
+//
+// With EnableETag, successful responses to introspection queries, i.e. queries selecting only the
+// __schema and __type fields, carry an ETag derived from [graphql.Schema.Hash] and the request.
+// Requests whose If-None-Match header matches it are answered with 304 Not Modified without executing
+// the query, so that clients only download the schema again once it changed. No ETags are served for
+// schemas whose introspection depends on the request, see
+// [graphql.Schema.IntrospectionDependsOnRequest].
 type Handler struct {
 	Schema *graphql.Schema
+	// MaxUploadMemory is the number of bytes of a multipart request which are held in memory. The rest
+	// of the files is stored in temporary files. It defaults to 32 MB.
+	MaxUploadMemory int64
+	// MaxUploadSize is the maximum size of a multipart request in bytes. Larger requests are answered
+	// with 413 Request Entity Too Large. It defaults to 100 MB, a negative value disables the limit.
+	MaxUploadSize int64
+	// EnableETag enables the ETag of introspection responses.
+	EnableETag bool
+	// VariablesDecoder decodes the JSON variables of requests, e.g. with
+	// graphql.JSONVariablesDecoder{UseInt64: true} to keep the precision of large integers. It defaults
+	// to [graphql.JSONVariablesDecoder].
+	VariablesDecoder graphql.VariablesDecoder
+}
+
+type params struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+
+	// vars are the variables decoded with the variables decoder of the handler.
+	vars map[string]interface{}
+	// files are the uploaded files opened for the variables, which are closed after the execution.
+	files []multipart.File
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var params struct {
-		Query         string                 `json:"query"`
-		OperationName string                 `json:"operationName"`
-		Variables     map[string]interface{} `json:"variables"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+	var params params
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		maxMemory := h.MaxUploadMemory
+		if maxMemory == 0 {
+			maxMemory = 32 << 20
+		}
+		maxSize := h.MaxUploadSize
+		if maxSize == 0 {
+			maxSize = 100 << 20
+		}
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+		if maxSize > 0 {
+			r.Body = http.MaxBytesReader(w, body, maxSize)
+		}
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			if maxSize > 0 && body.n > maxSize {
+				http.Error(w, fmt.Sprintf("multipart request exceeds the maximum size of %d bytes", maxSize), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+		defer func() {
+			for _, f := range params.files {
+				f.Close()
+			}
+		}()
+		if err := h.decodeMultipart(r.MultipartForm, &params); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else if err := h.decodeParams(r.Body, &params); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	response := h.Schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
-	responseJSON, err := json.Marshal(response)
+	var etag string
+	if h.EnableETag && !h.Schema.IntrospectionDependsOnRequest() && isIntrospection(&params) {
+		etag = h.etag(&params, r.Header.Get("Accept"))
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Vary", "Accept")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	response := h.Schema.Exec(r.Context(), params.Query, params.OperationName, params.vars)
+	data, contentType, err := h.Schema.MarshalResponseFor(response, r.Header.Get("Accept"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(responseJSON)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Vary", "Accept")
+	if policy, ok := response.CachePolicy(); ok {
+		w.Header().Set("Cache-Control", policy.HeaderValue())
+	}
+	if etag != "" && len(response.Errors) == 0 {
+		w.Header().Set("ETag", etag)
+	}
+	w.Write(data)
+}
+
+// isIntrospection reports whether the operation of p only selects the __schema and __type fields.
+func isIntrospection(p *params) bool {
+	if !strings.Contains(p.Query, "__schema") && !strings.Contains(p.Query, "__type") {
+		return false
+	}
+	doc, err := graphql.ParseQuery(p.Query, graphql.ParserOptions{})
+	if err != nil {
+		return false
+	}
+	var op *ast.OperationDefinition
+	for _, o := range doc.Operations {
+		if o.Name.Name == p.OperationName || len(doc.Operations) == 1 && p.OperationName == "" {
+			op = o
+		}
+	}
+	if op == nil || op.Type != query.Query {
+		return false
+	}
+	for _, sel := range op.Selections {
+		f, ok := sel.(*ast.Field)
+		if !ok || f.Name.Name != "__schema" && f.Name.Name != "__type" && f.Name.Name != "__typename" {
+			return false
+		}
+	}
+	return true
+}
+
+// etag returns the ETag of the response to p encoded for the Accept header accept. It combines the hash
+// of the schema with a digest of the request, as the response depends on both.
+func (h *Handler) etag(p *params, accept string) string {
+	d := sha256.New()
+	for _, s := range []string{p.Query, p.OperationName, string(p.Variables), accept} {
+		io.WriteString(d, s)
+		d.Write([]byte{0})
+	}
+	return fmt.Sprintf(`"%s-%x"`, h.Schema.Hash(), d.Sum(nil)[:8])
+}
+
+// etagMatches reports whether the If-None-Match header value header lists etag, also as weak ETag.
+func etagMatches(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeParams decodes the JSON request r into p. The variables are decoded with the variables decoder
+// of the handler.
+func (h *Handler) decodeParams(r io.Reader, p *params) error {
+	if err := json.NewDecoder(r).Decode(p); err != nil {
+		return err
+	}
+	if string(p.Variables) == "null" {
+		return nil
+	}
+	vars, err := graphql.DecodeVariables(h.VariablesDecoder, p.Variables)
+	if err != nil {
+		return fmt.Errorf("invalid variables: %s", err)
+	}
+	p.vars = vars
+	return nil
+}
+
+// decodeMultipart decodes the "operations" field of a multipart request into p and replaces the
+// variables listed in the "map" field with the uploaded files.
+func (h *Handler) decodeMultipart(form *multipart.Form, p *params) error {
+	if len(form.Value["operations"]) != 1 {
+		return errors.New("missing operations field in multipart request")
+	}
+	if err := h.decodeParams(strings.NewReader(form.Value["operations"][0]), p); err != nil {
+		return fmt.Errorf("invalid operations field: %s", err)
+	}
+
+	var fileMap map[string][]string
+	if m := form.Value["map"]; len(m) == 1 {
+		if err := json.Unmarshal([]byte(m[0]), &fileMap); err != nil {
+			return fmt.Errorf("invalid map field: %s", err)
+		}
+	}
+	for key, paths := range fileMap {
+		if len(form.File[key]) != 1 {
+			return fmt.Errorf("missing file %q in multipart request", key)
+		}
+		fh := form.File[key][0]
+		f, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		p.files = append(p.files, f)
+		upload := &graphql.Upload{
+			File:        f,
+			Filename:    fh.Filename,
+			ContentType: fh.Header.Get("Content-Type"),
+			Size:        fh.Size,
+		}
+		for _, path := range paths {
+			if err := setUpload(p.vars, path, upload); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setUpload replaces the value at path, e.g. "variables.files.0", with upload.
+func setUpload(vars map[string]interface{}, path string, upload *graphql.Upload) error {
+	segments := strings.Split(path, ".")
+	if len(segments) < 2 || segments[0] != "variables" || vars == nil {
+		return fmt.Errorf("invalid file path %q, expected it to start with \"variables.\"", path)
+	}
+
+	var container interface{} = vars
+	segments = segments[1:]
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if last {
+				c[seg] = upload
+				return nil
+			}
+			container = c[seg]
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(c) {
+				return fmt.Errorf("invalid file path %q", path)
+			}
+			if last {
+				c[idx] = upload
+				return nil
+			}
+			container = c[idx]
+		default:
+			return fmt.Errorf("invalid file path %q", path)
+		}
+	}
+	return nil
 }
@@ -1,12 +1,23 @@
 package relay_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/cbor"
 	"github.com/graph-gophers/graphql-go/example/starwars"
+	"github.com/graph-gophers/graphql-go/msgpack"
 	"github.com/graph-gophers/graphql-go/relay"
 )
 
@@ -34,3 +45,274 @@ func TestServeHTTP(t *testing.T) {
 		t.Fatalf("Invalid response. Expected [%s], but instead got [%s]", expectedResponse, actualResponse)
 	}
 }
+
+// empireDecoder decodes the JSON variables and always asks for the hero of the Empire Strikes Back.
+type empireDecoder struct{}
+
+func (empireDecoder) DecodeVariables(data []byte) (map[string]interface{}, error) {
+	vars, err := graphql.JSONVariablesDecoder{}.DecodeVariables(data)
+	if err != nil {
+		return nil, err
+	}
+	vars["episode"] = "EMPIRE"
+	return vars, nil
+}
+
+func TestServeHTTPVariablesDecoder(t *testing.T) {
+	const body = `{"query":"query($episode: Episode) { hero(episode: $episode) { name } }","variables":{"episode":"JEDI"}}`
+	for _, tc := range []struct {
+		dec  graphql.VariablesDecoder
+		want string
+	}{
+		{want: `{"data":{"hero":{"name":"R2-D2"}}}`},
+		{dec: empireDecoder{}, want: `{"data":{"hero":{"name":"Luke Skywalker"}}}`},
+	} {
+		w := httptest.NewRecorder()
+		h := relay.Handler{Schema: starwarsSchema, VariablesDecoder: tc.dec}
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+		if got := w.Body.String(); got != tc.want {
+			t.Errorf("got %s, want %s", got, tc.want)
+		}
+	}
+}
+
+type uploadResolver struct{}
+
+func (r *uploadResolver) Hello() string { return "hello" }
+
+func (r *uploadResolver) Upload(args struct {
+	File  graphql.Upload
+	Files []*graphql.Upload
+}) (string, error) {
+	names := []string{}
+	for _, u := range append([]*graphql.Upload{&args.File}, args.Files...) {
+		b, err := io.ReadAll(u.File)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, fmt.Sprintf("%s:%s:%d:%s", u.Filename, u.ContentType, u.Size, b))
+	}
+	return strings.Join(names, ","), nil
+}
+
+func TestServeHTTPMultipart(t *testing.T) {
+	schema := graphql.MustParseSchema(`
+		scalar Upload
+
+		type Query {
+			hello: String!
+		}
+
+		type Mutation {
+			upload(file: Upload!, files: [Upload!]!): String!
+		}
+	`, &uploadResolver{})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("operations", `{"query":"mutation($file: Upload!, $files: [Upload!]!) { upload(file: $file, files: $files) }","variables":{"file":null,"files":[null,null]}}`)
+	mw.WriteField("map", `{"0":["variables.file"],"1":["variables.files.0"],"2":["variables.files.1"]}`)
+	for key, content := range map[string]string{"0": "first", "1": "second", "2": "third"} {
+		part, err := mw.CreateFormFile(key, "file"+key+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	mw.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/graphql", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	h := relay.Handler{Schema: schema}
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Fatalf("Expected status code 200, got %d: %s", w.Code, w.Body)
+	}
+	expectedResponse := `{"data":{"upload":"file0.txt:application/octet-stream:5:first,file1.txt:application/octet-stream:6:second,file2.txt:application/octet-stream:5:third"}}`
+	if actualResponse := w.Body.String(); expectedResponse != actualResponse {
+		t.Fatalf("Invalid response. Expected [%s], but instead got [%s]", expectedResponse, actualResponse)
+	}
+}
+
+type keepUploadResolver struct {
+	files []io.Reader
+}
+
+func (r *keepUploadResolver) Hello() string { return "hello" }
+
+func (r *keepUploadResolver) Upload(args struct{ File graphql.Upload }) string {
+	r.files = append(r.files, args.File.File)
+	return args.File.Filename
+}
+
+func TestServeHTTPMultipartLimits(t *testing.T) {
+	res := &keepUploadResolver{}
+	schema := graphql.MustParseSchema(`
+		scalar Upload
+
+		type Query {
+			hello: String!
+		}
+
+		type Mutation {
+			upload(file: Upload!): String!
+		}
+	`, res)
+	request := func(content string) *http.Request {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("operations", `{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`)
+		mw.WriteField("map", `{"0":["variables.file"]}`)
+		part, _ := mw.CreateFormFile("0", "a.txt")
+		part.Write([]byte(content))
+		mw.Close()
+		r := httptest.NewRequest("POST", "/graphql", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+		return r
+	}
+	h := relay.Handler{Schema: schema, MaxUploadMemory: 1, MaxUploadSize: 1024}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, request("small"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d: %s", w.Code, w.Body)
+	}
+	if len(res.files) != 1 {
+		t.Fatalf("Expected 1 upload, got %d", len(res.files))
+	}
+	if _, err := res.files[0].Read(make([]byte, 1)); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected the upload to be closed after the request, got %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, request(strings.Repeat("a", 2048)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status code 413, got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestServeHTTPMultipartInvalidMap(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("operations", `{"query":"{ hero { name } }","variables":{}}`)
+	mw.WriteField("map", `{"0":["query"]}`)
+	part, _ := mw.CreateFormFile("0", "a.txt")
+	part.Write([]byte("a"))
+	mw.Close()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/graphql", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	h := relay.Handler{Schema: starwarsSchema}
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != 400 {
+		t.Fatalf("Expected status code 400, got %d.", w.Code)
+	}
+}
+
+func TestServeHTTPResponseFormat(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{},
+		graphql.ResponseFormat(msgpack.ContentType, msgpack.Encoder{}),
+		graphql.ResponseFormat(cbor.ContentType, cbor.Encoder{}),
+	)
+	h := relay.Handler{Schema: schema}
+
+	for _, tc := range []struct {
+		accept      string
+		contentType string
+		body        []byte
+	}{
+		{"", "application/json", []byte(`{"data":{"hero":{"name":"R2-D2"}}}`)},
+		{"application/json, application/msgpack;q=0.5", "application/json", []byte(`{"data":{"hero":{"name":"R2-D2"}}}`)},
+		{"application/msgpack", "application/msgpack", append([]byte{0x81, 0xa4, 'd', 'a', 't', 'a', 0x81, 0xa4, 'h', 'e', 'r', 'o', 0x81, 0xa4, 'n', 'a', 'm', 'e', 0xa5}, "R2-D2"...)},
+		{"application/json;q=0.9, application/cbor", "application/cbor", append([]byte{0xa1, 0x64, 'd', 'a', 't', 'a', 0xa1, 0x64, 'h', 'e', 'r', 'o', 0xa1, 0x64, 'n', 'a', 'm', 'e', 0x65}, "R2-D2"...)},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ hero { name } }"}`))
+		r.Header.Set("Accept", tc.accept)
+
+		h.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Type"); got != tc.contentType {
+			t.Errorf("Accept %q: got content type %q, want %q", tc.accept, got, tc.contentType)
+		}
+		if got := w.Body.Bytes(); !bytes.Equal(got, tc.body) {
+			t.Errorf("Accept %q: got body % x, want % x", tc.accept, got, tc.body)
+		}
+	}
+}
+
+func TestServeHTTPCacheControl(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.CacheControl(time.Minute))
+	h := relay.Handler{Schema: schema}
+
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{`{ hero { name } }`, "max-age=60, public"},
+		{`{ hero { name } unknown }`, ""},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"`+tc.query+`"}`))
+
+		h.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Cache-Control"); got != tc.want {
+			t.Errorf("%s: got Cache-Control %q, want %q", tc.query, got, tc.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h = relay.Handler{Schema: starwarsSchema}
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query":"{ hero { name } }"}`)))
+	if got := w.Header().Get("Cache-Control"); got != "" {
+		t.Errorf("got Cache-Control %q without the CacheControl option", got)
+	}
+}
+
+func TestServeHTTPIntrospectionETag(t *testing.T) {
+	h := relay.Handler{Schema: starwarsSchema, EnableETag: true}
+	serve := func(body, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/graphql", strings.NewReader(body))
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	const introspection = `{"query":"{ __schema { types { name } } }"}`
+	w := serve(introspection, "")
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || !strings.HasPrefix(etag, `"`+starwarsSchema.Hash()+"-") {
+		t.Fatalf("got status %d and ETag %q, want 200 and an ETag with the schema hash", w.Code, etag)
+	}
+	if w := serve(introspection, `"other", W/`+etag); w.Code != 304 || w.Body.Len() != 0 {
+		t.Errorf("got status %d and body %q for a matching ETag, want 304 without body", w.Code, w.Body)
+	}
+	if w := serve(`{"query":"{ __type(name: \"Droid\") { name } }"}`, etag); w.Code != 200 || w.Header().Get("ETag") == etag {
+		t.Errorf("got status %d and ETag %q for another introspection query, want 200 and another ETag", w.Code, w.Header().Get("ETag"))
+	}
+	if w := serve(`{"query":"{ __schema { types { name } } hero { name } }"}`, etag); w.Code != 200 || w.Header().Get("ETag") != "" {
+		t.Errorf("got status %d and ETag %q for a query selecting other fields, want 200 without ETag", w.Code, w.Header().Get("ETag"))
+	}
+
+	h.Schema = graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{}, graphql.VisibilityFilter(func(ctx context.Context, info graphql.VisibilityInfo) bool {
+		return true
+	}))
+	if w := serve(introspection, etag); w.Code != 200 || w.Header().Get("ETag") != "" {
+		t.Errorf("got status %d and ETag %q with a VisibilityFilter, want 200 without ETag", w.Code, w.Header().Get("ETag"))
+	}
+
+	h = relay.Handler{Schema: starwarsSchema}
+	if w := serve(introspection, etag); w.Code != 200 || w.Header().Get("ETag") != "" {
+		t.Errorf("got status %d and ETag %q without EnableETag, want 200 without ETag", w.Code, w.Header().Get("ETag"))
+	}
+}
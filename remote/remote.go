@@ -0,0 +1,99 @@
+// Package remote executes operations with remote GraphQL servers. A [Schema] is created from the
+// result of the introspection query of the server, e.g. as returned by [graphql.Schema.ToJSON]:
+//
+//	users, err := remote.NewSchema(introspectionJSON, "https://users.example.com/query")
+//
+// It validates operations like a local schema and forwards them to the server. Together with
+// [graphql.Delegate] it merges remote schemas into a local one: the types of the remote schema, see
+// [graphql.Schema.SDL], are declared in the local schema and the resolvers of the fields forwarded to
+// the remote server call [Schema.Delegate].
+//
+// A Schema is an http.Handler, so the remote server can also be proxied on its own, e.g. behind a
+// local endpoint with authentication:
+//
+//	http.Handle("/users", users)
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/client"
+	"github.com/graph-gophers/graphql-go/errors"
+)
+
+// Schema is a schema whose operations are executed by a remote server.
+type Schema struct {
+	// Client sends the operations to the server. It may be configured, e.g. to forward headers.
+	Client *client.Client
+
+	schema *graphql.Schema
+}
+
+// NewSchema returns the schema described by introspectionJSON, the response to the introspection
+// query or its data, whose operations are sent to the HTTP endpoint of a remote server. Subscriptions
+// are not supported.
+func NewSchema(introspectionJSON []byte, endpoint string, opts ...graphql.SchemaOpt) (*Schema, error) {
+	s, err := decodeIntrospection(introspectionJSON)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]graphql.SchemaOpt{graphql.UseStringDescriptions()}, opts...)
+	schema, err := graphql.ParseSchema(printSDL(s), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{Client: &client.Client{URL: endpoint}, schema: schema}, nil
+}
+
+// Definition returns the definition of the remote schema, e.g. to inspect it with
+// [graphql.Schema.SDL]. It has no resolvers, so it validates operations but can not execute them.
+func (s *Schema) Definition() *graphql.Schema {
+	return s.schema
+}
+
+// Exec validates the operation and sends it to the remote server. Errors of the transport are
+// returned in the response.
+func (s *Schema) Exec(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) *graphql.Response {
+	if errs := s.schema.ValidateWithVariables(queryString, variables); len(errs) != 0 {
+		return &graphql.Response{Errors: errs}
+	}
+	resp, err := s.Client.Exec(ctx, graphql.Request{Query: queryString, OperationName: operationName, Variables: variables})
+	if err != nil {
+		return &graphql.Response{Errors: []*errors.QueryError{errors.Errorf("remote: %s", err)}}
+	}
+	return &graphql.Response{Data: resp.Data, Errors: resp.Errors, Extensions: resp.Extensions}
+}
+
+// Delegate forwards the field whose resolver is called with ctx to the root field remoteField of
+// the remote schema, see [graphql.Delegate]:
+//
+//	func (r *Resolver) User(ctx context.Context) (*graphql.DelegatedResult, error) {
+//		return r.users.Delegate(ctx, "")
+//	}
+func (s *Schema) Delegate(ctx context.Context, remoteField string) (*graphql.DelegatedResult, error) {
+	d, err := graphql.Delegate(ctx, remoteField)
+	if err != nil {
+		return nil, err
+	}
+	resp := s.Exec(ctx, d.Request.Query, d.Request.OperationName, d.Request.Variables)
+	return d.Result(resp.Data, resp.Errors)
+}
+
+// ServeHTTP executes the operations of JSON requests like relay.Handler.
+func (s *Schema) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphql.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := json.Marshal(s.Exec(r.Context(), req.Query, req.OperationName, req.Variables))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
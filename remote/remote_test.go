@@ -0,0 +1,180 @@
+package remote_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go/remote"
+)
+
+const usersSDL = `
+	"The users service."
+	schema {
+		query: Query
+	}
+
+	type Query {
+		"Returns a user."
+		user(id: ID!, role: Role = ADMIN): User
+		search(filter: Filter!): [Node!]!
+	}
+
+	interface Node {
+		id: ID!
+	}
+
+	type User implements Node {
+		id: ID!
+		name: String!
+		nick: String @deprecated(reason: "Use name.")
+	}
+
+	enum Role {
+		ADMIN
+		GUEST
+	}
+
+	input Filter {
+		text: String = "x"
+	}
+`
+
+type usersResolver struct{}
+
+func (usersResolver) User(args struct {
+	ID   graphql.ID
+	Role string
+}) *user {
+	return &user{id: args.ID, name: "Alice (" + args.Role + ")"}
+}
+
+func (usersResolver) Search(args struct{ Filter struct{ Text string } }) []*node {
+	return nil
+}
+
+type node struct{}
+
+func (*node) ID() graphql.ID { return "" }
+
+func (*node) ToUser() (*user, bool) { return nil, false }
+
+type user struct {
+	id   graphql.ID
+	name string
+}
+
+func (u *user) ID() graphql.ID { return u.id }
+func (u *user) Name() string   { return u.name }
+func (u *user) Nick() *string  { return nil }
+
+func newServer(t *testing.T, requests *int32) (*graphql.Schema, *httptest.Server) {
+	users := graphql.MustParseSchema(usersSDL, usersResolver{}, graphql.UseStringDescriptions())
+	h := &relay.Handler{Schema: users}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return users, srv
+}
+
+func TestSchema(t *testing.T) {
+	var requests int32
+	users, srv := newServer(t, &requests)
+	introspection, err := users.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := remote.NewSchema(introspection, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"""Returns a user."""`,
+		`user(id: ID!, role: Role = ADMIN): User`,
+		`nick: String @deprecated(reason: "Use name.")`,
+		`type User implements Node`,
+		`text: String = "x"`,
+	} {
+		if sdl := s.Definition().SDL(); !strings.Contains(sdl, want) {
+			t.Errorf("SDL does not contain %q:\n%s", want, sdl)
+		}
+	}
+	resp := s.Exec(context.Background(), `query($id: ID!) { user(id: $id) { name } }`, "", map[string]interface{}{"id": "1"})
+	if len(resp.Errors) != 0 || string(resp.Data) != `{"user":{"name":"Alice (ADMIN)"}}` {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	resp = s.Exec(context.Background(), `{ unknown }`, "", nil)
+	if len(resp.Errors) != 1 || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("expected a validation error without a request, got %+v after %d requests", resp.Errors, requests)
+	}
+
+	proxy := httptest.NewServer(s)
+	defer proxy.Close()
+	httpResp, err := http.Post(proxy.URL, "application/json", strings.NewReader(`{"query":"{ user(id: \"1\") { name } }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(httpResp.Body)
+	httpResp.Body.Close()
+	if string(body) != `{"data":{"user":{"name":"Alice (ADMIN)"}}}` {
+		t.Errorf("unexpected proxied response %s", body)
+	}
+
+	srv.Close()
+	resp = s.Exec(context.Background(), `{ user(id: "1") { name } }`, "", nil)
+	if len(resp.Errors) != 1 || !strings.HasPrefix(resp.Errors[0].Message, "remote: ") {
+		t.Errorf("expected a transport error, got %+v", resp.Errors)
+	}
+}
+
+type gatewayResolver struct {
+	users *remote.Schema
+}
+
+func (r *gatewayResolver) Me(ctx context.Context, args struct{ ID graphql.ID }) (*graphql.DelegatedResult, error) {
+	return r.users.Delegate(ctx, "user")
+}
+
+func TestSchema_Delegate(t *testing.T) {
+	var requests int32
+	users, srv := newServer(t, &requests)
+	introspection, err := users.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := remote.NewSchema(introspection, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gateway := graphql.MustParseSchema(`
+		type Query {
+			me(id: ID!): User
+		}
+
+		type User {
+			id: ID!
+			name: String!
+		}
+	`, &gatewayResolver{users: s})
+
+	resp := gateway.Exec(context.Background(), `{ me(id: "2") { id name } }`, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	want := `{"me":{"id":"2","name":"Alice (ADMIN)"}}`
+	if !reflect.DeepEqual(string(resp.Data), want) {
+		t.Errorf("got %s, want %s", resp.Data, want)
+	}
+}
@@ -0,0 +1,234 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type introspectionSchema struct {
+	QueryType        *typeRef               `json:"queryType"`
+	MutationType     *typeRef               `json:"mutationType"`
+	SubscriptionType *typeRef               `json:"subscriptionType"`
+	Types            []*fullType            `json:"types"`
+	Directives       []*directiveDefinition `json:"directives"`
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+type fullType struct {
+	Kind          string        `json:"kind"`
+	Name          string        `json:"name"`
+	Description   *string       `json:"description"`
+	Fields        []*field      `json:"fields"`
+	InputFields   []*inputValue `json:"inputFields"`
+	Interfaces    []*typeRef    `json:"interfaces"`
+	EnumValues    []*enumValue  `json:"enumValues"`
+	PossibleTypes []*typeRef    `json:"possibleTypes"`
+}
+
+type field struct {
+	Name              string        `json:"name"`
+	Description       *string       `json:"description"`
+	Args              []*inputValue `json:"args"`
+	Type              *typeRef      `json:"type"`
+	IsDeprecated      bool          `json:"isDeprecated"`
+	DeprecationReason *string       `json:"deprecationReason"`
+}
+
+type inputValue struct {
+	Name              string   `json:"name"`
+	Description       *string  `json:"description"`
+	Type              *typeRef `json:"type"`
+	DefaultValue      *string  `json:"defaultValue"`
+	IsDeprecated      bool     `json:"isDeprecated"`
+	DeprecationReason *string  `json:"deprecationReason"`
+}
+
+type enumValue struct {
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type directiveDefinition struct {
+	Name        string        `json:"name"`
+	Description *string       `json:"description"`
+	Locations   []string      `json:"locations"`
+	Args        []*inputValue `json:"args"`
+}
+
+var builtinTypes = map[string]bool{"Int": true, "Float": true, "String": true, "Boolean": true, "ID": true}
+
+var builtinDirectives = map[string]bool{"include": true, "skip": true, "deprecated": true, "specifiedBy": true}
+
+// decodeIntrospection decodes the result of the introspection query. It accepts the whole response,
+// i.e. {"data": {"__schema": ...}}, as well as its data.
+func decodeIntrospection(introspectionJSON []byte) (*introspectionSchema, error) {
+	var result struct {
+		Data *struct {
+			Schema *introspectionSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(introspectionJSON, &result); err != nil {
+		return nil, fmt.Errorf("remote: invalid introspection result: %w", err)
+	}
+	s := result.Schema
+	if result.Data != nil {
+		s = result.Data.Schema
+	}
+	if s == nil || s.QueryType == nil {
+		return nil, fmt.Errorf("remote: introspection result without __schema.queryType")
+	}
+	return s, nil
+}
+
+// printSDL prints the schema described by the introspection result in the schema definition
+// language with string descriptions.
+func printSDL(s *introspectionSchema) string {
+	var sb strings.Builder
+	sb.WriteString("schema {\n\tquery: " + s.QueryType.Name + "\n")
+	if s.MutationType != nil {
+		sb.WriteString("\tmutation: " + s.MutationType.Name + "\n")
+	}
+	if s.SubscriptionType != nil {
+		sb.WriteString("\tsubscription: " + s.SubscriptionType.Name + "\n")
+	}
+	sb.WriteString("}\n")
+
+	for _, d := range s.Directives {
+		if builtinDirectives[d.Name] {
+			continue
+		}
+		sb.WriteByte('\n')
+		description(&sb, "", d.Description)
+		sb.WriteString("directive @" + d.Name)
+		arguments(&sb, d.Args)
+		sb.WriteString(" on " + strings.Join(d.Locations, " | ") + "\n")
+	}
+
+	for _, t := range s.Types {
+		if builtinTypes[t.Name] || strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		sb.WriteByte('\n')
+		description(&sb, "", t.Description)
+		switch t.Kind {
+		case "SCALAR":
+			sb.WriteString("scalar " + t.Name + "\n")
+		case "OBJECT", "INTERFACE":
+			if t.Kind == "OBJECT" {
+				sb.WriteString("type " + t.Name)
+			} else {
+				sb.WriteString("interface " + t.Name)
+			}
+			if len(t.Interfaces) > 0 {
+				names := make([]string, len(t.Interfaces))
+				for i, iface := range t.Interfaces {
+					names[i] = iface.Name
+				}
+				sb.WriteString(" implements " + strings.Join(names, " & "))
+			}
+			sb.WriteString(" {\n")
+			for _, f := range t.Fields {
+				description(&sb, "\t", f.Description)
+				sb.WriteString("\t" + f.Name)
+				arguments(&sb, f.Args)
+				sb.WriteString(": " + typeString(f.Type))
+				deprecated(&sb, f.IsDeprecated, f.DeprecationReason)
+				sb.WriteByte('\n')
+			}
+			sb.WriteString("}\n")
+		case "UNION":
+			names := make([]string, len(t.PossibleTypes))
+			for i, pt := range t.PossibleTypes {
+				names[i] = pt.Name
+			}
+			sb.WriteString("union " + t.Name + " = " + strings.Join(names, " | ") + "\n")
+		case "ENUM":
+			sb.WriteString("enum " + t.Name + " {\n")
+			for _, v := range t.EnumValues {
+				description(&sb, "\t", v.Description)
+				sb.WriteString("\t" + v.Name)
+				deprecated(&sb, v.IsDeprecated, v.DeprecationReason)
+				sb.WriteByte('\n')
+			}
+			sb.WriteString("}\n")
+		case "INPUT_OBJECT":
+			sb.WriteString("input " + t.Name + " {\n")
+			for _, v := range t.InputFields {
+				description(&sb, "\t", v.Description)
+				sb.WriteString("\t")
+				writeInputValue(&sb, v)
+				sb.WriteByte('\n')
+			}
+			sb.WriteString("}\n")
+		}
+	}
+	return sb.String()
+}
+
+func arguments(sb *strings.Builder, args []*inputValue) {
+	if len(args) == 0 {
+		return
+	}
+	sb.WriteByte('(')
+	for i, arg := range args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if arg.Description != nil && *arg.Description != "" {
+			sb.WriteString(quote(*arg.Description) + " ")
+		}
+		writeInputValue(sb, arg)
+	}
+	sb.WriteByte(')')
+}
+
+func writeInputValue(sb *strings.Builder, v *inputValue) {
+	sb.WriteString(v.Name + ": " + typeString(v.Type))
+	if v.DefaultValue != nil {
+		sb.WriteString(" = " + *v.DefaultValue)
+	}
+	deprecated(sb, v.IsDeprecated, v.DeprecationReason)
+}
+
+func description(sb *strings.Builder, indent string, desc *string) {
+	if desc == nil || *desc == "" {
+		return
+	}
+	sb.WriteString(indent + quote(*desc) + "\n")
+}
+
+func deprecated(sb *strings.Builder, isDeprecated bool, reason *string) {
+	if !isDeprecated {
+		return
+	}
+	sb.WriteString(" @deprecated")
+	if reason != nil {
+		sb.WriteString("(reason: " + quote(*reason) + ")")
+	}
+}
+
+// quote returns s as a GraphQL string value. JSON strings are valid GraphQL strings.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func typeString(t *typeRef) string {
+	switch t.Kind {
+	case "NON_NULL":
+		return typeString(t.OfType) + "!"
+	case "LIST":
+		return "[" + typeString(t.OfType) + "]"
+	default:
+		return t.Name
+	}
+}
@@ -0,0 +1,246 @@
+// Package responsecache implements a cache for whole GraphQL responses which can be plugged
+// into a schema using the [graphql.ResponseCache] option.
+//
+// Responses of query operations are cached by the query string, the operation name, the variables
+// and, for responses with a private scope, the identity of the caller. Responses of schemas which
+// hide types and fields per request, e.g. with [graphql.VisibilityFilter], and of
+// [graphql.Schema.ExecWithRoot] are always private. The time to live of each
+// entry is derived from the @cacheControl hints of the resolved fields (see package [cachecontrol]),
+// so fields can opt out of caching with @cacheControl(maxAge: 0). Fields without a hint use the
+// TTL of the [Cache]. Responses containing errors are never cached.
+//
+// After every executed mutation the cache is invalidated, even if some of its fields failed, as the
+// others may have written data. By default all entries are purged; this can be customized with
+// [Cache.Invalidate].
+package responsecache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/cachecontrol"
+)
+
+// Store persists cached responses. Implementations must be safe for concurrent use. Errors returned
+// by a Store are treated as cache misses. A shared store such as Redis can be used to share the
+// cache between multiple server instances.
+type Store interface {
+	// Get returns the value stored for key and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value for key for the duration of ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Purge removes all values from the store.
+	Purge(ctx context.Context) error
+}
+
+// Cache caches whole responses in a Store.
+type Cache struct {
+	// Store holds the cached responses.
+	Store Store
+	// TTL is the max age of fields without a @cacheControl hint.
+	TTL time.Duration
+	// Identity returns the identity of the caller. It is required to cache responses with a private
+	// scope; if it is nil or returns an empty string, private responses are not cached.
+	Identity func(ctx context.Context) string
+	// Invalidate is called after a mutation was executed, whether or not some of its fields failed. If
+	// it is nil, the store is purged.
+	Invalidate func(ctx context.Context, store Store, operationName string) error
+}
+
+// New returns a Cache storing responses in store, using ttl for fields without a cache hint.
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{Store: store, TTL: ttl}
+}
+
+// Request identifies a cacheable request.
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+	// Introspection reports whether introspection is allowed for the request, since it changes the result.
+	Introspection bool
+	// Private reports whether the response depends on the caller beyond the resolved fields, e.g. on a
+	// visibility filter or a root resolver created per request. Such responses are handled as if they
+	// had a private scope, so they are only cached for callers with a known identity.
+	Private bool
+}
+
+type entry struct {
+	Data   json.RawMessage    `json:"data"`
+	Scope  cachecontrol.Scope `json:"scope"`
+	MaxAge int64              `json:"maxAge"`
+}
+
+// Lookup returns the cached data of the response to req, along with the policy it was stored with.
+func (c *Cache) Lookup(ctx context.Context, req Request) (json.RawMessage, cachecontrol.Policy, bool) {
+	var keys []string
+	if !req.Private {
+		keys = append(keys, c.key(req, ""))
+	}
+	if id := c.identity(ctx); id != "" {
+		keys = append(keys, c.key(req, id))
+	}
+	for _, k := range keys {
+		b, ok, err := c.Store.Get(ctx, k)
+		if err != nil || !ok {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal(b, &e); err != nil {
+			continue
+		}
+		return e.Data, cachecontrol.Policy{MaxAge: time.Duration(e.MaxAge), Scope: e.Scope}, true
+	}
+	return nil, cachecontrol.Policy{}, false
+}
+
+// Save stores the data of the response to req according to policy. It does nothing if the policy
+// is not cacheable or if it has a private scope and the identity of the caller is unknown.
+func (c *Cache) Save(ctx context.Context, req Request, data json.RawMessage, policy cachecontrol.Policy) {
+	if !policy.Cacheable() {
+		return
+	}
+	if req.Private {
+		policy.Scope = cachecontrol.ScopePrivate
+	}
+	var id string
+	if policy.Scope == cachecontrol.ScopePrivate {
+		if id = c.identity(ctx); id == "" {
+			return
+		}
+	}
+	b, err := json.Marshal(entry{Data: data, Scope: policy.Scope, MaxAge: int64(policy.MaxAge)})
+	if err != nil {
+		return
+	}
+	_ = c.Store.Set(ctx, c.key(req, id), b, policy.MaxAge)
+}
+
+// MutationExecuted invalidates the cache after a mutation was executed.
+func (c *Cache) MutationExecuted(ctx context.Context, operationName string) {
+	if c.Invalidate != nil {
+		_ = c.Invalidate(ctx, c.Store, operationName)
+		return
+	}
+	_ = c.Store.Purge(ctx)
+}
+
+func (c *Cache) identity(ctx context.Context) string {
+	if c.Identity == nil {
+		return ""
+	}
+	return c.Identity(ctx)
+}
+
+func (c *Cache) key(req Request, identity string) string {
+	vars, err := json.Marshal(req.Variables)
+	if err != nil {
+		vars = nil
+	}
+	h := sha256.New()
+	for _, part := range []string{req.Query, req.OperationName, string(vars), identity} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if req.Introspection {
+		h.Write([]byte{1})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DefaultMaxEntries is the default capacity of a [MemoryStore].
+const DefaultMaxEntries = 10000
+
+// MemoryStore is an in-memory Store. The zero value is ready to use.
+type MemoryStore struct {
+	// MaxEntries is the maximum number of entries. Once it is reached, the expired entries are removed
+	// and, if the store is still full, arbitrary entries until a quarter of it is free again. It
+	// defaults to DefaultMaxEntries.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Get implements [Store].
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(e.expires) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set implements [Store].
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]memoryEntry)
+	}
+	now := time.Now()
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries() {
+		s.evict(now)
+	}
+	s.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) maxEntries() int {
+	if s.MaxEntries <= 0 {
+		return DefaultMaxEntries
+	}
+	return s.MaxEntries
+}
+
+// evict makes room for new entries. Freeing a quarter of the store at once keeps the cost of the
+// sweeps low when the store is full of entries which did not expire yet.
+func (s *MemoryStore) evict(now time.Time) {
+	for k, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+	max := s.maxEntries()
+	for k := range s.entries {
+		if len(s.entries) < max-max/4 {
+			break
+		}
+		delete(s.entries, k)
+	}
+}
+
+// Purge implements [Store].
+func (s *MemoryStore) Purge(context.Context) error {
+	s.mu.Lock()
+	s.entries = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of entries in the store, including expired entries not evicted yet.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
@@ -0,0 +1,210 @@
+package responsecache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/cachecontrol"
+	"github.com/graph-gophers/graphql-go/responsecache"
+)
+
+const schema = cachecontrol.SDL + `
+	type Query {
+		counter: Int!
+		uncached: Int! @cacheControl(maxAge: 0)
+		me: String! @cacheControl(scope: PRIVATE)
+	}
+
+	type Mutation {
+		increment: Int!
+		fail: Int
+	}
+`
+
+type resolver struct {
+	calls int32
+}
+
+func (r *resolver) Counter() int32   { return atomic.AddInt32(&r.calls, 1) }
+func (r *resolver) Uncached() int32  { return atomic.AddInt32(&r.calls, 1) }
+func (r *resolver) Increment() int32 { return atomic.AddInt32(&r.calls, 1) }
+func (r *resolver) Fail() (*int32, error) {
+	return nil, errors.New("failed")
+}
+func (r *resolver) Me(ctx context.Context) string {
+	atomic.AddInt32(&r.calls, 1)
+	return identity(ctx)
+}
+
+type userKey struct{}
+
+func identity(ctx context.Context) string {
+	u, _ := ctx.Value(userKey{}).(string)
+	return u
+}
+
+func newSchema(t *testing.T) (*graphql.Schema, *resolver, *responsecache.MemoryStore) {
+	t.Helper()
+	res := &resolver{}
+	store := responsecache.NewMemoryStore()
+	c := responsecache.New(store, time.Minute)
+	c.Identity = identity
+	return graphql.MustParseSchema(schema, res, graphql.ResponseCache(c)), res, store
+}
+
+func exec(t *testing.T, s *graphql.Schema, ctx context.Context, q string) string {
+	t.Helper()
+	resp := s.Exec(ctx, q, "", nil)
+	if len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+	return string(resp.Data)
+}
+
+func TestCache(t *testing.T) {
+	s, res, store := newSchema(t)
+	ctx := context.Background()
+
+	first := exec(t, s, ctx, `{ counter }`)
+	if second := exec(t, s, ctx, `{ counter }`); second != first {
+		t.Errorf("got %s, want cached %s", second, first)
+	}
+	if res.calls != 1 {
+		t.Errorf("resolver called %d times, want 1", res.calls)
+	}
+
+	exec(t, s, ctx, `mutation { increment }`)
+	if store.Len() != 0 {
+		t.Errorf("store has %d entries after mutation, want 0", store.Len())
+	}
+	if got := exec(t, s, ctx, `{ counter }`); got != `{"counter":3}` {
+		t.Errorf("got %s after invalidation", got)
+	}
+}
+
+func TestPartialMutation(t *testing.T) {
+	s, _, store := newSchema(t)
+	ctx := context.Background()
+
+	exec(t, s, ctx, `{ counter }`)
+	resp := s.Exec(ctx, `mutation { increment fail }`, "", nil)
+	if len(resp.Errors) != 1 {
+		t.Fatalf("got errors %v, want the error of fail", resp.Errors)
+	}
+	if store.Len() != 0 {
+		t.Errorf("store has %d entries after a partially failed mutation, want 0", store.Len())
+	}
+	if got := exec(t, s, ctx, `{ counter }`); got != `{"counter":3}` {
+		t.Errorf("got %s after invalidation", got)
+	}
+}
+
+func TestOptOut(t *testing.T) {
+	s, res, store := newSchema(t)
+	exec(t, s, context.Background(), `{ counter uncached }`)
+	exec(t, s, context.Background(), `{ counter uncached }`)
+	if res.calls != 4 {
+		t.Errorf("resolvers called %d times, want 4", res.calls)
+	}
+	if store.Len() != 0 {
+		t.Errorf("store has %d entries, want 0", store.Len())
+	}
+}
+
+func TestPrivateScope(t *testing.T) {
+	s, res, _ := newSchema(t)
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	bob := context.WithValue(context.Background(), userKey{}, "bob")
+
+	exec(t, s, alice, `{ me }`)
+	if got := exec(t, s, bob, `{ me }`); got != `{"me":"bob"}` {
+		t.Errorf("got %s for bob", got)
+	}
+	if got := exec(t, s, alice, `{ me }`); got != `{"me":"alice"}` {
+		t.Errorf("got %s for alice", got)
+	}
+	if res.calls != 2 {
+		t.Errorf("resolver called %d times, want 2", res.calls)
+	}
+
+	// private responses are not cached for anonymous callers
+	exec(t, s, context.Background(), `{ me }`)
+	exec(t, s, context.Background(), `{ me }`)
+	if res.calls != 4 {
+		t.Errorf("resolver called %d times, want 4", res.calls)
+	}
+}
+
+func TestCacheHitStats(t *testing.T) {
+	c := responsecache.New(responsecache.NewMemoryStore(), time.Minute)
+	s := graphql.MustParseSchema(schema, &resolver{}, graphql.ResponseCache(c), graphql.CollectStats())
+
+	for i, want := range []int{0, 1} {
+		resp := s.Exec(context.Background(), `{ counter }`, "", nil)
+		if resp.Stats == nil || resp.Stats.CacheHits != want {
+			t.Fatalf("request %d: expected %d cache hits, got %+v", i, want, resp.Stats)
+		}
+	}
+}
+
+func TestCallerDependentResponses(t *testing.T) {
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	for name, tt := range map[string]struct {
+		opts []graphql.SchemaOpt
+		exec func(s *graphql.Schema, ctx context.Context, res *resolver) *graphql.Response
+	}{
+		"visibility filter": {
+			opts: []graphql.SchemaOpt{graphql.VisibilityFilter(func(ctx context.Context, info graphql.VisibilityInfo) bool { return true })},
+			exec: func(s *graphql.Schema, ctx context.Context, res *resolver) *graphql.Response {
+				return s.Exec(ctx, `{ counter }`, "", nil)
+			},
+		},
+		"root resolver": {
+			exec: func(s *graphql.Schema, ctx context.Context, res *resolver) *graphql.Response {
+				return s.ExecWithRoot(ctx, res, `{ counter }`, "", nil)
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res := &resolver{}
+			c := responsecache.New(responsecache.NewMemoryStore(), time.Minute)
+			c.Identity = identity
+			s := graphql.MustParseSchema(schema, res, append(tt.opts, graphql.ResponseCache(c))...)
+
+			// anonymous callers must not share responses
+			tt.exec(s, context.Background(), res)
+			tt.exec(s, context.Background(), res)
+			if res.calls != 2 {
+				t.Errorf("resolver called %d times, want 2", res.calls)
+			}
+			tt.exec(s, alice, res)
+			if resp := tt.exec(s, alice, res); string(resp.Data) != `{"counter":3}` {
+				t.Errorf("got %s, want the cached response of alice", resp.Data)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	store := &responsecache.MemoryStore{MaxEntries: 8}
+	_ = store.Set(ctx, "expired", []byte("0"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	for i := 0; i < 100; i++ {
+		_ = store.Set(ctx, fmt.Sprint(i), []byte(fmt.Sprint(i)), time.Minute)
+		if store.Len() > 8 {
+			t.Fatalf("got %d entries, want at most 8", store.Len())
+		}
+	}
+	if _, ok, _ := store.Get(ctx, "99"); !ok {
+		t.Error("the last entry was evicted")
+	}
+	if _, ok, _ := store.Get(ctx, "expired"); ok {
+		t.Error("the expired entry was not evicted")
+	}
+}
@@ -0,0 +1,112 @@
+// Package retry defines policies for retrying field resolvers which failed with transient errors.
+// Policies are assigned to fields with the graphql.RetryPolicy schema option:
+//
+//	graphql.RetryPolicy(func(info retry.FieldInfo) retry.Policy {
+//		if info.TypeName == "Mutation" {
+//			return retry.Policy{} // mutations are not idempotent
+//		}
+//		return retry.Policy{MaxAttempts: 3, Backoff: retry.Exponential(10*time.Millisecond, time.Second)}
+//	})
+//
+// Only the last error of a resolver is recorded in the response.
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FieldInfo identifies the field whose resolver failed.
+type FieldInfo struct {
+	TypeName  string
+	FieldName string
+}
+
+// Policy describes how often and when a failed resolver is called again. The zero value disables retries.
+type Policy struct {
+	// MaxAttempts is the maximum number of calls of the resolver, including the first one.
+	MaxAttempts int
+
+	// Backoff returns the delay before the given retry, starting at 1. If it is nil, retries are
+	// not delayed.
+	Backoff func(retry int) time.Duration
+
+	// Retryable reports whether a resolver which failed with err may be called again. If it is nil,
+	// errors marked with [Transient] and errors with a Temporary() method returning true are retried.
+	Retryable func(err error) bool
+}
+
+// Do calls fn until it succeeds, the error is not retryable, the attempts are exhausted or ctx is done.
+// It returns the result of the last call.
+func (p Policy) Do(ctx context.Context, fn func() (interface{}, error)) (interface{}, error) {
+	res, err := fn()
+	if err != nil {
+		return p.Retry(ctx, err, fn)
+	}
+	return res, nil
+}
+
+// Retry calls fn again after a first call failed with err, following the policy. It returns the result
+// of the last call, or nil and err if fn was not called again.
+func (p Policy) Retry(ctx context.Context, err error, fn func() (interface{}, error)) (interface{}, error) {
+	var res interface{}
+	for retry := 1; err != nil && retry < p.MaxAttempts && p.retryable(err); retry++ {
+		if p.Backoff != nil {
+			t := time.NewTimer(p.Backoff(retry))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return res, err
+			case <-t.C:
+			}
+		} else if ctx.Err() != nil {
+			return res, err
+		}
+		res, err = fn()
+	}
+	return res, err
+}
+
+func (p Policy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsTransient(err)
+}
+
+// Exponential returns a backoff which starts at base and doubles with each retry up to max.
+func Exponential(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		d := base
+		for i := 1; i < retry && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string   { return e.err.Error() }
+func (e *transientError) Unwrap() error   { return e.err }
+func (e *transientError) Temporary() bool { return true }
+
+// Transient marks err as transient, so the resolver returning it is retried by the default policy.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+// IsTransient reports whether err or any error it wraps has a Temporary() method returning true.
+func IsTransient(err error) bool {
+	var t interface{ Temporary() bool }
+	return errors.As(err, &t) && t.Temporary()
+}
@@ -0,0 +1,73 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/retry"
+)
+
+func TestPolicyDo(t *testing.T) {
+	transient := retry.Transient(errors.New("unavailable"))
+	permanent := errors.New("not found")
+
+	for _, tc := range []struct {
+		name      string
+		policy    retry.Policy
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", policy: retry.Policy{MaxAttempts: 3}, errs: []error{nil}, wantCalls: 1},
+		{name: "recovers", policy: retry.Policy{MaxAttempts: 3}, errs: []error{transient, transient, nil}, wantCalls: 3},
+		{name: "exhausted", policy: retry.Policy{MaxAttempts: 2}, errs: []error{transient, transient, nil}, wantCalls: 2, wantErr: transient},
+		{name: "permanent", policy: retry.Policy{MaxAttempts: 3}, errs: []error{permanent, nil}, wantCalls: 1, wantErr: permanent},
+		{name: "zero policy", policy: retry.Policy{}, errs: []error{transient, nil}, wantCalls: 1, wantErr: transient},
+		{
+			name:      "custom retryable",
+			policy:    retry.Policy{MaxAttempts: 3, Retryable: func(err error) bool { return err == permanent }},
+			errs:      []error{permanent, nil},
+			wantCalls: 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			_, err := tc.policy.Do(context.Background(), func() (interface{}, error) {
+				err := tc.errs[calls]
+				calls++
+				return nil, err
+			})
+			if calls != tc.wantCalls {
+				t.Errorf("want %d calls, got %d", tc.wantCalls, calls)
+			}
+			if err != tc.wantErr {
+				t.Errorf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPolicyDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := retry.Policy{MaxAttempts: 5, Backoff: func(int) time.Duration { return time.Hour }}
+	calls := 0
+	go cancel()
+	_, err := p.Do(ctx, func() (interface{}, error) {
+		calls++
+		return nil, retry.Transient(errors.New("unavailable"))
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("want a single call with an error, got %d calls and error %v", calls, err)
+	}
+}
+
+func TestExponential(t *testing.T) {
+	b := retry.Exponential(10*time.Millisecond, 50*time.Millisecond)
+	for retry, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond} {
+		if got := b(retry); got != want {
+			t.Errorf("retry %d: want %s, got %s", retry, want, got)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+#!/bin/sh
+# benchcheck.sh runs the benchmarks of package benchmarks and compares them to the published
+# baseline with benchstat. It fails if the time, memory or allocations of a benchmark regressed by
+# more than THRESHOLD percent (10 by default) with statistical significance.
+#
+# Usage: ./scripts/benchcheck.sh [baseline]
+set -e
+
+BASELINE=${1:-benchmarks/testdata/baseline.txt}
+THRESHOLD=${THRESHOLD:-10}
+COUNT=${COUNT:-10}
+
+if ! command -v benchstat >/dev/null 2>&1; then
+  go install golang.org/x/perf/cmd/benchstat@latest
+  PATH=$(go env GOPATH)/bin:$PATH
+fi
+
+NEW=$(mktemp)
+REPORT=$(mktemp)
+trap 'rm -f "$NEW" "$REPORT"' EXIT
+
+go test -run '^$' -bench . -benchmem -count "$COUNT" ./benchmarks > "$NEW"
+benchstat "$BASELINE" "$NEW" | tee "$REPORT"
+
+# benchstat prints the change of significant differences as "+12.34%" and "~" otherwise.
+awk -v max="$THRESHOLD" '
+  /\+[0-9.]+%/ {
+    match($0, /\+[0-9.]+%/)
+    if (substr($0, RSTART + 1, RLENGTH - 2) + 0 > max) {
+      print "regression: " $0
+      failed = 1
+    }
+  }
+  END { exit failed }
+' "$REPORT"
@@ -0,0 +1,165 @@
+// Package server serves a schema over HTTP with everything a GraphQL server needs: queries and
+// mutations are posted to the endpoint like with relay.Handler, subscriptions are sent over websockets
+// to the same endpoint, GraphiQL is served at "/" and the server shuts down gracefully:
+//
+//	func main() {
+//		schema := graphql.MustParseSchema(`type Query { hello: String! }`, &resolver{})
+//		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//		defer stop()
+//		srv := &server.Server{Schema: schema}
+//		if err := srv.ListenAndServe(ctx); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// Websockets speak the graphql-transport-ws protocol of GraphiQL and the legacy graphql-ws protocol.
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/handler/playground"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// Server serves a schema over HTTP. It is an http.Handler, so it can also be mounted into an existing
+// server instead of being started with [Server.ListenAndServe].
+type Server struct {
+	Schema *graphql.Schema
+
+	// Addr is the TCP address ListenAndServe listens on. It defaults to ":8080".
+	Addr string
+
+	// Path is the path of the GraphQL endpoint. It defaults to "/query".
+	Path string
+
+	// DisablePlayground disables GraphiQL, which is served at "/" otherwise.
+	DisablePlayground bool
+
+	// CheckOrigin reports whether websockets may be opened by pages of the Origin of r. If it is nil,
+	// only pages of the host of the server may, so that other sites can not open websockets with the
+	// cookies of the user.
+	CheckOrigin func(r *http.Request) bool
+
+	// ConnectionInitTimeout is the time websocket clients have to initialize the connection. It
+	// defaults to 10 seconds.
+	ConnectionInitTimeout time.Duration
+
+	// MaxMessageSize is the maximum size of websocket messages in bytes. It defaults to 1 MB if it is
+	// not positive.
+	MaxMessageSize int64
+
+	// VariablesDecoder decodes the JSON variables of requests and websocket messages. It defaults to
+	// [graphql.JSONVariablesDecoder].
+	VariablesDecoder graphql.VariablesDecoder
+
+	// ShutdownTimeout is the time the server waits for running requests and subscriptions once the
+	// context of ListenAndServe is done. It defaults to 10 seconds.
+	ShutdownTimeout time.Duration
+
+	once     sync.Once
+	endpoint *relay.Handler
+	page     *playground.Handler
+	conns    sync.WaitGroup // websocket connections, which are not tracked by http.Server
+}
+
+func (s *Server) init() {
+	s.once.Do(func() {
+		s.endpoint = &relay.Handler{Schema: s.Schema, VariablesDecoder: s.VariablesDecoder}
+		s.page = &playground.Handler{Endpoint: s.path(), SubscriptionEndpoint: s.path()}
+	})
+}
+
+func (s *Server) path() string {
+	if s.Path == "" {
+		return "/query"
+	}
+	return s.Path
+}
+
+func (s *Server) readLimit() int64 {
+	if s.MaxMessageSize <= 0 {
+		return 1 << 20
+	}
+	return s.MaxMessageSize
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.init()
+	switch {
+	case r.URL.Path == s.path() && isWebSocketUpgrade(r):
+		s.serveWebSocket(w, r)
+	case r.URL.Path == s.path():
+		s.endpoint.ServeHTTP(w, r)
+	case r.URL.Path == "/" && !s.DisablePlayground:
+		s.page.ServeHTTP(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ListenAndServe listens on Addr and serves the schema until ctx is done, e.g. on a signal. Then
+// it shuts down gracefully, see [Server.Serve].
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	addr := s.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln and serves the schema until ctx is done. Then it stops accepting
+// connections, shuts the schema down, which ends its subscriptions, and waits for running requests
+// and websocket connections until ShutdownTimeout passed. It returns nil after a graceful shutdown.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: s}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout := s.ShutdownTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	schemaErr := s.Schema.Shutdown(shutdownCtx)
+	err := srv.Shutdown(shutdownCtx)
+	if err == nil {
+		err = schemaErr
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		s.conns.Wait()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-shutdownCtx.Done():
+		if err == nil {
+			err = shutdownCtx.Err()
+		}
+	}
+	if serveErr := <-serveErr; !errors.Is(serveErr, http.ErrServerClosed) && err == nil {
+		err = serveErr
+	}
+	return err
+}
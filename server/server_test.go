@@ -0,0 +1,227 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/client"
+	"github.com/graph-gophers/graphql-go/server"
+)
+
+const schemaString = `
+	type Query {
+		hello: String!
+	}
+	type Subscription {
+		count(to: Int!): Int!
+		forever: Int!
+	}
+`
+
+type resolver struct{}
+
+func (r *resolver) Hello() string { return "Hello, world!" }
+
+func (r *resolver) Count(ctx context.Context, args struct{ To int32 }) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		for i := int32(1); i <= args.To; i++ {
+			select {
+			case c <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c
+}
+
+func (r *resolver) Forever(ctx context.Context) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(c)
+		select {
+		case c <- 1:
+		case <-ctx.Done():
+			return
+		}
+		<-ctx.Done()
+	}()
+	return c
+}
+
+func TestServer(t *testing.T) {
+	schema := graphql.MustParseSchema(schemaString, &resolver{})
+	srv := httptest.NewServer(&server.Server{Schema: schema})
+	defer srv.Close()
+
+	t.Run("query", func(t *testing.T) {
+		c := &client.Client{URL: srv.URL + "/query"}
+		var res struct{ Hello string }
+		if err := c.Do(context.Background(), graphql.Request{Query: `{ hello }`}, &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Hello != "Hello, world!" {
+			t.Errorf("got %q", res.Hello)
+		}
+	})
+
+	for _, protocol := range []string{client.GraphQLTransportWS, client.GraphQLWS} {
+		t.Run("subscription "+protocol, func(t *testing.T) {
+			c := &client.Client{URL: srv.URL + "/query", Protocol: protocol}
+			sub, err := c.Subscribe(context.Background(), graphql.Request{
+				Query:     `subscription($to: Int!) { count(to: $to) }`,
+				Variables: map[string]interface{}{"to": 3},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sub.Close()
+			var got []int32
+			for {
+				var res struct{ Count int32 }
+				err := sub.Next(&res)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, res.Count)
+			}
+			if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+				t.Errorf("got events %v, want [1 2 3]", got)
+			}
+		})
+	}
+
+	t.Run("subscription error", func(t *testing.T) {
+		c := &client.Client{URL: srv.URL + "/query", Protocol: client.GraphQLTransportWS}
+		sub, err := c.Subscribe(context.Background(), graphql.Request{Query: `subscription { unknown }`})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		if err := sub.Next(nil); err == nil || !strings.Contains(err.Error(), `Cannot query field "unknown"`) {
+			t.Errorf("got error %v, want a validation error", err)
+		}
+	})
+
+	t.Run("playground", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `subscriptionUrl: new URL("/query"`) {
+			t.Errorf("got status %d and page:\n%s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/other")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+}
+
+func TestCacheControlHeader(t *testing.T) {
+	schema := graphql.MustParseSchema(schemaString, &resolver{}, graphql.CacheControl(time.Minute))
+	srv := httptest.NewServer(&server.Server{Schema: schema})
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/query", "application/json", strings.NewReader(`{"query":"{ hello }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got, want := resp.Header.Get("Cache-Control"), "max-age=60, public"; got != want {
+		t.Errorf("got Cache-Control %q, want %q", got, want)
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	schema := graphql.MustParseSchema(schemaString, &resolver{})
+	srv := httptest.NewServer(&server.Server{Schema: schema})
+	defer srv.Close()
+
+	c := &client.Client{
+		URL: srv.URL + "/query",
+		Header: func(ctx context.Context) http.Header {
+			return http.Header{"Origin": []string{"https://evil.example"}}
+		},
+	}
+	_, err := c.Subscribe(context.Background(), graphql.Request{Query: `subscription { forever }`})
+	if statusErr, ok := err.(*client.StatusError); !ok || statusErr.StatusCode != http.StatusForbidden {
+		t.Errorf("got error %v, want status %d", err, http.StatusForbidden)
+	}
+}
+
+func TestServeShutdown(t *testing.T) {
+	schema := graphql.MustParseSchema(schemaString, &resolver{})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- (&server.Server{Schema: schema, ShutdownTimeout: 5 * time.Second}).Serve(ctx, ln)
+	}()
+
+	c := &client.Client{URL: "http://" + ln.Addr().String() + "/query", Protocol: client.GraphQLTransportWS}
+	sub, err := c.Subscribe(context.Background(), graphql.Request{Query: `subscription { forever }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	var res struct{ Forever int32 }
+	if err := sub.Next(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if err := sub.Next(&res); err != io.EOF {
+		t.Errorf("got error %v after shutdown, want io.EOF", err)
+	}
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("got error %v, want a graceful shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestWebSocketMaxMessageSize(t *testing.T) {
+	schema := graphql.MustParseSchema(schemaString, &resolver{})
+	// a negative size keeps the default limit of 1 MB
+	srv := httptest.NewServer(&server.Server{Schema: schema, MaxMessageSize: -1})
+	defer srv.Close()
+
+	c := &client.Client{URL: srv.URL + "/query", Protocol: client.GraphQLTransportWS}
+	sub, err := c.Subscribe(context.Background(), graphql.Request{Query: `subscription { forever }` + strings.Repeat(" ", 2<<20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	var res struct{ Forever int32 }
+	if err := sub.Next(&res); err != io.EOF {
+		t.Errorf("got error %v, want the connection to be closed", err)
+	}
+}
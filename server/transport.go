@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/internal/websocket"
+)
+
+// Websocket subprotocols of GraphQL over websockets.
+const (
+	// GraphQLTransportWS is the protocol of the graphql-ws library, used by GraphiQL
+	// (https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md).
+	GraphQLTransportWS = "graphql-transport-ws"
+	// GraphQLWS is the legacy protocol of the subscriptions-transport-ws library
+	// (https://github.com/apollographql/subscriptions-transport-ws/blob/master/PROTOCOL.md).
+	GraphQLWS = "graphql-ws"
+)
+
+// Close codes of websockets, see RFC 6455, and of the graphql-transport-ws protocol.
+const (
+	closeNormal           = 1000
+	closeGoingAway        = 1001
+	closeMessageTooBig    = 1009
+	closeInvalidMessage   = 4400
+	closeUnauthorized     = 4401
+	closeInitTimeout      = 4408
+	closeSubscriberExists = 4409
+	closeTooManyInits     = 4429
+)
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// messageTypes are the names of the messages which differ between the protocols.
+type messageTypes struct {
+	subscribe, stop, next string
+}
+
+var protocolMessages = map[string]messageTypes{
+	GraphQLTransportWS: {subscribe: "subscribe", stop: "complete", next: "next"},
+	GraphQLWS:          {subscribe: "start", stop: "stop", next: "data"},
+}
+
+// wsSession executes the operations of a websocket connection.
+type wsSession struct {
+	server   *Server
+	conn     *websocket.Conn
+	protocol string
+	types    messageTypes
+
+	mu   sync.Mutex
+	ops  map[string]context.CancelFunc
+	idle chan struct{} // closed once no operation runs after the schema shut down
+	wg   sync.WaitGroup
+}
+
+// serveWebSocket upgrades r to a websocket and executes the operations sent over it until the client
+// or the server closes it. Once the schema shuts down, the connection is closed after the running
+// operations completed.
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	// counted before the connection is hijacked, while http.Server.Shutdown still waits for it
+	s.conns.Add(1)
+	defer s.conns.Done()
+	conn, protocol, err := upgradeWebSocket(w, r, []string{GraphQLTransportWS, GraphQLWS}, s.CheckOrigin, s.readLimit())
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	sess := &wsSession{
+		server:   s,
+		conn:     conn,
+		protocol: protocol,
+		types:    protocolMessages[protocol],
+		ops:      make(map[string]context.CancelFunc),
+	}
+	go func() {
+		select {
+		case <-s.Schema.ShuttingDown():
+			select {
+			case <-sess.drain():
+				conn.Close(closeGoingAway, "Server shutting down")
+			case <-ctx.Done():
+			}
+		case <-ctx.Done():
+		}
+	}()
+
+	code, reason := sess.run(ctx)
+	cancel()
+	sess.wg.Wait()
+	conn.Close(code, reason)
+}
+
+// run reads the messages of the client until the connection ends and returns the close code and
+// reason.
+func (sess *wsSession) run(ctx context.Context) (uint16, string) {
+	initTimeout := sess.server.ConnectionInitTimeout
+	if initTimeout == 0 {
+		initTimeout = 10 * time.Second
+	}
+	sess.conn.NetConn().SetReadDeadline(time.Now().Add(initTimeout))
+	initialized := false
+	for {
+		b, err := sess.conn.ReadMessage()
+		if err != nil {
+			var netErr net.Error
+			switch {
+			case !initialized && errors.As(err, &netErr) && netErr.Timeout():
+				return closeInitTimeout, "Connection initialisation timeout"
+			case err == websocket.ErrMessageTooLarge:
+				return closeMessageTooBig, "Message too large"
+			}
+			return closeNormal, ""
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(b, &msg); err != nil {
+			return closeInvalidMessage, "Invalid message received"
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			if initialized {
+				return closeTooManyInits, "Too many initialisation requests"
+			}
+			initialized = true
+			sess.conn.NetConn().SetReadDeadline(time.Time{})
+			if err := sess.conn.WriteJSON(&wsMessage{Type: "connection_ack"}); err != nil {
+				return closeNormal, ""
+			}
+			if sess.protocol == GraphQLWS {
+				sess.conn.WriteJSON(&wsMessage{Type: "ka"})
+			}
+
+		case "ping":
+			sess.conn.WriteJSON(&wsMessage{Type: "pong", Payload: msg.Payload})
+
+		case "pong":
+
+		case sess.types.subscribe:
+			if !initialized {
+				return closeUnauthorized, "Unauthorized"
+			}
+			if msg.ID == "" {
+				return closeInvalidMessage, "Invalid message received"
+			}
+			if !sess.start(ctx, msg.ID, msg.Payload) {
+				return closeSubscriberExists, "Subscriber for " + msg.ID + " already exists"
+			}
+
+		case sess.types.stop:
+			sess.stop(msg.ID)
+
+		case "connection_terminate":
+			return closeNormal, ""
+
+		default:
+			return closeInvalidMessage, "Invalid message received"
+		}
+	}
+}
+
+// drain rejects new operations and returns a channel which is closed once the running operations
+// ended.
+func (sess *wsSession) drain() <-chan struct{} {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.idle = make(chan struct{})
+	if len(sess.ops) == 0 {
+		close(sess.idle)
+	}
+	return sess.idle
+}
+
+// start executes the operation id with the request payload. It returns false if the operation id is
+// already running.
+func (sess *wsSession) start(ctx context.Context, id string, payload json.RawMessage) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if _, ok := sess.ops[id]; ok {
+		return false
+	}
+	if sess.idle != nil {
+		go sess.sendErrors(id, []*qerrors.QueryError{qerrors.Errorf("%s", graphql.ErrShutdown)})
+		return true
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sess.ops[id] = cancel
+	sess.wg.Add(1)
+	go func() {
+		defer sess.wg.Done()
+		defer cancel()
+		sess.execute(ctx, id, payload)
+		// operations stopped by the client are not completed by the server
+		if sess.remove(id) {
+			sess.conn.WriteJSON(&wsMessage{ID: id, Type: "complete"})
+		}
+	}()
+	return true
+}
+
+// stop cancels the operation id.
+func (sess *wsSession) stop(id string) {
+	sess.mu.Lock()
+	cancel, ok := sess.ops[id]
+	sess.deleteLocked(id)
+	sess.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// remove forgets the operation id and reports whether it was still running.
+func (sess *wsSession) remove(id string) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	_, ok := sess.ops[id]
+	sess.deleteLocked(id)
+	return ok
+}
+
+func (sess *wsSession) deleteLocked(id string) {
+	if _, ok := sess.ops[id]; !ok {
+		return
+	}
+	delete(sess.ops, id)
+	if sess.idle != nil && len(sess.ops) == 0 {
+		close(sess.idle)
+	}
+}
+
+// execute runs the operation id and sends its responses.
+func (sess *wsSession) execute(ctx context.Context, id string, payload json.RawMessage) {
+	var params struct {
+		Query         string          `json:"query"`
+		OperationName string          `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}
+	if err := json.Unmarshal(payload, &params); err != nil {
+		sess.sendErrors(id, []*qerrors.QueryError{qerrors.Errorf("invalid payload: %s", err)})
+		return
+	}
+	req := graphql.Request{Query: params.Query, OperationName: params.OperationName}
+	if len(params.Variables) != 0 && string(params.Variables) != "null" {
+		vars, err := graphql.DecodeVariables(sess.server.VariablesDecoder, params.Variables)
+		if err != nil {
+			sess.sendErrors(id, []*qerrors.QueryError{qerrors.Errorf("invalid variables: %s", err)})
+			return
+		}
+		req.Variables = vars
+	}
+
+	it, err := sess.server.Schema.Do(ctx, req)
+	if err != nil {
+		sess.sendErrors(id, []*qerrors.QueryError{qerrors.Errorf("%s", err)})
+		return
+	}
+	defer it.Close()
+	for first := true; ; first = false {
+		resp, ok := it.Next()
+		if !ok || ctx.Err() != nil {
+			return
+		}
+		// requests which were rejected before their execution started fail as a whole
+		if first && resp.Data == nil && len(resp.Errors) != 0 {
+			sess.sendErrors(id, resp.Errors)
+			return
+		}
+		data, err := sess.server.Schema.MarshalResponse(resp)
+		if err != nil {
+			sess.sendErrors(id, []*qerrors.QueryError{qerrors.Errorf("%s", err)})
+			return
+		}
+		if err := sess.conn.WriteJSON(&wsMessage{ID: id, Type: sess.types.next, Payload: data}); err != nil {
+			return
+		}
+	}
+}
+
+// sendErrors sends the error message of the operation id. The operation is not completed afterwards.
+func (sess *wsSession) sendErrors(id string, errs []*qerrors.QueryError) {
+	sess.remove(id)
+	var payload interface{} = errs
+	if sess.protocol == GraphQLWS {
+		// the legacy protocol sends a single error object
+		payload = errs[0]
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	sess.conn.WriteJSON(&wsMessage{ID: id, Type: "error", Payload: b})
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go/internal/websocket"
+)
+
+// isWebSocketUpgrade reports whether r asks to upgrade the connection to the websocket protocol.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") && headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// upgradeWebSocket completes the websocket handshake of r with the first of the subprotocols requested
+// by the client which is contained in protocols. Failures are answered with an HTTP error.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, protocols []string, checkOrigin func(r *http.Request) bool, limit int64) (*websocket.Conn, string, error) {
+	fail := func(status int, format string, a ...interface{}) (*websocket.Conn, string, error) {
+		err := fmt.Errorf(format, a...)
+		http.Error(w, err.Error(), status)
+		return nil, "", err
+	}
+	if r.Method != http.MethodGet {
+		return fail(http.StatusMethodNotAllowed, "websocket handshake requires method GET")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusUpgradeRequired, "unsupported websocket version %q", r.Header.Get("Sec-WebSocket-Version"))
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return fail(http.StatusBadRequest, "missing Sec-WebSocket-Key header")
+	}
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return fail(http.StatusForbidden, "origin %q not allowed", r.Header.Get("Origin"))
+	}
+	var protocol string
+	for _, p := range headerValues(r.Header, "Sec-WebSocket-Protocol") {
+		for _, supported := range protocols {
+			if protocol == "" && p == supported {
+				protocol = p
+			}
+		}
+	}
+	if protocol == "" {
+		return fail(http.StatusBadRequest, "unsupported websocket subprotocol, expected one of %s", strings.Join(protocols, ", "))
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, "connection can not be upgraded to a websocket")
+	}
+
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return fail(http.StatusInternalServerError, "%s", err)
+	}
+	fmt.Fprintf(brw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\nSec-WebSocket-Protocol: %s\r\n\r\n",
+		websocket.AcceptKey(key), protocol)
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, "", err
+	}
+	return websocket.NewServerConn(conn, brw.Reader, limit), protocol, nil
+}
+
+// sameOrigin reports whether the Origin header of r is absent or names the host of r, so that
+// browsers can not open websockets with the cookies of the user from other sites.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// headerValues returns the comma separated values of the header name.
+func headerValues(h http.Header, name string) []string {
+	var values []string
+	for _, v := range h.Values(name) {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range headerValues(h, name) {
+		if strings.EqualFold(v, token) {
+			return true
+		}
+	}
+	return false
+}
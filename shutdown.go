@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShutdown is returned by [Schema.Subscribe] once [Schema.Shutdown] has been called.
+var ErrShutdown = errors.New("graphql: schema is shut down")
+
+// subscriptionTracker keeps track of the active subscriptions of a schema. The zero value is ready
+// to use.
+type subscriptionTracker struct {
+	mu       sync.Mutex
+	next     int
+	cancels  map[int]context.CancelFunc
+	wg       sync.WaitGroup
+	shutdown chan struct{}
+	closed   bool
+}
+
+// add registers a subscription with the context ctx. It returns the context of the subscription,
+// which is cancelled on shutdown, and the function to call once the subscription ended.
+func (t *subscriptionTracker) add(ctx context.Context) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, nil, ErrShutdown
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	if t.cancels == nil {
+		t.cancels = make(map[int]context.CancelFunc)
+	}
+	id := t.next
+	t.next++
+	t.cancels[id] = cancel
+	t.wg.Add(1)
+	return ctx, func() {
+		t.mu.Lock()
+		delete(t.cancels, id)
+		t.mu.Unlock()
+		cancel()
+		t.wg.Done()
+	}, nil
+}
+
+func (t *subscriptionTracker) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+// shuttingDown returns the channel which is closed on shutdown.
+func (t *subscriptionTracker) shuttingDown() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.shutdown == nil {
+		t.shutdown = make(chan struct{})
+	}
+	return t.shutdown
+}
+
+// close rejects new subscriptions and cancels the active ones. It returns a channel which is closed
+// once all of them ended.
+func (t *subscriptionTracker) close() <-chan struct{} {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		if t.shutdown == nil {
+			t.shutdown = make(chan struct{})
+		}
+		close(t.shutdown)
+		for _, cancel := range t.cancels {
+			cancel()
+		}
+	}
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// Shutdown gracefully stops the subscriptions of the schema, e.g. to drain a server during a deploy.
+// It closes the channel returned by [Schema.ShuttingDown], so that transports can stop accepting
+// connections, makes [Schema.Subscribe] return [ErrShutdown] and cancels the contexts of all active
+// subscriptions, whose response channels are closed. Shutdown waits until the executor stopped reading
+// from the channels of the subscription resolvers or ctx is done, in which case it returns the error
+// of ctx. Queries and mutations are not affected.
+func (s *Schema) Shutdown(ctx context.Context) error {
+	select {
+	case <-s.subscriptions.close():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ShuttingDown returns a channel which is closed once [Schema.Shutdown] has been called.
+func (s *Schema) ShuttingDown() <-chan struct{} {
+	return s.subscriptions.shuttingDown()
+}
@@ -4,12 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	qerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/graph-gophers/graphql-go/live"
+	"github.com/graph-gophers/graphql-go/trace/noop"
 )
 
 type rootResolver struct {
@@ -27,6 +33,13 @@ func (r *helloResolver) Hello() string {
 var errResolver = errors.New("resolver error")
 var resolverQueryErr = &qerrors.QueryError{Message: "query", ResolverError: errResolver}
 
+// resolverErrorAt returns the error of errResolver for the field at line and column of the query.
+func resolverErrorAt(line, column int) *qerrors.QueryError {
+	err := qerrors.Errorf("%s", errResolver)
+	err.Locations = []qerrors.Location{{Line: line, Column: column}}
+	return err
+}
+
 type helloSaidResolver struct {
 	err      error
 	upstream <-chan *helloSaidEventResolver
@@ -153,7 +166,7 @@ func TestSchemaSubscribe(t *testing.T) {
 					Data: json.RawMessage(`
 						null
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{resolverErrorAt(4, 7)},
 				},
 				{
 					Data: json.RawMessage(`
@@ -211,7 +224,7 @@ func TestSchemaSubscribe(t *testing.T) {
 					Data: json.RawMessage(`
 						null
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{resolverErrorAt(3, 6)},
 				},
 			},
 		},
@@ -240,7 +253,7 @@ func TestSchemaSubscribe(t *testing.T) {
 							}
 						}
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{resolverErrorAt(4, 7)},
 				},
 			},
 		},
@@ -263,7 +276,7 @@ func TestSchemaSubscribe(t *testing.T) {
 							"helloSaidNullable": null
 						}
 					`),
-					Errors: []*qerrors.QueryError{qerrors.Errorf("%s", errResolver)},
+					Errors: []*qerrors.QueryError{resolverErrorAt(3, 6)},
 				},
 			},
 		},
@@ -541,6 +554,30 @@ func TestSchemaSubscribe_CustomResolverTimeout(t *testing.T) {
 	})
 }
 
+func TestSchemaSubscribe_ResolverTimeoutCode(t *testing.T) {
+	schema := graphql.MustParseSchema(`
+		type Query {}
+		type Subscription {
+			onTimeout : Message!
+		}
+
+		type Message {
+			msg: String!
+		}
+	`, &subscriptionsCustomTimeout{}, graphql.SubscribeResolverTimeout(time.Nanosecond))
+
+	c, err := schema.Subscribe(context.Background(), `subscription { onTimeout { msg } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := (<-c).(*graphql.Response)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != qerrors.CodeTimeout {
+		t.Errorf("got errors %v, want an error with code %s", resp.Errors, qerrors.CodeTimeout)
+	}
+	for range c {
+	}
+}
+
 type subscriptionsPanicInResolver struct{}
 
 func (r *subscriptionsPanicInResolver) OnPanic() <-chan string {
@@ -570,3 +607,554 @@ func TestSchemaSubscribe_PanicInResolver(t *testing.T) {
 		},
 	})
 }
+
+type panickingEvent struct{}
+
+func (panickingEvent) X() string {
+	panic("panickingEvent")
+}
+
+type subscriptionsPanicInEvent struct{}
+
+func (r *subscriptionsPanicInEvent) OnEvent() <-chan *panickingEvent {
+	c := make(chan *panickingEvent, 1)
+	c <- &panickingEvent{}
+	close(c)
+	return c
+}
+
+func TestSchemaSubscribe_PanicInEventField(t *testing.T) {
+	gqltesting.RunSubscribe(t, &gqltesting.TestSubscription{
+		Schema: graphql.MustParseSchema(`
+			type Query {}
+			type Subscription {
+				onEvent: Ev
+			}
+
+			type Ev {
+				x: String!
+			}
+		`, &subscriptionsPanicInEvent{}),
+		Query: `
+			subscription {
+				onEvent { x }
+			}
+		`,
+		ExpectedResults: []gqltesting.TestResponse{
+			{
+				Data:   json.RawMessage(`{"onEvent":null}`),
+				Errors: []*qerrors.QueryError{{Message: "panic occurred: panickingEvent", Locations: []qerrors.Location{{Line: 3, Column: 15}}}},
+			},
+		},
+	})
+}
+
+type thunkSubscription struct {
+	loader *thunkLoader
+}
+
+func (r *thunkSubscription) OnItems() <-chan *thunkQuery {
+	c := make(chan *thunkQuery, 1)
+	c <- &thunkQuery{ids: []string{"c", "a", "b"}, loader: r.loader}
+	close(c)
+	return c
+}
+
+func TestSchemaSubscribe_EventOptions(t *testing.T) {
+	sdl := `
+		type Query {}
+		type Subscription {
+			onItems: Items!
+		}
+
+		type Items {
+			items: [Item!]!
+		}
+
+		type Item {
+			id: ID!
+			name: String
+		}
+	`
+	loader := &thunkLoader{}
+	gqltesting.RunSubscribe(t, &gqltesting.TestSubscription{
+		Schema: graphql.MustParseSchema(sdl, &thunkSubscription{loader: loader}),
+		Query:  `subscription { onItems { items { name } } }`,
+		ExpectedResults: []gqltesting.TestResponse{
+			{Data: json.RawMessage(`{"onItems":{"items":[{"name":"C"},{"name":"A"},{"name":"B"}]}}`)},
+		},
+	})
+	if want := [][]string{{"a", "b", "c"}}; !reflect.DeepEqual(loader.batches, want) {
+		t.Errorf("want batches %q, got %q", want, loader.batches)
+	}
+
+	schema := graphql.MustParseSchema(sdl, &thunkSubscription{loader: &thunkLoader{}}, graphql.MaxResponseBytes(10))
+	c, err := schema.Subscribe(context.Background(), `subscription { onItems { items { id } } }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := (<-c).(*graphql.Response)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != qerrors.CodeResponseTooLarge {
+		t.Errorf("got errors %v, want an error with code %s", resp.Errors, qerrors.CodeResponseTooLarge)
+	}
+	for range c {
+	}
+}
+
+type eventAuthorKey struct{}
+
+type authoredEventResolver struct {
+	graphql.EventContext
+	msg string
+}
+
+func (r *authoredEventResolver) Msg() string {
+	return r.msg
+}
+
+func (r *authoredEventResolver) Author(ctx context.Context) string {
+	author, _ := ctx.Value(eventAuthorKey{}).(string)
+	return author
+}
+
+type eventContextResolver struct{}
+
+func (r *eventContextResolver) MessageSent() <-chan *authoredEventResolver {
+	c := make(chan *authoredEventResolver, 2)
+	for _, author := range []string{"alice", "bob"} {
+		author := author
+		c <- &authoredEventResolver{
+			EventContext: graphql.EventContext{With: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, eventAuthorKey{}, author)
+			}},
+			msg: "hello from " + author,
+		}
+	}
+	close(c)
+	return c
+}
+
+func TestSchemaSubscribe_EventContext(t *testing.T) {
+	gqltesting.RunSubscribe(t, &gqltesting.TestSubscription{
+		Schema: graphql.MustParseSchema(`
+			type Query {}
+			type Subscription {
+				messageSent: Message!
+			}
+
+			type Message {
+				msg: String!
+				author: String!
+			}
+		`, &eventContextResolver{}),
+		Query: `
+			subscription {
+				messageSent { msg author }
+			}
+		`,
+		ExpectedResults: []gqltesting.TestResponse{
+			{Data: json.RawMessage(`{"messageSent":{"msg":"hello from alice","author":"alice"}}`)},
+			{Data: json.RawMessage(`{"messageSent":{"msg":"hello from bob","author":"bob"}}`)},
+		},
+	})
+}
+
+func TestSchemaDo(t *testing.T) {
+	s := graphql.MustParseSchema(schema, &rootResolver{
+		helloSaidResolver: &helloSaidResolver{
+			upstream: closedUpstream(
+				&helloSaidEventResolver{msg: "Hello world!"},
+				&helloSaidEventResolver{msg: "Hello again!"},
+			),
+		},
+	})
+
+	collect := func(req graphql.Request) []string {
+		it, err := s.Do(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer it.Close()
+		var got []string
+		for resp, ok := it.Next(); ok; resp, ok = it.Next() {
+			b, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, string(b))
+		}
+		return got
+	}
+
+	tests := []struct {
+		name string
+		req  graphql.Request
+		want []string
+	}{
+		{
+			name: "query",
+			req:  graphql.Request{Query: `{ hello }`},
+			want: []string{`{"data":{"hello":"Hello world!"}}`},
+		},
+		{
+			name: "subscription",
+			req:  graphql.Request{Query: `subscription onHelloSaid { helloSaid { msg } }`, OperationName: "onHelloSaid"},
+			want: []string{
+				`{"data":{"helloSaid":{"msg":"Hello world!"}}}`,
+				`{"data":{"helloSaid":{"msg":"Hello again!"}}}`,
+			},
+		},
+		{
+			name: "invalid",
+			req:  graphql.Request{Query: `subscription {`},
+			want: []string{`{"errors":[{"message":"syntax error: unexpected \"\", expecting Ident","locations":[{"line":1,"column":15}],"extensions":{"code":"GRAPHQL_PARSE_FAILED"}}]}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(tt.req); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type parseCountTracer struct {
+	noop.Tracer
+	parses int32
+}
+
+func (t *parseCountTracer) TraceParse(context.Context, string) func(*qerrors.QueryError) {
+	atomic.AddInt32(&t.parses, 1)
+	return func(*qerrors.QueryError) {}
+}
+
+func TestSchemaDo_MaxQueryLength(t *testing.T) {
+	tracer := &parseCountTracer{}
+	s := graphql.MustParseSchema(schema, &rootResolver{
+		helloSaidResolver: &helloSaidResolver{
+			upstream: closedUpstream(&helloSaidEventResolver{msg: "Hello world!"}),
+		},
+	}, graphql.MaxQueryLength(40), graphql.Tracer(tracer))
+
+	for _, query := range []string{`{ hello }`, `subscription { helloSaid { msg } }`} {
+		atomic.StoreInt32(&tracer.parses, 0)
+		it, err := s.Do(context.Background(), graphql.Request{Query: query})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for resp, ok := it.Next(); ok; resp, ok = it.Next() {
+			if len(resp.Errors) != 0 {
+				t.Errorf("%s: got errors %v", query, resp.Errors)
+			}
+		}
+		it.Close()
+		if n := atomic.LoadInt32(&tracer.parses); n != 1 {
+			t.Errorf("%s: parsed %d times, want once", query, n)
+		}
+
+		atomic.StoreInt32(&tracer.parses, 0)
+		long := query + strings.Repeat(" ", 40)
+		it, err = s.Do(context.Background(), graphql.Request{Query: long})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, ok := it.Next()
+		it.Close()
+		if !ok || len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != qerrors.CodeQueryTooLong {
+			t.Errorf("%s: got %v, want a %s error", query, resp, qerrors.CodeQueryTooLong)
+		}
+		if n := atomic.LoadInt32(&tracer.parses); n != 0 {
+			t.Errorf("%s: parsed a too long query %d times", query, n)
+		}
+	}
+}
+
+func TestSchemaDo_Close(t *testing.T) {
+	upstream := make(chan *helloSaidEventResolver)
+	s := graphql.MustParseSchema(schema, &rootResolver{
+		helloSaidResolver: &helloSaidResolver{upstream: upstream},
+	})
+
+	it, err := s.Do(context.Background(), graphql.Request{Query: `subscription { helloSaid { msg } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream <- &helloSaidEventResolver{msg: "Hello world!"}
+	if resp, ok := it.Next(); !ok || len(resp.Errors) != 0 {
+		t.Fatalf("got %v, %t, want an event", resp, ok)
+	}
+	it.Close()
+	if _, ok := it.Next(); ok {
+		t.Error("got an event after Close")
+	}
+}
+
+type slowSubscriptionResolver struct{}
+
+func (*slowSubscriptionResolver) Tick(ctx context.Context) (<-chan int32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSchemaSubscribe_InitTimeout(t *testing.T) {
+	gqltesting.RunSubscribe(t, &gqltesting.TestSubscription{
+		Schema: graphql.MustParseSchema(`
+			type Query {}
+			type Subscription {
+				tick: Int!
+			}
+		`, &slowSubscriptionResolver{}, graphql.SubscriptionInitTimeout(20*time.Millisecond)),
+		Query: `subscription { tick }`,
+		ExpectedResults: []gqltesting.TestResponse{
+			{Errors: []*qerrors.QueryError{qerrors.Errorf("subscription timed out after 20ms")}},
+		},
+	})
+}
+
+type completingEventResolver struct {
+	*graphql.Completion
+	msg string
+}
+
+func (r *completingEventResolver) Msg() string {
+	return r.msg
+}
+
+type completingResolver struct {
+	completion *graphql.Completion
+}
+
+func (r *completingResolver) MessageSent() (<-chan *completingEventResolver, error) {
+	c := make(chan *completingEventResolver, 3)
+	c <- &completingEventResolver{msg: "hello"}
+	c <- &completingEventResolver{Completion: r.completion}
+	c <- &completingEventResolver{msg: "never sent"}
+	return c, nil
+}
+
+func TestSchemaSubscribe_Completion(t *testing.T) {
+	const sdl = `
+		type Query {}
+		type Subscription {
+			messageSent: Message!
+		}
+
+		type Message {
+			msg: String!
+		}
+	`
+	for _, tt := range []struct {
+		name       string
+		completion *graphql.Completion
+		want       graphql.Response
+	}{
+		{
+			name:       "graceful",
+			completion: &graphql.Completion{Extensions: map[string]interface{}{"reason": "room closed"}},
+			want: graphql.Response{
+				Extensions: map[string]interface{}{"reason": "room closed"},
+				Completion: graphql.Completed,
+			},
+		},
+		{
+			name:       "error",
+			completion: &graphql.Completion{Err: errResolver},
+			want: graphql.Response{
+				Errors:     []*qerrors.QueryError{{Message: errResolver.Error()}},
+				Completion: graphql.CompletedWithError,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := graphql.MustParseSchema(sdl, &completingResolver{completion: tt.completion})
+			c, err := s.Subscribe(context.Background(), `subscription { messageSent { msg } }`, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var results []*graphql.Response
+			for res := range c {
+				results = append(results, res.(*graphql.Response))
+			}
+			if len(results) != 2 {
+				t.Fatalf("got %d responses, want 2", len(results))
+			}
+			if got := string(results[0].Data); got != `{"messageSent":{"msg":"hello"}}` || results[0].Completion != graphql.NotCompleted {
+				t.Errorf("unexpected first response %s with %s", got, results[0].Completion)
+			}
+			final := *results[1]
+			for _, err := range final.Errors {
+				if !errors.Is(err, errResolver) {
+					t.Errorf("got error %v, want %v", err, errResolver)
+				}
+				err.Err, err.ResolverError = nil, nil
+			}
+			if !reflect.DeepEqual(final, tt.want) {
+				t.Errorf("unexpected final response:\ngot  %+v\nwant %+v", final, tt.want)
+			}
+		})
+	}
+
+	// the embedded Completion is not reported as an unused resolver method
+	if _, err := graphql.ParseSchema(sdl, &completingResolver{}, graphql.StrictResolvers()); err != nil {
+		t.Errorf("strict resolvers: %s", err)
+	}
+}
+
+type tickerResolver struct {
+	closed chan struct{}
+}
+
+func (r *tickerResolver) Tick(ctx context.Context) <-chan int32 {
+	c := make(chan int32)
+	go func() {
+		defer close(r.closed)
+		for i := int32(0); ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- i:
+			}
+		}
+	}()
+	return c
+}
+
+func TestSchemaShutdown(t *testing.T) {
+	r := &tickerResolver{closed: make(chan struct{})}
+	s := graphql.MustParseSchema(`
+		type Query {}
+		type Subscription {
+			tick: Int!
+		}
+	`, r)
+
+	c, err := s.Subscribe(context.Background(), `subscription { tick }`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := (<-c).(*graphql.Response); len(resp.Errors) != 0 {
+		t.Fatal(resp.Errors)
+	}
+
+	select {
+	case <-s.ShuttingDown():
+		t.Fatal("shutting down before Shutdown")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for range c {
+	}
+	select {
+	case <-r.closed:
+	case <-time.After(time.Second):
+		t.Error("the context of the resolver was not cancelled")
+	}
+	select {
+	case <-s.ShuttingDown():
+	default:
+		t.Error("not shutting down after Shutdown")
+	}
+
+	if _, err := s.Subscribe(context.Background(), `subscription { tick }`, "", nil); err != graphql.ErrShutdown {
+		t.Errorf("got error %v after Shutdown, want %v", err, graphql.ErrShutdown)
+	}
+	if resp := s.Exec(context.Background(), `{ __typename }`, "", nil); len(resp.Errors) != 0 {
+		t.Errorf("queries failed after Shutdown: %v", resp.Errors)
+	}
+}
+
+type liveResolver struct {
+	mu    sync.Mutex
+	texts map[graphql.ID]string
+}
+
+func (r *liveResolver) Todo(args struct{ ID graphql.ID }) *liveTodoResolver {
+	return &liveTodoResolver{root: r, id: args.ID}
+}
+
+func (r *liveResolver) setText(id graphql.ID, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts[id] = text
+}
+
+type liveTodoResolver struct {
+	root *liveResolver
+	id   graphql.ID
+}
+
+func (r *liveTodoResolver) Text(ctx context.Context) string {
+	graphql.LiveDependency(ctx, "Todo:"+string(r.id))
+	r.root.mu.Lock()
+	defer r.root.mu.Unlock()
+	return r.root.texts[r.id]
+}
+
+func TestLiveQueries(t *testing.T) {
+	const sdl = `
+		directive @live on QUERY
+		type Query {
+			todo(id: ID!): Todo!
+		}
+		type Todo {
+			text: String!
+		}
+	`
+	res := &liveResolver{texts: map[graphql.ID]string{"1": "a", "2": "x"}}
+	invalidations := live.New()
+	schema := graphql.MustParseSchema(sdl, res, graphql.LiveQueries(invalidations))
+
+	it, err := schema.Do(context.Background(), graphql.Request{Query: `query @live { todo(id: "1") { text } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := func() string {
+		resp, ok := it.Next()
+		if !ok {
+			t.Fatal("live query ended")
+		}
+		if len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+		return string(resp.Data)
+	}
+	if got, want := next(), `{"todo":{"text":"a"}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	invalidations.Invalidate("Todo:2")
+	res.setText("1", "b")
+	invalidations.Invalidate("Todo:1")
+	if got, want := next(), `{"todo":{"text":"b"}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	// unchanged results are not sent
+	invalidations.Invalidate("Todo:1")
+	res.setText("1", "c")
+	invalidations.Invalidate("Query.todo")
+	if got, want := next(), `{"todo":{"text":"c"}}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	it.Close()
+	if _, ok := it.Next(); ok {
+		t.Error("expected the live query to end once the iterator is closed")
+	}
+	for invalidations.Subscribers() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// without the option, live queries are executed once
+	resp := graphql.MustParseSchema(sdl, res).Exec(context.Background(), `query @live { todo(id: "1") { text } }`, "", nil)
+	if got, want := string(resp.Data), `{"todo":{"text":"c"}}`; got != want || len(resp.Errors) != 0 {
+		t.Errorf("got data %s and errors %v, want %s", got, resp.Errors, want)
+	}
+}
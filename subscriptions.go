@@ -3,62 +3,178 @@ package graphql
 import (
 	"context"
 	"errors"
+	"strconv"
+	"time"
 
+	"github.com/graph-gophers/graphql-go/ast"
 	qerrors "github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/internal/common"
 	"github.com/graph-gophers/graphql-go/internal/exec"
 	"github.com/graph-gophers/graphql-go/internal/exec/resolvable"
 	"github.com/graph-gophers/graphql-go/internal/exec/selected"
 	"github.com/graph-gophers/graphql-go/internal/query"
-	"github.com/graph-gophers/graphql-go/internal/validation"
 	"github.com/graph-gophers/graphql-go/introspection"
 )
 
+// EventContext attaches an event-scoped context to a subscription event. Embed it into the type of
+// the values sent on the subscription channel to pass per-event values, e.g. the user who triggered
+// the event, to the resolvers of the event's fields:
+//
+//	type messageEvent struct {
+//		graphql.EventContext
+//		text string
+//	}
+//
+//	c <- &messageEvent{
+//		EventContext: graphql.EventContext{With: func(ctx context.Context) context.Context {
+//			return user.NewContext(ctx, author)
+//		}},
+//		text: text,
+//	}
+type EventContext struct {
+	// With derives the context of the event's field resolvers from the subscription context.
+	With func(ctx context.Context) context.Context
+}
+
+// ResolverContext returns the context used to resolve the fields of the event.
+func (e EventContext) ResolverContext(ctx context.Context) context.Context {
+	if e.With == nil {
+		return ctx
+	}
+	return e.With(ctx)
+}
+
+// Completion ends a subscription from its resolver. Embed a *Completion into the type of the
+// values sent on the subscription channel and send a value with it set as the last event:
+//
+//	type messageEvent struct {
+//		*graphql.Completion
+//		text string
+//	}
+//
+//	c <- &messageEvent{Completion: &graphql.Completion{
+//		Extensions: map[string]interface{}{"reason": "room closed"},
+//	}}
+//
+// The fields of such an event are not resolved. Instead, a final response with the
+// [Response.Completion] reason is sent and the response channel is closed. Closing the channel
+// without a Completion also completes the subscription gracefully, but without a final response.
+type Completion struct {
+	// Err closes the subscription with an error instead of completing it gracefully.
+	Err error
+	// Extensions are sent as the extensions of the final response.
+	Extensions map[string]interface{}
+}
+
+// SubscriptionCompletion reports whether the event ends the subscription, and how.
+func (c *Completion) SubscriptionCompletion() (done bool, extensions map[string]interface{}, err error) {
+	if c == nil {
+		return false, nil, nil
+	}
+	return true, c.Extensions, c.Err
+}
+
+// CompletionReason tells transports why the final response of a subscription was sent, e.g. to
+// choose between the complete and error messages of the WebSocket protocols.
+type CompletionReason int
+
+const (
+	// NotCompleted is the reason of all responses but the final one.
+	NotCompleted CompletionReason = iota
+	// Completed is the reason of the final response of a subscription completed gracefully.
+	Completed
+	// CompletedWithError is the reason of the final response of a subscription closed with an
+	// error. The error is in the errors of the response.
+	CompletedWithError
+)
+
+// String returns the name of the reason.
+func (r CompletionReason) String() string {
+	switch r {
+	case NotCompleted:
+		return "NotCompleted"
+	case Completed:
+		return "Completed"
+	case CompletedWithError:
+		return "CompletedWithError"
+	}
+	return "CompletionReason(" + strconv.Itoa(int(r)) + ")"
+}
+
 // Subscribe returns a response channel for the given subscription with the schema's
-// resolver. It returns an error if the schema was created without a resolver.
+// resolver. Live queries are re-executed on the channel, see [LiveQueries]. It returns an
+// error if the schema was created without a resolver.
 // If the context gets cancelled, the response channel will be closed and no
 // further resolvers will be called. The context error will be returned as soon
 // as possible (not immediately).
 func (s *Schema) Subscribe(ctx context.Context, queryString string, operationName string, variables map[string]interface{}) (<-chan interface{}, error) {
+	return s.subscribeRequest(ctx, nil, queryString, operationName, variables)
+}
+
+// subscribeRequest starts the subscription like Subscribe. doc is the parsed queryString, or nil if it
+// was not parsed yet.
+func (s *Schema) subscribeRequest(ctx context.Context, doc *ast.ExecutableDefinition, queryString string, operationName string, variables map[string]interface{}) (<-chan interface{}, error) {
 	if !s.res.SubscriptionResolver.IsValid() {
 		return nil, errors.New("schema created without resolver, can not subscribe")
 	}
-	if _, ok := s.schema.RootOperationTypes["subscription"]; !ok {
+	if _, ok := s.schema.RootOperationTypes["subscription"]; !ok && s.invalidationBus == nil {
 		return nil, errors.New("no subscriptions are offered by the schema")
 	}
-	return s.subscribe(ctx, queryString, operationName, variables, s.res), nil
+	if s.subscriptions.isClosed() {
+		return nil, ErrShutdown
+	}
+	return s.subscribe(ctx, doc, queryString, operationName, variables, s.res), nil
 }
 
-func (s *Schema) subscribe(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) <-chan interface{} {
-	doc, qErr := query.Parse(queryString)
-	if qErr != nil {
-		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
+func (s *Schema) subscribe(ctx context.Context, doc *ast.ExecutableDefinition, queryString string, operationName string, variables map[string]interface{}, res *resolvable.Schema) <-chan interface{} {
+	if doc == nil {
+		var qErr *qerrors.QueryError
+		if doc, qErr = s.parseRequest(ctx, queryString); qErr != nil {
+			return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qErr}})
+		}
 	}
 
 	validationFinish := s.validationTracer.TraceValidation(ctx)
-	errs := validation.Validate(s.schema, doc, variables, s.maxDepth)
+	errs := s.validate(ctx, doc, variables)
 	validationFinish(errs)
+	errs, warnings := s.splitWarnings(errs)
 	if len(errs) != 0 {
 		return sendAndReturnClosed(&Response{Errors: errs})
 	}
 
 	op, err := getOperation(doc, operationName)
 	if err != nil {
-		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{operationError(err)}})
+	}
+	if err := s.estimateCost(ctx, doc, op, variables); err != nil {
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{err}})
 	}
 
-	r := &exec.Request{
-		Request: selected.Request{
-			Doc:    doc,
-			Vars:   variables,
-			Schema: s.schema,
-		},
-		Limiter:                  make(chan struct{}, s.maxParallelism),
-		Tracer:                   s.tracer,
-		Logger:                   s.logger,
-		PanicHandler:             s.panicHandler,
-		SubscribeResolverTimeout: s.subscribeResolverTimeout,
+	newRequest := func() *exec.Request {
+		return &exec.Request{
+			Request: selected.Request{
+				Doc:            doc,
+				Vars:           variables,
+				Schema:         s.schema,
+				CoercionFailed: s.coercionFailedFor(ctx),
+			},
+			Limiter:                  make(chan struct{}, s.maxParallelism),
+			Tracer:                   s.tracer,
+			SkipTracer:               s.skipTracer,
+			Logger:                   s.logger,
+			PanicHandler:             s.panicHandler,
+			SubscribeResolverTimeout: s.subscribeResolverTimeout,
+			DisableNullBubbling:      s.disableNullBubbling,
+			RetryPolicy:              s.retryPolicy,
+			SortResponseKeys:         s.sortResponseKeys,
+			Marshal:                  s.encoder.Marshal,
+			MaxResponseBytes:         s.maxResponseBytes,
+			DeduplicateFields:        s.deduplicateFields,
+			LenientEnums:             s.lenientEnumOutput,
+			PlanMutation:             s.planMutation,
+		}
 	}
+	r := newRequest()
 	varTypes := make(map[string]*introspection.Type)
 	for _, v := range op.Vars {
 		t, err := common.ResolveType(v.Type, s.schema.Resolve)
@@ -68,30 +184,98 @@ func (s *Schema) subscribe(ctx context.Context, queryString string, operationNam
 		varTypes[v.Name.Name] = introspection.WrapType(t)
 	}
 
+	if s.isLive(op) {
+		return s.liveQuery(ctx, doc, newRequest, res, op, queryString, warnings)
+	}
 	if op.Type == query.Query || op.Type == query.Mutation {
-		data, errs := r.Execute(ctx, res, op)
-		return sendAndReturnClosed(&Response{Data: data, Errors: errs})
+		data, errs, executed := s.execute(ctx, r, res, op, queryString)
+		if s.responseCache != nil && op.Type == query.Mutation && executed {
+			s.responseCache.MutationExecuted(ctx, operationName)
+		}
+		resp := &Response{Data: data, Errors: errs}
+		warnings = append(warnings, r.Warnings()...)
+		if len(warnings) != 0 {
+			resp.setExtension(warningsExtension, warnings)
+		}
+		return sendAndReturnClosed(resp)
 	}
 
-	responses := r.Subscribe(ctx, res, op)
+	ctx, done, err := s.subscriptions.add(ctx)
+	if err != nil {
+		return sendAndReturnClosed(&Response{Errors: []*qerrors.QueryError{qerrors.Errorf("%s", err)}})
+	}
+	ctx, a := s.operations.start(ctx, op, queryString)
+	traceCtx, traceEvent, finish := s.tracer.TraceSubscription(ctx, queryString, operationName, variables, varTypes)
+	responses, cancel := s.subscribeWithTimeout(traceCtx, r, res, op)
 	c := make(chan interface{})
 	go func() {
+		defer done()
+		defer s.operations.finish(a)
+		defer finish()
 	Loop:
 		for resp := range responses {
+			out := subscriptionResponse(resp)
+			if len(warnings) != 0 {
+				// the warnings are reported with the first response only
+				out.setExtension(warningsExtension, warnings)
+				warnings = nil
+			}
 			select {
-			case c <- &Response{Data: resp.Data, Errors: resp.Errors}:
+			case c <- out:
+				traceEvent(resp.Errors, len(resp.Data))
 				continue
 
 			case <-ctx.Done():
 				break Loop
 			}
 		}
+		cancel()
+		// wait for the executor to stop reading from the channel of the resolver
+		for range responses {
+		}
 		close(c)
 	}()
 
 	return c
 }
 
+// subscribeWithTimeout subscribes to op, giving up once the subscription init timeout is exceeded.
+// The returned function has to be called once the responses are no longer received.
+func (s *Schema) subscribeWithTimeout(ctx context.Context, r *exec.Request, res *resolvable.Schema, op *ast.OperationDefinition) (<-chan *exec.Response, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if s.subscriptionInitTimeout <= 0 {
+		return r.Subscribe(ctx, res, op), cancel
+	}
+
+	subscribed := make(chan (<-chan *exec.Response), 1)
+	go func() {
+		subscribed <- r.Subscribe(ctx, res, op)
+	}()
+	t := time.NewTimer(s.subscriptionInitTimeout)
+	defer t.Stop()
+	select {
+	case responses := <-subscribed:
+		return responses, cancel
+	case <-t.C:
+		cancel()
+		c := make(chan *exec.Response, 1)
+		c <- &exec.Response{Errors: []*qerrors.QueryError{timeoutError(op.Type, s.subscriptionInitTimeout)}}
+		close(c)
+		return c, cancel
+	}
+}
+
+func subscriptionResponse(resp *exec.Response) *Response {
+	r := &Response{Data: resp.Data, Errors: resp.Errors, Extensions: resp.Extensions}
+	if resp.Final {
+		r.Completion = Completed
+		if len(resp.Errors) != 0 {
+			r.Completion = CompletedWithError
+		}
+	}
+	return r
+}
+
 func sendAndReturnClosed(resp *Response) chan interface{} {
 	c := make(chan interface{}, 1)
 	c <- resp
@@ -0,0 +1,310 @@
+// Package social provides a schema and resolver of users with roles and friends, which makes use of
+// interfaces, unions, input objects and struct field resolvers. The schema has to be parsed with the
+// graphql.UseFieldResolvers option. The resolver serves a configurable in-memory [Dataset] and can
+// simulate the latency of a backend.
+package social
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/testschemas"
+)
+
+const Schema = `
+	schema {
+		query: Query
+	}
+	
+	type Query {
+		admin(id: ID!, role: Role = ADMIN): Admin!
+		user(id: ID!): User!
+		search(text: String!): [SearchResult]!
+	}
+	
+	interface Admin {
+		id: ID!
+		name: String!
+		role: Role!
+	}
+
+	interface Person {
+		name: String!
+	}
+
+	scalar Time	
+
+	type User implements Admin & Person {
+		id: ID!
+		name: String!
+		email: String!
+		role: Role!
+		phone: String!
+		address: [String!]
+		friends(page: Pagination): [User]
+		createdAt: Time!
+	}
+
+	input Pagination {
+	  	first: Int
+	  	last: Int
+	}
+	
+	enum Role {
+		ADMIN
+		USER
+	}
+
+	union SearchResult = User
+`
+
+type page struct {
+	First *float64
+	Last  *float64
+}
+
+type admin interface {
+	ID() graphql.ID
+	Name() string
+	Role() string
+}
+
+type adminResolver struct {
+	admin
+}
+
+func (r *adminResolver) ToUser() (*user, bool) {
+	n, ok := r.admin.(user)
+	return &n, ok
+}
+
+type searchResult struct {
+	result interface{}
+}
+
+func (r *searchResult) ToUser() (*user, bool) {
+	res, ok := r.result.(*user)
+	return res, ok
+}
+
+type contact struct {
+	Email string
+	Phone string
+}
+
+type user struct {
+	IDField      string
+	NameField    string
+	RoleField    string
+	Address      *[]string
+	FriendsField *[]*user
+	CreatedAt    graphql.Time
+	contact
+}
+
+func (u user) ID() graphql.ID {
+	return graphql.ID(u.IDField)
+}
+
+func (u user) Name() string {
+	return u.NameField
+}
+
+func (u user) Role() string {
+	return u.RoleField
+}
+
+func (u user) Friends(args struct{ Page *page }) (*[]*user, error) {
+	var from int
+	numFriends := len(*u.FriendsField)
+	to := numFriends
+
+	if args.Page != nil {
+		if args.Page.First != nil {
+			from = int(*args.Page.First)
+			if from > numFriends {
+				return nil, errors.New("not enough users")
+			}
+		}
+		if args.Page.Last != nil {
+			to = int(*args.Page.Last)
+			if to == 0 || to > numFriends {
+				to = numFriends
+			}
+		}
+	}
+
+	friends := (*u.FriendsField)[from:to]
+
+	return &friends, nil
+}
+
+// User is a user of the dataset.
+type User struct {
+	ID   string
+	Name string
+	// Role is either "ADMIN" or "USER".
+	Role    string
+	Email   string
+	Phone   string
+	Address []string
+	// Friends are the IDs of the friends of the user.
+	Friends   []string
+	CreatedAt time.Time
+}
+
+// Dataset holds the users served by a [Resolver]. The fields must not be modified once the dataset
+// is in use.
+type Dataset struct {
+	Users []*User
+
+	once     sync.Once
+	users    []*user
+	usersMap map[string]*user
+}
+
+// DefaultDataset returns a new dataset with a few students and teachers of Hogwarts.
+func DefaultDataset() *Dataset {
+	now := time.Now()
+	return &Dataset{
+		Users: []*User{
+			{
+				ID:        "0x01",
+				Name:      "Albus Dumbledore",
+				Role:      "ADMIN",
+				Email:     "Albus@hogwarts.com",
+				Phone:     "000-000-0000",
+				Address:   []string{"Office @ Hogwarts", "where Horcruxes are"},
+				Friends:   []string{"0x02"},
+				CreatedAt: now,
+			},
+			{
+				ID:        "0x02",
+				Name:      "Harry Potter",
+				Role:      "USER",
+				Email:     "harry@hogwarts.com",
+				Phone:     "000-000-0001",
+				Address:   []string{"123 dorm room @ Hogwarts", "456 random place"},
+				Friends:   []string{"0x01", "0x03", "0x04"},
+				CreatedAt: now,
+			},
+			{
+				ID:        "0x03",
+				Name:      "Hermione Granger",
+				Role:      "USER",
+				Email:     "hermione@hogwarts.com",
+				Phone:     "000-000-0011",
+				Address:   []string{"233 dorm room @ Hogwarts", "786 @ random place"},
+				Friends:   []string{"0x02", "0x04"},
+				CreatedAt: now,
+			},
+			{
+				ID:        "0x04",
+				Name:      "Ronald Weasley",
+				Role:      "USER",
+				Email:     "ronald@hogwarts.com",
+				Phone:     "000-000-0111",
+				Address:   []string{"411 dorm room @ Hogwarts", "981 @ random place"},
+				Friends:   []string{"0x02", "0x03"},
+				CreatedAt: now,
+			},
+		},
+	}
+}
+
+// defaultDataset is served by resolvers without a dataset.
+var defaultDataset = DefaultDataset()
+
+func (d *Dataset) index() {
+	d.once.Do(func() {
+		d.users = make([]*user, len(d.Users))
+		d.usersMap = make(map[string]*user, len(d.Users))
+		for i, u := range d.Users {
+			address := append([]string(nil), u.Address...)
+			d.users[i] = &user{
+				IDField:   u.ID,
+				NameField: u.Name,
+				RoleField: u.Role,
+				Address:   &address,
+				CreatedAt: graphql.Time{Time: u.CreatedAt},
+				contact: contact{
+					Email: u.Email,
+					Phone: u.Phone,
+				},
+			}
+			d.usersMap[u.ID] = d.users[i]
+		}
+		for i, u := range d.Users {
+			friends := make([]*user, 0, len(u.Friends))
+			for _, id := range u.Friends {
+				if f, ok := d.usersMap[id]; ok {
+					friends = append(friends, f)
+				}
+			}
+			d.users[i].FriendsField = &friends
+		}
+	})
+}
+
+// Resolver is the root resolver of [Schema]. The zero value serves the [DefaultDataset] without latency.
+type Resolver struct {
+	// Data is the dataset served by the resolver. If it is nil, a dataset shared by all resolvers
+	// without a dataset is used.
+	Data *Dataset
+	// Latency delays each call of a query field, simulating a call to a backend.
+	Latency time.Duration
+}
+
+func (r *Resolver) data() *Dataset {
+	d := r.Data
+	if d == nil {
+		d = defaultDataset
+	}
+	d.index()
+	return d
+}
+
+func (r *Resolver) Admin(ctx context.Context, args struct {
+	ID   string
+	Role string
+}) (*adminResolver, error) {
+	if err := testschemas.Delay(ctx, r.Latency); err != nil {
+		return nil, err
+	}
+	if usr, ok := r.data().usersMap[args.ID]; ok {
+		if usr.RoleField == args.Role {
+			return &adminResolver{*usr}, nil
+		}
+	}
+	err := fmt.Errorf("user with id=%s and role=%s does not exist", args.ID, args.Role)
+	return nil, err
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ Id string }) (user, error) {
+	if err := testschemas.Delay(ctx, r.Latency); err != nil {
+		return user{}, err
+	}
+	if usr, ok := r.data().usersMap[args.Id]; ok {
+		return *usr, nil
+	}
+	err := fmt.Errorf("user with id=%s does not exist", args.Id)
+	return user{}, err
+}
+
+func (r *Resolver) Search(ctx context.Context, args struct{ Text string }) ([]*searchResult, error) {
+	if err := testschemas.Delay(ctx, r.Latency); err != nil {
+		return nil, err
+	}
+	var result []*searchResult
+	for _, usr := range r.data().users {
+		if strings.Contains(usr.NameField, args.Text) {
+			result = append(result, &searchResult{usr})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package social_test
+
+import (
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/graph-gophers/graphql-go/testschemas/social"
+)
+
+func TestDataset(t *testing.T) {
+	data := &social.Dataset{
+		Users: []*social.User{
+			{ID: "1", Name: "Ada", Role: "ADMIN", Email: "ada@example.com", Friends: []string{"2"}},
+			{ID: "2", Name: "Grace", Role: "USER", Email: "grace@example.com", Friends: []string{"1"}},
+		},
+	}
+	schema := graphql.MustParseSchema(social.Schema, &social.Resolver{Data: data}, graphql.UseFieldResolvers())
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				admin(id: "1") {
+					name
+					... on User {
+						email
+						friends {
+							name
+							role
+						}
+					}
+				}
+				search(text: "Harry") {
+					__typename
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"admin": {
+					"name": "Ada",
+					"email": "ada@example.com",
+					"friends": [{"name": "Grace", "role": "USER"}]
+				},
+				"search": []
+			}
+		`,
+	})
+}
@@ -0,0 +1,752 @@
+// Package starwars provides a schema and resolver based on Star Wars characters. The resolver serves a
+// configurable in-memory [Dataset] and can simulate the latency of a backend.
+//
+// Source: https://github.com/graphql/graphql.github.io/blob/source/site/_core/swapiSchema.js
+package starwars
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/testschemas"
+)
+
+var Schema = `
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+	# The query type, represents all of the entry points into our object graph
+	type Query {
+		hero(episode: Episode = NEWHOPE): Character
+		reviews(episode: Episode!): [Review]!
+		search(text: String!): [SearchResult]!
+		character(id: ID!): Character
+		droid(id: ID!): Droid
+		human(id: ID!): Human
+		starship(id: ID!): Starship
+	}
+	# The mutation type, represents all updates we can make to our data
+	type Mutation {
+		createReview(episode: Episode!, review: ReviewInput!): Review
+	}
+	# The episodes in the Star Wars trilogy
+	enum Episode {
+		# Star Wars Episode IV: A New Hope, released in 1977.
+		NEWHOPE
+		# Star Wars Episode V: The Empire Strikes Back, released in 1980.
+		EMPIRE
+		# Star Wars Episode VI: Return of the Jedi, released in 1983.
+		JEDI
+	}
+	# A character from the Star Wars universe
+	interface Character {
+		# The ID of the character
+		id: ID!
+		# The name of the character
+		name: String!
+		# The friends of the character, or an empty list if they have none
+		friends: [Character]
+		# The friends of the character exposed as a connection with edges
+		friendsConnection(first: Int, after: ID): FriendsConnection!
+		# The movies this character appears in
+		appearsIn: [Episode!]!
+	}
+	# Units of height
+	enum LengthUnit {
+		# The standard unit around the world
+		METER
+		# Primarily used in the United States
+		FOOT
+	}
+	# A humanoid creature from the Star Wars universe
+	type Human implements Character {
+		# The ID of the human
+		id: ID!
+		# What this human calls themselves
+		name: String!
+		# Height in the preferred unit, default is meters
+		height(unit: LengthUnit = METER): Float!
+		# Mass in kilograms, or null if unknown
+		mass: Float
+		# This human's friends, or an empty list if they have none
+		friends: [Character]
+		# The friends of the human exposed as a connection with edges
+		friendsConnection(first: Int, after: ID): FriendsConnection!
+		# The movies this human appears in
+		appearsIn: [Episode!]!
+		# A list of starships this person has piloted, or an empty list if none
+		starships: [Starship]
+	}
+	# An autonomous mechanical character in the Star Wars universe
+	type Droid implements Character {
+		# The ID of the droid
+		id: ID!
+		# What others call this droid
+		name: String!
+		# This droid's friends, or an empty list if they have none
+		friends: [Character]
+		# The friends of the droid exposed as a connection with edges
+		friendsConnection(first: Int, after: ID): FriendsConnection!
+		# The movies this droid appears in
+		appearsIn: [Episode!]!
+		# This droid's primary function
+		primaryFunction: String
+	}
+	# A connection object for a character's friends
+	type FriendsConnection {
+		# The total number of friends
+		totalCount: Int!
+		# The edges for each of the character's friends.
+		edges: [FriendsEdge]
+		# A list of the friends, as a convenience when edges are not needed.
+		friends: [Character]
+		# Information for paginating this connection
+		pageInfo: PageInfo!
+	}
+	# An edge object for a character's friends
+	type FriendsEdge {
+		# A cursor used for pagination
+		cursor: ID!
+		# The character represented by this friendship edge
+		node: Character
+	}
+	# Information for paginating this connection
+	type PageInfo {
+		startCursor: ID
+		endCursor: ID
+		hasNextPage: Boolean!
+	}
+	# Represents a review for a movie
+	type Review {
+		# The number of stars this review gave, 1-5
+		stars: Int!
+		# Comment about the movie
+		commentary: String
+	}
+	# The input object sent when someone is creating a new review
+	input ReviewInput {
+		# 0-5 stars
+		stars: Int!
+		# Comment about the movie, optional
+		commentary: String
+	}
+	type Starship {
+		# The ID of the starship
+		id: ID!
+		# The name of the starship
+		name: String!
+		# Length of the starship, along the longest axis
+		length(unit: LengthUnit = METER): Float!
+	}
+	union SearchResult = Human | Droid | Starship
+`
+
+// Human is a humanoid creature of the dataset.
+type Human struct {
+	ID        graphql.ID
+	Name      string
+	Friends   []graphql.ID
+	AppearsIn []string
+	// Height is the height in meters.
+	Height float64
+	// Mass is the mass in kilograms. Zero means unknown.
+	Mass      int
+	Starships []graphql.ID
+}
+
+// Droid is an autonomous mechanical character of the dataset.
+type Droid struct {
+	ID              graphql.ID
+	Name            string
+	Friends         []graphql.ID
+	AppearsIn       []string
+	PrimaryFunction string
+}
+
+// Starship is a starship of the dataset.
+type Starship struct {
+	ID   graphql.ID
+	Name string
+	// Length is the length in meters.
+	Length float64
+}
+
+// Dataset holds the characters and starships served by a [Resolver]. Reviews created with the
+// createReview mutation are added to the dataset. The fields must not be modified once the dataset
+// is in use.
+type Dataset struct {
+	Humans    []*Human
+	Droids    []*Droid
+	Starships []*Starship
+
+	once      sync.Once
+	humans    map[graphql.ID]*Human
+	droids    map[graphql.ID]*Droid
+	starships map[graphql.ID]*Starship
+
+	mu      sync.Mutex
+	reviews map[string][]*review
+}
+
+// DefaultDataset returns a new dataset with the characters and starships of the original trilogy.
+func DefaultDataset() *Dataset {
+	return &Dataset{
+		Humans: []*Human{
+			{
+				ID:        "1000",
+				Name:      "Luke Skywalker",
+				Friends:   []graphql.ID{"1002", "1003", "2000", "2001"},
+				AppearsIn: []string{"NEWHOPE", "EMPIRE", "JEDI"},
+				Height:    1.72,
+				Mass:      77,
+				Starships: []graphql.ID{"3001", "3003"},
+			},
+			{
+				ID:        "1001",
+				Name:      "Darth Vader",
+				Friends:   []graphql.ID{"1004"},
+				AppearsIn: []string{"NEWHOPE", "EMPIRE", "JEDI"},
+				Height:    2.02,
+				Mass:      136,
+				Starships: []graphql.ID{"3002"},
+			},
+			{
+				ID:        "1002",
+				Name:      "Han Solo",
+				Friends:   []graphql.ID{"1000", "1003", "2001"},
+				AppearsIn: []string{"NEWHOPE", "EMPIRE", "JEDI"},
+				Height:    1.8,
+				Mass:      80,
+				Starships: []graphql.ID{"3000", "3003"},
+			},
+			{
+				ID:        "1003",
+				Name:      "Leia Organa",
+				Friends:   []graphql.ID{"1000", "1002", "2000", "2001"},
+				AppearsIn: []string{"NEWHOPE", "EMPIRE", "JEDI"},
+				Height:    1.5,
+				Mass:      49,
+			},
+			{
+				ID:        "1004",
+				Name:      "Wilhuff Tarkin",
+				Friends:   []graphql.ID{"1001"},
+				AppearsIn: []string{"NEWHOPE"},
+				Height:    1.8,
+				Mass:      0,
+			},
+		},
+		Droids: []*Droid{
+			{
+				ID:              "2000",
+				Name:            "C-3PO",
+				Friends:         []graphql.ID{"1000", "1002", "1003", "2001"},
+				AppearsIn:       []string{"NEWHOPE", "EMPIRE", "JEDI"},
+				PrimaryFunction: "Protocol",
+			},
+			{
+				ID:              "2001",
+				Name:            "R2-D2",
+				Friends:         []graphql.ID{"1000", "1002", "1003"},
+				AppearsIn:       []string{"NEWHOPE", "EMPIRE", "JEDI"},
+				PrimaryFunction: "Astromech",
+			},
+		},
+		Starships: []*Starship{
+			{
+				ID:     "3000",
+				Name:   "Millennium Falcon",
+				Length: 34.37,
+			},
+			{
+				ID:     "3001",
+				Name:   "X-Wing",
+				Length: 12.5,
+			},
+			{
+				ID:     "3002",
+				Name:   "TIE Advanced x1",
+				Length: 9.2,
+			},
+			{
+				ID:     "3003",
+				Name:   "Imperial shuttle",
+				Length: 20,
+			},
+		},
+	}
+}
+
+// defaultDataset is served by resolvers without a dataset.
+var defaultDataset = DefaultDataset()
+
+func (d *Dataset) index() {
+	d.once.Do(func() {
+		d.humans = make(map[graphql.ID]*Human, len(d.Humans))
+		for _, h := range d.Humans {
+			d.humans[h.ID] = h
+		}
+		d.droids = make(map[graphql.ID]*Droid, len(d.Droids))
+		for _, dr := range d.Droids {
+			d.droids[dr.ID] = dr
+		}
+		d.starships = make(map[graphql.ID]*Starship, len(d.Starships))
+		for _, s := range d.Starships {
+			d.starships[s.ID] = s
+		}
+		d.reviews = make(map[string][]*review)
+	})
+}
+
+type review struct {
+	stars      int32
+	commentary *string
+}
+
+// Resolver is the root resolver of [Schema]. The zero value serves the [DefaultDataset] without latency.
+type Resolver struct {
+	// Data is the dataset served by the resolver. If it is nil, a dataset shared by all resolvers
+	// without a dataset is used.
+	Data *Dataset
+	// Latency delays each call of a query or mutation field, simulating a call to a backend.
+	Latency time.Duration
+}
+
+// data returns the dataset served by r. The zero values of QueryResolver and MutationResolver have no
+// root resolver and serve the default dataset.
+func (r *Resolver) data() *Dataset {
+	d := defaultDataset
+	if r != nil && r.Data != nil {
+		d = r.Data
+	}
+	d.index()
+	return d
+}
+
+func (r *Resolver) latency() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.Latency
+}
+
+func (r *Resolver) Query() *QueryResolver {
+	return &QueryResolver{r}
+}
+
+// QueryResolver resolves the fields of the Query type. The zero value serves the [DefaultDataset]
+// without latency.
+type QueryResolver struct {
+	r *Resolver
+}
+
+func (r *QueryResolver) Hero(ctx context.Context, args struct{ Episode string }) (*characterResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	d := r.r.data()
+	if args.Episode == "EMPIRE" {
+		return d.character("1000"), nil
+	}
+	return d.character("2001"), nil
+}
+
+func (r *QueryResolver) Reviews(ctx context.Context, args struct{ Episode string }) ([]*reviewResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	d := r.r.data()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var l []*reviewResolver
+	for _, review := range d.reviews[args.Episode] {
+		l = append(l, &reviewResolver{review})
+	}
+	return l, nil
+}
+
+func (r *QueryResolver) Search(ctx context.Context, args struct{ Text string }) ([]*searchResultResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	d := r.r.data()
+	var l []*searchResultResolver
+	for _, h := range d.Humans {
+		if strings.Contains(h.Name, args.Text) {
+			l = append(l, &searchResultResolver{&humanResolver{d, h}})
+		}
+	}
+	for _, dr := range d.Droids {
+		if strings.Contains(dr.Name, args.Text) {
+			l = append(l, &searchResultResolver{&droidResolver{d, dr}})
+		}
+	}
+	for _, s := range d.Starships {
+		if strings.Contains(s.Name, args.Text) {
+			l = append(l, &searchResultResolver{&starshipResolver{s}})
+		}
+	}
+	return l, nil
+}
+
+func (r *QueryResolver) Character(ctx context.Context, args struct{ ID graphql.ID }) (*characterResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	return r.r.data().character(args.ID), nil
+}
+
+func (r *QueryResolver) Human(ctx context.Context, args struct{ ID graphql.ID }) (*humanResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	d := r.r.data()
+	if h := d.humans[args.ID]; h != nil {
+		return &humanResolver{d, h}, nil
+	}
+	return nil, nil
+}
+
+func (r *QueryResolver) Droid(ctx context.Context, args struct{ ID graphql.ID }) (*droidResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	d := r.r.data()
+	if dr := d.droids[args.ID]; dr != nil {
+		return &droidResolver{d, dr}, nil
+	}
+	return nil, nil
+}
+
+func (r *QueryResolver) Starship(ctx context.Context, args struct{ ID graphql.ID }) (*starshipResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	if s := r.r.data().starships[args.ID]; s != nil {
+		return &starshipResolver{s}, nil
+	}
+	return nil, nil
+}
+
+func (r *Resolver) Mutation() *MutationResolver {
+	return &MutationResolver{r}
+}
+
+// MutationResolver resolves the fields of the Mutation type. The zero value changes the
+// [DefaultDataset] without latency.
+type MutationResolver struct {
+	r *Resolver
+}
+
+func (r *MutationResolver) CreateReview(ctx context.Context, args *struct {
+	Episode string
+	Review  reviewInput
+}) (*reviewResolver, error) {
+	if err := testschemas.Delay(ctx, r.r.latency()); err != nil {
+		return nil, err
+	}
+	review := &review{
+		stars:      args.Review.Stars,
+		commentary: args.Review.Commentary,
+	}
+	d := r.r.data()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reviews[args.Episode] = append(d.reviews[args.Episode], review)
+	return &reviewResolver{review}, nil
+}
+
+type friendsConnectionArgs struct {
+	First *int32
+	After *graphql.ID
+}
+
+type character interface {
+	ID() graphql.ID
+	Name() string
+	Friends() *[]*characterResolver
+	FriendsConnection(friendsConnectionArgs) (*friendsConnectionResolver, error)
+	AppearsIn() []string
+}
+
+type characterResolver struct {
+	character
+}
+
+func (r *characterResolver) ToHuman() (*humanResolver, bool) {
+	c, ok := r.character.(*humanResolver)
+	return c, ok
+}
+
+func (r *characterResolver) ToDroid() (*droidResolver, bool) {
+	c, ok := r.character.(*droidResolver)
+	return c, ok
+}
+
+type humanResolver struct {
+	d *Dataset
+	h *Human
+}
+
+func (r *humanResolver) ID() graphql.ID {
+	return r.h.ID
+}
+
+func (r *humanResolver) Name() string {
+	return r.h.Name
+}
+
+func (r *humanResolver) Height(args struct{ Unit string }) float64 {
+	return convertLength(r.h.Height, args.Unit)
+}
+
+func (r *humanResolver) Mass() *float64 {
+	if r.h.Mass == 0 {
+		return nil
+	}
+	f := float64(r.h.Mass)
+	return &f
+}
+
+func (r *humanResolver) Friends() *[]*characterResolver {
+	return r.d.characters(r.h.Friends)
+}
+
+func (r *humanResolver) FriendsConnection(args friendsConnectionArgs) (*friendsConnectionResolver, error) {
+	return newFriendsConnectionResolver(r.d, r.h.Friends, args)
+}
+
+func (r *humanResolver) AppearsIn() []string {
+	return r.h.AppearsIn
+}
+
+func (r *humanResolver) Starships() *[]*starshipResolver {
+	l := make([]*starshipResolver, len(r.h.Starships))
+	for i, id := range r.h.Starships {
+		l[i] = &starshipResolver{r.d.starships[id]}
+	}
+	return &l
+}
+
+type droidResolver struct {
+	d  *Dataset
+	dr *Droid
+}
+
+func (r *droidResolver) ID() graphql.ID {
+	return r.dr.ID
+}
+
+func (r *droidResolver) Name() string {
+	return r.dr.Name
+}
+
+func (r *droidResolver) Friends() *[]*characterResolver {
+	return r.d.characters(r.dr.Friends)
+}
+
+func (r *droidResolver) FriendsConnection(args friendsConnectionArgs) (*friendsConnectionResolver, error) {
+	return newFriendsConnectionResolver(r.d, r.dr.Friends, args)
+}
+
+func (r *droidResolver) AppearsIn() []string {
+	return r.dr.AppearsIn
+}
+
+func (r *droidResolver) PrimaryFunction() *string {
+	if r.dr.PrimaryFunction == "" {
+		return nil
+	}
+	return &r.dr.PrimaryFunction
+}
+
+type starshipResolver struct {
+	s *Starship
+}
+
+func (r *starshipResolver) ID() graphql.ID {
+	return r.s.ID
+}
+
+func (r *starshipResolver) Name() string {
+	return r.s.Name
+}
+
+func (r *starshipResolver) Length(args struct{ Unit string }) float64 {
+	return convertLength(r.s.Length, args.Unit)
+}
+
+type searchResultResolver struct {
+	result interface{}
+}
+
+func (r *searchResultResolver) ToHuman() (*humanResolver, bool) {
+	res, ok := r.result.(*humanResolver)
+	return res, ok
+}
+
+func (r *searchResultResolver) ToDroid() (*droidResolver, bool) {
+	res, ok := r.result.(*droidResolver)
+	return res, ok
+}
+
+func (r *searchResultResolver) ToStarship() (*starshipResolver, bool) {
+	res, ok := r.result.(*starshipResolver)
+	return res, ok
+}
+
+func convertLength(meters float64, unit string) float64 {
+	switch unit {
+	case "METER":
+		return meters
+	case "FOOT":
+		return meters * 3.28084
+	default:
+		panic("invalid unit")
+	}
+}
+
+func (d *Dataset) characters(ids []graphql.ID) *[]*characterResolver {
+	var characters []*characterResolver
+	for _, id := range ids {
+		if c := d.character(id); c != nil {
+			characters = append(characters, c)
+		}
+	}
+	return &characters
+}
+
+func (d *Dataset) character(id graphql.ID) *characterResolver {
+	if h, ok := d.humans[id]; ok {
+		return &characterResolver{&humanResolver{d, h}}
+	}
+	if dr, ok := d.droids[id]; ok {
+		return &characterResolver{&droidResolver{d, dr}}
+	}
+	return nil
+}
+
+type reviewResolver struct {
+	r *review
+}
+
+func (r *reviewResolver) Stars() int32 {
+	return r.r.stars
+}
+
+func (r *reviewResolver) Commentary() *string {
+	return r.r.commentary
+}
+
+type friendsConnectionResolver struct {
+	d    *Dataset
+	ids  []graphql.ID
+	from int
+	to   int
+}
+
+func newFriendsConnectionResolver(d *Dataset, ids []graphql.ID, args friendsConnectionArgs) (*friendsConnectionResolver, error) {
+	from := 0
+	if args.After != nil {
+		b, err := base64.StdEncoding.DecodeString(string(*args.After))
+		if err != nil {
+			return nil, err
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(string(b), "cursor"))
+		if err != nil {
+			return nil, err
+		}
+		from = i
+	}
+
+	to := len(ids)
+	if args.First != nil {
+		to = from + int(*args.First)
+		if to > len(ids) {
+			to = len(ids)
+		}
+	}
+
+	return &friendsConnectionResolver{
+		d:    d,
+		ids:  ids,
+		from: from,
+		to:   to,
+	}, nil
+}
+
+func (r *friendsConnectionResolver) TotalCount() int32 {
+	return int32(len(r.ids))
+}
+
+func (r *friendsConnectionResolver) Edges() *[]*friendsEdgeResolver {
+	l := make([]*friendsEdgeResolver, r.to-r.from)
+	for i := range l {
+		l[i] = &friendsEdgeResolver{
+			d:      r.d,
+			cursor: encodeCursor(r.from + i),
+			id:     r.ids[r.from+i],
+		}
+	}
+	return &l
+}
+
+func (r *friendsConnectionResolver) Friends() *[]*characterResolver {
+	return r.d.characters(r.ids[r.from:r.to])
+}
+
+func (r *friendsConnectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{
+		startCursor: encodeCursor(r.from),
+		endCursor:   encodeCursor(r.to - 1),
+		hasNextPage: r.to < len(r.ids),
+	}
+}
+
+func encodeCursor(i int) graphql.ID {
+	return graphql.ID(base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("cursor%d", i+1))))
+}
+
+type friendsEdgeResolver struct {
+	d      *Dataset
+	cursor graphql.ID
+	id     graphql.ID
+}
+
+func (r *friendsEdgeResolver) Cursor() graphql.ID {
+	return r.cursor
+}
+
+func (r *friendsEdgeResolver) Node() *characterResolver {
+	return r.d.character(r.id)
+}
+
+type pageInfoResolver struct {
+	startCursor graphql.ID
+	endCursor   graphql.ID
+	hasNextPage bool
+}
+
+func (r *pageInfoResolver) StartCursor() *graphql.ID {
+	return &r.startCursor
+}
+
+func (r *pageInfoResolver) EndCursor() *graphql.ID {
+	return &r.endCursor
+}
+
+func (r *pageInfoResolver) HasNextPage() bool {
+	return r.hasNextPage
+}
+
+type reviewInput struct {
+	Stars      int32
+	Commentary *string
+}
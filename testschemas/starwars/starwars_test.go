@@ -0,0 +1,114 @@
+package starwars_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/graph-gophers/graphql-go/testschemas/starwars"
+)
+
+func TestDataset(t *testing.T) {
+	data := &starwars.Dataset{
+		Humans: []*starwars.Human{
+			{ID: "1", Name: "Din Djarin", Friends: []graphql.ID{"2"}, AppearsIn: []string{}, Height: 1.8},
+		},
+		Droids: []*starwars.Droid{
+			{ID: "2", Name: "IG-11", Friends: []graphql.ID{"1"}, AppearsIn: []string{}, PrimaryFunction: "Nurse"},
+		},
+	}
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{Data: data})
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: schema,
+			Query: `
+				{
+					human(id: "1") {
+						name
+						friends {
+							name
+							... on Droid {
+								primaryFunction
+							}
+						}
+					}
+					search(text: "Luke") {
+						__typename
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"human": {
+						"name": "Din Djarin",
+						"friends": [{"name": "IG-11", "primaryFunction": "Nurse"}]
+					},
+					"search": []
+				}
+			`,
+		},
+		{
+			Schema: schema,
+			Query: `
+				mutation {
+					createReview(episode: JEDI, review: {stars: 5}) {
+						stars
+					}
+				}
+			`,
+			ExpectedResult: `{"createReview": {"stars": 5}}`,
+		},
+		{
+			Schema:         schema,
+			Query:          `{ reviews(episode: JEDI) { stars } }`,
+			ExpectedResult: `{"reviews": [{"stars": 5}]}`,
+		},
+		{
+			Schema:         graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{Data: starwars.DefaultDataset()}),
+			Query:          `{ reviews(episode: JEDI) { stars } }`,
+			ExpectedResult: `{"reviews": []}`,
+		},
+	})
+}
+
+func TestLatency(t *testing.T) {
+	schema := graphql.MustParseSchema(starwars.Schema, &starwars.Resolver{Latency: 50 * time.Millisecond})
+
+	start := time.Now()
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				luke: human(id: "1000") { name }
+				leia: human(id: "1003") { name }
+			}
+		`,
+		ExpectedResult: `{"luke": {"name": "Luke Skywalker"}, "leia": {"name": "Leia Organa"}}`,
+	})
+	if d := time.Since(start); d < 50*time.Millisecond || d >= 100*time.Millisecond {
+		t.Errorf("got duration %s, want the latency of concurrent fields once", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Context: ctx,
+		Schema:  schema,
+		Query:   `{ human(id: "1000") { name } }`,
+		ExpectedErrors: []*gqlerrors.QueryError{{
+			Message:    "context deadline exceeded",
+			Extensions: map[string]interface{}{"code": gqlerrors.CodeTimeout},
+		}},
+	})
+}
+
+func TestZeroOperationResolvers(t *testing.T) {
+	hero, err := (&starwars.QueryResolver{}).Hero(context.Background(), struct{ Episode string }{Episode: "EMPIRE"})
+	if err != nil || hero == nil || hero.Name() != "Luke Skywalker" {
+		t.Errorf("got hero %v and error %v, want Luke Skywalker", hero, err)
+	}
+}
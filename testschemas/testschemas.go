@@ -0,0 +1,27 @@
+// Package testschemas provides realistic schemas with resolvers serving configurable in-memory
+// datasets, e.g. to benchmark and integration-test transports and middleware. The resolvers can
+// simulate the latency of backends.
+//
+// The schemas are provided by the subpackages starwars and social.
+package testschemas
+
+import (
+	"context"
+	"time"
+)
+
+// Delay waits for d or until ctx is done, in which case the error of ctx is returned. It returns
+// immediately if d is not positive.
+func Delay(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
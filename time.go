@@ -3,6 +3,8 @@ package graphql
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 )
 
@@ -62,3 +64,70 @@ func (t *Time) UnmarshalGraphQL(input interface{}) error {
 func (t Time) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.Time)
 }
+
+// UnixTime is a variant of [Time] for clients which exchange instants as Unix epoch numbers. It has to
+// be added to a schema via "scalar UnixTime". As an input it accepts Unix seconds or milliseconds, as
+// numbers or numeric strings, and RFC 3339 strings with optional fractional seconds. Numbers with an
+// absolute value of at least 1e11 are interpreted as milliseconds. It is marshaled as Unix seconds.
+type UnixTime struct {
+	time.Time
+}
+
+// ImplementsGraphQLType maps this custom Go type
+// to the graphql scalar type in the schema.
+func (UnixTime) ImplementsGraphQLType(name string) bool {
+	return name == "UnixTime"
+}
+
+// UnmarshalGraphQL is a custom unmarshaler for UnixTime
+//
+// This function will be called whenever you use the
+// UnixTime scalar as an input
+func (t *UnixTime) UnmarshalGraphQL(input interface{}) error {
+	var n float64
+	switch input := input.(type) {
+	case time.Time:
+		t.Time = input
+		return nil
+	case string:
+		f, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			t.Time, err = time.Parse(time.RFC3339Nano, input)
+			return err
+		}
+		n = f
+	case int32:
+		n = float64(input)
+	case int64:
+		n = float64(input)
+	case float64:
+		n = input
+	default:
+		return fmt.Errorf("wrong type for UnixTime: %T", input)
+	}
+	var err error
+	t.Time, err = fromEpoch(n)
+	return err
+}
+
+// MarshalJSON is a custom marshaler for UnixTime
+//
+// This function will be called whenever you
+// query for fields that use the UnixTime type
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Unix())
+}
+
+// fromEpoch converts Unix seconds, or milliseconds if n is at least 1e11 in magnitude, to a time.
+// NaN, infinities and numbers beyond the range of Unix milliseconds are rejected.
+func fromEpoch(n float64) (time.Time, error) {
+	if math.IsNaN(n) || math.Abs(n) >= 1<<63 {
+		return time.Time{}, fmt.Errorf("UnixTime out of range: %v", n)
+	}
+	if math.Abs(n) >= 1e11 {
+		ms := int64(math.Round(n))
+		return time.Unix(ms/1e3, ms%1e3*1e6), nil
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))), nil
+}
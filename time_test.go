@@ -3,6 +3,7 @@ package graphql_test
 import (
 	"bytes"
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -163,3 +164,53 @@ func TestTime_UnmarshalGraphQL(t *testing.T) {
 		})
 	}
 }
+
+func TestUnixTime_UnmarshalGraphQL(t *testing.T) {
+	ref := time.Date(2021, time.April, 20, 12, 3, 23, 551000000, time.UTC)
+
+	tests := []struct {
+		name  string
+		input interface{}
+		want  time.Time
+	}{
+		{name: "seconds int32", input: int32(ref.Unix()), want: time.Unix(ref.Unix(), 0)},
+		{name: "seconds float64", input: float64(ref.Unix()), want: time.Unix(ref.Unix(), 0)},
+		{name: "milliseconds int64", input: ref.UnixNano() / 1e6, want: ref},
+		{name: "milliseconds float64", input: float64(ref.UnixNano() / 1e6), want: ref},
+		{name: "seconds string", input: "1618920203", want: time.Unix(ref.Unix(), 0)},
+		{name: "milliseconds string", input: "1618920203551", want: ref},
+		{name: "RFC3339Nano", input: ref.Format(time.RFC3339Nano), want: ref},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gt graphql.UnixTime
+			if err := gt.UnmarshalGraphQL(tt.input); err != nil {
+				t.Fatalf("UnmarshalGraphQL() error = %v", err)
+			}
+			if !gt.Equal(tt.want) {
+				t.Errorf("UnmarshalGraphQL() got = %v, want = %v", gt.Time, tt.want)
+			}
+		})
+	}
+
+	var gt graphql.UnixTime
+	if err := gt.UnmarshalGraphQL(true); err == nil || err.Error() != "wrong type for UnixTime: bool" {
+		t.Errorf("UnmarshalGraphQL() unexpected error = %v", err)
+	}
+
+	for _, input := range []interface{}{math.NaN(), math.Inf(1), math.Inf(-1), 1e300, "NaN", "-Inf"} {
+		if err := gt.UnmarshalGraphQL(input); err == nil {
+			t.Errorf("UnmarshalGraphQL(%v) got = %v, want an error", input, gt.Time)
+		}
+	}
+}
+
+func TestUnixTime_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(graphql.UnixTime{Time: time.Date(2021, time.April, 20, 12, 3, 23, 551476231, time.UTC)})
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if got, want := string(b), "1618920203"; got != want {
+		t.Errorf("MarshalJSON() got = %s, want = %s", got, want)
+	}
+}
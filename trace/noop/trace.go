@@ -6,6 +6,7 @@ import (
 
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
 )
 
 // Tracer is a no-op tracer that does nothing.
@@ -22,3 +23,15 @@ func (Tracer) TraceField(ctx context.Context, label, typeName, fieldName string,
 func (Tracer) TraceValidation(context.Context) func([]*errors.QueryError) {
 	return func(errs []*errors.QueryError) {}
 }
+
+func (Tracer) TraceParse(context.Context, string) func(*errors.QueryError) {
+	return func(*errors.QueryError) {}
+}
+
+func (Tracer) TraceSubscription(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, func([]*errors.QueryError, int), func()) {
+	return ctx, func([]*errors.QueryError, int) {}, func() {}
+}
+
+func (Tracer) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, func(*errors.QueryError, int)) {
+	return ctx, func(*errors.QueryError, int) {}
+}
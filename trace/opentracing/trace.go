@@ -7,6 +7,7 @@ import (
 
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
@@ -77,4 +78,22 @@ func (Tracer) TraceValidation(ctx context.Context) func([]*errors.QueryError) {
 	}
 }
 
+// TraceSkipped logs each selection skipped by a @skip or @include directive to the span of the
+// request.
+func (Tracer) TraceSkipped(ctx context.Context, skipped []tracer.SkippedSelection) {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	for _, sel := range skipped {
+		span.LogFields(
+			log.String("event", "graphql.skipped"),
+			log.String("graphql.type", sel.TypeName),
+			log.String("graphql.field", sel.FieldName),
+			log.String("graphql.fragment", sel.Fragment),
+			log.String("graphql.directive", sel.Directive),
+		)
+	}
+}
+
 func noop(*errors.QueryError) {}
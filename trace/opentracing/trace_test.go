@@ -12,6 +12,7 @@ import (
 func TestInterfaceImplementation(t *testing.T) {
 	var _ tracer.ValidationTracer = &opentracing.Tracer{}
 	var _ tracer.Tracer = &opentracing.Tracer{}
+	var _ tracer.SkipTracer = &opentracing.Tracer{}
 }
 
 func TestTracerOption(t *testing.T) {
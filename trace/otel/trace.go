@@ -12,6 +12,7 @@ import (
 
 	"github.com/graph-gophers/graphql-go/errors"
 	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
 )
 
 // DefaultTracer creates a tracer using a default name.
@@ -90,3 +91,22 @@ func (t *Tracer) TraceValidation(ctx context.Context) func([]*errors.QueryError)
 		span.End()
 	}
 }
+
+// TraceSkipped adds an event to the span of the request for each selection skipped by a @skip or
+// @include directive.
+func (t *Tracer) TraceSkipped(ctx context.Context, skipped []tracer.SkippedSelection) {
+	span := oteltrace.SpanFromContext(ctx)
+	for _, sel := range skipped {
+		attributes := []attribute.KeyValue{
+			attribute.String("graphql.type", sel.TypeName),
+			attribute.String("graphql.directive", sel.Directive),
+		}
+		if sel.FieldName != "" {
+			attributes = append(attributes, attribute.String("graphql.field", sel.FieldName))
+		}
+		if sel.Fragment != "" {
+			attributes = append(attributes, attribute.String("graphql.fragment", sel.Fragment))
+		}
+		span.AddEvent("graphql.skipped", oteltrace.WithAttributes(attributes...))
+	}
+}
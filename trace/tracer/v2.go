@@ -0,0 +1,123 @@
+package tracer
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go/ast"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+)
+
+type ParseFinishFunc = func(*errors.QueryError)
+type ResolverFinishFunc = func(err *errors.QueryError, size int)
+type SubscriptionEventFunc = func(errs []*errors.QueryError, size int)
+type SubscriptionFinishFunc = func()
+
+// ResolverInfo describes a field which is about to be resolved.
+type ResolverInfo struct {
+	// Label is the trace label of the field, e.g. "GraphQL field: Query.hero".
+	Label string
+	// TypeName is the name of the type the field belongs to.
+	TypeName string
+	// FieldName is the name of the field in the schema.
+	FieldName string
+	// Trivial reports whether the field is resolved synchronously without calling a resolver
+	// which takes a context, arguments or returns an error.
+	Trivial bool
+	// Args are the arguments of the field as provided in the query.
+	Args map[string]interface{}
+	// ArgumentDefinitions are the definitions of the arguments of the field in the schema.
+	ArgumentDefinitions ast.ArgumentsDefinition
+	// CoercedArgs are the values of the arguments coerced into the Go types received by the resolver,
+	// including default values, keyed by argument name. It is nil if the resolver takes no arguments.
+	CoercedArgs map[string]interface{}
+}
+
+// SkippedSelection is a selection of an operation which was excluded from the execution by a @skip or
+// @include directive.
+type SkippedSelection struct {
+	// TypeName is the name of the type the selection is made on.
+	TypeName string
+	// FieldName is the name of a skipped field. It is empty for fragments.
+	FieldName string
+	// Alias is the response key of a skipped field. It is empty for fragments.
+	Alias string
+	// Fragment is the name of a skipped fragment spread. It is empty for fields and inline fragments.
+	Fragment string
+	// Directive is the directive which excluded the selection, either "skip" or "include".
+	Directive string
+}
+
+// SkipTracer is implemented by tracers which record the selections excluded by @skip and @include,
+// e.g. to measure how often clients request conditional fields.
+type SkipTracer interface {
+	// TraceSkipped is called with the context of the operation before it is executed, if any of its
+	// selections were skipped. Selections of fragments on abstract types are reported once for each
+	// possible type they were applied to.
+	TraceSkipped(ctx context.Context, skipped []SkippedSelection)
+}
+
+// TracerV2 traces every phase of a request: parsing, validation, execution of queries, mutations and
+// subscriptions, and the resolution of each field including the size of its serialized result.
+//
+// Tracers implementing only [Tracer] can be converted to a TracerV2 with [Upgrade].
+type TracerV2 interface {
+	ValidationTracer
+
+	// TraceParse is called before a query document is parsed.
+	TraceParse(ctx context.Context, queryString string) ParseFinishFunc
+	// TraceQuery is called before a query or mutation is executed.
+	TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, QueryFinishFunc)
+	// TraceSubscription is called when a subscription is started. The returned event function is called after
+	// each event sent to the subscriber and the finish function is called once the subscription ended.
+	TraceSubscription(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, SubscriptionEventFunc, SubscriptionFinishFunc)
+	// TraceResolver is called before a field is resolved. The finish function receives the resolver error,
+	// if any, and the size in bytes of the serialized field value.
+	TraceResolver(ctx context.Context, info ResolverInfo) (context.Context, ResolverFinishFunc)
+}
+
+// Upgrade converts a [Tracer] to a [TracerV2]. If t already implements TracerV2 it is returned as is.
+// Otherwise parsing and subscriptions are not traced, the size of field results is discarded and
+// validation is only traced if t implements [ValidationTracer].
+func Upgrade(t Tracer) TracerV2 {
+	if v2, ok := t.(TracerV2); ok {
+		return v2
+	}
+	return legacyTracer{t}
+}
+
+// AsSkipTracer returns t as a [SkipTracer], if t or the tracer converted by [Upgrade] implements it.
+func AsSkipTracer(t TracerV2) (SkipTracer, bool) {
+	if lt, ok := t.(legacyTracer); ok {
+		st, ok := lt.Tracer.(SkipTracer)
+		return st, ok
+	}
+	st, ok := t.(SkipTracer)
+	return st, ok
+}
+
+type legacyTracer struct {
+	Tracer
+}
+
+func (t legacyTracer) TraceValidation(ctx context.Context) ValidationFinishFunc {
+	if vt, ok := t.Tracer.(ValidationTracer); ok {
+		return vt.TraceValidation(ctx)
+	}
+	return func([]*errors.QueryError) {}
+}
+
+func (legacyTracer) TraceParse(context.Context, string) ParseFinishFunc {
+	return func(*errors.QueryError) {}
+}
+
+func (legacyTracer) TraceSubscription(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, SubscriptionEventFunc, SubscriptionFinishFunc) {
+	return ctx, func([]*errors.QueryError, int) {}, func() {}
+}
+
+func (t legacyTracer) TraceResolver(ctx context.Context, info ResolverInfo) (context.Context, ResolverFinishFunc) {
+	ctx, finish := t.TraceField(ctx, info.Label, info.TypeName, info.FieldName, info.Trivial, info.Args)
+	return ctx, func(err *errors.QueryError, _ int) {
+		finish(err)
+	}
+}
@@ -0,0 +1,238 @@
+// Package usage provides a tracer which aggregates how often the fields of a schema are requested,
+// and by which clients, and periodically flushes reports to a [Sink], e.g. to find out whether a
+// deprecated field can be removed:
+//
+//	agg := usage.New(sink, usage.Options{
+//		Interval: time.Minute,
+//		Client: func(ctx context.Context) string {
+//			return clientName(ctx) // e.g. read from a header by the HTTP handler
+//		},
+//	})
+//	defer agg.Close(context.Background())
+//	schema := graphql.MustParseSchema(sdl, resolver, graphql.Tracer(agg))
+//
+// A field is counted once per operation which resolved it, however often it was resolved. Fields
+// skipped by graphql.TraceSampler are not counted.
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace/noop"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
+)
+
+// FieldUsage is the number of operations of a client which requested a field.
+type FieldUsage struct {
+	TypeName  string
+	FieldName string
+	// Client identifies the client, see [Options.Client].
+	Client string
+	// Operations is the number of operations which resolved the field.
+	Operations int64
+}
+
+// Report holds the usage of fields between two flushes.
+type Report struct {
+	Start time.Time
+	End   time.Time
+	// Fields are sorted by type name, field name and client.
+	Fields []FieldUsage
+}
+
+// Sink receives the reports of an [Aggregator], e.g. to store them or to send them to a schema
+// registry.
+type Sink interface {
+	SendReport(ctx context.Context, report *Report) error
+}
+
+// SinkFunc is a function implementing [Sink].
+type SinkFunc func(ctx context.Context, report *Report) error
+
+// SendReport calls f.
+func (f SinkFunc) SendReport(ctx context.Context, report *Report) error {
+	return f(ctx, report)
+}
+
+// Options configure an [Aggregator].
+type Options struct {
+	// Interval is the time between two reports. It defaults to one minute.
+	Interval time.Duration
+	// Client returns the identity of the client of the operation with the context ctx. If it is nil,
+	// all operations are counted for the empty client.
+	Client func(ctx context.Context) string
+	// Next is the tracer which traces the operations in addition to the aggregator, e.g. an
+	// OpenTelemetry tracer. It defaults to noop.Tracer.
+	Next tracer.Tracer
+	// OnError is called with the errors of the sink. The report is discarded.
+	OnError func(err error)
+}
+
+// Aggregator is a tracer which counts the operations requesting each field. It is safe for
+// concurrent use.
+type Aggregator struct {
+	sink   Sink
+	opts   Options
+	next   tracer.TracerV2
+	mu     sync.Mutex
+	start  time.Time
+	counts map[FieldUsage]int64
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New returns an Aggregator which flushes its reports to sink every interval of opts until it is
+// closed.
+func New(sink Sink, opts Options) *Aggregator {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	next := opts.Next
+	if next == nil {
+		next = noop.Tracer{}
+	}
+	a := &Aggregator{
+		sink:   sink,
+		opts:   opts,
+		next:   tracer.Upgrade(next),
+		start:  time.Now(),
+		counts: make(map[FieldUsage]int64),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *Aggregator) run() {
+	defer close(a.done)
+	t := time.NewTicker(a.opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := a.Flush(context.Background()); err != nil && a.opts.OnError != nil {
+				a.opts.OnError(err)
+			}
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// Flush sends the usage counted since the last flush to the sink, unless no field was requested.
+func (a *Aggregator) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	report := &Report{Start: a.start, End: time.Now()}
+	for f, n := range a.counts {
+		f.Operations = n
+		report.Fields = append(report.Fields, f)
+	}
+	a.start = report.End
+	a.counts = make(map[FieldUsage]int64)
+	a.mu.Unlock()
+
+	if len(report.Fields) == 0 {
+		return nil
+	}
+	sort.Slice(report.Fields, func(i, j int) bool {
+		fi, fj := report.Fields[i], report.Fields[j]
+		if fi.TypeName != fj.TypeName {
+			return fi.TypeName < fj.TypeName
+		}
+		if fi.FieldName != fj.FieldName {
+			return fi.FieldName < fj.FieldName
+		}
+		return fi.Client < fj.Client
+	})
+	return a.sink.SendReport(ctx, report)
+}
+
+// Close stops the periodic reports and flushes the remaining usage.
+func (a *Aggregator) Close(ctx context.Context) error {
+	a.once.Do(func() { close(a.stop) })
+	<-a.done
+	return a.Flush(ctx)
+}
+
+type operationKey struct{}
+
+// operation collects the fields resolved by an operation.
+type operation struct {
+	client string
+	mu     sync.Mutex
+	fields map[FieldUsage]struct{}
+}
+
+func (a *Aggregator) startOperation(ctx context.Context) (context.Context, *operation) {
+	op := &operation{fields: make(map[FieldUsage]struct{})}
+	if a.opts.Client != nil {
+		op.client = a.opts.Client(ctx)
+	}
+	return context.WithValue(ctx, operationKey{}, op), op
+}
+
+func (a *Aggregator) finishOperation(op *operation) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for f := range op.fields {
+		a.counts[f]++
+	}
+}
+
+func (a *Aggregator) resolve(ctx context.Context, typeName, fieldName string) {
+	op, ok := ctx.Value(operationKey{}).(*operation)
+	if !ok {
+		return
+	}
+	op.mu.Lock()
+	op.fields[FieldUsage{TypeName: typeName, FieldName: fieldName, Client: op.client}] = struct{}{}
+	op.mu.Unlock()
+}
+
+func (a *Aggregator) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, func([]*errors.QueryError)) {
+	ctx, op := a.startOperation(ctx)
+	ctx, finish := a.next.TraceQuery(ctx, queryString, operationName, variables, varTypes)
+	return ctx, func(errs []*errors.QueryError) {
+		a.finishOperation(op)
+		finish(errs)
+	}
+}
+
+func (a *Aggregator) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, func(*errors.QueryError)) {
+	a.resolve(ctx, typeName, fieldName)
+	ctx, finish := a.next.TraceResolver(ctx, tracer.ResolverInfo{Label: label, TypeName: typeName, FieldName: fieldName, Trivial: trivial, Args: args})
+	return ctx, func(err *errors.QueryError) {
+		finish(err, 0)
+	}
+}
+
+func (a *Aggregator) TraceResolver(ctx context.Context, info tracer.ResolverInfo) (context.Context, func(*errors.QueryError, int)) {
+	a.resolve(ctx, info.TypeName, info.FieldName)
+	return a.next.TraceResolver(ctx, info)
+}
+
+func (a *Aggregator) TraceSubscription(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, func([]*errors.QueryError, int), func()) {
+	ctx, op := a.startOperation(ctx)
+	ctx, event, finish := a.next.TraceSubscription(ctx, queryString, operationName, variables, varTypes)
+	return ctx, event, func() {
+		a.finishOperation(op)
+		finish()
+	}
+}
+
+func (a *Aggregator) TraceValidation(ctx context.Context) func([]*errors.QueryError) {
+	return a.next.TraceValidation(ctx)
+}
+
+func (a *Aggregator) TraceParse(ctx context.Context, queryString string) func(*errors.QueryError) {
+	return a.next.TraceParse(ctx, queryString)
+}
@@ -0,0 +1,124 @@
+package usage_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/trace/tracer"
+	"github.com/graph-gophers/graphql-go/trace/usage"
+)
+
+func TestInterfaceImplementation(t *testing.T) {
+	var _ tracer.Tracer = &usage.Aggregator{}
+	var _ tracer.TracerV2 = &usage.Aggregator{}
+}
+
+type clientKey struct{}
+
+type resolver struct{}
+
+func (r *resolver) Hero() *character { return &character{} }
+
+type character struct{}
+
+func (c *character) Name() string { return "R2-D2" }
+func (c *character) Friends() []*character {
+	return []*character{{}, {}}
+}
+func (c *character) Height() float64 { return 1.09 }
+
+const schemaString = `
+	type Query {
+		hero: Character!
+	}
+	type Character {
+		name: String!
+		friends: [Character!]!
+		height: Float! @deprecated
+	}
+`
+
+func TestAggregator(t *testing.T) {
+	reports := make(chan *usage.Report, 1)
+	agg := usage.New(usage.SinkFunc(func(ctx context.Context, r *usage.Report) error {
+		reports <- r
+		return nil
+	}), usage.Options{
+		Interval: time.Hour,
+		Client: func(ctx context.Context) string {
+			name, _ := ctx.Value(clientKey{}).(string)
+			return name
+		},
+	})
+	schema := graphql.MustParseSchema(schemaString, &resolver{}, graphql.Tracer(agg))
+
+	web := context.WithValue(context.Background(), clientKey{}, "web")
+	ios := context.WithValue(context.Background(), clientKey{}, "ios")
+	for _, q := range []struct {
+		ctx   context.Context
+		query string
+	}{
+		{web, `{ hero { name friends { name } } }`},
+		{web, `{ hero { name height } }`},
+		{ios, `{ hero { height } }`},
+	} {
+		if resp := schema.Exec(q.ctx, q.query, "", nil); len(resp.Errors) != 0 {
+			t.Fatal(resp.Errors)
+		}
+	}
+
+	if err := agg.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r := <-reports
+	want := []usage.FieldUsage{
+		{TypeName: "Character", FieldName: "friends", Client: "web", Operations: 1},
+		{TypeName: "Character", FieldName: "height", Client: "ios", Operations: 1},
+		{TypeName: "Character", FieldName: "height", Client: "web", Operations: 1},
+		{TypeName: "Character", FieldName: "name", Client: "web", Operations: 2},
+		{TypeName: "Query", FieldName: "hero", Client: "ios", Operations: 1},
+		{TypeName: "Query", FieldName: "hero", Client: "web", Operations: 2},
+	}
+	if !reflect.DeepEqual(r.Fields, want) {
+		t.Errorf("got usage %+v, want %+v", r.Fields, want)
+	}
+	if r.End.Before(r.Start) {
+		t.Errorf("got report from %s to %s", r.Start, r.End)
+	}
+
+	// empty reports are not sent
+	if err := agg.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	schema.Exec(web, `{ hero { name } }`, "", nil)
+	if err := agg.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r = <-reports
+	if len(r.Fields) != 2 {
+		t.Errorf("got usage %+v, want the usage since the last report", r.Fields)
+	}
+}
+
+func TestAggregatorInterval(t *testing.T) {
+	reports := make(chan *usage.Report, 10)
+	agg := usage.New(usage.SinkFunc(func(ctx context.Context, r *usage.Report) error {
+		reports <- r
+		return nil
+	}), usage.Options{Interval: 10 * time.Millisecond})
+	defer agg.Close(context.Background())
+	schema := graphql.MustParseSchema(schemaString, &resolver{}, graphql.Tracer(agg))
+
+	schema.Exec(context.Background(), `{ hero { name } }`, "", nil)
+	select {
+	case r := <-reports:
+		if len(r.Fields) != 2 || r.Fields[0].Client != "" {
+			t.Errorf("got usage %+v", r.Fields)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no report was flushed")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build go1.18
+
+package graphql
+
+import "github.com/graph-gophers/graphql-go/internal/exec/resolvable"
+
+// TypedResolver exposes a plain data struct of type T as a resolver. Schema fields are resolved by the
+// exported fields of Value, matched by name or `graphql` struct tag as with [UseFieldResolvers], unless
+// the resolver has a method for the field. To add computed fields, embed TypedResolver in a struct and
+// define methods on it:
+//
+//	type User struct {
+//		graphql.TypedResolver[UserData]
+//	}
+//
+//	func (u *User) FullName() string {
+//		return u.Value.FirstName + " " + u.Value.LastName
+//	}
+//
+// The zero value of TypedResolver resolves the zero value of T.
+type TypedResolver[T any] struct {
+	_ resolvable.TypedValue
+
+	// Value holds the data of the object.
+	Value T
+}
+
+// Typed wraps every value in a [TypedResolver], e.g. to resolve a list of objects from a slice of data
+// structs without writing a resolver for them.
+func Typed[T any](values []T) []*TypedResolver[T] {
+	l := make([]*TypedResolver[T], len(values))
+	for i, v := range values {
+		l[i] = &TypedResolver[T]{Value: v}
+	}
+	return l
+}
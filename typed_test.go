@@ -0,0 +1,91 @@
+//go:build go1.18
+
+package graphql_test
+
+import (
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+)
+
+type typedUserData struct {
+	ID        graphql.ID
+	FirstName string
+	LastName  string
+	Email     string `graphql:"emailAddress"`
+}
+
+type typedUser struct {
+	graphql.TypedResolver[typedUserData]
+}
+
+func (u *typedUser) FullName() string {
+	return u.Value.FirstName + " " + u.Value.LastName
+}
+
+type typedQuery struct {
+	users []typedUserData
+}
+
+func (q *typedQuery) Users() []*graphql.TypedResolver[typedUserData] {
+	return graphql.Typed(q.users)
+}
+
+func (q *typedQuery) Me() *typedUser {
+	return &typedUser{graphql.TypedResolver[typedUserData]{Value: q.users[0]}}
+}
+
+func TestTypedResolver(t *testing.T) {
+	t.Parallel()
+
+	schema := graphql.MustParseSchema(`
+		type Query {
+			users: [User!]!
+			me: Me!
+		}
+
+		type User {
+			id: ID!
+			firstName: String!
+			emailAddress: String!
+		}
+
+		type Me {
+			id: ID!
+			fullName: String!
+		}
+	`, &typedQuery{users: []typedUserData{
+		{ID: "1", FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"},
+		{ID: "2", FirstName: "Alan", LastName: "Turing", Email: "alan@example.com"},
+	}})
+
+	gqltesting.RunTest(t, &gqltesting.Test{
+		Schema: schema,
+		Query: `
+			{
+				users {
+					id
+					firstName
+					emailAddress
+				}
+				me {
+					id
+					fullName
+				}
+			}
+		`,
+		ExpectedResult: `
+			{
+				"users": [
+					{"id": "1", "firstName": "Ada", "emailAddress": "ada@example.com"},
+					{"id": "2", "firstName": "Alan", "emailAddress": "alan@example.com"}
+				],
+				"me": {
+					"id": "1",
+					"fullName": "Ada Lovelace"
+				}
+			}
+		`,
+	})
+}
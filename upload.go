@@ -0,0 +1,41 @@
+package graphql
+
+import (
+	"fmt"
+	"io"
+)
+
+// Upload is a custom GraphQL type to represent a file uploaded with a GraphQL multipart request, see
+// https://github.com/jaydenseric/graphql-multipart-request-spec. It has to be added to a schema via
+// "scalar Upload" and can only be used as an input. HTTP handlers such as relay.Handler place the
+// uploaded files into the variables of the request.
+type Upload struct {
+	// File reads the content of the file. It is only valid for the duration of the request.
+	File        io.Reader
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+// ImplementsGraphQLType maps this custom Go type
+// to the graphql scalar type in the schema.
+func (Upload) ImplementsGraphQLType(name string) bool {
+	return name == "Upload"
+}
+
+// UnmarshalGraphQL is a custom unmarshaler for Upload
+//
+// This function will be called whenever you use the
+// Upload scalar as an input
+func (u *Upload) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case *Upload:
+		*u = *input
+		return nil
+	case Upload:
+		*u = input
+		return nil
+	default:
+		return fmt.Errorf("wrong type for Upload: %T", input)
+	}
+}